@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"net/http"
 	_ "net/http/pprof"
@@ -19,6 +18,7 @@ import (
 	"github.com/threefoldtech/rivine/pkg/daemon"
 
 	"github.com/ethereum/go-ethereum/log"
+	tfapi "github.com/threefoldfoundation/tfchain/pkg/api"
 	"github.com/threefoldfoundation/tfchain/pkg/config"
 	tftypes "github.com/threefoldfoundation/tfchain/pkg/types"
 	erc20types "github.com/threefoldtech/rivine-extension-erc20/types"
@@ -27,6 +27,7 @@ import (
 	"github.com/threefoldtech/rivine/extensions/minting"
 	mintingapi "github.com/threefoldtech/rivine/extensions/minting/api"
 
+	tfconsensus "github.com/threefoldfoundation/tfchain/extensions/tfchain/consensus"
 	"github.com/threefoldfoundation/tfchain/extensions/threebot"
 	bpapi "github.com/threefoldfoundation/tfchain/extensions/threebot/api"
 	erc20 "github.com/threefoldtech/rivine-extension-erc20"
@@ -47,11 +48,15 @@ type Commands struct {
 	BlockchainInfo rivinetypes.BlockchainInfo
 	ChainConstants rivinetypes.ChainConstants
 	NetworkConfig  config.DaemonNetworkConfig
+	ForkSchedule   tfconsensus.ForkSchedule
 	BootstrapPeers []modules.NetAddress
 	NoBootstrap    bool
 
 	EthNetworkName string
 
+	// GenesisFile is the optional path to a JSON file defining a custom network
+	GenesisFile string
+
 	// eth port for light client
 	EthPort uint16
 
@@ -73,6 +78,8 @@ type Commands struct {
 
 	VerboseRivineLogging bool
 	ConsensusDebugFile   string
+
+	HealthMaxBlockDelta rivinetypes.BlockHeight
 }
 
 const (
@@ -111,24 +118,33 @@ func (cmd *Commands) Root(_ *cobra.Command, args []string) (cmdErr error) {
 	log.Info("starting bridge", "version", cmd.BlockchainInfo.ChainVersion.String())
 
 	log.Info("loading network config (0/4)...")
+	var networkDefinition *config.NetworkDefinition
+	if cmd.GenesisFile != "" {
+		networkDefinition, err = config.LoadNetworkDefinition(cmd.GenesisFile)
+		if err != nil {
+			return err
+		}
+		cmd.BlockchainInfo.NetworkName = networkDefinition.Name
+	}
 	switch cmd.BlockchainInfo.NetworkName {
 	case config.NetworkNameStandard:
-		// cmd.ChainConstants = config.GetStandardnetGenesis()
-		// cmd.NetworkConfig = config.GetStandardDaemonNetworkConfig()
+		cmd.ChainConstants = config.GetStandardnetGenesis()
+		cmd.NetworkConfig = config.GetStandardDaemonNetworkConfig()
+		cmd.ForkSchedule = tfconsensus.GetStandardForkSchedule()
 
-		// if len(cmd.BootstrapPeers) == 0 {
-		// 	cmd.BootstrapPeers = config.GetStandardnetBootstrapPeers()
-		// }
+		if len(cmd.BootstrapPeers) == 0 {
+			cmd.BootstrapPeers = config.GetStandardnetBootstrapPeers()
+		}
 
-		// if cmd.EthNetworkName == "" {
-		// 	// default to main network on standard net
-		// 	cmd.EthNetworkName = "main"
-		// }
-		return errors.New("ERC20 feature is currently not enabled on standard net")
+		if cmd.EthNetworkName == "" {
+			// default to main network on standard net
+			cmd.EthNetworkName = "main"
+		}
 
 	case config.NetworkNameTest:
 		cmd.ChainConstants = config.GetTestnetGenesis()
 		cmd.NetworkConfig = config.GetTestnetDaemonNetworkConfig()
+		cmd.ForkSchedule = tfconsensus.GetTestnetForkSchedule()
 
 		if len(cmd.BootstrapPeers) == 0 {
 			cmd.BootstrapPeers = config.GetTestnetBootstrapPeers()
@@ -142,6 +158,7 @@ func (cmd *Commands) Root(_ *cobra.Command, args []string) (cmdErr error) {
 	case config.NetworkNameDev:
 		cmd.ChainConstants = config.GetDevnetGenesis()
 		cmd.NetworkConfig = config.GetDevnetDaemonNetworkConfig()
+		cmd.ForkSchedule = tfconsensus.GetDevnetForkSchedule()
 
 		if len(cmd.BootstrapPeers) == 0 {
 			cmd.BootstrapPeers = config.GetDevnetBootstrapPeers()
@@ -153,9 +170,26 @@ func (cmd *Commands) Root(_ *cobra.Command, args []string) (cmdErr error) {
 		}
 
 	default:
-		return fmt.Errorf(
-			"%q is an invalid network name, has to be one of {standard,testnet,devnet}",
-			cmd.BlockchainInfo.Name)
+		if networkDefinition == nil {
+			return fmt.Errorf(
+				"%q is an invalid network name, has to be one of {standard,testnet,devnet}",
+				cmd.BlockchainInfo.NetworkName)
+		}
+		cmd.ChainConstants = networkDefinition.ChainConstants()
+		cmd.NetworkConfig = networkDefinition.DaemonNetworkConfig()
+		cmd.ForkSchedule = tfconsensus.GetCustomForkSchedule(networkDefinition.ActivationHeights)
+
+		if len(cmd.BootstrapPeers) == 0 {
+			cmd.BootstrapPeers = networkDefinition.BootstrapPeers
+		}
+
+		if cmd.EthNetworkName == "" {
+			cmd.EthNetworkName = networkDefinition.ERC20Network()
+		}
+	}
+
+	if !cmd.ForkSchedule.IsScheduled(tfconsensus.FeatureERC20) {
+		return fmt.Errorf("ERC20 feature is not enabled on network %q", cmd.BlockchainInfo.NetworkName)
 	}
 
 	err = cmd.ChainConstants.Validate()
@@ -187,6 +221,18 @@ func (cmd *Commands) Root(_ *cobra.Command, args []string) (cmdErr error) {
 
 		var cs modules.ConsensusSet
 
+		// keep track of the status of the modules as they are loaded
+		minimumPeers := 1
+		if cmd.NoBootstrap {
+			minimumPeers = 0
+		}
+		healthMonitor := tfapi.NewHealthMonitor(tfapi.HealthMonitorOptions{
+			BlockFrequency: cmd.ChainConstants.BlockFrequency,
+			MaxBlockDelta:  cmd.HealthMaxBlockDelta,
+			MinimumPeers:   minimumPeers,
+		})
+		defer healthMonitor.Close()
+
 		// handle all our endpoints over a router,
 		// which requires a user agent should one be configured
 		srv.Handle("/", rivineapi.RequireUserAgentHandler(router, cmd.UserAgent))
@@ -215,6 +261,7 @@ func (cmd *Commands) Root(_ *cobra.Command, args []string) (cmdErr error) {
 				servErrs <- err
 			}
 		})
+		tfapi.RegisterDaemonHealthHTTPHandlers(router, healthMonitor)
 
 		log.Info("loading rivine gateway module (1/4)...")
 		gateway, err := gateway.New(
@@ -229,6 +276,7 @@ func (cmd *Commands) Root(_ *cobra.Command, args []string) (cmdErr error) {
 		// Blank password as we are not exposing the bridge HTTP API.
 		// TODO: Proper password verification like in the regular daemon
 		rivineapi.RegisterGatewayHTTPHandlers(router, gateway, "")
+		healthMonitor.SetGateway(gateway)
 		defer func() {
 			log.Info("Closing gateway module...")
 			err := gateway.Close()
@@ -247,7 +295,13 @@ func (cmd *Commands) Root(_ *cobra.Command, args []string) (cmdErr error) {
 			cmdErr = err
 			return
 		}
+		// validate transactions using the (height-activated) rules of the network
+		cs.SetTransactionValidators(cmd.ForkSchedule.TransactionValidators()...)
+		for txVersion, validators := range cmd.ForkSchedule.TransactionVersionMappedValidators() {
+			cs.SetTransactionVersionMappedValidators(txVersion, validators...)
+		}
 		rivineapi.RegisterConsensusHTTPHandlers(router, cs)
+		healthMonitor.SetConsensusSet(cs)
 		defer func() {
 			log.Info("Closing consensus module...")
 			err := cs.Close()
@@ -274,12 +328,11 @@ func (cmd *Commands) Root(_ *cobra.Command, args []string) (cmdErr error) {
 		// add the HTTP handlers for the minting plugin as well
 		mintingapi.RegisterConsensusMintingHTTPHandlers(router, mintingPlugin)
 
-		// 3Bot and ERC20 is not yet to be used on network standard
 		// create the 3Bot plugin
 		var tbPluginOpts *threebot.PluginOptions
-		if cmd.BlockchainInfo.NetworkName == config.NetworkNameTest {
-			tbPluginOpts = &threebot.PluginOptions{ // TODO: remove this hack once possible (e.g. a testnet network reset)
-				HackMinimumBlockHeightSinceDoubleRegistrationsAreForbidden: 350000,
+		if height, ok := cmd.ForkSchedule.ActivationHeight(tfconsensus.FeatureThreeBotDoubleRegistrationsForbidden); ok && height > 0 {
+			tbPluginOpts = &threebot.PluginOptions{
+				HackMinimumBlockHeightSinceDoubleRegistrationsAreForbidden: height,
 			}
 		}
 		threebotPlugin = threebot.NewPlugin(
@@ -329,6 +382,7 @@ func (cmd *Commands) Root(_ *cobra.Command, args []string) (cmdErr error) {
 		}()
 
 		erc20Client := bridged.GetClient()
+		healthMonitor.SetERC20(erc20Client)
 		erc20BridgeContract := bridged.GetBridgeContract()
 		erc20NodeValidator, err := erc20daemon.NewERC20NodeValidatorFromBridgeContract(erc20BridgeContract)
 		if err != nil {
@@ -397,6 +451,7 @@ func (cmd *Commands) Root(_ *cobra.Command, args []string) (cmdErr error) {
 			cmdErr = err
 			return
 		}
+		healthMonitor.MarkERC20Synced()
 
 		// Start the cs after the eth module is synced
 		cs.Start()
@@ -411,6 +466,7 @@ func (cmd *Commands) Root(_ *cobra.Command, args []string) (cmdErr error) {
 		}
 
 		log.Info("bridged is up and running...")
+		healthMonitor.MarkLoaded()
 
 		// wait until done
 		<-ctx.Done()
@@ -525,6 +581,11 @@ func main() {
 		cmd.BlockchainInfo.NetworkName,
 		"the name of the tfchain network to  connect to  {standard,testnet,devnet}",
 	)
+	cmdRoot.Flags().StringVar(
+		&cmd.GenesisFile,
+		"genesis-file", "",
+		"optional path to a JSON file defining a custom tfchain network, overwriting the network flag",
+	)
 
 	cli.NetAddressArrayFlagVar(
 		cmdRoot.Flags(),
@@ -589,6 +650,9 @@ func main() {
 	)
 	cmdRoot.Flags().BoolVarP(&cmd.VerboseRivineLogging, "verboseRivinelogging", "v", false, "enable verboselogging in the logfiles of the rivine modules")
 	cmdRoot.Flags().StringVar(&cmd.ConsensusDebugFile, "consensus-db-stats", cmd.ConsensusDebugFile, "file path in which json encoded database stats will be saved")
+	cmdRoot.Flags().Uint64Var(
+		(*uint64)(&cmd.HealthMaxBlockDelta), "health-max-block-delta", 10,
+		"amount of blocks bridged can lag behind while still being reported as ready by /daemon/ready")
 
 	// execute logic
 	if err := cmdRoot.Execute(); err != nil {
@@ -12,6 +12,8 @@ import (
 	"github.com/threefoldtech/rivine/modules"
 	"github.com/threefoldtech/rivine/pkg/client"
 
+	capacitycli "github.com/threefoldfoundation/tfchain/extensions/capacity/client"
+	recoverycli "github.com/threefoldfoundation/tfchain/extensions/recovery/client"
 	tfcli "github.com/threefoldfoundation/tfchain/extensions/tfchain/client"
 	tbcli "github.com/threefoldfoundation/tfchain/extensions/threebot/client"
 	erc20cli "github.com/threefoldtech/rivine-extension-erc20/client"
@@ -31,10 +33,18 @@ func main() {
 	exitIfError(err)
 	err = tbcli.CreateConsensusSubCmds(cliClient.CommandLineClient)
 	exitIfError(err)
+	err = recoverycli.CreateConsensusSubCmds(cliClient.CommandLineClient)
+	exitIfError(err)
+	err = capacitycli.CreateConsensusSubCmds(cliClient.CommandLineClient)
+	exitIfError(err)
 	err = mintingcli.CreateExploreCmd(cliClient.CommandLineClient)
 	exitIfError(err)
 	err = tbcli.CreateExplorerSubCmds(cliClient.CommandLineClient)
 	exitIfError(err)
+	err = recoverycli.CreateExplorerSubCmds(cliClient.CommandLineClient)
+	exitIfError(err)
+	err = capacitycli.CreateExplorerSubCmds(cliClient.CommandLineClient)
+	exitIfError(err)
 	err = mintingcli.CreateWalletCmds(
 		cliClient.CommandLineClient,
 		tftypes.TransactionVersionMinterDefinition, tftypes.TransactionVersionCoinCreation,
@@ -51,7 +61,13 @@ func main() {
 	exitIfError(err)
 	err = tbcli.CreateWalletCmds(cliClient.CommandLineClient)
 	exitIfError(err)
+	err = recoverycli.CreateWalletCmds(cliClient.CommandLineClient)
+	exitIfError(err)
+	err = capacitycli.CreateWalletCmds(cliClient.CommandLineClient)
+	exitIfError(err)
 	erc20cli.CreateERC20Cmd(cliClient.CommandLineClient)
+	err = tfcli.CreateVestingCmds(cliClient.CommandLineClient)
+	exitIfError(err)
 
 	err = authcointxcli.CreateConsensusAuthCoinInfoCmd(cliClient.CommandLineClient)
 	exitIfError(err)
@@ -70,6 +86,12 @@ func main() {
 	cliClient.ERC20Cmd = erc20cli.CreateERC20Cmd(cliClient.CommandLineClient)
 	cliClient.RootCmd.AddCommand(cliClient.ERC20Cmd)
 
+	// allow custom networks to be used, as defined by a genesis file
+	var genesisFile string
+	cliClient.RootCmd.PersistentFlags().StringVar(
+		&genesisFile, "genesis-file", "",
+		"optional path to the JSON file defining the custom network the daemon is connected to")
+
 	// define preRun function
 	cliClient.PreRunE = func(cfg *client.Config) (*client.Config, error) {
 		if cfg == nil {
@@ -121,7 +143,22 @@ func main() {
 			}
 
 		default:
-			return nil, fmt.Errorf("Netork name %q not recognized", cfg.NetworkName)
+			if genesisFile == "" {
+				return nil, fmt.Errorf("Netork name %q not recognized", cfg.NetworkName)
+			}
+			def, err := config.LoadNetworkDefinition(genesisFile)
+			if err != nil {
+				return nil, err
+			}
+			if def.Name != cfg.NetworkName {
+				return nil, fmt.Errorf("network %q defined in genesis file does not match daemon network %q", def.Name, cfg.NetworkName)
+			}
+			// Register the transaction controllers for all transaction versions
+			// supported on the custom network
+			err = tfcli.RegisterCustomTransactions(bc, def.ActivationHeights, def.DaemonNetworkConfig())
+			if err != nil {
+				return nil, err
+			}
 		}
 
 		return cfg, nil
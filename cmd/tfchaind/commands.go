@@ -8,6 +8,7 @@ import (
 	"runtime"
 	"strings"
 
+	"github.com/threefoldfoundation/tfchain/pkg/config"
 	"github.com/threefoldtech/rivine/pkg/cli"
 	"github.com/threefoldtech/rivine/pkg/daemon"
 	"github.com/threefoldtech/rivine/profile"
@@ -25,9 +26,36 @@ type commands struct {
 	erc20Cfg erc20daemon.ERC20NodeValidatorConfig
 }
 
-func (cmds *commands) rootCommand(*cobra.Command, []string) {
+func (cmds *commands) rootCommand(cmd *cobra.Command, _ []string) {
 	var err error
 
+	// apply the config file, if defined, should the matching flags not be set
+	if cmds.cfg.ConfigFile != "" {
+		fc, err := LoadFileConfig(cmds.cfg.ConfigFile)
+		if err != nil {
+			cli.DieWithError("failed to load config file", err)
+		}
+		err = fc.Apply(cmd.Flags(), &cmds.cfg, &cmds.moduleSetFlag, &cmds.erc20Cfg)
+		if err != nil {
+			cli.DieWithError("failed to apply config file", err)
+		}
+	}
+	cmds.cfg.explicitBootstrapPeers = flagChanged(cmd.Flags(), "bootstrap-peers")
+
+	// load the custom network, if defined, which defines the network name as well
+	if cmds.cfg.GenesisFile != "" {
+		def, err := config.LoadNetworkDefinition(cmds.cfg.GenesisFile)
+		if err != nil {
+			cli.DieWithError("failed to load genesis file", err)
+		}
+		if flagChanged(cmd.Flags(), "network") && cmds.cfg.BlockchainInfo.NetworkName != def.Name {
+			cli.DieWithError("failed to configure daemon", fmt.Errorf(
+				"network %q conflicts with network %q defined in genesis file", cmds.cfg.BlockchainInfo.NetworkName, def.Name))
+		}
+		cmds.cfg.BlockchainInfo.NetworkName = def.Name
+		cmds.cfg.networkDefinition = def
+	}
+
 	// Silently append a subdirectory for storage with the name of the network so we don't create conflicts
 	cmds.cfg.RootPersistentDir = filepath.Join(cmds.cfg.RootPersistentDir, cmds.cfg.BlockchainInfo.NetworkName)
 
@@ -74,7 +102,11 @@ func (cmds *commands) versionCommand(*cobra.Command, []string) {
 		postfix = "-testing"
 	case "standard": // ""
 	default:
-		postfix = "-???"
+		if cmds.cfg.networkDefinition != nil {
+			postfix = "-" + cmds.cfg.networkDefinition.Name
+		} else {
+			postfix = "-???"
+		}
 	}
 	fmt.Printf("%s Daemon v%s%s\n",
 		strings.Title(cmds.cfg.BlockchainInfo.Name),
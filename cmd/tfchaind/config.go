@@ -1,12 +1,46 @@
 package main
 
 import (
+	"github.com/threefoldfoundation/tfchain/pkg/config"
 	"github.com/threefoldtech/rivine/pkg/daemon"
+	"github.com/threefoldtech/rivine/types"
+)
+
+const (
+	// DefaultHealthMaxBlockDelta is the default amount of blocks
+	// the daemon can lag behind while still being reported as ready.
+	DefaultHealthMaxBlockDelta types.BlockHeight = 10
 )
 
 // ExtendedDaemonConfig contains all configurable variables for tfchaind.
 type ExtendedDaemonConfig struct {
 	daemon.Config
+
+	// HealthMaxBlockDelta defines how many blocks the consensus set
+	// and ERC20 light client can lag behind while still being reported as ready.
+	HealthMaxBlockDelta types.BlockHeight
+
+	// ConfigFile is the optional path to a YAML config file,
+	// see FileConfig for more information.
+	ConfigFile string
+
+	// GenesisFile is the optional path to a JSON file defining a custom network,
+	// see config.NetworkDefinition for more information.
+	GenesisFile string
+
+	// ERC20LocalValidation enables the local ERC20 validator, validating ERC20 withdrawals
+	// against a local list of observed withdrawals rather than against the ethereum network.
+	// Only to be used on the devnet and custom networks, for testing purposes.
+	ERC20LocalValidation bool
+	// ERC20LocalWithdrawalsFile is the optional path to the JSON file
+	// containing the withdrawals observed by the local ERC20 validator.
+	ERC20LocalWithdrawalsFile string
+
+	// explicitBootstrapPeers is true in case the bootstrap peers were defined as a flag,
+	// in which case they have precedence over the peers defined in the config file.
+	explicitBootstrapPeers bool
+	// networkDefinition is the custom network loaded from the genesis file, if any.
+	networkDefinition *config.NetworkDefinition
 }
 
 // DefaultConfig returns the default daemon configuration
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/spf13/pflag"
+	"github.com/threefoldfoundation/tfchain/pkg/config"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/pkg/daemon"
+	"github.com/threefoldtech/rivine/types"
+
+	erc20daemon "github.com/threefoldtech/rivine-extension-erc20/daemon"
+
+	"gopkg.in/yaml.v2"
+)
+
+type (
+	// FileConfig defines the structure of the (YAML) config file of tfchaind.
+	// All keys are optional, and flags always take precedence over the values defined in the file.
+	FileConfig struct {
+		Network             *string           `yaml:"network"`
+		GenesisFile         *string           `yaml:"genesis-file"`
+		Modules             *string           `yaml:"modules"`
+		PersistentDirectory *string           `yaml:"persistent-directory"`
+		RPCAddress          *string           `yaml:"rpc-address"`
+		NoBootstrap         *bool             `yaml:"no-bootstrap"`
+		BootstrapPeers      []string          `yaml:"bootstrap-peers"`
+		VerboseLogging      *bool             `yaml:"verbose-logging"`
+		HealthMaxBlockDelta *uint64           `yaml:"health-max-block-delta"`
+		API                 FileAPIConfig     `yaml:"api"`
+		ERC20               FileERC20Config   `yaml:"erc20"`
+		Profiling           FileProfileConfig `yaml:"profiling"`
+	}
+
+	// FileAPIConfig defines the api section of the tfchaind config file.
+	FileAPIConfig struct {
+		Address         *string `yaml:"address"`
+		UserAgent       *string `yaml:"user-agent"`
+		Authenticate    *bool   `yaml:"authenticate"`
+		Password        *string `yaml:"password"`
+		DisableSecurity *bool   `yaml:"disable-security"`
+	}
+
+	// FileERC20Config defines the erc20 section of the tfchaind config file.
+	FileERC20Config struct {
+		Validation *bool    `yaml:"validation"`
+		Network    *string  `yaml:"network"`
+		Port       *int     `yaml:"port"`
+		BootNodes  []string `yaml:"bootnodes"`
+		LogLevel   *int     `yaml:"log-level"`
+		Local      *bool    `yaml:"local"`
+		LocalFile  *string  `yaml:"local-file"`
+	}
+
+	// FileProfileConfig defines the profiling section of the tfchaind config file.
+	FileProfileConfig struct {
+		Enabled   *bool   `yaml:"enabled"`
+		Directory *string `yaml:"directory"`
+	}
+)
+
+// LoadFileConfig loads and validates the tfchaind config file found at the given path.
+func LoadFileConfig(path string) (FileConfig, error) {
+	var fc FileConfig
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fc, fmt.Errorf("failed to read config file %s: %v", path, err)
+	}
+	// unknown keys are reported by the strict decoder, including their line number
+	err = yaml.UnmarshalStrict(data, &fc)
+	if err != nil {
+		return fc, fmt.Errorf("failed to decode config file %s: %v", path, err)
+	}
+	err = fc.Validate()
+	if err != nil {
+		return fc, fmt.Errorf("invalid config file %s: %v", path, err)
+	}
+	return fc, nil
+}
+
+// Validate the values of the config file,
+// returning an error pointing to the first invalid key found.
+func (fc *FileConfig) Validate() error {
+	if fc.Network != nil && fc.GenesisFile == nil {
+		switch *fc.Network {
+		case config.NetworkNameStandard, config.NetworkNameTest, config.NetworkNameDev:
+		default:
+			return invalidKeyError("network", fmt.Errorf(
+				"%q is an invalid network name, has to be one of {standard,testnet,devnet}", *fc.Network))
+		}
+	}
+	if fc.GenesisFile != nil && *fc.GenesisFile == "" {
+		return invalidKeyError("genesis-file", fmt.Errorf("path cannot be empty"))
+	}
+	if fc.Modules != nil {
+		msf := daemon.DefaultModuleSetFlag()
+		if err := msf.Set(*fc.Modules); err != nil {
+			return invalidKeyError("modules", err)
+		}
+	}
+	if fc.RPCAddress != nil && *fc.RPCAddress == "" {
+		return invalidKeyError("rpc-address", fmt.Errorf("address cannot be empty"))
+	}
+	if _, err := fc.bootstrapPeers(); err != nil {
+		return err
+	}
+	if fc.API.Address != nil && *fc.API.Address == "" {
+		return invalidKeyError("api.address", fmt.Errorf("address cannot be empty"))
+	}
+	if fc.API.Password != nil && *fc.API.Password == "" {
+		return invalidKeyError("api.password", fmt.Errorf("password cannot be blank"))
+	}
+	if fc.ERC20.Network != nil {
+		switch *fc.ERC20.Network {
+		case "main", "ropsten", "rinkeby":
+		default:
+			return invalidKeyError("erc20.network", fmt.Errorf(
+				"%q is an invalid ethereum network name, has to be one of {main,ropsten,rinkeby}", *fc.ERC20.Network))
+		}
+	}
+	if fc.ERC20.Port != nil && (*fc.ERC20.Port <= 0 || *fc.ERC20.Port > 65535) {
+		return invalidKeyError("erc20.port", fmt.Errorf("%d is not a valid port", *fc.ERC20.Port))
+	}
+	if fc.ERC20.LocalFile != nil && *fc.ERC20.LocalFile == "" {
+		return invalidKeyError("erc20.local-file", fmt.Errorf("path cannot be empty"))
+	}
+	if fc.Profiling.Directory != nil && *fc.Profiling.Directory == "" {
+		return invalidKeyError("profiling.directory", fmt.Errorf("directory cannot be empty"))
+	}
+	return nil
+}
+
+func (fc *FileConfig) bootstrapPeers() ([]modules.NetAddress, error) {
+	if len(fc.BootstrapPeers) == 0 {
+		return nil, nil
+	}
+	peers := make([]modules.NetAddress, 0, len(fc.BootstrapPeers))
+	for idx, str := range fc.BootstrapPeers {
+		addr := modules.NetAddress(str)
+		if err := addr.IsStdValid(); err != nil {
+			return nil, invalidKeyError(fmt.Sprintf("bootstrap-peers[%d]", idx), fmt.Errorf(
+				"invalid network address %v: %v", str, err))
+		}
+		peers = append(peers, addr)
+	}
+	return peers, nil
+}
+
+// Apply the values of the config file to the given daemon configurations,
+// skipping all values for which the matching flag was set explicitly.
+func (fc *FileConfig) Apply(flags *pflag.FlagSet, cfg *ExtendedDaemonConfig, moduleSetFlag *daemon.ModuleSetFlag, erc20Cfg *erc20daemon.ERC20NodeValidatorConfig) error {
+	setString := func(flag string, dst *string, src *string) {
+		if src != nil && !flagChanged(flags, flag) {
+			*dst = *src
+		}
+	}
+	setBool := func(flag string, dst *bool, src *bool) {
+		if src != nil && !flagChanged(flags, flag) {
+			*dst = *src
+		}
+	}
+	setInt := func(flag string, dst *int, src *int) {
+		if src != nil && !flagChanged(flags, flag) {
+			*dst = *src
+		}
+	}
+
+	setString("network", &cfg.BlockchainInfo.NetworkName, fc.Network)
+	setString("genesis-file", &cfg.GenesisFile, fc.GenesisFile)
+	if fc.Modules != nil && !flagChanged(flags, "modules") {
+		if err := moduleSetFlag.Set(*fc.Modules); err != nil {
+			return invalidKeyError("modules", err)
+		}
+	}
+	setString("persistent-directory", &cfg.RootPersistentDir, fc.PersistentDirectory)
+	setString("rpc-addr", &cfg.RPCaddr, fc.RPCAddress)
+	setBool("no-bootstrap", &cfg.NoBootstrap, fc.NoBootstrap)
+	if !flagChanged(flags, "bootstrap-peers") {
+		peers, err := fc.bootstrapPeers()
+		if err != nil {
+			return err
+		}
+		if len(peers) > 0 {
+			cfg.BootstrapPeers = peers
+		}
+	}
+	setBool("verboselogging", &cfg.VerboseLogging, fc.VerboseLogging)
+	if fc.HealthMaxBlockDelta != nil && !flagChanged(flags, "health-max-block-delta") {
+		cfg.HealthMaxBlockDelta = types.BlockHeight(*fc.HealthMaxBlockDelta)
+	}
+
+	setString("api-addr", &cfg.APIaddr, fc.API.Address)
+	setString("agent", &cfg.RequiredUserAgent, fc.API.UserAgent)
+	setBool("authenticate-api", &cfg.AuthenticateAPI, fc.API.Authenticate)
+	// the API password cannot be defined as a flag
+	if fc.API.Password != nil {
+		cfg.APIPassword = *fc.API.Password
+	}
+	setBool("disable-api-security", &cfg.AllowAPIBind, fc.API.DisableSecurity)
+
+	setBool("ethvalidation", &erc20Cfg.Enabled, fc.ERC20.Validation)
+	setString("ethnetwork", &erc20Cfg.NetworkName, fc.ERC20.Network)
+	setInt("ethport", &erc20Cfg.Port, fc.ERC20.Port)
+	if len(fc.ERC20.BootNodes) > 0 && !flagChanged(flags, "ethbootnodes") {
+		erc20Cfg.BootNodes = fc.ERC20.BootNodes
+	}
+	setInt("ethereum-log-lvl", &erc20Cfg.EthLogLevel, fc.ERC20.LogLevel)
+	setBool("erc20-local", &cfg.ERC20LocalValidation, fc.ERC20.Local)
+	setString("erc20-local-file", &cfg.ERC20LocalWithdrawalsFile, fc.ERC20.LocalFile)
+
+	setBool("profile", &cfg.Profile, fc.Profiling.Enabled)
+	setString("profile-directory", &cfg.ProfileDir, fc.Profiling.Directory)
+
+	return nil
+}
+
+// flagChanged returns true if the flag exists and was set explicitly by the user.
+func flagChanged(flags *pflag.FlagSet, name string) bool {
+	flag := flags.Lookup(name)
+	return flag != nil && flag.Changed
+}
+
+func invalidKeyError(key string, err error) error {
+	return fmt.Errorf("invalid value for key %q: %v", key, err)
+}
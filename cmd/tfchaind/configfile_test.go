@@ -0,0 +1,342 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/threefoldfoundation/tfchain/pkg/config"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/pkg/daemon"
+)
+
+// writeConfigFile writes the given YAML content as config file in the given directory
+func writeConfigFile(t *testing.T, dir, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, "tfchaind.yaml")
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal("failed to write config file:", err)
+	}
+	return path
+}
+
+func newTestConfigDir(t *testing.T) (string, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "tfchaind-config")
+	if err != nil {
+		t.Fatal("failed to create temp dir:", err)
+	}
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+// newTestCommands creates the default daemon configurations,
+// registered to a flag set the same way as the root command does
+func newTestCommands() (*commands, *pflag.FlagSet) {
+	cmds := new(commands)
+	cmds.cfg.Config = DefaultConfig()
+	cmds.cfg.BlockchainInfo = config.GetBlockchainInfo()
+	cmds.cfg.HealthMaxBlockDelta = DefaultHealthMaxBlockDelta
+	cmds.moduleSetFlag = daemon.DefaultModuleSetFlag()
+
+	flags := pflag.NewFlagSet("tfchaind", pflag.ContinueOnError)
+	cmds.cfg.RegisterAsFlags(flags)
+	cmds.moduleSetFlag.RegisterFlag(flags, "tfchaind modules")
+	flags.StringVar(&cmds.cfg.GenesisFile, "genesis-file", "", "")
+	flags.Uint64Var((*uint64)(&cmds.cfg.HealthMaxBlockDelta), "health-max-block-delta", uint64(cmds.cfg.HealthMaxBlockDelta), "")
+	cmds.erc20Cfg.SetFlags(flags)
+	flags.BoolVar(&cmds.cfg.ERC20LocalValidation, "erc20-local", false, "")
+	flags.StringVar(&cmds.cfg.ERC20LocalWithdrawalsFile, "erc20-local-file", "", "")
+	return cmds, flags
+}
+
+func TestLoadFileConfig(t *testing.T) {
+	dir, cleanup := newTestConfigDir(t)
+	defer cleanup()
+
+	fc, err := LoadFileConfig(writeConfigFile(t, dir, `
+network: testnet
+modules: cgtwe
+bootstrap-peers:
+  - "bootstrap1.testnet.threefoldtoken.com:23112"
+health-max-block-delta: 5
+api:
+  authenticate: true
+  password: secret
+erc20:
+  network: ropsten
+  port: 30303
+`))
+	if err != nil {
+		t.Fatal("failed to load valid config file:", err)
+	}
+	if fc.Network == nil || *fc.Network != config.NetworkNameTest {
+		t.Errorf("unexpected network: %v", fc.Network)
+	}
+	if fc.HealthMaxBlockDelta == nil || *fc.HealthMaxBlockDelta != 5 {
+		t.Errorf("unexpected health max block delta: %v", fc.HealthMaxBlockDelta)
+	}
+	if fc.API.Password == nil || *fc.API.Password != "secret" {
+		t.Errorf("unexpected API password: %v", fc.API.Password)
+	}
+	if fc.RPCAddress != nil || fc.Profiling.Enabled != nil {
+		t.Error("expected undefined keys to remain undefined")
+	}
+
+	if _, err = LoadFileConfig(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Error("expected an error for a missing config file")
+	}
+
+	testCases := []struct {
+		Content string
+		Error   string
+	}{
+		{"netwrok: testnet", "netwrok"},
+		{"api:\n  pasword: secret", "pasword"},
+		{"no-bootstrap: maybe", "line 1"},
+		{"network: foo", `"network"`},
+		{"genesis-file: ''", `"genesis-file"`},
+		{"modules: cgtwxyz", `"modules"`},
+		{"rpc-address: ''", `"rpc-address"`},
+		{"bootstrap-peers: [localhost]", `"bootstrap-peers[0]"`},
+		{"api:\n  address: ''", `"api.address"`},
+		{"api:\n  password: ''", `"api.password"`},
+		{"erc20:\n  network: kovan", `"erc20.network"`},
+		{"erc20:\n  port: 70000", `"erc20.port"`},
+		{"erc20:\n  local-file: ''", `"erc20.local-file"`},
+		{"profiling:\n  directory: ''", `"profiling.directory"`},
+	}
+	for idx, testCase := range testCases {
+		_, err := LoadFileConfig(writeConfigFile(t, dir, testCase.Content))
+		if err == nil {
+			t.Errorf("#%d: expected config file to be invalid: %s", idx, testCase.Content)
+			continue
+		}
+		if !strings.Contains(err.Error(), testCase.Error) {
+			t.Errorf("#%d: expected error to point to %s: %v", idx, testCase.Error, err)
+		}
+	}
+}
+
+func TestFileConfigApply(t *testing.T) {
+	dir, cleanup := newTestConfigDir(t)
+	defer cleanup()
+	fc, err := LoadFileConfig(writeConfigFile(t, dir, `
+network: testnet
+rpc-address: ":24112"
+bootstrap-peers:
+  - "bootstrap1.testnet.threefoldtoken.com:23112"
+health-max-block-delta: 5
+api:
+  address: "localhost:24110"
+  authenticate: true
+  password: secret
+erc20:
+  network: ropsten
+  port: 30304
+profiling:
+  enabled: true
+`))
+	if err != nil {
+		t.Fatal("failed to load config file:", err)
+	}
+
+	cmds, flags := newTestCommands()
+	err = flags.Parse([]string{
+		"--network", "devnet",
+		"--bootstrap-peers", "localhost:23112",
+		"--health-max-block-delta", "20",
+		"--ethport", "30305",
+	})
+	if err != nil {
+		t.Fatal("failed to parse flags:", err)
+	}
+	err = fc.Apply(flags, &cmds.cfg, &cmds.moduleSetFlag, &cmds.erc20Cfg)
+	if err != nil {
+		t.Fatal("failed to apply config file:", err)
+	}
+
+	// flags take precedence
+	if cmds.cfg.BlockchainInfo.NetworkName != config.NetworkNameDev {
+		t.Errorf("expected network flag to take precedence, not %s", cmds.cfg.BlockchainInfo.NetworkName)
+	}
+	if len(cmds.cfg.BootstrapPeers) != 1 || cmds.cfg.BootstrapPeers[0] != "localhost:23112" {
+		t.Errorf("expected bootstrap peers flag to take precedence, not %v", cmds.cfg.BootstrapPeers)
+	}
+	if cmds.cfg.HealthMaxBlockDelta != 20 {
+		t.Errorf("expected health max block delta flag to take precedence, not %d", cmds.cfg.HealthMaxBlockDelta)
+	}
+	if cmds.erc20Cfg.Port != 30305 {
+		t.Errorf("expected ethport flag to take precedence, not %d", cmds.erc20Cfg.Port)
+	}
+
+	// config file values are used for all flags which weren't set
+	if cmds.cfg.RPCaddr != ":24112" {
+		t.Errorf("unexpected RPC address: %s", cmds.cfg.RPCaddr)
+	}
+	if cmds.cfg.APIaddr != "localhost:24110" {
+		t.Errorf("unexpected API address: %s", cmds.cfg.APIaddr)
+	}
+	if !cmds.cfg.AuthenticateAPI || cmds.cfg.APIPassword != "secret" {
+		t.Errorf("unexpected API authentication: %v (%q)", cmds.cfg.AuthenticateAPI, cmds.cfg.APIPassword)
+	}
+	if cmds.erc20Cfg.NetworkName != "ropsten" {
+		t.Errorf("unexpected ethereum network: %s", cmds.erc20Cfg.NetworkName)
+	}
+	if !cmds.cfg.Profile {
+		t.Error("expected profiling to be enabled")
+	}
+
+	// default values are kept for keys undefined in the config file
+	defaults, _ := newTestCommands()
+	if cmds.cfg.RequiredUserAgent != defaults.cfg.RequiredUserAgent {
+		t.Errorf("expected default user agent, not %s", cmds.cfg.RequiredUserAgent)
+	}
+	if cmds.cfg.NoBootstrap != defaults.cfg.NoBootstrap {
+		t.Errorf("expected default no-bootstrap value, not %v", cmds.cfg.NoBootstrap)
+	}
+}
+
+// testGateway records the addresses the reloader connects to
+type testGateway struct {
+	modules.Gateway
+	peers     []modules.Peer
+	connected chan modules.NetAddress
+}
+
+func (g *testGateway) Peers() []modules.Peer { return g.peers }
+
+func (g *testGateway) Connect(addr modules.NetAddress) error {
+	g.connected <- addr
+	return nil
+}
+
+func TestConfigReload(t *testing.T) {
+	dir, cleanup := newTestConfigDir(t)
+	defer cleanup()
+	path := writeConfigFile(t, dir, `
+api:
+  authenticate: true
+  password: old
+bootstrap-peers:
+  - "localhost:23112"
+`)
+
+	apiPassword, err := newAPIPasswordHandler("old")
+	if err != nil {
+		t.Fatal("failed to create API password handler:", err)
+	}
+	handler := apiPassword.Handler(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		// rivine's handlers are registered with the internal token
+		_, password, _ := req.BasicAuth()
+		if password != apiPassword.Token() {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	request := func(password string) int {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.SetBasicAuth("", password)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	if code := request("old"); code != http.StatusOK {
+		t.Fatalf("expected the initial password to be accepted, got status code %d", code)
+	}
+	if code := request(apiPassword.Token()); code != http.StatusUnauthorized {
+		t.Errorf("expected the internal token to be refused, got status code %d", code)
+	}
+
+	g := &testGateway{
+		peers:     []modules.Peer{{NetAddress: "localhost:23112"}},
+		connected: make(chan modules.NetAddress, 2),
+	}
+	var cfg ExtendedDaemonConfig
+	cfg.ConfigFile = path
+	cfg.AuthenticateAPI = true
+	reloader := &configReloader{cfg: cfg, apiPassword: apiPassword}
+	reloader.SetGateway(g)
+
+	writeConfigFile(t, dir, `
+api:
+  authenticate: true
+  password: new
+bootstrap-peers:
+  - "localhost:23112"
+  - "localhost:24112"
+`)
+	if err = reloader.Reload(); err != nil {
+		t.Fatal("failed to reload config file:", err)
+	}
+	if code := request("old"); code != http.StatusUnauthorized {
+		t.Errorf("expected the old password to be refused after reload, got status code %d", code)
+	}
+	if code := request("new"); code != http.StatusOK {
+		t.Errorf("expected the new password to be accepted after reload, got status code %d", code)
+	}
+	// only the newly defined peer is connected to
+	select {
+	case addr := <-g.connected:
+		if addr != "localhost:24112" {
+			t.Errorf("unexpected bootstrap peer connected to: %s", addr)
+		}
+	case <-time.After(time.Second):
+		t.Error("expected the reloader to connect to the new bootstrap peer")
+	}
+	select {
+	case addr := <-g.connected:
+		t.Errorf("unexpected connection to already connected peer %s", addr)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// invalid config files are not applied
+	writeConfigFile(t, dir, "api:\n  password: ''")
+	if err = reloader.Reload(); err == nil {
+		t.Error("expected reload of an invalid config file to fail")
+	}
+	if code := request("new"); code != http.StatusOK {
+		t.Errorf("expected the password to be unchanged after a failed reload, got status code %d", code)
+	}
+
+	// bootstrap peers defined as a flag are not reloaded
+	reloader.cfg.explicitBootstrapPeers = true
+	writeConfigFile(t, dir, "bootstrap-peers: [\"localhost:25112\"]")
+	if err = reloader.Reload(); err != nil {
+		t.Fatal("failed to reload config file:", err)
+	}
+	select {
+	case addr := <-g.connected:
+		t.Errorf("unexpected connection to bootstrap peer %s defined in the config file", addr)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestConfigReloadWithoutAuthentication(t *testing.T) {
+	dir, cleanup := newTestConfigDir(t)
+	defer cleanup()
+
+	apiPassword, err := newAPIPasswordHandler("")
+	if err != nil {
+		t.Fatal("failed to create API password handler:", err)
+	}
+	var cfg ExtendedDaemonConfig
+	cfg.ConfigFile = writeConfigFile(t, dir, "api:\n  password: secret")
+	reloader := &configReloader{cfg: cfg, apiPassword: apiPassword}
+	// the password is ignored, as authentication was disabled at startup
+	if err = reloader.Reload(); err != nil {
+		t.Error("expected the password to be ignored without authentication:", err)
+	}
+	if err = apiPassword.SetPassword("secret"); err == nil {
+		t.Error("expected the password to be immutable without authentication")
+	}
+
+	reloader.cfg.ConfigFile = ""
+	if err = reloader.Reload(); err == nil {
+		t.Error("expected reload to fail without config file")
+	}
+}
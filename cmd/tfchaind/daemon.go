@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -9,15 +10,23 @@ import (
 	"path"
 	"path/filepath"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/threefoldfoundation/tfchain/pkg/api"
 	"github.com/threefoldfoundation/tfchain/pkg/config"
+	"github.com/threefoldfoundation/tfchain/pkg/feemarket"
 	"github.com/threefoldtech/rivine/types"
 
+	"github.com/threefoldfoundation/tfchain/extensions/capacity"
+	capacityapi "github.com/threefoldfoundation/tfchain/extensions/capacity/api"
+	"github.com/threefoldfoundation/tfchain/extensions/recovery"
+	recoveryapi "github.com/threefoldfoundation/tfchain/extensions/recovery/api"
 	tfconsensus "github.com/threefoldfoundation/tfchain/extensions/tfchain/consensus"
 	"github.com/threefoldfoundation/tfchain/extensions/threebot"
 	tbapi "github.com/threefoldfoundation/tfchain/extensions/threebot/api"
+	tbtypes "github.com/threefoldfoundation/tfchain/extensions/threebot/types"
+	tferc20 "github.com/threefoldfoundation/tfchain/pkg/erc20"
 	tftypes "github.com/threefoldfoundation/tfchain/pkg/types"
 	erc20 "github.com/threefoldtech/rivine-extension-erc20"
 	erc20daemon "github.com/threefoldtech/rivine-extension-erc20/daemon"
@@ -71,6 +80,16 @@ func runDaemon(cfg ExtendedDaemonConfig, moduleIdentifiers daemon.ModuleIdentifi
 		servErrs <- srv.Serve()
 	}()
 
+	// the API password and bootstrap peers can be reloaded from the config file
+	apiPassword, err := newAPIPasswordHandler(cfg.APIPassword)
+	if err != nil {
+		return err
+	}
+	reloader := &configReloader{
+		cfg:         cfg,
+		apiPassword: apiPassword,
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 
 	// load all modules
@@ -95,24 +114,42 @@ func runDaemon(cfg ExtendedDaemonConfig, moduleIdentifiers daemon.ModuleIdentifi
 			cancel()
 			return
 		}
+		// the vesting condition is only known on the networks on which it is scheduled
+		if networkCfg.ForkSchedule.IsScheduled(tfconsensus.FeatureVesting) {
+			tftypes.RegisterVestingConditionType()
+		}
 
 		// start serving router ASAP
 		// handle all our endpoints over a router,
 		// which requires a user agent should one be configured
-		srv.Handle("/", rivineapi.RequireUserAgentHandler(router, cfg.RequiredUserAgent))
+		srv.Handle("/", rivineapi.RequireUserAgentHandler(apiPassword.Handler(router), cfg.RequiredUserAgent))
 
 		fmt.Println("Setting up root HTTP API handler...")
 
 		var cs modules.ConsensusSet
 
+		// keep track of the status of the modules as they are loaded
+		minimumPeers := 1
+		if cfg.NoBootstrap {
+			minimumPeers = 0
+		}
+		healthMonitor := api.NewHealthMonitor(api.HealthMonitorOptions{
+			BlockFrequency: networkCfg.NetworkConfig.Constants.BlockFrequency,
+			MaxBlockDelta:  cfg.HealthMaxBlockDelta,
+			MinimumPeers:   minimumPeers,
+		})
+		defer healthMonitor.Close()
+
 		// register our special daemon HTTP handlers
 		router.GET("/daemon/constants", func(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
 			var pluginNames []string
 			if cs != nil {
 				pluginNames = cs.LoadedPlugins()
 			}
-			constants := modules.NewDaemonConstants(cfg.BlockchainInfo, networkCfg.NetworkConfig.Constants, pluginNames)
-			rivineapi.WriteJSON(w, constants)
+			rivineapi.WriteJSON(w, daemonConstants{
+				DaemonConstants: modules.NewDaemonConstants(cfg.BlockchainInfo, networkCfg.NetworkConfig.Constants, pluginNames),
+				ForkSchedule:    networkCfg.ForkSchedule,
+			})
 		})
 		router.GET("/daemon/version", func(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
 			rivineapi.WriteJSON(w, daemon.Version{
@@ -137,6 +174,7 @@ func runDaemon(cfg ExtendedDaemonConfig, moduleIdentifiers daemon.ModuleIdentifi
 
 			cancel()
 		})
+		api.RegisterDaemonHealthHTTPHandlers(router, healthMonitor)
 
 		// Initialize the Rivine modules
 		var g modules.Gateway
@@ -150,7 +188,9 @@ func runDaemon(cfg ExtendedDaemonConfig, moduleIdentifiers daemon.ModuleIdentifi
 				cancel()
 				return
 			}
-			rivineapi.RegisterGatewayHTTPHandlers(router, g, cfg.APIPassword)
+			rivineapi.RegisterGatewayHTTPHandlers(router, g, apiPassword.Token())
+			healthMonitor.SetGateway(g)
+			reloader.SetGateway(g)
 			defer func() {
 				fmt.Println("Closing gateway...")
 				err := g.Close()
@@ -163,7 +203,10 @@ func runDaemon(cfg ExtendedDaemonConfig, moduleIdentifiers daemon.ModuleIdentifi
 		var mintingPlugin *minting.Plugin
 		var threebotPlugin *threebot.Plugin
 		var erc20TxValidator erc20types.ERC20TransactionValidator
+		var localERC20TxValidator *tferc20.LocalERC20TransactionValidator
 		var erc20Plugin *erc20.Plugin
+		var recoveryPlugin *recovery.Plugin
+		var capacityPlugin *capacity.Plugin
 		var authCoinTxPlugin *authcointx.Plugin
 
 		if moduleIdentifiers.Contains(daemon.ConsensusSetModule.Identifier()) {
@@ -183,6 +226,12 @@ func runDaemon(cfg ExtendedDaemonConfig, moduleIdentifiers daemon.ModuleIdentifi
 			}
 
 			rivineapi.RegisterConsensusHTTPHandlers(router, cs)
+			healthMonitor.SetConsensusSet(cs)
+			// suggest miner fees based on the fullness of recent blocks
+			api.RegisterDaemonFeesHTTPHandlers(router, feemarket.NewFeeEstimator(cs, networkCfg.NetworkConfig.Constants))
+			if networkCfg.ForkSchedule.IsScheduled(tfconsensus.FeatureVesting) {
+				api.RegisterConsensusVestingHTTPHandlers(router, cs)
+			}
 			defer func() {
 				fmt.Println("Closing consensus set...")
 				err := cs.Close()
@@ -204,13 +253,14 @@ func runDaemon(cfg ExtendedDaemonConfig, moduleIdentifiers daemon.ModuleIdentifi
 			// add the HTTP handlers for the minting plugin as well
 			mintingapi.RegisterConsensusMintingHTTPHandlers(router, mintingPlugin)
 
-			// 3Bot and ERC20 is not yet to be used on network standard
-			if cfg.BlockchainInfo.NetworkName != config.NetworkNameStandard {
+			// plugins are only created for the networks on which their feature is scheduled
+			schedule := networkCfg.ForkSchedule
+			if schedule.IsScheduled(tfconsensus.FeatureThreeBot) {
 				// create the 3Bot plugin
 				var tbPluginOpts *threebot.PluginOptions
-				if cfg.BlockchainInfo.NetworkName == config.NetworkNameTest {
-					tbPluginOpts = &threebot.PluginOptions{ // TODO: remove this hack once possible (e.g. a testnet network reset)
-						HackMinimumBlockHeightSinceDoubleRegistrationsAreForbidden: 350000,
+				if height, ok := schedule.ActivationHeight(tfconsensus.FeatureThreeBotDoubleRegistrationsForbidden); ok && height > 0 {
+					tbPluginOpts = &threebot.PluginOptions{
+						HackMinimumBlockHeightSinceDoubleRegistrationsAreForbidden: height,
 					}
 				}
 				threebotPlugin = threebot.NewPlugin(
@@ -221,15 +271,42 @@ func runDaemon(cfg ExtendedDaemonConfig, moduleIdentifiers daemon.ModuleIdentifi
 				// add the HTTP handlers for the threebot plugin as well
 				tbapi.RegisterConsensusHTTPHandlers(router, threebotPlugin)
 
+				// register the Threebot Plugin
+				err = cs.RegisterPlugin(ctx, "threebot", threebotPlugin)
+				if err != nil {
+					servErrs <- fmt.Errorf("failed to register the threebot extension: %v", err)
+					err = threebotPlugin.Close() //make sure any resources are released
+					if err != nil {
+						fmt.Println("Error during closing of the threebotPlugin:", err)
+					}
+					cancel()
+					return
+				}
+			}
+
+			if schedule.IsScheduled(tfconsensus.FeatureERC20) {
 				// create the ERC20 Tx Validator, used to validate the ERC20 Coin Creation Transactions
-				erc20TxValidator, err = setupERC20TransactionValidator(cfg.RootPersistentDir, cfg.BlockchainInfo.NetworkName, erc20Cfg, ctx.Done())
+				if cfg.networkDefinition != nil && erc20Cfg.NetworkName == "" {
+					erc20Cfg.NetworkName = cfg.networkDefinition.ERC20Network()
+				}
+				if cfg.ERC20LocalValidation {
+					localERC20TxValidator, err = setupLocalERC20TransactionValidator(cfg, erc20Cfg)
+					erc20TxValidator = localERC20TxValidator
+				} else {
+					erc20TxValidator, err = setupERC20TransactionValidator(cfg.RootPersistentDir, cfg.BlockchainInfo.NetworkName, erc20Cfg, ctx.Done())
+				}
 				if err != nil {
 					servErrs <- fmt.Errorf("failed to create ERC20 Transaction validator: %v", err)
 					cancel()
 					return
 				}
+				if localERC20TxValidator != nil {
+					// add the HTTP handlers used to manage the observed withdrawals as well
+					api.RegisterLocalERC20HTTPHandlers(router, localERC20TxValidator, apiPassword.Token())
+				}
 				// add the HTTP handlers for the ERC20 plugin as well
 				erc20api.RegisterERC20HTTPHandlers(router, erc20TxValidator)
+				healthMonitor.SetERC20(erc20TxValidator)
 
 				// create the ERC20 plugin
 				erc20Plugin = erc20.NewPlugin(
@@ -256,13 +333,49 @@ func runDaemon(cfg ExtendedDaemonConfig, moduleIdentifiers daemon.ModuleIdentifi
 					cancel()
 					return
 				}
-				// register the Threebot Plugin
-				err = cs.RegisterPlugin(ctx, "threebot", threebotPlugin)
+			}
+
+			if schedule.IsScheduled(tfconsensus.FeatureRecovery) {
+				// create the recovery plugin, used to freeze the addresses of lost seeds
+				// and recover their unspent coins to a new address,
+				// as well as to blacklist addresses as ordered by a court
+				recoveryPlugin = recovery.NewPlugin(
+					networkCfg.DaemonNetworkConfig.GenesisMintingCondition,
+					tftypes.TransactionVersionMinterDefinition,
+				)
+				// add the HTTP handlers for the recovery plugin as well
+				recoveryapi.RegisterConsensusHTTPHandlers(router, cs, recoveryPlugin, recoveryPlugin)
+
+				// register the Recovery Plugin
+				err = cs.RegisterPlugin(ctx, "recovery", recoveryPlugin)
 				if err != nil {
-					servErrs <- fmt.Errorf("failed to register the threebot extension: %v", err)
-					err = threebotPlugin.Close() //make sure any resources are released
+					servErrs <- fmt.Errorf("failed to register the recovery extension: %v", err)
+					err = recoveryPlugin.Close() //make sure any resources are released
 					if err != nil {
-						fmt.Println("Error during closing of the threebotPlugin:", err)
+						fmt.Println("Error during closing of the recoveryPlugin:", err)
+					}
+					cancel()
+					return
+				}
+			}
+
+			if schedule.IsScheduled(tfconsensus.FeatureCapacity) {
+				// create the capacity plugin, used to authorize farmers
+				// and keep track of the farms and capacity they register
+				capacityPlugin = capacity.NewPlugin(
+					networkCfg.DaemonNetworkConfig.GenesisMintingCondition,
+					tftypes.TransactionVersionMinterDefinition,
+				)
+				// add the HTTP handlers for the capacity plugin as well
+				capacityapi.RegisterConsensusHTTPHandlers(router, capacityPlugin, capacityPlugin)
+
+				// register the Capacity Plugin
+				err = cs.RegisterPlugin(ctx, "capacity", capacityPlugin)
+				if err != nil {
+					servErrs <- fmt.Errorf("failed to register the capacity extension: %v", err)
+					err = capacityPlugin.Close() //make sure any resources are released
+					if err != nil {
+						fmt.Println("Error during closing of the capacityPlugin:", err)
 					}
 					cancel()
 					return
@@ -293,7 +406,10 @@ func runDaemon(cfg ExtendedDaemonConfig, moduleIdentifiers daemon.ModuleIdentifi
 				cancel()
 				return
 			}
-			rivineapi.RegisterTransactionPoolHTTPHandlers(router, cs, tpool, cfg.APIPassword)
+			// order the unconfirmed transactions by fee rate,
+			// such that the block creator prioritizes the highest paying transactions
+			tpool = feemarket.NewTransactionPool(tpool)
+			rivineapi.RegisterTransactionPoolHTTPHandlers(router, cs, tpool, apiPassword.Token())
 			defer func() {
 				fmt.Println("Closing transaction pool...")
 				err := tpool.Close()
@@ -358,7 +474,8 @@ func runDaemon(cfg ExtendedDaemonConfig, moduleIdentifiers daemon.ModuleIdentifi
 				cancel()
 				return
 			}
-			rivineapi.RegisterWalletHTTPHandlers(router, w, cfg.APIPassword)
+			rivineapi.RegisterWalletHTTPHandlers(router, w, apiPassword.Token())
+			healthMonitor.SetWallet(w)
 			defer func() {
 				fmt.Println("Closing wallet...")
 				err := w.Close()
@@ -422,16 +539,30 @@ func runDaemon(cfg ExtendedDaemonConfig, moduleIdentifiers daemon.ModuleIdentifi
 			// DO NOT register rivineapi for Explorer HTTP Handles,
 			// as they are included in the tfchain api already
 			//rivineapi.RegisterExplorerHTTPHandlers(router, cs, e, tpool)
-			if cfg.BlockchainInfo.NetworkName == config.NetworkNameStandard {
-				api.RegisterExplorerHTTPHandlers(router, cs, e, tpool, nil, nil)
-			} else {
-				api.RegisterExplorerHTTPHandlers(router, cs, e, tpool, threebotPlugin, erc20Plugin)
+			// only pass the plugins which are created,
+			// as a nil plugin pointer would result in a non-nil registry interface
+			var tbRegistry tbtypes.BotRecordReadRegistry
+			if threebotPlugin != nil {
+				tbRegistry = threebotPlugin
+			}
+			var erc20Registry erc20types.ERC20Registry
+			if erc20Plugin != nil {
+				erc20Registry = erc20Plugin
+			}
+			api.RegisterExplorerHTTPHandlers(router, cs, e, tpool, tbRegistry, erc20Registry)
+			if recoveryPlugin != nil {
+				recoveryapi.RegisterExplorerHTTPHandlers(router, cs, recoveryPlugin, recoveryPlugin)
+			}
+			if capacityPlugin != nil {
+				capacityapi.RegisterExplorerHTTPHandlers(router, capacityPlugin, capacityPlugin)
+			}
+			if networkCfg.ForkSchedule.IsScheduled(tfconsensus.FeatureVesting) {
+				api.RegisterExplorerVestingHTTPHandlers(router, cs, e)
 			}
 			mintingapi.RegisterExplorerMintingHTTPHandlers(router, mintingPlugin)
 		}
 
-		// 3Bot and ERC20 is not yet to be used on network standard
-		if cfg.BlockchainInfo.NetworkName != config.NetworkNameStandard {
+		if erc20TxValidator != nil {
 			// Wait for the ethereum network to sync
 			err = erc20TxValidator.Wait(ctx)
 			if err != nil {
@@ -439,6 +570,7 @@ func runDaemon(cfg ExtendedDaemonConfig, moduleIdentifiers daemon.ModuleIdentifi
 				cancel()
 				return
 			}
+			healthMonitor.MarkERC20Synced()
 		}
 
 		if cs != nil {
@@ -448,6 +580,7 @@ func runDaemon(cfg ExtendedDaemonConfig, moduleIdentifiers daemon.ModuleIdentifi
 		// Print a 'startup complete' message.
 		startupTime := time.Since(loadStart)
 		fmt.Println("Finished loading in", startupTime.Seconds(), "seconds")
+		healthMonitor.MarkLoaded()
 
 		// wait until done
 		<-ctx.Done()
@@ -455,15 +588,28 @@ func runDaemon(cfg ExtendedDaemonConfig, moduleIdentifiers daemon.ModuleIdentifi
 
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, os.Kill)
-
-	// wait for server to be killed or the process to be done
-	select {
-	case <-sigChan:
-		fmt.Println("\rCaught stop signal, quitting...")
-		srv.Close()
-	case <-ctx.Done():
-		fmt.Println("\rContext is done, quitting...")
-		fmt.Println("context is done, quitting...")
+	reloadChan := make(chan os.Signal, 1)
+	signal.Notify(reloadChan, syscall.SIGHUP)
+
+	// wait for server to be killed or the process to be done,
+	// reloading the config file each time we are asked to do so
+	running := true
+	for running {
+		select {
+		case <-reloadChan:
+			fmt.Println("\rCaught reload signal, reloading config file...")
+			if err := reloader.Reload(); err != nil {
+				fmt.Println("Failed to reload config file:", err)
+			}
+		case <-sigChan:
+			fmt.Println("\rCaught stop signal, quitting...")
+			srv.Close()
+			running = false
+		case <-ctx.Done():
+			fmt.Println("\rContext is done, quitting...")
+			fmt.Println("context is done, quitting...")
+			running = false
+		}
 	}
 
 	cancel()
@@ -473,15 +619,31 @@ func runDaemon(cfg ExtendedDaemonConfig, moduleIdentifiers daemon.ModuleIdentifi
 	return <-servErrs
 }
 
+// daemonConstants extends the rivine daemon constants
+// with the fork schedule of the network.
+type daemonConstants struct {
+	modules.DaemonConstants
+	ForkSchedule tfconsensus.ForkSchedule `json:"forkschedule"`
+}
+
 type setupNetworkConfig struct {
 	NetworkConfig        daemon.NetworkConfig
 	DaemonNetworkConfig  config.DaemonNetworkConfig
 	GenesisAuthCondition types.UnlockConditionProxy
 
+	ForkSchedule     tfconsensus.ForkSchedule
 	Validators       []modules.TransactionValidationFunction
 	MappedValidators map[types.TransactionVersion][]modules.TransactionValidationFunction
 }
 
+// withScheduledValidators returns the network config,
+// with the transaction validators generated from its fork schedule.
+func (cfg setupNetworkConfig) withScheduledValidators() setupNetworkConfig {
+	cfg.Validators = cfg.ForkSchedule.TransactionValidators()
+	cfg.MappedValidators = cfg.ForkSchedule.TransactionVersionMappedValidators()
+	return cfg
+}
+
 // setupNetwork injects the correct chain constants and genesis nodes based on the chosen network,
 // it also ensures that features added during the lifetime of the blockchain,
 // only get activated on a certain block height, giving everyone sufficient time to upgrade should such features be introduced,
@@ -506,10 +668,9 @@ func setupNetwork(cfg ExtendedDaemonConfig) (setupNetworkConfig, error) {
 				BootstrapPeers: cfg.BootstrapPeers,
 			},
 			GenesisAuthCondition: config.GetStandardnetGenesisAuthCoinCondition(),
-			Validators:           tfconsensus.GetStandardTransactionValidators(),
-			MappedValidators:     tfconsensus.GetStandardTransactionVersionMappedValidators(),
+			ForkSchedule:         tfconsensus.GetStandardForkSchedule(),
 			DaemonNetworkConfig:  networkConfig,
-		}, nil
+		}.withScheduledValidators(), nil
 
 	case config.NetworkNameTest:
 		constants := config.GetTestnetGenesis()
@@ -527,10 +688,9 @@ func setupNetwork(cfg ExtendedDaemonConfig) (setupNetworkConfig, error) {
 				BootstrapPeers: cfg.BootstrapPeers,
 			},
 			GenesisAuthCondition: config.GetTestnetGenesisAuthCoinCondition(),
-			Validators:           tfconsensus.GetTestnetTransactionValidators(),
-			MappedValidators:     tfconsensus.GetTestnetTransactionVersionMappedValidators(),
+			ForkSchedule:         tfconsensus.GetTestnetForkSchedule(),
 			DaemonNetworkConfig:  networkConfig,
-		}, nil
+		}.withScheduledValidators(), nil
 
 	case config.NetworkNameDev:
 		constants := config.GetDevnetGenesis()
@@ -548,12 +708,29 @@ func setupNetwork(cfg ExtendedDaemonConfig) (setupNetworkConfig, error) {
 				BootstrapPeers: cfg.BootstrapPeers,
 			},
 			GenesisAuthCondition: config.GetDevnetGenesisAuthCoinCondition(),
-			Validators:           tfconsensus.GetDevnetTransactionValidators(),
-			MappedValidators:     tfconsensus.GetDevnetTransactionVersionMappedValidators(),
+			ForkSchedule:         tfconsensus.GetDevnetForkSchedule(),
 			DaemonNetworkConfig:  networkConfig,
-		}, nil
+		}.withScheduledValidators(), nil
 
 	default:
+		// a custom network, as defined by the genesis file
+		if def := cfg.networkDefinition; def != nil && def.Name == cfg.BlockchainInfo.NetworkName {
+			// Get the bootstrap peers from the genesis file
+			if len(cfg.BootstrapPeers) == 0 {
+				cfg.BootstrapPeers = def.BootstrapPeers
+			}
+
+			// return all info needed to setup the custom network
+			return setupNetworkConfig{
+				NetworkConfig: daemon.NetworkConfig{
+					Constants:      def.ChainConstants(),
+					BootstrapPeers: cfg.BootstrapPeers,
+				},
+				GenesisAuthCondition: def.AuthCondition,
+				ForkSchedule:         tfconsensus.GetCustomForkSchedule(def.ActivationHeights),
+				DaemonNetworkConfig:  def.DaemonNetworkConfig(),
+			}.withScheduledValidators(), nil
+		}
 		// network isn't recognised
 		return setupNetworkConfig{}, fmt.Errorf(
 			"Network name %q not recognized", cfg.BlockchainInfo.NetworkName)
@@ -564,7 +741,7 @@ func setupERC20TransactionValidator(rootDir, networkName string, erc20Cfg erc20d
 	if erc20Cfg.NetworkName == "" {
 		switch networkName {
 		case config.NetworkNameStandard:
-			erc20Cfg.NetworkName = "mainnet"
+			erc20Cfg.NetworkName = "main"
 		case config.NetworkNameTest:
 			erc20Cfg.NetworkName = "ropsten"
 		default:
@@ -574,3 +751,20 @@ func setupERC20TransactionValidator(rootDir, networkName string, erc20Cfg erc20d
 	erc20Cfg.DataDir = path.Join(rootDir, "leth")
 	return erc20daemon.NewERC20NodeValidator(erc20Cfg, cancel)
 }
+
+// setupLocalERC20TransactionValidator creates the local ERC20 validator,
+// only allowed on the devnet and custom networks, as it doesn't validate against the ETH network.
+func setupLocalERC20TransactionValidator(cfg ExtendedDaemonConfig, erc20Cfg erc20daemon.ERC20NodeValidatorConfig) (*tferc20.LocalERC20TransactionValidator, error) {
+	switch cfg.BlockchainInfo.NetworkName {
+	case config.NetworkNameStandard, config.NetworkNameTest:
+		return nil, fmt.Errorf("local ERC20 validation cannot be used on network %q", cfg.BlockchainInfo.NetworkName)
+	}
+	if erc20Cfg.Enabled {
+		return nil, errors.New("local ERC20 validation cannot be combined with ETH validation")
+	}
+	withdrawalsFile := cfg.ERC20LocalWithdrawalsFile
+	if withdrawalsFile == "" {
+		withdrawalsFile = path.Join(cfg.RootPersistentDir, "erc20-withdrawals.json")
+	}
+	return tferc20.NewLocalERC20TransactionValidator(withdrawalsFile)
+}
@@ -33,8 +33,30 @@ func main() {
 	// also add our modules as a flag
 	cmds.moduleSetFlag.RegisterFlag(rootCommand.Flags(), fmt.Sprintf("%s modules", os.Args[0]))
 
+	// config file flag
+	rootCommand.Flags().StringVarP(
+		&cmds.cfg.ConfigFile, "config", "c", "",
+		"optional path to a YAML config file, flags take precedence over the values defined in it")
+
+	// custom network flag
+	rootCommand.Flags().StringVar(
+		&cmds.cfg.GenesisFile, "genesis-file", "",
+		"optional path to a JSON file defining a custom network, used instead of one of the official networks")
+
+	// health flags
+	cmds.cfg.HealthMaxBlockDelta = DefaultHealthMaxBlockDelta
+	rootCommand.Flags().Uint64Var(
+		(*uint64)(&cmds.cfg.HealthMaxBlockDelta), "health-max-block-delta", uint64(cmds.cfg.HealthMaxBlockDelta),
+		"amount of blocks the daemon can lag behind while still being reported as ready by /daemon/ready")
+
 	// eth flags
 	cmds.erc20Cfg.SetFlags(rootCommand.Flags())
+	rootCommand.Flags().BoolVar(
+		&cmds.cfg.ERC20LocalValidation, "erc20-local", false,
+		"validate ERC20 withdrawals against a local list of observed withdrawals instead of the ETH network, only for devnet and custom networks")
+	rootCommand.Flags().StringVar(
+		&cmds.cfg.ERC20LocalWithdrawalsFile, "erc20-local-file", "",
+		"JSON file containing the withdrawals observed by the local ERC20 validator, defaults to erc20-withdrawals.json in the persistent directory")
 
 	// create the other commands
 	rootCommand.AddCommand(&cobra.Command{
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/threefoldtech/rivine/modules"
+)
+
+// apiPasswordHandler allows the API password to be updated while the daemon is running.
+//
+// The rivine HTTP handlers capture the password they are registered with,
+// which is why those are registered with an internal random token instead.
+// This handler replaces the password of any request authenticated
+// with the current API password by that token, prior to passing it on.
+type apiPasswordHandler struct {
+	token    string
+	password string
+	mu       sync.RWMutex
+}
+
+func newAPIPasswordHandler(password string) (*apiPasswordHandler, error) {
+	if password == "" {
+		// no password means no authentication, which cannot be reloaded
+		return &apiPasswordHandler{}, nil
+	}
+	var b [32]byte
+	_, err := rand.Read(b[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate internal API token: %v", err)
+	}
+	return &apiPasswordHandler{
+		token:    hex.EncodeToString(b[:]),
+		password: password,
+	}, nil
+}
+
+// Token returns the password to register the rivine HTTP handlers with.
+func (aph *apiPasswordHandler) Token() string {
+	return aph.token
+}
+
+// SetPassword updates the API password.
+func (aph *apiPasswordHandler) SetPassword(password string) error {
+	if aph.token == "" {
+		return fmt.Errorf("API authentication is disabled, password cannot be updated")
+	}
+	if password == "" {
+		return fmt.Errorf("password cannot be blank")
+	}
+	aph.mu.Lock()
+	aph.password = password
+	aph.mu.Unlock()
+	return nil
+}
+
+// Handler wraps the given handler, authenticating its requests using the current password.
+func (aph *apiPasswordHandler) Handler(h http.Handler) http.Handler {
+	if aph.token == "" {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		username, password, ok := req.BasicAuth()
+		if ok {
+			aph.mu.RLock()
+			valid := password == aph.password
+			aph.mu.RUnlock()
+			if valid {
+				req.SetBasicAuth(username, aph.token)
+			} else if password == aph.token {
+				// never accept the internal token directly
+				req.SetBasicAuth(username, "")
+			}
+		}
+		h.ServeHTTP(w, req)
+	})
+}
+
+// configReloader applies the reloadable sections of the config file,
+// while the daemon is running. Those sections are the bootstrap peers and the API password.
+type configReloader struct {
+	cfg         ExtendedDaemonConfig
+	apiPassword *apiPasswordHandler
+	gateway     modules.Gateway
+	mu          sync.Mutex
+}
+
+// SetGateway registers the gateway, used to connect to newly added bootstrap peers.
+func (cr *configReloader) SetGateway(g modules.Gateway) {
+	cr.mu.Lock()
+	cr.gateway = g
+	cr.mu.Unlock()
+}
+
+// Reload the config file, applying the reloadable sections.
+// Values for which a flag was defined when starting the daemon are ignored.
+func (cr *configReloader) Reload() error {
+	if cr.cfg.ConfigFile == "" {
+		return fmt.Errorf("no config file was used to start the daemon")
+	}
+	fc, err := LoadFileConfig(cr.cfg.ConfigFile)
+	if err != nil {
+		return err
+	}
+
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	if fc.API.Password != nil && cr.cfg.AuthenticateAPI {
+		err = cr.apiPassword.SetPassword(*fc.API.Password)
+		if err != nil {
+			return invalidKeyError("api.password", err)
+		}
+		fmt.Println("Reloaded API password")
+	}
+
+	if !cr.cfg.explicitBootstrapPeers && !cr.cfg.NoBootstrap && cr.gateway != nil {
+		peers, err := fc.bootstrapPeers()
+		if err != nil {
+			return err
+		}
+		connected := make(map[modules.NetAddress]struct{})
+		for _, peer := range cr.gateway.Peers() {
+			connected[peer.NetAddress] = struct{}{}
+		}
+		for _, addr := range peers {
+			if _, ok := connected[addr]; ok {
+				continue
+			}
+			go func(addr modules.NetAddress) {
+				err := cr.gateway.Connect(addr)
+				if err != nil {
+					fmt.Printf("Failed to connect to reloaded bootstrap peer %s: %v\r\n", addr, err)
+				}
+			}(addr)
+		}
+		fmt.Printf("Reloaded %d bootstrap peer(s)\r\n", len(peers))
+	}
+	return nil
+}
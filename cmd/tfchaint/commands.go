@@ -1,8 +1,10 @@
 package main
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
 	"strconv"
 	"strings"
 	"time"
@@ -15,6 +17,8 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/threefoldfoundation/tfchain/cmd/tfchaint/explorer"
 	"github.com/threefoldfoundation/tfchain/cmd/tfchaint/wallet"
+	tbtypes "github.com/threefoldfoundation/tfchain/extensions/threebot/types"
+	erc20types "github.com/threefoldtech/rivine-extension-erc20/types"
 )
 
 type (
@@ -43,7 +47,11 @@ const (
 )
 
 func (cmds *cmds) walletInit(cmd *cobra.Command, args []string) error {
-	wallet, err := wallet.New(args[0], cmds.KeysToLoad, cmds.Network)
+	passphrase, err := askNewPassphrase()
+	if err != nil {
+		return err
+	}
+	wallet, err := wallet.New(args[0], passphrase, cmds.KeysToLoad, cmds.Network, cmds.GenesisFile)
 	if err != nil {
 		return err
 	}
@@ -62,7 +70,11 @@ func (cmds *cmds) walletRecover(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	wallet, err := wallet.NewWalletFromMnemonic(args[0], strings.TrimSpace(mnemonic), cmds.KeysToLoad, cmds.Network)
+	passphrase, err := askNewPassphrase()
+	if err != nil {
+		return err
+	}
+	wallet, err := wallet.NewWalletFromMnemonic(args[0], strings.TrimSpace(mnemonic), passphrase, cmds.KeysToLoad, cmds.Network, cmds.GenesisFile)
 	if err != nil {
 		return err
 	}
@@ -76,12 +88,21 @@ func (cmds *cmds) walletRecover(cmd *cobra.Command, args []string) error {
 	fmt.Println("Created wallet", args[0], "from existing seed")
 	fmt.Println("Wallet seed:")
 	fmt.Println(newmnemonic)
+	if cmds.GapLimit == 0 {
+		return nil
+	}
+	fmt.Println("Discovering used addresses, this may take a while")
+	keys, err := wallet.DiscoverKeys(cmds.GapLimit)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Loaded", keys, "addresses")
 	return nil
 }
 
 func (cmds *cmds) walletSeed(cmd *cobra.Command, args []string) error {
 	walletName := cmd.Parent().Name()
-	w, err := wallet.Load(walletName)
+	w, err := cmds.loadWallet(walletName, true)
 	if err != nil {
 		return err
 	}
@@ -97,7 +118,7 @@ func (cmds *cmds) walletSeed(cmd *cobra.Command, args []string) error {
 func (cmds *cmds) walletSend(cmd *cobra.Command, args []string) error {
 	walletName := cmd.Parent().Name()
 
-	w, err := wallet.Load(walletName)
+	w, err := cmds.loadWallet(walletName, true)
 	if err != nil {
 		return err
 	}
@@ -192,6 +213,14 @@ func (cmds *cmds) walletSend(cmd *cobra.Command, args []string) error {
 		addresses = append(addresses, targetConditionProxies[i].UnlockHash().String())
 	}
 
+	if cmds.Unsigned || w.IsWatchOnly() {
+		ptx, err := w.CreateUnsignedTransaction(amounts, targetConditionProxies, []byte(cmds.DataString), cmds.GenerateNewRefundAddress)
+		if err != nil {
+			return err
+		}
+		return writePartialTransaction(cmds.OutputFile, ptx)
+	}
+
 	txID, err := w.TransferCoinsMulti(amounts, targetConditionProxies, []byte(cmds.DataString), cmds.GenerateNewRefundAddress)
 	if err != nil {
 		return err
@@ -212,7 +241,7 @@ func (cmds *cmds) walletReserveVM(cmd *cobra.Command, args []string) error {
 	}
 
 	walletName := cmd.Parent().Parent().Name()
-	w, err := wallet.Load(walletName)
+	w, err := cmds.loadWallet(walletName, true)
 	if err != nil {
 		return err
 	}
@@ -222,7 +251,7 @@ func (cmds *cmds) walletReserveVM(cmd *cobra.Command, args []string) error {
 
 func (cmds *cmds) walletReserveS3(cmd *cobra.Command, args []string) error {
 	walletName := cmd.Parent().Parent().Name()
-	w, err := wallet.Load(walletName)
+	w, err := cmds.loadWallet(walletName, true)
 	if err != nil {
 		return err
 	}
@@ -283,7 +312,7 @@ func reserveWorkload(w *wallet.Wallet, workload Workload, sizeString string,
 
 func (cmds *cmds) walletAddresses(cmd *cobra.Command, args []string) error {
 	walletName := cmd.Parent().Name()
-	w, err := wallet.Load(walletName)
+	w, err := cmds.loadWallet(walletName, false)
 	if err != nil {
 		return err
 	}
@@ -307,7 +336,7 @@ func (cmds *cmds) walletBalance(cmd *cobra.Command, args []string) error {
 
 	cc := client.NewCurrencyConvertor(types.CurrencyUnits{OneCoin: cts.OneCoin}, cts.ChainInfo.CoinUnit)
 
-	w, err := wallet.Load(walletName)
+	w, err := cmds.loadWallet(walletName, false)
 	if err != nil {
 		return err
 	}
@@ -340,7 +369,7 @@ func (cmds *cmds) walletLoad(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	w, err := wallet.Load(walletName)
+	w, err := cmds.loadWallet(walletName, true)
 	if err != nil {
 		return err
 	}
@@ -348,6 +377,702 @@ func (cmds *cmds) walletLoad(cmd *cobra.Command, args []string) error {
 	return w.LoadKeys(amount)
 }
 
+func (cmds *cmds) walletRescan(cmd *cobra.Command, args []string) error {
+	walletName := cmd.Parent().Name()
+	w, err := cmds.loadWallet(walletName, true)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Discovering used addresses, this may take a while")
+	keys, err := w.DiscoverKeys(cmds.GapLimit)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Loaded", keys, "addresses")
+	return nil
+}
+
+func (cmds *cmds) walletConsolidate(cmd *cobra.Command, args []string) error {
+	walletName := cmd.Parent().Name()
+	w, err := cmds.loadWallet(walletName, true)
+	if err != nil {
+		return err
+	}
+
+	var maxValue types.Currency
+	if cmds.MaxValueString != "" {
+		cts, err := w.GetChainConstants()
+		if err != nil {
+			return err
+		}
+		cc := client.NewCurrencyConvertor(types.CurrencyUnits{OneCoin: cts.OneCoin}, cts.ChainInfo.CoinUnit)
+		maxValue, err = cc.ParseCoinString(cmds.MaxValueString)
+		if err != nil {
+			return err
+		}
+	}
+
+	txnIDs, err := w.Consolidate(maxValue)
+	for _, txnID := range txnIDs {
+		fmt.Printf("Transaction posted: %s\n", txnID.String())
+	}
+	if err != nil {
+		return err
+	}
+	if len(txnIDs) == 0 {
+		fmt.Println("No outputs to consolidate")
+	}
+	return nil
+}
+
+func (cmds *cmds) walletMultiSigCreate(cmd *cobra.Command, args []string) error {
+	walletName := cmd.Parent().Parent().Name()
+	if len(args)%2 != 1 {
+		return errors.New("a multisig address followed by amount/address pair(s) expected")
+	}
+	var from types.UnlockHash
+	if err := from.LoadString(args[0]); err != nil {
+		return err
+	}
+
+	w, err := cmds.loadWallet(walletName, false)
+	if err != nil {
+		return err
+	}
+	cts, err := w.GetChainConstants()
+	if err != nil {
+		return err
+	}
+	cc := client.NewCurrencyConvertor(types.CurrencyUnits{OneCoin: cts.OneCoin}, cts.ChainInfo.CoinUnit)
+
+	var (
+		amounts    []types.Currency
+		conditions []types.UnlockConditionProxy
+	)
+	for i := 1; i < len(args); i += 2 {
+		amount, err := cc.ParseCoinString(args[i])
+		if err != nil {
+			return err
+		}
+		amounts = append(amounts, amount)
+		var to types.UnlockHash
+		if err = to.LoadString(args[i+1]); err != nil {
+			return err
+		}
+		conditions = append(conditions, types.NewCondition(types.NewUnlockHashCondition(to)))
+	}
+
+	ptx, err := w.CreateMultiSigTransaction(from, amounts, conditions, []byte(cmds.DataString))
+	if err != nil {
+		return err
+	}
+	return writePartialTransaction(cmds.OutputFile, ptx)
+}
+
+func (cmds *cmds) walletMultiSigMerge(cmd *cobra.Command, args []string) error {
+	var ptxs []*wallet.PartialTransaction
+	for _, path := range args {
+		ptx, err := wallet.LoadPartialTransaction(path)
+		if err != nil {
+			return err
+		}
+		ptxs = append(ptxs, ptx)
+	}
+	merged, err := wallet.MergePartialTransactions(ptxs...)
+	if err != nil {
+		return err
+	}
+	return writePartialTransaction(cmds.OutputFile, merged)
+}
+
+func (cmds *cmds) walletSign(cmd *cobra.Command, args []string) error {
+	walletName := cmd.Parent().Name()
+	ptx, err := wallet.LoadPartialTransaction(args[0])
+	if err != nil {
+		return err
+	}
+	// signing never requires network access
+	w, err := loadOfflineWallet(walletName, true)
+	if err != nil {
+		return err
+	}
+	signatures, err := w.SignTransaction(ptx)
+	if err != nil {
+		return err
+	}
+	if err = wallet.SavePartialTransaction(args[0], ptx); err != nil {
+		return err
+	}
+	fmt.Println("Added", signatures, "signature(s)")
+	printSignatures(ptx)
+	return nil
+}
+
+func (cmds *cmds) walletBroadcast(cmd *cobra.Command, args []string) error {
+	walletName := cmd.Parent().Name()
+	// the wallet is loaded first, such that the 3bot transactions of its network can be decoded
+	w, err := cmds.loadWallet(walletName, false)
+	if err != nil {
+		return err
+	}
+	ptx, err := wallet.LoadPartialTransaction(args[0])
+	if err != nil {
+		return err
+	}
+	txID, err := w.BroadcastTransaction(ptx)
+	if err != nil {
+		printSignatures(ptx)
+		return err
+	}
+	fmt.Printf("Transaction posted: %s\n", txID.String())
+	return nil
+}
+
+func (cmds *cmds) walletExportPublicKeys(cmd *cobra.Command, args []string) error {
+	walletName := cmd.Parent().Name()
+	// exporting the addresses does not require the seed
+	w, err := loadOfflineWallet(walletName, !cmds.AddressesOnly)
+	if err != nil {
+		return err
+	}
+	var entries []string
+	if cmds.AddressesOnly {
+		for _, address := range w.ListAddresses() {
+			entries = append(entries, address.String())
+		}
+	} else {
+		publicKeys, err := w.PublicKeys()
+		if err != nil {
+			return err
+		}
+		for _, pk := range publicKeys {
+			entries = append(entries, pk.String())
+		}
+	}
+	out := os.Stdout
+	if cmds.OutputFile != "" {
+		if out, err = os.Create(cmds.OutputFile); err != nil {
+			return err
+		}
+		defer out.Close()
+	}
+	e := json.NewEncoder(out)
+	e.SetIndent("", "  ")
+	return e.Encode(entries)
+}
+
+func (cmds *cmds) walletWatch(cmd *cobra.Command, args []string) error {
+	var (
+		publicKeys []types.PublicKey
+		addresses  []types.UnlockHash
+	)
+	// every argument is either a public key, an address or a file listing them
+	for _, arg := range args[1:] {
+		pk, uh, err := wallet.ParseWatchEntry(arg)
+		if err == nil {
+			if pk != nil {
+				publicKeys = append(publicKeys, *pk)
+			} else {
+				addresses = append(addresses, *uh)
+			}
+			continue
+		}
+		pks, uhs, err := wallet.LoadWatchList(arg)
+		if err != nil {
+			return err
+		}
+		publicKeys, addresses = append(publicKeys, pks...), append(addresses, uhs...)
+	}
+	w, err := wallet.NewWatchOnlyWallet(args[0], publicKeys, addresses, cmds.Network, cmds.GenesisFile)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Created watch-only wallet", args[0], "tracking", len(w.ListAddresses()), "addresses")
+	return nil
+}
+
+func (cmds *cmds) walletHistory(cmd *cobra.Command, args []string) error {
+	walletName := cmd.Parent().Name()
+	w, err := cmds.loadWallet(walletName, false)
+	if err != nil {
+		return err
+	}
+	cts, err := w.GetChainConstants()
+	if err != nil {
+		return err
+	}
+	cc := client.NewCurrencyConvertor(types.CurrencyUnits{OneCoin: cts.OneCoin}, cts.ChainInfo.CoinUnit)
+
+	entries, err := w.History()
+	if err != nil {
+		return err
+	}
+	pending, err := w.PendingTransactions()
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 && len(pending) == 0 {
+		fmt.Println("No transactions found")
+		return nil
+	}
+	listed := make(map[types.TransactionID]struct{}, len(entries))
+	for _, entry := range entries {
+		if entry.MinerPayout {
+			fmt.Printf("Miner payout in block %s", entry.BlockID.String())
+		} else {
+			listed[entry.TransactionID] = struct{}{}
+			fmt.Printf("Transaction %s", entry.TransactionID.String())
+		}
+		if entry.Unconfirmed {
+			fmt.Println(" (unconfirmed)")
+		} else {
+			fmt.Printf(" (height %d, %d confirmations)\n", entry.Height, entry.Confirmations)
+		}
+		if !entry.Received.IsZero() {
+			fmt.Println("\tIn: ", cc.ToCoinStringWithUnit(entry.Received))
+		}
+		if !entry.Spent.IsZero() {
+			fmt.Println("\tOut:", cc.ToCoinStringWithUnit(entry.Spent))
+			fmt.Println("\tFee:", cc.ToCoinStringWithUnit(entry.Fee))
+		}
+		if len(entry.Counterparties) > 0 {
+			direction := "From:"
+			if !entry.Spent.IsZero() {
+				direction = "To:  "
+			}
+			for _, uh := range entry.Counterparties {
+				fmt.Println("\t"+direction, uh.String())
+			}
+		}
+		if len(entry.Data) > 0 {
+			fmt.Printf("\tData: %q\n", entry.Data)
+		}
+	}
+	for _, ptxn := range pending {
+		if _, ok := listed[ptxn.ID]; !ok {
+			// a transaction spending the outputs of multisig addresses can be tracked more than once
+			listed[ptxn.ID] = struct{}{}
+			fmt.Printf("Transaction %s (pending, sent %s)\n", ptxn.ID.String(), ptxn.Sent.Format(time.RFC1123))
+		}
+	}
+	return nil
+}
+
+func (cmds *cmds) walletBotRegister(cmd *cobra.Command, args []string) error {
+	walletName := cmd.Parent().Parent().Name()
+	addresses, err := parseNetworkAddresses(cmds.BotAddresses)
+	if err != nil {
+		return err
+	}
+	names, err := parseBotNames(cmds.BotNames)
+	if err != nil {
+		return err
+	}
+	var pk *types.PublicKey
+	if cmds.BotPublicKey != "" {
+		pk = new(types.PublicKey)
+		if err = pk.LoadString(cmds.BotPublicKey); err != nil {
+			return err
+		}
+	}
+
+	w, err := cmds.loadWallet(walletName, true)
+	if err != nil {
+		return err
+	}
+	txID, botKey, err := w.RegisterBot(addresses, names, cmds.BotMonths, pk)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Transaction posted: %s\n", txID.String())
+	fmt.Println("3bot public key:", botKey.String())
+	return nil
+}
+
+func (cmds *cmds) walletBotUpdate(cmd *cobra.Command, args []string) error {
+	walletName := cmd.Parent().Parent().Name()
+	var id tbtypes.BotID
+	if err := id.LoadString(args[0]); err != nil {
+		return err
+	}
+	var (
+		addresses tbtypes.BotRecordAddressUpdate
+		names     tbtypes.BotRecordNameUpdate
+		err       error
+	)
+	if addresses.Add, err = parseNetworkAddresses(cmds.AddBotAddresses); err != nil {
+		return err
+	}
+	if addresses.Remove, err = parseNetworkAddresses(cmds.RemoveBotAddresses); err != nil {
+		return err
+	}
+	if names.Add, err = parseBotNames(cmds.AddBotNames); err != nil {
+		return err
+	}
+	if names.Remove, err = parseBotNames(cmds.RemoveBotNames); err != nil {
+		return err
+	}
+
+	w, err := cmds.loadWallet(walletName, true)
+	if err != nil {
+		return err
+	}
+	txID, err := w.UpdateBot(id, addresses, names, cmds.BotMonths)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Transaction posted: %s\n", txID.String())
+	return nil
+}
+
+func (cmds *cmds) walletBotTransferNames(cmd *cobra.Command, args []string) error {
+	walletName := cmd.Parent().Parent().Name()
+	var sender, receiver tbtypes.BotID
+	if err := sender.LoadString(args[0]); err != nil {
+		return err
+	}
+	if err := receiver.LoadString(args[1]); err != nil {
+		return err
+	}
+	names, err := parseBotNames(args[2:])
+	if err != nil {
+		return err
+	}
+
+	w, err := cmds.loadWallet(walletName, true)
+	if err != nil {
+		return err
+	}
+	ptx, err := w.TransferBotNames(sender, receiver, names)
+	if err != nil {
+		return err
+	}
+	signed, required, err := ptx.BotSignatures()
+	if err != nil {
+		return err
+	}
+	if signed < required {
+		fmt.Println("The transfer has to be signed by the owner of the other 3bot using the 'sign-transfer' command")
+		return writePartialTransaction(cmds.OutputFile, ptx)
+	}
+	txID, err := w.BroadcastTransaction(ptx)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Transaction posted: %s\n", txID.String())
+	return nil
+}
+
+func (cmds *cmds) walletBotSignTransfer(cmd *cobra.Command, args []string) error {
+	walletName := cmd.Parent().Parent().Name()
+	// the wallet is loaded first, such that the 3bot transactions of its network can be decoded
+	w, err := cmds.loadWallet(walletName, true)
+	if err != nil {
+		return err
+	}
+	ptx, err := wallet.LoadPartialTransaction(args[0])
+	if err != nil {
+		return err
+	}
+	signatures, err := w.SignBotTransaction(ptx)
+	if err != nil {
+		return err
+	}
+	if err = wallet.SavePartialTransaction(args[0], ptx); err != nil {
+		return err
+	}
+	fmt.Println("Added", signatures, "signature(s)")
+	printSignatures(ptx)
+	return nil
+}
+
+func (cmds *cmds) walletBotShow(cmd *cobra.Command, args []string) error {
+	walletName := cmd.Parent().Parent().Name()
+	w, err := cmds.loadWallet(walletName, false)
+	if err != nil {
+		return err
+	}
+	record, err := w.GetBotRecord(args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Println("ID:        ", record.ID.String())
+	fmt.Println("Public key:", record.PublicKey.String())
+	fmt.Println("Expiration:", time.Unix(int64(record.Expiration.SiaTimestamp()), 0).Format(time.RFC1123))
+	// the sorted sets only expose their elements through their JSON encoding
+	names, err := json.Marshal(record.Names)
+	if err != nil {
+		return err
+	}
+	addresses, err := json.Marshal(record.Addresses)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Names:     ", string(names))
+	fmt.Println("Addresses: ", string(addresses))
+	return nil
+}
+
+func (cmds *cmds) walletERC20Register(cmd *cobra.Command, args []string) error {
+	walletName := cmd.Parent().Parent().Name()
+	var pk *types.PublicKey
+	if len(args) > 0 {
+		pk = new(types.PublicKey)
+		if err := pk.LoadString(args[0]); err != nil {
+			return err
+		}
+	}
+
+	w, err := cmds.loadWallet(walletName, true)
+	if err != nil {
+		return err
+	}
+	txID, info, err := w.RegisterERC20Address(pk)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Transaction posted: %s\n", txID.String())
+	fmt.Println("TFT address:  ", info.Address.String())
+	fmt.Println("ERC20 address:", info.ERC20Address.String())
+	return nil
+}
+
+func (cmds *cmds) walletERC20Convert(cmd *cobra.Command, args []string) error {
+	walletName := cmd.Parent().Parent().Name()
+	var address erc20types.ERC20Address
+	if err := address.LoadString(args[0]); err != nil {
+		return err
+	}
+
+	w, err := cmds.loadWallet(walletName, true)
+	if err != nil {
+		return err
+	}
+	cts, err := w.GetChainConstants()
+	if err != nil {
+		return err
+	}
+	cc := client.NewCurrencyConvertor(types.CurrencyUnits{OneCoin: cts.OneCoin}, cts.ChainInfo.CoinUnit)
+	amount, err := cc.ParseCoinString(args[1])
+	if err != nil {
+		return err
+	}
+
+	txID, err := w.ConvertToERC20(address, amount)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Transaction posted: %s\n", txID.String())
+	return nil
+}
+
+func (cmds *cmds) walletERC20Addresses(cmd *cobra.Command, args []string) error {
+	walletName := cmd.Parent().Parent().Name()
+	w, err := cmds.loadWallet(walletName, false)
+	if err != nil {
+		return err
+	}
+	infos, err := w.ERC20Addresses()
+	if err != nil {
+		return err
+	}
+	for _, info := range infos {
+		status := "not registered"
+		if info.Registered {
+			status = fmt.Sprintf("registered, %d confirmations", info.Confirmations)
+		}
+		fmt.Printf("%s: %s (%s)\n", info.Address.String(), info.ERC20Address.String(), status)
+	}
+	return nil
+}
+
+// parseNetworkAddresses parses the given 3bot network addresses
+func parseNetworkAddresses(strs []string) ([]tbtypes.NetworkAddress, error) {
+	var addresses []tbtypes.NetworkAddress
+	for _, str := range strs {
+		var address tbtypes.NetworkAddress
+		if err := address.LoadString(str); err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, address)
+	}
+	return addresses, nil
+}
+
+// parseBotNames parses the given 3bot names
+func parseBotNames(strs []string) ([]tbtypes.BotName, error) {
+	var names []tbtypes.BotName
+	for _, str := range strs {
+		var name tbtypes.BotName
+		if err := name.LoadString(str); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// writePartialTransaction writes a partial transaction to the given file,
+// or to the STDOUT if no file is given
+func writePartialTransaction(path string, ptx *wallet.PartialTransaction) error {
+	if path != "" {
+		return wallet.SavePartialTransaction(path, ptx)
+	}
+	e := json.NewEncoder(os.Stdout)
+	e.SetIndent("", "  ")
+	return e.Encode(ptx)
+}
+
+// printSignatures prints the amount of signatures of every input of a partial transaction
+func printSignatures(ptx *wallet.PartialTransaction) {
+	signed, required := ptx.Signatures()
+	for idx, input := range ptx.Transaction.CoinInputs {
+		fmt.Printf("Input %s: %d/%d signatures\n", input.ParentID.String(), signed[idx], required[idx])
+	}
+	if signed, required, err := ptx.BotSignatures(); err == nil && required > 0 {
+		fmt.Printf("3bots: %d/%d signatures\n", signed, required)
+	}
+}
+
+func (cmds *cmds) walletUnlock(cmd *cobra.Command, args []string) error {
+	walletName := cmd.Parent().Name()
+	w, err := cmds.loadWallet(walletName, true)
+	if err != nil {
+		return err
+	}
+	if cmds.SessionTimeout <= 0 {
+		fmt.Println("Passphrase of wallet", walletName, "is valid")
+		return nil
+	}
+	err = w.StartSession(cmds.SessionTimeout)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Wallet %s is unlocked for %v\n", walletName, cmds.SessionTimeout)
+	return nil
+}
+
+func (cmds *cmds) walletLock(cmd *cobra.Command, args []string) error {
+	walletName := cmd.Parent().Name()
+	w, err := cmds.loadWallet(walletName, false)
+	if err != nil {
+		return err
+	}
+	err = w.Lock()
+	if err != nil {
+		return err
+	}
+	fmt.Println("Wallet", walletName, "is locked")
+	return nil
+}
+
+func (cmds *cmds) walletChangePassphrase(cmd *cobra.Command, args []string) error {
+	walletName := cmd.Parent().Name()
+	w, err := wallet.Load(walletName)
+	if err != nil {
+		return err
+	}
+	if w.IsEncrypted() {
+		// always require the current passphrase, even if the wallet has an active session
+		currentPassphrase, err := speakeasy.Ask("Current passphrase:")
+		if err != nil {
+			return err
+		}
+		err = w.Unlock(currentPassphrase)
+		if err != nil {
+			return err
+		}
+	}
+	passphrase, err := askNewPassphrase()
+	if err != nil {
+		return err
+	}
+	err = w.ChangePassphrase(passphrase)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Changed the passphrase of wallet", walletName)
+	return nil
+}
+
+// loadWallet loads the wallet with the given name, asking for its passphrase
+// if it has to be unlocked and has no active session. Legacy wallets which
+// store their seed unencrypted are migrated, by asking for a new passphrase.
+// The cached outputs of the wallet are discarded if the resync flag is set,
+// and the coin selection strategy defined by the flags is used to fund transactions.
+func (cmds *cmds) loadWallet(name string, unlock bool) (*wallet.Wallet, error) {
+	w, err := wallet.Load(name)
+	if err != nil {
+		return nil, err
+	}
+	if cmds.Resync {
+		if err = w.Resync(); err != nil {
+			return nil, err
+		}
+	}
+	selector, err := wallet.NewCoinSelector(cmds.CoinSelection)
+	if err != nil {
+		return nil, err
+	}
+	w.SetCoinSelector(selector)
+	return w, unlockWallet(w, name, unlock)
+}
+
+// loadOfflineWallet loads the wallet with the given name like loadWallet does,
+// without connecting to its backend
+func loadOfflineWallet(name string, unlock bool) (*wallet.Wallet, error) {
+	w, err := wallet.LoadOffline(name)
+	if err != nil {
+		return nil, err
+	}
+	return w, unlockWallet(w, name, unlock)
+}
+
+// unlockWallet asks for the passphrase of the wallet if it has to be unlocked, and migrates
+// legacy wallets by asking for a new passphrase. Watch-only wallets are never unlocked.
+func unlockWallet(w *wallet.Wallet, name string, unlock bool) error {
+	if w.IsWatchOnly() {
+		return nil
+	}
+	if !w.IsEncrypted() {
+		fmt.Println("Wallet", name, "stores its seed unencrypted, please define a passphrase to encrypt it")
+		passphrase, err := askNewPassphrase()
+		if err != nil {
+			return err
+		}
+		if err = w.ChangePassphrase(passphrase); err != nil {
+			return err
+		}
+	}
+	if unlock && w.IsLocked() {
+		passphrase, err := speakeasy.Ask("Passphrase:")
+		if err != nil {
+			return err
+		}
+		return w.Unlock(passphrase)
+	}
+	return nil
+}
+
+// askNewPassphrase asks for a new (non-empty) passphrase, which has to be confirmed
+func askNewPassphrase() (string, error) {
+	passphrase, err := speakeasy.Ask("New passphrase:")
+	if err != nil {
+		return "", err
+	}
+	if passphrase == "" {
+		return "", wallet.ErrEmptyPassphrase
+	}
+	confirmation, err := speakeasy.Ask("Confirm passphrase:")
+	if err != nil {
+		return "", err
+	}
+	if passphrase != confirmation {
+		return "", errors.New("Passphrases do not match")
+	}
+	return passphrase, nil
+}
+
 func parseAmount(amt string) (uint64, error) {
 	return strconv.ParseUint(amt, 10, 64)
 }
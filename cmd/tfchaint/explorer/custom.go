@@ -0,0 +1,47 @@
+package explorer
+
+import (
+	"errors"
+
+	tfcli "github.com/threefoldfoundation/tfchain/extensions/tfchain/client"
+	"github.com/threefoldfoundation/tfchain/pkg/config"
+	"github.com/threefoldtech/rivine/pkg/client"
+)
+
+// CustomGroupedExplorer is a GroupedExplorer configured for the explorers of a custom network
+type CustomGroupedExplorer struct {
+	*GroupedExplorer
+	name string
+}
+
+// NewCustomGroupedExplorer creates a grouped explorer for the explorers defined by a custom network
+func NewCustomGroupedExplorer(def *config.NetworkDefinition) (*CustomGroupedExplorer, error) {
+	if len(def.Explorers) == 0 {
+		return nil, errors.New("custom network does not define any explorers")
+	}
+	var explorers []*Explorer
+	for _, url := range def.Explorers {
+		explorers = append(explorers, NewExplorer(url, "Rivine-Agent", ""))
+	}
+	explorer := &CustomGroupedExplorer{
+		GroupedExplorer: NewGroupedExplorer(explorers...),
+		name:            def.Name,
+	}
+
+	// register transactions for the custom network
+	bc, err := client.NewBaseClient(explorer, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = tfcli.RegisterCustomTransactions(bc, def.ActivationHeights, def.DaemonNetworkConfig())
+	if err != nil {
+		return nil, err
+	}
+
+	return explorer, nil
+}
+
+// Name of the backend
+func (ce *CustomGroupedExplorer) Name() string {
+	return ce.name
+}
@@ -0,0 +1,28 @@
+package explorer
+
+import (
+	"net"
+
+	tfapi "github.com/threefoldfoundation/tfchain/pkg/api"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// GetERC20Info returns the ERC20 address registered for the given address, if any,
+// as returned together with its history by the explorer
+func (e *Explorer) GetERC20Info(addr types.UnlockHash) (*tfapi.ExplorerHashERC20Info, error) {
+	body := tfapi.ExplorerHashGET{}
+	_, err := e.get("/explorer/hashes/"+addr.String(), &body)
+	return body.ERC20Info, err
+}
+
+// GetERC20Info returns the ERC20 address registered for the given address, if any
+func (e *GroupedExplorer) GetERC20Info(addr types.UnlockHash) (*tfapi.ExplorerHashERC20Info, error) {
+	for _, explorer := range e.explorers {
+		info, err := explorer.GetERC20Info(addr)
+		if err, ok := err.(net.Error); ok && err.Timeout() {
+			continue
+		}
+		return info, err
+	}
+	return nil, ErrNoHealthyExplorers
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"strings"
@@ -14,6 +15,12 @@ import (
 	"github.com/threefoldtech/rivine/types"
 )
 
+var (
+	// ErrUnrecognizedHash is returned by the explorer for a hash it doesn't know about,
+	// which is the case for addresses which are not referenced by any block or transaction
+	ErrUnrecognizedHash = errors.New("unrecognized hash used as input to /explorer/hash")
+)
+
 type (
 	// Explorer is a backend which operates by querying a remote public explorer
 	Explorer struct {
@@ -42,6 +49,13 @@ func (e *Explorer) CheckAddress(addr types.UnlockHash) ([]api.ExplorerBlock, []a
 	return body.Blocks, body.Transactions, err
 }
 
+// GetBlock returns the block at the given height
+func (e *Explorer) GetBlock(height types.BlockHeight) (api.ExplorerBlock, error) {
+	body := api.ExplorerBlockGET{}
+	_, err := e.get(fmt.Sprintf("/explorer/blocks/%d", height), &body)
+	return body.Block, err
+}
+
 // SendTxn posts a transaction to the explorer to include it in the transactionpool
 func (e *Explorer) SendTxn(tx types.Transaction) (types.TransactionID, error) {
 	_, err := e.post("/transactionpool/transactions", tx, nil)
@@ -125,6 +139,9 @@ func (e *Explorer) request(method string, endpoint string, body interface{}, res
 		if err = json.NewDecoder(res.Body).Decode(&errBody); err != nil {
 			return nil, err
 		}
+		if errBody.Message == ErrUnrecognizedHash.Error() {
+			return nil, ErrUnrecognizedHash
+		}
 		return nil, errors.New(errBody.Message)
 	}
 	if responseBody != nil {
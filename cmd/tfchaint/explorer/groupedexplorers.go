@@ -36,6 +36,18 @@ func (e *GroupedExplorer) CheckAddress(addr types.UnlockHash) ([]api.ExplorerBlo
 	return nil, nil, ErrNoHealthyExplorers
 }
 
+// GetBlock returns the block at the given height
+func (e *GroupedExplorer) GetBlock(height types.BlockHeight) (api.ExplorerBlock, error) {
+	for _, explorer := range e.explorers {
+		block, err := explorer.GetBlock(height)
+		if err, ok := err.(net.Error); ok && err.Timeout() {
+			continue
+		}
+		return block, err
+	}
+	return api.ExplorerBlock{}, ErrNoHealthyExplorers
+}
+
 // CurrentHeight returns the current chain height
 func (e *GroupedExplorer) CurrentHeight() (types.BlockHeight, error) {
 	for _, explorer := range e.explorers {
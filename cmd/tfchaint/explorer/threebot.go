@@ -0,0 +1,59 @@
+package explorer
+
+import (
+	tbcli "github.com/threefoldfoundation/tfchain/extensions/threebot/client"
+	tbtypes "github.com/threefoldfoundation/tfchain/extensions/threebot/types"
+	"github.com/threefoldtech/rivine/pkg/client"
+	"github.com/threefoldtech/rivine/types"
+)
+
+var (
+	// ensure at compile time that the GroupedExplorer can look up 3bot records
+	_ tbtypes.BotRecordReadRegistry = (*GroupedExplorer)(nil)
+)
+
+// GetRecordForID returns the record of the 3bot with the given ID
+func (e *GroupedExplorer) GetRecordForID(id tbtypes.BotID) (*tbtypes.BotRecord, error) {
+	tbClient, err := e.threeBotClient()
+	if err != nil {
+		return nil, err
+	}
+	return tbClient.GetRecordForID(id)
+}
+
+// GetRecordForKey returns the record of the 3bot with the given public key
+func (e *GroupedExplorer) GetRecordForKey(key types.PublicKey) (*tbtypes.BotRecord, error) {
+	tbClient, err := e.threeBotClient()
+	if err != nil {
+		return nil, err
+	}
+	return tbClient.GetRecordForKey(key)
+}
+
+// GetRecordForName returns the record of the 3bot owning the given name
+func (e *GroupedExplorer) GetRecordForName(name tbtypes.BotName) (*tbtypes.BotRecord, error) {
+	tbClient, err := e.threeBotClient()
+	if err != nil {
+		return nil, err
+	}
+	return tbClient.GetRecordForName(name)
+}
+
+// GetBotTransactionIdentifiers returns the IDs of the transactions which created and updated the record of the 3bot
+func (e *GroupedExplorer) GetBotTransactionIdentifiers(id tbtypes.BotID) ([]types.TransactionID, error) {
+	tbClient, err := e.threeBotClient()
+	if err != nil {
+		return nil, err
+	}
+	return tbClient.GetBotTransactionIdentifiers(id)
+}
+
+// threeBotClient creates a client for the 3bot endpoints of the explorers
+func (e *GroupedExplorer) threeBotClient() (*tbcli.PluginClient, error) {
+	// the plugin client only uses the HTTP client, so the config doesn't have to be fetched
+	bc, err := client.NewBaseClient(e, &client.Config{})
+	if err != nil {
+		return nil, err
+	}
+	return tbcli.NewPluginExplorerClient(bc), nil
+}
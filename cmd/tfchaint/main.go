@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"time"
 
 	"github.com/threefoldfoundation/tfchain/cmd/tfchaint/wallet"
+	erc20types "github.com/threefoldtech/rivine-extension-erc20/types"
 
 	"github.com/spf13/cobra"
 )
@@ -15,6 +17,8 @@ const (
 	DefaultUserAgent = "Rivine-Agent"
 	// DefaultKeysToLoad is the default amount of keys to load
 	DefaultKeysToLoad = 1
+	// DefaultSessionTimeout is the default duration a wallet remains unlocked
+	DefaultSessionTimeout = 15 * time.Minute
 )
 
 type cmds struct {
@@ -24,7 +28,24 @@ type cmds struct {
 	DataString               string
 	LockString               string
 	Network                  string
+	GenesisFile              string
 	Broker                   string
+	SessionTimeout           time.Duration
+	Resync                   bool
+	GapLimit                 uint64
+	CoinSelection            string
+	MaxValueString           string
+	OutputFile               string
+	Unsigned                 bool
+	AddressesOnly            bool
+	BotAddresses             []string
+	BotNames                 []string
+	BotMonths                uint8
+	BotPublicKey             string
+	AddBotAddresses          []string
+	RemoveBotAddresses       []string
+	AddBotNames              []string
+	RemoveBotNames           []string
 }
 
 func main() {
@@ -48,18 +69,37 @@ without having to download the entire blockchain.`,
 	recoverCmd := &cobra.Command{
 		Use:   "recover [name]",
 		Short: "Recovers a wallet from an existing seed",
-		Long:  "Recover a wallet from an existing seed. This will add a wallet with the given name and the given seed.",
-		RunE:  cmd.walletRecover,
-		Args:  cobra.ExactArgs(1),
+		Long: `Recover a wallet from an existing seed. This will add a wallet with the given name and the given seed.
+Keys are loaded until an amount of consecutive unused addresses (the gap limit) is found, such that all used addresses are available.`,
+		RunE: cmd.walletRecover,
+		Args: cobra.ExactArgs(1),
 	}
 	initCmd.Flags().Uint64Var(&cmd.KeysToLoad, "key-amount", DefaultKeysToLoad, "Set the default amount of keys to load")
 	initCmd.Flags().StringVar(&cmd.Network, "network", "testnet", "Set the network to use for this wallet")
 	recoverCmd.Flags().Uint64Var(&cmd.KeysToLoad, "key-amount", DefaultKeysToLoad, "Set the default amount of keys to load")
 	recoverCmd.Flags().StringVar(&cmd.Network, "network", "testnet", "Set the network to use for this wallet")
+	recoverCmd.Flags().Uint64Var(&cmd.GapLimit, "gap-limit", wallet.DefaultGapLimit, "Load additional keys until this amount of consecutive unused addresses is found, 0 disables the discovery")
+	initCmd.Flags().StringVar(&cmd.GenesisFile, "genesis-file", "", "Use the custom network defined in this genesis file for this wallet, overwriting the network flag")
+	recoverCmd.Flags().StringVar(&cmd.GenesisFile, "genesis-file", "", "Use the custom network defined in this genesis file for this wallet, overwriting the network flag")
+
+	watchCmd := &cobra.Command{
+		Use:   "watch [name] [publickey|address|file]...",
+		Short: "Create a watch-only wallet from public keys or addresses",
+		Long: `Create a watch-only wallet with the given name, tracking the given public keys and addresses, which can be given directly
+or using files listing them, as exported using the 'export-public-keys' command of the wallet owning the seed.
+A watch-only wallet can check the balance and history of these addresses, and create unsigned transactions spending
+the outputs of the public keys, which have to be signed by the wallet owning the seed.
+Addresses given without their public key can only be watched.`,
+		RunE: cmd.walletWatch,
+		Args: cobra.MinimumNArgs(2),
+	}
+	watchCmd.Flags().StringVar(&cmd.Network, "network", "testnet", "Set the network to use for this wallet")
+	watchCmd.Flags().StringVar(&cmd.GenesisFile, "genesis-file", "", "Use the custom network defined in this genesis file for this wallet, overwriting the network flag")
 
 	rootCmd.AddCommand(
 		initCmd,
 		recoverCmd,
+		watchCmd,
 	)
 
 	walletNames, err := listWallets()
@@ -67,6 +107,10 @@ without having to download the entire blockchain.`,
 		fmt.Println("Failed to retrieve wallets:", err)
 		return
 	}
+	// make sure the cached keys of expired sessions do not outlive them
+	if err = wallet.RemoveExpiredSessions(walletNames); err != nil {
+		fmt.Println("Failed to remove expired sessions:", err)
+	}
 
 	for _, walletName := range walletNames {
 		walletCmd := &cobra.Command{
@@ -78,6 +122,9 @@ Additional actions can be performed on this wallet via subcommands`, walletName)
 			Args: cobra.NoArgs,
 		}
 
+		walletCmd.PersistentFlags().BoolVar(&cmd.Resync, "resync", false, "Discard the cached outputs of the wallet, and fetch the history of all its addresses again")
+		walletCmd.PersistentFlags().StringVar(&cmd.CoinSelection, "coin-selection", wallet.CoinSelectionLargestFirst,
+			"Strategy used to select the outputs funding a transaction: largest-first, smallest-first, branch-and-bound or single-address")
 		rootCmd.AddCommand(walletCmd)
 
 		seedCmd := &cobra.Command{
@@ -106,6 +153,8 @@ The following formats are supported to identify the receiver:
 		txCmd.Flags().BoolVar(&cmd.MultiSig, "multisig", false, "Send coins to a multisignature address")
 		txCmd.Flags().StringVarP(&cmd.DataString, "data", "d", "", "Attach this string as arbitrary data to the transaction")
 		txCmd.Flags().StringVarP(&cmd.LockString, "lock", "l", "", "Optional time lock. Supported formats are: <integer>, <data>, <date time> <duration>")
+		txCmd.Flags().BoolVar(&cmd.Unsigned, "unsigned", false, "Only create the transaction, to be signed using the 'sign' command, which is always the case for watch-only wallets")
+		txCmd.Flags().StringVarP(&cmd.OutputFile, "output", "o", "", "Write the unsigned transaction to this file")
 
 		reserveCmd := &cobra.Command{
 			Use:   "reserve <type> <size> <email>",
@@ -149,7 +198,7 @@ https://github.com/threefoldtoken/grid_broker`,
 			Use:   "addresses",
 			Short: "List all loaded addresses",
 			Long: `List all loaded addresses. If an address owned by this wallet is not in the list after recovering,
-	you can load more addresses using the 'load' sub command`,
+	you can load more addresses using the 'generate' sub command, or discover them using the 'rescan' command`,
 			RunE: cmd.walletAddresses,
 			Args: cobra.NoArgs,
 		}
@@ -165,7 +214,222 @@ If no amount is specified, 1 address will be generated`,
 			Args: cobra.MaximumNArgs(1),
 		}
 		addressesCmd.AddCommand(generateCmd)
-		walletCmd.AddCommand(seedCmd, txCmd, reserveCmd, addressesCmd)
+
+		rescanCmd := &cobra.Command{
+			Use:   "rescan",
+			Short: "Discover the used addresses of this wallet",
+			Long: `Derive keys from the seed until an amount of consecutive unused addresses (the gap limit) is found,
+loading all keys up to the last used address. Keys which are already loaded are never removed.`,
+			RunE: cmd.walletRescan,
+			Args: cobra.NoArgs,
+		}
+		rescanCmd.Flags().Uint64Var(&cmd.GapLimit, "gap-limit", wallet.DefaultGapLimit, "Stop once this amount of consecutive unused addresses is found")
+
+		consolidateCmd := &cobra.Command{
+			Use:   "consolidate",
+			Short: "Merge the outputs of this wallet into a single output",
+			Long: `Merge the unlocked outputs of this wallet, smallest first, into a single output sent to the first address.
+A single transaction can only spend a limited amount of outputs, so multiple transactions are created if required.
+Running the command again once those transactions are confirmed merges their outputs as well.`,
+			RunE: cmd.walletConsolidate,
+			Args: cobra.NoArgs,
+		}
+		consolidateCmd.Flags().StringVar(&cmd.MaxValueString, "max-value", "", "Only merge outputs with a value of at most this amount of coins")
+
+		multiSigCmd := &cobra.Command{
+			Use:   "multisig",
+			Short: "Spend coins from a multisig address",
+			Long: `Spend coins from a multisig address, by creating an unsigned transaction which is passed as a file
+to the co-signers of the address. Each co-signer signs the transaction using the 'sign' command of their own wallet,
+after which the signed transactions are merged and sent using the 'broadcast' command,
+once the required amount of signatures has been reached.`,
+		}
+		multiSigCreateCmd := &cobra.Command{
+			Use:   "create <multisigaddress> <amount> <address> [<amount> <address>...]",
+			Short: "Create an unsigned transaction spending coins from a multisig address",
+			Long: `Create an unsigned transaction sending the given amounts to the given addresses, funded by the multisig address.
+The transactionfee is paid by the multisig address as well, and any leftover value is sent back to it.
+The transaction is written to the given output file, or printed if no file is given.`,
+			RunE: cmd.walletMultiSigCreate,
+			Args: cobra.MinimumNArgs(3),
+		}
+		multiSigCreateCmd.Flags().StringVarP(&cmd.DataString, "data", "d", "", "Attach this string as arbitrary data to the transaction")
+		multiSigCreateCmd.Flags().StringVarP(&cmd.OutputFile, "output", "o", "", "Write the transaction to this file")
+		multiSigMergeCmd := &cobra.Command{
+			Use:   "merge <file> <file>...",
+			Short: "Merge the signatures of multisig transactions signed by different co-signers",
+			Long: `Merge the signatures of the multisig transactions stored in the given files, which have to define the same transaction.
+The merged transaction is written to the given output file, or printed if no file is given.`,
+			RunE: cmd.walletMultiSigMerge,
+			Args: cobra.MinimumNArgs(2),
+		}
+		multiSigMergeCmd.Flags().StringVarP(&cmd.OutputFile, "output", "o", "", "Write the merged transaction to this file")
+		multiSigCmd.AddCommand(multiSigCreateCmd, multiSigMergeCmd)
+
+		signCmd := &cobra.Command{
+			Use:   "sign <file>",
+			Short: "Sign a transaction using the keys of this wallet, without network access",
+			Long: `Sign every input of the transaction stored in the given file which can be signed by a key of this wallet, updating the file.
+Such transactions are created by watch-only wallets and multisig addresses. No network access is required to do so,
+such that the seed of the wallet can remain on an offline machine.`,
+			RunE: cmd.walletSign,
+			Args: cobra.ExactArgs(1),
+		}
+		broadcastCmd := &cobra.Command{
+			Use:   "broadcast <file>",
+			Short: "Send a transaction which has been signed using the 'sign' command",
+			Long:  `Send the transaction stored in the given file, which has to be signed by the required amount of signers for every input.`,
+			RunE:  cmd.walletBroadcast,
+			Args:  cobra.ExactArgs(1),
+		}
+		exportPublicKeysCmd := &cobra.Command{
+			Use:   "export-public-keys",
+			Short: "Export the public keys of this wallet, to create a watch-only wallet",
+			Long: `Export the public keys of the loaded addresses of this wallet, to create a watch-only wallet.
+As the keys of a wallet are derived from its seed, no extended public key exists to derive additional addresses from,
+so the keys have to be exported again after loading new addresses.`,
+			RunE: cmd.walletExportPublicKeys,
+			Args: cobra.NoArgs,
+		}
+		exportPublicKeysCmd.Flags().StringVarP(&cmd.OutputFile, "output", "o", "", "Write the public keys to this file")
+		exportPublicKeysCmd.Flags().BoolVar(&cmd.AddressesOnly, "addresses", false,
+			"Export the addresses instead of the public keys, which does not require the passphrase, but only allows to watch the balance")
+
+		historyCmd := &cobra.Command{
+			Use:   "history",
+			Short: "List the transactions affecting the balance of this wallet",
+			Long: `List the transactions and miner payouts affecting the balance of this wallet, including the amounts received and spent,
+the addresses coins are sent to or received from, the fee, the amount of confirmations and the attached data.
+Transactions sent by this wallet which are not confirmed yet are listed as well, the outputs they spend
+are not used by other transactions until they are confirmed, or until an hour has passed since they were sent.`,
+			RunE: cmd.walletHistory,
+			Args: cobra.NoArgs,
+		}
+
+		botCmd := &cobra.Command{
+			Use:   "3bot",
+			Short: "Register and manage 3bots",
+			Long: `Register and manage 3bots, identified by a key of this wallet. The fees of the 3bot transactions
+are paid by this wallet as well, and are sent to the foundation.`,
+		}
+		botRegisterCmd := &cobra.Command{
+			Use:   "register",
+			Short: "Register a new 3bot",
+			Long: `Register a new 3bot with the given network addresses and names, paying for the given amount of months.
+The 3bot is identified by the given public key, which has to be a key of this wallet, or by the key of a newly
+generated address if none is given. The ID of the 3bot is assigned once the transaction is confirmed,
+after which it can be found using the 'show' command.`,
+			RunE: cmd.walletBotRegister,
+			Args: cobra.NoArgs,
+		}
+		botRegisterCmd.Flags().StringArrayVar(&cmd.BotAddresses, "address", nil, "Network address (IP address or hostname) of the 3bot, can be given multiple times")
+		botRegisterCmd.Flags().StringArrayVar(&cmd.BotNames, "name", nil, "Name of the 3bot, can be given multiple times")
+		botRegisterCmd.Flags().Uint8Var(&cmd.BotMonths, "months", 1, "Amount of months to pay for, at most 24")
+		botRegisterCmd.Flags().StringVar(&cmd.BotPublicKey, "public-key", "", "Public key of this wallet identifying the 3bot, a new key is generated if not given")
+		botUpdateCmd := &cobra.Command{
+			Use:   "update <id>",
+			Short: "Update the record of a 3bot",
+			Long: `Update the network addresses and names of the 3bot with the given ID, and/or pay for additional months.
+The 3bot has to be identified by a key of this wallet. Names can only be added or removed while the 3bot is active.`,
+			RunE: cmd.walletBotUpdate,
+			Args: cobra.ExactArgs(1),
+		}
+		botUpdateCmd.Flags().StringArrayVar(&cmd.AddBotAddresses, "add-address", nil, "Network address to add, can be given multiple times")
+		botUpdateCmd.Flags().StringArrayVar(&cmd.RemoveBotAddresses, "remove-address", nil, "Network address to remove, can be given multiple times")
+		botUpdateCmd.Flags().StringArrayVar(&cmd.AddBotNames, "add-name", nil, "Name to add, can be given multiple times")
+		botUpdateCmd.Flags().StringArrayVar(&cmd.RemoveBotNames, "remove-name", nil, "Name to remove, can be given multiple times")
+		botUpdateCmd.Flags().Uint8Var(&cmd.BotMonths, "add-months", 0, "Amount of additional months to pay for, at most 24")
+		botTransferNamesCmd := &cobra.Command{
+			Use:   "transfer-names <sender id> <receiver id> <name>...",
+			Short: "Transfer names from one 3bot to another",
+			Long: `Transfer the given names from the sender 3bot to the receiver 3bot, both of which have to be active.
+At least one of the 3bots has to be identified by a key of this wallet, which pays the fees. If the other 3bot
+is identified by a key of another wallet, the transaction is written to the given output file, or printed
+if no file is given. It then has to be signed by the owner of the other 3bot using the 'sign-transfer' command,
+after which it can be sent using the 'broadcast' command.`,
+			RunE: cmd.walletBotTransferNames,
+			Args: cobra.MinimumNArgs(3),
+		}
+		botTransferNamesCmd.Flags().StringVarP(&cmd.OutputFile, "output", "o", "", "Write the transaction to this file if it has to be signed by another wallet")
+		botSignTransferCmd := &cobra.Command{
+			Use:   "sign-transfer <file>",
+			Short: "Sign a name transfer as a 3bot of this wallet",
+			Long: `Sign the name transfer stored in the given file as every 3bot identified by a key of this wallet, updating the file.
+Once signed by both 3bots, the transaction can be sent using the 'broadcast' command.`,
+			RunE: cmd.walletBotSignTransfer,
+			Args: cobra.ExactArgs(1),
+		}
+		botShowCmd := &cobra.Command{
+			Use:   "show <id|name|publickey>",
+			Short: "Show the record of a 3bot",
+			Long:  `Show the record of the 3bot identified by the given ID, one of its names or its public key.`,
+			RunE:  cmd.walletBotShow,
+			Args:  cobra.ExactArgs(1),
+		}
+		botCmd.AddCommand(botRegisterCmd, botUpdateCmd, botTransferNamesCmd, botSignTransferCmd, botShowCmd)
+
+		erc20Cmd := &cobra.Command{
+			Use:   "erc20",
+			Short: "Convert TFT to ERC20 funds, and manage ERC20 withdrawal addresses",
+			Long: `Convert TFT to ERC20 funds, and manage the ERC20 withdrawal addresses of this wallet.
+Every address of this wallet has an ERC20 address derived from it, which has to be registered
+before ERC20 funds sent to it are converted back to TFT, sent to the address of this wallet.`,
+		}
+		erc20RegisterCmd := &cobra.Command{
+			Use:   "register [publickey]",
+			Short: "Register an ERC20 withdrawal address",
+			Long: `Register the ERC20 withdrawal address derived from the given public key, which has to be a key of this wallet,
+or from the key of a newly generated address if none is given. The registration fee and transactionfee are paid by this wallet.`,
+			RunE: cmd.walletERC20Register,
+			Args: cobra.MaximumNArgs(1),
+		}
+		erc20ConvertCmd := &cobra.Command{
+			Use:   "convert <erc20address> <amount>",
+			Short: "Convert TFT to ERC20 funds sent to an ERC20 address",
+			Long: fmt.Sprintf(`Convert the given amount of TFT to ERC20 funds sent to the given ERC20 address.
+The bridge deducts its fees from the converted amount, the transactionfee is paid on top of it.
+At least %d TFT have to be converted.`, erc20types.ERC20ConversionMinimumValue),
+			RunE: cmd.walletERC20Convert,
+			Args: cobra.ExactArgs(2),
+		}
+		erc20AddressesCmd := &cobra.Command{
+			Use:   "addresses",
+			Short: "List the ERC20 addresses derived from the addresses of this wallet",
+			Long: `List the ERC20 address derived from every loaded address of this wallet,
+and whether or not it is registered as ERC20 withdrawal address.`,
+			RunE: cmd.walletERC20Addresses,
+			Args: cobra.NoArgs,
+		}
+		erc20Cmd.AddCommand(erc20RegisterCmd, erc20ConvertCmd, erc20AddressesCmd)
+
+		unlockCmd := &cobra.Command{
+			Use:   "unlock",
+			Short: "Unlock the wallet for a limited amount of time",
+			Long: `Unlock the wallet using its passphrase, caching the derived key, encrypted using a secret
+stored in the user runtime directory, until the session times out.
+While unlocked, commands which require the seed of the wallet no longer ask for the passphrase.
+The session can be ended early using the 'lock' sub command.`,
+			RunE: cmd.walletUnlock,
+			Args: cobra.NoArgs,
+		}
+		unlockCmd.Flags().DurationVar(&cmd.SessionTimeout, "timeout", DefaultSessionTimeout, "Duration the wallet remains unlocked, 0 only verifies the passphrase")
+
+		lockCmd := &cobra.Command{
+			Use:   "lock",
+			Short: "Lock the wallet, ending its active session",
+			RunE:  cmd.walletLock,
+			Args:  cobra.NoArgs,
+		}
+
+		changePassphraseCmd := &cobra.Command{
+			Use:   "change-passphrase",
+			Short: "Change the passphrase used to encrypt the seed of the wallet",
+			RunE:  cmd.walletChangePassphrase,
+			Args:  cobra.NoArgs,
+		}
+
+		walletCmd.AddCommand(seedCmd, txCmd, reserveCmd, addressesCmd, rescanCmd, consolidateCmd, multiSigCmd,
+			historyCmd, signCmd, broadcastCmd, exportPublicKeysCmd, botCmd, erc20Cmd, unlockCmd, lockCmd, changePassphraseCmd)
 	}
 
 	rootCmd.Execute()
@@ -1,6 +1,7 @@
 package wallet
 
 import (
+	tfapi "github.com/threefoldfoundation/tfchain/pkg/api"
 	"github.com/threefoldtech/rivine/modules"
 	"github.com/threefoldtech/rivine/pkg/api"
 	"github.com/threefoldtech/rivine/types"
@@ -10,6 +11,8 @@ import (
 type Backend interface {
 	// CheckAddress returns all interesting transactions and blocks related to a given unlockhash
 	CheckAddress(types.UnlockHash) ([]api.ExplorerBlock, []api.ExplorerTransaction, error)
+	// GetBlock returns the block at the given height
+	GetBlock(types.BlockHeight) (api.ExplorerBlock, error)
 	// CurrentHeight returns the current chain height
 	CurrentHeight() (types.BlockHeight, error)
 	// SendTxn sends a txn to the backend to ultimately include it in the transactionpool
@@ -19,3 +22,10 @@ type Backend interface {
 	// Name returns a static name for this backend, to allow loading and saving
 	Name() string
 }
+
+// ERC20Registry is implemented by backends which can look up the ERC20 address registered for an address
+type ERC20Registry interface {
+	// GetERC20Info returns the ERC20 address registered for the given address,
+	// nil is returned if no ERC20 address is registered for it
+	GetERC20Info(types.UnlockHash) (*tfapi.ExplorerHashERC20Info, error)
+}
@@ -0,0 +1,268 @@
+package wallet
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/threefoldtech/rivine/pkg/api"
+	"github.com/threefoldtech/rivine/types"
+)
+
+const (
+	// cacheFileName is the name of the file caching the unspent coin outputs of a wallet
+	cacheFileName = "cache.json"
+
+	// MaxIncrementalSyncBlocks is the maximum amount of blocks the cache is synced with block by block,
+	// if the cache is further behind, the history of all addresses is fetched again instead
+	MaxIncrementalSyncBlocks = 250
+)
+
+type (
+	// outputCache caches the unspent coin outputs of the synced addresses of a wallet,
+	// as known at the block it was synced to
+	outputCache struct {
+		// synced defines whether or not the cache was synced to a block
+		synced bool
+		// height and blockID identify the block the cache was synced to
+		height  types.BlockHeight
+		blockID types.BlockID
+		// addresses are all addresses for which the full history has been fetched
+		addresses map[types.UnlockHash]struct{}
+		// outputs are the unspent coin outputs of the synced addresses
+		outputs map[types.CoinOutputID]cachedOutput
+	}
+
+	// cachedOutput is an unspent coin output, as well as the height of the block which created it
+	cachedOutput struct {
+		ID          types.CoinOutputID `json:"id"`
+		Output      types.CoinOutput   `json:"output"`
+		Height      types.BlockHeight  `json:"height"`
+		MinerPayout bool               `json:"minerpayout,omitempty"`
+	}
+
+	cachePersist struct {
+		Height    types.BlockHeight  `json:"height"`
+		BlockID   types.BlockID      `json:"blockid"`
+		Addresses []types.UnlockHash `json:"addresses"`
+		Outputs   []cachedOutput     `json:"outputs"`
+	}
+)
+
+func newOutputCache() *outputCache {
+	return &outputCache{
+		addresses: make(map[types.UnlockHash]struct{}),
+		outputs:   make(map[types.CoinOutputID]cachedOutput),
+	}
+}
+
+// applyBlock adds the outputs created by the given block for any of the given addresses to the cache,
+// and removes all cached outputs spent by the block
+func (cache *outputCache) applyBlock(block api.ExplorerBlock, addresses map[types.UnlockHash]struct{}) {
+	for i, minerPayout := range block.RawBlock.MinerPayouts {
+		if _, ok := addresses[minerPayout.UnlockHash]; ok {
+			cache.outputs[block.MinerPayoutIDs[i]] = cachedOutput{
+				ID: block.MinerPayoutIDs[i],
+				Output: types.CoinOutput{
+					Value:     minerPayout.Value,
+					Condition: types.NewCondition(types.NewUnlockHashCondition(minerPayout.UnlockHash)),
+				},
+				Height:      block.Height,
+				MinerPayout: true,
+			}
+		}
+	}
+	for _, txn := range block.Transactions {
+		for _, ci := range txn.RawTransaction.CoinInputs {
+			delete(cache.outputs, ci.ParentID)
+		}
+		for i, co := range txn.RawTransaction.CoinOutputs {
+			if _, ok := addresses[co.Condition.UnlockHash()]; ok {
+				cache.outputs[txn.CoinOutputIDs[i]] = cachedOutput{
+					ID:     txn.CoinOutputIDs[i],
+					Output: co,
+					Height: block.Height,
+				}
+			}
+		}
+	}
+	cache.synced, cache.height, cache.blockID = true, block.Height, block.BlockID
+}
+
+// Resync discards the cached outputs of the wallet,
+// such that the history of all its addresses is fetched again the next time it is synced.
+func (w *Wallet) Resync() error {
+	w.cache = newOutputCache()
+	return removeCache(w.name)
+}
+
+// sync brings the cached outputs of the wallet up to date with the current block,
+// returning the current chain height. Blocks created since the last sync are applied one by one,
+// while the full history is fetched for addresses which weren't synced yet. The cache is discarded
+// if the block it was synced to is no longer part of the chain, or if it is too far behind.
+func (w *Wallet) sync() (types.BlockHeight, error) {
+	if w.cache == nil {
+		cache, err := loadCache(w.name)
+		if err != nil {
+			return 0, err
+		}
+		w.cache = cache
+	}
+
+	currentHeight, err := w.backend.CurrentHeight()
+	if err != nil {
+		return 0, err
+	}
+
+	if w.cache.synced {
+		if currentHeight < w.cache.height || currentHeight-w.cache.height > MaxIncrementalSyncBlocks {
+			w.cache = newOutputCache()
+		} else {
+			// detect reorgs by comparing the ID of the block the cache was synced to
+			block, err := w.backend.GetBlock(w.cache.height)
+			if err != nil {
+				return 0, err
+			}
+			if block.BlockID != w.cache.blockID {
+				w.cache = newOutputCache()
+			}
+		}
+	}
+
+	addresses := make(map[types.UnlockHash]struct{}, len(w.addresses))
+	var unsynced []types.UnlockHash
+	for _, address := range w.addresses {
+		addresses[address] = struct{}{}
+		if _, ok := w.cache.addresses[address]; !ok {
+			unsynced = append(unsynced, address)
+		}
+	}
+
+	if w.cache.synced {
+		blocks, err := w.getBlocks(w.cache.height+1, currentHeight)
+		if err != nil {
+			return 0, err
+		}
+		for _, block := range blocks {
+			w.cache.applyBlock(block, addresses)
+		}
+	} else {
+		// the history of the addresses might contain blocks created after this one,
+		// which is fine as applying a block to the cache multiple times has no effect
+		block, err := w.backend.GetBlock(currentHeight)
+		if err != nil {
+			return 0, err
+		}
+		w.cache.synced, w.cache.height, w.cache.blockID = true, block.Height, block.BlockID
+	}
+
+	if len(unsynced) > 0 {
+		outputs, err := w.getAddressOutputs(unsynced)
+		if err != nil {
+			return 0, err
+		}
+		for id, co := range outputs {
+			w.cache.outputs[id] = co
+		}
+		for _, address := range unsynced {
+			w.cache.addresses[address] = struct{}{}
+		}
+	}
+
+	return currentHeight, saveCache(w.name, w.cache)
+}
+
+// getBlocks fetches all blocks in the given (inclusive) range, ordered by height
+func (w *Wallet) getBlocks(start, end types.BlockHeight) ([]api.ExplorerBlock, error) {
+	if start > end {
+		return nil, nil
+	}
+	count := int(end - start + 1)
+	workerCount := WorkerCount
+	if count < workerCount {
+		workerCount = count
+	}
+
+	jobs := make(chan int, count)
+	errChan := make(chan error, count)
+	blocks := make([]api.ExplorerBlock, count)
+
+	for worker := 1; worker <= workerCount; worker++ {
+		go func() {
+			for idx := range jobs {
+				block, err := w.backend.GetBlock(start + types.BlockHeight(idx))
+				blocks[idx] = block
+				errChan <- err
+			}
+		}()
+	}
+	for idx := 0; idx < count; idx++ {
+		jobs <- idx
+	}
+	close(jobs)
+
+	errs := make([]string, 0)
+	for idx := 0; idx < count; idx++ {
+		if err := <-errChan; err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.New(strings.Join(errs, "\n"))
+	}
+	return blocks, nil
+}
+
+func saveCache(name string, cache *outputCache) error {
+	data := cachePersist{
+		Height:    cache.height,
+		BlockID:   cache.blockID,
+		Addresses: make([]types.UnlockHash, 0, len(cache.addresses)),
+		Outputs:   make([]cachedOutput, 0, len(cache.outputs)),
+	}
+	for address := range cache.addresses {
+		data.Addresses = append(data.Addresses, address)
+	}
+	for _, co := range cache.outputs {
+		data.Outputs = append(data.Outputs, co)
+	}
+	return writeJSONFile(filepath.Join(Dir(name), cacheFileName), data)
+}
+
+// loadCache loads the cached outputs of a wallet,
+// an empty cache is returned in case the wallet wasn't synced yet
+func loadCache(name string) (*outputCache, error) {
+	cache := newOutputCache()
+	file, err := os.Open(filepath.Join(Dir(name), cacheFileName))
+	if os.IsNotExist(err) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var data cachePersist
+	if err = json.NewDecoder(file).Decode(&data); err != nil {
+		// an invalid cache is simply synced again
+		return cache, nil
+	}
+	cache.synced, cache.height, cache.blockID = true, data.Height, data.BlockID
+	for _, address := range data.Addresses {
+		cache.addresses[address] = struct{}{}
+	}
+	for _, co := range data.Outputs {
+		cache.outputs[co.ID] = co
+	}
+	return cache, nil
+}
+
+// removeCache removes the cached outputs of a wallet, if they exist
+func removeCache(name string) error {
+	err := os.Remove(filepath.Join(Dir(name), cacheFileName))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package wallet
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/threefoldfoundation/tfchain/cmd/tfchaint/explorer"
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/pkg/api"
+	"github.com/threefoldtech/rivine/types"
+)
+
+func TestCacheSync(t *testing.T) {
+	home, err := ioutil.TempDir("", "tfchaint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+	defer os.Setenv("HOME", os.Getenv("HOME"))
+	os.Setenv("HOME", home)
+	if err = os.MkdirAll(Dir("cached"), walletDirPerm); err != nil {
+		t.Fatal(err)
+	}
+
+	addr := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: crypto.Hash{1}}
+	other := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: crypto.Hash{2}}
+	chain := &testChain{}
+	chain.addBlock(1, other, nil)
+	first := chain.addBlock(2, addr, nil)
+	chain.addBlock(3, other, nil)
+
+	w := &Wallet{
+		name:      "cached",
+		backend:   chain,
+		addresses: []types.UnlockHash{addr},
+	}
+	assertOutputs := func(expected ...types.CoinOutputID) {
+		t.Helper()
+		outputs, err := w.getUnspentCoinOutputs()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(outputs) != len(expected) {
+			t.Fatalf("expected %d outputs, but got %d", len(expected), len(outputs))
+		}
+		for _, id := range expected {
+			if _, ok := outputs[id]; !ok {
+				t.Fatalf("expected output %v to be unspent", id)
+			}
+		}
+	}
+
+	// the initial sync fetches the full history of the address
+	assertOutputs(first)
+	if chain.addressChecks != 1 {
+		t.Fatalf("expected 1 address check, but got %d", chain.addressChecks)
+	}
+
+	// new blocks are synced incrementally, also when the wallet is loaded again
+	second := chain.addBlock(4, addr, []types.CoinOutputID{first})
+	w = &Wallet{
+		name:      "cached",
+		backend:   chain,
+		addresses: []types.UnlockHash{addr},
+	}
+	assertOutputs(second)
+	if chain.addressChecks != 1 {
+		t.Fatalf("expected no additional address checks, but got %d", chain.addressChecks-1)
+	}
+
+	// a reorg of the synced block discards the cache
+	chain.blocks = chain.blocks[:len(chain.blocks)-1]
+	third := chain.addBlock(5, addr, nil)
+	assertOutputs(first, third)
+	if chain.addressChecks != 2 {
+		t.Fatalf("expected the address to be checked again, but got %d checks", chain.addressChecks)
+	}
+
+	// resyncing discards the cache as well
+	if err = w.Resync(); err != nil {
+		t.Fatal(err)
+	}
+	assertOutputs(first, third)
+	if chain.addressChecks != 3 {
+		t.Fatalf("expected the address to be checked again, but got %d checks", chain.addressChecks)
+	}
+}
+
+// testChain is a backend which serves a chain of blocks kept in memory,
+// each block containing a single transaction
+type testChain struct {
+	testBackend
+	blocks        []api.ExplorerBlock
+	addressChecks int
+	sent          []types.Transaction
+}
+
+// addBlock adds a block with a transaction spending the given outputs and
+// creating a single output for the given address, returning the ID of that output
+func (chain *testChain) addBlock(seed byte, address types.UnlockHash, spent []types.CoinOutputID) types.CoinOutputID {
+	return chain.addConditionBlock(seed, types.NewUnlockHashCondition(address), spent)
+}
+
+// addConditionBlock adds a block like addBlock does,
+// creating an output locked by the given condition instead
+func (chain *testChain) addConditionBlock(seed byte, condition types.MarshalableUnlockCondition, spent []types.CoinOutputID) types.CoinOutputID {
+	txn := types.Transaction{
+		Version: types.TransactionVersionOne,
+		CoinOutputs: []types.CoinOutput{{
+			Value:     types.NewCurrency64(uint64(seed)),
+			Condition: types.NewCondition(condition),
+		}},
+	}
+	for _, id := range spent {
+		txn.CoinInputs = append(txn.CoinInputs, types.CoinInput{ParentID: id})
+	}
+	id := types.CoinOutputID{seed}
+	height := types.BlockHeight(len(chain.blocks))
+	chain.blocks = append(chain.blocks, api.ExplorerBlock{
+		BlockFacts: modules.BlockFacts{
+			BlockID: types.BlockID{seed},
+			Height:  height,
+		},
+		Transactions: []api.ExplorerTransaction{{
+			ID:             types.TransactionID{seed},
+			Height:         height,
+			RawTransaction: txn,
+			CoinOutputIDs:  []types.CoinOutputID{id},
+		}},
+	})
+	return id
+}
+
+// CheckAddress returns all transactions of the chain, answering like an explorer does
+// for addresses it doesn't know about if the address isn't referenced by the chain
+func (chain *testChain) CheckAddress(address types.UnlockHash) ([]api.ExplorerBlock, []api.ExplorerTransaction, error) {
+	chain.addressChecks++
+	if !chain.references(address) {
+		return nil, nil, explorer.ErrUnrecognizedHash
+	}
+	var transactions []api.ExplorerTransaction
+	for _, block := range chain.blocks {
+		for _, txn := range block.Transactions {
+			transactions = append(transactions, txn)
+		}
+	}
+	return nil, transactions, nil
+}
+
+// references returns true if any output of the chain is sent to the given address
+func (chain *testChain) references(address types.UnlockHash) bool {
+	for _, block := range chain.blocks {
+		for _, txn := range block.Transactions {
+			for _, co := range txn.RawTransaction.CoinOutputs {
+				if co.Condition.UnlockHash() == address {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func (chain *testChain) GetBlock(height types.BlockHeight) (api.ExplorerBlock, error) {
+	return chain.blocks[height], nil
+}
+
+func (chain *testChain) CurrentHeight() (types.BlockHeight, error) {
+	return types.BlockHeight(len(chain.blocks) - 1), nil
+}
+
+func (chain *testChain) GetChainConstants() (modules.DaemonConstants, error) {
+	return modules.DaemonConstants{
+		MaturityDelay:             10,
+		MinimumTransactionFee:     types.NewCurrency64(1),
+		OneCoin:                   types.NewCurrency64(1),
+		DefaultTransactionVersion: types.TransactionVersionOne,
+	}, nil
+}
+
+func (chain *testChain) SendTxn(txn types.Transaction) (types.TransactionID, error) {
+	chain.sent = append(chain.sent, txn)
+	return txn.ID(), nil
+}
@@ -0,0 +1,235 @@
+package wallet
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/threefoldtech/rivine/types"
+)
+
+const (
+	// MaxTransactionInputs is the maximum amount of inputs used by a single transaction,
+	// keeping signed transactions well within the transaction size limit of 16 kB
+	MaxTransactionInputs = 80
+
+	// maxBranchAndBoundTries is the maximum amount of subsets explored by the branch and bound coin selector
+	maxBranchAndBoundTries = 100000
+)
+
+// Names of the available coin selection strategies
+const (
+	CoinSelectionLargestFirst   = "largest-first"
+	CoinSelectionSmallestFirst  = "smallest-first"
+	CoinSelectionBranchAndBound = "branch-and-bound"
+	CoinSelectionSingleAddress  = "single-address"
+)
+
+var (
+	// ErrTooManyInputs indicates that the transaction can't be funded without exceeding the maximum amount of inputs
+	ErrTooManyInputs = errors.New("Too many inputs are required to fund this transaction, consolidate the outputs of the wallet first")
+	// ErrNoSingleAddressFunds indicates that no single address of the wallet has sufficient funds to fund the transaction
+	ErrNoSingleAddressFunds = errors.New("No single address has sufficient funds to fund this transaction")
+)
+
+type (
+	// CoinSelector selects the coin outputs used to fund a transaction
+	CoinSelector interface {
+		// SelectCoins selects outputs with a total value of at least the required amount,
+		// using no more than maxInputs outputs
+		SelectCoins(outputs SpendableOutputs, required types.Currency, maxInputs int) (SpendableOutputs, error)
+	}
+
+	// LargestFirstSelector selects the largest outputs first,
+	// using as few inputs as possible
+	LargestFirstSelector struct{}
+
+	// SmallestFirstSelector selects the smallest outputs first,
+	// using as many inputs as allowed, which reduces the amount of dust in the wallet
+	SmallestFirstSelector struct{}
+
+	// BranchAndBoundSelector looks for a set of outputs which matches the required amount exactly,
+	// allowing the total value to exceed it by at most the tolerance, such that no change is created.
+	// The largest outputs are selected first in case no such set can be found.
+	BranchAndBoundSelector struct {
+		Tolerance types.Currency
+	}
+
+	// SingleAddressSelector only selects outputs of a single address, such that a transaction
+	// does not link multiple addresses of the wallet together. The largest outputs of the address
+	// able to fund the transaction with the fewest inputs are selected first.
+	SingleAddressSelector struct{}
+
+	// outputEntry is a spendable output and its ID, used to sort outputs by value
+	outputEntry struct {
+		id     types.CoinOutputID
+		output types.CoinOutput
+	}
+)
+
+// NewCoinSelector returns the coin selector for the strategy with the given name
+func NewCoinSelector(name string) (CoinSelector, error) {
+	switch name {
+	case CoinSelectionLargestFirst:
+		return LargestFirstSelector{}, nil
+	case CoinSelectionSmallestFirst:
+		return SmallestFirstSelector{}, nil
+	case CoinSelectionBranchAndBound:
+		return BranchAndBoundSelector{}, nil
+	case CoinSelectionSingleAddress:
+		return SingleAddressSelector{}, nil
+	default:
+		return nil, fmt.Errorf("unknown coin selection strategy %q", name)
+	}
+}
+
+// SelectCoins implements CoinSelector.SelectCoins
+func (LargestFirstSelector) SelectCoins(outputs SpendableOutputs, required types.Currency, maxInputs int) (SpendableOutputs, error) {
+	entries := sortOutputs(outputs, true)
+	selected := make(SpendableOutputs)
+	total := types.ZeroCurrency
+	for _, entry := range entries {
+		if total.Cmp(required) >= 0 {
+			break
+		}
+		if len(selected) == maxInputs {
+			return nil, ErrTooManyInputs
+		}
+		selected[entry.id] = entry.output
+		total = total.Add(entry.output.Value)
+	}
+	if total.Cmp(required) < 0 {
+		return nil, ErrInsufficientWalletFunds
+	}
+	return selected, nil
+}
+
+// SelectCoins implements CoinSelector.SelectCoins
+func (SmallestFirstSelector) SelectCoins(outputs SpendableOutputs, required types.Currency, maxInputs int) (SpendableOutputs, error) {
+	entries := sortOutputs(outputs, false)
+	// slide a window of at most maxInputs outputs over the outputs, from small to large,
+	// selecting the first window which has sufficient funds
+	total := types.ZeroCurrency
+	start := 0
+	for end, entry := range entries {
+		total = total.Add(entry.output.Value)
+		if end-start+1 > maxInputs {
+			total = total.Sub(entries[start].output.Value)
+			start++
+		}
+		if total.Cmp(required) >= 0 {
+			return entriesToOutputs(entries[start : end+1]), nil
+		}
+	}
+	if len(entries) > maxInputs {
+		return nil, ErrTooManyInputs
+	}
+	return nil, ErrInsufficientWalletFunds
+}
+
+// SelectCoins implements CoinSelector.SelectCoins
+func (selector BranchAndBoundSelector) SelectCoins(outputs SpendableOutputs, required types.Currency, maxInputs int) (SpendableOutputs, error) {
+	entries := sortOutputs(outputs, true)
+	upper := required.Add(selector.Tolerance)
+
+	// remaining[i] is the total value of all outputs starting from index i
+	remaining := make([]types.Currency, len(entries)+1)
+	remaining[len(entries)] = types.ZeroCurrency
+	for i := len(entries) - 1; i >= 0; i-- {
+		remaining[i] = remaining[i+1].Add(entries[i].output.Value)
+	}
+
+	var (
+		tries    int
+		selected []int
+		search   func(idx int, total types.Currency) bool
+	)
+	search = func(idx int, total types.Currency) bool {
+		if total.Cmp(required) >= 0 {
+			return total.Cmp(upper) <= 0
+		}
+		tries++
+		if idx == len(entries) || len(selected) == maxInputs || tries > maxBranchAndBoundTries {
+			return false
+		}
+		if total.Add(remaining[idx]).Cmp(required) < 0 {
+			// the remaining outputs can't fund the transaction
+			return false
+		}
+		// include the output, or skip it
+		selected = append(selected, idx)
+		if search(idx+1, total.Add(entries[idx].output.Value)) {
+			return true
+		}
+		selected = selected[:len(selected)-1]
+		return search(idx+1, total)
+	}
+	if !search(0, types.ZeroCurrency) {
+		return LargestFirstSelector{}.SelectCoins(outputs, required, maxInputs)
+	}
+
+	match := make(SpendableOutputs, len(selected))
+	for _, idx := range selected {
+		match[entries[idx].id] = entries[idx].output
+	}
+	return match, nil
+}
+
+// SelectCoins implements CoinSelector.SelectCoins
+func (SingleAddressSelector) SelectCoins(outputs SpendableOutputs, required types.Currency, maxInputs int) (SpendableOutputs, error) {
+	byAddress := make(map[types.UnlockHash]SpendableOutputs)
+	for id, co := range outputs {
+		uh := co.Condition.UnlockHash()
+		if _, ok := byAddress[uh]; !ok {
+			byAddress[uh] = make(SpendableOutputs)
+		}
+		byAddress[uh][id] = co
+	}
+	addresses := make([]types.UnlockHash, 0, len(byAddress))
+	for uh := range byAddress {
+		addresses = append(addresses, uh)
+	}
+	sort.Slice(addresses, func(i, j int) bool {
+		return addresses[i].Cmp(addresses[j]) < 0
+	})
+
+	var best SpendableOutputs
+	for _, uh := range addresses {
+		selected, err := LargestFirstSelector{}.SelectCoins(byAddress[uh], required, maxInputs)
+		if err != nil {
+			continue
+		}
+		if best == nil || len(selected) < len(best) {
+			best = selected
+		}
+	}
+	if best == nil {
+		return nil, ErrNoSingleAddressFunds
+	}
+	return best, nil
+}
+
+// sortOutputs returns the given outputs sorted by value,
+// using the output ID to order outputs of equal value
+func sortOutputs(outputs SpendableOutputs, descending bool) []outputEntry {
+	entries := make([]outputEntry, 0, len(outputs))
+	for id, co := range outputs {
+		entries = append(entries, outputEntry{id: id, output: co})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if c := entries[i].output.Value.Cmp(entries[j].output.Value); c != 0 {
+			return (c > 0) == descending
+		}
+		return bytes.Compare(entries[i].id[:], entries[j].id[:]) < 0
+	})
+	return entries
+}
+
+func entriesToOutputs(entries []outputEntry) SpendableOutputs {
+	outputs := make(SpendableOutputs, len(entries))
+	for _, entry := range entries {
+		outputs[entry.id] = entry.output
+	}
+	return outputs
+}
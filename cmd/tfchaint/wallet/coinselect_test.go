@@ -0,0 +1,68 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/types"
+)
+
+func TestCoinSelectors(t *testing.T) {
+	addrA := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: crypto.Hash{1}}
+	addrB := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: crypto.Hash{2}}
+	outputs := make(SpendableOutputs)
+	addOutput := func(id byte, value uint64, address types.UnlockHash) {
+		outputs[types.CoinOutputID{id}] = types.CoinOutput{
+			Value:     types.NewCurrency64(value),
+			Condition: types.NewCondition(types.NewUnlockHashCondition(address)),
+		}
+	}
+	addOutput(1, 1, addrA)
+	addOutput(2, 2, addrA)
+	addOutput(3, 5, addrB)
+	addOutput(4, 10, addrB)
+	addOutput(5, 20, addrA)
+
+	testCases := []struct {
+		selector  CoinSelector
+		required  uint64
+		maxInputs int
+		expected  []byte
+		err       error
+	}{
+		{LargestFirstSelector{}, 25, 10, []byte{5, 4}, nil},
+		{LargestFirstSelector{}, 25, 1, nil, ErrTooManyInputs},
+		{LargestFirstSelector{}, 39, 10, nil, ErrInsufficientWalletFunds},
+		{SmallestFirstSelector{}, 7, 10, []byte{1, 2, 3}, nil},
+		// the smallest outputs are skipped if too many inputs would be required
+		{SmallestFirstSelector{}, 7, 2, []byte{2, 3}, nil},
+		{SmallestFirstSelector{}, 31, 2, nil, ErrTooManyInputs},
+		{SmallestFirstSelector{}, 39, 10, nil, ErrInsufficientWalletFunds},
+		// an exact match is found, where largest first would select 20 and 10
+		{BranchAndBoundSelector{}, 26, 10, []byte{5, 3, 1}, nil},
+		{BranchAndBoundSelector{Tolerance: types.NewCurrency64(1)}, 22, 10, []byte{5, 2}, nil},
+		// without an exact match the largest outputs are selected first
+		{BranchAndBoundSelector{}, 39, 10, nil, ErrInsufficientWalletFunds},
+		{BranchAndBoundSelector{}, 26, 2, []byte{5, 4}, nil},
+		// the address requiring the fewest inputs is used
+		{SingleAddressSelector{}, 12, 10, []byte{5}, nil},
+		{SingleAddressSelector{}, 21, 10, []byte{5, 2}, nil},
+		{SingleAddressSelector{}, 24, 10, nil, ErrNoSingleAddressFunds},
+	}
+	for idx, testCase := range testCases {
+		selected, err := testCase.selector.SelectCoins(outputs, types.NewCurrency64(testCase.required), testCase.maxInputs)
+		if err != testCase.err {
+			t.Errorf("test case #%d: expected error %v, but got %v", idx, testCase.err, err)
+			continue
+		}
+		if len(selected) != len(testCase.expected) {
+			t.Errorf("test case #%d: expected %d outputs, but got %d", idx, len(testCase.expected), len(selected))
+			continue
+		}
+		for _, id := range testCase.expected {
+			if _, ok := selected[types.CoinOutputID{id}]; !ok {
+				t.Errorf("test case #%d: expected output %d to be selected", idx, id)
+			}
+		}
+	}
+}
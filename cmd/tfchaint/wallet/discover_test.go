@@ -0,0 +1,98 @@
+package wallet
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/threefoldfoundation/tfchain/cmd/tfchaint/explorer"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/pkg/api"
+	"github.com/threefoldtech/rivine/types"
+)
+
+func TestDiscoverKeys(t *testing.T) {
+	home, err := ioutil.TempDir("", "tfchaint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+	defer os.Setenv("HOME", os.Getenv("HOME"))
+	os.Setenv("HOME", home)
+
+	seed := modules.Seed{4, 2}
+	backend := usedAddressesBackend{used: make(map[types.UnlockHash]bool)}
+	// use the addresses with index 2 and 7, leaving a gap of 4 unused addresses
+	for _, index := range []uint64{2, 7} {
+		key, err := generateSpendableKey(seed, index)
+		if err != nil {
+			t.Fatal(err)
+		}
+		uh, err := key.UnlockHash()
+		if err != nil {
+			t.Fatal(err)
+		}
+		backend.used[uh] = true
+	}
+
+	w := &Wallet{
+		seed:     seed,
+		unlocked: true,
+		name:     "discover",
+		backend:  backend,
+	}
+	if err = w.generateKeys(1); err != nil {
+		t.Fatal(err)
+	}
+
+	// a gap limit of 4 stops discovering after the third address
+	keys, err := w.DiscoverKeys(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys != 3 || len(w.ListAddresses()) != 3 {
+		t.Fatalf("expected 3 keys to be loaded, but got %d", keys)
+	}
+
+	// a gap limit of 5 finds the eighth address as well
+	keys, err = w.DiscoverKeys(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys != 8 || len(w.ListAddresses()) != 8 {
+		t.Fatalf("expected 8 keys to be loaded, but got %d", keys)
+	}
+
+	// the discovered amount of keys is persisted
+	data, err := load("discover")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.KeysToLoad != 8 {
+		t.Fatalf("expected 8 keys to be persisted, but got %d", data.KeysToLoad)
+	}
+
+	// already loaded keys are never removed
+	keys, err = w.DiscoverKeys(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys != 8 {
+		t.Fatalf("expected 8 keys to remain loaded, but got %d", keys)
+	}
+}
+
+// usedAddressesBackend is a backend which only knows whether or not an address is used,
+// answering like an explorer does for unused addresses
+type usedAddressesBackend struct {
+	testBackend
+	used map[types.UnlockHash]bool
+}
+
+func (backend usedAddressesBackend) CheckAddress(address types.UnlockHash) ([]api.ExplorerBlock, []api.ExplorerTransaction, error) {
+	if !backend.used[address] {
+		return nil, nil, explorer.ErrUnrecognizedHash
+	}
+	return nil, []api.ExplorerTransaction{{}}, nil
+}
@@ -0,0 +1,142 @@
+package wallet
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"github.com/threefoldtech/rivine/modules"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// seedKDF is the name of the key derivation function used to derive
+	// the encryption key of the seed from the passphrase
+	seedKDF = "scrypt"
+	// seedCipher is the name of the authenticated encryption scheme used to encrypt the seed
+	seedCipher = "aes-256-gcm"
+
+	// default scrypt parameters, as recommended for interactive logins
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+	scryptSalt   = 32
+)
+
+var (
+	// ErrEmptyPassphrase indicates that no passphrase was given to encrypt the seed with
+	ErrEmptyPassphrase = errors.New("A non-empty passphrase is required")
+	// ErrInvalidPassphrase indicates that the seed could not be decrypted using the given passphrase
+	ErrInvalidPassphrase = errors.New("Invalid passphrase")
+)
+
+type (
+	// encryptedSeed is the persisted form of a seed,
+	// encrypted using a key derived from the passphrase of the wallet
+	encryptedSeed struct {
+		KDF        string    `json:"kdf"`
+		KDFParams  kdfParams `json:"kdfparams"`
+		Cipher     string    `json:"cipher"`
+		Nonce      []byte    `json:"nonce"`
+		Ciphertext []byte    `json:"ciphertext"`
+	}
+
+	// kdfParams are the scrypt parameters used to derive the key from the passphrase
+	kdfParams struct {
+		N    int    `json:"n"`
+		R    int    `json:"r"`
+		P    int    `json:"p"`
+		Salt []byte `json:"salt"`
+	}
+)
+
+// encryptSeed encrypts a seed using a key derived from the given passphrase,
+// using a newly generated salt. The derived key is returned as well,
+// such that it can be cached as part of a session.
+func encryptSeed(seed modules.Seed, passphrase string) (*encryptedSeed, []byte, error) {
+	if passphrase == "" {
+		return nil, nil, ErrEmptyPassphrase
+	}
+	params := kdfParams{
+		N:    scryptN,
+		R:    scryptR,
+		P:    scryptP,
+		Salt: make([]byte, scryptSalt),
+	}
+	if _, err := rand.Read(params.Salt); err != nil {
+		return nil, nil, err
+	}
+	key, err := params.deriveKey(passphrase)
+	if err != nil {
+		return nil, nil, err
+	}
+	es := &encryptedSeed{
+		KDF:       seedKDF,
+		KDFParams: params,
+		Cipher:    seedCipher,
+	}
+	aead, err := newSeedAEAD(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	es.Nonce = make([]byte, aead.NonceSize())
+	if _, err = rand.Read(es.Nonce); err != nil {
+		return nil, nil, err
+	}
+	es.Ciphertext = aead.Seal(nil, es.Nonce, seed[:], nil)
+	return es, key, nil
+}
+
+// decrypt decrypts the seed using a key derived from the given passphrase,
+// returning the derived key as well, such that it can be cached as part of a session.
+func (es *encryptedSeed) decrypt(passphrase string) (modules.Seed, []byte, error) {
+	if es.KDF != seedKDF {
+		return modules.Seed{}, nil, fmt.Errorf("unsupported key derivation function %q", es.KDF)
+	}
+	key, err := es.KDFParams.deriveKey(passphrase)
+	if err != nil {
+		return modules.Seed{}, nil, err
+	}
+	seed, err := es.decryptWithKey(key)
+	return seed, key, err
+}
+
+// decryptWithKey decrypts the seed using an already derived key.
+func (es *encryptedSeed) decryptWithKey(key []byte) (modules.Seed, error) {
+	if es.Cipher != seedCipher {
+		return modules.Seed{}, fmt.Errorf("unsupported cipher %q", es.Cipher)
+	}
+	aead, err := newSeedAEAD(key)
+	if err != nil {
+		return modules.Seed{}, err
+	}
+	if len(es.Nonce) != aead.NonceSize() {
+		return modules.Seed{}, errors.New("invalid nonce size")
+	}
+	plaintext, err := aead.Open(nil, es.Nonce, es.Ciphertext, nil)
+	if err != nil {
+		// authentication failed, which is almost always caused by a wrong passphrase
+		return modules.Seed{}, ErrInvalidPassphrase
+	}
+	var seed modules.Seed
+	if len(plaintext) != len(seed) {
+		return modules.Seed{}, errors.New("invalid seed size")
+	}
+	copy(seed[:], plaintext)
+	return seed, nil
+}
+
+func (params kdfParams) deriveKey(passphrase string) ([]byte, error) {
+	return scrypt.Key([]byte(passphrase), params.Salt, params.N, params.R, params.P, scryptKeyLen)
+}
+
+func newSeedAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
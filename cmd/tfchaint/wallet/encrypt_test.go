@@ -0,0 +1,176 @@
+package wallet
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/threefoldtech/rivine/modules"
+)
+
+func TestEncryptSeed(t *testing.T) {
+	seed := modules.Seed{1, 2, 3, 4}
+	es, key, err := encryptSeed(seed, "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	decrypted, decryptedKey, err := es.decrypt("passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decrypted != seed {
+		t.Error("decrypted seed does not match the original seed")
+	}
+	if string(decryptedKey) != string(key) {
+		t.Error("derived key does not match the original key")
+	}
+	if _, _, err = es.decrypt("wrong passphrase"); err != ErrInvalidPassphrase {
+		t.Errorf("expected invalid passphrase error, but got: %v", err)
+	}
+	es.Ciphertext[0] ^= 1
+	if _, err = es.decryptWithKey(key); err == nil {
+		t.Error("expected tampered ciphertext to fail authentication")
+	}
+	if _, _, err = encryptSeed(seed, ""); err != ErrEmptyPassphrase {
+		t.Errorf("expected empty passphrase error, but got: %v", err)
+	}
+}
+
+func TestLegacyWalletMigration(t *testing.T) {
+	home, err := ioutil.TempDir("", "tfchaint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+	defer os.Setenv("HOME", os.Getenv("HOME"))
+	os.Setenv("HOME", home)
+	defer os.Setenv("XDG_RUNTIME_DIR", os.Getenv("XDG_RUNTIME_DIR"))
+	os.Setenv("XDG_RUNTIME_DIR", filepath.Join(home, "run"))
+
+	// store a legacy wallet, using an unencrypted seed
+	seed := modules.Seed{4, 2}
+	err = os.MkdirAll(Dir("legacy"), 0777)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(walletPersist{Seed: &seed, KeysToLoad: 2, Backend: "devnet"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(filepath.Join(Dir("legacy"), walletFileName), b, 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := loadTestWallet("legacy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.IsEncrypted() || w.IsLocked() {
+		t.Fatal("legacy wallet is expected to be unencrypted and unlocked")
+	}
+	addresses := w.ListAddresses()
+	if len(addresses) != 2 {
+		t.Fatalf("expected 2 addresses, but got %d", len(addresses))
+	}
+	if err = w.ChangePassphrase("passphrase"); err != nil {
+		t.Fatal(err)
+	}
+
+	// the seed should no longer be stored unencrypted
+	data, err := load("legacy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.Seed != nil || data.EncryptedSeed == nil {
+		t.Fatal("migrated wallet is expected to only store its seed encrypted")
+	}
+	info, err := os.Stat(filepath.Join(Dir("legacy"), walletFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != walletFilePerm {
+		t.Errorf("unexpected wallet file permissions: %v", perm)
+	}
+
+	// the migrated wallet is loaded locked, but its addresses are still known
+	w, err = loadTestWallet("legacy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !w.IsEncrypted() || !w.IsLocked() {
+		t.Fatal("migrated wallet is expected to be encrypted and locked")
+	}
+	if len(w.ListAddresses()) != len(addresses) || w.ListAddresses()[0] != addresses[0] {
+		t.Fatalf("unexpected addresses: %v", w.ListAddresses())
+	}
+	if _, err = w.Mnemonic(); err != ErrWalletLocked {
+		t.Errorf("expected locked wallet error, but got: %v", err)
+	}
+	if err = w.Unlock("wrong passphrase"); err != ErrInvalidPassphrase {
+		t.Errorf("expected invalid passphrase error, but got: %v", err)
+	}
+	if err = w.Unlock("passphrase"); err != nil {
+		t.Fatal(err)
+	}
+	if w.seed != seed {
+		t.Error("unlocked seed does not match the original seed")
+	}
+
+	// an active session unlocks the wallet when loaded, until it is locked
+	if err = w.StartSession(time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	b, err = ioutil.ReadFile(filepath.Join(Dir("legacy"), sessionFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(b, w.key) || strings.Contains(string(b), base64.StdEncoding.EncodeToString(w.key)) {
+		t.Error("session is expected to only store the key encrypted")
+	}
+	w, err = loadTestWallet("legacy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.IsLocked() {
+		t.Error("wallet is expected to be unlocked by its session")
+	}
+	if err = w.Lock(); err != nil {
+		t.Fatal(err)
+	}
+	w, err = loadTestWallet("legacy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !w.IsLocked() {
+		t.Error("wallet is expected to be locked once its session ended")
+	}
+	if _, err = os.Stat(sessionSecretPath("legacy")); !os.IsNotExist(err) {
+		t.Error("session secret is expected to be removed once the session ended")
+	}
+}
+
+// loadTestWallet loads a wallet like Load does, without connecting to an explorer
+func loadTestWallet(name string) (*Wallet, error) {
+	data, err := load(name)
+	if err != nil {
+		return nil, err
+	}
+	w := &Wallet{
+		name:    name,
+		backend: testBackend{},
+	}
+	return w, w.restore(data)
+}
+
+type testBackend struct {
+	Backend
+}
+
+func (testBackend) Name() string { return "devnet" }
@@ -0,0 +1,149 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/threefoldfoundation/tfchain/cmd/tfchaint/explorer"
+	tftypes "github.com/threefoldfoundation/tfchain/pkg/types"
+	erc20types "github.com/threefoldtech/rivine-extension-erc20/types"
+	"github.com/threefoldtech/rivine/types"
+)
+
+var (
+	// ErrNoERC20Registry indicates that the backend of the wallet can't look up registered ERC20 addresses
+	ErrNoERC20Registry = errors.New("The backend of the wallet can't look up ERC20 addresses")
+	// ErrNotKeyOwner indicates that a public key is not a key of the wallet
+	ErrNotKeyOwner = errors.New("The public key is not a key of the wallet")
+
+	// ensure at compile time that the explorers can look up registered ERC20 addresses
+	_ ERC20Registry = (*explorer.GroupedExplorer)(nil)
+)
+
+// ERC20AddressInfo links an address of the wallet to the ERC20 address derived from it
+type ERC20AddressInfo struct {
+	Address      types.UnlockHash
+	ERC20Address erc20types.ERC20Address
+	// Registered is true if the ERC20 address is registered, such that ERC20 funds can be converted back to it
+	Registered bool
+	// Confirmations is the amount of blocks created since (and including) the block
+	// containing the registration, zero if the registration is not confirmed yet
+	Confirmations uint64
+}
+
+// RegisterERC20Address registers the ERC20 withdrawal address derived from the given public key,
+// which has to be a key of the wallet, or from a newly generated key of the wallet if none is given.
+// Once registered, ERC20 funds sent to the ERC20 address are converted to TFT sent to the address of the key.
+// The registration and transaction fees are paid by the wallet.
+func (w *Wallet) RegisterERC20Address(pk *types.PublicKey) (types.TransactionID, ERC20AddressInfo, error) {
+	if err := w.checkCanSign(); err != nil {
+		return types.TransactionID{}, ERC20AddressInfo{}, err
+	}
+	if pk != nil && !w.ownsKey(*pk) {
+		return types.TransactionID{}, ERC20AddressInfo{}, ErrNotKeyOwner
+	}
+	key, err := w.identityKey(pk)
+	if err != nil {
+		return types.TransactionID{}, ERC20AddressInfo{}, err
+	}
+	info, err := newERC20AddressInfo(key)
+	if err != nil {
+		return types.TransactionID{}, ERC20AddressInfo{}, err
+	}
+
+	chainCts, err := w.backend.GetChainConstants()
+	if err != nil {
+		return types.TransactionID{}, ERC20AddressInfo{}, err
+	}
+	tx := erc20types.ERC20AddressRegistrationTransaction{
+		PublicKey:       key,
+		RegistrationFee: chainCts.OneCoin.Mul64(erc20types.HardcodedERC20AddressRegistrationFeeOneCoinMultiplier),
+	}
+	// the transaction is signed by the key of the ERC20 address once funded
+	txn, outputs, err := w.fundTransaction(chainCts, tx.RegistrationFee, false, 1, func(inputs []types.CoinInput, refund *types.CoinOutput, fee types.Currency) types.Transaction {
+		tx.CoinInputs, tx.RefundCoinOutput, tx.TransactionFee = inputs, refund, fee
+		return tx.Transaction(tftypes.TransactionVersionERC20AddressRegistration)
+	})
+	if err != nil {
+		return types.TransactionID{}, ERC20AddressInfo{}, err
+	}
+
+	txnID, err := w.signAndSendTransaction(txn, outputs)
+	return txnID, info, err
+}
+
+// ConvertToERC20 converts the given amount of TFT to ERC20 funds sent to the given ERC20 address,
+// the bridge deducts its fees from the converted amount. The transaction fee is paid by the wallet on top of the amount.
+func (w *Wallet) ConvertToERC20(address erc20types.ERC20Address, amount types.Currency) (types.TransactionID, error) {
+	if err := w.checkCanSign(); err != nil {
+		return types.TransactionID{}, err
+	}
+	chainCts, err := w.backend.GetChainConstants()
+	if err != nil {
+		return types.TransactionID{}, err
+	}
+	if minimum := chainCts.OneCoin.Mul64(erc20types.ERC20ConversionMinimumValue); amount.Cmp(minimum) < 0 {
+		return types.TransactionID{}, fmt.Errorf("at least %d coins have to be converted", erc20types.ERC20ConversionMinimumValue)
+	}
+
+	tx := erc20types.ERC20ConvertTransaction{
+		Address: address,
+		Value:   amount,
+	}
+	txn, outputs, err := w.fundTransaction(chainCts, tx.Value, false, 0, func(inputs []types.CoinInput, refund *types.CoinOutput, fee types.Currency) types.Transaction {
+		tx.CoinInputs, tx.RefundCoinOutput, tx.TransactionFee = inputs, refund, fee
+		return tx.Transaction(tftypes.TransactionVersionERC20Conversion)
+	})
+	if err != nil {
+		return types.TransactionID{}, err
+	}
+
+	return w.signAndSendTransaction(txn, outputs)
+}
+
+// ERC20Addresses returns, for every loaded address of the wallet, the ERC20 address derived from it,
+// and whether or not it is registered. It does not require the wallet to be unlocked.
+func (w *Wallet) ERC20Addresses() ([]ERC20AddressInfo, error) {
+	registry, ok := w.backend.(ERC20Registry)
+	if !ok {
+		return nil, ErrNoERC20Registry
+	}
+	infos := make([]ERC20AddressInfo, 0, len(w.addresses))
+	for _, address := range w.addresses {
+		erc20Address, err := erc20types.ERC20AddressFromUnlockHash(address)
+		if err != nil {
+			return nil, err
+		}
+		info := ERC20AddressInfo{
+			Address:      address,
+			ERC20Address: erc20Address,
+		}
+		registered, err := registry.GetERC20Info(address)
+		if err == explorer.ErrUnrecognizedHash {
+			// an address the explorer doesn't know about can't be registered either
+			registered, err = nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if registered != nil && registered.ERC20Address == erc20Address {
+			info.Registered = true
+			info.Confirmations = registered.Confirmations
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// newERC20AddressInfo creates the (unregistered) info of the ERC20 address derived from the given public key
+func newERC20AddressInfo(pk types.PublicKey) (ERC20AddressInfo, error) {
+	uh, err := types.NewPubKeyUnlockHash(pk)
+	if err != nil {
+		return ERC20AddressInfo{}, err
+	}
+	erc20Address, err := erc20types.ERC20AddressFromUnlockHash(uh)
+	if err != nil {
+		return ERC20AddressInfo{}, err
+	}
+	return ERC20AddressInfo{Address: uh, ERC20Address: erc20Address}, nil
+}
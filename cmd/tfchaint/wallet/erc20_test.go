@@ -0,0 +1,110 @@
+package wallet
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/threefoldfoundation/tfchain/cmd/tfchaint/explorer"
+	tfapi "github.com/threefoldfoundation/tfchain/pkg/api"
+	tftypes "github.com/threefoldfoundation/tfchain/pkg/types"
+	erc20types "github.com/threefoldtech/rivine-extension-erc20/types"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+func TestERC20Transactions(t *testing.T) {
+	home, err := ioutil.TempDir("", "tfchaint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+	defer os.Setenv("HOME", os.Getenv("HOME"))
+	os.Setenv("HOME", home)
+	if err = os.MkdirAll(Dir("erc20"), walletDirPerm); err != nil {
+		t.Fatal(err)
+	}
+
+	w := &Wallet{
+		seed:     modules.Seed{6},
+		unlocked: true,
+		name:     "erc20",
+	}
+	if err = w.generateKeys(1); err != nil {
+		t.Fatal(err)
+	}
+	chain := &erc20Chain{testChain: &testChain{}, registered: make(map[types.UnlockHash]*tfapi.ExplorerHashERC20Info)}
+	w.backend = chain
+	chain.addBlock(100, w.firstAddress, nil)
+
+	// the registration signature is created by the registered transaction controller
+	types.RegisterTransactionVersion(tftypes.TransactionVersionERC20AddressRegistration, erc20types.ERC20AddressRegistrationTransactionController{
+		TransactionVersion: tftypes.TransactionVersionERC20AddressRegistration,
+		OneCoin:            types.NewCurrency64(1),
+	})
+	defer types.RegisterTransactionVersion(tftypes.TransactionVersionERC20AddressRegistration, nil)
+
+	// registering an ERC20 address pays the registration fee on top of the transaction fee,
+	// and derives the ERC20 address from a new key of the wallet
+	_, info, err := w.RegisterERC20Address(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(w.addresses) != 2 || w.addresses[1] != info.Address {
+		t.Fatalf("expected the ERC20 address to be derived from a newly generated address")
+	}
+	registration, err := erc20types.ERC20AddressRegistrationTransactionFromTransaction(chain.sent[0], tftypes.TransactionVersionERC20AddressRegistration)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(registration.Signature) == 0 {
+		t.Errorf("expected the registration to be signed by the key of the ERC20 address")
+	}
+	if registration.RefundCoinOutput == nil || !registration.RefundCoinOutput.Value.Equals64(100-10-1) {
+		t.Errorf("expected a refund of %d, but got %v", 100-10-1, registration.RefundCoinOutput)
+	}
+
+	// only the registered ERC20 address is listed as such
+	chain.registered[info.Address] = &tfapi.ExplorerHashERC20Info{TFTAddress: info.Address, ERC20Address: info.ERC20Address, Confirmations: 2}
+	infos, err := w.ERC20Addresses()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 2 || infos[0].Registered || !infos[1].Registered || infos[1].Confirmations != 2 {
+		t.Errorf("expected only the second address to be registered, but got %v", infos)
+	}
+
+	// an address unknown to the explorer is listed as not registered
+	if _, err = w.generateNewAddress(); err != nil {
+		t.Fatal(err)
+	}
+	if infos, err = w.ERC20Addresses(); err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 3 || infos[2].Registered {
+		t.Errorf("expected the unused third address to not be registered, but got %v", infos)
+	}
+
+	// conversions require a minimum amount
+	if _, err = w.ConvertToERC20(info.ERC20Address, types.NewCurrency64(erc20types.ERC20ConversionMinimumValue-1)); err == nil {
+		t.Errorf("expected a conversion below the minimum amount to be rejected")
+	}
+}
+
+// erc20Chain is a testChain which serves registered ERC20 addresses kept in memory
+type erc20Chain struct {
+	*testChain
+	registered map[types.UnlockHash]*tfapi.ExplorerHashERC20Info
+}
+
+// GetERC20Info answers like an explorer does for addresses it doesn't know about
+// if the address is neither registered nor referenced by the chain
+func (chain *erc20Chain) GetERC20Info(addr types.UnlockHash) (*tfapi.ExplorerHashERC20Info, error) {
+	if info, ok := chain.registered[addr]; ok {
+		return info, nil
+	}
+	if !chain.references(addr) {
+		return nil, explorer.ErrUnrecognizedHash
+	}
+	return nil, nil
+}
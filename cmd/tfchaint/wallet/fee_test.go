@@ -0,0 +1,206 @@
+package wallet
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	tbtypes "github.com/threefoldfoundation/tfchain/extensions/threebot/types"
+	tfapi "github.com/threefoldfoundation/tfchain/pkg/api"
+	"github.com/threefoldfoundation/tfchain/pkg/feemarket"
+	tftypes "github.com/threefoldfoundation/tfchain/pkg/types"
+	erc20types "github.com/threefoldtech/rivine-extension-erc20/types"
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+func TestTransactionFees(t *testing.T) {
+	home, err := ioutil.TempDir("", "tfchaint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+	defer os.Setenv("HOME", os.Getenv("HOME"))
+	os.Setenv("HOME", home)
+
+	// newWallet creates a wallet owning an output for every value in the inclusive range [from, to]
+	newWallet := func(name string, from, to byte) (*Wallet, *testChain) {
+		if err := os.MkdirAll(Dir(name), walletDirPerm); err != nil {
+			t.Fatal(err)
+		}
+		w := &Wallet{
+			seed:     modules.Seed{4, 4},
+			unlocked: true,
+			name:     name,
+		}
+		if err := w.generateKeys(1); err != nil {
+			t.Fatal(err)
+		}
+		chain := &testChain{}
+		for value := from; value <= to; value++ {
+			chain.addBlock(value, w.firstAddress, nil)
+		}
+		w.backend = chain
+		return w, chain
+	}
+	// assertFee checks that the signed transaction pays exactly the fee required by its size
+	assertFee := func(txn types.Transaction) {
+		t.Helper()
+		size, err := feemarket.TransactionSize(txn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		required := feemarket.RequiredMinerFee(size, types.NewCurrency64(1))
+		if len(txn.MinerFees) != 1 || !txn.MinerFees[0].Equals(required) {
+			t.Errorf("expected a fee of %v for %d bytes, but got %v", required, size, txn.MinerFees)
+		}
+		if required.Equals64(1) {
+			t.Errorf("expected a transaction of %d bytes to require more than the minimum fee", size)
+		}
+	}
+
+	// a transaction using many inputs pays the fee required by its signed size
+	w, chain := newWallet("transfer", 10, 49)
+	target := types.NewCondition(types.NewUnlockHashCondition(types.UnlockHash{Type: types.UnlockTypePubKey, Hash: crypto.Hash{1}}))
+	if _, err = w.TransferCoins(types.NewCurrency64(600), target, nil, false); err != nil {
+		t.Fatal(err)
+	}
+	if len(chain.sent) != 1 {
+		t.Fatalf("expected 1 transaction to be sent, but got %d", len(chain.sent))
+	}
+	assertFee(chain.sent[0])
+	if !chain.sent[0].CoinOutputs[0].Value.Equals64(600) {
+		t.Errorf("expected 600 to be sent, but got %v", chain.sent[0].CoinOutputs[0].Value)
+	}
+
+	// all outputs are spent if the fee can't be paid otherwise
+	w, chain = newWallet("exact", 10, 25)
+	if _, err = w.TransferCoins(types.NewCurrency64(280-3), target, nil, false); err != nil {
+		t.Fatal(err)
+	}
+	assertFee(chain.sent[0])
+	if len(chain.sent[0].CoinInputs) != 16 || len(chain.sent[0].CoinOutputs) != 1 {
+		t.Errorf("expected all 16 outputs to be spent without refund, but got %d inputs and %d outputs",
+			len(chain.sent[0].CoinInputs), len(chain.sent[0].CoinOutputs))
+	}
+
+	// consolidated outputs pay the fee required by the size of the transaction
+	w, chain = newWallet("consolidate", 10, 49)
+	if _, err = w.Consolidate(types.ZeroCurrency); err != nil {
+		t.Fatal(err)
+	}
+	if len(chain.sent) != 1 {
+		t.Fatalf("expected 1 transaction to be sent, but got %d", len(chain.sent))
+	}
+	assertFee(chain.sent[0])
+
+	// 3bot transactions pay the fee required by their size, including the 3bot signatures
+	w, chain = newWallet("3bot", 1, 30)
+	bots := &botChain{testChain: chain, records: make(map[tbtypes.BotID]*tbtypes.BotRecord)}
+	w.backend = bots
+	for v, c := range map[types.TransactionVersion]types.TransactionController{
+		tbtypes.TransactionVersionBotRegistration: tbtypes.BotRegistrationTransactionController{Registry: bots, OneCoin: types.NewCurrency64(1)},
+		tbtypes.TransactionVersionBotRecordUpdate: tbtypes.BotUpdateRecordTransactionController{Registry: bots, OneCoin: types.NewCurrency64(1)},
+	} {
+		types.RegisterTransactionVersion(v, c)
+		defer types.RegisterTransactionVersion(v, nil)
+	}
+	name, err := tbtypes.NewBotName("feepayer.tfbot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = w.RegisterBot(nil, []tbtypes.BotName{name}, 12, nil); err != nil {
+		t.Fatal(err)
+	}
+	assertFee(chain.sent[0])
+	w, chain = newWallet("3botupdate", 1, 15)
+	bots.testChain, w.backend = chain, bots
+	bots.records[1] = &tbtypes.BotRecord{ID: 1, PublicKey: w.publicKeys[w.firstAddress]}
+	if _, err = w.UpdateBot(1, tbtypes.BotRecordAddressUpdate{}, tbtypes.BotRecordNameUpdate{}, 11); err != nil {
+		t.Fatal(err)
+	}
+	assertFee(chain.sent[0])
+
+	// ERC20 transactions pay the fee required by their size, including the registration signature
+	w, chain = newWallet("erc20", 1, 15)
+	// outputs of a single coin each, such that the registration fee is funded by many inputs
+	for _, block := range chain.blocks {
+		block.Transactions[0].RawTransaction.CoinOutputs[0].Value = types.NewCurrency64(1)
+	}
+	w.backend = &erc20Chain{testChain: chain, registered: make(map[types.UnlockHash]*tfapi.ExplorerHashERC20Info)}
+	for v, c := range map[types.TransactionVersion]types.TransactionController{
+		tftypes.TransactionVersionERC20AddressRegistration: erc20types.ERC20AddressRegistrationTransactionController{
+			TransactionVersion: tftypes.TransactionVersionERC20AddressRegistration,
+			OneCoin:            types.NewCurrency64(1),
+		},
+		tftypes.TransactionVersionERC20Conversion: erc20types.ERC20ConvertTransactionController{
+			TransactionVersion: tftypes.TransactionVersionERC20Conversion,
+		},
+	} {
+		types.RegisterTransactionVersion(v, c)
+		defer types.RegisterTransactionVersion(v, nil)
+	}
+	if _, _, err = w.RegisterERC20Address(nil); err != nil {
+		t.Fatal(err)
+	}
+	assertFee(chain.sent[0])
+	w, chain = newWallet("erc20convert", 60, 120)
+	if _, err = w.ConvertToERC20(erc20types.ERC20Address{1}, types.NewCurrency64(erc20types.ERC20ConversionMinimumValue)); err != nil {
+		t.Fatal(err)
+	}
+	assertFee(chain.sent[0])
+}
+
+func TestMultiSigTransactionFee(t *testing.T) {
+	chain := &testChain{}
+	newWallet := func(name string, seed modules.Seed) *Wallet {
+		w := &Wallet{
+			seed:     seed,
+			unlocked: true,
+			name:     name,
+			backend:  chain,
+		}
+		if err := w.generateKeys(1); err != nil {
+			t.Fatal(err)
+		}
+		return w
+	}
+	alice, bob := newWallet("alice", modules.Seed{1}), newWallet("bob", modules.Seed{2})
+	condition := types.NewMultiSignatureCondition(types.UnlockHashSlice{alice.firstAddress, bob.firstAddress}, 2)
+	for value := byte(10); value < 20; value++ {
+		chain.addConditionBlock(value, condition, nil)
+	}
+
+	ptx, err := alice.CreateMultiSigTransaction(condition.UnlockHash(), []types.Currency{types.NewCurrency64(120)},
+		[]types.UnlockConditionProxy{types.NewCondition(types.NewUnlockHashCondition(alice.firstAddress))}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, w := range []*Wallet{alice, bob} {
+		if _, err = w.SignTransaction(ptx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = ptx.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	size, err := feemarket.TransactionSize(ptx.Transaction)
+	if err != nil {
+		t.Fatal(err)
+	}
+	required := feemarket.RequiredMinerFee(size, types.NewCurrency64(1))
+	if required.Equals64(1) || !ptx.Transaction.MinerFees[0].Equals(required) {
+		t.Errorf("expected a fee of %v for %d bytes, but got %v", required, size, ptx.Transaction.MinerFees[0])
+	}
+	inputValue, outputValue := types.ZeroCurrency, required
+	for _, output := range ptx.SpentOutputs {
+		inputValue = inputValue.Add(output.Value)
+	}
+	for _, output := range ptx.Transaction.CoinOutputs {
+		outputValue = outputValue.Add(output.Value)
+	}
+	if !inputValue.Equals(outputValue) {
+		t.Errorf("expected the inputs (%v) to fund the outputs and the fee (%v)", inputValue, outputValue)
+	}
+}
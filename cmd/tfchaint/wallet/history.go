@@ -0,0 +1,215 @@
+package wallet
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/threefoldtech/rivine/pkg/api"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// HistoryEntry is a transaction or miner payout affecting the balance of the wallet
+type HistoryEntry struct {
+	// TransactionID is the ID of the transaction, undefined for miner payouts
+	TransactionID types.TransactionID
+	// BlockID is the ID of the block containing the transaction or miner payout,
+	// undefined for unconfirmed transactions
+	BlockID     types.BlockID
+	Height      types.BlockHeight
+	Unconfirmed bool
+	MinerPayout bool
+	// Confirmations is the amount of blocks created since (and including) the block
+	// containing the transaction or miner payout, zero for unconfirmed transactions
+	Confirmations uint64
+	// Received is the total value sent to the addresses of the wallet
+	Received types.Currency
+	// Spent is the total value of the outputs of the wallet spent by the transaction
+	Spent types.Currency
+	// Fee is the total miner fee paid by the transaction
+	Fee types.Currency
+	// Counterparties are the addresses outside of the wallet the transaction sends coins to
+	// if the wallet spent any outputs, or receives coins from otherwise
+	Counterparties []types.UnlockHash
+	// Data is the arbitrary data attached to the transaction
+	Data []byte
+}
+
+// addressHistory is the history of a single address, as returned by the backend
+type addressHistory struct {
+	blocks       []api.ExplorerBlock
+	transactions []api.ExplorerTransaction
+}
+
+// History returns the transactions and miner payouts affecting the loaded addresses of the wallet,
+// ordered by height, followed by the unconfirmed transactions.
+// It does not require the wallet to be unlocked, and is available for watch-only wallets as well.
+func (w *Wallet) History() ([]HistoryEntry, error) {
+	currentHeight, err := w.backend.CurrentHeight()
+	if err != nil {
+		return nil, err
+	}
+	histories, err := w.getAddressHistories(w.addresses)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := make(map[types.UnlockHash]struct{}, len(w.addresses))
+	for _, address := range w.addresses {
+		addresses[address] = struct{}{}
+	}
+
+	// deduplicate the blocks and transactions referenced by multiple addresses
+	blocks := make(map[types.BlockID]api.ExplorerBlock)
+	transactions := make(map[types.TransactionID]api.ExplorerTransaction)
+	for _, history := range histories {
+		for _, block := range history.blocks {
+			blocks[block.BlockID] = block
+		}
+		for _, txn := range history.transactions {
+			transactions[txn.ID] = txn
+		}
+	}
+
+	// collect the value of the outputs of the wallet, such that spent outputs can be valued
+	outputs := make(map[types.CoinOutputID]types.Currency)
+	var entries []HistoryEntry
+	for _, block := range blocks {
+		entry := HistoryEntry{
+			BlockID:     block.BlockID,
+			Height:      block.Height,
+			MinerPayout: true,
+			Received:    types.ZeroCurrency,
+			Spent:       types.ZeroCurrency,
+		}
+		for i, minerPayout := range block.RawBlock.MinerPayouts {
+			if _, ok := addresses[minerPayout.UnlockHash]; ok {
+				outputs[block.MinerPayoutIDs[i]] = minerPayout.Value
+				entry.Received = entry.Received.Add(minerPayout.Value)
+			}
+		}
+		if !entry.Received.IsZero() {
+			entries = append(entries, entry)
+		}
+	}
+	for _, txn := range transactions {
+		for i, co := range txn.RawTransaction.CoinOutputs {
+			if _, ok := addresses[co.Condition.UnlockHash()]; ok {
+				outputs[txn.CoinOutputIDs[i]] = co.Value
+			}
+		}
+	}
+	for _, txn := range transactions {
+		entry := HistoryEntry{
+			TransactionID: txn.ID,
+			BlockID:       txn.Parent,
+			Height:        txn.Height,
+			Unconfirmed:   txn.Unconfirmed,
+			Received:      types.ZeroCurrency,
+			Spent:         types.ZeroCurrency,
+			Fee:           types.ZeroCurrency,
+			Data:          txn.RawTransaction.ArbitraryData,
+		}
+		for _, fee := range txn.RawTransaction.MinerFees {
+			entry.Fee = entry.Fee.Add(fee)
+		}
+		for _, co := range txn.RawTransaction.CoinOutputs {
+			if _, ok := addresses[co.Condition.UnlockHash()]; ok {
+				entry.Received = entry.Received.Add(co.Value)
+			}
+		}
+		for _, ci := range txn.RawTransaction.CoinInputs {
+			if value, ok := outputs[ci.ParentID]; ok {
+				entry.Spent = entry.Spent.Add(value)
+			}
+		}
+		if entry.Received.IsZero() && entry.Spent.IsZero() {
+			continue
+		}
+		if entry.Spent.IsZero() {
+			for _, co := range txn.CoinInputOutputs {
+				entry.Counterparties = appendCounterparty(entry.Counterparties, co.UnlockHash, addresses)
+			}
+		} else {
+			for _, co := range txn.RawTransaction.CoinOutputs {
+				entry.Counterparties = appendCounterparty(entry.Counterparties, co.Condition.UnlockHash(), addresses)
+			}
+		}
+		if entry.Unconfirmed {
+			entry.BlockID, entry.Height = types.BlockID{}, 0
+		}
+		entries = append(entries, entry)
+	}
+
+	for i := range entries {
+		if !entries[i].Unconfirmed && entries[i].Height <= currentHeight {
+			entries[i].Confirmations = uint64(currentHeight-entries[i].Height) + 1
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Unconfirmed != entries[j].Unconfirmed {
+			return entries[j].Unconfirmed
+		}
+		if entries[i].Height != entries[j].Height {
+			return entries[i].Height < entries[j].Height
+		}
+		// miner payouts are created before the transactions of a block
+		if entries[i].MinerPayout != entries[j].MinerPayout {
+			return entries[i].MinerPayout
+		}
+		return entries[i].TransactionID.String() < entries[j].TransactionID.String()
+	})
+	return entries, nil
+}
+
+// appendCounterparty appends the address to the counterparties,
+// unless it is an address of the wallet or already included
+func appendCounterparty(counterparties []types.UnlockHash, address types.UnlockHash, own map[types.UnlockHash]struct{}) []types.UnlockHash {
+	if _, ok := own[address]; ok {
+		return counterparties
+	}
+	for _, uh := range counterparties {
+		if uh == address {
+			return counterparties
+		}
+	}
+	return append(counterparties, address)
+}
+
+// getAddressHistories fetches the full history of the given addresses
+func (w *Wallet) getAddressHistories(addresses []types.UnlockHash) ([]addressHistory, error) {
+	workerCount := WorkerCount
+	if len(addresses) < workerCount {
+		workerCount = len(addresses)
+	}
+
+	jobs := make(chan int, len(addresses))
+	errChan := make(chan error, len(addresses))
+	histories := make([]addressHistory, len(addresses))
+
+	for worker := 1; worker <= workerCount; worker++ {
+		go func() {
+			for idx := range jobs {
+				blocks, transactions, err := w.getAddressHistory(addresses[idx])
+				histories[idx] = addressHistory{blocks: blocks, transactions: transactions}
+				errChan <- err
+			}
+		}()
+	}
+	for idx := range addresses {
+		jobs <- idx
+	}
+	close(jobs)
+
+	errs := make([]string, 0)
+	for range addresses {
+		if err := <-errChan; err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.New(strings.Join(errs, "\n"))
+	}
+	return histories, nil
+}
@@ -0,0 +1,122 @@
+package wallet
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+func TestWatchAddresses(t *testing.T) {
+	home, err := ioutil.TempDir("", "tfchaint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+	defer os.Setenv("HOME", os.Getenv("HOME"))
+	os.Setenv("HOME", home)
+	if err = os.MkdirAll(Dir("watch"), walletDirPerm); err != nil {
+		t.Fatal(err)
+	}
+
+	signer := &Wallet{
+		seed:     modules.Seed{1},
+		unlocked: true,
+		name:     "signer",
+		backend:  offlineBackend{name: "devnet"},
+	}
+	if err = signer.generateKeys(1); err != nil {
+		t.Fatal(err)
+	}
+	publicKeys, err := signer.PublicKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	watched := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: crypto.Hash{1}}
+	other := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: crypto.Hash{2}}
+
+	chain := &testChain{}
+	first := chain.addBlock(30, signer.firstAddress, nil)
+	chain.addBlock(40, watched, nil)
+	chain.addBlock(50, other, nil)
+	chain.addBlock(20, other, []types.CoinOutputID{first})
+
+	// the address of the public key comes first, even if it is given last
+	watcher := &Wallet{
+		name:    "watch",
+		backend: chain,
+	}
+	if err = watcher.watch(publicKeys, []types.UnlockHash{watched, signer.firstAddress}); err != nil {
+		t.Fatal(err)
+	}
+	if len(watcher.addresses) != 2 || watcher.firstAddress != signer.firstAddress {
+		t.Fatalf("expected the watch-only wallet to track 2 addresses, starting with the address of the public key")
+	}
+	if pks, err := watcher.PublicKeys(); err != nil || len(pks) != 1 {
+		t.Fatalf("expected 1 public key, but got %d (error: %v)", len(pks), err)
+	}
+
+	entries, err := watcher.History()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 history entries, but got %d", len(entries))
+	}
+	for i, expected := range []struct{ received, spent uint64 }{{30, 0}, {40, 0}, {0, 30}} {
+		if !entries[i].Received.Equals64(expected.received) || !entries[i].Spent.Equals64(expected.spent) {
+			t.Errorf("expected entry %d to receive %d and spend %d, but got %v and %v",
+				i, expected.received, expected.spent, entries[i].Received, entries[i].Spent)
+		}
+		if entries[i].Height != types.BlockHeight([]int{0, 1, 3}[i]) {
+			t.Errorf("expected entry %d to be ordered by height, but got height %d", i, entries[i].Height)
+		}
+		if entries[i].Confirmations != []uint64{4, 3, 1}[i] {
+			t.Errorf("expected entry %d to have %d confirmations, but got %d", i, []uint64{4, 3, 1}[i], entries[i].Confirmations)
+		}
+	}
+	if counterparties := entries[2].Counterparties; len(counterparties) != 1 || counterparties[0] != other {
+		t.Errorf("expected the spending transaction to send to %v, but got %v", other, counterparties)
+	}
+
+	// an address unknown to the explorer has no history
+	unused := types.UnlockHash{Type: types.UnlockTypePubKey, Hash: crypto.Hash{3}}
+	if err = watcher.watch(publicKeys, []types.UnlockHash{watched, signer.firstAddress, unused}); err != nil {
+		t.Fatal(err)
+	}
+	if entries, err = watcher.History(); err != nil || len(entries) != 3 {
+		t.Fatalf("expected the 3 history entries to remain, but got %d (error: %v)", len(entries), err)
+	}
+
+	// the balance includes the watched address, but its outputs can't be spent without its public key
+	unlocked, _, err := watcher.GetBalance()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !unlocked.Equals64(40) {
+		t.Errorf("expected a balance of 40, but got %v", unlocked)
+	}
+	if _, err = watcher.CreateUnsignedTransaction([]types.Currency{types.NewCurrency64(10)},
+		[]types.UnlockConditionProxy{types.NewCondition(types.NewUnlockHashCondition(other))}, nil, false); err != ErrInsufficientWalletFunds {
+		t.Errorf("expected insufficient funds error, but got: %v", err)
+	}
+
+	// watched addresses survive saving and loading the wallet
+	if err = save(watcher); err != nil {
+		t.Fatal(err)
+	}
+	data, err := load("watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded := &Wallet{name: "watch", backend: chain}
+	if err = loaded.restore(data); err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.addresses) != 3 || !loaded.canSign(signer.firstAddress) || loaded.canSign(watched) {
+		t.Errorf("expected the watched addresses to be restored")
+	}
+}
@@ -0,0 +1,121 @@
+package wallet
+
+import (
+	"errors"
+
+	"github.com/threefoldtech/rivine/types"
+)
+
+// ErrNotMultiSigAddress indicates that an address is not a multisig address
+var ErrNotMultiSigAddress = errors.New("The address is not a multisig address")
+
+// CreateMultiSigTransaction creates an unsigned transaction sending the given amounts
+// to the given conditions, funded by the unspent outputs of the given multisig address.
+// The miner fee is paid by the multisig address as well, and any leftover value is sent back to it.
+// The wallet does not have to be one of the co-signers of the multisig address, nor does it have to be unlocked.
+func (w *Wallet) CreateMultiSigTransaction(address types.UnlockHash, amounts []types.Currency, conditions []types.UnlockConditionProxy, data []byte) (*PartialTransaction, error) {
+	if address.Type != types.UnlockTypeMultiSig {
+		return nil, ErrNotMultiSigAddress
+	}
+	if len(data) > ArbitraryDataMaxSize {
+		return nil, ErrTooMuchData
+	}
+	if len(amounts) == 0 {
+		return nil, errors.New("at least one amount is required")
+	}
+	if len(amounts) != len(conditions) {
+		return nil, errors.New("the amount of of amounts does not match the amount of conditions")
+	}
+
+	chainCts, err := w.backend.GetChainConstants()
+	if err != nil {
+		return nil, err
+	}
+	blocks, transactions, err := w.getAddressHistory(address)
+	if err != nil {
+		return nil, err
+	}
+	unspent := collectAddressOutputs(address, blocks, transactions)
+	pendingSpent, err := w.updatePendingMultiSig(address, unspent)
+	if err != nil {
+		return nil, err
+	}
+	outputs := make(SpendableOutputs)
+	var refundCondition types.UnlockConditionProxy
+	for id, co := range unspent {
+		if _, ok := pendingSpent[id]; ok {
+			// ignore outputs spent by a transaction which isn't confirmed yet
+			continue
+		}
+		if co.Output.Condition.ConditionType() == types.ConditionTypeMultiSignature {
+			outputs[id] = co.Output
+			refundCondition = co.Output.Condition
+		}
+	}
+	// only continue with outputs which aren't time locked
+	outputs, _, err = w.splitTimeLockedOutputs(outputs)
+	if err != nil {
+		return nil, err
+	}
+
+	// the fee depends on the size of the transaction, and thus on the inputs used to fund it,
+	// starting from the minimum fee, inputs are selected again until they cover the required fee
+	txFee := chainCts.MinimumTransactionFee
+	for {
+		requiredFunds := txFee
+		for _, amount := range amounts {
+			requiredFunds = requiredFunds.Add(amount)
+		}
+		selected, err := w.getCoinSelector().SelectCoins(outputs, requiredFunds, MaxTransactionInputs)
+		if err != nil {
+			return nil, err
+		}
+
+		ptx := &PartialTransaction{
+			Transaction: types.Transaction{
+				Version:       chainCts.DefaultTransactionVersion,
+				MinerFees:     []types.Currency{txFee},
+				ArbitraryData: data,
+			},
+		}
+		inputValue := types.ZeroCurrency
+		for _, entry := range sortOutputs(selected, true) {
+			ptx.Transaction.CoinInputs = append(ptx.Transaction.CoinInputs, types.CoinInput{
+				ParentID:    entry.id,
+				Fulfillment: types.NewFulfillment(types.NewMultiSignatureFulfillment(nil)),
+			})
+			ptx.SpentOutputs = append(ptx.SpentOutputs, entry.output)
+			inputValue = inputValue.Add(entry.output.Value)
+		}
+		for i, condition := range conditions {
+			ptx.Transaction.CoinOutputs = append(ptx.Transaction.CoinOutputs, types.CoinOutput{
+				Value:     amounts[i],
+				Condition: condition,
+			})
+		}
+		remainder := inputValue.Sub(requiredFunds)
+		if !remainder.IsZero() {
+			ptx.Transaction.CoinOutputs = append(ptx.Transaction.CoinOutputs, types.CoinOutput{
+				Value:     remainder,
+				Condition: refundCondition,
+			})
+		}
+
+		fee, err := requiredMinerFee(ptx.Transaction, selected, 0, chainCts.MinimumTransactionFee)
+		if err != nil {
+			return nil, err
+		}
+		if fee.Cmp(txFee) > 0 {
+			// pay the additional fee using the leftover value if possible, otherwise select the inputs again
+			additionalFee := fee.Sub(txFee)
+			if remainder.Cmp(additionalFee) <= 0 {
+				txFee = fee
+				continue
+			}
+			refund := &ptx.Transaction.CoinOutputs[len(ptx.Transaction.CoinOutputs)-1]
+			refund.Value = remainder.Sub(additionalFee)
+			ptx.Transaction.MinerFees = []types.Currency{fee}
+		}
+		return ptx, nil
+	}
+}
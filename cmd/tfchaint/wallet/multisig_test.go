@@ -0,0 +1,134 @@
+package wallet
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+func TestMultiSigTransaction(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tfchaint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer os.Setenv("HOME", os.Getenv("HOME"))
+	os.Setenv("HOME", dir)
+
+	chain := &testChain{}
+	newWallet := func(name string, seed modules.Seed) *Wallet {
+		if err := os.MkdirAll(Dir(name), walletDirPerm); err != nil {
+			t.Fatal(err)
+		}
+		w := &Wallet{
+			seed:     seed,
+			unlocked: true,
+			name:     name,
+			backend:  chain,
+		}
+		if err := w.generateKeys(1); err != nil {
+			t.Fatal(err)
+		}
+		return w
+	}
+	alice, bob := newWallet("alice", modules.Seed{1}), newWallet("bob", modules.Seed{2})
+	condition := types.NewMultiSignatureCondition(types.UnlockHashSlice{alice.firstAddress, bob.firstAddress}, 2)
+	chain.addConditionBlock(100, condition, nil)
+
+	if _, err = alice.CreateMultiSigTransaction(alice.firstAddress, []types.Currency{types.NewCurrency64(50)},
+		[]types.UnlockConditionProxy{types.NewCondition(types.NewUnlockHashCondition(alice.firstAddress))}, nil); err != ErrNotMultiSigAddress {
+		t.Errorf("expected not a multisig address error, but got: %v", err)
+	}
+	ptx, err := alice.CreateMultiSigTransaction(condition.UnlockHash(), []types.Currency{types.NewCurrency64(50)},
+		[]types.UnlockConditionProxy{types.NewCondition(types.NewUnlockHashCondition(alice.firstAddress))}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ptx.Transaction.CoinInputs) != 1 || len(ptx.Transaction.CoinOutputs) != 2 {
+		t.Fatalf("unexpected transaction: %d inputs, %d outputs", len(ptx.Transaction.CoinInputs), len(ptx.Transaction.CoinOutputs))
+	}
+	if refund := ptx.Transaction.CoinOutputs[1]; !refund.Value.Equals64(49) || refund.Condition.UnlockHash() != condition.UnlockHash() {
+		t.Errorf("expected 49 to be refunded to the multisig address, but got %v to %v", refund.Value, refund.Condition.UnlockHash())
+	}
+	if err = ptx.Verify(); err == nil {
+		t.Error("expected unsigned transaction to fail verification")
+	}
+
+	// each co-signer signs its own copy of the transaction file
+	path := filepath.Join(dir, "tx.json")
+	if err = SavePartialTransaction(path, ptx); err != nil {
+		t.Fatal(err)
+	}
+	var ptxs []*PartialTransaction
+	for _, w := range []*Wallet{alice, bob} {
+		ptx, err := LoadPartialTransaction(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if signatures, err := w.SignTransaction(ptx); err != nil || signatures != 1 {
+			t.Fatalf("expected 1 signature, but got %d (error: %v)", signatures, err)
+		}
+		if signatures, err := w.SignTransaction(ptx); err != nil || signatures != 0 {
+			t.Fatalf("expected no additional signatures, but got %d (error: %v)", signatures, err)
+		}
+		ptxs = append(ptxs, ptx)
+	}
+	if _, err = alice.BroadcastTransaction(ptxs[0]); err == nil {
+		t.Error("expected transaction signed by a single co-signer to fail verification")
+	}
+
+	// merging the signatures reaches the threshold
+	merged, err := MergePartialTransactions(ptxs[0], ptxs[1], ptxs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signed, _ := merged.Signatures(); signed[0] != 2 {
+		t.Fatalf("expected 2 signatures, but got %d", signed[0])
+	}
+	if _, err = bob.BroadcastTransaction(merged); err != nil {
+		t.Fatal(err)
+	}
+	if len(chain.sent) != 1 {
+		t.Fatalf("expected 1 transaction to be sent, but got %d", len(chain.sent))
+	}
+
+	// the outputs of the multisig address spent by the broadcasted transaction can't be spent again while it is pending
+	if _, err = bob.CreateMultiSigTransaction(condition.UnlockHash(), []types.Currency{types.NewCurrency64(50)},
+		[]types.UnlockConditionProxy{types.NewCondition(types.NewUnlockHashCondition(bob.firstAddress))}, nil); err != ErrInsufficientWalletFunds {
+		t.Errorf("expected insufficient funds error, but got: %v", err)
+	}
+	pending, err := bob.PendingTransactions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 || pending[0].MultiSigAddress == nil || *pending[0].MultiSigAddress != condition.UnlockHash() {
+		t.Fatalf("expected the broadcasted transaction to be pending for the multisig address, but got %v", pending)
+	}
+	// the pending transaction of the multisig address doesn't affect the outputs of the wallet itself
+	if _, err = bob.getUnspentCoinOutputs(); err != nil {
+		t.Fatal(err)
+	}
+	if pending, err = bob.PendingTransactions(); err != nil || len(pending) != 1 {
+		t.Fatalf("expected the transaction to remain pending, but got %d (error: %v)", len(pending), err)
+	}
+	// once confirmed, the pending transaction is no longer tracked
+	chain.addConditionBlock(40, condition, coinInputIDs(merged.Transaction.CoinInputs))
+	if _, err = bob.CreateMultiSigTransaction(condition.UnlockHash(), []types.Currency{types.NewCurrency64(30)},
+		[]types.UnlockConditionProxy{types.NewCondition(types.NewUnlockHashCondition(bob.firstAddress))}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if pending, err = bob.PendingTransactions(); err != nil || len(pending) != 0 {
+		t.Fatalf("expected no pending transactions, but got %d (error: %v)", len(pending), err)
+	}
+
+	// only the signatures of the same transaction can be merged
+	other := *ptxs[1]
+	other.Transaction.ArbitraryData = []byte("data")
+	if _, err = MergePartialTransactions(ptxs[0], &other); err != ErrTransactionMismatch {
+		t.Errorf("expected transaction mismatch error, but got: %v", err)
+	}
+}
@@ -0,0 +1,228 @@
+package wallet
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/threefoldfoundation/tfchain/pkg/config"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/pkg/api"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// ErrOffline indicates that a wallet loaded for offline use tried to reach the chain
+var ErrOffline = errors.New("The wallet is loaded offline")
+
+// offlineBackend is the Backend of a wallet loaded for offline use,
+// which only remembers the name of the actual backend of the wallet
+type offlineBackend struct {
+	name string
+}
+
+// NewWatchOnlyWallet creates a new watch-only wallet for the given public keys and addresses.
+// Such a wallet can track the balance and history of the addresses, and create unsigned transactions
+// spending the outputs of the addresses of the public keys, which have to be signed by the wallet owning the seed.
+// Addresses given without their public key can only be watched.
+// A custom network is used in case a genesis file is given.
+func NewWatchOnlyWallet(name string, publicKeys []types.PublicKey, addresses []types.UnlockHash, backendName string, genesisFile string) (*Wallet, error) {
+	if len(publicKeys) == 0 && len(addresses) == 0 {
+		return nil, errors.New("at least one public key or address is required")
+	}
+	exists, err := walletExists(name)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrWalletExists
+	}
+
+	var network *config.NetworkDefinition
+	if genesisFile != "" {
+		network, err = config.LoadNetworkDefinition(genesisFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+	backend, err := loadBackend(backendName, network)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Wallet{
+		name:    name,
+		backend: backend,
+		network: network,
+	}
+	if err = w.watch(publicKeys, addresses); err != nil {
+		return nil, err
+	}
+	if err = save(w); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// LoadOffline loads a wallet like Load does, without connecting to its backend,
+// such that it can be used on a machine without network access, e.g. to sign transactions.
+func LoadOffline(name string) (*Wallet, error) {
+	data, err := load(name)
+	if err != nil {
+		return nil, err
+	}
+	network, err := loadNetwork(name)
+	if err != nil {
+		return nil, err
+	}
+	w := &Wallet{
+		name:    name,
+		backend: offlineBackend{name: data.Backend},
+		network: network,
+	}
+	if err = w.restore(data); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// watch turns the wallet into a watch-only wallet, tracking the addresses of the given public keys
+// followed by the given addresses. The first address receives the leftover value of unsigned transactions,
+// so it is the address of the first public key, if any.
+func (w *Wallet) watch(publicKeys []types.PublicKey, addresses []types.UnlockHash) error {
+	w.watchOnly = true
+	w.publicKeys = make(map[types.UnlockHash]types.PublicKey, len(publicKeys))
+	w.addresses = make([]types.UnlockHash, 0, len(publicKeys)+len(addresses))
+	known := make(map[types.UnlockHash]struct{}, len(publicKeys)+len(addresses))
+	for _, pk := range publicKeys {
+		uh, err := types.NewPubKeyUnlockHash(pk)
+		if err != nil {
+			return err
+		}
+		if _, ok := known[uh]; ok {
+			continue
+		}
+		known[uh] = struct{}{}
+		w.publicKeys[uh] = pk
+		w.addresses = append(w.addresses, uh)
+	}
+	for _, uh := range addresses {
+		if _, ok := known[uh]; ok {
+			continue
+		}
+		known[uh] = struct{}{}
+		w.addresses = append(w.addresses, uh)
+	}
+	if len(w.addresses) == 0 {
+		return errors.New("watch-only wallet does not define any address")
+	}
+	w.firstAddress = w.addresses[0]
+	return nil
+}
+
+// PublicKeys returns the public keys of all loaded addresses, in the order they were generated,
+// such that they can be imported in a watch-only wallet. For watch-only wallets,
+// addresses which were imported without their public key are skipped.
+func (w *Wallet) PublicKeys() ([]types.PublicKey, error) {
+	if w.IsLocked() && !w.IsWatchOnly() {
+		return nil, ErrWalletLocked
+	}
+	publicKeys := make([]types.PublicKey, 0, len(w.addresses))
+	for _, address := range w.addresses {
+		if pk, ok := w.publicKeys[address]; ok {
+			publicKeys = append(publicKeys, pk)
+		}
+	}
+	return publicKeys, nil
+}
+
+// canSign returns true if the wallet knows the public key of the given address,
+// which is required to create an input spending its outputs
+func (w *Wallet) canSign(address types.UnlockHash) bool {
+	_, ok := w.publicKeys[address]
+	return ok
+}
+
+// CreateUnsignedTransaction creates a transaction like TransferCoinsMulti does, without signing or submitting it.
+// The returned partial transaction includes the spent outputs, such that it can be signed offline.
+// Watch-only wallets can only send the leftover value to the first address.
+func (w *Wallet) CreateUnsignedTransaction(amounts []types.Currency, conditions []types.UnlockConditionProxy, data []byte, newRefundAddress bool) (*PartialTransaction, error) {
+	txn, outputs, err := w.createTransaction(amounts, conditions, data, newRefundAddress)
+	if err != nil {
+		return nil, err
+	}
+	ptx := &PartialTransaction{Transaction: txn}
+	for _, ci := range txn.CoinInputs {
+		ptx.SpentOutputs = append(ptx.SpentOutputs, outputs[ci.ParentID])
+	}
+	return ptx, nil
+}
+
+// ParseWatchEntry parses a string as either a public key or an address,
+// as used to create a watch-only wallet
+func ParseWatchEntry(s string) (*types.PublicKey, *types.UnlockHash, error) {
+	var pk types.PublicKey
+	if err := pk.LoadString(s); err == nil {
+		return &pk, nil, nil
+	}
+	var uh types.UnlockHash
+	if err := uh.LoadString(s); err != nil {
+		return nil, nil, fmt.Errorf("%q is neither a public key nor an address", s)
+	}
+	return nil, &uh, nil
+}
+
+// LoadWatchList loads a JSON-encoded list of public keys and/or addresses from a file,
+// as exported by a wallet owning the seed
+func LoadWatchList(path string) ([]types.PublicKey, []types.UnlockHash, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+	var entries []string
+	if err = json.NewDecoder(file).Decode(&entries); err != nil {
+		return nil, nil, err
+	}
+	var (
+		publicKeys []types.PublicKey
+		addresses  []types.UnlockHash
+	)
+	for _, entry := range entries {
+		pk, uh, err := ParseWatchEntry(entry)
+		if err != nil {
+			return nil, nil, err
+		}
+		if pk != nil {
+			publicKeys = append(publicKeys, *pk)
+		} else {
+			addresses = append(addresses, *uh)
+		}
+	}
+	return publicKeys, addresses, nil
+}
+
+func (offlineBackend) CheckAddress(types.UnlockHash) ([]api.ExplorerBlock, []api.ExplorerTransaction, error) {
+	return nil, nil, ErrOffline
+}
+
+func (offlineBackend) GetBlock(types.BlockHeight) (api.ExplorerBlock, error) {
+	return api.ExplorerBlock{}, ErrOffline
+}
+
+func (offlineBackend) CurrentHeight() (types.BlockHeight, error) {
+	return 0, ErrOffline
+}
+
+func (offlineBackend) SendTxn(types.Transaction) (types.TransactionID, error) {
+	return types.TransactionID{}, ErrOffline
+}
+
+func (offlineBackend) GetChainConstants() (modules.DaemonConstants, error) {
+	return modules.DaemonConstants{}, ErrOffline
+}
+
+// Name returns the name of the actual backend of the wallet
+func (b offlineBackend) Name() string {
+	return b.name
+}
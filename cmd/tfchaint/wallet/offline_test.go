@@ -0,0 +1,97 @@
+package wallet
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+func TestOfflineSigning(t *testing.T) {
+	home, err := ioutil.TempDir("", "tfchaint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+	defer os.Setenv("HOME", os.Getenv("HOME"))
+	os.Setenv("HOME", home)
+	if err = os.MkdirAll(Dir("watch"), walletDirPerm); err != nil {
+		t.Fatal(err)
+	}
+
+	// the seed wallet only has an offline backend
+	signer := &Wallet{
+		seed:     modules.Seed{1},
+		unlocked: true,
+		name:     "signer",
+		backend:  offlineBackend{name: "devnet"},
+	}
+	if err = signer.generateKeys(2); err != nil {
+		t.Fatal(err)
+	}
+	publicKeys, err := signer.PublicKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chain := &testChain{}
+	chain.addBlock(30, signer.addresses[0], nil)
+	chain.addBlock(40, signer.addresses[1], nil)
+	watcher := &Wallet{
+		name:    "watch",
+		backend: chain,
+	}
+	if err = watcher.watch(publicKeys, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(watcher.addresses) != 2 || watcher.firstAddress != signer.firstAddress {
+		t.Fatalf("expected the watch-only wallet to track the addresses of the seed wallet")
+	}
+
+	target := types.NewCondition(types.NewUnlockHashCondition(types.UnlockHash{Type: types.UnlockTypePubKey}))
+	if _, err = watcher.CreateUnsignedTransaction([]types.Currency{types.NewCurrency64(50)},
+		[]types.UnlockConditionProxy{target}, nil, true); err != ErrWatchOnlyWallet {
+		t.Errorf("expected watch-only wallet error for a new refund address, but got: %v", err)
+	}
+	ptx, err := watcher.CreateUnsignedTransaction([]types.Currency{types.NewCurrency64(50)},
+		[]types.UnlockConditionProxy{target}, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ptx.Transaction.CoinInputs) != 2 || len(ptx.SpentOutputs) != 2 {
+		t.Fatalf("expected 2 inputs and their spent outputs, but got %d and %d", len(ptx.Transaction.CoinInputs), len(ptx.SpentOutputs))
+	}
+	if refund := ptx.Transaction.CoinOutputs[1]; !refund.Value.Equals64(19) || refund.Condition.UnlockHash() != signer.firstAddress {
+		t.Errorf("expected 19 to be refunded to the first address, but got %v to %v", refund.Value, refund.Condition.UnlockHash())
+	}
+	if _, err = watcher.SignTransaction(ptx); err != ErrWatchOnlyWallet {
+		t.Errorf("expected watch-only wallet error when signing, but got: %v", err)
+	}
+	if _, err = watcher.BroadcastTransaction(ptx); err == nil {
+		t.Error("expected unsigned transaction to fail verification")
+	}
+
+	if signatures, err := signer.SignTransaction(ptx); err != nil || signatures != 2 {
+		t.Fatalf("expected 2 signatures, but got %d (error: %v)", signatures, err)
+	}
+	if _, err = signer.BroadcastTransaction(ptx); err != ErrOffline {
+		t.Errorf("expected offline error, but got: %v", err)
+	}
+	if _, err = watcher.BroadcastTransaction(ptx); err != nil {
+		t.Fatal(err)
+	}
+	if len(chain.sent) != 1 {
+		t.Fatalf("expected 1 transaction to be sent, but got %d", len(chain.sent))
+	}
+
+	// the spent outputs are no longer available to the watch-only wallet
+	outputs, err := watcher.getUnspentCoinOutputs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(outputs) != 0 {
+		t.Errorf("expected no unspent outputs, but got %d", len(outputs))
+	}
+}
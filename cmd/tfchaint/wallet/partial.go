@@ -0,0 +1,298 @@
+package wallet
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/threefoldtech/rivine/types"
+)
+
+// ErrTransactionMismatch indicates that partial transactions which are merged do not define the same transaction
+var ErrTransactionMismatch = errors.New("The partial transactions do not define the same transaction")
+
+// PartialTransaction is a transaction which isn't (fully) signed yet, spending single signature
+// and/or multisig outputs. The outputs it spends are included, in the same order as the coin inputs
+// of the transaction, such that it can be signed and verified without having to look them up.
+type PartialTransaction struct {
+	Transaction  types.Transaction  `json:"transaction"`
+	SpentOutputs []types.CoinOutput `json:"spentoutputs"`
+}
+
+// SignTransaction adds a signature for every input of the partial transaction
+// which can be signed by a key of this wallet, and wasn't signed by that key yet.
+// No backend is used to do so. The amount of added signatures is returned.
+func (w *Wallet) SignTransaction(ptx *PartialTransaction) (int, error) {
+	if w.IsWatchOnly() {
+		return 0, ErrWatchOnlyWallet
+	}
+	if w.IsLocked() {
+		return 0, ErrWalletLocked
+	}
+	if err := ptx.validate(); err != nil {
+		return 0, err
+	}
+	var signatures int
+	for idx, input := range ptx.Transaction.CoinInputs {
+		ctx := types.FulfillmentSignContext{
+			ExtraObjects: []interface{}{uint64(idx)},
+			Transaction:  ptx.Transaction,
+		}
+		switch condition := ptx.SpentOutputs[idx].Condition.Condition.(type) {
+		case *types.UnlockHashCondition:
+			fulfillment := input.Fulfillment.Fulfillment.(*types.SingleSignatureFulfillment)
+			key, ok := w.keys[condition.TargetUnlockHash]
+			if !ok || len(fulfillment.Signature) != 0 {
+				continue
+			}
+			ctx.Key = key.SecretKey
+			if err := fulfillment.Sign(ctx); err != nil {
+				return signatures, err
+			}
+			signatures++
+
+		case *types.MultiSignatureCondition:
+			fulfillment := input.Fulfillment.Fulfillment.(*types.MultiSignatureFulfillment)
+			for _, uh := range condition.UnlockHashes {
+				key, ok := w.keys[uh]
+				if !ok || hasSigned(fulfillment, uh) {
+					continue
+				}
+				ctx.Key = types.KeyPair{
+					PublicKey:  types.Ed25519PublicKey(key.PublicKey),
+					PrivateKey: types.ByteSlice(key.SecretKey[:]),
+				}
+				if err := fulfillment.Sign(ctx); err != nil {
+					return signatures, err
+				}
+				signatures++
+			}
+		}
+	}
+	return signatures, nil
+}
+
+// BroadcastTransaction submits a partial transaction, which has to be fully signed.
+// The outputs of the wallet it spends are tracked as pending until the transaction is confirmed.
+func (w *Wallet) BroadcastTransaction(ptx *PartialTransaction) (types.TransactionID, error) {
+	if err := ptx.Verify(); err != nil {
+		return types.TransactionID{}, err
+	}
+	txnID, err := w.backend.SendTxn(ptx.Transaction)
+	if err != nil {
+		return types.TransactionID{}, err
+	}
+	addresses := make(map[types.UnlockHash]struct{}, len(w.addresses))
+	for _, address := range w.addresses {
+		addresses[address] = struct{}{}
+	}
+	// track the spent outputs of the wallet, as well as those of the multisig addresses,
+	// such that they are not used again while the transaction is pending
+	ptxns := []PendingTransaction{{ID: txnID}}
+	multiSigIndices := make(map[types.UnlockHash]int)
+	for idx, input := range ptx.Transaction.CoinInputs {
+		address := ptx.SpentOutputs[idx].Condition.UnlockHash()
+		if _, ok := addresses[address]; ok {
+			ptxns[0].Inputs = append(ptxns[0].Inputs, input.ParentID)
+			continue
+		}
+		if address.Type != types.UnlockTypeMultiSig {
+			continue
+		}
+		i, ok := multiSigIndices[address]
+		if !ok {
+			i = len(ptxns)
+			multiSigIndices[address] = i
+			multiSigAddress := address
+			ptxns = append(ptxns, PendingTransaction{ID: txnID, MultiSigAddress: &multiSigAddress})
+		}
+		ptxns[i].Inputs = append(ptxns[i].Inputs, input.ParentID)
+	}
+	return txnID, w.addPendingTransactions(ptxns...)
+}
+
+// MergePartialTransactions merges the signatures of partial transactions,
+// signed by different (co-)signers, into a single partial transaction.
+func MergePartialTransactions(ptxs ...*PartialTransaction) (*PartialTransaction, error) {
+	if len(ptxs) == 0 {
+		return nil, errors.New("at least one partial transaction is required")
+	}
+	for _, ptx := range ptxs {
+		if err := ptx.validate(); err != nil {
+			return nil, err
+		}
+	}
+	hash, err := ptxs[0].Transaction.SignatureHash()
+	if err != nil {
+		return nil, err
+	}
+
+	// copy the first partial transaction, such that the original remains untouched
+	merged := &PartialTransaction{
+		Transaction:  ptxs[0].Transaction,
+		SpentOutputs: ptxs[0].SpentOutputs,
+	}
+	merged.Transaction.CoinInputs = make([]types.CoinInput, len(ptxs[0].Transaction.CoinInputs))
+	for idx, input := range ptxs[0].Transaction.CoinInputs {
+		merged.Transaction.CoinInputs[idx] = types.CoinInput{ParentID: input.ParentID}
+		switch fulfillment := input.Fulfillment.Fulfillment.(type) {
+		case *types.SingleSignatureFulfillment:
+			merged.Transaction.CoinInputs[idx].Fulfillment = types.NewFulfillment(
+				types.NewSingleSignatureFulfillment(fulfillment.PublicKey))
+		case *types.MultiSignatureFulfillment:
+			merged.Transaction.CoinInputs[idx].Fulfillment = types.NewFulfillment(
+				types.NewMultiSignatureFulfillment(nil))
+		}
+	}
+
+	for _, ptx := range ptxs {
+		// signatures don't cover the fulfillments, so equal signature hashes imply the same transaction
+		other, err := ptx.Transaction.SignatureHash()
+		if err != nil {
+			return nil, err
+		}
+		if other != hash {
+			return nil, ErrTransactionMismatch
+		}
+		for idx, input := range ptx.Transaction.CoinInputs {
+			switch fulfillment := merged.Transaction.CoinInputs[idx].Fulfillment.Fulfillment.(type) {
+			case *types.SingleSignatureFulfillment:
+				signed, ok := input.Fulfillment.Fulfillment.(*types.SingleSignatureFulfillment)
+				if !ok {
+					return nil, ErrTransactionMismatch
+				}
+				if len(signed.Signature) != 0 {
+					fulfillment.Signature = signed.Signature
+				}
+			case *types.MultiSignatureFulfillment:
+				signed, ok := input.Fulfillment.Fulfillment.(*types.MultiSignatureFulfillment)
+				if !ok {
+					return nil, ErrTransactionMismatch
+				}
+				for _, pair := range signed.Pairs {
+					uh, err := types.NewPubKeyUnlockHash(pair.PublicKey)
+					if err != nil {
+						return nil, err
+					}
+					if !hasSigned(fulfillment, uh) {
+						fulfillment.Pairs = append(fulfillment.Pairs, pair)
+					}
+				}
+			}
+		}
+	}
+	return merged, nil
+}
+
+// Verify checks that every input of the partial transaction
+// is signed by the required amount of (co-)signers, using valid signatures,
+// and that 3bot transactions are signed by all 3bots involved.
+func (ptx *PartialTransaction) Verify() error {
+	if err := ptx.validate(); err != nil {
+		return err
+	}
+	signed, required, err := ptx.BotSignatures()
+	if err != nil {
+		return err
+	}
+	if signed < required {
+		return fmt.Errorf("the 3bot transaction is signed by %d of the %d required 3bots", signed, required)
+	}
+	for idx, input := range ptx.Transaction.CoinInputs {
+		err := ptx.SpentOutputs[idx].Condition.Fulfill(input.Fulfillment, types.FulfillContext{
+			ExtraObjects: []interface{}{uint64(idx)},
+			Transaction:  ptx.Transaction,
+		})
+		if err != nil {
+			return fmt.Errorf("input %d (%s) is not fully signed: %v", idx, input.ParentID.String(), err)
+		}
+	}
+	return nil
+}
+
+// Signatures returns, for every input, the amount of signatures it has
+// and the amount of signatures it requires.
+func (ptx *PartialTransaction) Signatures() (signed []uint64, required []uint64) {
+	for idx, input := range ptx.Transaction.CoinInputs {
+		var count, minimum uint64
+		switch fulfillment := input.Fulfillment.Fulfillment.(type) {
+		case *types.SingleSignatureFulfillment:
+			if len(fulfillment.Signature) != 0 {
+				count = 1
+			}
+		case *types.MultiSignatureFulfillment:
+			count = uint64(len(fulfillment.Pairs))
+		}
+		if idx < len(ptx.SpentOutputs) {
+			switch condition := ptx.SpentOutputs[idx].Condition.Condition.(type) {
+			case *types.UnlockHashCondition:
+				minimum = 1
+			case *types.MultiSignatureCondition:
+				minimum = condition.MinimumSignatureCount
+			}
+		}
+		signed, required = append(signed, count), append(required, minimum)
+	}
+	return
+}
+
+// validate ensures all inputs of the partial transaction spend single signature or multisig outputs,
+// using the matching fulfillment
+func (ptx *PartialTransaction) validate() error {
+	if len(ptx.Transaction.CoinInputs) != len(ptx.SpentOutputs) {
+		return errors.New("the amount of spent outputs does not match the amount of coin inputs")
+	}
+	for idx, input := range ptx.Transaction.CoinInputs {
+		var ok bool
+		switch ptx.SpentOutputs[idx].Condition.Condition.(type) {
+		case *types.UnlockHashCondition:
+			_, ok = input.Fulfillment.Fulfillment.(*types.SingleSignatureFulfillment)
+		case *types.MultiSignatureCondition:
+			_, ok = input.Fulfillment.Fulfillment.(*types.MultiSignatureFulfillment)
+		default:
+			return fmt.Errorf("input %d (%s) spends an output with an unsupported condition", idx, input.ParentID.String())
+		}
+		if !ok {
+			return fmt.Errorf("input %d (%s) does not use the fulfillment matching its condition", idx, input.ParentID.String())
+		}
+	}
+	return nil
+}
+
+// hasSigned returns true if the fulfillment contains a signature of the key with the given unlock hash
+func hasSigned(fulfillment *types.MultiSignatureFulfillment, uh types.UnlockHash) bool {
+	for _, pair := range fulfillment.Pairs {
+		puh, err := types.NewPubKeyUnlockHash(pair.PublicKey)
+		if err == nil && puh == uh {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadPartialTransaction loads a JSON-encoded partial transaction from a file
+func LoadPartialTransaction(path string) (*PartialTransaction, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var ptx PartialTransaction
+	if err = json.NewDecoder(file).Decode(&ptx); err != nil {
+		return nil, err
+	}
+	return &ptx, nil
+}
+
+// SavePartialTransaction stores a partial transaction JSON-encoded in a file
+func SavePartialTransaction(path string, ptx *PartialTransaction) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	e := json.NewEncoder(file)
+	e.SetIndent("", "  ")
+	return e.Encode(ptx)
+}
@@ -0,0 +1,155 @@
+package wallet
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/threefoldtech/rivine/types"
+)
+
+const (
+	// pendingFileName is the name of the file tracking the sent transactions of a wallet which aren't confirmed yet
+	pendingFileName = "pending.json"
+
+	// PendingTransactionTimeout is the duration after which a sent transaction which still isn't confirmed
+	// is considered expired, such that the outputs it spends can be used again
+	PendingTransactionTimeout = time.Hour
+)
+
+// PendingTransaction is a transaction sent by the wallet which isn't confirmed yet.
+// The outputs it spends are excluded from coin selection until it is confirmed or expired.
+// A transaction spending the outputs of the wallet as well as those of multisig addresses
+// is tracked once for the wallet and once for every multisig address.
+type PendingTransaction struct {
+	ID     types.TransactionID  `json:"id"`
+	Inputs []types.CoinOutputID `json:"inputs"`
+	// MultiSigAddress is the multisig address owning the inputs,
+	// nil if the inputs are owned by the wallet itself
+	MultiSigAddress *types.UnlockHash `json:"multisigaddress,omitempty"`
+	Sent            time.Time         `json:"sent"`
+}
+
+// addPending tracks a sent transaction, spending the given outputs of the wallet, until it is confirmed
+func (w *Wallet) addPending(txnID types.TransactionID, inputs []types.CoinOutputID) error {
+	return w.addPendingTransactions(PendingTransaction{ID: txnID, Inputs: inputs})
+}
+
+// addPendingTransactions tracks the given sent transactions until they are confirmed,
+// those which don't spend any inputs are ignored
+func (w *Wallet) addPendingTransactions(ptxns ...PendingTransaction) error {
+	pending, err := loadPending(w.name)
+	if err != nil {
+		return err
+	}
+	added := false
+	for _, ptxn := range ptxns {
+		if len(ptxn.Inputs) == 0 {
+			continue
+		}
+		ptxn.Sent = time.Now()
+		pending = append(pending, ptxn)
+		added = true
+	}
+	if !added {
+		return nil
+	}
+	return writeJSONFile(filepath.Join(Dir(w.name), pendingFileName), pending)
+}
+
+// updatePending removes the pending transactions which are confirmed or expired, given the unspent outputs
+// of the synced wallet, and returns the outputs spent by the remaining ones.
+// A transaction is considered confirmed once none of the outputs it spends is unspent any longer,
+// which is also the case if a conflicting transaction was confirmed instead.
+func (w *Wallet) updatePending(unspent map[types.CoinOutputID]cachedOutput) (map[types.CoinOutputID]struct{}, error) {
+	return w.updatePendingOf(nil, unspent)
+}
+
+// updatePendingMultiSig is like updatePending, but for the pending transactions
+// spending the outputs of the given multisig address, given its unspent outputs.
+func (w *Wallet) updatePendingMultiSig(address types.UnlockHash, unspent map[types.CoinOutputID]cachedOutput) (map[types.CoinOutputID]struct{}, error) {
+	return w.updatePendingOf(&address, unspent)
+}
+
+// updatePendingOf updates the pending transactions spending the outputs of the given multisig address,
+// or those of the wallet itself if no address is given, leaving the other pending transactions untouched
+// (except for expired ones, which are removed).
+func (w *Wallet) updatePendingOf(address *types.UnlockHash, unspent map[types.CoinOutputID]cachedOutput) (map[types.CoinOutputID]struct{}, error) {
+	pending, err := loadPending(w.name)
+	if err != nil {
+		return nil, err
+	}
+	spent := make(map[types.CoinOutputID]struct{})
+	remaining := make([]PendingTransaction, 0, len(pending))
+	for _, ptxn := range pending {
+		if time.Since(ptxn.Sent) > PendingTransactionTimeout {
+			continue
+		}
+		if !ptxn.spendsOutputsOf(address) {
+			remaining = append(remaining, ptxn)
+			continue
+		}
+		confirmed := true
+		for _, id := range ptxn.Inputs {
+			if _, ok := unspent[id]; ok {
+				confirmed = false
+				break
+			}
+		}
+		if confirmed {
+			continue
+		}
+		remaining = append(remaining, ptxn)
+		for _, id := range ptxn.Inputs {
+			spent[id] = struct{}{}
+		}
+	}
+	if len(remaining) == len(pending) {
+		return spent, nil
+	}
+	return spent, writeJSONFile(filepath.Join(Dir(w.name), pendingFileName), remaining)
+}
+
+// spendsOutputsOf returns true if the pending transaction spends the outputs of the given multisig address,
+// or those of the wallet itself if no address is given
+func (ptxn *PendingTransaction) spendsOutputsOf(address *types.UnlockHash) bool {
+	if address == nil || ptxn.MultiSigAddress == nil {
+		return address == nil && ptxn.MultiSigAddress == nil
+	}
+	return *address == *ptxn.MultiSigAddress
+}
+
+// PendingTransactions returns the transactions sent by the wallet which weren't confirmed
+// the last time the wallet was synced, and aren't expired yet
+func (w *Wallet) PendingTransactions() ([]PendingTransaction, error) {
+	pending, err := loadPending(w.name)
+	if err != nil {
+		return nil, err
+	}
+	remaining := make([]PendingTransaction, 0, len(pending))
+	for _, ptxn := range pending {
+		if time.Since(ptxn.Sent) <= PendingTransactionTimeout {
+			remaining = append(remaining, ptxn)
+		}
+	}
+	return remaining, nil
+}
+
+// loadPending loads the pending transactions of a wallet,
+// none are returned in case the file doesn't exist or is invalid
+func loadPending(name string) ([]PendingTransaction, error) {
+	file, err := os.Open(filepath.Join(Dir(name), pendingFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var pending []PendingTransaction
+	if err = json.NewDecoder(file).Decode(&pending); err != nil {
+		return nil, nil
+	}
+	return pending, nil
+}
@@ -0,0 +1,88 @@
+package wallet
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+func TestPendingTransactions(t *testing.T) {
+	home, err := ioutil.TempDir("", "tfchaint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+	defer os.Setenv("HOME", os.Getenv("HOME"))
+	os.Setenv("HOME", home)
+	if err = os.MkdirAll(Dir("pending"), walletDirPerm); err != nil {
+		t.Fatal(err)
+	}
+
+	w := &Wallet{
+		seed:     modules.Seed{3},
+		unlocked: true,
+		name:     "pending",
+	}
+	if err = w.generateKeys(1); err != nil {
+		t.Fatal(err)
+	}
+	chain := &testChain{}
+	w.backend = chain
+	first := chain.addBlock(30, w.firstAddress, nil)
+	second := chain.addBlock(40, w.firstAddress, nil)
+
+	target := types.NewCondition(types.NewUnlockHashCondition(types.UnlockHash{Type: types.UnlockTypePubKey, Hash: crypto.Hash{1}}))
+	send := func(amount uint64) error {
+		_, err := w.TransferCoins(types.NewCurrency64(amount), target, nil, false)
+		return err
+	}
+
+	// the outputs spent by the first transaction can't be spent again while it is pending
+	if err = send(35); err != nil {
+		t.Fatal(err)
+	}
+	if err = send(35); err != ErrInsufficientWalletFunds {
+		t.Fatalf("expected insufficient funds error, but got: %v", err)
+	}
+	pending, err := w.PendingTransactions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 || pending[0].ID != chain.sent[0].ID() {
+		t.Fatalf("expected the sent transaction to be pending, but got %v", pending)
+	}
+
+	// a pending transaction expires after the timeout
+	pending[0].Sent = time.Now().Add(-PendingTransactionTimeout - time.Minute)
+	if err = writeJSONFile(filepath.Join(Dir("pending"), pendingFileName), pending); err != nil {
+		t.Fatal(err)
+	}
+	if err = send(35); err != nil {
+		t.Fatal(err)
+	}
+	if pending, err = w.PendingTransactions(); err != nil || len(pending) != 1 {
+		t.Fatalf("expected only the new transaction to be pending, but got %d (error: %v)", len(pending), err)
+	}
+
+	// a pending transaction is no longer tracked once the outputs it spends are spent on the chain
+	refund := chain.addBlock(5, w.firstAddress, []types.CoinOutputID{second})
+	outputs, err := w.getUnspentCoinOutputs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pending, err = w.PendingTransactions(); err != nil || len(pending) != 0 {
+		t.Fatalf("expected no pending transactions, but got %d (error: %v)", len(pending), err)
+	}
+	if _, ok := outputs[refund]; !ok || len(outputs) != 2 {
+		t.Errorf("expected the first output and the refund to be unspent, but got %d outputs", len(outputs))
+	}
+	if _, ok := outputs[first]; !ok {
+		t.Errorf("expected the first output to be unspent")
+	}
+}
@@ -1,12 +1,20 @@
 package wallet
 
 import (
+	"crypto/rand"
+	"encoding/binary"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"runtime"
+	"time"
 
+	"github.com/threefoldfoundation/tfchain/pkg/config"
 	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
 )
 
 const (
@@ -16,13 +24,43 @@ const (
 	walletsSubDir = "light-wallets"
 	// walletFileName is the name of the wallet save file
 	walletFileName = "wallet.json"
+	// genesisFileName is the name of the copy of the genesis file,
+	// stored only for wallets using a custom network
+	genesisFileName = "genesis.json"
+	// sessionFileName is the name of the file caching the encrypted key of an unlocked wallet
+	sessionFileName = "session.json"
+	// sessionSecretExt is the extension of the file storing the secret
+	// used to encrypt the cached key of a wallet, stored in the session directory
+	sessionSecretExt = ".key"
+	// sessionSecretLen is the size of the secret used to encrypt the cached key of a wallet
+	sessionSecretLen = 32
+
+	// the wallet directory and files are only accessible by the current user
+	walletDirPerm  = 0700
+	walletFilePerm = 0600
 )
 
 type (
 	walletPersist struct {
-		Seed       modules.Seed `json:"seed"`
-		KeysToLoad uint64       `json:"keys_to_load"`
-		Backend    string       `json:"backend"`
+		// Seed is only defined for (legacy) wallets which store their seed unencrypted
+		Seed          *modules.Seed      `json:"seed,omitempty"`
+		EncryptedSeed *encryptedSeed     `json:"encryptedseed,omitempty"`
+		Addresses     []types.UnlockHash `json:"addresses,omitempty"`
+		// PublicKeys are only defined for watch-only wallets, which have no seed,
+		// and might not cover all addresses, as addresses can be watched without their public key
+		PublicKeys []types.PublicKey `json:"publickeys,omitempty"`
+		WatchOnly  bool              `json:"watchonly,omitempty"`
+		KeysToLoad uint64            `json:"keys_to_load"`
+		Backend    string            `json:"backend"`
+	}
+
+	// sessionPersist is the persisted form of a session, caching the key of an unlocked wallet
+	// encrypted using a secret stored outside of the wallet directory, in the session directory,
+	// such that the wallet directory on its own is not sufficient to decrypt the seed
+	sessionPersist struct {
+		Nonce        []byte    `json:"nonce"`
+		EncryptedKey []byte    `json:"encryptedkey"`
+		Expires      time.Time `json:"expires"`
 	}
 )
 
@@ -39,20 +77,184 @@ func walletExists(name string) (bool, error) {
 
 func save(wallet *Wallet) error {
 	data := walletPersist{
-		Seed:       wallet.seed,
-		KeysToLoad: uint64(len(wallet.keys)),
-		Backend:    wallet.backend.Name(),
+		EncryptedSeed: wallet.encryptedSeed,
+		Addresses:     wallet.addresses,
+		KeysToLoad:    uint64(len(wallet.addresses)),
+		Backend:       wallet.backend.Name(),
+	}
+	if wallet.watchOnly {
+		data.WatchOnly = true
+		for _, address := range wallet.addresses {
+			if pk, ok := wallet.publicKeys[address]; ok {
+				data.PublicKeys = append(data.PublicKeys, pk)
+			}
+		}
+	} else if wallet.encryptedSeed == nil {
+		// only the case for a legacy wallet which isn't migrated yet
+		seed := wallet.seed
+		data.Seed = &seed
+		data.Addresses = nil
+	}
+	err := os.MkdirAll(Dir(wallet.name), walletDirPerm)
+	if err != nil {
+		return err
+	}
+	// restrict the permissions of wallets created by older versions
+	err = os.Chmod(Dir(wallet.name), walletDirPerm)
+	if err != nil {
+		return err
+	}
+	err = writeJSONFile(filepath.Join(Dir(wallet.name), walletFileName), data)
+	if err != nil || wallet.network == nil {
+		return err
+	}
+	// store a copy of the custom network definition,
+	// such that the wallet does not depend on the original genesis file
+	return writeJSONFile(filepath.Join(Dir(wallet.name), genesisFileName), wallet.network)
+}
+
+// writeJSONFile (over)writes a file, only accessible by the current user,
+// with the JSON encoding of the given value
+func writeJSONFile(path string, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return writeFile(path, b)
+}
+
+// writeFile atomically (over)writes a file, only accessible by the current user,
+// such that the original file remains intact in case the write fails
+func writeFile(path string, data []byte) (err error) {
+	file, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			file.Close()
+			os.Remove(file.Name())
+		}
+	}()
+	if err = file.Chmod(walletFilePerm); err != nil {
+		return err
+	}
+	if _, err = file.Write(data); err != nil {
+		return err
+	}
+	if err = file.Sync(); err != nil {
+		return err
+	}
+	if err = file.Close(); err != nil {
+		return err
 	}
-	err := os.MkdirAll(Dir(wallet.name), 0777)
+	return os.Rename(file.Name(), path)
+}
+
+// saveSession caches the key of an unlocked wallet until the given expiration time,
+// encrypted using a newly generated secret, stored in the session directory
+func saveSession(name string, key []byte, expires time.Time) error {
+	secret := make([]byte, sessionSecretLen)
+	if _, err := rand.Read(secret); err != nil {
+		return err
+	}
+	aead, err := newSeedAEAD(secret)
 	if err != nil {
 		return err
 	}
-	file, err := os.Create(filepath.Join(Dir(wallet.name), walletFileName))
+	session := sessionPersist{
+		Nonce:   make([]byte, aead.NonceSize()),
+		Expires: expires,
+	}
+	if _, err = rand.Read(session.Nonce); err != nil {
+		return err
+	}
+	// the expiration time is authenticated, such that it cannot be extended
+	session.EncryptedKey = aead.Seal(nil, session.Nonce, key, sessionAdditionalData(expires))
+
+	err = os.MkdirAll(SessionDir(), walletDirPerm)
 	if err != nil {
 		return err
 	}
+	err = os.Chmod(SessionDir(), walletDirPerm)
+	if err != nil {
+		return err
+	}
+	err = writeFile(sessionSecretPath(name), secret)
+	if err != nil {
+		return err
+	}
+	return writeJSONFile(filepath.Join(Dir(name), sessionFileName), session)
+}
+
+// loadSession returns the cached key of a wallet,
+// nil is returned in case no session exists or if it has expired
+func loadSession(name string) ([]byte, error) {
+	file, err := os.Open(filepath.Join(Dir(name), sessionFileName))
+	if os.IsNotExist(err) {
+		// remove a secret left behind by a session which wasn't fully written
+		return nil, removeSession(name)
+	}
+	if err != nil {
+		return nil, err
+	}
 	defer file.Close()
-	return json.NewEncoder(file).Encode(data)
+	var session sessionPersist
+	err = json.NewDecoder(file).Decode(&session)
+	if err != nil || time.Now().After(session.Expires) {
+		// remove invalid and expired sessions
+		return nil, removeSession(name)
+	}
+	key, err := session.decryptKey(name)
+	if err != nil {
+		// the secret is gone (e.g. the session directory was cleared at logout) or invalid
+		return nil, removeSession(name)
+	}
+	return key, nil
+}
+
+// decryptKey decrypts the cached key using the secret stored in the session directory
+func (session *sessionPersist) decryptKey(name string) ([]byte, error) {
+	secret, err := ioutil.ReadFile(sessionSecretPath(name))
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newSeedAEAD(secret)
+	if err != nil {
+		return nil, err
+	}
+	if len(session.Nonce) != aead.NonceSize() {
+		return nil, errors.New("invalid nonce size")
+	}
+	return aead.Open(nil, session.Nonce, session.EncryptedKey, sessionAdditionalData(session.Expires))
+}
+
+func sessionAdditionalData(expires time.Time) []byte {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], uint64(expires.UnixNano()))
+	return b[:]
+}
+
+// removeSession removes the cached key of a wallet and its secret, if they exist
+func removeSession(name string) error {
+	for _, path := range []string{filepath.Join(Dir(name), sessionFileName), sessionSecretPath(name)} {
+		err := os.Remove(path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// RemoveExpiredSessions removes the invalid and expired sessions of the given wallets,
+// such that no cached keys are left behind once a session has expired
+func RemoveExpiredSessions(names []string) error {
+	for _, name := range names {
+		if _, err := loadSession(name); err != nil {
+			return fmt.Errorf("wallet %s: %v", name, err)
+		}
+	}
+	return nil
 }
 
 func load(name string) (walletPersist, error) {
@@ -73,6 +275,20 @@ func load(name string) (walletPersist, error) {
 	return w, err
 }
 
+// loadNetwork loads the custom network definition of a wallet,
+// returning nil if the wallet uses one of the official networks
+func loadNetwork(name string) (*config.NetworkDefinition, error) {
+	path := filepath.Join(Dir(name), genesisFileName)
+	_, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return config.LoadNetworkDefinition(path)
+}
+
 // UserHomeDir gets the home directory of the current user
 func UserHomeDir() string {
 	if runtime.GOOS == "windows" {
@@ -90,6 +306,19 @@ func PersistDir() string {
 	return filepath.Join(UserHomeDir(), tfchaindDir, walletsSubDir)
 }
 
+// SessionDir is the directory in which the secrets of the active sessions are stored,
+// which is a per-user runtime directory, cleared at logout, if one is available
+func SessionDir() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "tfchaint")
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("tfchaint-%d", os.Getuid()))
+}
+
+func sessionSecretPath(name string) string {
+	return filepath.Join(SessionDir(), name+sessionSecretExt)
+}
+
 // Dir returns the directory where the data is stored for a named wallet
 func Dir(name string) string {
 	return filepath.Join(PersistDir(), name)
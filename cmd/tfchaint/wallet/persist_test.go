@@ -0,0 +1,136 @@
+package wallet
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSessionExpiry(t *testing.T) {
+	home, err := ioutil.TempDir("", "tfchaint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+	defer os.Setenv("HOME", os.Getenv("HOME"))
+	os.Setenv("HOME", home)
+	defer os.Setenv("XDG_RUNTIME_DIR", os.Getenv("XDG_RUNTIME_DIR"))
+	os.Setenv("XDG_RUNTIME_DIR", filepath.Join(home, "run"))
+
+	key := []byte("0123456789abcdef0123456789abcdef")
+	for _, name := range []string{"active", "expired", "tampered", "nosecret"} {
+		if err = os.MkdirAll(Dir(name), walletDirPerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = saveSession("active", key, time.Now().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if err = saveSession("expired", key, time.Now().Add(-time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if err = saveSession("nosecret", key, time.Now().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Remove(sessionSecretPath("nosecret")); err != nil {
+		t.Fatal(err)
+	}
+	// extending the expiration time of an expired session invalidates it
+	if err = saveSession("tampered", key, time.Now().Add(-time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	var session sessionPersist
+	if err = readTestJSONFile(filepath.Join(Dir("tampered"), sessionFileName), &session); err != nil {
+		t.Fatal(err)
+	}
+	session.Expires = time.Now().Add(time.Hour)
+	if err = writeJSONFile(filepath.Join(Dir("tampered"), sessionFileName), session); err != nil {
+		t.Fatal(err)
+	}
+
+	err = RemoveExpiredSessions([]string{"active", "expired", "tampered", "nosecret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"expired", "tampered", "nosecret"} {
+		cached, err := loadSession(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cached != nil {
+			t.Errorf("%s: session is not expected to unlock the wallet", name)
+		}
+		if _, err = os.Stat(filepath.Join(Dir(name), sessionFileName)); !os.IsNotExist(err) {
+			t.Errorf("%s: session is expected to be removed", name)
+		}
+		if _, err = os.Stat(sessionSecretPath(name)); !os.IsNotExist(err) {
+			t.Errorf("%s: session secret is expected to be removed", name)
+		}
+	}
+	cached, err := loadSession("active")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(cached) != string(key) {
+		t.Error("active session is expected to return the cached key")
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tfchaint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, walletFileName)
+	if err = ioutil.WriteFile(path, []byte("original"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = writeJSONFile(path, walletPersist{KeysToLoad: 1}); err != nil {
+		t.Fatal(err)
+	}
+	var data walletPersist
+	if err = readTestJSONFile(path, &data); err != nil {
+		t.Fatal(err)
+	}
+	if data.KeysToLoad != 1 {
+		t.Errorf("unexpected file content: %+v", data)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != walletFilePerm {
+		t.Errorf("unexpected file permissions: %v", perm)
+	}
+
+	// a failed write leaves the original file intact
+	if err = writeJSONFile(path, func() {}); err == nil {
+		t.Fatal("expected unencodable value to fail")
+	}
+	if err = writeFile(filepath.Join(dir, "missing", walletFileName), nil); err == nil {
+		t.Fatal("expected write to a missing directory to fail")
+	}
+	if err = readTestJSONFile(path, &data); err != nil || data.KeysToLoad != 1 {
+		t.Errorf("original file is expected to remain intact: %v", err)
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Errorf("expected no temporary files to be left behind, but found %d files", len(files))
+	}
+}
+
+func readTestJSONFile(path string, v interface{}) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
@@ -0,0 +1,213 @@
+package wallet
+
+import (
+	"errors"
+	"strconv"
+
+	tbtypes "github.com/threefoldfoundation/tfchain/extensions/threebot/types"
+	"github.com/threefoldtech/rivine/types"
+)
+
+const (
+	// MaxBotPrepaidMonths is the maximum amount of months which can be paid for a 3bot in a single transaction
+	MaxBotPrepaidMonths = 24
+)
+
+var (
+	// ErrNoBotRegistry indicates that the backend of the wallet can't look up 3bot records
+	ErrNoBotRegistry = errors.New("The backend of the wallet can't look up 3bots")
+	// ErrNotBotOwner indicates that the wallet does not own the key of a 3bot
+	ErrNotBotOwner = errors.New("The wallet does not own the key of the 3bot")
+)
+
+// GetBotRecord returns the record of the 3bot identified by the given ID, name or public key
+func (w *Wallet) GetBotRecord(identifier string) (*tbtypes.BotRecord, error) {
+	registry, ok := w.backend.(tbtypes.BotRecordReadRegistry)
+	if !ok {
+		return nil, ErrNoBotRegistry
+	}
+	if id, err := strconv.ParseUint(identifier, 10, 32); err == nil {
+		return registry.GetRecordForID(tbtypes.BotID(id))
+	}
+	var name tbtypes.BotName
+	if err := name.LoadString(identifier); err == nil {
+		return registry.GetRecordForName(name)
+	}
+	var pk types.PublicKey
+	if err := pk.LoadString(identifier); err != nil {
+		return nil, errors.New("a 3bot is identified by its ID, one of its names or its public key")
+	}
+	return registry.GetRecordForKey(pk)
+}
+
+// RegisterBot registers a new 3bot with the given addresses and names, paying for the given amount of months.
+// The 3bot is identified by the given public key, which has to be a key of the wallet, or by a newly
+// generated key of the wallet if none is given. The fees are paid by the wallet. The ID of the submitted
+// transaction and the public key of the 3bot are returned, the ID of the 3bot is assigned once it is confirmed.
+func (w *Wallet) RegisterBot(addresses []tbtypes.NetworkAddress, names []tbtypes.BotName, months uint8, pk *types.PublicKey) (types.TransactionID, types.PublicKey, error) {
+	if len(addresses) == 0 && len(names) == 0 {
+		return types.TransactionID{}, types.PublicKey{}, errors.New("at least one address or name is required")
+	}
+	if months == 0 || months > MaxBotPrepaidMonths {
+		return types.TransactionID{}, types.PublicKey{}, errors.New("the amount of months has to be in the inclusive interval [1,24]")
+	}
+	if err := w.checkCanSign(); err != nil {
+		return types.TransactionID{}, types.PublicKey{}, err
+	}
+
+	if pk != nil && !w.ownsKey(*pk) {
+		return types.TransactionID{}, types.PublicKey{}, ErrNotBotOwner
+	}
+	botKey, err := w.identityKey(pk)
+	if err != nil {
+		return types.TransactionID{}, types.PublicKey{}, err
+	}
+
+	chainCts, err := w.backend.GetChainConstants()
+	if err != nil {
+		return types.TransactionID{}, types.PublicKey{}, err
+	}
+	tx := tbtypes.BotRegistrationTransaction{
+		Addresses:  addresses,
+		Names:      names,
+		NrOfMonths: months,
+		Identification: tbtypes.PublicKeySignaturePair{
+			PublicKey: botKey,
+		},
+	}
+	// the transaction is signed by the 3bot once funded
+	txn, outputs, err := w.fundTransaction(chainCts, tx.RequiredBotFee(chainCts.OneCoin), false, 1, func(inputs []types.CoinInput, refund *types.CoinOutput, fee types.Currency) types.Transaction {
+		tx.CoinInputs, tx.RefundCoinOutput, tx.TransactionFee = inputs, refund, fee
+		return tx.Transaction(chainCts.OneCoin)
+	})
+	if err != nil {
+		return types.TransactionID{}, types.PublicKey{}, err
+	}
+
+	txnID, err := w.signAndSendTransaction(txn, outputs)
+	return txnID, botKey, err
+}
+
+// UpdateBot updates the addresses and names of the 3bot with the given ID, and pays for the given amount of additional months.
+// The key of the 3bot has to be a key of the wallet, the fees are paid by the wallet as well.
+// Names can only be removed while the 3bot is active.
+func (w *Wallet) UpdateBot(id tbtypes.BotID, addresses tbtypes.BotRecordAddressUpdate, names tbtypes.BotRecordNameUpdate, months uint8) (types.TransactionID, error) {
+	if months == 0 && len(addresses.Add) == 0 && len(addresses.Remove) == 0 && len(names.Add) == 0 && len(names.Remove) == 0 {
+		return types.TransactionID{}, errors.New("at least one update is required")
+	}
+	if months > MaxBotPrepaidMonths {
+		return types.TransactionID{}, errors.New("the amount of months has to be in the inclusive interval [0,24]")
+	}
+	if err := w.checkCanSign(); err != nil {
+		return types.TransactionID{}, err
+	}
+	record, err := w.GetBotRecord(id.String())
+	if err != nil {
+		return types.TransactionID{}, err
+	}
+	if !w.ownsKey(record.PublicKey) {
+		return types.TransactionID{}, ErrNotBotOwner
+	}
+
+	chainCts, err := w.backend.GetChainConstants()
+	if err != nil {
+		return types.TransactionID{}, err
+	}
+	tx := tbtypes.BotRecordUpdateTransaction{
+		Identifier: id,
+		Addresses:  addresses,
+		Names:      names,
+		NrOfMonths: months,
+	}
+	// the transaction is signed by the 3bot once funded
+	txn, outputs, err := w.fundTransaction(chainCts, tx.RequiredBotFee(chainCts.OneCoin), false, 1, func(inputs []types.CoinInput, refund *types.CoinOutput, fee types.Currency) types.Transaction {
+		tx.CoinInputs, tx.RefundCoinOutput, tx.TransactionFee = inputs, refund, fee
+		return tx.Transaction(chainCts.OneCoin)
+	})
+	if err != nil {
+		return types.TransactionID{}, err
+	}
+
+	return w.signAndSendTransaction(txn, outputs)
+}
+
+// TransferBotNames creates a transaction transferring the given names from the sender to the receiver 3bot,
+// both of which have to be active. The fees are paid by the wallet, which has to own the key of at least one of the 3bots.
+// The transaction is signed as both 3bots if possible, otherwise the returned partial transaction has to be
+// signed by the owner of the other 3bot using SignBotTransaction.
+func (w *Wallet) TransferBotNames(sender, receiver tbtypes.BotID, names []tbtypes.BotName) (*PartialTransaction, error) {
+	if len(names) == 0 {
+		return nil, errors.New("at least one name is required")
+	}
+	if err := w.checkCanSign(); err != nil {
+		return nil, err
+	}
+	owned := false
+	for _, id := range []tbtypes.BotID{sender, receiver} {
+		record, err := w.GetBotRecord(id.String())
+		if err != nil {
+			return nil, err
+		}
+		owned = owned || w.ownsKey(record.PublicKey)
+	}
+	if !owned {
+		return nil, ErrNotBotOwner
+	}
+
+	chainCts, err := w.backend.GetChainConstants()
+	if err != nil {
+		return nil, err
+	}
+	tx := tbtypes.BotNameTransferTransaction{
+		Sender:   tbtypes.BotIdentifierSignaturePair{Identifier: sender},
+		Receiver: tbtypes.BotIdentifierSignaturePair{Identifier: receiver},
+		Names:    names,
+	}
+	// the transaction is signed by both 3bots once funded
+	txn, outputs, err := w.fundTransaction(chainCts, tx.RequiredBotFee(chainCts.OneCoin), false, 2, func(inputs []types.CoinInput, refund *types.CoinOutput, fee types.Currency) types.Transaction {
+		tx.CoinInputs, tx.RefundCoinOutput, tx.TransactionFee = inputs, refund, fee
+		return tx.Transaction(chainCts.OneCoin)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ptx := &PartialTransaction{Transaction: txn}
+	for _, ci := range txn.CoinInputs {
+		ptx.SpentOutputs = append(ptx.SpentOutputs, outputs[ci.ParentID])
+	}
+	if err = w.signTxn(ptx.Transaction, outputs); err != nil {
+		return nil, err
+	}
+	if _, err = w.signExtension(&ptx.Transaction); err != nil {
+		return nil, err
+	}
+	return ptx, nil
+}
+
+// SignBotTransaction signs the 3bot transaction of the partial transaction as every 3bot
+// for which the wallet owns the key, returning the amount of added signatures
+func (w *Wallet) SignBotTransaction(ptx *PartialTransaction) (int, error) {
+	if err := w.checkCanSign(); err != nil {
+		return 0, err
+	}
+	return w.signExtension(&ptx.Transaction)
+}
+
+// BotSignatures returns the amount of 3bot signatures the partial transaction has and requires.
+// Only name transfers have to be signed by multiple 3bots, other transactions require no 3bot signatures at all.
+func (ptx *PartialTransaction) BotSignatures() (signed int, required int, err error) {
+	if ptx.Transaction.Version != tbtypes.TransactionVersionBotNameTransfer {
+		return 0, 0, nil
+	}
+	tx, err := tbtypes.BotNameTransferTransactionFromTransaction(ptx.Transaction)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, signature := range []types.ByteSlice{tx.Sender.Signature, tx.Receiver.Signature} {
+		if len(signature) > 0 {
+			signed++
+		}
+	}
+	return signed, 2, nil
+}
@@ -0,0 +1,148 @@
+package wallet
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	tbtypes "github.com/threefoldfoundation/tfchain/extensions/threebot/types"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+func TestBotTransactions(t *testing.T) {
+	home, err := ioutil.TempDir("", "tfchaint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+	defer os.Setenv("HOME", os.Getenv("HOME"))
+	os.Setenv("HOME", home)
+	if err = os.MkdirAll(Dir("bot"), walletDirPerm); err != nil {
+		t.Fatal(err)
+	}
+
+	w := &Wallet{
+		seed:     modules.Seed{4},
+		unlocked: true,
+		name:     "bot",
+	}
+	if err = w.generateKeys(1); err != nil {
+		t.Fatal(err)
+	}
+	other := &Wallet{
+		seed:     modules.Seed{5},
+		unlocked: true,
+		name:     "other",
+	}
+	if err = other.generateKeys(1); err != nil {
+		t.Fatal(err)
+	}
+	chain := &botChain{testChain: &testChain{}, records: make(map[tbtypes.BotID]*tbtypes.BotRecord)}
+	w.backend, other.backend = chain, chain
+	chain.addBlock(200, w.firstAddress, nil)
+	chain.addBlock(100, w.firstAddress, nil)
+	chain.addBlock(60, w.firstAddress, nil)
+
+	// the 3bot signatures are created by the registered transaction controllers
+	for v, c := range map[types.TransactionVersion]types.TransactionController{
+		tbtypes.TransactionVersionBotRegistration: tbtypes.BotRegistrationTransactionController{Registry: chain, OneCoin: types.NewCurrency64(1)},
+		tbtypes.TransactionVersionBotRecordUpdate: tbtypes.BotUpdateRecordTransactionController{Registry: chain, OneCoin: types.NewCurrency64(1)},
+		tbtypes.TransactionVersionBotNameTransfer: tbtypes.BotNameTransferTransactionController{Registry: chain, OneCoin: types.NewCurrency64(1)},
+	} {
+		types.RegisterTransactionVersion(v, c)
+		defer types.RegisterTransactionVersion(v, nil)
+	}
+
+	name, err := tbtypes.NewBotName("alice.tfbot")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// registering a 3bot pays the registration and monthly fee on top of the transaction fee,
+	// and identifies the 3bot using a new key of the wallet
+	_, botKey, err := w.RegisterBot(nil, []tbtypes.BotName{name}, 1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uh, err := types.NewPubKeyUnlockHash(botKey); err != nil || len(w.addresses) != 2 || w.addresses[1] != uh {
+		t.Fatalf("expected the 3bot to be identified by a newly generated key")
+	}
+	registration, err := tbtypes.BotRegistrationTransactionFromTransaction(chain.sent[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(registration.Identification.Signature) == 0 {
+		t.Errorf("expected the registration to be signed by the 3bot")
+	}
+	if registration.RefundCoinOutput == nil || !registration.RefundCoinOutput.Value.Equals64(200-90-10-1) {
+		t.Errorf("expected a refund of %d, but got %v", 200-90-10-1, registration.RefundCoinOutput)
+	}
+
+	// only 3bots identified by a key of the wallet can be updated
+	chain.records[1] = &tbtypes.BotRecord{ID: 1, PublicKey: botKey}
+	chain.records[2] = &tbtypes.BotRecord{ID: 2, PublicKey: other.publicKeys[other.firstAddress]}
+	update := tbtypes.BotRecordNameUpdate{Remove: []tbtypes.BotName{name}}
+	if _, err = w.UpdateBot(2, tbtypes.BotRecordAddressUpdate{}, update, 0); err != ErrNotBotOwner {
+		t.Errorf("expected not owner error, but got: %v", err)
+	}
+	if _, err = w.UpdateBot(1, tbtypes.BotRecordAddressUpdate{}, update, 0); err != nil {
+		t.Fatal(err)
+	}
+	recordUpdate, err := tbtypes.BotRecordUpdateTransactionFromTransaction(chain.sent[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recordUpdate.Signature) == 0 {
+		t.Errorf("expected the update to be signed by the 3bot")
+	}
+
+	// a name transfer to a 3bot of another wallet has to be signed by both wallets
+	ptx, err := w.TransferBotNames(1, 2, []tbtypes.BotName{name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signed, required, err := ptx.BotSignatures(); err != nil || signed != 1 || required != 2 {
+		t.Fatalf("expected 1/2 3bot signatures, but got %d/%d (error: %v)", signed, required, err)
+	}
+	if err = ptx.Verify(); err == nil {
+		t.Errorf("expected a transfer signed by a single 3bot to be rejected")
+	}
+	if signatures, err := other.SignBotTransaction(ptx); err != nil || signatures != 1 {
+		t.Fatalf("expected 1 signature to be added, but got %d (error: %v)", signatures, err)
+	}
+	if err = ptx.Verify(); err != nil {
+		t.Errorf("expected the transfer to be fully signed, but got: %v", err)
+	}
+}
+
+// botChain is a testChain which serves 3bot records kept in memory
+type botChain struct {
+	*testChain
+	records map[tbtypes.BotID]*tbtypes.BotRecord
+}
+
+func (chain *botChain) GetRecordForID(id tbtypes.BotID) (*tbtypes.BotRecord, error) {
+	record, ok := chain.records[id]
+	if !ok {
+		return nil, tbtypes.ErrBotNotFound
+	}
+	return record, nil
+}
+
+func (chain *botChain) GetRecordForKey(key types.PublicKey) (*tbtypes.BotRecord, error) {
+	for _, record := range chain.records {
+		if record.PublicKey.String() == key.String() {
+			return record, nil
+		}
+	}
+	return nil, tbtypes.ErrBotKeyNotFound
+}
+
+func (chain *botChain) GetRecordForName(name tbtypes.BotName) (*tbtypes.BotRecord, error) {
+	return nil, tbtypes.ErrBotNameNotFound
+}
+
+func (chain *botChain) GetBotTransactionIdentifiers(id tbtypes.BotID) ([]types.TransactionID, error) {
+	return nil, nil
+}
@@ -8,22 +8,46 @@ import (
 	"time"
 
 	"github.com/threefoldfoundation/tfchain/cmd/tfchaint/explorer"
+	"github.com/threefoldfoundation/tfchain/pkg/config"
+	"github.com/threefoldfoundation/tfchain/pkg/feemarket"
 	"github.com/threefoldtech/rivine/crypto"
 	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/pkg/api"
 	"github.com/threefoldtech/rivine/types"
 )
 
 type (
 	// Wallet represents a seed, and some derived info used to spend the associated funds
 	Wallet struct {
-		// seed is the seed of the wallet
+		// seed is the seed of the wallet, only defined while the wallet is unlocked
 		seed modules.Seed
-		// keys are all generated addresses and the spendableKey's used to spend them
+		// encryptedSeed is the seed encrypted using the passphrase of the wallet,
+		// nil for (legacy) wallets which store their seed unencrypted
+		encryptedSeed *encryptedSeed
+		// key is the key derived from the passphrase, only defined while the wallet is unlocked
+		key []byte
+		// unlocked defines whether or not the seed and keys are available
+		unlocked bool
+		// keys are all generated addresses and the spendableKey's used to spend them,
+		// only defined while the wallet is unlocked
 		keys map[types.UnlockHash]spendableKey
+		// publicKeys are the public keys of all generated (or imported) addresses,
+		// only defined while the wallet is unlocked, or if it is a watch-only wallet
+		publicKeys map[types.UnlockHash]types.PublicKey
+		// watchOnly defines whether or not the wallet only knows the public keys of its addresses
+		watchOnly bool
+		// addresses are all generated addresses, in the order they were generated
+		addresses []types.UnlockHash
 		// firstAddress is the first address generated from the seed, which is the default refund address
 		firstAddress types.UnlockHash
 		// backend used to interact with the chain
 		backend Backend
+		// network is the custom network used by the wallet, if any
+		network *config.NetworkDefinition
+		// cache contains the unspent coin outputs of the wallet, loaded when first synced
+		cache *outputCache
+		// coinSelector selects the outputs used to fund transactions, largest first if not defined
+		coinSelector CoinSelector
 
 		// name is the name of the wallet
 		name string
@@ -37,13 +61,6 @@ type (
 		PublicKey crypto.PublicKey
 		SecretKey crypto.SecretKey
 	}
-
-	// jobContext stores information used to fetch an address on the explorer
-	jobContext struct {
-		currentChainHeight types.BlockHeight
-		maturityDelay      types.BlockHeight
-		address            types.UnlockHash
-	}
 )
 
 const (
@@ -52,6 +69,10 @@ const (
 
 	// WorkerCount is the number of workers used to process information about explorer addresses
 	WorkerCount = 25
+
+	// DefaultGapLimit is the default amount of consecutive unused addresses
+	// after which no more keys are derived when discovering the keys of a wallet
+	DefaultGapLimit = 20
 )
 
 var (
@@ -63,30 +84,39 @@ var (
 	ErrTooMuchData = errors.New("Too much data is being supplied to the transaction")
 	// ErrInsufficientWalletFunds indicates that the wallet does not have sufficient funds to fund the transaction
 	ErrInsufficientWalletFunds = errors.New("Insufficient funds to create this transaction")
+	// ErrWalletLocked indicates that the wallet has to be unlocked in order to perform an action
+	ErrWalletLocked = errors.New("The wallet is locked")
+	// ErrWalletNotEncrypted indicates that the wallet stores its seed unencrypted
+	ErrWalletNotEncrypted = errors.New("The wallet seed is not encrypted")
+	// ErrWatchOnlyWallet indicates that an action requires the seed, which a watch-only wallet doesn't have
+	ErrWatchOnlyWallet = errors.New("The wallet is watch-only")
 )
 
-// New creates a new wallet with a random seed
-func New(name string, keysToLoad uint64, backendName string) (*Wallet, error) {
+// New creates a new wallet with a random seed, encrypted using the given passphrase,
+// a custom network is used in case a genesis file is given.
+func New(name string, passphrase string, keysToLoad uint64, backendName string, genesisFile string) (*Wallet, error) {
 	seed := modules.Seed{}
 	_, err := rand.Read(seed[:])
 	if err != nil {
 		return nil, err
 	}
 
-	return NewWalletFromSeed(name, seed, keysToLoad, backendName)
+	return NewWalletFromSeed(name, seed, passphrase, keysToLoad, backendName, genesisFile)
 }
 
-// NewWalletFromMnemonic creates a new wallet from a given mnemonic
-func NewWalletFromMnemonic(name string, mnemonic string, keysToLoad uint64, backendName string) (*Wallet, error) {
+// NewWalletFromMnemonic creates a new wallet from a given mnemonic, encrypted using the given passphrase,
+// a custom network is used in case a genesis file is given.
+func NewWalletFromMnemonic(name string, mnemonic string, passphrase string, keysToLoad uint64, backendName string, genesisFile string) (*Wallet, error) {
 	seed, err := modules.InitialSeedFromMnemonic(mnemonic)
 	if err != nil {
 		return nil, err
 	}
-	return NewWalletFromSeed(name, seed, keysToLoad, backendName)
+	return NewWalletFromSeed(name, seed, passphrase, keysToLoad, backendName, genesisFile)
 }
 
-// NewWalletFromSeed creates a new wallet with a given seed
-func NewWalletFromSeed(name string, seed modules.Seed, keysToLoad uint64, backendName string) (*Wallet, error) {
+// NewWalletFromSeed creates a new wallet with a given seed, encrypted using the given passphrase,
+// a custom network is used in case a genesis file is given.
+func NewWalletFromSeed(name string, seed modules.Seed, passphrase string, keysToLoad uint64, backendName string, genesisFile string) (*Wallet, error) {
 	exists, err := walletExists(name)
 	if err != nil {
 		return nil, err
@@ -95,12 +125,30 @@ func NewWalletFromSeed(name string, seed modules.Seed, keysToLoad uint64, backen
 		return nil, ErrWalletExists
 	}
 
-	backend := loadBackend(backendName)
+	var network *config.NetworkDefinition
+	if genesisFile != "" {
+		network, err = config.LoadNetworkDefinition(genesisFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+	backend, err := loadBackend(backendName, network)
+	if err != nil {
+		return nil, err
+	}
 
+	es, key, err := encryptSeed(seed, passphrase)
+	if err != nil {
+		return nil, err
+	}
 	w := &Wallet{
-		seed:    seed,
-		name:    name,
-		backend: backend,
+		seed:          seed,
+		encryptedSeed: es,
+		key:           key,
+		unlocked:      true,
+		name:          name,
+		backend:       backend,
+		network:       network,
 	}
 
 	w.generateKeys(keysToLoad)
@@ -112,36 +160,165 @@ func NewWalletFromSeed(name string, seed modules.Seed, keysToLoad uint64, backen
 	return w, nil
 }
 
-// LoadBackend loads a backend with the given name
-func loadBackend(name string) Backend {
+// LoadBackend loads a backend with the given name,
+// or the backend of the custom network if one is given
+func loadBackend(name string, network *config.NetworkDefinition) (Backend, error) {
+	if network != nil {
+		return explorer.NewCustomGroupedExplorer(network)
+	}
 	switch name {
 	case "standard":
-		return explorer.NewMainnetGroupedExplorer()
+		return explorer.NewMainnetGroupedExplorer(), nil
 	case "testnet":
-		return explorer.NewTestnetGroupedExplorer()
+		return explorer.NewTestnetGroupedExplorer(), nil
 	case "devnet":
-		return explorer.NewDevnetGroupedExplorer()
+		return explorer.NewDevnetGroupedExplorer(), nil
 	default:
 		// for now anything else will also default to devnet
-		return explorer.NewDevnetGroupedExplorer()
+		return explorer.NewDevnetGroupedExplorer(), nil
 	}
 }
 
-// Load loads persistent data for a wallet with a given name, and restores the wallets state
+// Load loads persistent data for a wallet with a given name, and restores the wallets state.
+// An encrypted wallet is returned locked, unless it has an active session.
 func Load(name string) (*Wallet, error) {
 	data, err := load(name)
 	if err != nil {
 		return nil, err
 	}
+	network, err := loadNetwork(name)
+	if err != nil {
+		return nil, err
+	}
+	backend, err := loadBackend(data.Backend, network)
+	if err != nil {
+		return nil, err
+	}
 	w := &Wallet{
 		name:    name,
-		seed:    data.Seed,
-		backend: loadBackend(data.Backend),
+		backend: backend,
+		network: network,
+	}
+	if err = w.restore(data); err != nil {
+		return nil, err
 	}
+	return w, nil
+}
 
-	w.generateKeys(data.KeysToLoad)
+// restore restores the wallet state from its persistent data,
+// unlocking an encrypted wallet only if it has an active session.
+func (w *Wallet) restore(data walletPersist) error {
+	if data.WatchOnly {
+		return w.watch(data.PublicKeys, data.Addresses)
+	}
+	if data.EncryptedSeed == nil {
+		// legacy wallet, storing its seed unencrypted
+		if data.Seed == nil {
+			return errors.New("wallet does not define a seed")
+		}
+		w.seed, w.unlocked = *data.Seed, true
+		return w.generateKeys(data.KeysToLoad)
+	}
+	w.encryptedSeed, w.addresses = data.EncryptedSeed, data.Addresses
+	if len(w.addresses) > 0 {
+		w.firstAddress = w.addresses[0]
+	}
 
-	return w, nil
+	// unlock the wallet using the cached key of an active session, if any
+	key, err := loadSession(w.name)
+	if err != nil || key == nil {
+		return err
+	}
+	if err = w.unlock(key); err != nil {
+		// the session is no longer valid, e.g. because the passphrase was changed
+		return removeSession(w.name)
+	}
+	return nil
+}
+
+// IsEncrypted returns true if the wallet stores its seed encrypted.
+func (w *Wallet) IsEncrypted() bool {
+	return w.encryptedSeed != nil
+}
+
+// IsWatchOnly returns true if the wallet only knows the public keys of its addresses,
+// such that it can create unsigned transactions, but not sign them.
+func (w *Wallet) IsWatchOnly() bool {
+	return w.watchOnly
+}
+
+// IsLocked returns true if the seed of the wallet is not available.
+func (w *Wallet) IsLocked() bool {
+	return !w.unlocked
+}
+
+// Unlock decrypts the seed of the wallet using the given passphrase.
+func (w *Wallet) Unlock(passphrase string) error {
+	if w.IsWatchOnly() {
+		return ErrWatchOnlyWallet
+	}
+	if !w.IsEncrypted() {
+		return ErrWalletNotEncrypted
+	}
+	_, key, err := w.encryptedSeed.decrypt(passphrase)
+	if err != nil {
+		return err
+	}
+	return w.unlock(key)
+}
+
+// unlock decrypts the seed of the wallet using an already derived key.
+func (w *Wallet) unlock(key []byte) error {
+	seed, err := w.encryptedSeed.decryptWithKey(key)
+	if err != nil {
+		return err
+	}
+	w.seed, w.key, w.unlocked = seed, key, true
+	return w.generateKeys(uint64(len(w.addresses)))
+}
+
+// Lock removes the seed and keys of the wallet from memory,
+// and ends its active session, if any.
+func (w *Wallet) Lock() error {
+	// remove any session left behind, even if the wallet can't be locked
+	if err := removeSession(w.name); err != nil {
+		return err
+	}
+	if !w.IsEncrypted() {
+		return ErrWalletNotEncrypted
+	}
+	w.seed, w.key, w.keys, w.unlocked = modules.Seed{}, nil, nil, false
+	return nil
+}
+
+// StartSession caches the key of the unlocked wallet for the given duration,
+// such that it is unlocked automatically when loaded during that time.
+func (w *Wallet) StartSession(timeout time.Duration) error {
+	if !w.IsEncrypted() {
+		return ErrWalletNotEncrypted
+	}
+	if w.IsLocked() {
+		return ErrWalletLocked
+	}
+	return saveSession(w.name, w.key, time.Now().Add(timeout))
+}
+
+// ChangePassphrase encrypts the seed of the unlocked wallet using the given passphrase,
+// ending its active session, if any. A legacy wallet, which stores its seed unencrypted,
+// is migrated to an encrypted wallet this way.
+func (w *Wallet) ChangePassphrase(passphrase string) error {
+	if w.IsLocked() {
+		return ErrWalletLocked
+	}
+	es, key, err := encryptSeed(w.seed, passphrase)
+	if err != nil {
+		return err
+	}
+	w.encryptedSeed, w.key = es, key
+	if err = removeSession(w.name); err != nil {
+		return err
+	}
+	return save(w)
 }
 
 // GetChainConstants returns the chainconstatns of the underlying network
@@ -177,169 +354,464 @@ func (w *Wallet) TransferCoins(amount types.Currency, to types.UnlockConditionPr
 // TransferCoinsMulti transfers coins by creating and submitting a V1 transaction,
 // with multiple outputs. Data can optionally be included.
 func (w *Wallet) TransferCoinsMulti(amounts []types.Currency, conditions []types.UnlockConditionProxy, data []byte, newRefundAddress bool) (types.TransactionID, error) {
+	if w.IsLocked() {
+		return types.TransactionID{}, ErrWalletLocked
+	}
+
+	txn, outputs, err := w.createTransaction(amounts, conditions, data, newRefundAddress)
+	if err != nil {
+		return types.TransactionID{}, err
+	}
+
+	// sign transaction
+	if err := w.signTxn(txn, outputs); err != nil {
+		return types.TransactionID{}, err
+	}
+
+	// finally commit
+	txnID, err := w.backend.SendTxn(txn)
+	if err != nil {
+		return types.TransactionID{}, err
+	}
+	return txnID, w.addPending(txnID, coinInputIDs(txn.CoinInputs))
+}
+
+// createTransaction creates an unsigned V1 transaction with multiple outputs, funded by the
+// unlocked outputs of the wallet, returning it together with the outputs of the wallet it can spend.
+// Data can optionally be included.
+func (w *Wallet) createTransaction(amounts []types.Currency, conditions []types.UnlockConditionProxy, data []byte, newRefundAddress bool) (types.Transaction, SpendableOutputs, error) {
 	// check data length
 	if len(data) > ArbitraryDataMaxSize {
-		return types.TransactionID{}, ErrTooMuchData
+		return types.Transaction{}, nil, ErrTooMuchData
 	}
 	if len(amounts) == 0 {
-		return types.TransactionID{}, errors.New("at least one amount is required")
+		return types.Transaction{}, nil, errors.New("at least one amount is required")
 	}
 	if len(amounts) != len(conditions) {
-		return types.TransactionID{}, errors.New("the amount of of amounts does not match the amount of conditions")
+		return types.Transaction{}, nil, errors.New("the amount of of amounts does not match the amount of conditions")
+	}
+	if w.IsLocked() && !w.IsWatchOnly() {
+		return types.Transaction{}, nil, ErrWalletLocked
+	}
+	if newRefundAddress && w.IsWatchOnly() {
+		// no new addresses can be derived without the seed
+		return types.Transaction{}, nil, ErrWatchOnlyWallet
 	}
 
 	chainCts, err := w.backend.GetChainConstants()
 	if err != nil {
-		return types.TransactionID{}, err
+		return types.Transaction{}, nil, err
 	}
 
-	outputs, err := w.getUnspentCoinOutputs()
-	if err != nil {
-		return types.TransactionID{}, err
+	// The total funds we will be spending in this transaction, excluding the miner fee
+	var requiredFunds types.Currency
+	for i := range amounts {
+		requiredFunds = requiredFunds.Add(amounts[i])
 	}
+	txn, outputs, err := w.fundTransaction(chainCts, requiredFunds, newRefundAddress, 0, func(inputs []types.CoinInput, refund *types.CoinOutput, fee types.Currency) types.Transaction {
+		// Create the transaction object
+		var txn types.Transaction
+		txn.Version = chainCts.DefaultTransactionVersion
+		// Set the inputs
+		txn.CoinInputs = inputs
+
+		// Add our first output
+		for i, condition := range conditions {
+			amount := amounts[i]
+			txn.CoinOutputs = append(txn.CoinOutputs, types.CoinOutput{
+				Value:     amount,
+				Condition: condition,
+			})
+		}
 
-	// only continue with unlocked outputs
-	outputs, _, err = w.splitTimeLockedOutputs(outputs)
+		// add our self referencing output to the transaction, consuming the leftover value
+		if refund != nil {
+			txn.CoinOutputs = append(txn.CoinOutputs, *refund)
+		}
+
+		// Add the miner fee to the transaction
+		txn.MinerFees = []types.Currency{fee}
+
+		// Make sure to set the data
+		txn.ArbitraryData = data
+		return txn
+	})
 	if err != nil {
-		return types.TransactionID{}, err
+		return types.Transaction{}, nil, err
 	}
+	fmt.Printf("fee: %s\n", txn.MinerFees[0].String())
+	return txn, outputs, nil
+}
 
-	walletBalance := w.getBalance(outputs)
-
-	// we give only the minimum fee
+// fundTransaction funds the transaction created by the given function, using the unlocked outputs of the wallet,
+// such that the inputs cover the given amount as well as the miner fee. The create function is given the (unsigned)
+// inputs, the output refunding the leftover value (if any) and the miner fee, which depends on the size of the
+// transaction, and thus on the inputs used to fund it. Starting from the minimum fee, inputs are selected again
+// until they cover the required fee. The given amount of extension signatures, such as the signatures of 3bots,
+// are not yet part of the created transaction, but are included in the size it is charged for.
+func (w *Wallet) fundTransaction(chainCts modules.DaemonConstants, amount types.Currency, newRefundAddress bool, extensionSignatures uint64, create func(inputs []types.CoinInput, refund *types.CoinOutput, fee types.Currency) types.Transaction) (types.Transaction, SpendableOutputs, error) {
 	txFee := chainCts.MinimumTransactionFee
+	var refundCondition *types.UnlockConditionProxy
+	for {
+		// Select the coin inputs used to fund the amount and minerfee,
+		// refunding to the address generated by a previous attempt, if any
+		inputs, refund, outputs, err := w.fundCoins(amount.Add(txFee), newRefundAddress && refundCondition == nil)
+		if err != nil {
+			return types.Transaction{}, nil, err
+		}
+		if refund != nil && newRefundAddress {
+			if refundCondition == nil {
+				refundCondition = &refund.Condition
+			} else {
+				refund.Condition = *refundCondition
+			}
+		}
+
+		txn := create(inputs, refund, txFee)
+		fee, err := requiredMinerFee(txn, outputs, extensionSignatures, chainCts.MinimumTransactionFee)
+		if err != nil {
+			return types.Transaction{}, nil, err
+		}
+		if fee.Cmp(txFee) > 0 {
+			// pay the additional fee using the leftover value if possible, otherwise select the inputs again
+			additionalFee := fee.Sub(txFee)
+			if refund == nil || refund.Value.Cmp(additionalFee) <= 0 {
+				txFee = fee
+				continue
+			}
+			refund.Value = refund.Value.Sub(additionalFee)
+			txn = create(inputs, refund, fee)
+		}
+		return txn, outputs, nil
+	}
+}
 
-	// Since this is only for demonstration purposes, lets give a fixed 10 hastings fee
-	// minerfee := types.NewCurrency64(10)
+// requiredMinerFee returns the miner fee required by the given unsigned transaction,
+// based on its size once the coin inputs spending the given outputs,
+// as well as the given amount of (single) extension signatures, are signed.
+func requiredMinerFee(txn types.Transaction, outputs SpendableOutputs, extensionSignatures uint64, minimumFee types.Currency) (types.Currency, error) {
+	size, err := feemarket.TransactionSize(txn)
+	if err != nil {
+		return types.Currency{}, err
+	}
+	for _, input := range txn.CoinInputs {
+		size += signaturesSize(outputs[input.ParentID].Condition)
+	}
+	size += extensionSignatures * crypto.SignatureSize
+	return feemarket.RequiredMinerFee(size, minimumFee), nil
+}
 
-	// The total funds we will be spending in this transaction
-	requiredFunds := (types.Currency{}).Add(txFee)
-	fmt.Printf("fee: %s\n", requiredFunds.String())
-	for i := range amounts {
-		requiredFunds = requiredFunds.Add(amounts[i])
+// signaturesSize returns the encoded size of the signatures required to fulfill the given condition,
+// which are not yet part of the fulfillment of an unsigned coin input.
+func signaturesSize(condition types.UnlockConditionProxy) uint64 {
+	if msc, ok := condition.Condition.(*types.MultiSignatureCondition); ok {
+		// every signature is paired with its (length-prefixed) public key, prefixed by its algorithm
+		return msc.MinimumSignatureCount * (types.SpecifierLen + 8 + crypto.PublicKeySize + 8 + crypto.SignatureSize)
 	}
-	fmt.Printf("available funds: %s\n", walletBalance.String())
-	fmt.Printf("required funds (with %d outputs): %s\n", len(amounts), requiredFunds.String())
+	return crypto.SignatureSize
+}
 
-	// Verify that we actually have enough funds available in the wallet to complete the transaction
-	if walletBalance.Cmp(requiredFunds) == -1 {
-		return types.TransactionID{}, ErrInsufficientWalletFunds
+// fundCoins selects the unlocked outputs of the wallet used to fund the given amount, returning the (unsigned) coin inputs
+// spending them, the output sending the leftover value back to the wallet (if any) and the outputs of the wallet they spend.
+// The leftover value is sent to the first address, or to a newly generated address if newRefundAddress is set.
+func (w *Wallet) fundCoins(amount types.Currency, newRefundAddress bool) ([]types.CoinInput, *types.CoinOutput, SpendableOutputs, error) {
+	outputs, err := w.getUnspentCoinOutputs()
+	if err != nil {
+		return nil, nil, nil, err
 	}
 
-	// Create the transaction object
-	var txn types.Transaction
-	txn.Version = chainCts.DefaultTransactionVersion
+	// only continue with unlocked outputs
+	outputs, _, err = w.splitTimeLockedOutputs(outputs)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if w.IsWatchOnly() {
+		// outputs of addresses imported without their public key can't be spent
+		for id, co := range outputs {
+			if !w.canSign(co.Condition.UnlockHash()) {
+				delete(outputs, id)
+			}
+		}
+	}
 
-	// Greedily add coin inputs until we have enough to fund the output and minerfee
-	inputs := []types.CoinInput{}
+	walletBalance := w.getBalance(outputs)
+	fmt.Printf("available funds: %s\n", walletBalance.String())
 
-	// Track the amount of coins we already added via the inputs
-	inputValue := types.ZeroCurrency
+	// Verify that we actually have enough funds available in the wallet to complete the transaction
+	if walletBalance.Cmp(amount) == -1 {
+		return nil, nil, nil, ErrInsufficientWalletFunds
+	}
 
-	for id, utxo := range outputs {
-		// If the inputValue is not smaller than the requiredFunds we added enough inputs to fund the transaction
-		if inputValue.Cmp(requiredFunds) != -1 {
-			break
-		}
-		// Append the input
-		inputs = append(inputs, types.CoinInput{
-			ParentID: id,
-			Fulfillment: types.NewFulfillment(types.NewSingleSignatureFulfillment(
-				types.Ed25519PublicKey(w.keys[utxo.Condition.UnlockHash()].PublicKey))),
-		})
-		// And update the value in the transaction
-		inputValue = inputValue.Add(utxo.Value)
+	selected, err := w.getCoinSelector().SelectCoins(outputs, amount, MaxTransactionInputs)
+	if err != nil {
+		return nil, nil, nil, err
 	}
-	// Set the inputs
-	txn.CoinInputs = inputs
+	inputs, inputValue := w.createCoinInputs(selected)
 
 	// sanity checking
 	for _, inp := range inputs {
-		if _, exists := w.keys[outputs[inp.ParentID].Condition.UnlockHash()]; !exists {
-			return types.TransactionID{}, errors.New("Trying to spend unexisting output")
+		if !w.canSign(outputs[inp.ParentID].Condition.UnlockHash()) {
+			return nil, nil, nil, errors.New("Trying to spend unexisting output")
 		}
 	}
 
-	// Add our first output
-	for i, condition := range conditions {
-		amount := amounts[i]
-		txn.CoinOutputs = append(txn.CoinOutputs, types.CoinOutput{
-			Value:     amount,
-			Condition: condition,
-		})
-	}
-
 	// So now we have enough inputs to fund everything. But we might have overshot it a little bit, so lets check that
 	// and add a new output to ourself if required to consume the leftover value
-	remainder := inputValue.Sub(requiredFunds)
-	if !remainder.IsZero() {
-		var refundAddr types.UnlockHash
-		// We have leftover funds, so add a new output
-		if !newRefundAddress {
-			refundAddr = w.firstAddress
-		} else {
-			// generate a new address
-			key, err := generateSpendableKey(w.seed, uint64(len(w.keys)))
-			if err != nil {
-				return types.TransactionID{}, err
-			}
-			refundAddr, err = key.UnlockHash()
-			if err != nil {
-				return types.TransactionID{}, err
-			}
-			w.keys[refundAddr] = key
-			// make sure to save so we update the key count in the persistent data
-			if err = save(w); err != nil {
-				return types.TransactionID{}, err
+	remainder := inputValue.Sub(amount)
+	if remainder.IsZero() {
+		return inputs, nil, outputs, nil
+	}
+	var refundAddr types.UnlockHash
+	if !newRefundAddress {
+		refundAddr = w.firstAddress
+		if _, ok := w.getCoinSelector().(SingleAddressSelector); ok {
+			// refund to the spent address, as to not link it to the first address
+			refundAddr = outputs[inputs[0].ParentID].Condition.UnlockHash()
+		}
+	} else {
+		refundAddr, err = w.generateNewAddress()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	refund := &types.CoinOutput{
+		Value:     remainder,
+		Condition: types.NewCondition(types.NewUnlockHashCondition(refundAddr)),
+	}
+	return inputs, refund, outputs, nil
+}
+
+// generateNewAddress loads the next key of the wallet, returning its address.
+// The wallet is saved, such that the key count is updated in the persistent data.
+func (w *Wallet) generateNewAddress() (types.UnlockHash, error) {
+	if w.IsWatchOnly() {
+		// no new addresses can be derived without the seed
+		return types.UnlockHash{}, ErrWatchOnlyWallet
+	}
+	key, err := generateSpendableKey(w.seed, uint64(len(w.keys)))
+	if err != nil {
+		return types.UnlockHash{}, err
+	}
+	uh, err := key.UnlockHash()
+	if err != nil {
+		return types.UnlockHash{}, err
+	}
+	w.keys[uh] = key
+	w.publicKeys[uh] = types.Ed25519PublicKey(key.PublicKey)
+	w.addresses = append(w.addresses, uh)
+	return uh, save(w)
+}
+
+// Consolidate merges the unlocked outputs of the wallet with a value of at most maxValue
+// (or all unlocked outputs if maxValue is zero) into a single output sent to the first address,
+// using as many transactions as required given the maximum amount of inputs per transaction.
+// The IDs of the submitted transactions are returned.
+func (w *Wallet) Consolidate(maxValue types.Currency) ([]types.TransactionID, error) {
+	if w.IsLocked() {
+		return nil, ErrWalletLocked
+	}
+
+	chainCts, err := w.backend.GetChainConstants()
+	if err != nil {
+		return nil, err
+	}
+	outputs, err := w.getUnspentCoinOutputs()
+	if err != nil {
+		return nil, err
+	}
+	outputs, _, err = w.splitTimeLockedOutputs(outputs)
+	if err != nil {
+		return nil, err
+	}
+
+	// merge the smallest outputs first
+	entries := sortOutputs(outputs, false)
+	if !maxValue.IsZero() {
+		for i, entry := range entries {
+			if entry.output.Value.Cmp(maxValue) > 0 {
+				entries = entries[:i]
+				break
 			}
 		}
-		outputToSelf := types.CoinOutput{
-			Value:     remainder,
-			Condition: types.NewCondition(types.NewUnlockHashCondition(refundAddr)),
+	}
+
+	var txnIDs []types.TransactionID
+	for len(entries) > 1 {
+		batchSize := MaxTransactionInputs
+		if len(entries) < batchSize {
+			batchSize = len(entries)
+		}
+		selected := entriesToOutputs(entries[:batchSize])
+		entries = entries[batchSize:]
+
+		inputs, inputValue := w.createCoinInputs(selected)
+		txn := types.Transaction{
+			Version:    chainCts.DefaultTransactionVersion,
+			CoinInputs: inputs,
+			CoinOutputs: []types.CoinOutput{{
+				Value:     inputValue,
+				Condition: types.NewCondition(types.NewUnlockHashCondition(w.firstAddress)),
+			}},
+			MinerFees: []types.Currency{chainCts.MinimumTransactionFee},
+		}
+		fee, err := requiredMinerFee(txn, selected, 0, chainCts.MinimumTransactionFee)
+		if err != nil {
+			return txnIDs, err
+		}
+		if inputValue.Cmp(fee) <= 0 {
+			// the outputs are not even worth the fee
+			continue
+		}
+		txn.CoinOutputs[0].Value = inputValue.Sub(fee)
+		txn.MinerFees = []types.Currency{fee}
+		if err = w.signTxn(txn, selected); err != nil {
+			return txnIDs, err
+		}
+		txnID, err := w.backend.SendTxn(txn)
+		if err != nil {
+			return txnIDs, err
+		}
+		txnIDs = append(txnIDs, txnID)
+		if err = w.addPending(txnID, coinInputIDs(txn.CoinInputs)); err != nil {
+			return txnIDs, err
 		}
-		// add our self referencing output to the transaction
-		txn.CoinOutputs = append(txn.CoinOutputs, outputToSelf)
 	}
+	return txnIDs, nil
+}
 
-	// Add the miner fee to the transaction
-	txn.MinerFees = []types.Currency{txFee}
+// SetCoinSelector sets the coin selection strategy used to fund transactions.
+func (w *Wallet) SetCoinSelector(selector CoinSelector) {
+	w.coinSelector = selector
+}
 
-	// Make sure to set the data
-	txn.ArbitraryData = data
+func (w *Wallet) getCoinSelector() CoinSelector {
+	if w.coinSelector == nil {
+		return LargestFirstSelector{}
+	}
+	return w.coinSelector
+}
 
-	// sign transaction
-	if err := w.signTxn(txn, outputs); err != nil {
-		return types.TransactionID{}, err
+// createCoinInputs creates the (unsigned) coin inputs spending the given outputs,
+// ordered by value, returning them together with their total value
+func (w *Wallet) createCoinInputs(outputs SpendableOutputs) ([]types.CoinInput, types.Currency) {
+	inputs := make([]types.CoinInput, 0, len(outputs))
+	inputValue := types.ZeroCurrency
+	for _, entry := range sortOutputs(outputs, true) {
+		inputs = append(inputs, types.CoinInput{
+			ParentID: entry.id,
+			Fulfillment: types.NewFulfillment(types.NewSingleSignatureFulfillment(
+				w.publicKeys[entry.output.Condition.UnlockHash()])),
+		})
+		inputValue = inputValue.Add(entry.output.Value)
 	}
+	return inputs, inputValue
+}
 
-	// finally commit
-	return w.backend.SendTxn(txn)
+// coinInputIDs returns the IDs of the outputs spent by the given coin inputs
+func coinInputIDs(inputs []types.CoinInput) []types.CoinOutputID {
+	ids := make([]types.CoinOutputID, 0, len(inputs))
+	for _, ci := range inputs {
+		ids = append(ids, ci.ParentID)
+	}
+	return ids
 }
 
 // ListAddresses returns all currently loaded addresses
 func (w *Wallet) ListAddresses() []types.UnlockHash {
-	var addresses []types.UnlockHash
-	for key := range w.keys {
-		addresses = append(addresses, key)
-	}
+	addresses := make([]types.UnlockHash, len(w.addresses))
+	copy(addresses, w.addresses)
 	return addresses
 }
 
 // LoadKeys loads `amount` additional keys in the wallet and saves the wallet state
 func (w *Wallet) LoadKeys(amount uint64) error {
+	if w.IsWatchOnly() {
+		return ErrWatchOnlyWallet
+	}
+	if w.IsLocked() {
+		return ErrWalletLocked
+	}
 	currentKeys := len(w.keys)
 	w.generateKeys(uint64(currentKeys) + amount)
 	return save(w)
 }
 
-func (w *Wallet) getUnspentCoinOutputs() (SpendableOutputs, error) {
-	workerCount := WorkerCount
+// DiscoverKeys derives keys from the seed until gapLimit consecutive addresses are found
+// which have never been used on the chain, and loads all keys up to the last used address,
+// saving the wallet state. Already loaded keys are never removed. The amount of loaded keys is returned.
+func (w *Wallet) DiscoverKeys(gapLimit uint64) (uint64, error) {
+	if gapLimit == 0 {
+		return 0, errors.New("the gap limit has to be at least 1")
+	}
+	if w.IsWatchOnly() {
+		return 0, ErrWatchOnlyWallet
+	}
+	if w.IsLocked() {
+		return 0, ErrWalletLocked
+	}
 
-	if len(w.keys) < workerCount {
-		workerCount = len(w.keys)
+	keysToLoad := uint64(len(w.addresses))
+	var usedKeys uint64
+	for index := uint64(0); index < usedKeys+gapLimit; index += gapLimit {
+		addresses := make([]types.UnlockHash, 0, gapLimit)
+		for i := index; i < index+gapLimit; i++ {
+			key, err := generateSpendableKey(w.seed, i)
+			if err != nil {
+				return 0, err
+			}
+			uh, err := key.UnlockHash()
+			if err != nil {
+				return 0, err
+			}
+			addresses = append(addresses, uh)
+		}
+		used, err := w.getUsedAddresses(addresses)
+		if err != nil {
+			return 0, err
+		}
+		// addresses are checked in batches, but only those within the gap limit
+		// of the last used address are taken into account
+		for i, uh := range addresses {
+			if index+uint64(i) >= usedKeys+gapLimit {
+				break
+			}
+			if used[uh] {
+				usedKeys = index + uint64(i) + 1
+			}
+		}
+	}
+	if usedKeys > keysToLoad {
+		keysToLoad = usedKeys
 	}
 
-	currentChainHeight, err := w.backend.CurrentHeight()
+	if err := w.generateKeys(keysToLoad); err != nil {
+		return 0, err
+	}
+	return keysToLoad, save(w)
+}
+
+// getUsedAddresses returns which of the given addresses
+// are referenced by any block or transaction on the chain
+func (w *Wallet) getUsedAddresses(addresses []types.UnlockHash) (map[types.UnlockHash]bool, error) {
+	histories, err := w.getAddressHistories(addresses)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[types.UnlockHash]bool, len(addresses))
+	for idx, uh := range addresses {
+		result[uh] = len(histories[idx].blocks) > 0 || len(histories[idx].transactions) > 0
+	}
+	return result, nil
+}
+
+// getUnspentCoinOutputs returns the unspent coin outputs of the wallet,
+// excluding miner payouts which haven't matured yet and outputs spent by pending transactions
+func (w *Wallet) getUnspentCoinOutputs() (SpendableOutputs, error) {
+	currentChainHeight, err := w.sync()
+	if err != nil {
+		return nil, err
+	}
+	pendingSpent, err := w.updatePending(w.cache.outputs)
 	if err != nil {
 		return nil, err
 	}
@@ -349,27 +821,47 @@ func (w *Wallet) getUnspentCoinOutputs() (SpendableOutputs, error) {
 		return nil, err
 	}
 
-	jobs := make(chan jobContext, len(w.keys))
-	results := make(chan SpendableOutputs)
-	errChan := make(chan error, len(w.keys))
+	ucos := make(SpendableOutputs)
+	for id, co := range w.cache.outputs {
+		if co.MinerPayout && co.Height+chainCts.MaturityDelay >= currentChainHeight {
+			// ignore miner payout which hasn't yet matured
+			continue
+		}
+		if _, ok := pendingSpent[id]; ok {
+			// ignore outputs spent by a transaction which isn't confirmed yet
+			continue
+		}
+		ucos[id] = co.Output
+	}
+	return ucos, nil
+}
+
+// getAddressOutputs fetches the full history of the given addresses,
+// returning their unspent coin outputs, including immature miner payouts
+func (w *Wallet) getAddressOutputs(addresses []types.UnlockHash) (map[types.CoinOutputID]cachedOutput, error) {
+	workerCount := WorkerCount
+
+	if len(addresses) < workerCount {
+		workerCount = len(addresses)
+	}
+
+	jobs := make(chan types.UnlockHash, len(addresses))
+	results := make(chan map[types.CoinOutputID]cachedOutput)
+	errChan := make(chan error, len(addresses))
 
 	for worker := 1; worker <= workerCount; worker++ {
 		go w.checkAddress(jobs, results, errChan)
 	}
 
-	for address := range w.keys {
-		jobs <- jobContext{
-			address:            address,
-			currentChainHeight: currentChainHeight,
-			maturityDelay:      chainCts.MaturityDelay,
-		}
+	for _, address := range addresses {
+		jobs <- address
 	}
 	close(jobs)
 
-	ucos := make(SpendableOutputs)
+	ucos := make(map[types.CoinOutputID]cachedOutput)
 	errs := make([]string, 0)
 
-	for i := 0; i < len(w.keys); i++ {
+	for i := 0; i < len(addresses); i++ {
 		select {
 		case res := <-results:
 			for k, v := range res {
@@ -383,6 +875,7 @@ func (w *Wallet) getUnspentCoinOutputs() (SpendableOutputs, error) {
 	close(results)
 	close(errChan)
 
+	var err error
 	if len(errs) > 0 {
 		err = errors.New(strings.Join(errs, "\n"))
 	}
@@ -390,52 +883,79 @@ func (w *Wallet) getUnspentCoinOutputs() (SpendableOutputs, error) {
 	return ucos, err
 }
 
-func (w *Wallet) checkAddress(jobs <-chan jobContext, results chan<- SpendableOutputs, errChan chan<- error) {
-	for context := range jobs {
-		blocks, transactions, err := w.backend.CheckAddress(context.address)
+func (w *Wallet) checkAddress(jobs <-chan types.UnlockHash, results chan<- map[types.CoinOutputID]cachedOutput, errChan chan<- error) {
+	for address := range jobs {
+		blocks, transactions, err := w.getAddressHistory(address)
 		if err != nil {
 			errChan <- err
 			continue
 		}
-		tempMap := make(SpendableOutputs)
+		results <- collectAddressOutputs(address, blocks, transactions)
+	}
+}
 
-		// We scann the blocks here for the miner fees, and the transactions for actual transactions
-		for _, block := range blocks {
-			// Collect the miner fees
-			// But only those that have matured already
-			if block.Height+context.maturityDelay >= context.currentChainHeight {
-				// ignore miner payout which hasn't yet matured
-				continue
-			}
-			for i, minerPayout := range block.RawBlock.MinerPayouts {
-				if minerPayout.UnlockHash == context.address {
-					tempMap[block.MinerPayoutIDs[i]] = types.CoinOutput{
+// getAddressHistory returns all blocks and transactions related to the given address,
+// an address the explorer doesn't know about has no history
+func (w *Wallet) getAddressHistory(address types.UnlockHash) ([]api.ExplorerBlock, []api.ExplorerTransaction, error) {
+	blocks, transactions, err := w.backend.CheckAddress(address)
+	if err == explorer.ErrUnrecognizedHash {
+		return nil, nil, nil
+	}
+	return blocks, transactions, err
+}
+
+// collectAddressOutputs returns the unspent coin outputs of an address, given its full history,
+// including immature miner payouts
+func collectAddressOutputs(address types.UnlockHash, blocks []api.ExplorerBlock, transactions []api.ExplorerTransaction) map[types.CoinOutputID]cachedOutput {
+	tempMap := make(map[types.CoinOutputID]cachedOutput)
+
+	// We scann the blocks here for the miner fees, and the transactions for actual transactions
+	for _, block := range blocks {
+		// Collect the miner fees, maturity is checked when the outputs are used
+		for i, minerPayout := range block.RawBlock.MinerPayouts {
+			if minerPayout.UnlockHash == address {
+				tempMap[block.MinerPayoutIDs[i]] = cachedOutput{
+					ID: block.MinerPayoutIDs[i],
+					Output: types.CoinOutput{
 						Value: minerPayout.Value,
 						Condition: types.UnlockConditionProxy{
 							Condition: types.NewUnlockHashCondition(minerPayout.UnlockHash),
 						},
-					}
+					},
+					Height:      block.Height,
+					MinerPayout: true,
 				}
 			}
 		}
+	}
 
-		// Collect the transaction outputs
-		for _, txn := range transactions {
-			for i, utxo := range txn.RawTransaction.CoinOutputs {
-				if utxo.Condition.UnlockHash() == context.address {
-					tempMap[txn.CoinOutputIDs[i]] = utxo
+	// Collect the transaction outputs,
+	// ignoring unconfirmed transactions as only confirmed outputs are cached
+	for _, txn := range transactions {
+		if txn.Unconfirmed {
+			continue
+		}
+		for i, utxo := range txn.RawTransaction.CoinOutputs {
+			if utxo.Condition.UnlockHash() == address {
+				tempMap[txn.CoinOutputIDs[i]] = cachedOutput{
+					ID:     txn.CoinOutputIDs[i],
+					Output: utxo,
+					Height: txn.Height,
 				}
 			}
 		}
-		// Remove the ones we've spent already
-		for _, txn := range transactions {
-			for _, ci := range txn.RawTransaction.CoinInputs {
-				delete(tempMap, ci.ParentID)
-			}
+	}
+	// Remove the ones we've spent already
+	for _, txn := range transactions {
+		if txn.Unconfirmed {
+			continue
+		}
+		for _, ci := range txn.RawTransaction.CoinInputs {
+			delete(tempMap, ci.ParentID)
 		}
-
-		results <- tempMap
 	}
+
+	return tempMap
 }
 
 // splitTimeLockedOutputs separates a list of SpendableOutputs into a list of outputs which can be spent right now (no timelock or
@@ -464,6 +984,8 @@ func (w *Wallet) splitTimeLockedOutputs(outputs SpendableOutputs) (SpendableOutp
 // generateKeys clears all existing keys and generates up to amount keys. If amount <= len(w.keys), no new keys will be generated
 func (w *Wallet) generateKeys(amount uint64) error {
 	w.keys = make(map[types.UnlockHash]spendableKey)
+	w.publicKeys = make(map[types.UnlockHash]types.PublicKey)
+	w.addresses = make([]types.UnlockHash, 0, amount)
 
 	for i := 0; i < int(amount); i++ {
 		key, err := generateSpendableKey(w.seed, uint64(i))
@@ -475,6 +997,8 @@ func (w *Wallet) generateKeys(amount uint64) error {
 			return err
 		}
 		w.keys[uh] = key
+		w.publicKeys[uh] = types.Ed25519PublicKey(key.PublicKey)
+		w.addresses = append(w.addresses, uh)
 		if i == 0 {
 			w.firstAddress = uh
 		}
@@ -502,8 +1026,84 @@ func (w *Wallet) signTxn(txn types.Transaction, usedOutputIDs SpendableOutputs)
 	return nil
 }
 
+// signAndSendTransaction signs the coin inputs and the extension of the transaction,
+// and submits it. The outputs it spends are tracked as pending until it is confirmed.
+func (w *Wallet) signAndSendTransaction(txn types.Transaction, outputs SpendableOutputs) (types.TransactionID, error) {
+	if err := w.signTxn(txn, outputs); err != nil {
+		return types.TransactionID{}, err
+	}
+	if _, err := w.signExtension(&txn); err != nil {
+		return types.TransactionID{}, err
+	}
+	txnID, err := w.backend.SendTxn(txn)
+	if err != nil {
+		return types.TransactionID{}, err
+	}
+	return txnID, w.addPending(txnID, coinInputIDs(txn.CoinInputs))
+}
+
+// signExtension signs the extension of the transaction, such as the signatures of 3bot and ERC20 transactions,
+// using every key of the wallet requested by it, returning the amount of signatures
+func (w *Wallet) signExtension(txn *types.Transaction) (int, error) {
+	var signatures int
+	err := txn.SignExtension(func(fulfillment *types.UnlockFulfillmentProxy, condition types.UnlockConditionProxy, extraObjects ...interface{}) error {
+		key, ok := w.keys[condition.UnlockHash()]
+		if !ok {
+			// signed by another wallet
+			return nil
+		}
+		signatures++
+		return fulfillment.Sign(types.FulfillmentSignContext{
+			ExtraObjects: extraObjects,
+			Transaction:  *txn,
+			Key:          key.SecretKey,
+		})
+	})
+	return signatures, err
+}
+
+// checkCanSign returns an error if the wallet can't sign transactions
+func (w *Wallet) checkCanSign() error {
+	if w.IsWatchOnly() {
+		return ErrWatchOnlyWallet
+	}
+	if w.IsLocked() {
+		return ErrWalletLocked
+	}
+	return nil
+}
+
+// ownsKey returns true if the given public key is a key of the wallet
+func (w *Wallet) ownsKey(pk types.PublicKey) bool {
+	uh, err := types.NewPubKeyUnlockHash(pk)
+	if err != nil {
+		return false
+	}
+	_, ok := w.keys[uh]
+	return ok
+}
+
+// identityKey returns the given public key, or the key of a newly generated address if none is given,
+// used to identify the owner of 3bots and ERC20 addresses
+func (w *Wallet) identityKey(pk *types.PublicKey) (types.PublicKey, error) {
+	if pk != nil {
+		return *pk, nil
+	}
+	uh, err := w.generateNewAddress()
+	if err != nil {
+		return types.PublicKey{}, err
+	}
+	return w.publicKeys[uh], nil
+}
+
 // Mnemonic returns the human readable form of the seed
 func (w *Wallet) Mnemonic() (string, error) {
+	if w.IsWatchOnly() {
+		return "", ErrWatchOnlyWallet
+	}
+	if w.IsLocked() {
+		return "", ErrWalletLocked
+	}
 	return modules.NewMnemonic(w.seed)
 }
 
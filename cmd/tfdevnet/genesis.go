@@ -0,0 +1,164 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/threefoldfoundation/tfchain/pkg/config"
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+const (
+	// genesisFileName is the name of the genesis file stored in the root directory of the devnet
+	genesisFileName = "genesis.json"
+	// stateFileName is the name of the file describing the devnet, stored in its root directory
+	stateFileName = "devnet.json"
+	// erc20WithdrawalsFileName is the name of the file containing the ERC20 withdrawals
+	// observed by the local ERC20 validators of all nodes, stored in the root directory of the devnet
+	erc20WithdrawalsFileName = "erc20-withdrawals.json"
+)
+
+type (
+	// Devnet describes a local devnet, and is stored as JSON in the root directory of that devnet,
+	// such that (CI) scripts can find the nodes and their wallets.
+	Devnet struct {
+		Name        string `json:"name"`
+		GenesisFile string `json:"genesisfile"`
+		// ERC20WithdrawalsFile is shared by all nodes, listing the ERC20 withdrawals
+		// they consider to be observed, see pkg/erc20.LocalERC20TransactionValidator.
+		ERC20WithdrawalsFile string `json:"erc20withdrawalsfile"`
+		Nodes                []Node `json:"nodes"`
+	}
+
+	// Node describes a single node of a local devnet.
+	Node struct {
+		Name       string             `json:"name"`
+		Dir        string             `json:"dir"`
+		RPCAddress modules.NetAddress `json:"rpcaddress"`
+		APIAddress string             `json:"apiaddress"`
+		Modules    string             `json:"modules"`
+		PID        int                `json:"pid,omitempty"`
+
+		// Seed is the hex-encoded seed of the wallet of the node,
+		// its first address owns the genesis coins and block stakes of the node
+		Seed     string           `json:"seed"`
+		Mnemonic string           `json:"mnemonic"`
+		Address  types.UnlockHash `json:"address"`
+	}
+)
+
+// generateDevnet generates a new devnet of the given amount of nodes,
+// each with their own wallet, funded with coins and block stakes in the genesis block.
+// The wallet of the first node owns the auth and minting condition,
+// as well as the foundation and ERC20 fee pools.
+func generateDevnet(opts devnetOptions) (*Devnet, *config.NetworkDefinition, error) {
+	devnet := &Devnet{
+		Name:                 opts.NetworkName,
+		GenesisFile:          filepath.Join(opts.Dir, genesisFileName),
+		ERC20WithdrawalsFile: filepath.Join(opts.Dir, erc20WithdrawalsFileName),
+	}
+	def := config.DefaultNetworkDefinition()
+	def.Name = opts.NetworkName
+	// create blocks fast, with block stakes which can be used immediately
+	def.Constants.BlockFrequency = opts.BlockFrequency
+	def.Constants.MaturityDelay = 3
+	def.Constants.BlockStakeAging = 0
+	def.Constants.GenesisTimestamp = types.CurrentTimestamp()
+
+	oneCoin := config.GetCurrencyUnits().OneCoin
+	for i := 0; i < opts.Nodes; i++ {
+		var seed modules.Seed
+		_, err := rand.Read(seed[:])
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to generate seed of node #%d: %v", i, err)
+		}
+		mnemonic, err := modules.NewMnemonic(seed)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create mnemonic of node #%d: %v", i, err)
+		}
+		address, err := firstAddress(seed)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create address of node #%d: %v", i, err)
+		}
+		condition := types.NewCondition(types.NewUnlockHashCondition(address))
+
+		rpcPort := opts.BasePort + i*10
+		node := Node{
+			Name:       fmt.Sprintf("node%d", i),
+			Dir:        filepath.Join(opts.Dir, fmt.Sprintf("node%d", i)),
+			RPCAddress: modules.NetAddress(fmt.Sprintf("127.0.0.1:%d", rpcPort)),
+			APIAddress: fmt.Sprintf("127.0.0.1:%d", rpcPort+1),
+			Modules:    "gctwb",
+			Seed:       seed.String(),
+			Mnemonic:   mnemonic,
+			Address:    address,
+		}
+		if i == 0 {
+			// the first node also serves as the explorer of the devnet
+			node.Modules = "gctwbe"
+			def.AuthCondition = condition
+			def.MintingCondition = condition
+			def.FoundationPoolAddress = address
+			def.ERC20FeePoolAddress = address
+			def.Explorers = append(def.Explorers, "http://"+node.APIAddress)
+		}
+		devnet.Nodes = append(devnet.Nodes, node)
+
+		def.BootstrapPeers = append(def.BootstrapPeers, node.RPCAddress)
+		def.Genesis.CoinOutputs = append(def.Genesis.CoinOutputs, types.CoinOutput{
+			Value:     oneCoin.Mul64(opts.Coins),
+			Condition: condition,
+		})
+		def.Genesis.BlockStakeOutputs = append(def.Genesis.BlockStakeOutputs, types.BlockStakeOutput{
+			Value:     types.NewCurrency64(opts.BlockStakes),
+			Condition: condition,
+		})
+	}
+	err := def.Validate()
+	if err != nil {
+		return nil, nil, fmt.Errorf("generated invalid network definition: %v", err)
+	}
+	return devnet, def, nil
+}
+
+// firstAddress returns the first address of a wallet using the given seed,
+// derived in the same way as the rivine wallet does.
+func firstAddress(seed modules.Seed) (types.UnlockHash, error) {
+	entropy, err := crypto.HashAll(seed, uint64(0))
+	if err != nil {
+		return types.UnlockHash{}, err
+	}
+	_, pk := crypto.GenerateKeyPairDeterministic(entropy)
+	return types.NewEd25519PubKeyUnlockHash(pk)
+}
+
+// save the devnet description and genesis file in the root directory of the devnet
+func (devnet *Devnet) save(dir string, def *config.NetworkDefinition) error {
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return err
+	}
+	if def != nil {
+		err = writeJSONFile(devnet.GenesisFile, def)
+		if err != nil {
+			return err
+		}
+	}
+	return writeJSONFile(filepath.Join(dir, stateFileName), devnet)
+}
+
+func writeJSONFile(path string, value interface{}) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
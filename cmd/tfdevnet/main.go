@@ -0,0 +1,255 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// devnetOptions contains all configurable variables of tfdevnet
+type devnetOptions struct {
+	Nodes          int
+	Dir            string
+	NetworkName    string
+	BasePort       int
+	BlockFrequency types.BlockHeight
+	Coins          uint64
+	BlockStakes    uint64
+
+	TfchaindBinary string
+	StartTimeout   time.Duration
+	StopTimeout    time.Duration
+	Keep           bool
+}
+
+type commands struct {
+	opts devnetOptions
+}
+
+// genesisCommand only generates the genesis file and wallets of a devnet
+func (cmds *commands) genesisCommand(_ *cobra.Command, _ []string) error {
+	if err := cmds.validate(); err != nil {
+		return err
+	}
+	devnet, def, err := generateDevnet(cmds.opts)
+	if err != nil {
+		return err
+	}
+	err = devnet.save(cmds.opts.Dir, def)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Generated devnet", devnet.Name, "in", cmds.opts.Dir)
+	return nil
+}
+
+// upCommand generates a new devnet and runs all its nodes,
+// until the process is interrupted, tearing the devnet down afterwards.
+func (cmds *commands) upCommand(_ *cobra.Command, _ []string) (cmdErr error) {
+	if err := cmds.validate(); err != nil {
+		return err
+	}
+	binary, err := findTfchaind(cmds.opts.TfchaindBinary)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(cmds.opts.Dir); err == nil {
+		return fmt.Errorf("directory %s already exists, remove it first or use another directory", cmds.opts.Dir)
+	}
+
+	devnet, def, err := generateDevnet(cmds.opts)
+	if err != nil {
+		return err
+	}
+	err = devnet.save(cmds.opts.Dir, def)
+	if err != nil {
+		return err
+	}
+
+	var nodes []*nodeProcess
+	defer func() {
+		fmt.Println("Stopping devnet...")
+		for i := len(nodes) - 1; i >= 0; i-- {
+			if err := nodes[i].stop(cmds.opts.StopTimeout); err != nil {
+				fmt.Fprintln(os.Stderr, "Error during node shutdown:", err)
+			}
+		}
+		if cmds.opts.Keep || cmdErr != nil {
+			fmt.Println("Kept devnet data in", cmds.opts.Dir)
+			return
+		}
+		if err := os.RemoveAll(cmds.opts.Dir); err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to remove devnet data:", err)
+		}
+	}()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+
+	// start all nodes, each bootstrapping from the first node
+	deadline := time.Now().Add(cmds.opts.StartTimeout)
+	for i, node := range devnet.Nodes {
+		fmt.Printf("Starting %s (api: %s, rpc: %s)...\r\n", node.Name, node.APIAddress, node.RPCAddress)
+		np, err := startNode(binary, node, devnet)
+		if err != nil {
+			return err
+		}
+		nodes = append(nodes, np)
+		devnet.Nodes[i].PID = np.PID
+	}
+	for _, np := range nodes {
+		err = np.waitForAPI(deadline)
+		if err != nil {
+			return err
+		}
+		err = np.initWallet()
+		if err != nil {
+			return err
+		}
+	}
+
+	// the devnet is considered up as soon as all nodes agree on a first block
+	fmt.Println("Waiting for the first block to be created...")
+	err = waitForFirstBlock(nodes, deadline, sigChan)
+	if err != nil {
+		return err
+	}
+	err = devnet.save(cmds.opts.Dir, nil)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Devnet %s is up with %d node(s), described in %s\r\n",
+		devnet.Name, len(nodes), filepath.Join(cmds.opts.Dir, stateFileName))
+
+	// run until interrupted, or until one of the nodes stops
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-sigChan:
+			fmt.Println("\rCaught stop signal, quitting...")
+			return nil
+		case <-ticker.C:
+			for _, np := range nodes {
+				if err := np.exited(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+func waitForFirstBlock(nodes []*nodeProcess, deadline time.Time, sigChan <-chan os.Signal) error {
+	for {
+		synced := true
+		for _, np := range nodes {
+			if err := np.exited(); err != nil {
+				return err
+			}
+			height, err := np.height()
+			if err != nil || height == 0 {
+				synced = false
+				break
+			}
+		}
+		if synced {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return errors.New("devnet did not create its first block in time")
+		}
+		select {
+		case <-sigChan:
+			return errors.New("interrupted while starting devnet")
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+func (cmds *commands) validate() error {
+	if cmds.opts.Nodes < 1 {
+		return errors.New("at least one node is required")
+	}
+	if cmds.opts.BasePort < 1 || cmds.opts.BasePort+cmds.opts.Nodes*10 > 65535 {
+		return fmt.Errorf("base port %d is invalid for %d node(s)", cmds.opts.BasePort, cmds.opts.Nodes)
+	}
+	if cmds.opts.BlockFrequency == 0 {
+		return errors.New("block frequency cannot be 0")
+	}
+	dir, err := filepath.Abs(cmds.opts.Dir)
+	if err != nil {
+		return err
+	}
+	cmds.opts.Dir = dir
+	return nil
+}
+
+// findTfchaind returns the path of the tfchaind binary,
+// looking next to the tfdevnet binary if it can't be found in the PATH.
+func findTfchaind(binary string) (string, error) {
+	path, err := exec.LookPath(binary)
+	if err == nil {
+		return path, nil
+	}
+	if self, selfErr := os.Executable(); selfErr == nil {
+		path = filepath.Join(filepath.Dir(self), binary)
+		if _, statErr := os.Stat(path); statErr == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("failed to find tfchaind binary %q: %v", binary, err)
+}
+
+func main() {
+	cmds := new(commands)
+
+	rootCmd := &cobra.Command{
+		Use:          "tfdevnet",
+		Short:        "Run a local multi-node tfchain devnet",
+		Long:         "Run a local multi-node tfchain devnet, using a generated genesis block, for (end-to-end) testing purposes.",
+		SilenceUsage: true,
+	}
+	upCmd := &cobra.Command{
+		Use:   "up",
+		Short: "Generate and run a new devnet, until interrupted",
+		Long: `Generate and run a new devnet, until interrupted.
+All nodes run on the loopback interface and have a wallet funded with coins and block stakes.
+The wallet of the first node owns the auth and minting conditions.
+The devnet is described in the devnet.json file of the devnet directory, once all nodes are up.`,
+		Args: cobra.NoArgs,
+		RunE: cmds.upCommand,
+	}
+	genesisCmd := &cobra.Command{
+		Use:   "genesis",
+		Short: "Only generate the genesis file and wallets of a new devnet",
+		Args:  cobra.NoArgs,
+		RunE:  cmds.genesisCommand,
+	}
+	rootCmd.AddCommand(upCmd, genesisCmd)
+
+	for _, cmd := range []*cobra.Command{upCmd, genesisCmd} {
+		cmd.Flags().IntVarP(&cmds.opts.Nodes, "nodes", "n", 3, "amount of nodes in the devnet")
+		cmd.Flags().StringVarP(&cmds.opts.Dir, "dir", "d", "tfdevnet", "directory in which all devnet data is stored")
+		cmd.Flags().StringVar(&cmds.opts.NetworkName, "network", "localnet", "name of the devnet")
+		cmd.Flags().IntVar(&cmds.opts.BasePort, "base-port", 24112, "RPC port of the first node, every next node uses a port 10 higher, the API port is the RPC port + 1")
+		cmd.Flags().Uint64Var((*uint64)(&cmds.opts.BlockFrequency), "block-frequency", 5, "average amount of seconds between two blocks")
+		cmd.Flags().Uint64Var(&cmds.opts.Coins, "coins", 1000000, "amount of coins given to the wallet of each node in the genesis block")
+		cmd.Flags().Uint64Var(&cmds.opts.BlockStakes, "block-stakes", 1000, "amount of block stakes given to the wallet of each node in the genesis block")
+	}
+	upCmd.Flags().StringVar(&cmds.opts.TfchaindBinary, "tfchaind", "tfchaind", "tfchaind binary used to run the nodes")
+	upCmd.Flags().DurationVar(&cmds.opts.StartTimeout, "start-timeout", 2*time.Minute, "maximum duration to wait for the devnet to be up")
+	upCmd.Flags().DurationVar(&cmds.opts.StopTimeout, "stop-timeout", 30*time.Second, "maximum duration to wait for a node to stop gracefully")
+	upCmd.Flags().BoolVar(&cmds.opts.Keep, "keep", false, "keep the devnet data once the devnet is stopped")
+
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	rivineapi "github.com/threefoldtech/rivine/pkg/api"
+	"github.com/threefoldtech/rivine/pkg/daemon"
+	"github.com/threefoldtech/rivine/types"
+)
+
+const (
+	// walletPassphrase is the passphrase used to encrypt the wallets of all nodes,
+	// a devnet is meant for testing only, so there is no need to keep it secret
+	walletPassphrase = "tfdevnet"
+
+	// nodeLogFileName is the name of the file the output of a node is written to
+	nodeLogFileName = "tfchaind.log"
+)
+
+// nodeProcess is a (local) tfchaind process, running a node of the devnet
+type nodeProcess struct {
+	Node
+
+	cmd  *exec.Cmd
+	done chan struct{}
+	err  error
+}
+
+// startNode starts a tfchaind process for the given node,
+// writing its output to a log file in the directory of that node.
+func startNode(binary string, node Node, devnet *Devnet) (*nodeProcess, error) {
+	err := os.MkdirAll(node.Dir, 0700)
+	if err != nil {
+		return nil, err
+	}
+	logFile, err := os.Create(filepath.Join(node.Dir, nodeLogFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{
+		"--genesis-file", devnet.GenesisFile,
+		"--persistent-directory", node.Dir,
+		"--rpc-addr", string(node.RPCAddress),
+		"--api-addr", node.APIAddress,
+		"--modules", node.Modules,
+		// validate ERC20 withdrawals offline, against the withdrawals file shared by all nodes
+		"--erc20-local",
+		"--erc20-local-file", devnet.ERC20WithdrawalsFile,
+	}
+	bootstrapPeer := devnet.Nodes[0]
+	if bootstrapPeer.Name == node.Name {
+		// the first node doesn't have anyone to bootstrap from
+		args = append(args, "--no-bootstrap")
+	} else {
+		args = append(args, "--bootstrap-peers", string(bootstrapPeer.RPCAddress))
+	}
+
+	cmd := exec.Command(binary, args...)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	err = cmd.Start()
+	if err != nil {
+		logFile.Close()
+		return nil, fmt.Errorf("failed to start %s: %v", node.Name, err)
+	}
+	node.PID = cmd.Process.Pid
+
+	np := &nodeProcess{
+		Node: node,
+		cmd:  cmd,
+		done: make(chan struct{}),
+	}
+	go func() {
+		np.err = cmd.Wait()
+		logFile.Close()
+		close(np.done)
+	}()
+	return np, nil
+}
+
+// client returns an HTTP client for the API of the node
+func (np *nodeProcess) client() *rivineapi.HTTPClient {
+	return &rivineapi.HTTPClient{
+		RootURL:   "http://" + np.APIAddress,
+		UserAgent: daemon.RivineUserAgent,
+	}
+}
+
+// exited returns an error if the process of the node is no longer running
+func (np *nodeProcess) exited() error {
+	select {
+	case <-np.done:
+		return fmt.Errorf("%s exited unexpectedly (%v), see %s",
+			np.Name, np.err, filepath.Join(np.Dir, nodeLogFileName))
+	default:
+		return nil
+	}
+}
+
+// waitForAPI waits until the API of the node is served,
+// and the node has loaded all its modules.
+func (np *nodeProcess) waitForAPI(deadline time.Time) error {
+	for {
+		if err := np.exited(); err != nil {
+			return err
+		}
+		var status struct {
+			Modules map[string]struct {
+				Ready bool `json:"ready"`
+			} `json:"modules"`
+		}
+		err := np.client().GetWithResponse("/daemon/health", &status)
+		if err == nil && status.Modules["daemon"].Ready {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%s did not load in time", np.Name)
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+}
+
+// initWallet initializes the wallet of the node using its seed, and unlocks it,
+// such that it can start creating blocks using its block stakes.
+func (np *nodeProcess) initWallet() error {
+	cl := np.client()
+	data := url.Values{}
+	data.Set("seed", np.Seed)
+	data.Set("passphrase", walletPassphrase)
+	err := cl.Post("/wallet/init", data.Encode())
+	if err != nil {
+		return fmt.Errorf("failed to init wallet of %s: %v", np.Name, err)
+	}
+	data = url.Values{}
+	data.Set("passphrase", walletPassphrase)
+	err = cl.Post("/wallet/unlock", data.Encode())
+	if err != nil {
+		return fmt.Errorf("failed to unlock wallet of %s: %v", np.Name, err)
+	}
+	return nil
+}
+
+// height returns the current consensus height of the node
+func (np *nodeProcess) height() (types.BlockHeight, error) {
+	var cg rivineapi.ConsensusGET
+	err := np.client().GetWithResponse("/consensus", &cg)
+	return cg.Height, err
+}
+
+// stop the node, gracefully if possible, killing it otherwise
+func (np *nodeProcess) stop(timeout time.Duration) error {
+	if np.exited() != nil {
+		return nil // already stopped
+	}
+	err := np.client().Post("/daemon/stop", "")
+	if err != nil {
+		// ignore the error, as the node might be stopping already
+		np.cmd.Process.Signal(os.Interrupt)
+	}
+	select {
+	case <-np.done:
+		return nil
+	case <-time.After(timeout):
+	}
+	np.cmd.Process.Kill() // ignore the error, as the node might have stopped in the meantime
+	<-np.done
+	return fmt.Errorf("%s did not stop in time and was killed", np.Name)
+}
@@ -0,0 +1,245 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	ctypes "github.com/threefoldfoundation/tfchain/extensions/capacity/types"
+
+	"github.com/threefoldtech/rivine/pkg/api"
+	"github.com/threefoldtech/rivine/types"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// RegisterConsensusHTTPHandlers registers the capacity handlers for all consensus HTTP endpoints.
+func RegisterConsensusHTTPHandlers(router api.Router, registry ctypes.CapacityReadRegistry, farmRegistry ctypes.FarmReadRegistry) {
+	if registry == nil {
+		panic("no CapacityReadRegistry API given")
+	}
+	if farmRegistry == nil {
+		panic("no FarmReadRegistry API given")
+	}
+	if router == nil {
+		panic("no httprouter Router given")
+	}
+
+	router.GET("/consensus/capacity/node/:node", NewGetNodeCapacityHandler(registry))
+	router.GET("/consensus/capacity/farmer/:address", NewGetFarmerHandler(registry))
+	router.GET("/consensus/capacity/farm/:id", NewGetFarmForIDHandler(farmRegistry))
+	router.GET("/consensus/capacity/farmname/:name", NewGetFarmForNameHandler(farmRegistry))
+	router.GET("/consensus/capacity/farm/:id/nodes", NewGetFarmNodesHandler(farmRegistry))
+	router.GET("/consensus/capacity/farm/:id/capacity", NewGetFarmCapacityHandler(registry))
+	router.GET("/consensus/capacity/node/:node/farm", NewGetNodeFarmHandler(farmRegistry))
+	router.GET("/consensus/capacity/farm/:id/rewards", NewGetFarmRewardsHandler(farmRegistry))
+}
+
+// RegisterExplorerHTTPHandlers registers the capacity handlers for all explorer HTTP endpoints.
+func RegisterExplorerHTTPHandlers(router api.Router, registry ctypes.CapacityReadRegistry, farmRegistry ctypes.FarmReadRegistry) {
+	if registry == nil {
+		panic("no CapacityReadRegistry API given")
+	}
+	if farmRegistry == nil {
+		panic("no FarmReadRegistry API given")
+	}
+	if router == nil {
+		panic("no httprouter Router given")
+	}
+
+	router.GET("/explorer/capacity/node/:node", NewGetNodeCapacityHandler(registry))
+	router.GET("/explorer/capacity/farmer/:address", NewGetFarmerHandler(registry))
+	router.GET("/explorer/capacity/farm/:id", NewGetFarmForIDHandler(farmRegistry))
+	router.GET("/explorer/capacity/farmname/:name", NewGetFarmForNameHandler(farmRegistry))
+	router.GET("/explorer/capacity/farm/:id/nodes", NewGetFarmNodesHandler(farmRegistry))
+	router.GET("/explorer/capacity/farm/:id/capacity", NewGetFarmCapacityHandler(registry))
+	router.GET("/explorer/capacity/node/:node/farm", NewGetNodeFarmHandler(farmRegistry))
+	router.GET("/explorer/capacity/farm/:id/rewards", NewGetFarmRewardsHandler(farmRegistry))
+}
+
+// NewGetNodeCapacityHandler creates a handler to handle the API calls to /transactiondb/capacity/node/:node,
+// where the node is identified by its public key. An optional height query parameter can be given,
+// in order to get the capacity registered for the node at that block height.
+func NewGetNodeCapacityHandler(registry ctypes.CapacityReadRegistry) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		var node types.PublicKey
+		err := node.LoadString(ps.ByName("node"))
+		if err != nil {
+			api.WriteError(w, api.Error{Message: fmt.Errorf("node has to be a valid public key: %v", err).Error()},
+				http.StatusBadRequest)
+			return
+		}
+		var nc ctypes.NodeCapacity
+		if heightStr := req.FormValue("height"); heightStr != "" {
+			var height uint64
+			height, err = strconv.ParseUint(heightStr, 10, 64)
+			if err != nil {
+				api.WriteError(w, api.Error{Message: fmt.Errorf("height has to be a valid block height: %v", err).Error()},
+					http.StatusBadRequest)
+				return
+			}
+			nc, err = registry.GetNodeCapacityAt(node, types.BlockHeight(height))
+		} else {
+			nc, err = registry.GetNodeCapacity(node)
+		}
+		if err != nil {
+			api.WriteError(w, api.Error{Message: err.Error()}, capacityErrorAsHTTPStatusCode(err))
+			return
+		}
+		api.WriteJSON(w, nc)
+	}
+}
+
+// NewGetFarmerHandler creates a handler to handle the API calls to /transactiondb/capacity/farmer/:address,
+// returning the authorization state of the farmer.
+func NewGetFarmerHandler(registry ctypes.CapacityReadRegistry) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		var address types.UnlockHash
+		err := address.LoadString(ps.ByName("address"))
+		if err != nil {
+			api.WriteError(w, api.Error{Message: fmt.Errorf("address has to be a valid address: %v", err).Error()},
+				http.StatusBadRequest)
+			return
+		}
+		authorized, err := registry.IsFarmerAuthorized(address)
+		if err != nil {
+			api.WriteError(w, api.Error{Message: err.Error()}, capacityErrorAsHTTPStatusCode(err))
+			return
+		}
+		api.WriteJSON(w, ctypes.FarmerStatus{
+			Farmer:     address,
+			Authorized: authorized,
+		})
+	}
+}
+
+// NewGetFarmCapacityHandler creates a handler to handle the API calls to /transactiondb/capacity/farm/:id/capacity,
+// returning the nodes and total capacity registered by the farm with the given ID.
+func NewGetFarmCapacityHandler(registry ctypes.CapacityReadRegistry) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		var id ctypes.FarmID
+		err := id.LoadString(ps.ByName("id"))
+		if err != nil {
+			api.WriteError(w, api.Error{Message: fmt.Errorf("id has to be a valid FarmID: %v", err).Error()},
+				http.StatusBadRequest)
+			return
+		}
+		fc, err := registry.GetFarmCapacity(id)
+		if err != nil {
+			api.WriteError(w, api.Error{Message: err.Error()}, capacityErrorAsHTTPStatusCode(err))
+			return
+		}
+		api.WriteJSON(w, fc)
+	}
+}
+
+// NewGetFarmForIDHandler creates a handler to handle the API calls to /transactiondb/capacity/farm/:id,
+// returning the record of the farm with the given ID.
+func NewGetFarmForIDHandler(farmRegistry ctypes.FarmReadRegistry) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		var id ctypes.FarmID
+		err := id.LoadString(ps.ByName("id"))
+		if err != nil {
+			api.WriteError(w, api.Error{Message: fmt.Errorf("id has to be a valid FarmID: %v", err).Error()},
+				http.StatusBadRequest)
+			return
+		}
+		record, err := farmRegistry.GetFarm(id)
+		if err != nil {
+			api.WriteError(w, api.Error{Message: err.Error()}, capacityErrorAsHTTPStatusCode(err))
+			return
+		}
+		api.WriteJSON(w, record)
+	}
+}
+
+// NewGetFarmForNameHandler creates a handler to handle the API calls to /transactiondb/capacity/farmname/:name,
+// returning the record of the farm registered with the given name.
+func NewGetFarmForNameHandler(farmRegistry ctypes.FarmReadRegistry) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		name := ps.ByName("name")
+		err := ctypes.ValidateFarmName(name)
+		if err != nil {
+			api.WriteError(w, api.Error{Message: fmt.Errorf("invalid farm name: %v", err).Error()},
+				http.StatusBadRequest)
+			return
+		}
+		record, err := farmRegistry.GetFarmForName(name)
+		if err != nil {
+			api.WriteError(w, api.Error{Message: err.Error()}, capacityErrorAsHTTPStatusCode(err))
+			return
+		}
+		api.WriteJSON(w, record)
+	}
+}
+
+// NewGetFarmNodesHandler creates a handler to handle the API calls to /transactiondb/capacity/farm/:id/nodes,
+// returning the nodes currently linked to the farm with the given ID.
+func NewGetFarmNodesHandler(farmRegistry ctypes.FarmReadRegistry) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		var id ctypes.FarmID
+		err := id.LoadString(ps.ByName("id"))
+		if err != nil {
+			api.WriteError(w, api.Error{Message: fmt.Errorf("id has to be a valid FarmID: %v", err).Error()},
+				http.StatusBadRequest)
+			return
+		}
+		fn, err := farmRegistry.GetFarmNodes(id)
+		if err != nil {
+			api.WriteError(w, api.Error{Message: err.Error()}, capacityErrorAsHTTPStatusCode(err))
+			return
+		}
+		api.WriteJSON(w, fn)
+	}
+}
+
+// NewGetNodeFarmHandler creates a handler to handle the API calls to /transactiondb/capacity/node/:node/farm,
+// returning the link of the node (identified by its public key) to its current farm.
+func NewGetNodeFarmHandler(farmRegistry ctypes.FarmReadRegistry) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		var node types.PublicKey
+		err := node.LoadString(ps.ByName("node"))
+		if err != nil {
+			api.WriteError(w, api.Error{Message: fmt.Errorf("node has to be a valid public key: %v", err).Error()},
+				http.StatusBadRequest)
+			return
+		}
+		link, err := farmRegistry.GetNodeFarm(node)
+		if err != nil {
+			api.WriteError(w, api.Error{Message: err.Error()}, capacityErrorAsHTTPStatusCode(err))
+			return
+		}
+		api.WriteJSON(w, link)
+	}
+}
+
+// NewGetFarmRewardsHandler creates a handler to handle the API calls to /transactiondb/capacity/farm/:id/rewards,
+// returning all farming rewards paid out to the farm with the given ID, as well as their total.
+func NewGetFarmRewardsHandler(farmRegistry ctypes.FarmReadRegistry) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		var id ctypes.FarmID
+		err := id.LoadString(ps.ByName("id"))
+		if err != nil {
+			api.WriteError(w, api.Error{Message: fmt.Errorf("id has to be a valid FarmID: %v", err).Error()},
+				http.StatusBadRequest)
+			return
+		}
+		fr, err := farmRegistry.GetFarmRewards(id)
+		if err != nil {
+			api.WriteError(w, api.Error{Message: err.Error()}, capacityErrorAsHTTPStatusCode(err))
+			return
+		}
+		api.WriteJSON(w, fr)
+	}
+}
+
+// capacityErrorAsHTTPStatusCode converts a capacity error to an http status code.
+// if it is not an applicable capacity error, an internal server error code is returned
+func capacityErrorAsHTTPStatusCode(err error) int {
+	switch err {
+	case ctypes.ErrNodeNotFound, ctypes.ErrFarmNotFound, ctypes.ErrNodeNotLinked:
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,284 @@
+package client
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/threefoldtech/rivine/pkg/cli"
+	"github.com/threefoldtech/rivine/pkg/client"
+	rivinecli "github.com/threefoldtech/rivine/pkg/client"
+	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
+	"github.com/threefoldtech/rivine/types"
+
+	"github.com/spf13/cobra"
+)
+
+func CreateConsensusSubCmds(ccli *rivinecli.CommandLineClient) error {
+	bc, err := client.NewLazyBaseClientFromCommandLineClient(ccli)
+	if err != nil {
+		return err
+	}
+
+	consensusSubCmds := &consensusSubCmds{
+		cli:     ccli,
+		cClient: NewPluginConsensusClient(bc),
+	}
+
+	// define commands
+	var (
+		getNodeCapacityCmd = &cobra.Command{
+			Use:   "nodecapacity <nodepublickey>",
+			Short: "Get the capacity registered for the given node",
+			Long: `Get the capacity registered for the node identified by the given (ed25519) public key,
+as well as the farm that registered it. Use the height flag to get the capacity registered at a given block height.
+`,
+			Run: rivinecli.Wrap(consensusSubCmds.getNodeCapacity),
+		}
+		getFarmerCmd = &cobra.Command{
+			Use:   "farmer <address>",
+			Short: "Get the authorization state of the given farmer",
+			Long: `Get the authorization state of the given farmer address,
+only authorized farmers can own farms for which capacity can be registered.
+`,
+			Run: rivinecli.Wrap(consensusSubCmds.getFarmer),
+		}
+		getFarmCmd = &cobra.Command{
+			Use:   "farm <id|name>",
+			Short: "Get the record of the given farm",
+			Long: `Get the record of the farm identified by the given ID or name,
+containing its name, owner, managers, location and payout address.
+An identifier consisting of digits only is interpreted as a farm ID.
+`,
+			Run: rivinecli.Wrap(consensusSubCmds.getFarm),
+		}
+		getFarmNodesCmd = &cobra.Command{
+			Use:   "farmnodes <id|name>",
+			Short: "Get the nodes linked to the given farm",
+			Long: `Get the public keys of all nodes currently linked to the farm identified by the given ID or name.
+An identifier consisting of digits only is interpreted as a farm ID.
+`,
+			Run: rivinecli.Wrap(consensusSubCmds.getFarmNodes),
+		}
+		getFarmCapacityCmd = &cobra.Command{
+			Use:   "farmcapacity <id|name>",
+			Short: "Get the capacity registered by the given farm",
+			Long: `Get all nodes linked to the farm identified by the given ID or name,
+for which that farm registered capacity, as well as their total capacity.
+An identifier consisting of digits only is interpreted as a farm ID.
+`,
+			Run: rivinecli.Wrap(consensusSubCmds.getFarmCapacity),
+		}
+		getNodeFarmCmd = &cobra.Command{
+			Use:   "nodefarm <nodepublickey>",
+			Short: "Get the farm the given node is linked to",
+			Long: `Get the ID of the farm the node identified by the given (ed25519) public key is currently linked to,
+as well as the block height and ID of the transaction that linked it.
+`,
+			Run: rivinecli.Wrap(consensusSubCmds.getNodeFarm),
+		}
+		getFarmRewardsCmd = &cobra.Command{
+			Use:   "farmrewards <id|name>",
+			Short: "Get the farming rewards paid out to the given farm",
+			Long: `Get all farming rewards paid out to the farm identified by the given ID or name,
+as well as their total. An identifier consisting of digits only is interpreted as a farm ID.
+`,
+			Run: rivinecli.Wrap(consensusSubCmds.getFarmRewards),
+		}
+	)
+
+	// add commands as consensus sub commands
+	ccli.ConsensusCmd.AddCommand(
+		getNodeCapacityCmd,
+		getFarmerCmd,
+		getFarmCmd,
+		getFarmNodesCmd,
+		getFarmCapacityCmd,
+		getNodeFarmCmd,
+		getFarmRewardsCmd,
+	)
+
+	// register flags
+	getNodeCapacityCmd.Flags().Uint64Var(
+		&consensusSubCmds.getNodeCapacityCfg.Height, "height", 0,
+		"optionally get the capacity registered at the given block height instead of the current capacity")
+	getNodeCapacityCmd.Flags().Var(
+		cli.NewEncodingTypeFlag(0, &consensusSubCmds.getNodeCapacityCfg.EncodingType, 0), "encoding",
+		cli.EncodingTypeFlagDescription(0))
+	getFarmerCmd.Flags().Var(
+		cli.NewEncodingTypeFlag(0, &consensusSubCmds.getFarmerCfg.EncodingType, 0), "encoding",
+		cli.EncodingTypeFlagDescription(0))
+	getFarmCmd.Flags().Var(
+		cli.NewEncodingTypeFlag(0, &consensusSubCmds.getFarmCfg.EncodingType, 0), "encoding",
+		cli.EncodingTypeFlagDescription(0))
+	getFarmNodesCmd.Flags().Var(
+		cli.NewEncodingTypeFlag(0, &consensusSubCmds.getFarmNodesCfg.EncodingType, 0), "encoding",
+		cli.EncodingTypeFlagDescription(0))
+	getFarmCapacityCmd.Flags().Var(
+		cli.NewEncodingTypeFlag(0, &consensusSubCmds.getFarmCapacityCfg.EncodingType, 0), "encoding",
+		cli.EncodingTypeFlagDescription(0))
+	getNodeFarmCmd.Flags().Var(
+		cli.NewEncodingTypeFlag(0, &consensusSubCmds.getNodeFarmCfg.EncodingType, 0), "encoding",
+		cli.EncodingTypeFlagDescription(0))
+	getFarmRewardsCmd.Flags().Var(
+		cli.NewEncodingTypeFlag(0, &consensusSubCmds.getFarmRewardsCfg.EncodingType, 0), "encoding",
+		cli.EncodingTypeFlagDescription(0))
+
+	return nil
+}
+
+type consensusSubCmds struct {
+	cli                *rivinecli.CommandLineClient
+	cClient            *PluginClient
+	getNodeCapacityCfg struct {
+		Height       uint64
+		EncodingType cli.EncodingType
+	}
+	getFarmerCfg struct {
+		EncodingType cli.EncodingType
+	}
+	getFarmCfg struct {
+		EncodingType cli.EncodingType
+	}
+	getFarmNodesCfg struct {
+		EncodingType cli.EncodingType
+	}
+	getFarmCapacityCfg struct {
+		EncodingType cli.EncodingType
+	}
+	getNodeFarmCfg struct {
+		EncodingType cli.EncodingType
+	}
+	getFarmRewardsCfg struct {
+		EncodingType cli.EncodingType
+	}
+}
+
+func (consensusSubCmds *consensusSubCmds) getNodeCapacity(str string) {
+	var node types.PublicKey
+	err := node.LoadString(str)
+	if err != nil {
+		cli.DieWithError("invalid node public key", err)
+	}
+	var result interface{}
+	if height := consensusSubCmds.getNodeCapacityCfg.Height; height > 0 {
+		result, err = consensusSubCmds.cClient.GetNodeCapacityAt(node, types.BlockHeight(height))
+	} else {
+		result, err = consensusSubCmds.cClient.GetNodeCapacity(node)
+	}
+	if err != nil {
+		cli.DieWithError("error while fetching the node capacity", err)
+	}
+	err = encodeResult(result, consensusSubCmds.getNodeCapacityCfg.EncodingType)
+	if err != nil {
+		cli.DieWithError("failed to encode node capacity", err)
+	}
+}
+
+func (consensusSubCmds *consensusSubCmds) getFarmer(str string) {
+	var farmer types.UnlockHash
+	err := farmer.LoadString(str)
+	if err != nil {
+		cli.DieWithError("invalid farmer address", err)
+	}
+	result, err := consensusSubCmds.cClient.GetFarmer(farmer)
+	if err != nil {
+		cli.DieWithError("error while fetching the farmer", err)
+	}
+	err = encodeResult(result, consensusSubCmds.getFarmerCfg.EncodingType)
+	if err != nil {
+		cli.DieWithError("failed to encode farmer", err)
+	}
+}
+
+func (consensusSubCmds *consensusSubCmds) getFarm(str string) {
+	result, err := consensusSubCmds.cClient.GetFarmForIDOrName(str)
+	if err != nil {
+		cli.DieWithError("error while fetching the farm", err)
+	}
+	err = encodeResult(result, consensusSubCmds.getFarmCfg.EncodingType)
+	if err != nil {
+		cli.DieWithError("failed to encode farm", err)
+	}
+}
+
+func (consensusSubCmds *consensusSubCmds) getFarmNodes(str string) {
+	record, err := consensusSubCmds.cClient.GetFarmForIDOrName(str)
+	if err != nil {
+		cli.DieWithError("error while fetching the farm", err)
+	}
+	result, err := consensusSubCmds.cClient.GetFarmNodes(record.ID)
+	if err != nil {
+		cli.DieWithError("error while fetching the farm nodes", err)
+	}
+	err = encodeResult(result, consensusSubCmds.getFarmNodesCfg.EncodingType)
+	if err != nil {
+		cli.DieWithError("failed to encode farm nodes", err)
+	}
+}
+
+func (consensusSubCmds *consensusSubCmds) getFarmCapacity(str string) {
+	record, err := consensusSubCmds.cClient.GetFarmForIDOrName(str)
+	if err != nil {
+		cli.DieWithError("error while fetching the farm", err)
+	}
+	result, err := consensusSubCmds.cClient.GetFarmCapacity(record.ID)
+	if err != nil {
+		cli.DieWithError("error while fetching the farm capacity", err)
+	}
+	err = encodeResult(result, consensusSubCmds.getFarmCapacityCfg.EncodingType)
+	if err != nil {
+		cli.DieWithError("failed to encode farm capacity", err)
+	}
+}
+
+func (consensusSubCmds *consensusSubCmds) getNodeFarm(str string) {
+	var node types.PublicKey
+	err := node.LoadString(str)
+	if err != nil {
+		cli.DieWithError("invalid node public key", err)
+	}
+	result, err := consensusSubCmds.cClient.GetNodeFarm(node)
+	if err != nil {
+		cli.DieWithError("error while fetching the node farm", err)
+	}
+	err = encodeResult(result, consensusSubCmds.getNodeFarmCfg.EncodingType)
+	if err != nil {
+		cli.DieWithError("failed to encode node farm", err)
+	}
+}
+
+func (consensusSubCmds *consensusSubCmds) getFarmRewards(str string) {
+	record, err := consensusSubCmds.cClient.GetFarmForIDOrName(str)
+	if err != nil {
+		cli.DieWithError("error while fetching the farm", err)
+	}
+	result, err := consensusSubCmds.cClient.GetFarmRewards(record.ID)
+	if err != nil {
+		cli.DieWithError("error while fetching the farm rewards", err)
+	}
+	err = encodeResult(result, consensusSubCmds.getFarmRewardsCfg.EncodingType)
+	if err != nil {
+		cli.DieWithError("failed to encode farm rewards", err)
+	}
+}
+
+// encodeResult encodes the given value to the STDOUT, depending on the encoding type
+func encodeResult(v interface{}, encodingType cli.EncodingType) error {
+	switch encodingType {
+	case cli.EncodingTypeJSON:
+		return json.NewEncoder(os.Stdout).Encode(v)
+	case cli.EncodingTypeHex:
+		b, err := siabin.Marshal(v)
+		if err != nil {
+			return err
+		}
+		fmt.Println(hex.EncodeToString(b))
+		return nil
+	default:
+		e := json.NewEncoder(os.Stdout)
+		e.SetIndent("", "  ")
+		return e.Encode(v)
+	}
+}
@@ -0,0 +1,151 @@
+package client
+
+import (
+	"fmt"
+	"net/url"
+
+	ctypes "github.com/threefoldfoundation/tfchain/extensions/capacity/types"
+	"github.com/threefoldtech/rivine/pkg/client"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// PluginClient is used to be able to get capacity and farm information from
+// a daemon that has the capacity extension enabled and running.
+type PluginClient struct {
+	bc           client.BaseClient
+	rootEndpoint string
+}
+
+// NewPluginConsensusClient creates a new PluginClient,
+// that can be used for easy interaction with the API exposed via the Consensus endpoints
+func NewPluginConsensusClient(bc client.BaseClient) *PluginClient {
+	if bc == nil {
+		panic("no BaseClient given")
+	}
+	return &PluginClient{
+		bc:           bc,
+		rootEndpoint: "/consensus",
+	}
+}
+
+// NewPluginExplorerClient creates a new PluginClient,
+// that can be used for easy interaction with the API exposed via the Explorer endpoints
+func NewPluginExplorerClient(bc client.BaseClient) *PluginClient {
+	if bc == nil {
+		panic("no BaseClient given")
+	}
+	return &PluginClient{
+		bc:           bc,
+		rootEndpoint: "/explorer",
+	}
+}
+
+var (
+	// ensure PluginClient implements the FarmReadRegistry interface
+	_ ctypes.FarmReadRegistry = (*PluginClient)(nil)
+)
+
+// GetNodeCapacity returns the capacity currently registered for the given node.
+func (client *PluginClient) GetNodeCapacity(node types.PublicKey) (ctypes.NodeCapacity, error) {
+	var result ctypes.NodeCapacity
+	err := client.bc.HTTP().GetWithResponse(fmt.Sprintf("%s/capacity/node/%s", client.rootEndpoint, node.String()), &result)
+	if err != nil {
+		return ctypes.NodeCapacity{}, fmt.Errorf("failed to get capacity of node %s from daemon: %v", node.String(), err)
+	}
+	return result, nil
+}
+
+// GetNodeCapacityAt returns the capacity registered for the given node at the given block height.
+func (client *PluginClient) GetNodeCapacityAt(node types.PublicKey, height types.BlockHeight) (ctypes.NodeCapacity, error) {
+	var result ctypes.NodeCapacity
+	err := client.bc.HTTP().GetWithResponse(fmt.Sprintf("%s/capacity/node/%s?height=%d", client.rootEndpoint, node.String(), height), &result)
+	if err != nil {
+		return ctypes.NodeCapacity{}, fmt.Errorf("failed to get capacity of node %s at height %d from daemon: %v", node.String(), height, err)
+	}
+	return result, nil
+}
+
+// GetFarmer returns the authorization state of the given farmer.
+func (client *PluginClient) GetFarmer(farmer types.UnlockHash) (ctypes.FarmerStatus, error) {
+	var result ctypes.FarmerStatus
+	err := client.bc.HTTP().GetWithResponse(fmt.Sprintf("%s/capacity/farmer/%s", client.rootEndpoint, farmer.String()), &result)
+	if err != nil {
+		return ctypes.FarmerStatus{}, fmt.Errorf("failed to get farmer %s from daemon: %v", farmer.String(), err)
+	}
+	return result, nil
+}
+
+// GetFarmCapacity returns the nodes and total capacity currently registered by the farm with the given ID.
+func (client *PluginClient) GetFarmCapacity(id ctypes.FarmID) (ctypes.FarmCapacity, error) {
+	var result ctypes.FarmCapacity
+	err := client.bc.HTTP().GetWithResponse(fmt.Sprintf("%s/capacity/farm/%s/capacity", client.rootEndpoint, id.String()), &result)
+	if err != nil {
+		return ctypes.FarmCapacity{}, fmt.Errorf("failed to get capacity of farm %s from daemon: %v", id.String(), err)
+	}
+	return result, nil
+}
+
+// GetFarm implements FarmReadRegistry.GetFarm,
+// returning the record of the farm with the given ID.
+func (client *PluginClient) GetFarm(id ctypes.FarmID) (ctypes.FarmRecord, error) {
+	var result ctypes.FarmRecord
+	err := client.bc.HTTP().GetWithResponse(fmt.Sprintf("%s/capacity/farm/%s", client.rootEndpoint, id.String()), &result)
+	if err != nil {
+		return ctypes.FarmRecord{}, fmt.Errorf("failed to get farm %s from daemon: %v", id.String(), err)
+	}
+	return result, nil
+}
+
+// GetFarmForName implements FarmReadRegistry.GetFarmForName,
+// returning the record of the farm registered with the given name.
+func (client *PluginClient) GetFarmForName(name string) (ctypes.FarmRecord, error) {
+	var result ctypes.FarmRecord
+	err := client.bc.HTTP().GetWithResponse(fmt.Sprintf("%s/capacity/farmname/%s", client.rootEndpoint, url.PathEscape(name)), &result)
+	if err != nil {
+		return ctypes.FarmRecord{}, fmt.Errorf("failed to get farm %q from daemon: %v", name, err)
+	}
+	return result, nil
+}
+
+// GetFarmForIDOrName returns the record of the farm identified by the given string,
+// interpreted as a farm ID if possible, and as the name of the farm otherwise.
+func (client *PluginClient) GetFarmForIDOrName(str string) (ctypes.FarmRecord, error) {
+	var id ctypes.FarmID
+	if err := id.LoadString(str); err == nil {
+		return client.GetFarm(id)
+	}
+	return client.GetFarmForName(str)
+}
+
+// GetFarmNodes implements FarmReadRegistry.GetFarmNodes,
+// returning the nodes currently linked to the farm with the given ID.
+func (client *PluginClient) GetFarmNodes(id ctypes.FarmID) (ctypes.FarmNodes, error) {
+	var result ctypes.FarmNodes
+	err := client.bc.HTTP().GetWithResponse(fmt.Sprintf("%s/capacity/farm/%s/nodes", client.rootEndpoint, id.String()), &result)
+	if err != nil {
+		return ctypes.FarmNodes{}, fmt.Errorf("failed to get nodes of farm %s from daemon: %v", id.String(), err)
+	}
+	return result, nil
+}
+
+// GetNodeFarm implements FarmReadRegistry.GetNodeFarm,
+// returning the link of the given node to its current farm.
+func (client *PluginClient) GetNodeFarm(node types.PublicKey) (ctypes.NodeFarmLink, error) {
+	var result ctypes.NodeFarmLink
+	err := client.bc.HTTP().GetWithResponse(fmt.Sprintf("%s/capacity/node/%s/farm", client.rootEndpoint, node.String()), &result)
+	if err != nil {
+		return ctypes.NodeFarmLink{}, fmt.Errorf("failed to get farm of node %s from daemon: %v", node.String(), err)
+	}
+	return result, nil
+}
+
+// GetFarmRewards implements FarmReadRegistry.GetFarmRewards,
+// returning all farming rewards paid out to the farm with the given ID.
+func (client *PluginClient) GetFarmRewards(id ctypes.FarmID) (ctypes.FarmRewards, error) {
+	var result ctypes.FarmRewards
+	err := client.bc.HTTP().GetWithResponse(fmt.Sprintf("%s/capacity/farm/%s/rewards", client.rootEndpoint, id.String()), &result)
+	if err != nil {
+		return ctypes.FarmRewards{}, fmt.Errorf("failed to get rewards of farm %s from daemon: %v", id.String(), err)
+	}
+	return result, nil
+}
@@ -0,0 +1,622 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	ctypes "github.com/threefoldfoundation/tfchain/extensions/capacity/types"
+
+	"github.com/threefoldtech/rivine/pkg/cli"
+	"github.com/threefoldtech/rivine/pkg/client"
+	rivinecli "github.com/threefoldtech/rivine/pkg/client"
+	"github.com/threefoldtech/rivine/types"
+
+	"github.com/spf13/cobra"
+)
+
+// CreateWalletCmds adds the wallet cli subcommands for the capacity plugin
+func CreateWalletCmds(ccli *client.CommandLineClient) error {
+	bc, err := client.NewLazyBaseClientFromCommandLineClient(ccli)
+	if err != nil {
+		return err
+	}
+	walletCmd := &walletCmd{
+		cli:          ccli,
+		walletClient: rivinecli.NewWalletClient(bc),
+		txPoolClient: rivinecli.NewTransactionPoolClient(bc),
+		cClient:      NewPluginConsensusClient(bc),
+	}
+
+	// define commands
+	var (
+		createFarmerAuthorizationTxCmd = &cobra.Command{
+			Use:   "farmerauthorizationtransaction",
+			Short: "Create a new farmer authorization transaction",
+			Long: `Create a new farmer authorization transaction,
+authorizing and/or deauthorizing the given farmer addresses.
+Only authorized farmers can own farms, for which the capacity of their nodes can be registered.
+
+The returned (raw) FarmerAuthorizationTransaction still has to be signed, prior to sending.
+	`,
+			Args: cobra.MaximumNArgs(0),
+			Run:  walletCmd.createFarmerAuthorizationTxCmd,
+		}
+		createCapacityRegistrationTxCmd = &cobra.Command{
+			Use:   "capacityregistrationtransaction <nodepublickey> <farm id|name> <manager>",
+			Short: "Create a new capacity registration transaction",
+			Long: `Create a new capacity registration transaction,
+registering the capacity of the node identified by the given (ed25519) public key
+for the farm identified by the given ID or name, to which the node has to be linked.
+The manager has to be the address of one of the managers of the farm.
+The capacity is defined using flags, registering no capacity at all decommissions the node.
+The required fee is funded by this wallet.
+
+The returned (raw) CapacityRegistrationTransaction still has to be signed by the node,
+the manager and this wallet (for the fee), prior to sending.
+	`,
+			Args: cobra.ExactArgs(3),
+			Run:  walletCmd.createCapacityRegistrationTxCmd,
+		}
+		sendFarmRegistrationTxCmd = &cobra.Command{
+			Use:   "farmregistration <name> <owner>",
+			Short: "Create, sign and send a new farm registration transaction",
+			Long: `Create, sign and send a new farm registration transaction,
+registering a farm with the given unique name, owned by the given (authorized) farmer.
+The owner can be given as an address or as a JSON-encoded (multisig) condition.
+Managers, a location and a payout address can optionally be defined using flags,
+the payout address defaults to the address of the owner.
+
+The owner has to be (partly) loaded into the wallet of this daemon,
+and the coin inputs used to pay the fees are funded and signed using the wallet of this daemon as well.
+
+If this command returns without errors, the Tx is signed and sent,
+and you'll receive the TxID which will allow you to look it up in an explorer.
+The ID of the farm is assigned once the Tx is part of the blockchain.
+`,
+			Args: cobra.ExactArgs(2),
+			Run:  walletCmd.sendFarmRegistrationTxCmd,
+		}
+		sendFarmUpdateTxCmd = &cobra.Command{
+			Use:   "farmupdate <id|name>",
+			Short: "Create, sign and send a farm update transaction",
+			Long: `Create, sign and send a farm update transaction,
+updating the farm identified by the given ID or name. Only the properties defined using flags are updated.
+
+The current owner of the farm has to be (partly) loaded into the wallet of this daemon,
+and the coin inputs used to pay the fees are funded and signed using the wallet of this daemon as well.
+
+If this command returns without errors, the Tx is signed and sent,
+and you'll receive the TxID which will allow you to look it up in an explorer.
+`,
+			Args: cobra.ExactArgs(1),
+			Run:  walletCmd.sendFarmUpdateTxCmd,
+		}
+		createNodeLinkTxCmd = &cobra.Command{
+			Use:   "nodelinktransaction <nodepublickey> <farm id|name> <manager>",
+			Short: "Create a new node link transaction",
+			Long: `Create a new node link transaction, linking the node identified by the given (ed25519) public key
+to the farm identified by the given ID or name. A node that is already linked to another farm is relinked.
+The manager has to be the address of one of the managers of the farm.
+The required fee is funded by this wallet.
+
+The returned (raw) NodeLinkTransaction still has to be signed by the node,
+the manager and this wallet (for the fee), prior to sending.
+	`,
+			Args: cobra.ExactArgs(3),
+			Run:  walletCmd.createNodeLinkTxCmd,
+		}
+		createNodeUnlinkTxCmd = &cobra.Command{
+			Use:   "nodeunlinktransaction <nodepublickey> <manager>",
+			Short: "Create a new node unlink transaction",
+			Long: `Create a new node link transaction, unlinking the node identified by the given (ed25519) public key
+from the farm it is currently linked to. The manager has to be the address
+of one of the managers of the farm the node is currently linked to.
+The required fee is funded by this wallet.
+
+The returned (raw) NodeLinkTransaction still has to be signed by the node,
+the manager and this wallet (for the fee), prior to sending.
+	`,
+			Args: cobra.ExactArgs(2),
+			Run:  walletCmd.createNodeUnlinkTxCmd,
+		}
+		createFarmingRewardTxCmd = &cobra.Command{
+			Use:   "farmingrewardtransaction <snapshotheight> <farm id|name> <amount> [<farm id|name> <amount>]...",
+			Short: "Create a new farming reward transaction",
+			Long: `Create a new farming reward transaction, paying out the given amounts
+to the farms identified by the given IDs or names, for the capacity registered at the given snapshot height.
+Each amount is paid out to the (current) payout address of the farm.
+
+Amounts have to be given expressed in the OneCoin unit, and without the unit of currency.
+Decimals are possible and have to be defined using the decimal point.
+
+The returned (raw) FarmingRewardTransaction still has to be signed, prior to sending.
+	`,
+			Args: cobra.MinimumNArgs(3),
+			Run:  walletCmd.createFarmingRewardTxCmd,
+		}
+	)
+
+	// add commands as wallet sub commands
+	ccli.WalletCmd.RootCmdCreate.AddCommand(
+		createFarmerAuthorizationTxCmd,
+		createCapacityRegistrationTxCmd,
+		createNodeLinkTxCmd,
+		createNodeUnlinkTxCmd,
+		createFarmingRewardTxCmd,
+	)
+	ccli.WalletCmd.RootCmdSend.AddCommand(
+		sendFarmRegistrationTxCmd,
+		sendFarmUpdateTxCmd,
+	)
+
+	// register flags
+	cli.ArbitraryDataFlagVar(createFarmerAuthorizationTxCmd.Flags(), &walletCmd.farmerAuthorizationTxCfg.Description,
+		"description", "optionally add a description to describe the reasons of the farmer authorization, added as arbitrary data")
+	createFarmerAuthorizationTxCmd.Flags().StringSliceVarP(
+		&walletCmd.farmerAuthorizationTxCfg.AuthAddresses,
+		"auth", "e", nil, "add farmer addresses to authorize, allowing them to register capacity",
+	)
+	createFarmerAuthorizationTxCmd.Flags().StringSliceVarP(
+		&walletCmd.farmerAuthorizationTxCfg.DeauthAddresses,
+		"deauth", "d", nil, "add farmer addresses to deauthorize, no longer allowing them to register capacity",
+	)
+
+	createCapacityRegistrationTxCmd.Flags().Uint64Var(
+		&walletCmd.capacityRegistrationTxCfg.Capacity.CRU, "cru", 0, "amount of compute resource units (virtual CPU cores)")
+	createCapacityRegistrationTxCmd.Flags().Uint64Var(
+		&walletCmd.capacityRegistrationTxCfg.Capacity.MRU, "mru", 0, "amount of memory resource units (GB of memory)")
+	createCapacityRegistrationTxCmd.Flags().Uint64Var(
+		&walletCmd.capacityRegistrationTxCfg.Capacity.HRU, "hru", 0, "amount of HDD resource units (GB of HDD storage)")
+	createCapacityRegistrationTxCmd.Flags().Uint64Var(
+		&walletCmd.capacityRegistrationTxCfg.Capacity.SRU, "sru", 0, "amount of SSD resource units (GB of SSD storage)")
+	cli.ArbitraryDataFlagVar(createCapacityRegistrationTxCmd.Flags(), &walletCmd.capacityRegistrationTxCfg.Description,
+		"description", "optionally add a description to the capacity registration, added as arbitrary data")
+
+	sendFarmRegistrationTxCmd.Flags().StringSliceVar(
+		&walletCmd.farmRegistrationTxCfg.Managers,
+		"manager", nil, "add addresses authorized to manage the farm")
+	sendFarmRegistrationTxCmd.Flags().StringVar(
+		&walletCmd.farmRegistrationTxCfg.Location, "location", "", "optionally define the location of the farm")
+	sendFarmRegistrationTxCmd.Flags().StringVar(
+		&walletCmd.farmRegistrationTxCfg.PayoutAddress, "payout", "",
+		"optionally define the address to which the farming rewards are paid, defaults to the address of the owner")
+	cli.ArbitraryDataFlagVar(sendFarmRegistrationTxCmd.Flags(), &walletCmd.farmRegistrationTxCfg.Description,
+		"description", "optionally add a description to the farm registration, added as arbitrary data")
+
+	sendFarmUpdateTxCmd.Flags().StringVar(
+		&walletCmd.farmUpdateTxCfg.Name, "name", "", "optionally define a new (unique) name for the farm")
+	sendFarmUpdateTxCmd.Flags().StringVar(
+		&walletCmd.farmUpdateTxCfg.Owner, "owner", "",
+		"optionally transfer the farm to a new (authorized) owner, given as an address or JSON-encoded (multisig) condition")
+	sendFarmUpdateTxCmd.Flags().StringSliceVar(
+		&walletCmd.farmUpdateTxCfg.ManagersToAdd,
+		"addmanager", nil, "add addresses authorized to manage the farm")
+	sendFarmUpdateTxCmd.Flags().StringSliceVar(
+		&walletCmd.farmUpdateTxCfg.ManagersToRemove,
+		"removemanager", nil, "remove addresses no longer authorized to manage the farm")
+	sendFarmUpdateTxCmd.Flags().StringVar(
+		&walletCmd.farmUpdateTxCfg.Location, "location", "", "optionally define a new location for the farm")
+	sendFarmUpdateTxCmd.Flags().StringVar(
+		&walletCmd.farmUpdateTxCfg.PayoutAddress, "payout", "",
+		"optionally define a new address to which the farming rewards are paid")
+	cli.ArbitraryDataFlagVar(sendFarmUpdateTxCmd.Flags(), &walletCmd.farmUpdateTxCfg.Description,
+		"description", "optionally add a description to the farm update, added as arbitrary data")
+
+	cli.ArbitraryDataFlagVar(createNodeLinkTxCmd.Flags(), &walletCmd.nodeLinkTxCfg.Description,
+		"description", "optionally add a description to the node link, added as arbitrary data")
+	cli.ArbitraryDataFlagVar(createNodeUnlinkTxCmd.Flags(), &walletCmd.nodeLinkTxCfg.Description,
+		"description", "optionally add a description to the node unlink, added as arbitrary data")
+
+	cli.ArbitraryDataFlagVar(createFarmingRewardTxCmd.Flags(), &walletCmd.farmingRewardTxCfg.Description,
+		"description", "optionally add a description to the farming reward, added as arbitrary data")
+
+	return nil
+}
+
+type walletCmd struct {
+	cli          *rivinecli.CommandLineClient
+	walletClient *rivinecli.WalletClient
+	txPoolClient *rivinecli.TransactionPoolClient
+	cClient      *PluginClient
+
+	farmerAuthorizationTxCfg struct {
+		AuthAddresses   []string
+		DeauthAddresses []string
+		Description     []byte
+	}
+	capacityRegistrationTxCfg struct {
+		Capacity    ctypes.CapacityUnits
+		Description []byte
+	}
+	farmRegistrationTxCfg struct {
+		Managers      []string
+		Location      string
+		PayoutAddress string
+		Description   []byte
+	}
+	farmUpdateTxCfg struct {
+		Name             string
+		Owner            string
+		ManagersToAdd    []string
+		ManagersToRemove []string
+		Location         string
+		PayoutAddress    string
+		Description      []byte
+	}
+	nodeLinkTxCfg struct {
+		Description []byte
+	}
+	farmingRewardTxCfg struct {
+		Description []byte
+	}
+}
+
+func (walletCmd *walletCmd) createFarmerAuthorizationTxCmd(cmd *cobra.Command, args []string) {
+	// create a farmer authorization tx with a random nonce and the minimum required miner fee
+	tx := ctypes.FarmerAuthorizationTransaction{
+		Nonce:     types.RandomTransactionNonce(),
+		MinerFees: []types.Currency{walletCmd.cli.Config.MinimumTransactionFee},
+	}
+
+	if n := len(walletCmd.farmerAuthorizationTxCfg.Description); n > 0 {
+		tx.ArbitraryData = make([]byte, n)
+		copy(tx.ArbitraryData[:], walletCmd.farmerAuthorizationTxCfg.Description[:])
+	}
+
+	var err error
+	tx.AuthAddresses, err = parseAddresses(walletCmd.farmerAuthorizationTxCfg.AuthAddresses)
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.DieWithError("invalid address to authorize", err)
+	}
+	tx.DeauthAddresses, err = parseAddresses(walletCmd.farmerAuthorizationTxCfg.DeauthAddresses)
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.DieWithError("invalid address to deauthorize", err)
+	}
+	if len(tx.AuthAddresses) == 0 && len(tx.DeauthAddresses) == 0 {
+		cmd.UsageFunc()(cmd)
+		cli.Die(ctypes.ErrNoFarmerAuthorization)
+	}
+
+	// encode the transaction as a JSON-encoded string and print it to the STDOUT
+	err = json.NewEncoder(os.Stdout).Encode(tx.Transaction())
+	if err != nil {
+		cli.DieWithError("failed to encode farmer authorization transaction", err)
+	}
+}
+
+func (walletCmd *walletCmd) createCapacityRegistrationTxCmd(cmd *cobra.Command, args []string) {
+	record, err := walletCmd.cClient.GetFarmForIDOrName(args[1])
+	if err != nil {
+		cli.DieWithError("failed to get the farm to register the capacity for", err)
+	}
+	tx := ctypes.CapacityRegistrationTransaction{
+		FarmID:    record.ID,
+		Capacity:  walletCmd.capacityRegistrationTxCfg.Capacity,
+		MinerFees: []types.Currency{walletCmd.cli.Config.MinimumTransactionFee},
+	}
+	err = tx.Node.LoadString(args[0])
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.Die(fmt.Sprintf("invalid node public key %q: %v", args[0], err))
+	}
+	if tx.Node.Algorithm != types.SignatureAlgoEd25519 {
+		cmd.UsageFunc()(cmd)
+		cli.Die(ctypes.ErrInvalidNodePublicKey)
+	}
+	err = tx.Manager.LoadString(args[2])
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.Die(fmt.Sprintf("invalid manager address %q: %v", args[2], err))
+	}
+
+	if n := len(walletCmd.capacityRegistrationTxCfg.Description); n > 0 {
+		tx.ArbitraryData = make([]byte, n)
+		copy(tx.ArbitraryData[:], walletCmd.capacityRegistrationTxCfg.Description[:])
+	}
+
+	// fund the coin inputs
+	var refundCoinOutput *types.CoinOutput
+	tx.CoinInputs, refundCoinOutput, err = walletCmd.walletClient.FundCoins(walletCmd.cli.Config.MinimumTransactionFee, nil, false)
+	if err != nil {
+		cli.DieWithError("failed to fund the capacity registration Tx", err)
+	}
+	if refundCoinOutput != nil {
+		tx.CoinOutputs = append(tx.CoinOutputs, *refundCoinOutput)
+	}
+
+	// encode the transaction as a JSON-encoded string and print it to the STDOUT
+	err = json.NewEncoder(os.Stdout).Encode(tx.Transaction())
+	if err != nil {
+		cli.DieWithError("failed to encode capacity registration transaction", err)
+	}
+}
+
+func (walletCmd *walletCmd) sendFarmRegistrationTxCmd(cmd *cobra.Command, args []string) {
+	tx := ctypes.FarmRegistrationTransaction{
+		Name:      args[0],
+		Location:  walletCmd.farmRegistrationTxCfg.Location,
+		MinerFees: []types.Currency{walletCmd.cli.Config.MinimumTransactionFee},
+	}
+	err := ctypes.ValidateFarmName(tx.Name)
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.Die(err)
+	}
+	tx.Owner, err = parseCondition(args[1])
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.DieWithError("invalid farm owner", err)
+	}
+	tx.Managers, err = parseAddresses(walletCmd.farmRegistrationTxCfg.Managers)
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.DieWithError("invalid farm manager", err)
+	}
+	if str := walletCmd.farmRegistrationTxCfg.PayoutAddress; str != "" {
+		err = tx.PayoutAddress.LoadString(str)
+		if err != nil {
+			cmd.UsageFunc()(cmd)
+			cli.Die(fmt.Sprintf("invalid payout address %q: %v", str, err))
+		}
+	} else {
+		tx.PayoutAddress = tx.Owner.UnlockHash()
+	}
+
+	if n := len(walletCmd.farmRegistrationTxCfg.Description); n > 0 {
+		tx.ArbitraryData = make([]byte, n)
+		copy(tx.ArbitraryData[:], walletCmd.farmRegistrationTxCfg.Description[:])
+	}
+
+	// fund the coin inputs
+	var refundCoinOutput *types.CoinOutput
+	tx.CoinInputs, refundCoinOutput, err = walletCmd.walletClient.FundCoins(walletCmd.cli.Config.MinimumTransactionFee, nil, false)
+	if err != nil {
+		cli.DieWithError("failed to fund the farm registration Tx", err)
+	}
+	if refundCoinOutput != nil {
+		tx.CoinOutputs = append(tx.CoinOutputs, *refundCoinOutput)
+	}
+
+	// sign the Tx
+	rtx := tx.Transaction()
+	err = walletCmd.walletClient.GreedySignTx(&rtx)
+	if err != nil {
+		cli.DieWithError("failed to sign the farm registration Tx", err)
+	}
+
+	// submit the Tx
+	txID, err := walletCmd.txPoolClient.AddTransactiom(rtx)
+	if err != nil {
+		b, _ := json.Marshal(rtx)
+		fmt.Fprintln(os.Stderr, "bad tx: "+string(b))
+		cli.DieWithError("failed to submit the farm registration Tx to the Tx Pool", err)
+	}
+	fmt.Println("farm registration transaction submitted with ID:", txID.String())
+}
+
+func (walletCmd *walletCmd) sendFarmUpdateTxCmd(cmd *cobra.Command, args []string) {
+	record, err := walletCmd.cClient.GetFarmForIDOrName(args[0])
+	if err != nil {
+		cli.DieWithError("failed to get the farm to update", err)
+	}
+	tx := ctypes.FarmUpdateTransaction{
+		FarmID:    record.ID,
+		Name:      walletCmd.farmUpdateTxCfg.Name,
+		MinerFees: []types.Currency{walletCmd.cli.Config.MinimumTransactionFee},
+	}
+	if tx.Name != "" {
+		err = ctypes.ValidateFarmName(tx.Name)
+		if err != nil {
+			cmd.UsageFunc()(cmd)
+			cli.Die(err)
+		}
+	}
+	if str := walletCmd.farmUpdateTxCfg.Owner; str != "" {
+		owner, err := parseCondition(str)
+		if err != nil {
+			cmd.UsageFunc()(cmd)
+			cli.DieWithError("invalid farm owner", err)
+		}
+		tx.Owner = &owner
+	}
+	tx.ManagersToAdd, err = parseAddresses(walletCmd.farmUpdateTxCfg.ManagersToAdd)
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.DieWithError("invalid farm manager to add", err)
+	}
+	tx.ManagersToRemove, err = parseAddresses(walletCmd.farmUpdateTxCfg.ManagersToRemove)
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.DieWithError("invalid farm manager to remove", err)
+	}
+	// an empty location is a valid update, as it clears the location
+	if cmd.Flags().Changed("location") {
+		location := walletCmd.farmUpdateTxCfg.Location
+		tx.Location = &location
+	}
+	if str := walletCmd.farmUpdateTxCfg.PayoutAddress; str != "" {
+		var uh types.UnlockHash
+		err = uh.LoadString(str)
+		if err != nil {
+			cmd.UsageFunc()(cmd)
+			cli.Die(fmt.Sprintf("invalid payout address %q: %v", str, err))
+		}
+		tx.PayoutAddress = &uh
+	}
+	if tx.IsEmpty() {
+		cmd.UsageFunc()(cmd)
+		cli.Die(ctypes.ErrNoFarmUpdate)
+	}
+
+	if n := len(walletCmd.farmUpdateTxCfg.Description); n > 0 {
+		tx.ArbitraryData = make([]byte, n)
+		copy(tx.ArbitraryData[:], walletCmd.farmUpdateTxCfg.Description[:])
+	}
+
+	// fund the coin inputs
+	var refundCoinOutput *types.CoinOutput
+	tx.CoinInputs, refundCoinOutput, err = walletCmd.walletClient.FundCoins(walletCmd.cli.Config.MinimumTransactionFee, nil, false)
+	if err != nil {
+		cli.DieWithError("failed to fund the farm update Tx", err)
+	}
+	if refundCoinOutput != nil {
+		tx.CoinOutputs = append(tx.CoinOutputs, *refundCoinOutput)
+	}
+
+	// sign the Tx
+	rtx := tx.Transaction()
+	err = walletCmd.walletClient.GreedySignTx(&rtx)
+	if err != nil {
+		cli.DieWithError("failed to sign the farm update Tx", err)
+	}
+
+	// submit the Tx
+	txID, err := walletCmd.txPoolClient.AddTransactiom(rtx)
+	if err != nil {
+		b, _ := json.Marshal(rtx)
+		fmt.Fprintln(os.Stderr, "bad tx: "+string(b))
+		cli.DieWithError("failed to submit the farm update Tx to the Tx Pool", err)
+	}
+	fmt.Println("farm update transaction submitted with ID:", txID.String())
+}
+
+func (walletCmd *walletCmd) createNodeLinkTxCmd(cmd *cobra.Command, args []string) {
+	record, err := walletCmd.cClient.GetFarmForIDOrName(args[1])
+	if err != nil {
+		cli.DieWithError("failed to get the farm to link the node to", err)
+	}
+	walletCmd.createNodeLinkTx(cmd, args[0], record.ID, args[2])
+}
+
+func (walletCmd *walletCmd) createNodeUnlinkTxCmd(cmd *cobra.Command, args []string) {
+	walletCmd.createNodeLinkTx(cmd, args[0], 0, args[1])
+}
+
+// createNodeLinkTx creates a node link tx, funded by this wallet,
+// linking the node to the given farm, or unlinking it in case the farm ID is zero
+func (walletCmd *walletCmd) createNodeLinkTx(cmd *cobra.Command, nodeStr string, farmID ctypes.FarmID, managerStr string) {
+	tx := ctypes.NodeLinkTransaction{
+		FarmID:    farmID,
+		MinerFees: []types.Currency{walletCmd.cli.Config.MinimumTransactionFee},
+	}
+	err := tx.Node.LoadString(nodeStr)
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.Die(fmt.Sprintf("invalid node public key %q: %v", nodeStr, err))
+	}
+	if tx.Node.Algorithm != types.SignatureAlgoEd25519 {
+		cmd.UsageFunc()(cmd)
+		cli.Die(ctypes.ErrInvalidNodePublicKey)
+	}
+	err = tx.Manager.LoadString(managerStr)
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.Die(fmt.Sprintf("invalid manager address %q: %v", managerStr, err))
+	}
+
+	if n := len(walletCmd.nodeLinkTxCfg.Description); n > 0 {
+		tx.ArbitraryData = make([]byte, n)
+		copy(tx.ArbitraryData[:], walletCmd.nodeLinkTxCfg.Description[:])
+	}
+
+	// fund the coin inputs
+	var refundCoinOutput *types.CoinOutput
+	tx.CoinInputs, refundCoinOutput, err = walletCmd.walletClient.FundCoins(walletCmd.cli.Config.MinimumTransactionFee, nil, false)
+	if err != nil {
+		cli.DieWithError("failed to fund the node link Tx", err)
+	}
+	if refundCoinOutput != nil {
+		tx.CoinOutputs = append(tx.CoinOutputs, *refundCoinOutput)
+	}
+
+	// encode the transaction as a JSON-encoded string and print it to the STDOUT
+	err = json.NewEncoder(os.Stdout).Encode(tx.Transaction())
+	if err != nil {
+		cli.DieWithError("failed to encode node link transaction", err)
+	}
+}
+
+func (walletCmd *walletCmd) createFarmingRewardTxCmd(cmd *cobra.Command, args []string) {
+	currencyConvertor := walletCmd.cli.CreateCurrencyConvertor()
+
+	// create a farming reward tx with a random nonce and the minimum required miner fee
+	tx := ctypes.FarmingRewardTransaction{
+		Nonce:     types.RandomTransactionNonce(),
+		MinerFees: []types.Currency{walletCmd.cli.Config.MinimumTransactionFee},
+	}
+	height, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.Die(fmt.Sprintf("invalid snapshot height %q: %v", args[0], err))
+	}
+	tx.SnapshotHeight = types.BlockHeight(height)
+
+	// Check that the remaining args are farm + value pairs
+	pairs := args[1:]
+	if len(pairs)%2 != 0 {
+		cmd.UsageFunc()(cmd)
+		cli.Die("Invalid arguments. Arguments must be of the form <snapshotheight> <farm id|name> <amount> [<farm id|name> <amount>]...")
+	}
+	for idx := 0; idx < len(pairs); idx += 2 {
+		record, err := walletCmd.cClient.GetFarmForIDOrName(pairs[idx])
+		if err != nil {
+			cli.DieWithError(fmt.Sprintf("failed to get the farm %q to reward", pairs[idx]), err)
+		}
+		value, err := currencyConvertor.ParseCoinString(pairs[idx+1])
+		if err != nil {
+			cmd.UsageFunc()(cmd)
+			cli.Die(fmt.Sprintf("invalid amount %q for farm %q: %v", pairs[idx+1], pairs[idx], err))
+		}
+		tx.Payouts = append(tx.Payouts, ctypes.FarmPayout{
+			FarmID:        record.ID,
+			Value:         value,
+			PayoutAddress: record.PayoutAddress,
+		})
+	}
+
+	if n := len(walletCmd.farmingRewardTxCfg.Description); n > 0 {
+		tx.ArbitraryData = make([]byte, n)
+		copy(tx.ArbitraryData[:], walletCmd.farmingRewardTxCfg.Description[:])
+	}
+
+	// encode the transaction as a JSON-encoded string and print it to the STDOUT
+	err = json.NewEncoder(os.Stdout).Encode(tx.Transaction())
+	if err != nil {
+		cli.DieWithError("failed to encode farming reward transaction", err)
+	}
+}
+
+// parseCondition parses the given string as an address,
+// or as a JSON-encoded condition in case it isn't an address
+func parseCondition(str string) (types.UnlockConditionProxy, error) {
+	var uh types.UnlockHash
+	err := uh.LoadString(str)
+	if err == nil {
+		return types.NewCondition(types.NewUnlockHashCondition(uh)), nil
+	}
+	var condition types.UnlockConditionProxy
+	err = condition.UnmarshalJSON([]byte(str))
+	if err != nil {
+		return types.UnlockConditionProxy{}, errors.New("condition has to be an address or JSON-encoded condition")
+	}
+	return condition, nil
+}
+
+func parseAddresses(strs []string) ([]types.UnlockHash, error) {
+	if len(strs) == 0 {
+		return nil, nil
+	}
+	addresses := make([]types.UnlockHash, len(strs))
+	for idx, str := range strs {
+		err := addresses[idx].LoadString(str)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address %q: %v", str, err)
+		}
+	}
+	return addresses, nil
+}
@@ -0,0 +1,1848 @@
+package capacity
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/extensions/minting"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/modules/consensus"
+	"github.com/threefoldtech/rivine/persist"
+	"github.com/threefoldtech/rivine/pkg/encoding/rivbin"
+	"github.com/threefoldtech/rivine/types"
+
+	ctypes "github.com/threefoldfoundation/tfchain/extensions/capacity/types"
+
+	bolt "github.com/rivine/bbolt"
+)
+
+const (
+	pluginDBVersion = "1.0.0.0"
+	pluginDBHeader  = "capacityPlugin"
+)
+
+var (
+	bucketMintConditions = []byte("mintconditions") // height => mint condition
+	bucketFarmers        = []byte("farmers")        // address => []farmerAuthorization
+	bucketNodes          = []byte("nodes")          // node public key => []NodeCapacity
+	bucketFarms          = []byte("farms")          // farm ID => FarmRecord
+	bucketFarmNames      = []byte("farmnames")      // farm name => farm ID
+	bucketFarmUpdates    = []byte("farmupdates")    // farm update tx ID => previous FarmRecord
+	bucketNodeFarms      = []byte("nodefarms")      // node public key => []NodeFarmLink
+	bucketFarmNodes      = []byte("farmnodes")      // farm ID => []node public key
+	bucketFarmRewards    = []byte("farmrewards")    // farm ID => []FarmReward
+	bucketRewardHeights  = []byte("rewardheights")  // snapshot height => farming reward tx ID
+
+	bucketSlice = [][]byte{
+		bucketMintConditions,
+		bucketFarmers,
+		bucketNodes,
+		bucketFarms,
+		bucketFarmNames,
+		bucketFarmUpdates,
+		bucketNodeFarms,
+		bucketFarmNodes,
+		bucketFarmRewards,
+		bucketRewardHeights,
+	}
+)
+
+type (
+	// Plugin is a struct defines the capacity plugin,
+	// used to keep track of the authorized farmers, the farms registered by them,
+	// as well as of the capacity registered by those farms for their (linked) nodes.
+	//
+	// The plugin keeps track of the mint condition itself,
+	// as the mint condition has to be looked up within the same
+	// DB transaction as the one used to validate the farmer authorization transactions.
+	// The full registration history of each node is stored,
+	// such that the capacity can be looked up for any block height.
+	// Farms are assigned a unique sequential ID, in the same way as 3Bots are.
+	Plugin struct {
+		genesisMintCondition               types.UnlockConditionProxy
+		minterDefinitionTransactionVersion types.TransactionVersion
+
+		storage            modules.PluginViewStorage
+		unregisterCallback modules.PluginUnregisterCallback
+	}
+
+	// farmerAuthorization is a single (de)authorization of a farmer address,
+	// as stored in the authorization history of that address.
+	farmerAuthorization struct {
+		Height     types.BlockHeight
+		Authorized bool
+	}
+)
+
+var (
+	_ modules.ConsensusSetPlugin  = (*Plugin)(nil)
+	_ minting.MintConditionGetter = (*Plugin)(nil)
+	_ ctypes.CapacityReadRegistry = (*Plugin)(nil)
+	_ ctypes.FarmReadRegistry     = (*Plugin)(nil)
+)
+
+// NewPlugin creates a new capacity Plugin,
+// using the genesis mint condition and the version of the minter definition transactions
+// to keep track of the mint condition, which has to be fulfilled by all farmer authorization transactions.
+func NewPlugin(genesisMintCondition types.UnlockConditionProxy, minterDefinitionTransactionVersion types.TransactionVersion) *Plugin {
+	p := &Plugin{
+		genesisMintCondition:               genesisMintCondition,
+		minterDefinitionTransactionVersion: minterDefinitionTransactionVersion,
+	}
+	types.RegisterTransactionVersion(ctypes.TransactionVersionFarmerAuthorization, ctypes.FarmerAuthorizationTransactionController{
+		MintConditionGetter: p,
+	})
+	types.RegisterTransactionVersion(ctypes.TransactionVersionCapacityRegistration, ctypes.CapacityRegistrationTransactionController{})
+	types.RegisterTransactionVersion(ctypes.TransactionVersionFarmRegistration, ctypes.FarmRegistrationTransactionController{})
+	types.RegisterTransactionVersion(ctypes.TransactionVersionFarmUpdate, ctypes.FarmUpdateTransactionController{
+		Registry: p,
+	})
+	types.RegisterTransactionVersion(ctypes.TransactionVersionNodeLink, ctypes.NodeLinkTransactionController{})
+	types.RegisterTransactionVersion(ctypes.TransactionVersionFarmingReward, ctypes.FarmingRewardTransactionController{
+		MintConditionGetter: p,
+	})
+	return p
+}
+
+// InitPlugin initializes the Bucket for the first time
+func (p *Plugin) InitPlugin(metadata *persist.Metadata, bucket *bolt.Bucket, storage modules.PluginViewStorage, unregisterCallback modules.PluginUnregisterCallback) (persist.Metadata, error) {
+	p.storage = storage
+	p.unregisterCallback = unregisterCallback
+	if metadata == nil {
+		for _, bucketName := range bucketSlice {
+			b := bucket.Bucket([]byte(bucketName))
+			if b == nil {
+				var err error
+				_, err = bucket.CreateBucket([]byte(bucketName))
+				if err != nil {
+					return persist.Metadata{}, fmt.Errorf("failed to create bucket %s: %v", string(bucketName), err)
+				}
+			}
+		}
+
+		mintcond, err := rivbin.Marshal(p.genesisMintCondition)
+		if err != nil {
+			return persist.Metadata{}, fmt.Errorf("failed to marshal genesis mint condition: %v", err)
+		}
+		err = bucket.Bucket(bucketMintConditions).Put(encodeBlockheight(0), mintcond)
+		if err != nil {
+			return persist.Metadata{}, fmt.Errorf("failed to store genesis mint condition: %v", err)
+		}
+
+		metadata = &persist.Metadata{
+			Version: pluginDBVersion,
+			Header:  pluginDBHeader,
+		}
+	} else if metadata.Version != pluginDBVersion {
+		return persist.Metadata{}, errors.New("There is only 1 version of this plugin, version mismatch")
+	}
+	return *metadata, nil
+}
+
+// GetActiveMintCondition implements minting.MintConditionGetter.GetActiveMintCondition
+func (p *Plugin) GetActiveMintCondition() (mintCondition types.UnlockConditionProxy, err error) {
+	err = p.storage.View(func(bucket *bolt.Bucket) error {
+		mintConditionBucket := bucket.Bucket(bucketMintConditions)
+		if mintConditionBucket == nil {
+			return errors.New("corrupt capacity plugin DB: mint condition bucket does not exist")
+		}
+		mintCondition, err = getActiveMintCondition(mintConditionBucket)
+		return err
+	})
+	return
+}
+
+// GetMintConditionAt implements minting.MintConditionGetter.GetMintConditionAt
+func (p *Plugin) GetMintConditionAt(height types.BlockHeight) (mintCondition types.UnlockConditionProxy, err error) {
+	err = p.storage.View(func(bucket *bolt.Bucket) error {
+		mintConditionBucket := bucket.Bucket(bucketMintConditions)
+		if mintConditionBucket == nil {
+			return errors.New("corrupt capacity plugin DB: mint condition bucket does not exist")
+		}
+		mintCondition, err = getMintConditionAt(mintConditionBucket, height)
+		return err
+	})
+	return
+}
+
+// GetNodeCapacity implements CapacityReadRegistry.GetNodeCapacity
+func (p *Plugin) GetNodeCapacity(node types.PublicKey) (nc ctypes.NodeCapacity, err error) {
+	err = p.storage.View(func(bucket *bolt.Bucket) error {
+		history, err := getNodeHistory(bucket, node)
+		if err != nil {
+			return err
+		}
+		if len(history) == 0 {
+			return ctypes.ErrNodeNotFound
+		}
+		nc = history[len(history)-1]
+		return nil
+	})
+	return
+}
+
+// GetNodeCapacityAt implements CapacityReadRegistry.GetNodeCapacityAt
+func (p *Plugin) GetNodeCapacityAt(node types.PublicKey, height types.BlockHeight) (nc ctypes.NodeCapacity, err error) {
+	err = p.storage.View(func(bucket *bolt.Bucket) error {
+		history, err := getNodeHistory(bucket, node)
+		if err != nil {
+			return err
+		}
+		var ok bool
+		nc, ok = nodeCapacityAt(history, height)
+		if !ok {
+			return ctypes.ErrNodeNotFound
+		}
+		return nil
+	})
+	return
+}
+
+// GetFarmCapacity implements CapacityReadRegistry.GetFarmCapacity
+func (p *Plugin) GetFarmCapacity(id ctypes.FarmID) (fc ctypes.FarmCapacity, err error) {
+	err = p.storage.View(func(bucket *bolt.Bucket) error {
+		farmBucket := bucket.Bucket(bucketFarms)
+		if farmBucket == nil {
+			return errors.New("corrupt capacity plugin DB: farm bucket does not exist")
+		}
+		_, err := getFarm(farmBucket, id)
+		if err != nil {
+			return err
+		}
+		farmNodesBucket := bucket.Bucket(bucketFarmNodes)
+		if farmNodesBucket == nil {
+			return errors.New("corrupt capacity plugin DB: farm nodes bucket does not exist")
+		}
+		nodes, err := getFarmNodesFromBucket(farmNodesBucket, id)
+		if err != nil {
+			return err
+		}
+		fc.FarmID = id
+		// only the capacity registered by the farm itself counts,
+		// capacity registered by a previous farm of a node is ignored
+		for _, node := range nodes {
+			history, err := getNodeHistory(bucket, node)
+			if err != nil {
+				return err
+			}
+			if len(history) == 0 || history[len(history)-1].FarmID != id {
+				continue
+			}
+			fc.Nodes = append(fc.Nodes, node)
+			fc.Capacity = fc.Capacity.Add(history[len(history)-1].Capacity)
+		}
+		return nil
+	})
+	return
+}
+
+// IsFarmerAuthorized implements CapacityReadRegistry.IsFarmerAuthorized
+func (p *Plugin) IsFarmerAuthorized(farmer types.UnlockHash) (authorized bool, err error) {
+	err = p.storage.View(func(bucket *bolt.Bucket) error {
+		authorized, err = isFarmerAuthorized(bucket, farmer)
+		return err
+	})
+	return
+}
+
+// GetFarm implements FarmReadRegistry.GetFarm
+func (p *Plugin) GetFarm(id ctypes.FarmID) (record ctypes.FarmRecord, err error) {
+	err = p.storage.View(func(bucket *bolt.Bucket) error {
+		farmBucket := bucket.Bucket(bucketFarms)
+		if farmBucket == nil {
+			return errors.New("corrupt capacity plugin DB: farm bucket does not exist")
+		}
+		record, err = getFarm(farmBucket, id)
+		return err
+	})
+	return
+}
+
+// GetFarmForName implements FarmReadRegistry.GetFarmForName
+func (p *Plugin) GetFarmForName(name string) (record ctypes.FarmRecord, err error) {
+	err = p.storage.View(func(bucket *bolt.Bucket) error {
+		id, err := getFarmIDForName(bucket, name)
+		if err != nil {
+			return err
+		}
+		farmBucket := bucket.Bucket(bucketFarms)
+		if farmBucket == nil {
+			return errors.New("corrupt capacity plugin DB: farm bucket does not exist")
+		}
+		record, err = getFarm(farmBucket, id)
+		return err
+	})
+	return
+}
+
+// GetFarmNodes implements types.FarmReadRegistry.GetFarmNodes
+func (p *Plugin) GetFarmNodes(id ctypes.FarmID) (fn ctypes.FarmNodes, err error) {
+	err = p.storage.View(func(bucket *bolt.Bucket) error {
+		farmBucket := bucket.Bucket(bucketFarms)
+		if farmBucket == nil {
+			return errors.New("corrupt capacity plugin DB: farm bucket does not exist")
+		}
+		_, err := getFarm(farmBucket, id)
+		if err != nil {
+			return err
+		}
+		farmNodesBucket := bucket.Bucket(bucketFarmNodes)
+		if farmNodesBucket == nil {
+			return errors.New("corrupt capacity plugin DB: farm nodes bucket does not exist")
+		}
+		fn.FarmID = id
+		fn.Nodes, err = getFarmNodesFromBucket(farmNodesBucket, id)
+		return err
+	})
+	return
+}
+
+// GetNodeFarm implements types.FarmReadRegistry.GetNodeFarm
+func (p *Plugin) GetNodeFarm(node types.PublicKey) (link ctypes.NodeFarmLink, err error) {
+	err = p.storage.View(func(bucket *bolt.Bucket) error {
+		nodeFarmBucket := bucket.Bucket(bucketNodeFarms)
+		if nodeFarmBucket == nil {
+			return errors.New("corrupt capacity plugin DB: node farm bucket does not exist")
+		}
+		link, err = getNodeFarmLink(nodeFarmBucket, node)
+		return err
+	})
+	return
+}
+
+// GetFarmRewards implements types.FarmReadRegistry.GetFarmRewards
+func (p *Plugin) GetFarmRewards(id ctypes.FarmID) (fr ctypes.FarmRewards, err error) {
+	err = p.storage.View(func(bucket *bolt.Bucket) error {
+		farmBucket := bucket.Bucket(bucketFarms)
+		if farmBucket == nil {
+			return errors.New("corrupt capacity plugin DB: farm bucket does not exist")
+		}
+		_, err := getFarm(farmBucket, id)
+		if err != nil {
+			return err
+		}
+		farmRewardsBucket := bucket.Bucket(bucketFarmRewards)
+		if farmRewardsBucket == nil {
+			return errors.New("corrupt capacity plugin DB: farm rewards bucket does not exist")
+		}
+		fr.FarmID = id
+		fr.Rewards, err = getFarmRewardsFromBucket(farmRewardsBucket, id)
+		if err != nil {
+			return err
+		}
+		for _, reward := range fr.Rewards {
+			fr.Total = fr.Total.Add(reward.Value)
+		}
+		return nil
+	})
+	return
+}
+
+// ApplyBlock applies a block's capacity transactions to the capacity bucket.
+func (p *Plugin) ApplyBlock(block modules.ConsensusBlock, bucket *persist.LazyBoltBucket) error {
+	if bucket == nil {
+		return errors.New("capacity bucket does not exist")
+	}
+	var err error
+	for idx, txn := range block.Transactions {
+		cTxn := modules.ConsensusTransaction{
+			Transaction:            txn,
+			BlockHeight:            block.Height,
+			BlockTime:              block.Timestamp,
+			SequenceID:             uint16(idx),
+			SpentCoinOutputs:       block.SpentCoinOutputs,
+			SpentBlockStakeOutputs: block.SpentBlockStakeOutputs,
+		}
+		err = p.ApplyTransaction(cTxn, bucket)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ApplyBlockHeader applies a block's header to the capacity bucket,
+// nothing has to be done for this plugin.
+func (p *Plugin) ApplyBlockHeader(header modules.ConsensusBlockHeader, bucket *persist.LazyBoltBucket) error {
+	return nil
+}
+
+// ApplyTransaction applies a capacity (or minter definition) transaction to the capacity bucket.
+func (p *Plugin) ApplyTransaction(txn modules.ConsensusTransaction, bucket *persist.LazyBoltBucket) error {
+	if bucket == nil {
+		return errors.New("capacity bucket does not exist")
+	}
+	var err error
+	// check the version and handle the ones we care about
+	switch txn.Version {
+	case p.minterDefinitionTransactionVersion:
+		err = p.applyMinterDefinitionTx(txn, bucket)
+	case ctypes.TransactionVersionFarmerAuthorization:
+		err = p.applyFarmerAuthorizationTx(txn, bucket)
+	case ctypes.TransactionVersionCapacityRegistration:
+		err = p.applyCapacityRegistrationTx(txn, bucket)
+	case ctypes.TransactionVersionFarmRegistration:
+		err = p.applyFarmRegistrationTx(txn, bucket)
+	case ctypes.TransactionVersionFarmUpdate:
+		err = p.applyFarmUpdateTx(txn, bucket)
+	case ctypes.TransactionVersionNodeLink:
+		err = p.applyNodeLinkTx(txn, bucket)
+	case ctypes.TransactionVersionFarmingReward:
+		err = p.applyFarmingRewardTx(txn, bucket)
+	}
+	return err
+}
+
+func (p *Plugin) applyMinterDefinitionTx(txn modules.ConsensusTransaction, bucket *persist.LazyBoltBucket) error {
+	mdtx, err := minting.MinterDefinitionTransactionFromTransaction(txn.Transaction, p.minterDefinitionTransactionVersion, true)
+	if err != nil {
+		return fmt.Errorf("unexpected error while unpacking the minter def. tx type: %v", err)
+	}
+	mintConditionBucket, err := bucket.Bucket(bucketMintConditions)
+	if err != nil {
+		return fmt.Errorf("corrupt capacity plugin DB: %v", err)
+	}
+	mintcond, err := rivbin.Marshal(mdtx.MintCondition)
+	if err != nil {
+		return fmt.Errorf("failed to marshal mint condition: %v", err)
+	}
+	err = mintConditionBucket.Put(encodeBlockheight(txn.BlockHeight), mintcond)
+	if err != nil {
+		return fmt.Errorf(
+			"failed to put mint condition for block height %d: %v",
+			txn.BlockHeight, err)
+	}
+	return nil
+}
+
+func (p *Plugin) applyFarmerAuthorizationTx(txn modules.ConsensusTransaction, bucket *persist.LazyBoltBucket) error {
+	fatx, err := ctypes.FarmerAuthorizationTransactionFromTransaction(txn.Transaction)
+	if err != nil {
+		return fmt.Errorf("unexpected error while unpacking the farmer authorization tx type: %v", err)
+	}
+	farmerBucket, err := bucket.Bucket(bucketFarmers)
+	if err != nil {
+		return fmt.Errorf("corrupt capacity plugin DB: %v", err)
+	}
+	for _, uh := range fatx.AuthAddresses {
+		err = pushFarmerAuthorization(farmerBucket, uh, farmerAuthorization{Height: txn.BlockHeight, Authorized: true})
+		if err != nil {
+			return err
+		}
+	}
+	for _, uh := range fatx.DeauthAddresses {
+		err = pushFarmerAuthorization(farmerBucket, uh, farmerAuthorization{Height: txn.BlockHeight, Authorized: false})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Plugin) applyCapacityRegistrationTx(txn modules.ConsensusTransaction, bucket *persist.LazyBoltBucket) error {
+	crtx, err := ctypes.CapacityRegistrationTransactionFromTransaction(txn.Transaction)
+	if err != nil {
+		return fmt.Errorf("unexpected error while unpacking the capacity registration tx type: %v", err)
+	}
+	nodeBucket, err := bucket.Bucket(bucketNodes)
+	if err != nil {
+		return fmt.Errorf("corrupt capacity plugin DB: %v", err)
+	}
+	history, err := getNodeHistoryFromBucket(nodeBucket, crtx.Node)
+	if err != nil {
+		return err
+	}
+	history = append(history, ctypes.NodeCapacity{
+		Node:          crtx.Node,
+		FarmID:        crtx.FarmID,
+		Capacity:      crtx.Capacity,
+		Height:        txn.BlockHeight,
+		TransactionID: txn.ID(),
+	})
+	return putNodeHistory(nodeBucket, crtx.Node, history)
+}
+
+func (p *Plugin) applyFarmRegistrationTx(txn modules.ConsensusTransaction, bucket *persist.LazyBoltBucket) error {
+	frtx, err := ctypes.FarmRegistrationTransactionFromTransaction(txn.Transaction)
+	if err != nil {
+		return fmt.Errorf("unexpected error while unpacking the farm registration tx type: %v", err)
+	}
+	farmBucket, err := bucket.Bucket(bucketFarms)
+	if err != nil {
+		return fmt.Errorf("corrupt capacity plugin DB: %v", err)
+	}
+	farmNameBucket, err := bucket.Bucket(bucketFarmNames)
+	if err != nil {
+		return fmt.Errorf("corrupt capacity plugin DB: %v", err)
+	}
+	// get the next farm ID
+	rawID, err := farmBucket.NextSequence()
+	if err != nil {
+		return fmt.Errorf("failed to get next farm ID: %v", err)
+	}
+	if rawID > ctypes.MaxFarmID {
+		return fmt.Errorf("farm ID overflow: %d > %d", rawID, uint64(ctypes.MaxFarmID))
+	}
+	record := ctypes.FarmRecord{
+		ID:            ctypes.FarmID(rawID),
+		Name:          frtx.Name,
+		Owner:         frtx.Owner,
+		Managers:      frtx.Managers,
+		Location:      frtx.Location,
+		PayoutAddress: frtx.PayoutAddress,
+	}
+	err = putFarm(farmBucket, record)
+	if err != nil {
+		return err
+	}
+	return putFarmName(farmNameBucket, record.Name, record.ID)
+}
+
+func (p *Plugin) applyFarmUpdateTx(txn modules.ConsensusTransaction, bucket *persist.LazyBoltBucket) error {
+	futx, err := ctypes.FarmUpdateTransactionFromTransaction(txn.Transaction)
+	if err != nil {
+		return fmt.Errorf("unexpected error while unpacking the farm update tx type: %v", err)
+	}
+	farmBucket, err := bucket.Bucket(bucketFarms)
+	if err != nil {
+		return fmt.Errorf("corrupt capacity plugin DB: %v", err)
+	}
+	farmNameBucket, err := bucket.Bucket(bucketFarmNames)
+	if err != nil {
+		return fmt.Errorf("corrupt capacity plugin DB: %v", err)
+	}
+	farmUpdateBucket, err := bucket.Bucket(bucketFarmUpdates)
+	if err != nil {
+		return fmt.Errorf("corrupt capacity plugin DB: %v", err)
+	}
+	previous, err := getFarm(farmBucket, futx.FarmID)
+	if err != nil {
+		return err
+	}
+	// store the previous record, such that the update can be reverted
+	txID := txn.ID()
+	b, err := rivbin.Marshal(previous)
+	if err != nil {
+		return fmt.Errorf("failed to marshal previous record of farm %s: %v", previous.ID.String(), err)
+	}
+	err = farmUpdateBucket.Put(txID[:], b)
+	if err != nil {
+		return fmt.Errorf("failed to store previous record of farm %s: %v", previous.ID.String(), err)
+	}
+	record := previous
+	err = futx.UpdateFarmRecord(&record)
+	if err != nil {
+		return fmt.Errorf("corrupt capacity plugin DB: %v", err)
+	}
+	if record.Name != previous.Name {
+		err = farmNameBucket.Delete([]byte(previous.Name))
+		if err != nil {
+			return fmt.Errorf("failed to delete name %q of farm %s: %v", previous.Name, previous.ID.String(), err)
+		}
+		err = putFarmName(farmNameBucket, record.Name, record.ID)
+		if err != nil {
+			return err
+		}
+	}
+	return putFarm(farmBucket, record)
+}
+
+func (p *Plugin) applyNodeLinkTx(txn modules.ConsensusTransaction, bucket *persist.LazyBoltBucket) error {
+	nltx, err := ctypes.NodeLinkTransactionFromTransaction(txn.Transaction)
+	if err != nil {
+		return fmt.Errorf("unexpected error while unpacking the node link tx type: %v", err)
+	}
+	nodeFarmBucket, err := bucket.Bucket(bucketNodeFarms)
+	if err != nil {
+		return fmt.Errorf("corrupt capacity plugin DB: %v", err)
+	}
+	farmNodesBucket, err := bucket.Bucket(bucketFarmNodes)
+	if err != nil {
+		return fmt.Errorf("corrupt capacity plugin DB: %v", err)
+	}
+	history, err := getNodeFarmHistory(nodeFarmBucket, nltx.Node)
+	if err != nil {
+		return err
+	}
+	// a node can move from one farm to another
+	if len(history) > 0 {
+		if previous := history[len(history)-1].FarmID; previous != 0 {
+			err = removeFarmNode(farmNodesBucket, previous, nltx.Node)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if !nltx.IsUnlink() {
+		err = addFarmNode(farmNodesBucket, nltx.FarmID, nltx.Node)
+		if err != nil {
+			return err
+		}
+	}
+	history = append(history, ctypes.NodeFarmLink{
+		Node:          nltx.Node,
+		FarmID:        nltx.FarmID,
+		Height:        txn.BlockHeight,
+		TransactionID: txn.ID(),
+	})
+	return putNodeFarmHistory(nodeFarmBucket, nltx.Node, history)
+}
+
+func (p *Plugin) applyFarmingRewardTx(txn modules.ConsensusTransaction, bucket *persist.LazyBoltBucket) error {
+	frtx, err := ctypes.FarmingRewardTransactionFromTransaction(txn.Transaction)
+	if err != nil {
+		return fmt.Errorf("unexpected error while unpacking the farming reward tx type: %v", err)
+	}
+	rewardHeightsBucket, err := bucket.Bucket(bucketRewardHeights)
+	if err != nil {
+		return fmt.Errorf("corrupt capacity plugin DB: %v", err)
+	}
+	farmRewardsBucket, err := bucket.Bucket(bucketFarmRewards)
+	if err != nil {
+		return fmt.Errorf("corrupt capacity plugin DB: %v", err)
+	}
+	txID := txn.ID()
+	err = rewardHeightsBucket.Put(encodeBlockheight(frtx.SnapshotHeight), txID[:])
+	if err != nil {
+		return fmt.Errorf(
+			"failed to put farming reward for snapshot height %d: %v",
+			frtx.SnapshotHeight, err)
+	}
+	for _, payout := range frtx.Payouts {
+		err = pushFarmReward(farmRewardsBucket, payout.FarmID, ctypes.FarmReward{
+			Value:          payout.Value,
+			PayoutAddress:  payout.PayoutAddress,
+			SnapshotHeight: frtx.SnapshotHeight,
+			Height:         txn.BlockHeight,
+			TransactionID:  txID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RevertBlock reverts a block's capacity transactions from the capacity bucket.
+func (p *Plugin) RevertBlock(block modules.ConsensusBlock, bucket *persist.LazyBoltBucket) error {
+	if bucket == nil {
+		return errors.New("capacity bucket does not exist")
+	}
+	// revert in the opposite order as the block was applied,
+	// such that the registration histories are popped in the correct order
+	var err error
+	for idx := len(block.Transactions) - 1; idx >= 0; idx-- {
+		cTxn := modules.ConsensusTransaction{
+			Transaction:            block.Transactions[idx],
+			BlockHeight:            block.Height,
+			BlockTime:              block.Timestamp,
+			SequenceID:             uint16(idx),
+			SpentCoinOutputs:       block.SpentCoinOutputs,
+			SpentBlockStakeOutputs: block.SpentBlockStakeOutputs,
+		}
+		err = p.RevertTransaction(cTxn, bucket)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RevertBlockHeader reverts a block's header from the capacity bucket,
+// nothing has to be done for this plugin.
+func (p *Plugin) RevertBlockHeader(header modules.ConsensusBlockHeader, bucket *persist.LazyBoltBucket) error {
+	return nil
+}
+
+// RevertTransaction reverts a capacity (or minter definition) transaction from the capacity bucket.
+func (p *Plugin) RevertTransaction(txn modules.ConsensusTransaction, bucket *persist.LazyBoltBucket) error {
+	if bucket == nil {
+		return errors.New("capacity bucket does not exist")
+	}
+	var err error
+	switch txn.Version {
+	case p.minterDefinitionTransactionVersion:
+		err = p.revertMinterDefinitionTx(txn, bucket)
+	case ctypes.TransactionVersionFarmerAuthorization:
+		err = p.revertFarmerAuthorizationTx(txn, bucket)
+	case ctypes.TransactionVersionCapacityRegistration:
+		err = p.revertCapacityRegistrationTx(txn, bucket)
+	case ctypes.TransactionVersionFarmRegistration:
+		err = p.revertFarmRegistrationTx(txn, bucket)
+	case ctypes.TransactionVersionFarmUpdate:
+		err = p.revertFarmUpdateTx(txn, bucket)
+	case ctypes.TransactionVersionNodeLink:
+		err = p.revertNodeLinkTx(txn, bucket)
+	case ctypes.TransactionVersionFarmingReward:
+		err = p.revertFarmingRewardTx(txn, bucket)
+	}
+	return err
+}
+
+func (p *Plugin) revertMinterDefinitionTx(txn modules.ConsensusTransaction, bucket *persist.LazyBoltBucket) error {
+	mintConditionBucket, err := bucket.Bucket(bucketMintConditions)
+	if err != nil {
+		return fmt.Errorf("corrupt capacity plugin DB: %v", err)
+	}
+	err = mintConditionBucket.Delete(encodeBlockheight(txn.BlockHeight))
+	if err != nil {
+		return fmt.Errorf(
+			"failed to delete mint condition for block height %d: %v",
+			txn.BlockHeight, err)
+	}
+	return nil
+}
+
+func (p *Plugin) revertFarmerAuthorizationTx(txn modules.ConsensusTransaction, bucket *persist.LazyBoltBucket) error {
+	fatx, err := ctypes.FarmerAuthorizationTransactionFromTransaction(txn.Transaction)
+	if err != nil {
+		return fmt.Errorf("unexpected error while unpacking the farmer authorization tx type: %v", err)
+	}
+	farmerBucket, err := bucket.Bucket(bucketFarmers)
+	if err != nil {
+		return fmt.Errorf("corrupt capacity plugin DB: %v", err)
+	}
+	// revert in the opposite order as the addresses were applied
+	for idx := len(fatx.DeauthAddresses) - 1; idx >= 0; idx-- {
+		err = popFarmerAuthorization(farmerBucket, fatx.DeauthAddresses[idx])
+		if err != nil {
+			return err
+		}
+	}
+	for idx := len(fatx.AuthAddresses) - 1; idx >= 0; idx-- {
+		err = popFarmerAuthorization(farmerBucket, fatx.AuthAddresses[idx])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Plugin) revertCapacityRegistrationTx(txn modules.ConsensusTransaction, bucket *persist.LazyBoltBucket) error {
+	crtx, err := ctypes.CapacityRegistrationTransactionFromTransaction(txn.Transaction)
+	if err != nil {
+		return fmt.Errorf("unexpected error while unpacking the capacity registration tx type: %v", err)
+	}
+	nodeBucket, err := bucket.Bucket(bucketNodes)
+	if err != nil {
+		return fmt.Errorf("corrupt capacity plugin DB: %v", err)
+	}
+	history, err := getNodeHistoryFromBucket(nodeBucket, crtx.Node)
+	if err != nil {
+		return err
+	}
+	if len(history) == 0 {
+		return fmt.Errorf("corrupt capacity plugin DB: no capacity registered for node %s", crtx.Node.String())
+	}
+	history = history[:len(history)-1]
+	return putNodeHistory(nodeBucket, crtx.Node, history)
+}
+
+func (p *Plugin) revertFarmRegistrationTx(txn modules.ConsensusTransaction, bucket *persist.LazyBoltBucket) error {
+	frtx, err := ctypes.FarmRegistrationTransactionFromTransaction(txn.Transaction)
+	if err != nil {
+		return fmt.Errorf("unexpected error while unpacking the farm registration tx type: %v", err)
+	}
+	farmBucket, err := bucket.Bucket(bucketFarms)
+	if err != nil {
+		return fmt.Errorf("corrupt capacity plugin DB: %v", err)
+	}
+	farmNameBucket, err := bucket.Bucket(bucketFarmNames)
+	if err != nil {
+		return fmt.Errorf("corrupt capacity plugin DB: %v", err)
+	}
+	// the farm registered last is the one to revert
+	rawID := farmBucket.Sequence()
+	record, err := getFarm(farmBucket, ctypes.FarmID(rawID))
+	if err != nil {
+		return err
+	}
+	if record.Name != frtx.Name {
+		return fmt.Errorf("corrupt capacity plugin DB: farm %s is registered as %q instead of %q", record.ID.String(), record.Name, frtx.Name)
+	}
+	err = farmBucket.Delete(encodeFarmID(record.ID))
+	if err != nil {
+		return fmt.Errorf("failed to delete farm %s: %v", record.ID.String(), err)
+	}
+	err = farmNameBucket.Delete([]byte(record.Name))
+	if err != nil {
+		return fmt.Errorf("failed to delete name %q of farm %s: %v", record.Name, record.ID.String(), err)
+	}
+	// release the farm ID, such that it can be assigned again
+	err = farmBucket.SetSequence(rawID - 1)
+	if err != nil {
+		return fmt.Errorf("failed to revert the farm ID sequence: %v", err)
+	}
+	return nil
+}
+
+func (p *Plugin) revertFarmUpdateTx(txn modules.ConsensusTransaction, bucket *persist.LazyBoltBucket) error {
+	futx, err := ctypes.FarmUpdateTransactionFromTransaction(txn.Transaction)
+	if err != nil {
+		return fmt.Errorf("unexpected error while unpacking the farm update tx type: %v", err)
+	}
+	farmBucket, err := bucket.Bucket(bucketFarms)
+	if err != nil {
+		return fmt.Errorf("corrupt capacity plugin DB: %v", err)
+	}
+	farmNameBucket, err := bucket.Bucket(bucketFarmNames)
+	if err != nil {
+		return fmt.Errorf("corrupt capacity plugin DB: %v", err)
+	}
+	farmUpdateBucket, err := bucket.Bucket(bucketFarmUpdates)
+	if err != nil {
+		return fmt.Errorf("corrupt capacity plugin DB: %v", err)
+	}
+	txID := txn.ID()
+	b := farmUpdateBucket.Get(txID[:])
+	if len(b) == 0 {
+		return fmt.Errorf("corrupt capacity plugin DB: no previous record stored for farm update tx %s", txID.String())
+	}
+	var previous ctypes.FarmRecord
+	err = rivbin.Unmarshal(b, &previous)
+	if err != nil {
+		return fmt.Errorf("corrupt capacity plugin DB: failed to decode previous record of farm %s: %v", futx.FarmID.String(), err)
+	}
+	record, err := getFarm(farmBucket, futx.FarmID)
+	if err != nil {
+		return err
+	}
+	if record.Name != previous.Name {
+		err = farmNameBucket.Delete([]byte(record.Name))
+		if err != nil {
+			return fmt.Errorf("failed to delete name %q of farm %s: %v", record.Name, record.ID.String(), err)
+		}
+		err = putFarmName(farmNameBucket, previous.Name, previous.ID)
+		if err != nil {
+			return err
+		}
+	}
+	err = putFarm(farmBucket, previous)
+	if err != nil {
+		return err
+	}
+	err = farmUpdateBucket.Delete(txID[:])
+	if err != nil {
+		return fmt.Errorf("failed to delete previous record of farm %s: %v", previous.ID.String(), err)
+	}
+	return nil
+}
+
+func (p *Plugin) revertNodeLinkTx(txn modules.ConsensusTransaction, bucket *persist.LazyBoltBucket) error {
+	nltx, err := ctypes.NodeLinkTransactionFromTransaction(txn.Transaction)
+	if err != nil {
+		return fmt.Errorf("unexpected error while unpacking the node link tx type: %v", err)
+	}
+	nodeFarmBucket, err := bucket.Bucket(bucketNodeFarms)
+	if err != nil {
+		return fmt.Errorf("corrupt capacity plugin DB: %v", err)
+	}
+	farmNodesBucket, err := bucket.Bucket(bucketFarmNodes)
+	if err != nil {
+		return fmt.Errorf("corrupt capacity plugin DB: %v", err)
+	}
+	history, err := getNodeFarmHistory(nodeFarmBucket, nltx.Node)
+	if err != nil {
+		return err
+	}
+	if len(history) == 0 {
+		return fmt.Errorf("corrupt capacity plugin DB: no farm links stored for node %s", nltx.Node.String())
+	}
+	history = history[:len(history)-1]
+	if !nltx.IsUnlink() {
+		err = removeFarmNode(farmNodesBucket, nltx.FarmID, nltx.Node)
+		if err != nil {
+			return err
+		}
+	}
+	// link the node back to its previous farm, if it had one
+	if len(history) > 0 {
+		if previous := history[len(history)-1].FarmID; previous != 0 {
+			err = addFarmNode(farmNodesBucket, previous, nltx.Node)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return putNodeFarmHistory(nodeFarmBucket, nltx.Node, history)
+}
+
+func (p *Plugin) revertFarmingRewardTx(txn modules.ConsensusTransaction, bucket *persist.LazyBoltBucket) error {
+	frtx, err := ctypes.FarmingRewardTransactionFromTransaction(txn.Transaction)
+	if err != nil {
+		return fmt.Errorf("unexpected error while unpacking the farming reward tx type: %v", err)
+	}
+	rewardHeightsBucket, err := bucket.Bucket(bucketRewardHeights)
+	if err != nil {
+		return fmt.Errorf("corrupt capacity plugin DB: %v", err)
+	}
+	farmRewardsBucket, err := bucket.Bucket(bucketFarmRewards)
+	if err != nil {
+		return fmt.Errorf("corrupt capacity plugin DB: %v", err)
+	}
+	// revert in the opposite order as the payouts were applied
+	for idx := len(frtx.Payouts) - 1; idx >= 0; idx-- {
+		err = popFarmReward(farmRewardsBucket, frtx.Payouts[idx].FarmID)
+		if err != nil {
+			return err
+		}
+	}
+	err = rewardHeightsBucket.Delete(encodeBlockheight(frtx.SnapshotHeight))
+	if err != nil {
+		return fmt.Errorf(
+			"failed to delete farming reward for snapshot height %d: %v",
+			frtx.SnapshotHeight, err)
+	}
+	return nil
+}
+
+// TransactionValidators returns all tx validators linked to this plugin
+func (p *Plugin) TransactionValidators() []modules.PluginTransactionValidationFunction {
+	return nil
+}
+
+// TransactionValidatorVersionFunctionMapping returns all tx validators linked to this plugin
+func (p *Plugin) TransactionValidatorVersionFunctionMapping() map[types.TransactionVersion][]modules.PluginTransactionValidationFunction {
+	return map[types.TransactionVersion][]modules.PluginTransactionValidationFunction{
+		ctypes.TransactionVersionFarmerAuthorization: {
+			p.validateFarmerAuthorizationTx,
+		},
+		ctypes.TransactionVersionCapacityRegistration: {
+			p.validateCapacityRegistrationTx,
+		},
+		ctypes.TransactionVersionFarmRegistration: {
+			p.validateFarmRegistrationTx,
+		},
+		ctypes.TransactionVersionFarmUpdate: {
+			p.validateFarmUpdateTx,
+		},
+		ctypes.TransactionVersionNodeLink: {
+			p.validateNodeLinkTx,
+		},
+		ctypes.TransactionVersionFarmingReward: {
+			p.validateFarmingRewardTx,
+		},
+	}
+}
+
+func (p *Plugin) validateFarmerAuthorizationTx(txn modules.ConsensusTransaction, ctx types.TransactionValidationContext, bucket *persist.LazyBoltBucket) error {
+	fatx, err := ctypes.FarmerAuthorizationTransactionFromTransaction(txn.Transaction)
+	if err != nil {
+		return fmt.Errorf("failed to use tx as a farmer authorization tx: %v", err)
+	}
+	// ensure the Nonce is not Nil
+	if fatx.Nonce == (types.TransactionNonce{}) {
+		return errors.New("nil nonce is not allowed for a farmer authorization transaction")
+	}
+	err = validateFarmerAddresses(fatx.AuthAddresses, fatx.DeauthAddresses)
+	if err != nil {
+		return err
+	}
+
+	rootBucket, err := bucket.AsBoltBucket()
+	if err != nil {
+		return fmt.Errorf("failed to cast passed bucket as a bolt bucket: %v", err)
+	}
+	// check if MintFulfillment fulfills the Globally defined MintCondition for the context-defined block height
+	err = p.fulfillMintCondition(rootBucket, fatx.MintFulfillment, txn, ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fulfill mint condition for farmer authorization transaction: %v", err)
+	}
+
+	// validate the miner fee
+	for _, fee := range fatx.MinerFees {
+		if fee.Cmp(ctx.MinimumMinerFee) == -1 {
+			return types.ErrTooSmallMinerFee
+		}
+	}
+	return nil
+}
+
+func (p *Plugin) validateCapacityRegistrationTx(txn modules.ConsensusTransaction, ctx types.TransactionValidationContext, bucket *persist.LazyBoltBucket) error {
+	crtx, err := ctypes.CapacityRegistrationTransactionFromTransaction(txn.Transaction)
+	if err != nil {
+		return fmt.Errorf("failed to use tx as a capacity registration tx: %v", err)
+	}
+	err = validateNodePublicKey(crtx.Node)
+	if err != nil {
+		return err
+	}
+	// farm managers are always personal (public key) addresses
+	if crtx.Manager.Type != types.UnlockTypePubKey {
+		return errors.New("capacity can only be registered using a personal (public key) manager address")
+	}
+
+	rootBucket, err := bucket.AsBoltBucket()
+	if err != nil {
+		return fmt.Errorf("failed to cast passed bucket as a bolt bucket: %v", err)
+	}
+	farmBucket := rootBucket.Bucket(bucketFarms)
+	if farmBucket == nil {
+		return errors.New("corrupt capacity plugin DB: farm bucket does not exist")
+	}
+	nodeFarmBucket := rootBucket.Bucket(bucketNodeFarms)
+	if nodeFarmBucket == nil {
+		return errors.New("corrupt capacity plugin DB: node farm bucket does not exist")
+	}
+	record, err := getFarm(farmBucket, crtx.FarmID)
+	if err != nil {
+		return err
+	}
+	if !record.IsManager(crtx.Manager) {
+		return ctypes.ErrNotAFarmManager
+	}
+	// only farms owned by an authorized farmer can register capacity
+	authorized, err := isFarmerAuthorized(rootBucket, record.Owner.UnlockHash())
+	if err != nil {
+		return err
+	}
+	if !authorized {
+		return ctypes.ErrFarmerNotAuthorized
+	}
+	// capacity can only be registered for nodes linked to the farm
+	link, err := getNodeFarmLink(nodeFarmBucket, crtx.Node)
+	if err != nil {
+		return err
+	}
+	if link.FarmID != crtx.FarmID {
+		return ctypes.ErrNodeNotLinked
+	}
+
+	// check if the node signed the transaction using its own private key
+	err = validateNodeSignature(txn.Transaction, crtx.Node, crtx.NodeSignature, ctx, ctypes.CapacityRegistrationSignatureSpecifierNode)
+	if err != nil {
+		return fmt.Errorf("invalid node signature for capacity registration transaction: %v", err)
+	}
+	// check if the ManagerFulfillment fulfills the condition of the manager address
+	err = types.NewCondition(types.NewUnlockHashCondition(crtx.Manager)).Fulfill(crtx.ManagerFulfillment, types.FulfillContext{
+		ExtraObjects: []interface{}{ctypes.CapacityRegistrationSignatureSpecifierManager},
+		BlockHeight:  ctx.BlockHeight,
+		BlockTime:    ctx.BlockTime,
+		Transaction:  txn.Transaction,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to fulfill manager condition for capacity registration transaction: %v", err)
+	}
+
+	// validate the miner fee
+	for _, fee := range crtx.MinerFees {
+		if fee.Cmp(ctx.MinimumMinerFee) == -1 {
+			return types.ErrTooSmallMinerFee
+		}
+	}
+	// the coin inputs have to pay for the miner fees and refund
+	return consensus.ValidateCoinOutputsAreBalanced(txn, ctx)
+}
+
+func (p *Plugin) validateFarmRegistrationTx(txn modules.ConsensusTransaction, ctx types.TransactionValidationContext, bucket *persist.LazyBoltBucket) error {
+	frtx, err := ctypes.FarmRegistrationTransactionFromTransaction(txn.Transaction)
+	if err != nil {
+		return fmt.Errorf("failed to use tx as a farm registration tx: %v", err)
+	}
+	err = ctypes.ValidateFarmName(frtx.Name)
+	if err != nil {
+		return err
+	}
+	err = ctypes.ValidateFarmOwner(frtx.Owner)
+	if err != nil {
+		return err
+	}
+	err = ctypes.ValidateFarmManagers(frtx.Managers)
+	if err != nil {
+		return err
+	}
+	err = ctypes.ValidateFarmLocation(frtx.Location)
+	if err != nil {
+		return err
+	}
+	err = ctypes.ValidateFarmPayoutAddress(frtx.PayoutAddress)
+	if err != nil {
+		return err
+	}
+
+	rootBucket, err := bucket.AsBoltBucket()
+	if err != nil {
+		return fmt.Errorf("failed to cast passed bucket as a bolt bucket: %v", err)
+	}
+	// the farm name has to be unique
+	err = validateFarmNameAvailable(rootBucket, frtx.Name)
+	if err != nil {
+		return err
+	}
+	// only authorized farmers can register a farm
+	authorized, err := isFarmerAuthorized(rootBucket, frtx.Owner.UnlockHash())
+	if err != nil {
+		return err
+	}
+	if !authorized {
+		return ctypes.ErrFarmerNotAuthorized
+	}
+
+	// check if the OwnerFulfillment fulfills the owner condition of the farm
+	err = frtx.Owner.Fulfill(frtx.OwnerFulfillment, types.FulfillContext{
+		BlockHeight: ctx.BlockHeight,
+		BlockTime:   ctx.BlockTime,
+		Transaction: txn.Transaction,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to fulfill owner condition for farm registration transaction: %v", err)
+	}
+
+	// validate the miner fee
+	for _, fee := range frtx.MinerFees {
+		if fee.Cmp(ctx.MinimumMinerFee) == -1 {
+			return types.ErrTooSmallMinerFee
+		}
+	}
+	// the coin inputs have to pay for the miner fees and refund
+	return consensus.ValidateCoinOutputsAreBalanced(txn, ctx)
+}
+
+func (p *Plugin) validateFarmUpdateTx(txn modules.ConsensusTransaction, ctx types.TransactionValidationContext, bucket *persist.LazyBoltBucket) error {
+	futx, err := ctypes.FarmUpdateTransactionFromTransaction(txn.Transaction)
+	if err != nil {
+		return fmt.Errorf("failed to use tx as a farm update tx: %v", err)
+	}
+	if futx.IsEmpty() {
+		return ctypes.ErrNoFarmUpdate
+	}
+
+	rootBucket, err := bucket.AsBoltBucket()
+	if err != nil {
+		return fmt.Errorf("failed to cast passed bucket as a bolt bucket: %v", err)
+	}
+	farmBucket := rootBucket.Bucket(bucketFarms)
+	if farmBucket == nil {
+		return errors.New("corrupt capacity plugin DB: farm bucket does not exist")
+	}
+	record, err := getFarm(farmBucket, futx.FarmID)
+	if err != nil {
+		return err
+	}
+
+	// check if the OwnerFulfillment fulfills the (current) owner condition of the farm
+	err = record.Owner.Fulfill(futx.OwnerFulfillment, types.FulfillContext{
+		BlockHeight: ctx.BlockHeight,
+		BlockTime:   ctx.BlockTime,
+		Transaction: txn.Transaction,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to fulfill owner condition for farm update transaction: %v", err)
+	}
+
+	if futx.Name != "" && futx.Name != record.Name {
+		err = ctypes.ValidateFarmName(futx.Name)
+		if err != nil {
+			return err
+		}
+		err = validateFarmNameAvailable(rootBucket, futx.Name)
+		if err != nil {
+			return err
+		}
+	}
+	if futx.Owner != nil {
+		err = ctypes.ValidateFarmOwner(*futx.Owner)
+		if err != nil {
+			return err
+		}
+		// a farm can only be transferred to an authorized farmer
+		authorized, err := isFarmerAuthorized(rootBucket, futx.Owner.UnlockHash())
+		if err != nil {
+			return err
+		}
+		if !authorized {
+			return ctypes.ErrFarmerNotAuthorized
+		}
+	}
+	if futx.Location != nil {
+		err = ctypes.ValidateFarmLocation(*futx.Location)
+		if err != nil {
+			return err
+		}
+	}
+	if futx.PayoutAddress != nil {
+		err = ctypes.ValidateFarmPayoutAddress(*futx.PayoutAddress)
+		if err != nil {
+			return err
+		}
+	}
+	// apply the update to the (decoded) record, such that the managers can be validated
+	err = futx.UpdateFarmRecord(&record)
+	if err != nil {
+		return err
+	}
+	err = ctypes.ValidateFarmManagers(record.Managers)
+	if err != nil {
+		return err
+	}
+
+	// validate the miner fee
+	for _, fee := range futx.MinerFees {
+		if fee.Cmp(ctx.MinimumMinerFee) == -1 {
+			return types.ErrTooSmallMinerFee
+		}
+	}
+	// the coin inputs have to pay for the miner fees and refund
+	return consensus.ValidateCoinOutputsAreBalanced(txn, ctx)
+}
+
+func (p *Plugin) validateNodeLinkTx(txn modules.ConsensusTransaction, ctx types.TransactionValidationContext, bucket *persist.LazyBoltBucket) error {
+	nltx, err := ctypes.NodeLinkTransactionFromTransaction(txn.Transaction)
+	if err != nil {
+		return fmt.Errorf("failed to use tx as a node link tx: %v", err)
+	}
+	err = validateNodePublicKey(nltx.Node)
+	if err != nil {
+		return err
+	}
+	// farm managers are always personal (public key) addresses
+	if nltx.Manager.Type != types.UnlockTypePubKey {
+		return errors.New("a node can only be linked using a personal (public key) manager address")
+	}
+
+	rootBucket, err := bucket.AsBoltBucket()
+	if err != nil {
+		return fmt.Errorf("failed to cast passed bucket as a bolt bucket: %v", err)
+	}
+	farmBucket := rootBucket.Bucket(bucketFarms)
+	if farmBucket == nil {
+		return errors.New("corrupt capacity plugin DB: farm bucket does not exist")
+	}
+	nodeFarmBucket := rootBucket.Bucket(bucketNodeFarms)
+	if nodeFarmBucket == nil {
+		return errors.New("corrupt capacity plugin DB: node farm bucket does not exist")
+	}
+	var currentFarmID ctypes.FarmID
+	link, err := getNodeFarmLink(nodeFarmBucket, nltx.Node)
+	switch err {
+	case nil:
+		currentFarmID = link.FarmID
+	case ctypes.ErrNodeNotLinked:
+	default:
+		return err
+	}
+	// linking requires a manager of the new farm,
+	// while unlinking requires a manager of the current farm
+	farmID := nltx.FarmID
+	if nltx.IsUnlink() {
+		if currentFarmID == 0 {
+			return ctypes.ErrNodeNotLinked
+		}
+		farmID = currentFarmID
+	} else if currentFarmID == nltx.FarmID {
+		return ctypes.ErrNodeAlreadyLinked
+	}
+	record, err := getFarm(farmBucket, farmID)
+	if err != nil {
+		return err
+	}
+	if !record.IsManager(nltx.Manager) {
+		return ctypes.ErrNotAFarmManager
+	}
+
+	// check if the node signed the transaction using its own private key
+	err = validateNodeSignature(txn.Transaction, nltx.Node, nltx.NodeSignature, ctx, ctypes.NodeLinkSignatureSpecifierNode)
+	if err != nil {
+		return fmt.Errorf("invalid node signature for node link transaction: %v", err)
+	}
+	// check if the ManagerFulfillment fulfills the condition of the manager address
+	err = types.NewCondition(types.NewUnlockHashCondition(nltx.Manager)).Fulfill(nltx.ManagerFulfillment, types.FulfillContext{
+		ExtraObjects: []interface{}{ctypes.NodeLinkSignatureSpecifierManager},
+		BlockHeight:  ctx.BlockHeight,
+		BlockTime:    ctx.BlockTime,
+		Transaction:  txn.Transaction,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to fulfill manager condition for node link transaction: %v", err)
+	}
+
+	// validate the miner fee
+	for _, fee := range nltx.MinerFees {
+		if fee.Cmp(ctx.MinimumMinerFee) == -1 {
+			return types.ErrTooSmallMinerFee
+		}
+	}
+	// the coin inputs have to pay for the miner fees and refund
+	return consensus.ValidateCoinOutputsAreBalanced(txn, ctx)
+}
+
+func (p *Plugin) validateFarmingRewardTx(txn modules.ConsensusTransaction, ctx types.TransactionValidationContext, bucket *persist.LazyBoltBucket) error {
+	frtx, err := ctypes.FarmingRewardTransactionFromTransaction(txn.Transaction)
+	if err != nil {
+		return fmt.Errorf("failed to use tx as a farming reward tx: %v", err)
+	}
+	// ensure the Nonce is not Nil
+	if frtx.Nonce == (types.TransactionNonce{}) {
+		return errors.New("nil nonce is not allowed for a farming reward transaction")
+	}
+	if len(frtx.Payouts) == 0 {
+		return ctypes.ErrNoFarmPayouts
+	}
+	// rewards can only be paid out for a capacity snapshot of the past
+	if frtx.SnapshotHeight >= ctx.BlockHeight {
+		return ctypes.ErrInvalidRewardSnapshot
+	}
+
+	rootBucket, err := bucket.AsBoltBucket()
+	if err != nil {
+		return fmt.Errorf("failed to cast passed bucket as a bolt bucket: %v", err)
+	}
+	// each snapshot can only be rewarded once, and in order
+	rewardHeightsBucket := rootBucket.Bucket(bucketRewardHeights)
+	if rewardHeightsBucket == nil {
+		return errors.New("corrupt capacity plugin DB: reward heights bucket does not exist")
+	}
+	if k, _ := rewardHeightsBucket.Cursor().Last(); len(k) != 0 && frtx.SnapshotHeight <= decodeBlockheight(k) {
+		return ctypes.ErrRewardSnapshotAlreadyUsed
+	}
+	// each payout has to go to the payout address of a registered farm
+	farmBucket := rootBucket.Bucket(bucketFarms)
+	if farmBucket == nil {
+		return errors.New("corrupt capacity plugin DB: farm bucket does not exist")
+	}
+	farms := make(map[ctypes.FarmID]struct{}, len(frtx.Payouts))
+	for _, payout := range frtx.Payouts {
+		if _, ok := farms[payout.FarmID]; ok {
+			return fmt.Errorf("farm %s is paid out more than once", payout.FarmID.String())
+		}
+		farms[payout.FarmID] = struct{}{}
+		if payout.Value.IsZero() {
+			return ctypes.ErrInvalidFarmPayoutValue
+		}
+		record, err := getFarm(farmBucket, payout.FarmID)
+		if err != nil {
+			return fmt.Errorf("invalid payout to farm %s: %v", payout.FarmID.String(), err)
+		}
+		if record.PayoutAddress.Cmp(payout.PayoutAddress) != 0 {
+			return fmt.Errorf("invalid payout to farm %s: %v", payout.FarmID.String(), ctypes.ErrInvalidFarmPayoutAddress)
+		}
+	}
+	// each farm has to have provided capacity at the snapshot height
+	withCapacity, err := farmsWithCapacityAt(rootBucket, frtx.SnapshotHeight, farms)
+	if err != nil {
+		return err
+	}
+	for _, payout := range frtx.Payouts {
+		if _, ok := withCapacity[payout.FarmID]; !ok {
+			return fmt.Errorf("invalid payout to farm %s: %v", payout.FarmID.String(), ctypes.ErrFarmWithoutCapacity)
+		}
+	}
+
+	// check if MintFulfillment fulfills the Globally defined MintCondition for the context-defined block height
+	err = p.fulfillMintCondition(rootBucket, frtx.MintFulfillment, txn, ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fulfill mint condition for farming reward transaction: %v", err)
+	}
+
+	// validate the miner fee
+	for _, fee := range frtx.MinerFees {
+		if fee.Cmp(ctx.MinimumMinerFee) == -1 {
+			return types.ErrTooSmallMinerFee
+		}
+	}
+	return nil
+}
+
+// fulfillMintCondition checks if the given fulfillment fulfills the mint condition
+// active at the block height defined by the validation context
+func (p *Plugin) fulfillMintCondition(rootBucket *bolt.Bucket, fulfillment types.UnlockFulfillmentProxy, txn modules.ConsensusTransaction, ctx types.TransactionValidationContext) error {
+	mintConditionBucket := rootBucket.Bucket(bucketMintConditions)
+	if mintConditionBucket == nil {
+		return errors.New("corrupt capacity plugin DB: mint condition bucket does not exist")
+	}
+	var (
+		mintCondition types.UnlockConditionProxy
+		err           error
+	)
+	if ctx.Confirmed || ctx.BlockHeight > 0 {
+		mintCondition, err = getMintConditionAt(mintConditionBucket, ctx.BlockHeight)
+	} else {
+		mintCondition, err = getActiveMintCondition(mintConditionBucket)
+	}
+	if err != nil {
+		return err
+	}
+	return mintCondition.Fulfill(fulfillment, types.FulfillContext{
+		BlockHeight: ctx.BlockHeight,
+		BlockTime:   ctx.BlockTime,
+		Transaction: txn.Transaction,
+	})
+}
+
+// Close unregisters the plugin from the consensus
+func (p *Plugin) Close() error {
+	if p.storage == nil {
+		return nil
+	}
+	return p.storage.Close()
+}
+
+func getActiveMintCondition(mintConditionBucket *bolt.Bucket) (types.UnlockConditionProxy, error) {
+	k, b := mintConditionBucket.Cursor().Last()
+	if len(k) == 0 {
+		return types.UnlockConditionProxy{}, errors.New("corrupt capacity plugin DB: no mint condition could be found")
+	}
+	var mintCondition types.UnlockConditionProxy
+	err := rivbin.Unmarshal(b, &mintCondition)
+	if err != nil {
+		return types.UnlockConditionProxy{}, fmt.Errorf("corrupt capacity plugin DB: failed to decode found mint condition: %v", err)
+	}
+	return mintCondition, nil
+}
+
+func getMintConditionAt(mintConditionBucket *bolt.Bucket, height types.BlockHeight) (types.UnlockConditionProxy, error) {
+	cursor := mintConditionBucket.Cursor()
+	k, b := cursor.Seek(encodeBlockheight(height))
+	if len(k) == 0 {
+		// could be that we're past the last key, use the last key in that case
+		k, b = cursor.Last()
+	} else if decodeBlockheight(k) > height {
+		// the mint condition we need was defined earlier
+		k, b = cursor.Prev()
+	}
+	if len(k) == 0 {
+		return types.UnlockConditionProxy{}, fmt.Errorf("corrupt capacity plugin DB: no mint condition could be found for height %d", height)
+	}
+	var mintCondition types.UnlockConditionProxy
+	err := rivbin.Unmarshal(b, &mintCondition)
+	if err != nil {
+		return types.UnlockConditionProxy{}, fmt.Errorf("corrupt capacity plugin DB: failed to decode found mint condition: %v", err)
+	}
+	return mintCondition, nil
+}
+
+func isFarmerAuthorized(rootBucket *bolt.Bucket, farmer types.UnlockHash) (bool, error) {
+	farmerBucket := rootBucket.Bucket(bucketFarmers)
+	if farmerBucket == nil {
+		return false, errors.New("corrupt capacity plugin DB: farmer bucket does not exist")
+	}
+	history, err := getFarmerAuthorizations(farmerBucket, farmer)
+	if err != nil {
+		return false, err
+	}
+	if len(history) == 0 {
+		return false, nil
+	}
+	return history[len(history)-1].Authorized, nil
+}
+
+func getFarmerAuthorizations(farmerBucket *bolt.Bucket, farmer types.UnlockHash) ([]farmerAuthorization, error) {
+	b := farmerBucket.Get(encodeAddress(farmer))
+	if len(b) == 0 {
+		return nil, nil
+	}
+	var history []farmerAuthorization
+	err := rivbin.Unmarshal(b, &history)
+	if err != nil {
+		return nil, fmt.Errorf("corrupt capacity plugin DB: failed to decode authorizations of farmer %s: %v", farmer.String(), err)
+	}
+	return history, nil
+}
+
+func putFarmerAuthorizations(farmerBucket *bolt.Bucket, farmer types.UnlockHash, history []farmerAuthorization) error {
+	key := encodeAddress(farmer)
+	if len(history) == 0 {
+		return farmerBucket.Delete(key)
+	}
+	b, err := rivbin.Marshal(history)
+	if err != nil {
+		return fmt.Errorf("failed to marshal authorizations of farmer %s: %v", farmer.String(), err)
+	}
+	err = farmerBucket.Put(key, b)
+	if err != nil {
+		return fmt.Errorf("failed to store authorizations of farmer %s: %v", farmer.String(), err)
+	}
+	return nil
+}
+
+func pushFarmerAuthorization(farmerBucket *bolt.Bucket, farmer types.UnlockHash, fa farmerAuthorization) error {
+	history, err := getFarmerAuthorizations(farmerBucket, farmer)
+	if err != nil {
+		return err
+	}
+	return putFarmerAuthorizations(farmerBucket, farmer, append(history, fa))
+}
+
+func popFarmerAuthorization(farmerBucket *bolt.Bucket, farmer types.UnlockHash) error {
+	history, err := getFarmerAuthorizations(farmerBucket, farmer)
+	if err != nil {
+		return err
+	}
+	if len(history) == 0 {
+		return fmt.Errorf("corrupt capacity plugin DB: no authorizations to revert for farmer %s", farmer.String())
+	}
+	return putFarmerAuthorizations(farmerBucket, farmer, history[:len(history)-1])
+}
+
+func getNodeHistory(rootBucket *bolt.Bucket, node types.PublicKey) ([]ctypes.NodeCapacity, error) {
+	nodeBucket := rootBucket.Bucket(bucketNodes)
+	if nodeBucket == nil {
+		return nil, errors.New("corrupt capacity plugin DB: node bucket does not exist")
+	}
+	return getNodeHistoryFromBucket(nodeBucket, node)
+}
+
+func getNodeHistoryFromBucket(nodeBucket *bolt.Bucket, node types.PublicKey) ([]ctypes.NodeCapacity, error) {
+	b := nodeBucket.Get(encodePublicKey(node))
+	if len(b) == 0 {
+		return nil, nil
+	}
+	var history []ctypes.NodeCapacity
+	err := rivbin.Unmarshal(b, &history)
+	if err != nil {
+		return nil, fmt.Errorf("corrupt capacity plugin DB: failed to decode capacity history of node %s: %v", node.String(), err)
+	}
+	return history, nil
+}
+
+func putNodeHistory(nodeBucket *bolt.Bucket, node types.PublicKey, history []ctypes.NodeCapacity) error {
+	key := encodePublicKey(node)
+	if len(history) == 0 {
+		return nodeBucket.Delete(key)
+	}
+	b, err := rivbin.Marshal(history)
+	if err != nil {
+		return fmt.Errorf("failed to marshal capacity history of node %s: %v", node.String(), err)
+	}
+	err = nodeBucket.Put(key, b)
+	if err != nil {
+		return fmt.Errorf("failed to store capacity history of node %s: %v", node.String(), err)
+	}
+	return nil
+}
+
+// nodeCapacityAt returns the last capacity registered at or before the given height,
+// the history is expected to be sorted by height in ascending order.
+func nodeCapacityAt(history []ctypes.NodeCapacity, height types.BlockHeight) (ctypes.NodeCapacity, bool) {
+	for idx := len(history) - 1; idx >= 0; idx-- {
+		if history[idx].Height <= height {
+			return history[idx], true
+		}
+	}
+	return ctypes.NodeCapacity{}, false
+}
+
+func getNodeFarmHistory(nodeFarmBucket *bolt.Bucket, node types.PublicKey) ([]ctypes.NodeFarmLink, error) {
+	b := nodeFarmBucket.Get(encodePublicKey(node))
+	if len(b) == 0 {
+		return nil, nil
+	}
+	var history []ctypes.NodeFarmLink
+	err := rivbin.Unmarshal(b, &history)
+	if err != nil {
+		return nil, fmt.Errorf("corrupt capacity plugin DB: failed to decode farm links of node %s: %v", node.String(), err)
+	}
+	return history, nil
+}
+
+func putNodeFarmHistory(nodeFarmBucket *bolt.Bucket, node types.PublicKey, history []ctypes.NodeFarmLink) error {
+	key := encodePublicKey(node)
+	if len(history) == 0 {
+		return nodeFarmBucket.Delete(key)
+	}
+	b, err := rivbin.Marshal(history)
+	if err != nil {
+		return fmt.Errorf("failed to marshal farm links of node %s: %v", node.String(), err)
+	}
+	err = nodeFarmBucket.Put(key, b)
+	if err != nil {
+		return fmt.Errorf("failed to store farm links of node %s: %v", node.String(), err)
+	}
+	return nil
+}
+
+// getNodeFarmLink returns the current farm link of the given node,
+// returning ErrNodeNotLinked if the node was never linked or was unlinked from its last farm
+func getNodeFarmLink(nodeFarmBucket *bolt.Bucket, node types.PublicKey) (ctypes.NodeFarmLink, error) {
+	history, err := getNodeFarmHistory(nodeFarmBucket, node)
+	if err != nil {
+		return ctypes.NodeFarmLink{}, err
+	}
+	if len(history) == 0 || history[len(history)-1].FarmID == 0 {
+		return ctypes.NodeFarmLink{}, ctypes.ErrNodeNotLinked
+	}
+	return history[len(history)-1], nil
+}
+
+// nodeFarmLinkAt returns the last farm link made at or before the given height,
+// the history is expected to be sorted by height in ascending order.
+func nodeFarmLinkAt(history []ctypes.NodeFarmLink, height types.BlockHeight) (ctypes.NodeFarmLink, bool) {
+	for idx := len(history) - 1; idx >= 0; idx-- {
+		if history[idx].Height <= height {
+			return history[idx], true
+		}
+	}
+	return ctypes.NodeFarmLink{}, false
+}
+
+// farmsWithCapacityAt returns the subset of the given farms that, at the given height,
+// had at least one linked node with non-zero capacity registered by that farm
+func farmsWithCapacityAt(rootBucket *bolt.Bucket, height types.BlockHeight, farms map[ctypes.FarmID]struct{}) (map[ctypes.FarmID]struct{}, error) {
+	nodeBucket := rootBucket.Bucket(bucketNodes)
+	if nodeBucket == nil {
+		return nil, errors.New("corrupt capacity plugin DB: node bucket does not exist")
+	}
+	nodeFarmBucket := rootBucket.Bucket(bucketNodeFarms)
+	if nodeFarmBucket == nil {
+		return nil, errors.New("corrupt capacity plugin DB: node farm bucket does not exist")
+	}
+	result := make(map[ctypes.FarmID]struct{}, len(farms))
+	cursor := nodeBucket.Cursor()
+	for k, v := cursor.First(); len(k) != 0 && len(result) < len(farms); k, v = cursor.Next() {
+		var history []ctypes.NodeCapacity
+		err := rivbin.Unmarshal(v, &history)
+		if err != nil {
+			return nil, fmt.Errorf("corrupt capacity plugin DB: failed to decode capacity history: %v", err)
+		}
+		nc, ok := nodeCapacityAt(history, height)
+		if !ok || nc.Capacity.IsZero() {
+			continue
+		}
+		if _, ok = farms[nc.FarmID]; !ok {
+			continue
+		}
+		// the capacity only counts if the node was still linked to that farm
+		links, err := getNodeFarmHistory(nodeFarmBucket, nc.Node)
+		if err != nil {
+			return nil, err
+		}
+		if link, ok := nodeFarmLinkAt(links, height); ok && link.FarmID == nc.FarmID {
+			result[nc.FarmID] = struct{}{}
+		}
+	}
+	return result, nil
+}
+
+func getFarmNodesFromBucket(farmNodesBucket *bolt.Bucket, id ctypes.FarmID) ([]types.PublicKey, error) {
+	b := farmNodesBucket.Get(encodeFarmID(id))
+	if len(b) == 0 {
+		return nil, nil
+	}
+	var nodes []types.PublicKey
+	err := rivbin.Unmarshal(b, &nodes)
+	if err != nil {
+		return nil, fmt.Errorf("corrupt capacity plugin DB: failed to decode nodes of farm %s: %v", id.String(), err)
+	}
+	return nodes, nil
+}
+
+func putFarmNodes(farmNodesBucket *bolt.Bucket, id ctypes.FarmID, nodes []types.PublicKey) error {
+	key := encodeFarmID(id)
+	if len(nodes) == 0 {
+		return farmNodesBucket.Delete(key)
+	}
+	b, err := rivbin.Marshal(nodes)
+	if err != nil {
+		return fmt.Errorf("failed to marshal nodes of farm %s: %v", id.String(), err)
+	}
+	err = farmNodesBucket.Put(key, b)
+	if err != nil {
+		return fmt.Errorf("failed to store nodes of farm %s: %v", id.String(), err)
+	}
+	return nil
+}
+
+// addFarmNode links the given node to the given farm
+func addFarmNode(farmNodesBucket *bolt.Bucket, id ctypes.FarmID, node types.PublicKey) error {
+	nodes, err := getFarmNodesFromBucket(farmNodesBucket, id)
+	if err != nil {
+		return err
+	}
+	nodes, ok := insertSortedNode(nodes, node)
+	if !ok {
+		return nil // already linked
+	}
+	return putFarmNodes(farmNodesBucket, id, nodes)
+}
+
+// removeFarmNode unlinks the given node from the given farm
+func removeFarmNode(farmNodesBucket *bolt.Bucket, id ctypes.FarmID, node types.PublicKey) error {
+	nodes, err := getFarmNodesFromBucket(farmNodesBucket, id)
+	if err != nil {
+		return err
+	}
+	nodes, ok := deleteSortedNode(nodes, node)
+	if !ok {
+		return fmt.Errorf("corrupt capacity plugin DB: node %s is not linked to farm %s", node.String(), id.String())
+	}
+	return putFarmNodes(farmNodesBucket, id, nodes)
+}
+
+// insertSortedNode inserts the given node in the sorted list of nodes,
+// keeping the list sorted, such that the order does not depend on reverts.
+// False is returned if the node was already part of the list.
+func insertSortedNode(nodes []types.PublicKey, node types.PublicKey) ([]types.PublicKey, bool) {
+	key := node.String()
+	idx := sort.Search(len(nodes), func(i int) bool {
+		return nodes[i].String() >= key
+	})
+	if idx < len(nodes) && nodes[idx].String() == key {
+		return nodes, false
+	}
+	nodes = append(nodes, types.PublicKey{})
+	copy(nodes[idx+1:], nodes[idx:])
+	nodes[idx] = node
+	return nodes, true
+}
+
+// deleteSortedNode deletes the given node from the sorted list of nodes,
+// returning false if the node was not part of the list.
+func deleteSortedNode(nodes []types.PublicKey, node types.PublicKey) ([]types.PublicKey, bool) {
+	key := node.String()
+	for idx := range nodes {
+		if nodes[idx].String() == key {
+			return append(nodes[:idx], nodes[idx+1:]...), true
+		}
+	}
+	return nodes, false
+}
+
+func getFarmRewardsFromBucket(farmRewardsBucket *bolt.Bucket, id ctypes.FarmID) ([]ctypes.FarmReward, error) {
+	b := farmRewardsBucket.Get(encodeFarmID(id))
+	if len(b) == 0 {
+		return nil, nil
+	}
+	var rewards []ctypes.FarmReward
+	err := rivbin.Unmarshal(b, &rewards)
+	if err != nil {
+		return nil, fmt.Errorf("corrupt capacity plugin DB: failed to decode rewards of farm %s: %v", id.String(), err)
+	}
+	return rewards, nil
+}
+
+func putFarmRewards(farmRewardsBucket *bolt.Bucket, id ctypes.FarmID, rewards []ctypes.FarmReward) error {
+	key := encodeFarmID(id)
+	if len(rewards) == 0 {
+		return farmRewardsBucket.Delete(key)
+	}
+	b, err := rivbin.Marshal(rewards)
+	if err != nil {
+		return fmt.Errorf("failed to marshal rewards of farm %s: %v", id.String(), err)
+	}
+	err = farmRewardsBucket.Put(key, b)
+	if err != nil {
+		return fmt.Errorf("failed to store rewards of farm %s: %v", id.String(), err)
+	}
+	return nil
+}
+
+func pushFarmReward(farmRewardsBucket *bolt.Bucket, id ctypes.FarmID, reward ctypes.FarmReward) error {
+	rewards, err := getFarmRewardsFromBucket(farmRewardsBucket, id)
+	if err != nil {
+		return err
+	}
+	return putFarmRewards(farmRewardsBucket, id, append(rewards, reward))
+}
+
+func popFarmReward(farmRewardsBucket *bolt.Bucket, id ctypes.FarmID) error {
+	rewards, err := getFarmRewardsFromBucket(farmRewardsBucket, id)
+	if err != nil {
+		return err
+	}
+	if len(rewards) == 0 {
+		return fmt.Errorf("corrupt capacity plugin DB: no rewards to revert for farm %s", id.String())
+	}
+	return putFarmRewards(farmRewardsBucket, id, rewards[:len(rewards)-1])
+}
+
+func getFarm(farmBucket *bolt.Bucket, id ctypes.FarmID) (ctypes.FarmRecord, error) {
+	b := farmBucket.Get(encodeFarmID(id))
+	if len(b) == 0 {
+		return ctypes.FarmRecord{}, ctypes.ErrFarmNotFound
+	}
+	var record ctypes.FarmRecord
+	err := rivbin.Unmarshal(b, &record)
+	if err != nil {
+		return ctypes.FarmRecord{}, fmt.Errorf("corrupt capacity plugin DB: failed to decode record of farm %s: %v", id.String(), err)
+	}
+	return record, nil
+}
+
+func putFarm(farmBucket *bolt.Bucket, record ctypes.FarmRecord) error {
+	b, err := rivbin.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal record of farm %s: %v", record.ID.String(), err)
+	}
+	err = farmBucket.Put(encodeFarmID(record.ID), b)
+	if err != nil {
+		return fmt.Errorf("failed to store record of farm %s: %v", record.ID.String(), err)
+	}
+	return nil
+}
+
+func getFarmIDForName(rootBucket *bolt.Bucket, name string) (ctypes.FarmID, error) {
+	farmNameBucket := rootBucket.Bucket(bucketFarmNames)
+	if farmNameBucket == nil {
+		return 0, errors.New("corrupt capacity plugin DB: farm name bucket does not exist")
+	}
+	b := farmNameBucket.Get([]byte(name))
+	if len(b) == 0 {
+		return 0, ctypes.ErrFarmNotFound
+	}
+	var id ctypes.FarmID
+	err := rivbin.Unmarshal(b, &id)
+	if err != nil {
+		return 0, fmt.Errorf("corrupt capacity plugin DB: failed to decode farm ID for name %q: %v", name, err)
+	}
+	return id, nil
+}
+
+func putFarmName(farmNameBucket *bolt.Bucket, name string, id ctypes.FarmID) error {
+	b, err := rivbin.Marshal(id)
+	if err != nil {
+		return fmt.Errorf("failed to marshal ID of farm %q: %v", name, err)
+	}
+	err = farmNameBucket.Put([]byte(name), b)
+	if err != nil {
+		return fmt.Errorf("failed to store name %q of farm %s: %v", name, id.String(), err)
+	}
+	return nil
+}
+
+// validateFarmNameAvailable validates that no farm is registered yet using the given name
+func validateFarmNameAvailable(rootBucket *bolt.Bucket, name string) error {
+	_, err := getFarmIDForName(rootBucket, name)
+	switch err {
+	case nil:
+		return ctypes.ErrFarmNameAlreadyRegistered
+	case ctypes.ErrFarmNotFound:
+		return nil
+	default:
+		return err
+	}
+}
+
+// encodeFarmID encodes the given farm ID as a sortable key
+func encodeFarmID(id ctypes.FarmID) []byte {
+	key := make([]byte, 4)
+	binary.BigEndian.PutUint32(key[:], uint32(id))
+	return key
+}
+
+// encodeBlockheight encodes the given blockheight as a sortable key
+func encodeBlockheight(height types.BlockHeight) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key[:], uint64(height))
+	return key
+}
+
+// decodeBlockheight decodes the given sortable key as a blockheight
+func decodeBlockheight(key []byte) types.BlockHeight {
+	return types.BlockHeight(binary.BigEndian.Uint64(key))
+}
+
+// encodeAddress encodes the given address as a key
+func encodeAddress(address types.UnlockHash) []byte {
+	key := make([]byte, 1+crypto.HashSize)
+	key[0] = byte(address.Type)
+	copy(key[1:], address.Hash[:])
+	return key
+}
+
+// encodePublicKey encodes the given public key as a key
+func encodePublicKey(pk types.PublicKey) []byte {
+	return []byte(pk.String())
+}
@@ -0,0 +1,683 @@
+package capacity
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/persist"
+	"github.com/threefoldtech/rivine/types"
+
+	ctypes "github.com/threefoldfoundation/tfchain/extensions/capacity/types"
+	tftypes "github.com/threefoldfoundation/tfchain/pkg/types"
+
+	bolt "github.com/rivine/bbolt"
+)
+
+var testPluginBucket = []byte("capacity")
+
+// testPluginStorage provides a read-only view on the bucket of the plugin
+type testPluginStorage struct {
+	db *bolt.DB
+}
+
+func (s testPluginStorage) View(callback func(bucket *bolt.Bucket) error) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		return callback(tx.Bucket(testPluginBucket))
+	})
+}
+
+func (s testPluginStorage) Close() error { return nil }
+
+// testFarmer is a (personal) farmer address, together with the key used to sign for it
+type testFarmer struct {
+	sk      crypto.SecretKey
+	pk      crypto.PublicKey
+	address types.UnlockHash
+}
+
+// pluginTester applies and reverts blocks to a capacity plugin using a temporary bolt DB,
+// keeping track of the coin outputs that can be used to pay the fees of the capacity transactions
+type pluginTester struct {
+	t      *testing.T
+	plugin *Plugin
+	db     *bolt.DB
+
+	minter testFarmer
+
+	outputs    map[types.CoinOutputID]types.CoinOutput
+	outputSeed uint64
+	blocks     []modules.ConsensusBlock
+}
+
+func newPluginTester(t *testing.T) (*pluginTester, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "tfchain-capacity")
+	if err != nil {
+		t.Fatal("failed to create temp dir:", err)
+	}
+	db, err := bolt.Open(filepath.Join(dir, "consensus.db"), 0600, nil)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal("failed to open DB:", err)
+	}
+
+	pt := &pluginTester{
+		t:       t,
+		db:      db,
+		outputs: make(map[types.CoinOutputID]types.CoinOutput),
+	}
+	pt.minter = pt.newFarmer()
+	pt.plugin = NewPlugin(types.NewCondition(types.NewUnlockHashCondition(pt.minter.address)), tftypes.TransactionVersionMinterDefinition)
+	err = db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucket(testPluginBucket)
+		if err != nil {
+			return err
+		}
+		_, err = pt.plugin.InitPlugin(nil, bucket, testPluginStorage{db: db}, nil)
+		return err
+	})
+	if err != nil {
+		t.Fatal("failed to init plugin:", err)
+	}
+
+	return pt, func() {
+		for _, version := range []types.TransactionVersion{
+			ctypes.TransactionVersionFarmerAuthorization,
+			ctypes.TransactionVersionCapacityRegistration,
+			ctypes.TransactionVersionFarmRegistration,
+			ctypes.TransactionVersionFarmUpdate,
+			ctypes.TransactionVersionNodeLink,
+			ctypes.TransactionVersionFarmingReward,
+		} {
+			types.RegisterTransactionVersion(version, nil)
+		}
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+// newFarmer returns a new (random) personal address
+func (pt *pluginTester) newFarmer() testFarmer {
+	sk, pk := crypto.GenerateKeyPair()
+	uh, err := types.NewPubKeyUnlockHash(types.Ed25519PublicKey(pk))
+	if err != nil {
+		pt.t.Fatal(err)
+	}
+	return testFarmer{sk: sk, pk: pk, address: uh}
+}
+
+// testNode is a node public key, together with the private key of the node
+type testNode struct {
+	sk crypto.SecretKey
+	pk types.PublicKey
+}
+
+// newNode returns a new (random) node
+func newNode() testNode {
+	sk, pk := crypto.GenerateKeyPair()
+	return testNode{sk: sk, pk: types.Ed25519PublicKey(pk)}
+}
+
+// height returns the height of the next block
+func (pt *pluginTester) height() types.BlockHeight {
+	return types.BlockHeight(len(pt.blocks))
+}
+
+// validate the given transaction, as if it is part of the next block,
+// using the version-specific validators of the plugin
+func (pt *pluginTester) validate(txn types.Transaction) error {
+	ctx := types.TransactionValidationContext{
+		ValidationContext: types.ValidationContext{
+			Confirmed:   true,
+			BlockHeight: pt.height(),
+		},
+		MinimumMinerFee: types.NewCurrency64(1),
+	}
+	cTxn := modules.ConsensusTransaction{
+		Transaction:      txn,
+		BlockHeight:      ctx.BlockHeight,
+		SpentCoinOutputs: pt.spentCoinOutputs(txn),
+	}
+	return pt.db.View(func(tx *bolt.Tx) error {
+		bucket := persist.NewLazyBoltBucket(func() (*bolt.Bucket, error) {
+			return tx.Bucket(testPluginBucket), nil
+		})
+		for _, validator := range pt.plugin.TransactionValidatorVersionFunctionMapping()[txn.Version] {
+			if err := validator(cTxn, ctx, bucket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// validateError ensures the given transaction is invalid, failing with the given error if not nil
+func (pt *pluginTester) validateError(description string, txn types.Transaction, expected error) {
+	pt.t.Helper()
+	err := pt.validate(txn)
+	if err == nil {
+		pt.t.Errorf("expected %s to be invalid", description)
+	} else if expected != nil && err != expected {
+		pt.t.Errorf("expected %s to be invalid with error %q, not %q", description, expected, err)
+	}
+}
+
+func (pt *pluginTester) spentCoinOutputs(txns ...types.Transaction) map[types.CoinOutputID]types.CoinOutput {
+	spent := make(map[types.CoinOutputID]types.CoinOutput)
+	for _, txn := range txns {
+		for _, ci := range txn.CoinInputs {
+			if co, ok := pt.outputs[ci.ParentID]; ok {
+				spent[ci.ParentID] = co
+			}
+		}
+	}
+	return spent
+}
+
+// applyBlock validates the given transactions, and applies them as the next block
+func (pt *pluginTester) applyBlock(txns ...types.Transaction) {
+	pt.t.Helper()
+	for idx, txn := range txns {
+		if err := pt.validate(txn); err != nil {
+			pt.t.Fatalf("transaction #%d of block %d is invalid: %v", idx, pt.height(), err)
+		}
+	}
+	block := modules.ConsensusBlock{
+		Block:            types.Block{Transactions: txns},
+		Height:           pt.height(),
+		SpentCoinOutputs: pt.spentCoinOutputs(txns...),
+	}
+	err := pt.db.Update(func(tx *bolt.Tx) error {
+		bucket := persist.NewLazyBoltBucket(func() (*bolt.Bucket, error) {
+			return tx.Bucket(testPluginBucket), nil
+		})
+		return pt.plugin.ApplyBlock(block, bucket)
+	})
+	if err != nil {
+		pt.t.Fatalf("failed to apply block %d: %v", block.Height, err)
+	}
+	for id := range block.SpentCoinOutputs {
+		delete(pt.outputs, id)
+	}
+	pt.blocks = append(pt.blocks, block)
+}
+
+// revertBlock reverts the last applied block
+func (pt *pluginTester) revertBlock() {
+	pt.t.Helper()
+	block := pt.blocks[len(pt.blocks)-1]
+	err := pt.db.Update(func(tx *bolt.Tx) error {
+		bucket := persist.NewLazyBoltBucket(func() (*bolt.Bucket, error) {
+			return tx.Bucket(testPluginBucket), nil
+		})
+		return pt.plugin.RevertBlock(block, bucket)
+	})
+	if err != nil {
+		pt.t.Fatalf("failed to revert block %d: %v", block.Height, err)
+	}
+	for id, co := range block.SpentCoinOutputs {
+		pt.outputs[id] = co
+	}
+	pt.blocks = pt.blocks[:len(pt.blocks)-1]
+}
+
+// feeInput creates a new coin output of the given farmer, worth exactly the miner fee,
+// returning the coin input spending it
+func (pt *pluginTester) feeInput(farmer testFarmer) types.CoinInput {
+	var id types.CoinOutputID
+	pt.outputSeed++
+	binary.LittleEndian.PutUint64(id[:], pt.outputSeed)
+	pt.outputs[id] = types.CoinOutput{
+		Value:     types.NewCurrency64(1),
+		Condition: types.NewCondition(types.NewUnlockHashCondition(farmer.address)),
+	}
+	return types.CoinInput{
+		ParentID:    id,
+		Fulfillment: types.NewFulfillment(types.NewSingleSignatureFulfillment(types.Ed25519PublicKey(farmer.pk))),
+	}
+}
+
+// sign signs the extension of the given transaction using the given keys,
+// each (single signature) fulfillment is only signed by the key matching its public key
+func (pt *pluginTester) sign(txn *types.Transaction, keys ...crypto.SecretKey) {
+	pt.t.Helper()
+	err := txn.SignExtension(func(fulfillment *types.UnlockFulfillmentProxy, condition types.UnlockConditionProxy, extraObjects ...interface{}) error {
+		ssf, ok := fulfillment.Fulfillment.(*types.SingleSignatureFulfillment)
+		if !ok {
+			return nil
+		}
+		for _, sk := range keys {
+			pk := sk.PublicKey()
+			if !bytes.Equal(ssf.PublicKey.Key, pk[:]) {
+				continue
+			}
+			return fulfillment.Sign(types.FulfillmentSignContext{
+				ExtraObjects: extraObjects,
+				Transaction:  *txn,
+				Key:          sk,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		pt.t.Fatal("failed to sign transaction:", err)
+	}
+}
+
+// newAuthorizationTx creates a farmer authorization transaction, signed by the given signer,
+// after applying the given modifications
+func (pt *pluginTester) newAuthorizationTx(signer testFarmer, auth, deauth []types.UnlockHash, modify ...func(*ctypes.FarmerAuthorizationTransaction)) types.Transaction {
+	pt.t.Helper()
+	fatx := ctypes.FarmerAuthorizationTransaction{
+		Nonce:           types.RandomTransactionNonce(),
+		AuthAddresses:   auth,
+		DeauthAddresses: deauth,
+		MintFulfillment: types.NewFulfillment(types.NewSingleSignatureFulfillment(types.Ed25519PublicKey(signer.pk))),
+		MinerFees:       []types.Currency{types.NewCurrency64(1)},
+	}
+	for _, fn := range modify {
+		fn(&fatx)
+	}
+	txn := fatx.Transaction()
+	pt.sign(&txn, signer.sk)
+	return txn
+}
+
+// newRegistrationTx creates a capacity registration transaction for the given farm,
+// signed by the given manager and node, after applying the given modifications
+func (pt *pluginTester) newRegistrationTx(manager testFarmer, id ctypes.FarmID, node testNode, capacity ctypes.CapacityUnits, modify ...func(*ctypes.CapacityRegistrationTransaction)) types.Transaction {
+	pt.t.Helper()
+	crtx := ctypes.CapacityRegistrationTransaction{
+		FarmID:             id,
+		Node:               node.pk,
+		Capacity:           capacity,
+		Manager:            manager.address,
+		ManagerFulfillment: types.NewFulfillment(types.NewSingleSignatureFulfillment(types.Ed25519PublicKey(manager.pk))),
+		CoinInputs:         []types.CoinInput{pt.feeInput(manager)},
+		MinerFees:          []types.Currency{types.NewCurrency64(1)},
+	}
+	for _, fn := range modify {
+		fn(&crtx)
+	}
+	txn := crtx.Transaction()
+	pt.sign(&txn, manager.sk, node.sk)
+	return txn
+}
+
+// newFarmRegistrationTx creates a farm registration transaction, owned and signed by the given farmer,
+// and managed by the given managers
+func (pt *pluginTester) newFarmRegistrationTx(farmer testFarmer, name string, managers ...types.UnlockHash) types.Transaction {
+	pt.t.Helper()
+	frtx := ctypes.FarmRegistrationTransaction{
+		Name:             name,
+		Owner:            types.NewCondition(types.NewUnlockHashCondition(farmer.address)),
+		Managers:         managers,
+		PayoutAddress:    farmer.address,
+		OwnerFulfillment: types.NewFulfillment(types.NewSingleSignatureFulfillment(types.Ed25519PublicKey(farmer.pk))),
+		CoinInputs:       []types.CoinInput{pt.feeInput(farmer)},
+		MinerFees:        []types.Currency{types.NewCurrency64(1)},
+	}
+	txn := frtx.Transaction()
+	pt.sign(&txn, farmer.sk)
+	return txn
+}
+
+// newNodeLinkTx creates a node link transaction, linking the given node to the given farm,
+// signed by the given manager and node
+func (pt *pluginTester) newNodeLinkTx(manager testFarmer, id ctypes.FarmID, node testNode) types.Transaction {
+	pt.t.Helper()
+	nltx := ctypes.NodeLinkTransaction{
+		Node:               node.pk,
+		FarmID:             id,
+		Manager:            manager.address,
+		ManagerFulfillment: types.NewFulfillment(types.NewSingleSignatureFulfillment(types.Ed25519PublicKey(manager.pk))),
+		CoinInputs:         []types.CoinInput{pt.feeInput(manager)},
+		MinerFees:          []types.Currency{types.NewCurrency64(1)},
+	}
+	txn := nltx.Transaction()
+	pt.sign(&txn, manager.sk, node.sk)
+	return txn
+}
+
+// newFarmingRewardTx creates a farming reward transaction for the given snapshot height,
+// paying out to the given farms, using the address of the given farmer as payout address, signed by the minter
+func (pt *pluginTester) newFarmingRewardTx(snapshot types.BlockHeight, payouts map[ctypes.FarmID]testFarmer) types.Transaction {
+	pt.t.Helper()
+	frtx := ctypes.FarmingRewardTransaction{
+		Nonce:           types.RandomTransactionNonce(),
+		SnapshotHeight:  snapshot,
+		MintFulfillment: types.NewFulfillment(types.NewSingleSignatureFulfillment(types.Ed25519PublicKey(pt.minter.pk))),
+		MinerFees:       []types.Currency{types.NewCurrency64(1)},
+	}
+	for id := ctypes.FarmID(1); len(frtx.Payouts) < len(payouts); id++ {
+		if farmer, ok := payouts[id]; ok {
+			frtx.Payouts = append(frtx.Payouts, ctypes.FarmPayout{
+				FarmID:        id,
+				Value:         types.NewCurrency64(100),
+				PayoutAddress: farmer.address,
+			})
+		}
+	}
+	txn := frtx.Transaction()
+	pt.sign(&txn, pt.minter.sk)
+	return txn
+}
+
+// registerFarm applies a block registering a farm, owned by the given farmer
+// and managed by the given managers, returning the ID assigned to it
+func (pt *pluginTester) registerFarm(farmer testFarmer, name string, managers ...types.UnlockHash) ctypes.FarmID {
+	pt.t.Helper()
+	pt.applyBlock(pt.newFarmRegistrationTx(farmer, name, managers...))
+	record, err := pt.plugin.GetFarmForName(name)
+	if err != nil {
+		pt.t.Fatalf("failed to get registered farm %q: %v", name, err)
+	}
+	return record.ID
+}
+
+func (pt *pluginTester) expectAuthorized(farmer testFarmer, expected bool) {
+	pt.t.Helper()
+	authorized, err := pt.plugin.IsFarmerAuthorized(farmer.address)
+	if err != nil {
+		pt.t.Fatal("failed to get farmer authorization:", err)
+	}
+	if authorized != expected {
+		pt.t.Errorf("expected farmer %s to be authorized: %v, at height %d", farmer.address.String(), expected, pt.height())
+	}
+}
+
+// expectNode ensures the given node is (currently) registered by the given farm with the given capacity,
+// and that it is the only node for which that farm registered capacity
+func (pt *pluginTester) expectNode(node testNode, id ctypes.FarmID, capacity ctypes.CapacityUnits) {
+	pt.t.Helper()
+	nc, err := pt.plugin.GetNodeCapacity(node.pk)
+	if err != nil {
+		pt.t.Fatal("failed to get node capacity:", err)
+	}
+	if nc.FarmID != id || nc.Capacity != capacity {
+		pt.t.Errorf("expected node to be registered by farm %s with capacity %s, not by farm %s with capacity %s",
+			id.String(), capacity.String(), nc.FarmID.String(), nc.Capacity.String())
+	}
+	fc, err := pt.plugin.GetFarmCapacity(id)
+	if err != nil {
+		pt.t.Fatal("failed to get farm capacity:", err)
+	}
+	if len(fc.Nodes) != 1 || fc.Nodes[0].String() != node.pk.String() || fc.Capacity != capacity {
+		pt.t.Errorf("expected farm %s to have only the node with capacity %s, not %v with capacity %s",
+			id.String(), capacity.String(), fc.Nodes, fc.Capacity.String())
+	}
+}
+
+// expectNoNodes ensures the given farm has no capacity registered for any of its (linked) nodes
+func (pt *pluginTester) expectNoNodes(id ctypes.FarmID) {
+	pt.t.Helper()
+	fc, err := pt.plugin.GetFarmCapacity(id)
+	if err != nil {
+		pt.t.Fatal("failed to get farm capacity:", err)
+	}
+	if len(fc.Nodes) != 0 || !fc.Capacity.IsZero() {
+		pt.t.Errorf("expected farm %s to have no nodes, not %v with capacity %s", id.String(), fc.Nodes, fc.Capacity.String())
+	}
+}
+
+func TestPluginFarmerAuthorization(t *testing.T) {
+	pt, cleanup := newPluginTester(t)
+	defer cleanup()
+
+	alice, bob := pt.newFarmer(), pt.newFarmer()
+	pt.validateError("authorization signed by a non-minter",
+		pt.newAuthorizationTx(alice, []types.UnlockHash{alice.address}, nil), nil)
+	pt.validateError("authorization with nil nonce",
+		pt.newAuthorizationTx(pt.minter, []types.UnlockHash{alice.address}, nil, func(fatx *ctypes.FarmerAuthorizationTransaction) {
+			fatx.Nonce = types.TransactionNonce{}
+		}), nil)
+	pt.validateError("authorization without addresses", pt.newAuthorizationTx(pt.minter, nil, nil), nil)
+
+	pt.applyBlock(pt.newAuthorizationTx(pt.minter, []types.UnlockHash{alice.address, bob.address}, nil))
+	pt.expectAuthorized(alice, true)
+	pt.expectAuthorized(bob, true)
+	id := pt.registerFarm(bob, "bobsfarm", bob.address)
+	node := newNode()
+	pt.applyBlock(pt.newNodeLinkTx(bob, id, node))
+
+	pt.applyBlock(pt.newAuthorizationTx(pt.minter, nil, []types.UnlockHash{bob.address}))
+	pt.expectAuthorized(alice, true)
+	pt.expectAuthorized(bob, false)
+
+	// the farms of deauthorized farmers can no longer register capacity,
+	// and deauthorized farmers can no longer register farms
+	pt.validateError("capacity registration for the farm of a deauthorized farmer",
+		pt.newRegistrationTx(bob, id, node, ctypes.CapacityUnits{CRU: 1}), ctypes.ErrFarmerNotAuthorized)
+	pt.validateError("farm registration of a deauthorized farmer",
+		pt.newFarmRegistrationTx(bob, "bobsotherfarm"), ctypes.ErrFarmerNotAuthorized)
+
+	pt.revertBlock()
+	pt.expectAuthorized(bob, true)
+	pt.applyBlock(pt.newRegistrationTx(bob, id, node, ctypes.CapacityUnits{CRU: 1}))
+	pt.expectNode(node, id, ctypes.CapacityUnits{CRU: 1})
+	pt.revertBlock()
+	pt.revertBlock()
+	pt.revertBlock()
+	pt.revertBlock()
+	pt.expectAuthorized(alice, false)
+	pt.expectAuthorized(bob, false)
+}
+
+func TestPluginCapacityRegistration(t *testing.T) {
+	pt, cleanup := newPluginTester(t)
+	defer cleanup()
+
+	alice, bob, manager := pt.newFarmer(), pt.newFarmer(), pt.newFarmer()
+	pt.applyBlock(pt.newAuthorizationTx(pt.minter, []types.UnlockHash{alice.address, bob.address}, nil))
+	id := pt.registerFarm(alice, "alicesfarm", manager.address)
+	node := newNode()
+	pt.applyBlock(pt.newNodeLinkTx(manager, id, node))
+	pt.expectNoNodes(id)
+	if _, err := pt.plugin.GetFarmCapacity(id + 1); err != ctypes.ErrFarmNotFound {
+		t.Errorf("expected capacity of an unknown farm to be unknown: %v", err)
+	}
+
+	capacity := ctypes.CapacityUnits{CRU: 4, MRU: 16, HRU: 1000, SRU: 250}
+	pt.validateError("capacity registration for an unknown farm",
+		pt.newRegistrationTx(manager, id+1, node, capacity), ctypes.ErrFarmNotFound)
+	pt.validateError("capacity registration by the farm owner, which is not a manager",
+		pt.newRegistrationTx(alice, id, node, capacity), ctypes.ErrNotAFarmManager)
+	pt.validateError("capacity registration by another farmer",
+		pt.newRegistrationTx(bob, id, node, capacity), ctypes.ErrNotAFarmManager)
+	pt.validateError("capacity registration fulfilled by another farmer",
+		pt.newRegistrationTx(manager, id, node, capacity, func(crtx *ctypes.CapacityRegistrationTransaction) {
+			crtx.ManagerFulfillment = types.NewFulfillment(types.NewSingleSignatureFulfillment(types.Ed25519PublicKey(bob.pk)))
+		}), nil)
+	pt.validateError("capacity registration of an invalid node",
+		pt.newRegistrationTx(manager, id, testNode{}, capacity), ctypes.ErrInvalidNodePublicKey)
+	pt.validateError("capacity registration of an unlinked node",
+		pt.newRegistrationTx(manager, id, newNode(), capacity), ctypes.ErrNodeNotLinked)
+	pt.validateError("capacity registration not signed by the node",
+		pt.newRegistrationTx(manager, id, testNode{pk: node.pk}, capacity), nil)
+	pt.validateError("capacity registration without miner fee",
+		pt.newRegistrationTx(manager, id, node, capacity, func(crtx *ctypes.CapacityRegistrationTransaction) {
+			crtx.MinerFees[0] = types.ZeroCurrency
+		}), types.ErrTooSmallMinerFee)
+
+	pt.applyBlock(pt.newRegistrationTx(manager, id, node, capacity))
+	registeredAt := pt.height() - 1
+	pt.expectNode(node, id, capacity)
+
+	// a manager can update the capacity of a node of the farm
+	updated := ctypes.CapacityUnits{CRU: 8, MRU: 32, HRU: 1000, SRU: 250}
+	pt.applyBlock(pt.newRegistrationTx(manager, id, node, updated))
+	pt.expectNode(node, id, updated)
+	nc, err := pt.plugin.GetNodeCapacityAt(node.pk, registeredAt)
+	if err != nil {
+		t.Fatal("failed to get node capacity at registration height:", err)
+	}
+	if nc.Capacity != capacity {
+		t.Errorf("expected capacity %s at height %d, not %s", capacity.String(), registeredAt, nc.Capacity.String())
+	}
+	if _, err = pt.plugin.GetNodeCapacityAt(node.pk, registeredAt-1); err != ctypes.ErrNodeNotFound {
+		t.Errorf("expected node not to be registered before height %d: %v", registeredAt, err)
+	}
+
+	pt.revertBlock()
+	pt.expectNode(node, id, capacity)
+	pt.revertBlock()
+	pt.expectNoNodes(id)
+	if _, err = pt.plugin.GetNodeCapacity(node.pk); err != ctypes.ErrNodeNotFound {
+		t.Errorf("expected reverted node to be unknown: %v", err)
+	}
+}
+
+func TestPluginCapacityRegistrationRequiresNodeKey(t *testing.T) {
+	pt, cleanup := newPluginTester(t)
+	defer cleanup()
+
+	alice, bob := pt.newFarmer(), pt.newFarmer()
+	pt.applyBlock(pt.newAuthorizationTx(pt.minter, []types.UnlockHash{alice.address, bob.address}, nil))
+	aliceFarm := pt.registerFarm(alice, "alicesfarm", alice.address)
+	bobFarm := pt.registerFarm(bob, "bobsfarm", bob.address)
+	node := newNode()
+	capacity := ctypes.CapacityUnits{CRU: 4, MRU: 16}
+
+	// a farm cannot claim a node it doesn't have the key of,
+	// neither by linking it nor by registering its capacity
+	pt.validateError("link of a node without its key", pt.newNodeLinkTx(bob, bobFarm, testNode{pk: node.pk}), nil)
+	pt.validateError("registration of an unlinked node without its key",
+		pt.newRegistrationTx(bob, bobFarm, testNode{pk: node.pk}, capacity), ctypes.ErrNodeNotLinked)
+	pt.applyBlock(pt.newNodeLinkTx(alice, aliceFarm, node))
+	pt.validateError("registration of a node linked to another farm",
+		pt.newRegistrationTx(bob, bobFarm, node, capacity), ctypes.ErrNodeNotLinked)
+	pt.validateError("registration of a linked node without its key",
+		pt.newRegistrationTx(alice, aliceFarm, testNode{pk: node.pk}, capacity), nil)
+
+	// the farm the node is linked to can register it
+	pt.applyBlock(pt.newRegistrationTx(alice, aliceFarm, node, capacity))
+	pt.expectNode(node, aliceFarm, capacity)
+	pt.expectNoNodes(bobFarm)
+}
+
+func TestPluginNodeRelink(t *testing.T) {
+	pt, cleanup := newPluginTester(t)
+	defer cleanup()
+
+	alice, bob := pt.newFarmer(), pt.newFarmer()
+	pt.applyBlock(pt.newAuthorizationTx(pt.minter, []types.UnlockHash{alice.address, bob.address}, nil))
+	aliceFarm := pt.registerFarm(alice, "alicesfarm", alice.address)
+	bobFarm := pt.registerFarm(bob, "bobsfarm", bob.address)
+	node := newNode()
+	capacity := ctypes.CapacityUnits{CRU: 4, MRU: 16}
+	pt.applyBlock(pt.newNodeLinkTx(alice, aliceFarm, node))
+	pt.applyBlock(pt.newRegistrationTx(alice, aliceFarm, node, capacity))
+
+	// once relinked, the capacity registered by the previous farm no longer counts for either farm
+	pt.applyBlock(pt.newNodeLinkTx(bob, bobFarm, node))
+	pt.expectNoNodes(aliceFarm)
+	pt.expectNoNodes(bobFarm)
+	pt.validateError("registration by the previous farm of the node",
+		pt.newRegistrationTx(alice, aliceFarm, node, capacity), ctypes.ErrNodeNotLinked)
+
+	// until the new farm registers the capacity of the node
+	pt.applyBlock(pt.newRegistrationTx(bob, bobFarm, node, capacity))
+	pt.expectNode(node, bobFarm, capacity)
+	pt.expectNoNodes(aliceFarm)
+
+	pt.revertBlock()
+	pt.expectNoNodes(aliceFarm)
+	pt.expectNoNodes(bobFarm)
+	pt.revertBlock()
+	pt.expectNode(node, aliceFarm, capacity)
+	pt.expectNoNodes(bobFarm)
+}
+
+func TestPluginFarmRegistrationRevert(t *testing.T) {
+	pt, cleanup := newPluginTester(t)
+	defer cleanup()
+
+	alice := pt.newFarmer()
+	pt.applyBlock(pt.newAuthorizationTx(pt.minter, []types.UnlockHash{alice.address}, nil))
+	pt.applyBlock(pt.newFarmRegistrationTx(alice, "farm one"))
+	pt.validateError("farm with a registered name", pt.newFarmRegistrationTx(alice, "farm one"), ctypes.ErrFarmNameAlreadyRegistered)
+	pt.applyBlock(pt.newFarmRegistrationTx(alice, "farm two"), pt.newFarmRegistrationTx(alice, "farm three"))
+
+	expectFarm := func(name string, id ctypes.FarmID) {
+		t.Helper()
+		record, err := pt.plugin.GetFarmForName(name)
+		if err != nil {
+			t.Fatalf("failed to get farm %q: %v", name, err)
+		}
+		if record.ID != id || record.Owner.UnlockHash().Cmp(alice.address) != 0 {
+			t.Errorf("expected farm %q to have ID %s and be owned by %s, not %s and %s",
+				name, id.String(), alice.address.String(), record.ID.String(), record.Owner.UnlockHash().String())
+		}
+	}
+	expectFarm("farm one", 1)
+	expectFarm("farm two", 2)
+	expectFarm("farm three", 3)
+
+	// reverting releases the farm IDs and names, such that they can be assigned again
+	pt.revertBlock()
+	for _, name := range []string{"farm two", "farm three"} {
+		if _, err := pt.plugin.GetFarmForName(name); err != ctypes.ErrFarmNotFound {
+			t.Errorf("expected reverted farm %q to be unknown: %v", name, err)
+		}
+	}
+	if _, err := pt.plugin.GetFarm(2); err != ctypes.ErrFarmNotFound {
+		t.Errorf("expected reverted farm ID 2 to be unknown: %v", err)
+	}
+	pt.applyBlock(pt.newFarmRegistrationTx(alice, "farm three"))
+	expectFarm("farm one", 1)
+	expectFarm("farm three", 2)
+}
+
+func TestPluginFarmingRewardRequiresCapacity(t *testing.T) {
+	pt, cleanup := newPluginTester(t)
+	defer cleanup()
+
+	alice, bob := pt.newFarmer(), pt.newFarmer()
+	pt.applyBlock(pt.newAuthorizationTx(pt.minter, []types.UnlockHash{alice.address, bob.address}, nil))
+	aliceFarm := pt.registerFarm(alice, "alicesfarm", alice.address)
+	bobFarm := pt.registerFarm(bob, "bobsfarm", bob.address)
+	aliceNode, bobNode := newNode(), newNode()
+	pt.applyBlock(pt.newNodeLinkTx(alice, aliceFarm, aliceNode), pt.newNodeLinkTx(bob, bobFarm, bobNode))
+	beforeRegistration := pt.height() - 1
+	pt.applyBlock(
+		pt.newRegistrationTx(alice, aliceFarm, aliceNode, ctypes.CapacityUnits{CRU: 4, MRU: 16}),
+		pt.newRegistrationTx(bob, bobFarm, bobNode, ctypes.CapacityUnits{}))
+	registered := pt.height() - 1
+	// once relinked, the node no longer provides capacity for either farm
+	pt.applyBlock(pt.newNodeLinkTx(bob, bobFarm, aliceNode))
+	relinked := pt.height() - 1
+
+	expectWithoutCapacity := func(description string, txn types.Transaction) {
+		t.Helper()
+		err := pt.validate(txn)
+		if err == nil || !strings.Contains(err.Error(), ctypes.ErrFarmWithoutCapacity.Error()) {
+			t.Errorf("expected %s to be invalid with error %q, not %v", description, ctypes.ErrFarmWithoutCapacity, err)
+		}
+	}
+	expectWithoutCapacity("reward of a farm prior to registering capacity",
+		pt.newFarmingRewardTx(beforeRegistration, map[ctypes.FarmID]testFarmer{aliceFarm: alice}))
+	expectWithoutCapacity("reward of a farm with only decommissioned nodes",
+		pt.newFarmingRewardTx(registered, map[ctypes.FarmID]testFarmer{bobFarm: bob}))
+	expectWithoutCapacity("reward of a farm with and a farm without capacity",
+		pt.newFarmingRewardTx(registered, map[ctypes.FarmID]testFarmer{aliceFarm: alice, bobFarm: bob}))
+	expectWithoutCapacity("reward of the previous farm of a relinked node",
+		pt.newFarmingRewardTx(relinked, map[ctypes.FarmID]testFarmer{aliceFarm: alice}))
+	expectWithoutCapacity("reward of the new farm of a relinked node",
+		pt.newFarmingRewardTx(relinked, map[ctypes.FarmID]testFarmer{bobFarm: bob}))
+
+	pt.applyBlock(pt.newFarmingRewardTx(registered, map[ctypes.FarmID]testFarmer{aliceFarm: alice}))
+	fr, err := pt.plugin.GetFarmRewards(aliceFarm)
+	if err != nil {
+		t.Fatal("failed to get farm rewards:", err)
+	}
+	if len(fr.Rewards) != 1 || fr.Rewards[0].SnapshotHeight != registered {
+		t.Errorf("expected a single reward for snapshot height %d, not %v", registered, fr.Rewards)
+	}
+}
@@ -0,0 +1,103 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/threefoldtech/rivine/types"
+)
+
+// Capacity errors
+var (
+	ErrNodeNotFound          = errors.New("node not found")
+	ErrFarmerNotAuthorized   = errors.New("farmer address is not authorized")
+	ErrInvalidNodePublicKey  = errors.New("node public key has to be an ed25519 public key")
+	ErrInvalidFarmerAddress  = errors.New("farmer address has to be a personal (public key) or multisig address")
+	ErrNoFarmerAuthorization = errors.New("at least one farmer address has to be (de)authorized")
+)
+
+type (
+	// CapacityUnits defines the capacity provided by a node,
+	// expressed in the ThreeFold resource units.
+	CapacityUnits struct {
+		// CRU is the amount of compute resource units (virtual CPU cores)
+		CRU uint64 `json:"cru"`
+		// MRU is the amount of memory resource units (GB of memory)
+		MRU uint64 `json:"mru"`
+		// HRU is the amount of HDD resource units (GB of HDD storage)
+		HRU uint64 `json:"hru"`
+		// SRU is the amount of SSD resource units (GB of SSD storage)
+		SRU uint64 `json:"sru"`
+	}
+
+	// NodeCapacity is the capacity registered for a node,
+	// as registered using a CapacityRegistrationTransaction.
+	NodeCapacity struct {
+		// Node is the public key identifying the node
+		Node types.PublicKey `json:"node"`
+		// FarmID is the ID of the farm that registered the capacity
+		FarmID FarmID `json:"farmid"`
+		// Capacity registered for the node
+		Capacity CapacityUnits `json:"capacity"`
+		// Height of the block that contains the registration
+		Height types.BlockHeight `json:"height"`
+		// TransactionID is the ID of the CapacityRegistrationTransaction
+		TransactionID types.TransactionID `json:"txid"`
+	}
+
+	// FarmCapacity is the total capacity registered by a farm,
+	// for the nodes currently linked to that farm.
+	FarmCapacity struct {
+		// FarmID is the ID of the farm
+		FarmID FarmID `json:"farmid"`
+		// Capacity is the sum of the capacity of all nodes registered by the farm
+		Capacity CapacityUnits `json:"capacity"`
+		// Nodes lists the public keys of all nodes linked to the farm,
+		// for which the farm registered capacity
+		Nodes []types.PublicKey `json:"nodes"`
+	}
+
+	// FarmerStatus is the authorization state of a farmer address.
+	FarmerStatus struct {
+		// Farmer is the address of the farmer
+		Farmer types.UnlockHash `json:"farmer"`
+		// Authorized is true if the farmer is (still) authorized to own farms
+		Authorized bool `json:"authorized"`
+	}
+
+	// CapacityReadRegistry defines the public READ API
+	// expected from a registry of capacity.
+	CapacityReadRegistry interface {
+		// GetNodeCapacity returns the capacity currently registered for the given node,
+		// returning ErrNodeNotFound if no capacity was registered for that node.
+		GetNodeCapacity(node types.PublicKey) (NodeCapacity, error)
+		// GetNodeCapacityAt returns the capacity registered for the given node at the given block height,
+		// returning ErrNodeNotFound if no capacity was registered for that node at that height.
+		GetNodeCapacityAt(node types.PublicKey, height types.BlockHeight) (NodeCapacity, error)
+		// GetFarmCapacity returns the total capacity currently registered by the farm with the given ID,
+		// returning ErrFarmNotFound if no farm is registered with that ID.
+		GetFarmCapacity(id FarmID) (FarmCapacity, error)
+		// IsFarmerAuthorized returns true if the given farmer address is authorized to own farms.
+		IsFarmerAuthorized(farmer types.UnlockHash) (bool, error)
+	}
+)
+
+// IsZero returns true if no capacity is defined.
+func (cu CapacityUnits) IsZero() bool {
+	return cu.CRU == 0 && cu.MRU == 0 && cu.HRU == 0 && cu.SRU == 0
+}
+
+// Add returns the sum of both capacities.
+func (cu CapacityUnits) Add(other CapacityUnits) CapacityUnits {
+	return CapacityUnits{
+		CRU: cu.CRU + other.CRU,
+		MRU: cu.MRU + other.MRU,
+		HRU: cu.HRU + other.HRU,
+		SRU: cu.SRU + other.SRU,
+	}
+}
+
+// String returns a human-readable representation of the capacity.
+func (cu CapacityUnits) String() string {
+	return fmt.Sprintf("%d CRU, %d MRU, %d HRU, %d SRU", cu.CRU, cu.MRU, cu.HRU, cu.SRU)
+}
@@ -0,0 +1,201 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+
+	"github.com/threefoldtech/rivine/types"
+)
+
+const (
+	// RegexpFarmName is used to validate a farm name.
+	RegexpFarmName = `^[A-Za-z0-9]{1}[A-Za-z0-9 ._\-]{1,62}[A-Za-z0-9]{1}$`
+	// MaxLengthFarmLocation defines the maximum length (in bytes) the location of a farm can have.
+	MaxLengthFarmLocation = 128
+	// MaxFarmManagers defines the maximum amount of manager addresses a farm can have.
+	MaxFarmManagers = 16
+
+	// MinFarmID defines the minimum value a farm ID can have,
+	// in other words the smallest identifier value a farm can have.
+	MinFarmID = 1
+	// MaxFarmID defines the maximum value a farm ID can have,
+	// in other words the biggest identifier value a farm can have.
+	MaxFarmID = math.MaxUint32
+)
+
+var (
+	rexFarmName = regexp.MustCompile(RegexpFarmName)
+)
+
+// Farm errors
+var (
+	ErrFarmNotFound              = errors.New("farm not found")
+	ErrFarmNameAlreadyRegistered = errors.New("farm name is already registered")
+	ErrInvalidFarmName           = errors.New("farm name has to be 3 to 64 characters long, consisting of letters, digits, spaces, dots, underscores and dashes, starting and ending with a letter or digit")
+	ErrFarmLocationTooLong       = fmt.Errorf("farm location can maximum be %d bytes long", MaxLengthFarmLocation)
+	ErrTooManyFarmManagers       = fmt.Errorf("a farm can have a maximum of %d managers", MaxFarmManagers)
+	ErrInvalidFarmOwner          = errors.New("farm owner has to be a single signature or multisig condition")
+	ErrNoFarmUpdate              = errors.New("farm update has to update at least one property")
+	ErrNotAFarmManager           = errors.New("address is not a manager of the farm")
+	ErrNodeNotLinked             = errors.New("node is not linked to a farm")
+	ErrNodeAlreadyLinked         = errors.New("node is already linked to the farm")
+)
+
+type (
+	// FarmID defines the identifier type for farms,
+	// each farm has a unique identifier using this type.
+	FarmID uint32
+
+	// FarmRecord is the record type used to store a unique farm.
+	// Once a record is created it is never deleted,
+	// but it can be modified by its owner using a FarmUpdateTransaction.
+	FarmRecord struct {
+		// ID is the unique identifier of the farm, assigned at registration
+		ID FarmID `json:"id"`
+		// Name is the unique name of the farm
+		Name string `json:"name"`
+		// Owner is the condition which has to be fulfilled in order to update the farm
+		Owner types.UnlockConditionProxy `json:"owner"`
+		// Managers are the addresses authorized to manage the (nodes of the) farm
+		Managers []types.UnlockHash `json:"managers"`
+		// Location is a free-format description of the location of the farm
+		Location string `json:"location"`
+		// PayoutAddress is the address to which the farming rewards of the farm are paid
+		PayoutAddress types.UnlockHash `json:"payoutaddress"`
+	}
+
+	// NodeFarmLink defines the link of a node to a farm,
+	// as created by a NodeLinkTransaction.
+	NodeFarmLink struct {
+		// Node is the public key identifying the node
+		Node types.PublicKey `json:"node"`
+		// FarmID is the ID of the farm the node is linked to,
+		// a zero ID means the node was unlinked from its farm
+		FarmID FarmID `json:"farmid"`
+		// Height of the block that contains the link
+		Height types.BlockHeight `json:"height"`
+		// TransactionID is the ID of the NodeLinkTransaction
+		TransactionID types.TransactionID `json:"txid"`
+	}
+
+	// FarmNodes lists the nodes linked to a farm.
+	FarmNodes struct {
+		// FarmID is the ID of the farm
+		FarmID FarmID `json:"farmid"`
+		// Nodes are the public keys of all nodes linked to the farm
+		Nodes []types.PublicKey `json:"nodes"`
+	}
+
+	// FarmReadRegistry defines the public READ API
+	// expected from a registry of farms.
+	FarmReadRegistry interface {
+		// GetFarm returns the record of the farm with the given ID,
+		// returning ErrFarmNotFound if no farm exists for that ID.
+		GetFarm(id FarmID) (FarmRecord, error)
+		// GetFarmForName returns the record of the farm registered with the given name,
+		// returning ErrFarmNotFound if no farm exists for that name.
+		GetFarmForName(name string) (FarmRecord, error)
+		// GetFarmNodes returns the nodes currently linked to the farm with the given ID,
+		// returning ErrFarmNotFound if no farm exists for that ID.
+		GetFarmNodes(id FarmID) (FarmNodes, error)
+		// GetNodeFarm returns the link of the given node to its current farm,
+		// returning ErrNodeNotLinked if the node is not linked to a farm.
+		GetNodeFarm(node types.PublicKey) (NodeFarmLink, error)
+		// GetFarmRewards returns all farming rewards paid out to the farm with the given ID,
+		// returning ErrFarmNotFound if no farm exists for that ID.
+		GetFarmRewards(id FarmID) (FarmRewards, error)
+	}
+)
+
+// LoadString loads a farm ID from a string
+func (id *FarmID) LoadString(str string) error {
+	x, err := strconv.ParseUint(str, 10, 32)
+	if err != nil {
+		return fmt.Errorf("FarmID: %v", err)
+	}
+	if x < MinFarmID {
+		return fmt.Errorf("farm ID has to be at least %d", MinFarmID)
+	}
+	*id = FarmID(x)
+	return nil
+}
+
+// String implements fmt.Stringer.String
+func (id FarmID) String() string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+// IsManager returns true if the given address is one of the managers of the farm.
+func (record *FarmRecord) IsManager(address types.UnlockHash) bool {
+	for _, manager := range record.Managers {
+		if manager.Cmp(address) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateFarmName validates the given name can be used as the name of a farm.
+func ValidateFarmName(name string) error {
+	if !rexFarmName.MatchString(name) {
+		return ErrInvalidFarmName
+	}
+	return nil
+}
+
+// ValidateFarmLocation validates the given location can be used as the location of a farm.
+func ValidateFarmLocation(location string) error {
+	if len(location) > MaxLengthFarmLocation {
+		return ErrFarmLocationTooLong
+	}
+	return nil
+}
+
+// ValidateFarmOwner validates the given condition can be used as the owner of a farm,
+// only single signature (public key) and multisig conditions are supported.
+func ValidateFarmOwner(owner types.UnlockConditionProxy) error {
+	switch owner.ConditionType() {
+	case types.ConditionTypeUnlockHash:
+		if owner.UnlockHash().Type != types.UnlockTypePubKey {
+			return ErrInvalidFarmOwner
+		}
+		return nil
+	case types.ConditionTypeMultiSignature:
+		return nil
+	default:
+		return ErrInvalidFarmOwner
+	}
+}
+
+// ValidateFarmManagers validates the given addresses can be used as the managers of a farm,
+// allowing up to MaxFarmManagers unique personal (public key) addresses.
+func ValidateFarmManagers(managers []types.UnlockHash) error {
+	if len(managers) > MaxFarmManagers {
+		return ErrTooManyFarmManagers
+	}
+	seen := make(map[types.UnlockHash]struct{}, len(managers))
+	for _, uh := range managers {
+		if uh.Type != types.UnlockTypePubKey {
+			return fmt.Errorf("farm manager %s has to be a personal (public key) address", uh.String())
+		}
+		if _, ok := seen[uh]; ok {
+			return fmt.Errorf("farm manager %s is listed more than once", uh.String())
+		}
+		seen[uh] = struct{}{}
+	}
+	return nil
+}
+
+// ValidateFarmPayoutAddress validates the given address can be used as the payout address of a farm,
+// only personal (public key) and multisig addresses are supported.
+func ValidateFarmPayoutAddress(address types.UnlockHash) error {
+	switch address.Type {
+	case types.UnlockTypePubKey, types.UnlockTypeMultiSig:
+		return nil
+	default:
+		return fmt.Errorf("farm payout address %s has to be a personal (public key) or multisig address", address.String())
+	}
+}
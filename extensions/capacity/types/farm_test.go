@@ -0,0 +1,147 @@
+package types
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/threefoldtech/rivine/types"
+)
+
+func TestFarmIDLoadString(t *testing.T) {
+	testCases := []struct {
+		String string
+		ID     FarmID
+		Valid  bool
+	}{
+		{"", 0, false},
+		{"0", 0, false},
+		{"-1", 0, false},
+		{"foo", 0, false},
+		{"4294967296", 0, false},
+		{"1", 1, true},
+		{"42", 42, true},
+		{"4294967295", MaxFarmID, true},
+	}
+	for idx, testCase := range testCases {
+		var id FarmID
+		err := id.LoadString(testCase.String)
+		if !testCase.Valid {
+			if err == nil {
+				t.Errorf("test case #%d: expected error, but none received", idx)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("test case #%d: unexpected error: %v", idx, err)
+			continue
+		}
+		if id != testCase.ID {
+			t.Errorf("test case #%d: %d != %d", idx, id, testCase.ID)
+		}
+		if str := id.String(); str != testCase.String {
+			t.Errorf("test case #%d: %q != %q", idx, str, testCase.String)
+		}
+	}
+}
+
+func TestValidateFarmName(t *testing.T) {
+	testCases := []struct {
+		Name  string
+		Valid bool
+	}{
+		{"", false},
+		{"ab", false},
+		{" abc", false},
+		{"abc ", false},
+		{"-abc", false},
+		{"a/c", false},
+		{strings.Repeat("a", 65), false},
+		{"abc", true},
+		{"123", true},
+		{"My Farm_01.be-2", true},
+		{strings.Repeat("a", 64), true},
+	}
+	for idx, testCase := range testCases {
+		err := ValidateFarmName(testCase.Name)
+		if testCase.Valid && err != nil {
+			t.Errorf("test case #%d: unexpected error: %v", idx, err)
+		} else if !testCase.Valid && err == nil {
+			t.Errorf("test case #%d: expected error, but none received", idx)
+		}
+	}
+}
+
+func TestValidateFarmOwner(t *testing.T) {
+	pubKeyAddress := types.UnlockHash{Type: types.UnlockTypePubKey}
+	testCases := []struct {
+		Owner types.UnlockConditionProxy
+		Valid bool
+	}{
+		{types.UnlockConditionProxy{}, false},
+		{types.NewCondition(types.NewUnlockHashCondition(types.UnlockHash{Type: types.UnlockTypeAtomicSwap})), false},
+		{types.NewCondition(types.NewTimeLockCondition(42, types.NewUnlockHashCondition(pubKeyAddress))), false},
+		{types.NewCondition(types.NewUnlockHashCondition(pubKeyAddress)), true},
+		{types.NewCondition(types.NewMultiSignatureCondition(types.UnlockHashSlice{pubKeyAddress}, 1)), true},
+	}
+	for idx, testCase := range testCases {
+		err := ValidateFarmOwner(testCase.Owner)
+		if testCase.Valid && err != nil {
+			t.Errorf("test case #%d: unexpected error: %v", idx, err)
+		} else if !testCase.Valid && err == nil {
+			t.Errorf("test case #%d: expected error, but none received", idx)
+		}
+	}
+}
+
+func TestValidateFarmManagers(t *testing.T) {
+	a := types.UnlockHash{Type: types.UnlockTypePubKey}
+	a.Hash[0] = 1
+	b := types.UnlockHash{Type: types.UnlockTypePubKey}
+	b.Hash[0] = 2
+	tooMany := make([]types.UnlockHash, MaxFarmManagers+1)
+	for idx := range tooMany {
+		tooMany[idx] = types.UnlockHash{Type: types.UnlockTypePubKey}
+		tooMany[idx].Hash[0] = byte(idx)
+	}
+
+	testCases := []struct {
+		Managers []types.UnlockHash
+		Valid    bool
+	}{
+		{nil, true},
+		{[]types.UnlockHash{a}, true},
+		{[]types.UnlockHash{a, b}, true},
+		{tooMany[:MaxFarmManagers], true},
+		{tooMany, false},
+		{[]types.UnlockHash{a, a}, false},
+		{[]types.UnlockHash{{Type: types.UnlockTypeMultiSig}}, false},
+		{[]types.UnlockHash{{}}, false},
+	}
+	for idx, testCase := range testCases {
+		err := ValidateFarmManagers(testCase.Managers)
+		if testCase.Valid && err != nil {
+			t.Errorf("test case #%d: unexpected error: %v", idx, err)
+		} else if !testCase.Valid && err == nil {
+			t.Errorf("test case #%d: expected error, but none received", idx)
+		}
+	}
+}
+
+func TestValidateFarmLocationAndPayoutAddress(t *testing.T) {
+	if err := ValidateFarmLocation(strings.Repeat("a", MaxLengthFarmLocation)); err != nil {
+		t.Error("unexpected error:", err)
+	}
+	if err := ValidateFarmLocation(strings.Repeat("a", MaxLengthFarmLocation+1)); err == nil {
+		t.Error("expected error for too long location, but none received")
+	}
+	for _, unlockType := range []types.UnlockType{types.UnlockTypePubKey, types.UnlockTypeMultiSig} {
+		if err := ValidateFarmPayoutAddress(types.UnlockHash{Type: unlockType}); err != nil {
+			t.Errorf("unexpected error for unlock type %d: %v", unlockType, err)
+		}
+	}
+	for _, unlockType := range []types.UnlockType{types.UnlockTypeNil, types.UnlockTypeAtomicSwap} {
+		if err := ValidateFarmPayoutAddress(types.UnlockHash{Type: unlockType}); err == nil {
+			t.Errorf("expected error for unlock type %d, but none received", unlockType)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package types
+
+import (
+	"errors"
+
+	"github.com/threefoldtech/rivine/types"
+)
+
+// Farming reward errors
+var (
+	ErrNoFarmPayouts             = errors.New("a farming reward has to pay out to at least one farm")
+	ErrInvalidFarmPayoutAddress  = errors.New("farm payout has to go to the payout address of the farm")
+	ErrInvalidFarmPayoutValue    = errors.New("farm payout value cannot be zero")
+	ErrInvalidRewardSnapshot     = errors.New("farming reward snapshot height has to be lower than the current block height")
+	ErrRewardSnapshotAlreadyUsed = errors.New("farming reward snapshot height has to be higher than the last rewarded snapshot height")
+	ErrFarmWithoutCapacity       = errors.New("farm had no linked node with capacity registered by it at the farming reward snapshot height")
+)
+
+type (
+	// FarmPayout defines a single payout to a farm,
+	// as part of a FarmingRewardTransaction.
+	FarmPayout struct {
+		// FarmID is the ID of the farm that is rewarded
+		FarmID FarmID `json:"farmid"`
+		// Value is the amount of coins paid out to the farm
+		Value types.Currency `json:"value"`
+		// PayoutAddress is the address the coins are paid out to,
+		// which has to be the payout address of the farm
+		PayoutAddress types.UnlockHash `json:"payoutaddress"`
+	}
+
+	// FarmReward defines a single reward paid out to a farm,
+	// as created by a FarmingRewardTransaction.
+	FarmReward struct {
+		// Value is the amount of coins paid out to the farm
+		Value types.Currency `json:"value"`
+		// PayoutAddress is the address the coins were paid out to
+		PayoutAddress types.UnlockHash `json:"payoutaddress"`
+		// SnapshotHeight is the capacity snapshot height the reward is based on
+		SnapshotHeight types.BlockHeight `json:"snapshotheight"`
+		// Height of the block that contains the reward
+		Height types.BlockHeight `json:"height"`
+		// TransactionID is the ID of the FarmingRewardTransaction
+		TransactionID types.TransactionID `json:"txid"`
+	}
+
+	// FarmRewards summarizes all rewards paid out to a farm.
+	FarmRewards struct {
+		// FarmID is the ID of the farm
+		FarmID FarmID `json:"farmid"`
+		// Total is the sum of all rewards paid out to the farm
+		Total types.Currency `json:"total"`
+		// Rewards lists all rewards paid out to the farm, ordered by block height
+		Rewards []FarmReward `json:"rewards"`
+	}
+)
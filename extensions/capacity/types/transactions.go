@@ -0,0 +1,2041 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/extensions/minting"
+	"github.com/threefoldtech/rivine/pkg/encoding/rivbin"
+	"github.com/threefoldtech/rivine/types"
+)
+
+const (
+	// TransactionVersionFarmerAuthorization defines the Transaction version
+	// for a FarmerAuthorization Transaction, used by the foundation to
+	// (de)authorize the addresses of farmers, allowing them to register capacity.
+	TransactionVersionFarmerAuthorization types.TransactionVersion = iota + 192
+	// TransactionVersionCapacityRegistration defines the Transaction version
+	// for a CapacityRegistration Transaction, used by a node and a farm manager
+	// to register the capacity of a node linked to that farm.
+	TransactionVersionCapacityRegistration
+	// TransactionVersionFarmRegistration defines the Transaction version
+	// for a FarmRegistration Transaction, used by an authorized farmer
+	// to register a new farm.
+	TransactionVersionFarmRegistration
+	// TransactionVersionFarmUpdate defines the Transaction version
+	// for a FarmUpdate Transaction, used by the owner of a farm to update it.
+	TransactionVersionFarmUpdate
+	// TransactionVersionNodeLink defines the Transaction version
+	// for a NodeLink Transaction, used by a node and a farm manager
+	// to link the node to a farm, or to unlink it.
+	TransactionVersionNodeLink
+	// TransactionVersionFarmingReward defines the Transaction version
+	// for a FarmingReward Transaction, used by the Coin Minters
+	// to pay out the farming rewards to the registered farms.
+	TransactionVersionFarmingReward
+)
+
+var (
+	SpecifierFarmerAuthorizationTransaction  = types.Specifier{'f', 'a', 'r', 'm', 'e', 'r', ' ', 'a', 'u', 't', 'h', ' ', 't', 'x'}
+	SpecifierCapacityRegistrationTransaction = types.Specifier{'c', 'a', 'p', 'a', 'c', 'i', 't', 'y', ' ', 'r', 'e', 'g', ' ', 't', 'x'}
+	SpecifierFarmRegistrationTransaction     = types.Specifier{'f', 'a', 'r', 'm', ' ', 'r', 'e', 'g', ' ', 't', 'x'}
+	SpecifierFarmUpdateTransaction           = types.Specifier{'f', 'a', 'r', 'm', ' ', 'u', 'p', 'd', 'a', 't', 'e', ' ', 't', 'x'}
+	SpecifierNodeLinkTransaction             = types.Specifier{'n', 'o', 'd', 'e', ' ', 'l', 'i', 'n', 'k', ' ', 't', 'x'}
+	SpecifierFarmingRewardTransaction        = types.Specifier{'f', 'a', 'r', 'm', ' ', 'r', 'e', 'w', 'a', 'r', 'd', ' ', 't', 'x'}
+)
+
+type (
+	// FarmerAuthorizationTransaction defines the Transaction (with version 0xc0)
+	// used to authorize and/or deauthorize farmer addresses,
+	// such that only authorized farmers can register capacity.
+	// It is to be created only by the Coin Minters.
+	FarmerAuthorizationTransaction struct {
+		// Nonce used to ensure the uniqueness of a FarmerAuthorizationTransaction's ID and signature.
+		Nonce types.TransactionNonce `json:"nonce"`
+		// AuthAddresses contains a list of farmer addresses to be authorized,
+		// it is also considered valid to authorize an address that is already authorized.
+		AuthAddresses []types.UnlockHash `json:"authaddresses"`
+		// DeauthAddresses contains a list of farmer addresses to be deauthorized,
+		// it is also considered valid to deauthorize an address that has no authorization.
+		DeauthAddresses []types.UnlockHash `json:"deauthaddresses"`
+		// MintFulfillment defines the fulfillment which is used in order to
+		// fulfill the globally defined MintCondition.
+		MintFulfillment types.UnlockFulfillmentProxy `json:"mintfulfillment"`
+		// MinerFees, a fee paid for this farmer authorization transaction.
+		MinerFees []types.Currency `json:"minerfees"`
+		// ArbitraryData can be used for any purpose.
+		ArbitraryData []byte `json:"arbitrarydata,omitempty"`
+	}
+	// FarmerAuthorizationTransactionExtension defines the FarmerAuthorizationTransaction Extension Data
+	FarmerAuthorizationTransactionExtension struct {
+		Nonce           types.TransactionNonce
+		AuthAddresses   []types.UnlockHash
+		DeauthAddresses []types.UnlockHash
+		MintFulfillment types.UnlockFulfillmentProxy
+	}
+)
+
+// FarmerAuthorizationTransactionFromTransaction creates a FarmerAuthorizationTransaction,
+// using a regular in-memory tfchain transaction.
+//
+// Past the (tx) Version validation it piggy-backs onto the
+// `FarmerAuthorizationTransactionFromTransactionData` constructor.
+func FarmerAuthorizationTransactionFromTransaction(tx types.Transaction) (FarmerAuthorizationTransaction, error) {
+	if tx.Version != TransactionVersionFarmerAuthorization {
+		return FarmerAuthorizationTransaction{}, fmt.Errorf(
+			"a farmer authorization transaction requires tx version %d",
+			TransactionVersionFarmerAuthorization)
+	}
+	return FarmerAuthorizationTransactionFromTransactionData(types.TransactionData{
+		CoinInputs:        tx.CoinInputs,
+		CoinOutputs:       tx.CoinOutputs,
+		BlockStakeInputs:  tx.BlockStakeInputs,
+		BlockStakeOutputs: tx.BlockStakeOutputs,
+		MinerFees:         tx.MinerFees,
+		ArbitraryData:     tx.ArbitraryData,
+		Extension:         tx.Extension,
+	})
+}
+
+// FarmerAuthorizationTransactionFromTransactionData creates a FarmerAuthorizationTransaction,
+// using the TransactionData from a regular in-memory tfchain transaction.
+func FarmerAuthorizationTransactionFromTransactionData(txData types.TransactionData) (FarmerAuthorizationTransaction, error) {
+	// (tx) extension (data) is expected to be a pointer to a valid FarmerAuthorizationTransactionExtension
+	extensionData, ok := txData.Extension.(*FarmerAuthorizationTransactionExtension)
+	if !ok {
+		return FarmerAuthorizationTransaction{}, errors.New("invalid extension data for a FarmerAuthorizationTransaction")
+	}
+	// at least one miner fee is required
+	if len(txData.MinerFees) == 0 {
+		return FarmerAuthorizationTransaction{}, errors.New("at least one miner fee is required for a FarmerAuthorizationTransaction")
+	}
+	// no coin inputs/outputs or block stake inputs/outputs are allowed
+	if len(txData.CoinInputs) != 0 || len(txData.CoinOutputs) != 0 || len(txData.BlockStakeInputs) != 0 || len(txData.BlockStakeOutputs) != 0 {
+		return FarmerAuthorizationTransaction{}, errors.New("no coin inputs/outputs and block stake inputs/outputs are allowed in a FarmerAuthorizationTransaction")
+	}
+	return FarmerAuthorizationTransaction{
+		Nonce:           extensionData.Nonce,
+		AuthAddresses:   extensionData.AuthAddresses,
+		DeauthAddresses: extensionData.DeauthAddresses,
+		MintFulfillment: extensionData.MintFulfillment,
+		MinerFees:       txData.MinerFees,
+		ArbitraryData:   txData.ArbitraryData,
+	}, nil
+}
+
+// TransactionData returns this FarmerAuthorizationTransaction
+// as regular tfchain transaction data.
+func (fatx *FarmerAuthorizationTransaction) TransactionData() types.TransactionData {
+	return types.TransactionData{
+		MinerFees:     fatx.MinerFees,
+		ArbitraryData: fatx.ArbitraryData,
+		Extension: &FarmerAuthorizationTransactionExtension{
+			Nonce:           fatx.Nonce,
+			AuthAddresses:   fatx.AuthAddresses,
+			DeauthAddresses: fatx.DeauthAddresses,
+			MintFulfillment: fatx.MintFulfillment,
+		},
+	}
+}
+
+// Transaction returns this FarmerAuthorizationTransaction
+// as regular tfchain transaction, using TransactionVersionFarmerAuthorization as the type.
+func (fatx *FarmerAuthorizationTransaction) Transaction() types.Transaction {
+	return types.Transaction{
+		Version:       TransactionVersionFarmerAuthorization,
+		MinerFees:     fatx.MinerFees,
+		ArbitraryData: fatx.ArbitraryData,
+		Extension: &FarmerAuthorizationTransactionExtension{
+			Nonce:           fatx.Nonce,
+			AuthAddresses:   fatx.AuthAddresses,
+			DeauthAddresses: fatx.DeauthAddresses,
+			MintFulfillment: fatx.MintFulfillment,
+		},
+	}
+}
+
+// MarshalSia implements SiaMarshaler.MarshalSia,
+// alias of MarshalRivine for backwards-compatibility reasons.
+func (fatx FarmerAuthorizationTransaction) MarshalSia(w io.Writer) error {
+	return fatx.MarshalRivine(w)
+}
+
+// UnmarshalSia implements SiaUnmarshaler.UnmarshalSia,
+// alias of UnmarshalRivine for backwards-compatibility reasons.
+func (fatx *FarmerAuthorizationTransaction) UnmarshalSia(r io.Reader) error {
+	return fatx.UnmarshalRivine(r)
+}
+
+// MarshalRivine implements RivineMarshaler.MarshalRivine
+func (fatx FarmerAuthorizationTransaction) MarshalRivine(w io.Writer) error {
+	return rivbin.NewEncoder(w).EncodeAll(
+		fatx.Nonce,
+		fatx.AuthAddresses,
+		fatx.DeauthAddresses,
+		fatx.MintFulfillment,
+		fatx.MinerFees,
+		fatx.ArbitraryData,
+	)
+}
+
+// UnmarshalRivine implements RivineUnmarshaler.UnmarshalRivine
+func (fatx *FarmerAuthorizationTransaction) UnmarshalRivine(r io.Reader) error {
+	return rivbin.NewDecoder(r).DecodeAll(
+		&fatx.Nonce,
+		&fatx.AuthAddresses,
+		&fatx.DeauthAddresses,
+		&fatx.MintFulfillment,
+		&fatx.MinerFees,
+		&fatx.ArbitraryData,
+	)
+}
+
+type (
+	// CapacityRegistrationTransaction defines the Transaction (with version 0xc1)
+	// used to register (or update) the capacity of a node, on behalf of the farm the node is linked to.
+	// Both the node, using its own ed25519 key, and a manager of the farm have to sign it.
+	// Registering zero capacity for a node is allowed, and is used to decommission a node.
+	CapacityRegistrationTransaction struct {
+		// FarmID is the ID of the farm that registers the capacity,
+		// the node has to be linked to this farm.
+		FarmID FarmID `json:"farmid"`
+		// Node is the ed25519 public key identifying the node.
+		Node types.PublicKey `json:"node"`
+		// NodeSignature is the signature of the node, created using its own private key.
+		NodeSignature types.ByteSlice `json:"nodesignature"`
+		// Capacity defines the capacity provided by the node.
+		Capacity CapacityUnits `json:"capacity"`
+		// Manager is the address of a manager of the farm.
+		Manager types.UnlockHash `json:"manager"`
+		// ManagerFulfillment defines the fulfillment which is used in order to
+		// fulfill the (single signature) condition of the manager address.
+		ManagerFulfillment types.UnlockFulfillmentProxy `json:"managerfulfillment"`
+		// CoinInputs are only used for the required fees.
+		CoinInputs []types.CoinInput `json:"coininputs"`
+		// CoinOutputs are only used for the optional refund.
+		CoinOutputs []types.CoinOutput `json:"coinoutputs,omitempty"`
+		// MinerFees, a fee paid for this capacity registration transaction.
+		MinerFees []types.Currency `json:"minerfees"`
+		// ArbitraryData can be used for any purpose.
+		ArbitraryData []byte `json:"arbitrarydata,omitempty"`
+	}
+	// CapacityRegistrationTransactionExtension defines the CapacityRegistrationTransaction Extension Data
+	CapacityRegistrationTransactionExtension struct {
+		FarmID             FarmID
+		Node               types.PublicKey
+		NodeSignature      types.ByteSlice
+		Capacity           CapacityUnits
+		Manager            types.UnlockHash
+		ManagerFulfillment types.UnlockFulfillmentProxy
+	}
+)
+
+// Specifiers used to ensure the node and manager signatures are unique within a CapacityRegistrationTransaction.
+var (
+	CapacityRegistrationSignatureSpecifierNode    = [...]byte{'n', 'o', 'd', 'e'}
+	CapacityRegistrationSignatureSpecifierManager = [...]byte{'m', 'a', 'n', 'a', 'g', 'e', 'r'}
+)
+
+// CapacityRegistrationTransactionFromTransaction creates a CapacityRegistrationTransaction,
+// using a regular in-memory tfchain transaction.
+//
+// Past the (tx) Version validation it piggy-backs onto the
+// `CapacityRegistrationTransactionFromTransactionData` constructor.
+func CapacityRegistrationTransactionFromTransaction(tx types.Transaction) (CapacityRegistrationTransaction, error) {
+	if tx.Version != TransactionVersionCapacityRegistration {
+		return CapacityRegistrationTransaction{}, fmt.Errorf(
+			"a capacity registration transaction requires tx version %d",
+			TransactionVersionCapacityRegistration)
+	}
+	return CapacityRegistrationTransactionFromTransactionData(types.TransactionData{
+		CoinInputs:        tx.CoinInputs,
+		CoinOutputs:       tx.CoinOutputs,
+		BlockStakeInputs:  tx.BlockStakeInputs,
+		BlockStakeOutputs: tx.BlockStakeOutputs,
+		MinerFees:         tx.MinerFees,
+		ArbitraryData:     tx.ArbitraryData,
+		Extension:         tx.Extension,
+	})
+}
+
+// CapacityRegistrationTransactionFromTransactionData creates a CapacityRegistrationTransaction,
+// using the TransactionData from a regular in-memory tfchain transaction.
+func CapacityRegistrationTransactionFromTransactionData(txData types.TransactionData) (CapacityRegistrationTransaction, error) {
+	// (tx) extension (data) is expected to be a pointer to a valid CapacityRegistrationTransactionExtension
+	extensionData, ok := txData.Extension.(*CapacityRegistrationTransactionExtension)
+	if !ok {
+		return CapacityRegistrationTransaction{}, errors.New("invalid extension data for a CapacityRegistrationTransaction")
+	}
+	// at least one coin input as well as one miner fee is required
+	if len(txData.CoinInputs) == 0 || len(txData.MinerFees) == 0 {
+		return CapacityRegistrationTransaction{}, errors.New("at least one coin input and miner fee is required for a CapacityRegistrationTransaction")
+	}
+	// no block stake inputs or block stake outputs are allowed
+	if len(txData.BlockStakeInputs) != 0 || len(txData.BlockStakeOutputs) != 0 {
+		return CapacityRegistrationTransaction{}, errors.New("no block stake inputs/outputs are allowed in a CapacityRegistrationTransaction")
+	}
+	return CapacityRegistrationTransaction{
+		FarmID:             extensionData.FarmID,
+		Node:               extensionData.Node,
+		NodeSignature:      extensionData.NodeSignature,
+		Capacity:           extensionData.Capacity,
+		Manager:            extensionData.Manager,
+		ManagerFulfillment: extensionData.ManagerFulfillment,
+		CoinInputs:         txData.CoinInputs,
+		CoinOutputs:        txData.CoinOutputs,
+		MinerFees:          txData.MinerFees,
+		ArbitraryData:      txData.ArbitraryData,
+	}, nil
+}
+
+// TransactionData returns this CapacityRegistrationTransaction
+// as regular tfchain transaction data.
+func (crtx *CapacityRegistrationTransaction) TransactionData() types.TransactionData {
+	return types.TransactionData{
+		CoinInputs:    crtx.CoinInputs,
+		CoinOutputs:   crtx.CoinOutputs,
+		MinerFees:     crtx.MinerFees,
+		ArbitraryData: crtx.ArbitraryData,
+		Extension: &CapacityRegistrationTransactionExtension{
+			FarmID:             crtx.FarmID,
+			Node:               crtx.Node,
+			NodeSignature:      crtx.NodeSignature,
+			Capacity:           crtx.Capacity,
+			Manager:            crtx.Manager,
+			ManagerFulfillment: crtx.ManagerFulfillment,
+		},
+	}
+}
+
+// Transaction returns this CapacityRegistrationTransaction
+// as regular tfchain transaction, using TransactionVersionCapacityRegistration as the type.
+func (crtx *CapacityRegistrationTransaction) Transaction() types.Transaction {
+	return types.Transaction{
+		Version:       TransactionVersionCapacityRegistration,
+		CoinInputs:    crtx.CoinInputs,
+		CoinOutputs:   crtx.CoinOutputs,
+		MinerFees:     crtx.MinerFees,
+		ArbitraryData: crtx.ArbitraryData,
+		Extension: &CapacityRegistrationTransactionExtension{
+			FarmID:             crtx.FarmID,
+			Node:               crtx.Node,
+			NodeSignature:      crtx.NodeSignature,
+			Capacity:           crtx.Capacity,
+			Manager:            crtx.Manager,
+			ManagerFulfillment: crtx.ManagerFulfillment,
+		},
+	}
+}
+
+// MarshalSia implements SiaMarshaler.MarshalSia,
+// alias of MarshalRivine for backwards-compatibility reasons.
+func (crtx CapacityRegistrationTransaction) MarshalSia(w io.Writer) error {
+	return crtx.MarshalRivine(w)
+}
+
+// UnmarshalSia implements SiaUnmarshaler.UnmarshalSia,
+// alias of UnmarshalRivine for backwards-compatibility reasons.
+func (crtx *CapacityRegistrationTransaction) UnmarshalSia(r io.Reader) error {
+	return crtx.UnmarshalRivine(r)
+}
+
+// MarshalRivine implements RivineMarshaler.MarshalRivine
+func (crtx CapacityRegistrationTransaction) MarshalRivine(w io.Writer) error {
+	return rivbin.NewEncoder(w).EncodeAll(
+		crtx.FarmID,
+		crtx.Node,
+		crtx.NodeSignature,
+		crtx.Capacity,
+		crtx.Manager,
+		crtx.ManagerFulfillment,
+		crtx.CoinInputs,
+		crtx.CoinOutputs,
+		crtx.MinerFees,
+		crtx.ArbitraryData,
+	)
+}
+
+// UnmarshalRivine implements RivineUnmarshaler.UnmarshalRivine
+func (crtx *CapacityRegistrationTransaction) UnmarshalRivine(r io.Reader) error {
+	return rivbin.NewDecoder(r).DecodeAll(
+		&crtx.FarmID,
+		&crtx.Node,
+		&crtx.NodeSignature,
+		&crtx.Capacity,
+		&crtx.Manager,
+		&crtx.ManagerFulfillment,
+		&crtx.CoinInputs,
+		&crtx.CoinOutputs,
+		&crtx.MinerFees,
+		&crtx.ArbitraryData,
+	)
+}
+
+type (
+	// FarmRegistrationTransaction defines the Transaction (with version 0xc2)
+	// used to register a new farm, owned by an authorized farmer.
+	// A unique farm ID is assigned to the farm once the transaction is part of the blockchain.
+	FarmRegistrationTransaction struct {
+		// Name is the unique name of the farm.
+		Name string `json:"name"`
+		// Owner is the condition of the (authorized) farmer owning the farm,
+		// it has to be fulfilled in order to register or update the farm.
+		Owner types.UnlockConditionProxy `json:"owner"`
+		// Managers are the addresses authorized to manage the (nodes of the) farm.
+		Managers []types.UnlockHash `json:"managers,omitempty"`
+		// Location is a free-format description of the location of the farm.
+		Location string `json:"location,omitempty"`
+		// PayoutAddress is the address to which the farming rewards of the farm are paid.
+		PayoutAddress types.UnlockHash `json:"payoutaddress"`
+		// OwnerFulfillment defines the fulfillment which is used in order to
+		// fulfill the owner condition of the farm.
+		OwnerFulfillment types.UnlockFulfillmentProxy `json:"ownerfulfillment"`
+		// CoinInputs are only used for the required fees.
+		CoinInputs []types.CoinInput `json:"coininputs"`
+		// CoinOutputs are only used for the optional refund.
+		CoinOutputs []types.CoinOutput `json:"coinoutputs,omitempty"`
+		// MinerFees, a fee paid for this farm registration transaction.
+		MinerFees []types.Currency `json:"minerfees"`
+		// ArbitraryData can be used for any purpose.
+		ArbitraryData []byte `json:"arbitrarydata,omitempty"`
+	}
+	// FarmRegistrationTransactionExtension defines the FarmRegistrationTransaction Extension Data
+	FarmRegistrationTransactionExtension struct {
+		Name             string
+		Owner            types.UnlockConditionProxy
+		Managers         []types.UnlockHash
+		Location         string
+		PayoutAddress    types.UnlockHash
+		OwnerFulfillment types.UnlockFulfillmentProxy
+	}
+)
+
+// FarmRegistrationTransactionFromTransaction creates a FarmRegistrationTransaction,
+// using a regular in-memory tfchain transaction.
+//
+// Past the (tx) Version validation it piggy-backs onto the
+// `FarmRegistrationTransactionFromTransactionData` constructor.
+func FarmRegistrationTransactionFromTransaction(tx types.Transaction) (FarmRegistrationTransaction, error) {
+	if tx.Version != TransactionVersionFarmRegistration {
+		return FarmRegistrationTransaction{}, fmt.Errorf(
+			"a farm registration transaction requires tx version %d",
+			TransactionVersionFarmRegistration)
+	}
+	return FarmRegistrationTransactionFromTransactionData(types.TransactionData{
+		CoinInputs:        tx.CoinInputs,
+		CoinOutputs:       tx.CoinOutputs,
+		BlockStakeInputs:  tx.BlockStakeInputs,
+		BlockStakeOutputs: tx.BlockStakeOutputs,
+		MinerFees:         tx.MinerFees,
+		ArbitraryData:     tx.ArbitraryData,
+		Extension:         tx.Extension,
+	})
+}
+
+// FarmRegistrationTransactionFromTransactionData creates a FarmRegistrationTransaction,
+// using the TransactionData from a regular in-memory tfchain transaction.
+func FarmRegistrationTransactionFromTransactionData(txData types.TransactionData) (FarmRegistrationTransaction, error) {
+	// (tx) extension (data) is expected to be a pointer to a valid FarmRegistrationTransactionExtension
+	extensionData, ok := txData.Extension.(*FarmRegistrationTransactionExtension)
+	if !ok {
+		return FarmRegistrationTransaction{}, errors.New("invalid extension data for a FarmRegistrationTransaction")
+	}
+	// at least one coin input as well as one miner fee is required
+	if len(txData.CoinInputs) == 0 || len(txData.MinerFees) == 0 {
+		return FarmRegistrationTransaction{}, errors.New("at least one coin input and miner fee is required for a FarmRegistrationTransaction")
+	}
+	// no block stake inputs or block stake outputs are allowed
+	if len(txData.BlockStakeInputs) != 0 || len(txData.BlockStakeOutputs) != 0 {
+		return FarmRegistrationTransaction{}, errors.New("no block stake inputs/outputs are allowed in a FarmRegistrationTransaction")
+	}
+	return FarmRegistrationTransaction{
+		Name:             extensionData.Name,
+		Owner:            extensionData.Owner,
+		Managers:         extensionData.Managers,
+		Location:         extensionData.Location,
+		PayoutAddress:    extensionData.PayoutAddress,
+		OwnerFulfillment: extensionData.OwnerFulfillment,
+		CoinInputs:       txData.CoinInputs,
+		CoinOutputs:      txData.CoinOutputs,
+		MinerFees:        txData.MinerFees,
+		ArbitraryData:    txData.ArbitraryData,
+	}, nil
+}
+
+// TransactionData returns this FarmRegistrationTransaction
+// as regular tfchain transaction data.
+func (frtx *FarmRegistrationTransaction) TransactionData() types.TransactionData {
+	return types.TransactionData{
+		CoinInputs:    frtx.CoinInputs,
+		CoinOutputs:   frtx.CoinOutputs,
+		MinerFees:     frtx.MinerFees,
+		ArbitraryData: frtx.ArbitraryData,
+		Extension: &FarmRegistrationTransactionExtension{
+			Name:             frtx.Name,
+			Owner:            frtx.Owner,
+			Managers:         frtx.Managers,
+			Location:         frtx.Location,
+			PayoutAddress:    frtx.PayoutAddress,
+			OwnerFulfillment: frtx.OwnerFulfillment,
+		},
+	}
+}
+
+// Transaction returns this FarmRegistrationTransaction
+// as regular tfchain transaction, using TransactionVersionFarmRegistration as the type.
+func (frtx *FarmRegistrationTransaction) Transaction() types.Transaction {
+	return types.Transaction{
+		Version:       TransactionVersionFarmRegistration,
+		CoinInputs:    frtx.CoinInputs,
+		CoinOutputs:   frtx.CoinOutputs,
+		MinerFees:     frtx.MinerFees,
+		ArbitraryData: frtx.ArbitraryData,
+		Extension: &FarmRegistrationTransactionExtension{
+			Name:             frtx.Name,
+			Owner:            frtx.Owner,
+			Managers:         frtx.Managers,
+			Location:         frtx.Location,
+			PayoutAddress:    frtx.PayoutAddress,
+			OwnerFulfillment: frtx.OwnerFulfillment,
+		},
+	}
+}
+
+// MarshalSia implements SiaMarshaler.MarshalSia,
+// alias of MarshalRivine for backwards-compatibility reasons.
+func (frtx FarmRegistrationTransaction) MarshalSia(w io.Writer) error {
+	return frtx.MarshalRivine(w)
+}
+
+// UnmarshalSia implements SiaUnmarshaler.UnmarshalSia,
+// alias of UnmarshalRivine for backwards-compatibility reasons.
+func (frtx *FarmRegistrationTransaction) UnmarshalSia(r io.Reader) error {
+	return frtx.UnmarshalRivine(r)
+}
+
+// MarshalRivine implements RivineMarshaler.MarshalRivine
+func (frtx FarmRegistrationTransaction) MarshalRivine(w io.Writer) error {
+	return rivbin.NewEncoder(w).EncodeAll(
+		frtx.Name,
+		frtx.Owner,
+		frtx.Managers,
+		frtx.Location,
+		frtx.PayoutAddress,
+		frtx.OwnerFulfillment,
+		frtx.CoinInputs,
+		frtx.CoinOutputs,
+		frtx.MinerFees,
+		frtx.ArbitraryData,
+	)
+}
+
+// UnmarshalRivine implements RivineUnmarshaler.UnmarshalRivine
+func (frtx *FarmRegistrationTransaction) UnmarshalRivine(r io.Reader) error {
+	return rivbin.NewDecoder(r).DecodeAll(
+		&frtx.Name,
+		&frtx.Owner,
+		&frtx.Managers,
+		&frtx.Location,
+		&frtx.PayoutAddress,
+		&frtx.OwnerFulfillment,
+		&frtx.CoinInputs,
+		&frtx.CoinOutputs,
+		&frtx.MinerFees,
+		&frtx.ArbitraryData,
+	)
+}
+
+type (
+	// FarmUpdateTransaction defines the Transaction (with version 0xc3)
+	// used by the owner of a farm to update the farm.
+	// Only the properties that are defined are updated.
+	FarmUpdateTransaction struct {
+		// FarmID is the unique identifier of the farm to update.
+		FarmID FarmID `json:"farmid"`
+		// Name is the optional new (unique) name of the farm.
+		Name string `json:"name,omitempty"`
+		// Owner is the optional condition of the (authorized) farmer to transfer the farm to.
+		Owner *types.UnlockConditionProxy `json:"owner,omitempty"`
+		// ManagersToAdd are the addresses to add as managers of the farm.
+		ManagersToAdd []types.UnlockHash `json:"addmanagers,omitempty"`
+		// ManagersToRemove are the addresses to remove as managers of the farm.
+		ManagersToRemove []types.UnlockHash `json:"removemanagers,omitempty"`
+		// Location is the optional new location of the farm.
+		Location *string `json:"location,omitempty"`
+		// PayoutAddress is the optional new address to which the farming rewards of the farm are paid.
+		PayoutAddress *types.UnlockHash `json:"payoutaddress,omitempty"`
+		// OwnerFulfillment defines the fulfillment which is used in order to
+		// fulfill the (current) owner condition of the farm.
+		OwnerFulfillment types.UnlockFulfillmentProxy `json:"ownerfulfillment"`
+		// CoinInputs are only used for the required fees.
+		CoinInputs []types.CoinInput `json:"coininputs"`
+		// CoinOutputs are only used for the optional refund.
+		CoinOutputs []types.CoinOutput `json:"coinoutputs,omitempty"`
+		// MinerFees, a fee paid for this farm update transaction.
+		MinerFees []types.Currency `json:"minerfees"`
+		// ArbitraryData can be used for any purpose.
+		ArbitraryData []byte `json:"arbitrarydata,omitempty"`
+	}
+	// FarmUpdateTransactionExtension defines the FarmUpdateTransaction Extension Data
+	FarmUpdateTransactionExtension struct {
+		FarmID           FarmID
+		Name             string
+		Owner            *types.UnlockConditionProxy
+		ManagersToAdd    []types.UnlockHash
+		ManagersToRemove []types.UnlockHash
+		Location         *string
+		PayoutAddress    *types.UnlockHash
+		OwnerFulfillment types.UnlockFulfillmentProxy
+	}
+)
+
+// FarmUpdateTransactionFromTransaction creates a FarmUpdateTransaction,
+// using a regular in-memory tfchain transaction.
+//
+// Past the (tx) Version validation it piggy-backs onto the
+// `FarmUpdateTransactionFromTransactionData` constructor.
+func FarmUpdateTransactionFromTransaction(tx types.Transaction) (FarmUpdateTransaction, error) {
+	if tx.Version != TransactionVersionFarmUpdate {
+		return FarmUpdateTransaction{}, fmt.Errorf(
+			"a farm update transaction requires tx version %d",
+			TransactionVersionFarmUpdate)
+	}
+	return FarmUpdateTransactionFromTransactionData(types.TransactionData{
+		CoinInputs:        tx.CoinInputs,
+		CoinOutputs:       tx.CoinOutputs,
+		BlockStakeInputs:  tx.BlockStakeInputs,
+		BlockStakeOutputs: tx.BlockStakeOutputs,
+		MinerFees:         tx.MinerFees,
+		ArbitraryData:     tx.ArbitraryData,
+		Extension:         tx.Extension,
+	})
+}
+
+// FarmUpdateTransactionFromTransactionData creates a FarmUpdateTransaction,
+// using the TransactionData from a regular in-memory tfchain transaction.
+func FarmUpdateTransactionFromTransactionData(txData types.TransactionData) (FarmUpdateTransaction, error) {
+	// (tx) extension (data) is expected to be a pointer to a valid FarmUpdateTransactionExtension
+	extensionData, ok := txData.Extension.(*FarmUpdateTransactionExtension)
+	if !ok {
+		return FarmUpdateTransaction{}, errors.New("invalid extension data for a FarmUpdateTransaction")
+	}
+	// at least one coin input as well as one miner fee is required
+	if len(txData.CoinInputs) == 0 || len(txData.MinerFees) == 0 {
+		return FarmUpdateTransaction{}, errors.New("at least one coin input and miner fee is required for a FarmUpdateTransaction")
+	}
+	// no block stake inputs or block stake outputs are allowed
+	if len(txData.BlockStakeInputs) != 0 || len(txData.BlockStakeOutputs) != 0 {
+		return FarmUpdateTransaction{}, errors.New("no block stake inputs/outputs are allowed in a FarmUpdateTransaction")
+	}
+	return FarmUpdateTransaction{
+		FarmID:           extensionData.FarmID,
+		Name:             extensionData.Name,
+		Owner:            extensionData.Owner,
+		ManagersToAdd:    extensionData.ManagersToAdd,
+		ManagersToRemove: extensionData.ManagersToRemove,
+		Location:         extensionData.Location,
+		PayoutAddress:    extensionData.PayoutAddress,
+		OwnerFulfillment: extensionData.OwnerFulfillment,
+		CoinInputs:       txData.CoinInputs,
+		CoinOutputs:      txData.CoinOutputs,
+		MinerFees:        txData.MinerFees,
+		ArbitraryData:    txData.ArbitraryData,
+	}, nil
+}
+
+// TransactionData returns this FarmUpdateTransaction
+// as regular tfchain transaction data.
+func (futx *FarmUpdateTransaction) TransactionData() types.TransactionData {
+	return types.TransactionData{
+		CoinInputs:    futx.CoinInputs,
+		CoinOutputs:   futx.CoinOutputs,
+		MinerFees:     futx.MinerFees,
+		ArbitraryData: futx.ArbitraryData,
+		Extension:     futx.extension(),
+	}
+}
+
+// Transaction returns this FarmUpdateTransaction
+// as regular tfchain transaction, using TransactionVersionFarmUpdate as the type.
+func (futx *FarmUpdateTransaction) Transaction() types.Transaction {
+	return types.Transaction{
+		Version:       TransactionVersionFarmUpdate,
+		CoinInputs:    futx.CoinInputs,
+		CoinOutputs:   futx.CoinOutputs,
+		MinerFees:     futx.MinerFees,
+		ArbitraryData: futx.ArbitraryData,
+		Extension:     futx.extension(),
+	}
+}
+
+func (futx *FarmUpdateTransaction) extension() *FarmUpdateTransactionExtension {
+	return &FarmUpdateTransactionExtension{
+		FarmID:           futx.FarmID,
+		Name:             futx.Name,
+		Owner:            futx.Owner,
+		ManagersToAdd:    futx.ManagersToAdd,
+		ManagersToRemove: futx.ManagersToRemove,
+		Location:         futx.Location,
+		PayoutAddress:    futx.PayoutAddress,
+		OwnerFulfillment: futx.OwnerFulfillment,
+	}
+}
+
+// IsEmpty returns true if this FarmUpdateTransaction does not update any property of the farm.
+func (futx *FarmUpdateTransaction) IsEmpty() bool {
+	return futx.Name == "" && futx.Owner == nil &&
+		len(futx.ManagersToAdd) == 0 && len(futx.ManagersToRemove) == 0 &&
+		futx.Location == nil && futx.PayoutAddress == nil
+}
+
+// UpdateFarmRecord applies the updates defined by this FarmUpdateTransaction
+// to the given farm record, returning an error if a manager cannot be added or removed.
+func (futx *FarmUpdateTransaction) UpdateFarmRecord(record *FarmRecord) error {
+	if futx.Name != "" {
+		record.Name = futx.Name
+	}
+	if futx.Owner != nil {
+		record.Owner = *futx.Owner
+	}
+	// copy the managers, such that shallow copies of the record are not affected
+	managers := make([]types.UnlockHash, len(record.Managers))
+	copy(managers, record.Managers)
+	record.Managers = managers
+	for _, uh := range futx.ManagersToRemove {
+		idx := -1
+		for i, manager := range record.Managers {
+			if manager.Cmp(uh) == 0 {
+				idx = i
+				break
+			}
+		}
+		if idx == -1 {
+			return fmt.Errorf("address %s is not a manager of farm %s", uh.String(), record.ID.String())
+		}
+		record.Managers = append(record.Managers[:idx], record.Managers[idx+1:]...)
+	}
+	for _, uh := range futx.ManagersToAdd {
+		if record.IsManager(uh) {
+			return fmt.Errorf("address %s is already a manager of farm %s", uh.String(), record.ID.String())
+		}
+		record.Managers = append(record.Managers, uh)
+	}
+	if futx.Location != nil {
+		record.Location = *futx.Location
+	}
+	if futx.PayoutAddress != nil {
+		record.PayoutAddress = *futx.PayoutAddress
+	}
+	return nil
+}
+
+// MarshalSia implements SiaMarshaler.MarshalSia,
+// alias of MarshalRivine for backwards-compatibility reasons.
+func (futx FarmUpdateTransaction) MarshalSia(w io.Writer) error {
+	return futx.MarshalRivine(w)
+}
+
+// UnmarshalSia implements SiaUnmarshaler.UnmarshalSia,
+// alias of UnmarshalRivine for backwards-compatibility reasons.
+func (futx *FarmUpdateTransaction) UnmarshalSia(r io.Reader) error {
+	return futx.UnmarshalRivine(r)
+}
+
+// MarshalRivine implements RivineMarshaler.MarshalRivine
+func (futx FarmUpdateTransaction) MarshalRivine(w io.Writer) error {
+	enc := rivbin.NewEncoder(w)
+	err := futx.encodeUpdates(enc)
+	if err != nil {
+		return err
+	}
+	return enc.EncodeAll(
+		futx.OwnerFulfillment,
+		futx.CoinInputs,
+		futx.CoinOutputs,
+		futx.MinerFees,
+		futx.ArbitraryData,
+	)
+}
+
+// UnmarshalRivine implements RivineUnmarshaler.UnmarshalRivine
+func (futx *FarmUpdateTransaction) UnmarshalRivine(r io.Reader) error {
+	dec := rivbin.NewDecoder(r)
+
+	// decode the farm ID, name and flags (indicating which optional properties are included)
+	var flags uint8
+	err := dec.DecodeAll(&futx.FarmID, &futx.Name, &flags)
+	if err != nil {
+		return err
+	}
+	if flags&^(farmUpdateFlagOwner|farmUpdateFlagLocation|farmUpdateFlagPayoutAddress) != 0 {
+		return fmt.Errorf("invalid farm update flags: %d", flags)
+	}
+	futx.Owner, futx.Location, futx.PayoutAddress = nil, nil, nil
+	if flags&farmUpdateFlagOwner != 0 {
+		futx.Owner = new(types.UnlockConditionProxy)
+		err = dec.Decode(futx.Owner)
+		if err != nil {
+			return err
+		}
+	}
+	err = dec.DecodeAll(&futx.ManagersToAdd, &futx.ManagersToRemove)
+	if err != nil {
+		return err
+	}
+	if flags&farmUpdateFlagLocation != 0 {
+		futx.Location = new(string)
+		err = dec.Decode(futx.Location)
+		if err != nil {
+			return err
+		}
+	}
+	if flags&farmUpdateFlagPayoutAddress != 0 {
+		futx.PayoutAddress = new(types.UnlockHash)
+		err = dec.Decode(futx.PayoutAddress)
+		if err != nil {
+			return err
+		}
+	}
+
+	return dec.DecodeAll(
+		&futx.OwnerFulfillment,
+		&futx.CoinInputs,
+		&futx.CoinOutputs,
+		&futx.MinerFees,
+		&futx.ArbitraryData,
+	)
+}
+
+// flags used to indicate which optional properties are included in a binary-encoded FarmUpdateTransaction
+const (
+	farmUpdateFlagOwner uint8 = 1 << iota
+	farmUpdateFlagLocation
+	farmUpdateFlagPayoutAddress
+)
+
+// encodeUpdates encodes the farm ID and all (optional) updates of this FarmUpdateTransaction,
+// used for both the binary encoding and the signature hash of the transaction.
+func (futx *FarmUpdateTransaction) encodeUpdates(enc *rivbin.Encoder) error {
+	var flags uint8
+	if futx.Owner != nil {
+		flags |= farmUpdateFlagOwner
+	}
+	if futx.Location != nil {
+		flags |= farmUpdateFlagLocation
+	}
+	if futx.PayoutAddress != nil {
+		flags |= farmUpdateFlagPayoutAddress
+	}
+	err := enc.EncodeAll(futx.FarmID, futx.Name, flags)
+	if err != nil {
+		return err
+	}
+	// deref the optional properties to ensure we do not also encode one byte
+	// for the pointer indication, as this is already covered by the flags
+	if futx.Owner != nil {
+		err = enc.Encode(*futx.Owner)
+		if err != nil {
+			return err
+		}
+	}
+	err = enc.EncodeAll(futx.ManagersToAdd, futx.ManagersToRemove)
+	if err != nil {
+		return err
+	}
+	if futx.Location != nil {
+		err = enc.Encode(*futx.Location)
+		if err != nil {
+			return err
+		}
+	}
+	if futx.PayoutAddress != nil {
+		err = enc.Encode(*futx.PayoutAddress)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type (
+	// NodeLinkTransaction defines the Transaction (with version 0xc4)
+	// used to link a node to a farm, or to unlink it from its current farm.
+	// Both the node, using its own ed25519 key, and a manager of the farm have to sign it.
+	// A node that is already linked to another farm is relinked to the given farm.
+	NodeLinkTransaction struct {
+		// Node is the ed25519 public key identifying the node.
+		Node types.PublicKey `json:"node"`
+		// NodeSignature is the signature of the node, created using its own private key.
+		NodeSignature types.ByteSlice `json:"nodesignature"`
+		// FarmID is the ID of the farm to link the node to,
+		// a zero ID unlinks the node from its current farm.
+		FarmID FarmID `json:"farmid"`
+		// Manager is the address of a manager of the farm,
+		// the farm the node is linked to or, when unlinking, the current farm of the node.
+		Manager types.UnlockHash `json:"manager"`
+		// ManagerFulfillment defines the fulfillment which is used in order to
+		// fulfill the (single signature) condition of the manager address.
+		ManagerFulfillment types.UnlockFulfillmentProxy `json:"managerfulfillment"`
+		// CoinInputs are only used for the required fees.
+		CoinInputs []types.CoinInput `json:"coininputs"`
+		// CoinOutputs are only used for the optional refund.
+		CoinOutputs []types.CoinOutput `json:"coinoutputs,omitempty"`
+		// MinerFees, a fee paid for this node link transaction.
+		MinerFees []types.Currency `json:"minerfees"`
+		// ArbitraryData can be used for any purpose.
+		ArbitraryData []byte `json:"arbitrarydata,omitempty"`
+	}
+	// NodeLinkTransactionExtension defines the NodeLinkTransaction Extension Data
+	NodeLinkTransactionExtension struct {
+		Node               types.PublicKey
+		NodeSignature      types.ByteSlice
+		FarmID             FarmID
+		Manager            types.UnlockHash
+		ManagerFulfillment types.UnlockFulfillmentProxy
+	}
+)
+
+// Specifiers used to ensure the node and manager signatures are unique within a NodeLinkTransaction.
+var (
+	NodeLinkSignatureSpecifierNode    = [...]byte{'n', 'o', 'd', 'e'}
+	NodeLinkSignatureSpecifierManager = [...]byte{'m', 'a', 'n', 'a', 'g', 'e', 'r'}
+)
+
+// NodeLinkTransactionFromTransaction creates a NodeLinkTransaction,
+// using a regular in-memory tfchain transaction.
+//
+// Past the (tx) Version validation it piggy-backs onto the
+// `NodeLinkTransactionFromTransactionData` constructor.
+func NodeLinkTransactionFromTransaction(tx types.Transaction) (NodeLinkTransaction, error) {
+	if tx.Version != TransactionVersionNodeLink {
+		return NodeLinkTransaction{}, fmt.Errorf(
+			"a node link transaction requires tx version %d",
+			TransactionVersionNodeLink)
+	}
+	return NodeLinkTransactionFromTransactionData(types.TransactionData{
+		CoinInputs:        tx.CoinInputs,
+		CoinOutputs:       tx.CoinOutputs,
+		BlockStakeInputs:  tx.BlockStakeInputs,
+		BlockStakeOutputs: tx.BlockStakeOutputs,
+		MinerFees:         tx.MinerFees,
+		ArbitraryData:     tx.ArbitraryData,
+		Extension:         tx.Extension,
+	})
+}
+
+// NodeLinkTransactionFromTransactionData creates a NodeLinkTransaction,
+// using the TransactionData from a regular in-memory tfchain transaction.
+func NodeLinkTransactionFromTransactionData(txData types.TransactionData) (NodeLinkTransaction, error) {
+	// (tx) extension (data) is expected to be a pointer to a valid NodeLinkTransactionExtension
+	extensionData, ok := txData.Extension.(*NodeLinkTransactionExtension)
+	if !ok {
+		return NodeLinkTransaction{}, errors.New("invalid extension data for a NodeLinkTransaction")
+	}
+	// at least one coin input as well as one miner fee is required
+	if len(txData.CoinInputs) == 0 || len(txData.MinerFees) == 0 {
+		return NodeLinkTransaction{}, errors.New("at least one coin input and miner fee is required for a NodeLinkTransaction")
+	}
+	// no block stake inputs or block stake outputs are allowed
+	if len(txData.BlockStakeInputs) != 0 || len(txData.BlockStakeOutputs) != 0 {
+		return NodeLinkTransaction{}, errors.New("no block stake inputs/outputs are allowed in a NodeLinkTransaction")
+	}
+	return NodeLinkTransaction{
+		Node:               extensionData.Node,
+		NodeSignature:      extensionData.NodeSignature,
+		FarmID:             extensionData.FarmID,
+		Manager:            extensionData.Manager,
+		ManagerFulfillment: extensionData.ManagerFulfillment,
+		CoinInputs:         txData.CoinInputs,
+		CoinOutputs:        txData.CoinOutputs,
+		MinerFees:          txData.MinerFees,
+		ArbitraryData:      txData.ArbitraryData,
+	}, nil
+}
+
+// TransactionData returns this NodeLinkTransaction
+// as regular tfchain transaction data.
+func (nltx *NodeLinkTransaction) TransactionData() types.TransactionData {
+	return types.TransactionData{
+		CoinInputs:    nltx.CoinInputs,
+		CoinOutputs:   nltx.CoinOutputs,
+		MinerFees:     nltx.MinerFees,
+		ArbitraryData: nltx.ArbitraryData,
+		Extension: &NodeLinkTransactionExtension{
+			Node:               nltx.Node,
+			NodeSignature:      nltx.NodeSignature,
+			FarmID:             nltx.FarmID,
+			Manager:            nltx.Manager,
+			ManagerFulfillment: nltx.ManagerFulfillment,
+		},
+	}
+}
+
+// Transaction returns this NodeLinkTransaction
+// as regular tfchain transaction, using TransactionVersionNodeLink as the type.
+func (nltx *NodeLinkTransaction) Transaction() types.Transaction {
+	return types.Transaction{
+		Version:       TransactionVersionNodeLink,
+		CoinInputs:    nltx.CoinInputs,
+		CoinOutputs:   nltx.CoinOutputs,
+		MinerFees:     nltx.MinerFees,
+		ArbitraryData: nltx.ArbitraryData,
+		Extension: &NodeLinkTransactionExtension{
+			Node:               nltx.Node,
+			NodeSignature:      nltx.NodeSignature,
+			FarmID:             nltx.FarmID,
+			Manager:            nltx.Manager,
+			ManagerFulfillment: nltx.ManagerFulfillment,
+		},
+	}
+}
+
+// IsUnlink returns true if this NodeLinkTransaction unlinks the node from its current farm.
+func (nltx *NodeLinkTransaction) IsUnlink() bool {
+	return nltx.FarmID == 0
+}
+
+// MarshalSia implements SiaMarshaler.MarshalSia,
+// alias of MarshalRivine for backwards-compatibility reasons.
+func (nltx NodeLinkTransaction) MarshalSia(w io.Writer) error {
+	return nltx.MarshalRivine(w)
+}
+
+// UnmarshalSia implements SiaUnmarshaler.UnmarshalSia,
+// alias of UnmarshalRivine for backwards-compatibility reasons.
+func (nltx *NodeLinkTransaction) UnmarshalSia(r io.Reader) error {
+	return nltx.UnmarshalRivine(r)
+}
+
+// MarshalRivine implements RivineMarshaler.MarshalRivine
+func (nltx NodeLinkTransaction) MarshalRivine(w io.Writer) error {
+	return rivbin.NewEncoder(w).EncodeAll(
+		nltx.Node,
+		nltx.NodeSignature,
+		nltx.FarmID,
+		nltx.Manager,
+		nltx.ManagerFulfillment,
+		nltx.CoinInputs,
+		nltx.CoinOutputs,
+		nltx.MinerFees,
+		nltx.ArbitraryData,
+	)
+}
+
+// UnmarshalRivine implements RivineUnmarshaler.UnmarshalRivine
+func (nltx *NodeLinkTransaction) UnmarshalRivine(r io.Reader) error {
+	return rivbin.NewDecoder(r).DecodeAll(
+		&nltx.Node,
+		&nltx.NodeSignature,
+		&nltx.FarmID,
+		&nltx.Manager,
+		&nltx.ManagerFulfillment,
+		&nltx.CoinInputs,
+		&nltx.CoinOutputs,
+		&nltx.MinerFees,
+		&nltx.ArbitraryData,
+	)
+}
+
+type (
+	// FarmingRewardTransaction defines the Transaction (with version 0xc5)
+	// used to pay out the farming rewards, for a given capacity snapshot height,
+	// to the payout addresses of the registered farms. Many farms can be paid in one transaction.
+	// It is to be created only by the Coin Minters.
+	FarmingRewardTransaction struct {
+		// Nonce used to ensure the uniqueness of a FarmingRewardTransaction's ID and signature.
+		Nonce types.TransactionNonce `json:"nonce"`
+		// SnapshotHeight is the block height of the capacity snapshot the rewards are based on.
+		SnapshotHeight types.BlockHeight `json:"snapshotheight"`
+		// Payouts defines the rewards paid out to the farms,
+		// each payout creates a coin output, paying to the payout address of the farm.
+		Payouts []FarmPayout `json:"payouts"`
+		// MintFulfillment defines the fulfillment which is used in order to
+		// fulfill the globally defined MintCondition.
+		MintFulfillment types.UnlockFulfillmentProxy `json:"mintfulfillment"`
+		// MinerFees, a fee paid for this farming reward transaction.
+		MinerFees []types.Currency `json:"minerfees"`
+		// ArbitraryData can be used for any purpose.
+		ArbitraryData []byte `json:"arbitrarydata,omitempty"`
+	}
+	// FarmingRewardTransactionExtension defines the FarmingRewardTransaction Extension Data,
+	// the payouts themselves are stored as regular coin outputs, in the same order as the farm IDs.
+	FarmingRewardTransactionExtension struct {
+		Nonce           types.TransactionNonce
+		SnapshotHeight  types.BlockHeight
+		FarmIDs         []FarmID
+		MintFulfillment types.UnlockFulfillmentProxy
+	}
+)
+
+// FarmingRewardTransactionFromTransaction creates a FarmingRewardTransaction,
+// using a regular in-memory tfchain transaction.
+//
+// Past the (tx) Version validation it piggy-backs onto the
+// `FarmingRewardTransactionFromTransactionData` constructor.
+func FarmingRewardTransactionFromTransaction(tx types.Transaction) (FarmingRewardTransaction, error) {
+	if tx.Version != TransactionVersionFarmingReward {
+		return FarmingRewardTransaction{}, fmt.Errorf(
+			"a farming reward transaction requires tx version %d",
+			TransactionVersionFarmingReward)
+	}
+	return FarmingRewardTransactionFromTransactionData(types.TransactionData{
+		CoinInputs:        tx.CoinInputs,
+		CoinOutputs:       tx.CoinOutputs,
+		BlockStakeInputs:  tx.BlockStakeInputs,
+		BlockStakeOutputs: tx.BlockStakeOutputs,
+		MinerFees:         tx.MinerFees,
+		ArbitraryData:     tx.ArbitraryData,
+		Extension:         tx.Extension,
+	})
+}
+
+// FarmingRewardTransactionFromTransactionData creates a FarmingRewardTransaction,
+// using the TransactionData from a regular in-memory tfchain transaction.
+func FarmingRewardTransactionFromTransactionData(txData types.TransactionData) (FarmingRewardTransaction, error) {
+	// (tx) extension (data) is expected to be a pointer to a valid FarmingRewardTransactionExtension
+	extensionData, ok := txData.Extension.(*FarmingRewardTransactionExtension)
+	if !ok {
+		return FarmingRewardTransaction{}, errors.New("invalid extension data for a FarmingRewardTransaction")
+	}
+	// at least one miner fee is required
+	if len(txData.MinerFees) == 0 {
+		return FarmingRewardTransaction{}, errors.New("at least one miner fee is required for a FarmingRewardTransaction")
+	}
+	// no coin inputs or block stake inputs/outputs are allowed
+	if len(txData.CoinInputs) != 0 || len(txData.BlockStakeInputs) != 0 || len(txData.BlockStakeOutputs) != 0 {
+		return FarmingRewardTransaction{}, errors.New("no coin inputs and block stake inputs/outputs are allowed in a FarmingRewardTransaction")
+	}
+	// each coin output pays out to a single farm
+	if len(txData.CoinOutputs) != len(extensionData.FarmIDs) {
+		return FarmingRewardTransaction{}, fmt.Errorf(
+			"a FarmingRewardTransaction requires exactly one coin output per farm: %d coin outputs != %d farms",
+			len(txData.CoinOutputs), len(extensionData.FarmIDs))
+	}
+	var payouts []FarmPayout
+	if n := len(txData.CoinOutputs); n > 0 {
+		payouts = make([]FarmPayout, n)
+		for idx, co := range txData.CoinOutputs {
+			if co.Condition.ConditionType() != types.ConditionTypeUnlockHash {
+				return FarmingRewardTransaction{}, errors.New("the coin outputs of a FarmingRewardTransaction can only use an unlock hash condition")
+			}
+			payouts[idx] = FarmPayout{
+				FarmID:        extensionData.FarmIDs[idx],
+				Value:         co.Value,
+				PayoutAddress: co.Condition.UnlockHash(),
+			}
+		}
+	}
+	return FarmingRewardTransaction{
+		Nonce:           extensionData.Nonce,
+		SnapshotHeight:  extensionData.SnapshotHeight,
+		Payouts:         payouts,
+		MintFulfillment: extensionData.MintFulfillment,
+		MinerFees:       txData.MinerFees,
+		ArbitraryData:   txData.ArbitraryData,
+	}, nil
+}
+
+// TransactionData returns this FarmingRewardTransaction
+// as regular tfchain transaction data.
+func (frtx *FarmingRewardTransaction) TransactionData() types.TransactionData {
+	coinOutputs, extension := frtx.splitPayouts()
+	return types.TransactionData{
+		CoinOutputs:   coinOutputs,
+		MinerFees:     frtx.MinerFees,
+		ArbitraryData: frtx.ArbitraryData,
+		Extension:     extension,
+	}
+}
+
+// Transaction returns this FarmingRewardTransaction
+// as regular tfchain transaction, using TransactionVersionFarmingReward as the type.
+func (frtx *FarmingRewardTransaction) Transaction() types.Transaction {
+	coinOutputs, extension := frtx.splitPayouts()
+	return types.Transaction{
+		Version:       TransactionVersionFarmingReward,
+		CoinOutputs:   coinOutputs,
+		MinerFees:     frtx.MinerFees,
+		ArbitraryData: frtx.ArbitraryData,
+		Extension:     extension,
+	}
+}
+
+// splitPayouts splits the payouts into regular coin outputs,
+// and the extension data that links each coin output to its farm.
+func (frtx *FarmingRewardTransaction) splitPayouts() ([]types.CoinOutput, *FarmingRewardTransactionExtension) {
+	extension := &FarmingRewardTransactionExtension{
+		Nonce:           frtx.Nonce,
+		SnapshotHeight:  frtx.SnapshotHeight,
+		MintFulfillment: frtx.MintFulfillment,
+	}
+	if len(frtx.Payouts) == 0 {
+		return nil, extension
+	}
+	coinOutputs := make([]types.CoinOutput, len(frtx.Payouts))
+	extension.FarmIDs = make([]FarmID, len(frtx.Payouts))
+	for idx, payout := range frtx.Payouts {
+		coinOutputs[idx] = types.CoinOutput{
+			Value:     payout.Value,
+			Condition: types.NewCondition(types.NewUnlockHashCondition(payout.PayoutAddress)),
+		}
+		extension.FarmIDs[idx] = payout.FarmID
+	}
+	return coinOutputs, extension
+}
+
+// MarshalSia implements SiaMarshaler.MarshalSia,
+// alias of MarshalRivine for backwards-compatibility reasons.
+func (frtx FarmingRewardTransaction) MarshalSia(w io.Writer) error {
+	return frtx.MarshalRivine(w)
+}
+
+// UnmarshalSia implements SiaUnmarshaler.UnmarshalSia,
+// alias of UnmarshalRivine for backwards-compatibility reasons.
+func (frtx *FarmingRewardTransaction) UnmarshalSia(r io.Reader) error {
+	return frtx.UnmarshalRivine(r)
+}
+
+// MarshalRivine implements RivineMarshaler.MarshalRivine
+func (frtx FarmingRewardTransaction) MarshalRivine(w io.Writer) error {
+	return rivbin.NewEncoder(w).EncodeAll(
+		frtx.Nonce,
+		frtx.SnapshotHeight,
+		frtx.Payouts,
+		frtx.MintFulfillment,
+		frtx.MinerFees,
+		frtx.ArbitraryData,
+	)
+}
+
+// UnmarshalRivine implements RivineUnmarshaler.UnmarshalRivine
+func (frtx *FarmingRewardTransaction) UnmarshalRivine(r io.Reader) error {
+	return rivbin.NewDecoder(r).DecodeAll(
+		&frtx.Nonce,
+		&frtx.SnapshotHeight,
+		&frtx.Payouts,
+		&frtx.MintFulfillment,
+		&frtx.MinerFees,
+		&frtx.ArbitraryData,
+	)
+}
+
+type (
+	// FarmerAuthorizationTransactionController defines a tfchain-specific transaction controller,
+	// for a transaction type reserved at type 0xc0. It allows the Coin Minters to (de)authorize farmers.
+	FarmerAuthorizationTransactionController struct {
+		// MintConditionGetter is used to get the mint condition,
+		// which has to be fulfilled in order to (de)authorize farmers.
+		MintConditionGetter minting.MintConditionGetter
+	}
+
+	// CapacityRegistrationTransactionController defines a tfchain-specific transaction controller,
+	// for a transaction type reserved at type 0xc1. It allows authorized farmers to register capacity.
+	CapacityRegistrationTransactionController struct{}
+
+	// FarmRegistrationTransactionController defines a tfchain-specific transaction controller,
+	// for a transaction type reserved at type 0xc2. It allows authorized farmers to register a farm.
+	FarmRegistrationTransactionController struct{}
+
+	// FarmUpdateTransactionController defines a tfchain-specific transaction controller,
+	// for a transaction type reserved at type 0xc3. It allows the owner of a farm to update it.
+	FarmUpdateTransactionController struct {
+		// Registry is used to look up the current owner of the farm,
+		// which has to sign the farm update.
+		Registry FarmReadRegistry
+	}
+
+	// NodeLinkTransactionController defines a tfchain-specific transaction controller,
+	// for a transaction type reserved at type 0xc4. It allows a node and a farm manager
+	// to link the node to a farm, or to unlink it.
+	NodeLinkTransactionController struct{}
+
+	// FarmingRewardTransactionController defines a tfchain-specific transaction controller,
+	// for a transaction type reserved at type 0xc5. It allows the Coin Minters to pay out farming rewards.
+	FarmingRewardTransactionController struct {
+		// MintConditionGetter is used to get the mint condition,
+		// which has to be fulfilled in order to pay out farming rewards.
+		MintConditionGetter minting.MintConditionGetter
+	}
+)
+
+var (
+	// ensure at compile time that FarmerAuthorizationTransactionController
+	// implements the desired interfaces
+	_ types.TransactionController                = FarmerAuthorizationTransactionController{}
+	_ types.TransactionExtensionSigner           = FarmerAuthorizationTransactionController{}
+	_ types.TransactionSignatureHasher           = FarmerAuthorizationTransactionController{}
+	_ types.TransactionIDEncoder                 = FarmerAuthorizationTransactionController{}
+	_ types.TransactionCommonExtensionDataGetter = FarmerAuthorizationTransactionController{}
+
+	// ensure at compile time that CapacityRegistrationTransactionController
+	// implements the desired interfaces
+	_ types.TransactionController                = CapacityRegistrationTransactionController{}
+	_ types.TransactionExtensionSigner           = CapacityRegistrationTransactionController{}
+	_ types.TransactionSignatureHasher           = CapacityRegistrationTransactionController{}
+	_ types.TransactionIDEncoder                 = CapacityRegistrationTransactionController{}
+	_ types.TransactionCommonExtensionDataGetter = CapacityRegistrationTransactionController{}
+
+	// ensure at compile time that FarmRegistrationTransactionController
+	// implements the desired interfaces
+	_ types.TransactionController                = FarmRegistrationTransactionController{}
+	_ types.TransactionExtensionSigner           = FarmRegistrationTransactionController{}
+	_ types.TransactionSignatureHasher           = FarmRegistrationTransactionController{}
+	_ types.TransactionIDEncoder                 = FarmRegistrationTransactionController{}
+	_ types.TransactionCommonExtensionDataGetter = FarmRegistrationTransactionController{}
+
+	// ensure at compile time that FarmUpdateTransactionController
+	// implements the desired interfaces
+	_ types.TransactionController      = FarmUpdateTransactionController{}
+	_ types.TransactionExtensionSigner = FarmUpdateTransactionController{}
+	_ types.TransactionSignatureHasher = FarmUpdateTransactionController{}
+	_ types.TransactionIDEncoder       = FarmUpdateTransactionController{}
+
+	// ensure at compile time that NodeLinkTransactionController
+	// implements the desired interfaces
+	_ types.TransactionController                = NodeLinkTransactionController{}
+	_ types.TransactionExtensionSigner           = NodeLinkTransactionController{}
+	_ types.TransactionSignatureHasher           = NodeLinkTransactionController{}
+	_ types.TransactionIDEncoder                 = NodeLinkTransactionController{}
+	_ types.TransactionCommonExtensionDataGetter = NodeLinkTransactionController{}
+
+	// ensure at compile time that FarmingRewardTransactionController
+	// implements the desired interfaces
+	_ types.TransactionController      = FarmingRewardTransactionController{}
+	_ types.TransactionExtensionSigner = FarmingRewardTransactionController{}
+	_ types.TransactionSignatureHasher = FarmingRewardTransactionController{}
+	_ types.TransactionIDEncoder       = FarmingRewardTransactionController{}
+)
+
+// FarmerAuthorizationTransactionController
+
+// EncodeTransactionData implements TransactionController.EncodeTransactionData
+func (fatc FarmerAuthorizationTransactionController) EncodeTransactionData(w io.Writer, txData types.TransactionData) error {
+	fatx, err := FarmerAuthorizationTransactionFromTransactionData(txData)
+	if err != nil {
+		return fmt.Errorf("failed to convert txData to a FarmerAuthorizationTx: %v", err)
+	}
+	return rivbin.NewEncoder(w).Encode(fatx)
+}
+
+// DecodeTransactionData implements TransactionController.DecodeTransactionData
+func (fatc FarmerAuthorizationTransactionController) DecodeTransactionData(r io.Reader) (types.TransactionData, error) {
+	var fatx FarmerAuthorizationTransaction
+	err := rivbin.NewDecoder(r).Decode(&fatx)
+	if err != nil {
+		return types.TransactionData{}, fmt.Errorf(
+			"failed to binary-decode tx as a FarmerAuthorizationTx: %v", err)
+	}
+	// return farmer authorization tx as regular tfchain tx data
+	return fatx.TransactionData(), nil
+}
+
+// JSONEncodeTransactionData implements TransactionController.JSONEncodeTransactionData
+func (fatc FarmerAuthorizationTransactionController) JSONEncodeTransactionData(txData types.TransactionData) ([]byte, error) {
+	fatx, err := FarmerAuthorizationTransactionFromTransactionData(txData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert txData to a FarmerAuthorizationTx: %v", err)
+	}
+	return json.Marshal(fatx)
+}
+
+// JSONDecodeTransactionData implements TransactionController.JSONDecodeTransactionData
+func (fatc FarmerAuthorizationTransactionController) JSONDecodeTransactionData(data []byte) (types.TransactionData, error) {
+	var fatx FarmerAuthorizationTransaction
+	err := json.Unmarshal(data, &fatx)
+	if err != nil {
+		return types.TransactionData{}, fmt.Errorf(
+			"failed to json-decode tx as a FarmerAuthorizationTx: %v", err)
+	}
+	// return farmer authorization tx as regular tfchain tx data
+	return fatx.TransactionData(), nil
+}
+
+// SignExtension implements TransactionExtensionSigner.SignExtension
+func (fatc FarmerAuthorizationTransactionController) SignExtension(extension interface{}, sign func(*types.UnlockFulfillmentProxy, types.UnlockConditionProxy, ...interface{}) error) (interface{}, error) {
+	// (tx) extension (data) is expected to be a pointer to a valid FarmerAuthorizationTransactionExtension
+	faTxExtension, ok := extension.(*FarmerAuthorizationTransactionExtension)
+	if !ok {
+		return nil, errors.New("invalid extension data for a FarmerAuthorizationTransaction")
+	}
+	mintCondition, err := fatc.MintConditionGetter.GetActiveMintCondition()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the active mint condition: %v", err)
+	}
+	err = sign(&faTxExtension.MintFulfillment, mintCondition)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign mint fulfillment of farmer authorization tx: %v", err)
+	}
+	return faTxExtension, nil
+}
+
+// SignatureHash implements TransactionSignatureHasher.SignatureHash
+func (fatc FarmerAuthorizationTransactionController) SignatureHash(t types.Transaction, extraObjects ...interface{}) (crypto.Hash, error) {
+	fatx, err := FarmerAuthorizationTransactionFromTransaction(t)
+	if err != nil {
+		return crypto.Hash{}, fmt.Errorf("failed to use tx as a farmer authorization tx: %v", err)
+	}
+
+	h := crypto.NewHash()
+	enc := rivbin.NewEncoder(h)
+
+	enc.EncodeAll(
+		t.Version,
+		SpecifierFarmerAuthorizationTransaction,
+		fatx.Nonce,
+	)
+
+	if len(extraObjects) > 0 {
+		enc.EncodeAll(extraObjects...)
+	}
+
+	enc.EncodeAll(
+		fatx.AuthAddresses,
+		fatx.DeauthAddresses,
+		fatx.MinerFees,
+		fatx.ArbitraryData,
+	)
+
+	var hash crypto.Hash
+	h.Sum(hash[:0])
+	return hash, nil
+}
+
+// EncodeTransactionIDInput implements TransactionIDEncoder.EncodeTransactionIDInput
+func (fatc FarmerAuthorizationTransactionController) EncodeTransactionIDInput(w io.Writer, txData types.TransactionData) error {
+	fatx, err := FarmerAuthorizationTransactionFromTransactionData(txData)
+	if err != nil {
+		return fmt.Errorf("failed to convert txData to a FarmerAuthorizationTx: %v", err)
+	}
+	return rivbin.NewEncoder(w).EncodeAll(SpecifierFarmerAuthorizationTransaction, fatx)
+}
+
+// GetCommonExtensionData implements TransactionCommonExtensionDataGetter.GetCommonExtensionData,
+// such that the explorer links the transaction to the (de)authorized farmer addresses.
+func (fatc FarmerAuthorizationTransactionController) GetCommonExtensionData(extension interface{}) (types.CommonTransactionExtensionData, error) {
+	faTxExtension, ok := extension.(*FarmerAuthorizationTransactionExtension)
+	if !ok {
+		return types.CommonTransactionExtensionData{}, errors.New("invalid extension data for a FarmerAuthorizationTransaction")
+	}
+	conditions := make([]types.UnlockConditionProxy, 0, len(faTxExtension.AuthAddresses)+len(faTxExtension.DeauthAddresses))
+	for _, uh := range faTxExtension.AuthAddresses {
+		conditions = append(conditions, types.NewCondition(types.NewUnlockHashCondition(uh)))
+	}
+	for _, uh := range faTxExtension.DeauthAddresses {
+		conditions = append(conditions, types.NewCondition(types.NewUnlockHashCondition(uh)))
+	}
+	return types.CommonTransactionExtensionData{
+		UnlockConditions: conditions,
+	}, nil
+}
+
+// CapacityRegistrationTransactionController
+
+// EncodeTransactionData implements TransactionController.EncodeTransactionData
+func (crtc CapacityRegistrationTransactionController) EncodeTransactionData(w io.Writer, txData types.TransactionData) error {
+	crtx, err := CapacityRegistrationTransactionFromTransactionData(txData)
+	if err != nil {
+		return fmt.Errorf("failed to convert txData to a CapacityRegistrationTx: %v", err)
+	}
+	return rivbin.NewEncoder(w).Encode(crtx)
+}
+
+// DecodeTransactionData implements TransactionController.DecodeTransactionData
+func (crtc CapacityRegistrationTransactionController) DecodeTransactionData(r io.Reader) (types.TransactionData, error) {
+	var crtx CapacityRegistrationTransaction
+	err := rivbin.NewDecoder(r).Decode(&crtx)
+	if err != nil {
+		return types.TransactionData{}, fmt.Errorf(
+			"failed to binary-decode tx as a CapacityRegistrationTx: %v", err)
+	}
+	// return capacity registration tx as regular tfchain tx data
+	return crtx.TransactionData(), nil
+}
+
+// JSONEncodeTransactionData implements TransactionController.JSONEncodeTransactionData
+func (crtc CapacityRegistrationTransactionController) JSONEncodeTransactionData(txData types.TransactionData) ([]byte, error) {
+	crtx, err := CapacityRegistrationTransactionFromTransactionData(txData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert txData to a CapacityRegistrationTx: %v", err)
+	}
+	return json.Marshal(crtx)
+}
+
+// JSONDecodeTransactionData implements TransactionController.JSONDecodeTransactionData
+func (crtc CapacityRegistrationTransactionController) JSONDecodeTransactionData(data []byte) (types.TransactionData, error) {
+	var crtx CapacityRegistrationTransaction
+	err := json.Unmarshal(data, &crtx)
+	if err != nil {
+		return types.TransactionData{}, fmt.Errorf(
+			"failed to json-decode tx as a CapacityRegistrationTx: %v", err)
+	}
+	// return capacity registration tx as regular tfchain tx data
+	return crtx.TransactionData(), nil
+}
+
+// SignExtension implements TransactionExtensionSigner.SignExtension,
+// signing as the node and/or the manager, depending on the keys available to the signer.
+func (crtc CapacityRegistrationTransactionController) SignExtension(extension interface{}, sign func(*types.UnlockFulfillmentProxy, types.UnlockConditionProxy, ...interface{}) error) (interface{}, error) {
+	// (tx) extension (data) is expected to be a pointer to a valid CapacityRegistrationTransactionExtension
+	crTxExtension, ok := extension.(*CapacityRegistrationTransactionExtension)
+	if !ok {
+		return nil, errors.New("invalid extension data for a CapacityRegistrationTransaction")
+	}
+
+	// sign as the node
+	uh, err := types.NewPubKeyUnlockHash(crTxExtension.Node)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare the signing (as the node) of the capacity registration tx: %v", err)
+	}
+	fulfillment := types.NewFulfillment(types.NewSingleSignatureFulfillment(crTxExtension.Node))
+	err = sign(&fulfillment, types.NewCondition(types.NewUnlockHashCondition(uh)), CapacityRegistrationSignatureSpecifierNode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign (as the node) the capacity registration tx: %v", err)
+	}
+	signature := fulfillment.Fulfillment.(*types.SingleSignatureFulfillment).Signature
+	if len(signature) > 0 { // extract signature, only if we actually signed
+		crTxExtension.NodeSignature = signature
+	}
+
+	// (or) sign as the manager
+	err = sign(&crTxExtension.ManagerFulfillment, types.NewCondition(types.NewUnlockHashCondition(crTxExtension.Manager)), CapacityRegistrationSignatureSpecifierManager)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign (as the manager) the capacity registration tx: %v", err)
+	}
+	return crTxExtension, nil
+}
+
+// SignatureHash implements TransactionSignatureHasher.SignatureHash
+func (crtc CapacityRegistrationTransactionController) SignatureHash(t types.Transaction, extraObjects ...interface{}) (crypto.Hash, error) {
+	crtx, err := CapacityRegistrationTransactionFromTransaction(t)
+	if err != nil {
+		return crypto.Hash{}, fmt.Errorf("failed to use tx as a capacity registration tx: %v", err)
+	}
+
+	h := crypto.NewHash()
+	enc := rivbin.NewEncoder(h)
+
+	enc.EncodeAll(
+		t.Version,
+		SpecifierCapacityRegistrationTransaction,
+		crtx.FarmID,
+		crtx.Node,
+		crtx.Manager,
+		crtx.Capacity,
+	)
+
+	if len(extraObjects) > 0 {
+		enc.EncodeAll(extraObjects...)
+	}
+
+	enc.Encode(len(crtx.CoinInputs))
+	for _, ci := range crtx.CoinInputs {
+		enc.Encode(ci.ParentID)
+	}
+	enc.EncodeAll(
+		crtx.CoinOutputs,
+		crtx.MinerFees,
+		crtx.ArbitraryData,
+	)
+
+	var hash crypto.Hash
+	h.Sum(hash[:0])
+	return hash, nil
+}
+
+// EncodeTransactionIDInput implements TransactionIDEncoder.EncodeTransactionIDInput
+func (crtc CapacityRegistrationTransactionController) EncodeTransactionIDInput(w io.Writer, txData types.TransactionData) error {
+	crtx, err := CapacityRegistrationTransactionFromTransactionData(txData)
+	if err != nil {
+		return fmt.Errorf("failed to convert txData to a CapacityRegistrationTx: %v", err)
+	}
+	return rivbin.NewEncoder(w).EncodeAll(SpecifierCapacityRegistrationTransaction, crtx)
+}
+
+// GetCommonExtensionData implements TransactionCommonExtensionDataGetter.GetCommonExtensionData,
+// such that the explorer links the transaction to the manager as well as the (address of the) node.
+func (crtc CapacityRegistrationTransactionController) GetCommonExtensionData(extension interface{}) (types.CommonTransactionExtensionData, error) {
+	crTxExtension, ok := extension.(*CapacityRegistrationTransactionExtension)
+	if !ok {
+		return types.CommonTransactionExtensionData{}, errors.New("invalid extension data for a CapacityRegistrationTransaction")
+	}
+	conditions := []types.UnlockConditionProxy{
+		types.NewCondition(types.NewUnlockHashCondition(crTxExtension.Manager)),
+	}
+	if uh, err := types.NewPubKeyUnlockHash(crTxExtension.Node); err == nil {
+		conditions = append(conditions, types.NewCondition(types.NewUnlockHashCondition(uh)))
+	}
+	return types.CommonTransactionExtensionData{
+		UnlockConditions: conditions,
+	}, nil
+}
+
+// FarmRegistrationTransactionController
+
+// EncodeTransactionData implements TransactionController.EncodeTransactionData
+func (frtc FarmRegistrationTransactionController) EncodeTransactionData(w io.Writer, txData types.TransactionData) error {
+	frtx, err := FarmRegistrationTransactionFromTransactionData(txData)
+	if err != nil {
+		return fmt.Errorf("failed to convert txData to a FarmRegistrationTx: %v", err)
+	}
+	return rivbin.NewEncoder(w).Encode(frtx)
+}
+
+// DecodeTransactionData implements TransactionController.DecodeTransactionData
+func (frtc FarmRegistrationTransactionController) DecodeTransactionData(r io.Reader) (types.TransactionData, error) {
+	var frtx FarmRegistrationTransaction
+	err := rivbin.NewDecoder(r).Decode(&frtx)
+	if err != nil {
+		return types.TransactionData{}, fmt.Errorf(
+			"failed to binary-decode tx as a FarmRegistrationTx: %v", err)
+	}
+	// return farm registration tx as regular tfchain tx data
+	return frtx.TransactionData(), nil
+}
+
+// JSONEncodeTransactionData implements TransactionController.JSONEncodeTransactionData
+func (frtc FarmRegistrationTransactionController) JSONEncodeTransactionData(txData types.TransactionData) ([]byte, error) {
+	frtx, err := FarmRegistrationTransactionFromTransactionData(txData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert txData to a FarmRegistrationTx: %v", err)
+	}
+	return json.Marshal(frtx)
+}
+
+// JSONDecodeTransactionData implements TransactionController.JSONDecodeTransactionData
+func (frtc FarmRegistrationTransactionController) JSONDecodeTransactionData(data []byte) (types.TransactionData, error) {
+	var frtx FarmRegistrationTransaction
+	err := json.Unmarshal(data, &frtx)
+	if err != nil {
+		return types.TransactionData{}, fmt.Errorf(
+			"failed to json-decode tx as a FarmRegistrationTx: %v", err)
+	}
+	// return farm registration tx as regular tfchain tx data
+	return frtx.TransactionData(), nil
+}
+
+// SignExtension implements TransactionExtensionSigner.SignExtension
+func (frtc FarmRegistrationTransactionController) SignExtension(extension interface{}, sign func(*types.UnlockFulfillmentProxy, types.UnlockConditionProxy, ...interface{}) error) (interface{}, error) {
+	// (tx) extension (data) is expected to be a pointer to a valid FarmRegistrationTransactionExtension
+	frTxExtension, ok := extension.(*FarmRegistrationTransactionExtension)
+	if !ok {
+		return nil, errors.New("invalid extension data for a FarmRegistrationTransaction")
+	}
+	err := sign(&frTxExtension.OwnerFulfillment, frTxExtension.Owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign owner fulfillment of farm registration tx: %v", err)
+	}
+	return frTxExtension, nil
+}
+
+// SignatureHash implements TransactionSignatureHasher.SignatureHash
+func (frtc FarmRegistrationTransactionController) SignatureHash(t types.Transaction, extraObjects ...interface{}) (crypto.Hash, error) {
+	frtx, err := FarmRegistrationTransactionFromTransaction(t)
+	if err != nil {
+		return crypto.Hash{}, fmt.Errorf("failed to use tx as a farm registration tx: %v", err)
+	}
+
+	h := crypto.NewHash()
+	enc := rivbin.NewEncoder(h)
+
+	enc.EncodeAll(
+		t.Version,
+		SpecifierFarmRegistrationTransaction,
+		frtx.Name,
+		frtx.Owner,
+		frtx.Managers,
+		frtx.Location,
+		frtx.PayoutAddress,
+	)
+
+	if len(extraObjects) > 0 {
+		enc.EncodeAll(extraObjects...)
+	}
+
+	enc.Encode(len(frtx.CoinInputs))
+	for _, ci := range frtx.CoinInputs {
+		enc.Encode(ci.ParentID)
+	}
+	enc.EncodeAll(
+		frtx.CoinOutputs,
+		frtx.MinerFees,
+		frtx.ArbitraryData,
+	)
+
+	var hash crypto.Hash
+	h.Sum(hash[:0])
+	return hash, nil
+}
+
+// EncodeTransactionIDInput implements TransactionIDEncoder.EncodeTransactionIDInput
+func (frtc FarmRegistrationTransactionController) EncodeTransactionIDInput(w io.Writer, txData types.TransactionData) error {
+	frtx, err := FarmRegistrationTransactionFromTransactionData(txData)
+	if err != nil {
+		return fmt.Errorf("failed to convert txData to a FarmRegistrationTx: %v", err)
+	}
+	return rivbin.NewEncoder(w).EncodeAll(SpecifierFarmRegistrationTransaction, frtx)
+}
+
+// GetCommonExtensionData implements TransactionCommonExtensionDataGetter.GetCommonExtensionData,
+// such that the explorer links the transaction to the owner of the farm.
+func (frtc FarmRegistrationTransactionController) GetCommonExtensionData(extension interface{}) (types.CommonTransactionExtensionData, error) {
+	frTxExtension, ok := extension.(*FarmRegistrationTransactionExtension)
+	if !ok {
+		return types.CommonTransactionExtensionData{}, errors.New("invalid extension data for a FarmRegistrationTransaction")
+	}
+	return types.CommonTransactionExtensionData{
+		UnlockConditions: []types.UnlockConditionProxy{frTxExtension.Owner},
+	}, nil
+}
+
+// FarmUpdateTransactionController
+
+// EncodeTransactionData implements TransactionController.EncodeTransactionData
+func (futc FarmUpdateTransactionController) EncodeTransactionData(w io.Writer, txData types.TransactionData) error {
+	futx, err := FarmUpdateTransactionFromTransactionData(txData)
+	if err != nil {
+		return fmt.Errorf("failed to convert txData to a FarmUpdateTx: %v", err)
+	}
+	return rivbin.NewEncoder(w).Encode(futx)
+}
+
+// DecodeTransactionData implements TransactionController.DecodeTransactionData
+func (futc FarmUpdateTransactionController) DecodeTransactionData(r io.Reader) (types.TransactionData, error) {
+	var futx FarmUpdateTransaction
+	err := rivbin.NewDecoder(r).Decode(&futx)
+	if err != nil {
+		return types.TransactionData{}, fmt.Errorf(
+			"failed to binary-decode tx as a FarmUpdateTx: %v", err)
+	}
+	// return farm update tx as regular tfchain tx data
+	return futx.TransactionData(), nil
+}
+
+// JSONEncodeTransactionData implements TransactionController.JSONEncodeTransactionData
+func (futc FarmUpdateTransactionController) JSONEncodeTransactionData(txData types.TransactionData) ([]byte, error) {
+	futx, err := FarmUpdateTransactionFromTransactionData(txData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert txData to a FarmUpdateTx: %v", err)
+	}
+	return json.Marshal(futx)
+}
+
+// JSONDecodeTransactionData implements TransactionController.JSONDecodeTransactionData
+func (futc FarmUpdateTransactionController) JSONDecodeTransactionData(data []byte) (types.TransactionData, error) {
+	var futx FarmUpdateTransaction
+	err := json.Unmarshal(data, &futx)
+	if err != nil {
+		return types.TransactionData{}, fmt.Errorf(
+			"failed to json-decode tx as a FarmUpdateTx: %v", err)
+	}
+	// return farm update tx as regular tfchain tx data
+	return futx.TransactionData(), nil
+}
+
+// SignExtension implements TransactionExtensionSigner.SignExtension
+func (futc FarmUpdateTransactionController) SignExtension(extension interface{}, sign func(*types.UnlockFulfillmentProxy, types.UnlockConditionProxy, ...interface{}) error) (interface{}, error) {
+	// (tx) extension (data) is expected to be a pointer to a valid FarmUpdateTransactionExtension
+	fuTxExtension, ok := extension.(*FarmUpdateTransactionExtension)
+	if !ok {
+		return nil, errors.New("invalid extension data for a FarmUpdateTransaction")
+	}
+	// the current owner of the farm has to sign the update
+	record, err := futc.Registry.GetFarm(fuTxExtension.FarmID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get farm %s: %v", fuTxExtension.FarmID.String(), err)
+	}
+	err = sign(&fuTxExtension.OwnerFulfillment, record.Owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign owner fulfillment of farm update tx: %v", err)
+	}
+	return fuTxExtension, nil
+}
+
+// SignatureHash implements TransactionSignatureHasher.SignatureHash
+func (futc FarmUpdateTransactionController) SignatureHash(t types.Transaction, extraObjects ...interface{}) (crypto.Hash, error) {
+	futx, err := FarmUpdateTransactionFromTransaction(t)
+	if err != nil {
+		return crypto.Hash{}, fmt.Errorf("failed to use tx as a farm update tx: %v", err)
+	}
+
+	h := crypto.NewHash()
+	enc := rivbin.NewEncoder(h)
+
+	enc.EncodeAll(
+		t.Version,
+		SpecifierFarmUpdateTransaction,
+	)
+	futx.encodeUpdates(enc)
+
+	if len(extraObjects) > 0 {
+		enc.EncodeAll(extraObjects...)
+	}
+
+	enc.Encode(len(futx.CoinInputs))
+	for _, ci := range futx.CoinInputs {
+		enc.Encode(ci.ParentID)
+	}
+	enc.EncodeAll(
+		futx.CoinOutputs,
+		futx.MinerFees,
+		futx.ArbitraryData,
+	)
+
+	var hash crypto.Hash
+	h.Sum(hash[:0])
+	return hash, nil
+}
+
+// EncodeTransactionIDInput implements TransactionIDEncoder.EncodeTransactionIDInput
+func (futc FarmUpdateTransactionController) EncodeTransactionIDInput(w io.Writer, txData types.TransactionData) error {
+	futx, err := FarmUpdateTransactionFromTransactionData(txData)
+	if err != nil {
+		return fmt.Errorf("failed to convert txData to a FarmUpdateTx: %v", err)
+	}
+	return rivbin.NewEncoder(w).EncodeAll(SpecifierFarmUpdateTransaction, futx)
+}
+
+// NodeLinkTransactionController
+
+// EncodeTransactionData implements TransactionController.EncodeTransactionData
+func (nltc NodeLinkTransactionController) EncodeTransactionData(w io.Writer, txData types.TransactionData) error {
+	nltx, err := NodeLinkTransactionFromTransactionData(txData)
+	if err != nil {
+		return fmt.Errorf("failed to convert txData to a NodeLinkTx: %v", err)
+	}
+	return rivbin.NewEncoder(w).Encode(nltx)
+}
+
+// DecodeTransactionData implements TransactionController.DecodeTransactionData
+func (nltc NodeLinkTransactionController) DecodeTransactionData(r io.Reader) (types.TransactionData, error) {
+	var nltx NodeLinkTransaction
+	err := rivbin.NewDecoder(r).Decode(&nltx)
+	if err != nil {
+		return types.TransactionData{}, fmt.Errorf(
+			"failed to binary-decode tx as a NodeLinkTx: %v", err)
+	}
+	// return node link tx as regular tfchain tx data
+	return nltx.TransactionData(), nil
+}
+
+// JSONEncodeTransactionData implements TransactionController.JSONEncodeTransactionData
+func (nltc NodeLinkTransactionController) JSONEncodeTransactionData(txData types.TransactionData) ([]byte, error) {
+	nltx, err := NodeLinkTransactionFromTransactionData(txData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert txData to a NodeLinkTx: %v", err)
+	}
+	return json.Marshal(nltx)
+}
+
+// JSONDecodeTransactionData implements TransactionController.JSONDecodeTransactionData
+func (nltc NodeLinkTransactionController) JSONDecodeTransactionData(data []byte) (types.TransactionData, error) {
+	var nltx NodeLinkTransaction
+	err := json.Unmarshal(data, &nltx)
+	if err != nil {
+		return types.TransactionData{}, fmt.Errorf(
+			"failed to json-decode tx as a NodeLinkTx: %v", err)
+	}
+	// return node link tx as regular tfchain tx data
+	return nltx.TransactionData(), nil
+}
+
+// SignExtension implements TransactionExtensionSigner.SignExtension,
+// signing as the node and/or the manager, depending on the keys available to the signer.
+func (nltc NodeLinkTransactionController) SignExtension(extension interface{}, sign func(*types.UnlockFulfillmentProxy, types.UnlockConditionProxy, ...interface{}) error) (interface{}, error) {
+	// (tx) extension (data) is expected to be a pointer to a valid NodeLinkTransactionExtension
+	nlTxExtension, ok := extension.(*NodeLinkTransactionExtension)
+	if !ok {
+		return nil, errors.New("invalid extension data for a NodeLinkTransaction")
+	}
+
+	// sign as the node
+	uh, err := types.NewPubKeyUnlockHash(nlTxExtension.Node)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare the signing (as the node) of the node link tx: %v", err)
+	}
+	fulfillment := types.NewFulfillment(types.NewSingleSignatureFulfillment(nlTxExtension.Node))
+	err = sign(&fulfillment, types.NewCondition(types.NewUnlockHashCondition(uh)), NodeLinkSignatureSpecifierNode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign (as the node) the node link tx: %v", err)
+	}
+	signature := fulfillment.Fulfillment.(*types.SingleSignatureFulfillment).Signature
+	if len(signature) > 0 { // extract signature, only if we actually signed
+		nlTxExtension.NodeSignature = signature
+	}
+
+	// (or) sign as the manager
+	err = sign(&nlTxExtension.ManagerFulfillment, types.NewCondition(types.NewUnlockHashCondition(nlTxExtension.Manager)), NodeLinkSignatureSpecifierManager)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign (as the manager) the node link tx: %v", err)
+	}
+	return nlTxExtension, nil
+}
+
+// SignatureHash implements TransactionSignatureHasher.SignatureHash
+func (nltc NodeLinkTransactionController) SignatureHash(t types.Transaction, extraObjects ...interface{}) (crypto.Hash, error) {
+	nltx, err := NodeLinkTransactionFromTransaction(t)
+	if err != nil {
+		return crypto.Hash{}, fmt.Errorf("failed to use tx as a node link tx: %v", err)
+	}
+
+	h := crypto.NewHash()
+	enc := rivbin.NewEncoder(h)
+
+	enc.EncodeAll(
+		t.Version,
+		SpecifierNodeLinkTransaction,
+		nltx.Node,
+		nltx.FarmID,
+		nltx.Manager,
+	)
+
+	if len(extraObjects) > 0 {
+		enc.EncodeAll(extraObjects...)
+	}
+
+	enc.Encode(len(nltx.CoinInputs))
+	for _, ci := range nltx.CoinInputs {
+		enc.Encode(ci.ParentID)
+	}
+	enc.EncodeAll(
+		nltx.CoinOutputs,
+		nltx.MinerFees,
+		nltx.ArbitraryData,
+	)
+
+	var hash crypto.Hash
+	h.Sum(hash[:0])
+	return hash, nil
+}
+
+// EncodeTransactionIDInput implements TransactionIDEncoder.EncodeTransactionIDInput
+func (nltc NodeLinkTransactionController) EncodeTransactionIDInput(w io.Writer, txData types.TransactionData) error {
+	nltx, err := NodeLinkTransactionFromTransactionData(txData)
+	if err != nil {
+		return fmt.Errorf("failed to convert txData to a NodeLinkTx: %v", err)
+	}
+	return rivbin.NewEncoder(w).EncodeAll(SpecifierNodeLinkTransaction, nltx)
+}
+
+// GetCommonExtensionData implements TransactionCommonExtensionDataGetter.GetCommonExtensionData,
+// such that the explorer links the transaction to the manager as well as the (address of the) node.
+func (nltc NodeLinkTransactionController) GetCommonExtensionData(extension interface{}) (types.CommonTransactionExtensionData, error) {
+	nlTxExtension, ok := extension.(*NodeLinkTransactionExtension)
+	if !ok {
+		return types.CommonTransactionExtensionData{}, errors.New("invalid extension data for a NodeLinkTransaction")
+	}
+	conditions := []types.UnlockConditionProxy{
+		types.NewCondition(types.NewUnlockHashCondition(nlTxExtension.Manager)),
+	}
+	if uh, err := types.NewPubKeyUnlockHash(nlTxExtension.Node); err == nil {
+		conditions = append(conditions, types.NewCondition(types.NewUnlockHashCondition(uh)))
+	}
+	return types.CommonTransactionExtensionData{
+		UnlockConditions: conditions,
+	}, nil
+}
+
+// FarmingRewardTransactionController
+
+// EncodeTransactionData implements TransactionController.EncodeTransactionData
+func (frtc FarmingRewardTransactionController) EncodeTransactionData(w io.Writer, txData types.TransactionData) error {
+	frtx, err := FarmingRewardTransactionFromTransactionData(txData)
+	if err != nil {
+		return fmt.Errorf("failed to convert txData to a FarmingRewardTx: %v", err)
+	}
+	return rivbin.NewEncoder(w).Encode(frtx)
+}
+
+// DecodeTransactionData implements TransactionController.DecodeTransactionData
+func (frtc FarmingRewardTransactionController) DecodeTransactionData(r io.Reader) (types.TransactionData, error) {
+	var frtx FarmingRewardTransaction
+	err := rivbin.NewDecoder(r).Decode(&frtx)
+	if err != nil {
+		return types.TransactionData{}, fmt.Errorf(
+			"failed to binary-decode tx as a FarmingRewardTx: %v", err)
+	}
+	// return farming reward tx as regular tfchain tx data
+	return frtx.TransactionData(), nil
+}
+
+// JSONEncodeTransactionData implements TransactionController.JSONEncodeTransactionData
+func (frtc FarmingRewardTransactionController) JSONEncodeTransactionData(txData types.TransactionData) ([]byte, error) {
+	frtx, err := FarmingRewardTransactionFromTransactionData(txData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert txData to a FarmingRewardTx: %v", err)
+	}
+	return json.Marshal(frtx)
+}
+
+// JSONDecodeTransactionData implements TransactionController.JSONDecodeTransactionData
+func (frtc FarmingRewardTransactionController) JSONDecodeTransactionData(data []byte) (types.TransactionData, error) {
+	var frtx FarmingRewardTransaction
+	err := json.Unmarshal(data, &frtx)
+	if err != nil {
+		return types.TransactionData{}, fmt.Errorf(
+			"failed to json-decode tx as a FarmingRewardTx: %v", err)
+	}
+	// return farming reward tx as regular tfchain tx data
+	return frtx.TransactionData(), nil
+}
+
+// SignExtension implements TransactionExtensionSigner.SignExtension
+func (frtc FarmingRewardTransactionController) SignExtension(extension interface{}, sign func(*types.UnlockFulfillmentProxy, types.UnlockConditionProxy, ...interface{}) error) (interface{}, error) {
+	// (tx) extension (data) is expected to be a pointer to a valid FarmingRewardTransactionExtension
+	frTxExtension, ok := extension.(*FarmingRewardTransactionExtension)
+	if !ok {
+		return nil, errors.New("invalid extension data for a FarmingRewardTransaction")
+	}
+	mintCondition, err := frtc.MintConditionGetter.GetActiveMintCondition()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the active mint condition: %v", err)
+	}
+	err = sign(&frTxExtension.MintFulfillment, mintCondition)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign mint fulfillment of farming reward tx: %v", err)
+	}
+	return frTxExtension, nil
+}
+
+// SignatureHash implements TransactionSignatureHasher.SignatureHash
+func (frtc FarmingRewardTransactionController) SignatureHash(t types.Transaction, extraObjects ...interface{}) (crypto.Hash, error) {
+	frtx, err := FarmingRewardTransactionFromTransaction(t)
+	if err != nil {
+		return crypto.Hash{}, fmt.Errorf("failed to use tx as a farming reward tx: %v", err)
+	}
+
+	h := crypto.NewHash()
+	enc := rivbin.NewEncoder(h)
+
+	enc.EncodeAll(
+		t.Version,
+		SpecifierFarmingRewardTransaction,
+		frtx.Nonce,
+	)
+
+	if len(extraObjects) > 0 {
+		enc.EncodeAll(extraObjects...)
+	}
+
+	enc.EncodeAll(
+		frtx.SnapshotHeight,
+		frtx.Payouts,
+		frtx.MinerFees,
+		frtx.ArbitraryData,
+	)
+
+	var hash crypto.Hash
+	h.Sum(hash[:0])
+	return hash, nil
+}
+
+// EncodeTransactionIDInput implements TransactionIDEncoder.EncodeTransactionIDInput
+func (frtc FarmingRewardTransactionController) EncodeTransactionIDInput(w io.Writer, txData types.TransactionData) error {
+	frtx, err := FarmingRewardTransactionFromTransactionData(txData)
+	if err != nil {
+		return fmt.Errorf("failed to convert txData to a FarmingRewardTx: %v", err)
+	}
+	return rivbin.NewEncoder(w).EncodeAll(SpecifierFarmingRewardTransaction, frtx)
+}
@@ -0,0 +1,733 @@
+package types
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/pkg/encoding/rivbin"
+	"github.com/threefoldtech/rivine/types"
+)
+
+func TestFarmerAuthorizationTransactionEncodingAndID(t *testing.T) {
+	types.RegisterTransactionVersion(TransactionVersionFarmerAuthorization, FarmerAuthorizationTransactionController{})
+	defer types.RegisterTransactionVersion(TransactionVersionFarmerAuthorization, nil)
+
+	fatx := FarmerAuthorizationTransaction{
+		Nonce: types.RandomTransactionNonce(),
+		AuthAddresses: []types.UnlockHash{
+			testAddress(t, "01b49da2ff193f46ee0fc684d7a6121a8b8e324144dffc7327471a4da79f1730960edcb2ce737f"),
+		},
+		DeauthAddresses: []types.UnlockHash{
+			testAddress(t, "017fda17489854109399aa8c1bfa6bdef40f93606744d95cc5055270d78b465e6acd263c96ab2b"),
+		},
+		MintFulfillment: testFulfillment(),
+		MinerFees:       []types.Currency{types.NewCurrency64(100000000)},
+		ArbitraryData:   []byte("new farmer"),
+	}
+	testTransactionEncodingAndID(t, fatx.Transaction())
+
+	ofatx, err := FarmerAuthorizationTransactionFromTransaction(fatx.Transaction())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ofatx.Nonce != fatx.Nonce || len(ofatx.AuthAddresses) != 1 || len(ofatx.DeauthAddresses) != 1 {
+		t.Fatal("unexpected farmer authorization transaction", ofatx, "!=", fatx)
+	}
+	if ofatx.AuthAddresses[0].Cmp(fatx.AuthAddresses[0]) != 0 || ofatx.DeauthAddresses[0].Cmp(fatx.DeauthAddresses[0]) != 0 {
+		t.Fatal("unexpected farmer addresses", ofatx, "!=", fatx)
+	}
+}
+
+func TestCapacityRegistrationTransactionEncodingAndID(t *testing.T) {
+	types.RegisterTransactionVersion(TransactionVersionCapacityRegistration, CapacityRegistrationTransactionController{})
+	defer types.RegisterTransactionVersion(TransactionVersionCapacityRegistration, nil)
+
+	crtx := CapacityRegistrationTransaction{
+		FarmID:             1,
+		Node:               testPublicKey(),
+		NodeSignature:      make([]byte, crypto.SignatureSize),
+		Capacity:           CapacityUnits{CRU: 4, MRU: 16, HRU: 2000, SRU: 250},
+		Manager:            testAddress(t, "01b49da2ff193f46ee0fc684d7a6121a8b8e324144dffc7327471a4da79f1730960edcb2ce737f"),
+		ManagerFulfillment: testFulfillment(),
+		CoinInputs: []types.CoinInput{
+			{
+				ParentID:    types.CoinOutputID(crypto.HashBytes([]byte("parent"))),
+				Fulfillment: testFulfillment(),
+			},
+		},
+		CoinOutputs: []types.CoinOutput{
+			{
+				Value:     types.NewCurrency64(4200000000),
+				Condition: types.NewCondition(types.NewUnlockHashCondition(testAddress(t, "017fda17489854109399aa8c1bfa6bdef40f93606744d95cc5055270d78b465e6acd263c96ab2b"))),
+			},
+		},
+		MinerFees: []types.Currency{types.NewCurrency64(100000000)},
+	}
+	testTransactionEncodingAndID(t, crtx.Transaction())
+
+	ocrtx, err := CapacityRegistrationTransactionFromTransaction(crtx.Transaction())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ocrtx.FarmID != crtx.FarmID || ocrtx.Manager.Cmp(crtx.Manager) != 0 || ocrtx.Node.String() != crtx.Node.String() ||
+		ocrtx.Capacity != crtx.Capacity || !bytes.Equal(ocrtx.NodeSignature, crtx.NodeSignature) {
+		t.Fatal("unexpected capacity registration transaction", ocrtx, "!=", crtx)
+	}
+}
+
+func TestFarmerAuthorizationTransactionFromTransactionData(t *testing.T) {
+	testCases := []struct {
+		TxData types.TransactionData
+		Valid  bool
+	}{
+		{types.TransactionData{}, false},
+		{types.TransactionData{Extension: &FarmerAuthorizationTransactionExtension{}}, false},
+		{types.TransactionData{
+			Extension:  &FarmerAuthorizationTransactionExtension{},
+			CoinInputs: []types.CoinInput{{}},
+			MinerFees:  []types.Currency{types.NewCurrency64(1)},
+		}, false},
+		{types.TransactionData{
+			Extension:         &FarmerAuthorizationTransactionExtension{},
+			BlockStakeOutputs: []types.BlockStakeOutput{{}},
+			MinerFees:         []types.Currency{types.NewCurrency64(1)},
+		}, false},
+		{types.TransactionData{
+			Extension: &FarmerAuthorizationTransactionExtension{},
+			MinerFees: []types.Currency{types.NewCurrency64(1)},
+		}, true},
+	}
+	for idx, testCase := range testCases {
+		_, err := FarmerAuthorizationTransactionFromTransactionData(testCase.TxData)
+		if testCase.Valid && err != nil {
+			t.Errorf("test case #%d: unexpected error: %v", idx, err)
+		} else if !testCase.Valid && err == nil {
+			t.Errorf("test case #%d: expected error, but none received", idx)
+		}
+	}
+}
+
+func TestCapacityRegistrationTransactionFromTransactionData(t *testing.T) {
+	testCases := []struct {
+		TxData types.TransactionData
+		Valid  bool
+	}{
+		{types.TransactionData{}, false},
+		{types.TransactionData{Extension: &CapacityRegistrationTransactionExtension{}}, false},
+		{types.TransactionData{
+			Extension: &CapacityRegistrationTransactionExtension{},
+			MinerFees: []types.Currency{types.NewCurrency64(1)},
+		}, false},
+		{types.TransactionData{
+			Extension:  &CapacityRegistrationTransactionExtension{},
+			CoinInputs: []types.CoinInput{{}},
+		}, false},
+		{types.TransactionData{
+			Extension:        &CapacityRegistrationTransactionExtension{},
+			CoinInputs:       []types.CoinInput{{}},
+			BlockStakeInputs: []types.BlockStakeInput{{}},
+			MinerFees:        []types.Currency{types.NewCurrency64(1)},
+		}, false},
+		{types.TransactionData{
+			Extension:  &CapacityRegistrationTransactionExtension{},
+			CoinInputs: []types.CoinInput{{}},
+			MinerFees:  []types.Currency{types.NewCurrency64(1)},
+		}, true},
+	}
+	for idx, testCase := range testCases {
+		_, err := CapacityRegistrationTransactionFromTransactionData(testCase.TxData)
+		if testCase.Valid && err != nil {
+			t.Errorf("test case #%d: unexpected error: %v", idx, err)
+		} else if !testCase.Valid && err == nil {
+			t.Errorf("test case #%d: expected error, but none received", idx)
+		}
+	}
+}
+
+func TestCapacityRegistrationTransactionUniqueSignatureHashes(t *testing.T) {
+	types.RegisterTransactionVersion(TransactionVersionCapacityRegistration, CapacityRegistrationTransactionController{})
+	defer types.RegisterTransactionVersion(TransactionVersionCapacityRegistration, nil)
+
+	crtx := CapacityRegistrationTransaction{
+		FarmID:             1,
+		Node:               testPublicKey(),
+		Capacity:           CapacityUnits{CRU: 4, MRU: 16, HRU: 2000, SRU: 250},
+		Manager:            testAddress(t, "01b49da2ff193f46ee0fc684d7a6121a8b8e324144dffc7327471a4da79f1730960edcb2ce737f"),
+		ManagerFulfillment: testFulfillment(),
+		CoinInputs:         []types.CoinInput{{Fulfillment: testFulfillment()}},
+		MinerFees:          []types.Currency{types.NewCurrency64(100000000)},
+	}
+	hashes := map[crypto.Hash]struct{}{}
+	addHash := func(tx types.Transaction, extraObjects ...interface{}) {
+		hash, err := tx.SignatureHash(extraObjects...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := hashes[hash]; ok {
+			t.Fatal("duplicate signature hash:", hash.String())
+		}
+		hashes[hash] = struct{}{}
+	}
+	// the node and manager sign a different hash
+	addHash(crtx.Transaction(), CapacityRegistrationSignatureSpecifierNode)
+	addHash(crtx.Transaction(), CapacityRegistrationSignatureSpecifierManager)
+	crtx.Capacity.SRU++
+	addHash(crtx.Transaction(), CapacityRegistrationSignatureSpecifierNode)
+	crtx.Node.Key[0] = 1
+	addHash(crtx.Transaction(), CapacityRegistrationSignatureSpecifierNode)
+	crtx.FarmID = 2
+	addHash(crtx.Transaction(), CapacityRegistrationSignatureSpecifierNode)
+	crtx.Manager = testAddress(t, "017fda17489854109399aa8c1bfa6bdef40f93606744d95cc5055270d78b465e6acd263c96ab2b")
+	addHash(crtx.Transaction(), CapacityRegistrationSignatureSpecifierNode)
+	crtx.CoinInputs[0].ParentID[0] = 1
+	addHash(crtx.Transaction(), CapacityRegistrationSignatureSpecifierNode)
+
+	// the signatures themselves are not part of the signature hash
+	hash, err := crtx.Transaction().SignatureHash(CapacityRegistrationSignatureSpecifierNode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	crtx.NodeSignature = make([]byte, crypto.SignatureSize)
+	crtx.ManagerFulfillment = types.NewFulfillment(types.NewSingleSignatureFulfillment(testPublicKey()))
+	if ohash, err := crtx.Transaction().SignatureHash(CapacityRegistrationSignatureSpecifierNode); err != nil || ohash != hash {
+		t.Fatal("signature hash is not expected to depend on the signatures:", hash.String(), "!=", ohash.String(), err)
+	}
+}
+
+func TestFarmRegistrationTransactionEncodingAndID(t *testing.T) {
+	types.RegisterTransactionVersion(TransactionVersionFarmRegistration, FarmRegistrationTransactionController{})
+	defer types.RegisterTransactionVersion(TransactionVersionFarmRegistration, nil)
+
+	owner := testAddress(t, "01b49da2ff193f46ee0fc684d7a6121a8b8e324144dffc7327471a4da79f1730960edcb2ce737f")
+	frtx := FarmRegistrationTransaction{
+		Name:  "my farm",
+		Owner: types.NewCondition(types.NewUnlockHashCondition(owner)),
+		Managers: []types.UnlockHash{
+			testAddress(t, "017fda17489854109399aa8c1bfa6bdef40f93606744d95cc5055270d78b465e6acd263c96ab2b"),
+		},
+		Location:         "Ghent, Belgium",
+		PayoutAddress:    owner,
+		OwnerFulfillment: testFulfillment(),
+		CoinInputs: []types.CoinInput{
+			{
+				ParentID:    types.CoinOutputID(crypto.HashBytes([]byte("parent"))),
+				Fulfillment: testFulfillment(),
+			},
+		},
+		MinerFees: []types.Currency{types.NewCurrency64(100000000)},
+	}
+	testTransactionEncodingAndID(t, frtx.Transaction())
+
+	ofrtx, err := FarmRegistrationTransactionFromTransaction(frtx.Transaction())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ofrtx.Name != frtx.Name || ofrtx.Location != frtx.Location || len(ofrtx.Managers) != 1 ||
+		ofrtx.Owner.UnlockHash().Cmp(owner) != 0 || ofrtx.PayoutAddress.Cmp(owner) != 0 {
+		t.Fatal("unexpected farm registration transaction", ofrtx, "!=", frtx)
+	}
+}
+
+func TestFarmUpdateTransactionEncodingAndID(t *testing.T) {
+	types.RegisterTransactionVersion(TransactionVersionFarmUpdate, FarmUpdateTransactionController{})
+	defer types.RegisterTransactionVersion(TransactionVersionFarmUpdate, nil)
+
+	futx := FarmUpdateTransaction{
+		FarmID:           42,
+		OwnerFulfillment: testFulfillment(),
+		CoinInputs: []types.CoinInput{
+			{
+				ParentID:    types.CoinOutputID(crypto.HashBytes([]byte("parent"))),
+				Fulfillment: testFulfillment(),
+			},
+		},
+		MinerFees: []types.Currency{types.NewCurrency64(100000000)},
+	}
+	// only a location update
+	location := "Cairo, Egypt"
+	futx.Location = &location
+	testTransactionEncodingAndID(t, futx.Transaction())
+
+	// all properties updated
+	owner := types.NewCondition(types.NewUnlockHashCondition(
+		testAddress(t, "017fda17489854109399aa8c1bfa6bdef40f93606744d95cc5055270d78b465e6acd263c96ab2b")))
+	payout := owner.UnlockHash()
+	futx.Name = "new farm"
+	futx.Owner = &owner
+	futx.ManagersToAdd = []types.UnlockHash{payout}
+	futx.ManagersToRemove = []types.UnlockHash{testAddress(t, "01b49da2ff193f46ee0fc684d7a6121a8b8e324144dffc7327471a4da79f1730960edcb2ce737f")}
+	futx.PayoutAddress = &payout
+	testTransactionEncodingAndID(t, futx.Transaction())
+
+	ofutx, err := FarmUpdateTransactionFromTransaction(futx.Transaction())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ofutx.FarmID != futx.FarmID || ofutx.Name != futx.Name || ofutx.Owner == nil || ofutx.Location == nil ||
+		*ofutx.Location != location || ofutx.PayoutAddress == nil || ofutx.PayoutAddress.Cmp(payout) != 0 {
+		t.Fatal("unexpected farm update transaction", ofutx, "!=", futx)
+	}
+}
+
+func TestFarmUpdateTransactionFromTransactionData(t *testing.T) {
+	testCases := []struct {
+		TxData types.TransactionData
+		Valid  bool
+	}{
+		{types.TransactionData{}, false},
+		{types.TransactionData{Extension: &FarmUpdateTransactionExtension{}}, false},
+		{types.TransactionData{
+			Extension: &FarmUpdateTransactionExtension{},
+			MinerFees: []types.Currency{types.NewCurrency64(1)},
+		}, false},
+		{types.TransactionData{
+			Extension:         &FarmUpdateTransactionExtension{},
+			CoinInputs:        []types.CoinInput{{}},
+			BlockStakeOutputs: []types.BlockStakeOutput{{}},
+			MinerFees:         []types.Currency{types.NewCurrency64(1)},
+		}, false},
+		{types.TransactionData{
+			Extension:  &FarmRegistrationTransactionExtension{},
+			CoinInputs: []types.CoinInput{{}},
+			MinerFees:  []types.Currency{types.NewCurrency64(1)},
+		}, false},
+		{types.TransactionData{
+			Extension:  &FarmUpdateTransactionExtension{},
+			CoinInputs: []types.CoinInput{{}},
+			MinerFees:  []types.Currency{types.NewCurrency64(1)},
+		}, true},
+	}
+	for idx, testCase := range testCases {
+		_, err := FarmUpdateTransactionFromTransactionData(testCase.TxData)
+		if testCase.Valid && err != nil {
+			t.Errorf("test case #%d: unexpected error: %v", idx, err)
+		} else if !testCase.Valid && err == nil {
+			t.Errorf("test case #%d: expected error, but none received", idx)
+		}
+	}
+}
+
+func TestFarmUpdateTransactionUniqueSignatureHashes(t *testing.T) {
+	types.RegisterTransactionVersion(TransactionVersionFarmUpdate, FarmUpdateTransactionController{})
+	defer types.RegisterTransactionVersion(TransactionVersionFarmUpdate, nil)
+
+	futx := FarmUpdateTransaction{
+		FarmID:           1,
+		Name:             "my farm",
+		OwnerFulfillment: testFulfillment(),
+		CoinInputs:       []types.CoinInput{{Fulfillment: testFulfillment()}},
+		MinerFees:        []types.Currency{types.NewCurrency64(100000000)},
+	}
+	hashes := map[crypto.Hash]struct{}{}
+	addHash := func(tx types.Transaction) {
+		hash, err := tx.SignatureHash()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := hashes[hash]; ok {
+			t.Fatal("duplicate signature hash:", hash.String())
+		}
+		hashes[hash] = struct{}{}
+	}
+	addHash(futx.Transaction())
+	futx.FarmID = 2
+	addHash(futx.Transaction())
+	// clearing the location differs from not updating it
+	location := ""
+	futx.Location = &location
+	addHash(futx.Transaction())
+	payout := testAddress(t, "01b49da2ff193f46ee0fc684d7a6121a8b8e324144dffc7327471a4da79f1730960edcb2ce737f")
+	futx.PayoutAddress = &payout
+	addHash(futx.Transaction())
+	futx.ManagersToAdd = []types.UnlockHash{payout}
+	addHash(futx.Transaction())
+	futx.ManagersToAdd, futx.ManagersToRemove = nil, []types.UnlockHash{payout}
+	addHash(futx.Transaction())
+}
+
+func TestFarmUpdateTransactionUpdateFarmRecord(t *testing.T) {
+	a := testAddress(t, "01b49da2ff193f46ee0fc684d7a6121a8b8e324144dffc7327471a4da79f1730960edcb2ce737f")
+	b := testAddress(t, "017fda17489854109399aa8c1bfa6bdef40f93606744d95cc5055270d78b465e6acd263c96ab2b")
+	original := FarmRecord{
+		ID:            1,
+		Name:          "my farm",
+		Owner:         types.NewCondition(types.NewUnlockHashCondition(a)),
+		Managers:      []types.UnlockHash{a},
+		Location:      "Ghent, Belgium",
+		PayoutAddress: a,
+	}
+
+	var futx FarmUpdateTransaction
+	if !futx.IsEmpty() {
+		t.Fatal("farm update without updates is expected to be empty")
+	}
+	location := ""
+	futx.Location = &location
+	futx.ManagersToRemove = []types.UnlockHash{a}
+	futx.ManagersToAdd = []types.UnlockHash{b}
+	if futx.IsEmpty() {
+		t.Fatal("farm update with updates is not expected to be empty")
+	}
+	record := original
+	err := futx.UpdateFarmRecord(&record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.Name != original.Name || record.Location != "" || len(record.Managers) != 1 || record.Managers[0].Cmp(b) != 0 {
+		t.Fatal("unexpected updated farm record", record)
+	}
+	// the original record should not be affected
+	if len(original.Managers) != 1 || original.Managers[0].Cmp(a) != 0 {
+		t.Fatal("unexpected original farm record", original)
+	}
+
+	// removing an address that isn't a manager is not allowed
+	futx = FarmUpdateTransaction{ManagersToRemove: []types.UnlockHash{b}}
+	record = original
+	if err = futx.UpdateFarmRecord(&record); err == nil {
+		t.Fatal("expected error while removing unknown manager, but none received")
+	}
+	// adding an address that already is a manager is not allowed either
+	futx = FarmUpdateTransaction{ManagersToAdd: []types.UnlockHash{a}}
+	record = original
+	if err = futx.UpdateFarmRecord(&record); err == nil {
+		t.Fatal("expected error while adding existing manager, but none received")
+	}
+}
+
+func TestNodeLinkTransactionEncodingAndID(t *testing.T) {
+	types.RegisterTransactionVersion(TransactionVersionNodeLink, NodeLinkTransactionController{})
+	defer types.RegisterTransactionVersion(TransactionVersionNodeLink, nil)
+
+	nltx := NodeLinkTransaction{
+		Node:               testPublicKey(),
+		NodeSignature:      make([]byte, crypto.SignatureSize),
+		FarmID:             1,
+		Manager:            testAddress(t, "01b49da2ff193f46ee0fc684d7a6121a8b8e324144dffc7327471a4da79f1730960edcb2ce737f"),
+		ManagerFulfillment: testFulfillment(),
+		CoinInputs: []types.CoinInput{
+			{
+				ParentID:    types.CoinOutputID(crypto.HashBytes([]byte("parent"))),
+				Fulfillment: testFulfillment(),
+			},
+		},
+		MinerFees:     []types.Currency{types.NewCurrency64(100000000)},
+		ArbitraryData: []byte("rack 3"),
+	}
+	testTransactionEncodingAndID(t, nltx.Transaction())
+
+	// unlinking a node
+	nltx.FarmID = 0
+	if !nltx.IsUnlink() {
+		t.Fatal("node link transaction with zero farm ID is expected to unlink")
+	}
+	testTransactionEncodingAndID(t, nltx.Transaction())
+
+	onltx, err := NodeLinkTransactionFromTransaction(nltx.Transaction())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if onltx.FarmID != nltx.FarmID || onltx.Node.String() != nltx.Node.String() ||
+		onltx.Manager.Cmp(nltx.Manager) != 0 || !bytes.Equal(onltx.NodeSignature, nltx.NodeSignature) {
+		t.Fatal("unexpected node link transaction", onltx, "!=", nltx)
+	}
+}
+
+func TestNodeLinkTransactionFromTransactionData(t *testing.T) {
+	testCases := []struct {
+		TxData types.TransactionData
+		Valid  bool
+	}{
+		{types.TransactionData{}, false},
+		{types.TransactionData{Extension: &NodeLinkTransactionExtension{}}, false},
+		{types.TransactionData{
+			Extension:  &NodeLinkTransactionExtension{},
+			CoinInputs: []types.CoinInput{{}},
+		}, false},
+		{types.TransactionData{
+			Extension:        &NodeLinkTransactionExtension{},
+			CoinInputs:       []types.CoinInput{{}},
+			BlockStakeInputs: []types.BlockStakeInput{{}},
+			MinerFees:        []types.Currency{types.NewCurrency64(1)},
+		}, false},
+		{types.TransactionData{
+			Extension:  &FarmUpdateTransactionExtension{},
+			CoinInputs: []types.CoinInput{{}},
+			MinerFees:  []types.Currency{types.NewCurrency64(1)},
+		}, false},
+		{types.TransactionData{
+			Extension:  &NodeLinkTransactionExtension{},
+			CoinInputs: []types.CoinInput{{}},
+			MinerFees:  []types.Currency{types.NewCurrency64(1)},
+		}, true},
+	}
+	for idx, testCase := range testCases {
+		_, err := NodeLinkTransactionFromTransactionData(testCase.TxData)
+		if testCase.Valid && err != nil {
+			t.Errorf("test case #%d: unexpected error: %v", idx, err)
+		} else if !testCase.Valid && err == nil {
+			t.Errorf("test case #%d: expected error, but none received", idx)
+		}
+	}
+}
+
+func TestNodeLinkTransactionUniqueSignatureHashes(t *testing.T) {
+	types.RegisterTransactionVersion(TransactionVersionNodeLink, NodeLinkTransactionController{})
+	defer types.RegisterTransactionVersion(TransactionVersionNodeLink, nil)
+
+	nltx := NodeLinkTransaction{
+		Node:               testPublicKey(),
+		FarmID:             1,
+		Manager:            testAddress(t, "01b49da2ff193f46ee0fc684d7a6121a8b8e324144dffc7327471a4da79f1730960edcb2ce737f"),
+		ManagerFulfillment: testFulfillment(),
+		CoinInputs:         []types.CoinInput{{Fulfillment: testFulfillment()}},
+		MinerFees:          []types.Currency{types.NewCurrency64(100000000)},
+	}
+	hashes := map[crypto.Hash]struct{}{}
+	addHash := func(tx types.Transaction, extraObjects ...interface{}) {
+		hash, err := tx.SignatureHash(extraObjects...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := hashes[hash]; ok {
+			t.Fatal("duplicate signature hash:", hash.String())
+		}
+		hashes[hash] = struct{}{}
+	}
+	// the node and manager sign a different hash
+	addHash(nltx.Transaction(), NodeLinkSignatureSpecifierNode)
+	addHash(nltx.Transaction(), NodeLinkSignatureSpecifierManager)
+	nltx.FarmID = 0
+	addHash(nltx.Transaction(), NodeLinkSignatureSpecifierNode)
+	nltx.Node.Key[0] = 1
+	addHash(nltx.Transaction(), NodeLinkSignatureSpecifierNode)
+	nltx.Manager = testAddress(t, "017fda17489854109399aa8c1bfa6bdef40f93606744d95cc5055270d78b465e6acd263c96ab2b")
+	addHash(nltx.Transaction(), NodeLinkSignatureSpecifierNode)
+
+	// the signatures themselves are not part of the signature hash
+	hash, err := nltx.Transaction().SignatureHash(NodeLinkSignatureSpecifierNode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nltx.NodeSignature = make([]byte, crypto.SignatureSize)
+	nltx.ManagerFulfillment = types.NewFulfillment(types.NewSingleSignatureFulfillment(testPublicKey()))
+	if ohash, err := nltx.Transaction().SignatureHash(NodeLinkSignatureSpecifierNode); err != nil || ohash != hash {
+		t.Fatal("signature hash is not expected to depend on the signatures:", hash.String(), "!=", ohash.String(), err)
+	}
+}
+
+func TestFarmingRewardTransactionEncodingAndID(t *testing.T) {
+	types.RegisterTransactionVersion(TransactionVersionFarmingReward, FarmingRewardTransactionController{})
+	defer types.RegisterTransactionVersion(TransactionVersionFarmingReward, nil)
+
+	frtx := FarmingRewardTransaction{
+		Nonce:          types.RandomTransactionNonce(),
+		SnapshotHeight: 42,
+		Payouts: []FarmPayout{
+			{
+				FarmID:        1,
+				Value:         types.NewCurrency64(1000000000),
+				PayoutAddress: testAddress(t, "01b49da2ff193f46ee0fc684d7a6121a8b8e324144dffc7327471a4da79f1730960edcb2ce737f"),
+			},
+			{
+				FarmID:        3,
+				Value:         types.NewCurrency64(2500000000),
+				PayoutAddress: testAddress(t, "017fda17489854109399aa8c1bfa6bdef40f93606744d95cc5055270d78b465e6acd263c96ab2b"),
+			},
+		},
+		MintFulfillment: testFulfillment(),
+		MinerFees:       []types.Currency{types.NewCurrency64(100000000)},
+		ArbitraryData:   []byte("rewards for 42"),
+	}
+	tx := frtx.Transaction()
+	testTransactionEncodingAndID(t, tx)
+
+	// each payout is a regular coin output
+	if len(tx.CoinOutputs) != len(frtx.Payouts) {
+		t.Fatal("unexpected amount of coin outputs:", len(tx.CoinOutputs))
+	}
+	for idx, co := range tx.CoinOutputs {
+		if !co.Value.Equals(frtx.Payouts[idx].Value) || co.Condition.UnlockHash().Cmp(frtx.Payouts[idx].PayoutAddress) != 0 {
+			t.Error("unexpected coin output", idx, co)
+		}
+	}
+
+	ofrtx, err := FarmingRewardTransactionFromTransaction(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ofrtx.Nonce != frtx.Nonce || ofrtx.SnapshotHeight != frtx.SnapshotHeight || len(ofrtx.Payouts) != len(frtx.Payouts) {
+		t.Fatal("unexpected farming reward transaction", ofrtx, "!=", frtx)
+	}
+	for idx, payout := range ofrtx.Payouts {
+		if payout.FarmID != frtx.Payouts[idx].FarmID || !payout.Value.Equals(frtx.Payouts[idx].Value) ||
+			payout.PayoutAddress.Cmp(frtx.Payouts[idx].PayoutAddress) != 0 {
+			t.Error("unexpected payout", idx, payout, "!=", frtx.Payouts[idx])
+		}
+	}
+}
+
+func TestFarmingRewardTransactionFromTransactionData(t *testing.T) {
+	output := types.CoinOutput{
+		Value:     types.NewCurrency64(1),
+		Condition: types.NewCondition(types.NewUnlockHashCondition(types.UnlockHash{Type: types.UnlockTypePubKey})),
+	}
+	testCases := []struct {
+		TxData types.TransactionData
+		Valid  bool
+	}{
+		{types.TransactionData{}, false},
+		{types.TransactionData{Extension: &FarmingRewardTransactionExtension{}}, false},
+		{types.TransactionData{
+			Extension:   &FarmingRewardTransactionExtension{FarmIDs: []FarmID{1}},
+			CoinInputs:  []types.CoinInput{{}},
+			CoinOutputs: []types.CoinOutput{output},
+			MinerFees:   []types.Currency{types.NewCurrency64(1)},
+		}, false},
+		{types.TransactionData{
+			Extension:   &FarmingRewardTransactionExtension{FarmIDs: []FarmID{1, 2}},
+			CoinOutputs: []types.CoinOutput{output},
+			MinerFees:   []types.Currency{types.NewCurrency64(1)},
+		}, false},
+		{types.TransactionData{
+			Extension: &FarmingRewardTransactionExtension{FarmIDs: []FarmID{1}},
+			CoinOutputs: []types.CoinOutput{{
+				Value:     types.NewCurrency64(1),
+				Condition: types.NewCondition(types.NewTimeLockCondition(1, types.NewUnlockHashCondition(types.UnlockHash{Type: types.UnlockTypePubKey}))),
+			}},
+			MinerFees: []types.Currency{types.NewCurrency64(1)},
+		}, false},
+		{types.TransactionData{
+			Extension:   &FarmerAuthorizationTransactionExtension{},
+			CoinOutputs: []types.CoinOutput{output},
+			MinerFees:   []types.Currency{types.NewCurrency64(1)},
+		}, false},
+		{types.TransactionData{
+			Extension: &FarmingRewardTransactionExtension{},
+			MinerFees: []types.Currency{types.NewCurrency64(1)},
+		}, true},
+		{types.TransactionData{
+			Extension:   &FarmingRewardTransactionExtension{FarmIDs: []FarmID{1}},
+			CoinOutputs: []types.CoinOutput{output},
+			MinerFees:   []types.Currency{types.NewCurrency64(1)},
+		}, true},
+	}
+	for idx, testCase := range testCases {
+		_, err := FarmingRewardTransactionFromTransactionData(testCase.TxData)
+		if testCase.Valid && err != nil {
+			t.Errorf("test case #%d: unexpected error: %v", idx, err)
+		} else if !testCase.Valid && err == nil {
+			t.Errorf("test case #%d: expected error, but none received", idx)
+		}
+	}
+}
+
+func TestFarmingRewardTransactionUniqueSignatureHashes(t *testing.T) {
+	types.RegisterTransactionVersion(TransactionVersionFarmingReward, FarmingRewardTransactionController{})
+	defer types.RegisterTransactionVersion(TransactionVersionFarmingReward, nil)
+
+	frtx := FarmingRewardTransaction{
+		SnapshotHeight: 1,
+		Payouts: []FarmPayout{{
+			FarmID:        1,
+			Value:         types.NewCurrency64(1000000000),
+			PayoutAddress: testAddress(t, "01b49da2ff193f46ee0fc684d7a6121a8b8e324144dffc7327471a4da79f1730960edcb2ce737f"),
+		}},
+		MintFulfillment: testFulfillment(),
+		MinerFees:       []types.Currency{types.NewCurrency64(100000000)},
+	}
+	hashes := map[crypto.Hash]struct{}{}
+	addHash := func(tx types.Transaction) {
+		hash, err := tx.SignatureHash()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := hashes[hash]; ok {
+			t.Fatal("duplicate signature hash:", hash.String())
+		}
+		hashes[hash] = struct{}{}
+	}
+	addHash(frtx.Transaction())
+	frtx.Nonce[0] = 1
+	addHash(frtx.Transaction())
+	frtx.SnapshotHeight++
+	addHash(frtx.Transaction())
+	frtx.Payouts[0].FarmID = 2
+	addHash(frtx.Transaction())
+	frtx.Payouts[0].Value = types.NewCurrency64(2000000000)
+	addHash(frtx.Transaction())
+	frtx.Payouts[0].PayoutAddress = testAddress(t, "017fda17489854109399aa8c1bfa6bdef40f93606744d95cc5055270d78b465e6acd263c96ab2b")
+	addHash(frtx.Transaction())
+}
+
+func TestCapacityUnits(t *testing.T) {
+	cu := CapacityUnits{CRU: 1, MRU: 2, HRU: 3, SRU: 4}
+	if cu.IsZero() || !(CapacityUnits{}).IsZero() {
+		t.Fatal("unexpected IsZero result")
+	}
+	if sum := cu.Add(cu); sum != (CapacityUnits{CRU: 2, MRU: 4, HRU: 6, SRU: 8}) {
+		t.Fatal("unexpected sum:", sum.String())
+	}
+}
+
+func testTransactionEncodingAndID(t *testing.T, tx types.Transaction) {
+	id := tx.ID()
+
+	b, err := json.Marshal(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var jsonTx types.Transaction
+	err = json.Unmarshal(b, &jsonTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if oID := jsonTx.ID(); id != oID {
+		t.Fatal("JSON:", id, "!=", oID)
+	}
+
+	b, err = rivbin.Marshal(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var binTx types.Transaction
+	err = rivbin.Unmarshal(b, &binTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if oID := binTx.ID(); id != oID {
+		t.Fatal("binary:", id, "!=", oID)
+	}
+	ob, err := rivbin.Marshal(binTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, ob) {
+		t.Fatal(hex.EncodeToString(b), "!=", hex.EncodeToString(ob))
+	}
+}
+
+func testAddress(t *testing.T, str string) (uh types.UnlockHash) {
+	err := uh.LoadString(str)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return
+}
+
+func testPublicKey() types.PublicKey {
+	return types.PublicKey{
+		Algorithm: types.SignatureAlgoEd25519,
+		Key:       make([]byte, crypto.PublicKeySize),
+	}
+}
+
+func testFulfillment() types.UnlockFulfillmentProxy {
+	return types.NewFulfillment(&types.SingleSignatureFulfillment{
+		PublicKey: testPublicKey(),
+		Signature: make([]byte, crypto.SignatureSize),
+	})
+}
@@ -0,0 +1,63 @@
+package capacity
+
+import (
+	"fmt"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/types"
+
+	ctypes "github.com/threefoldfoundation/tfchain/extensions/capacity/types"
+)
+
+// validateNodePublicKey validates that the given public key can identify a node,
+// only ed25519 public keys are supported.
+func validateNodePublicKey(pk types.PublicKey) error {
+	if pk.Algorithm != types.SignatureAlgoEd25519 || len(pk.Key) != crypto.PublicKeySize {
+		return ctypes.ErrInvalidNodePublicKey
+	}
+	return nil
+}
+
+// validateFarmerAddresses validates that at least one farmer address is (de)authorized,
+// that all given farmer addresses are personal (public key) or multisig addresses,
+// and that no address is authorized and deauthorized (or listed twice) within the same transaction.
+func validateFarmerAddresses(authAddresses, deauthAddresses []types.UnlockHash) error {
+	if len(authAddresses) == 0 && len(deauthAddresses) == 0 {
+		return ctypes.ErrNoFarmerAuthorization
+	}
+	seen := make(map[types.UnlockHash]struct{}, len(authAddresses)+len(deauthAddresses))
+	for _, addresses := range [][]types.UnlockHash{authAddresses, deauthAddresses} {
+		for _, uh := range addresses {
+			if uh.Type != types.UnlockTypePubKey && uh.Type != types.UnlockTypeMultiSig {
+				return ctypes.ErrInvalidFarmerAddress
+			}
+			if _, ok := seen[uh]; ok {
+				return fmt.Errorf("farmer address %s is listed more than once", uh.String())
+			}
+			seen[uh] = struct{}{}
+		}
+	}
+	return nil
+}
+
+// validateNodeSignature validates that the given signature was created by the private key
+// of the given node, signing the given transaction (and extra objects).
+func validateNodeSignature(t types.Transaction, node types.PublicKey, signature types.ByteSlice, ctx types.TransactionValidationContext, extraObjects ...interface{}) error {
+	uh, err := types.NewPubKeyUnlockHash(node)
+	if err != nil {
+		return err
+	}
+	condition := types.NewCondition(types.NewUnlockHashCondition(uh))
+	// and a matching single-signature fulfillment
+	fulfillment := types.NewFulfillment(&types.SingleSignatureFulfillment{
+		PublicKey: node,
+		Signature: signature,
+	})
+	// validate the signature is correct
+	return condition.Fulfill(fulfillment, types.FulfillContext{
+		ExtraObjects: extraObjects,
+		BlockHeight:  ctx.BlockHeight,
+		BlockTime:    ctx.BlockTime,
+		Transaction:  t,
+	})
+}
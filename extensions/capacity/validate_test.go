@@ -0,0 +1,105 @@
+package capacity
+
+import (
+	"testing"
+
+	ctypes "github.com/threefoldfoundation/tfchain/extensions/capacity/types"
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/types"
+)
+
+func TestValidateNodePublicKey(t *testing.T) {
+	testCases := []struct {
+		PublicKey types.PublicKey
+		Valid     bool
+	}{
+		{types.PublicKey{}, false},
+		{types.PublicKey{Algorithm: types.SignatureAlgoEd25519}, false},
+		{types.PublicKey{Algorithm: types.SignatureAlgoEd25519, Key: make([]byte, crypto.PublicKeySize-1)}, false},
+		{types.PublicKey{Algorithm: types.SignatureAlgoNil, Key: make([]byte, crypto.PublicKeySize)}, false},
+		{types.PublicKey{Algorithm: types.SignatureAlgoEd25519, Key: make([]byte, crypto.PublicKeySize)}, true},
+	}
+	for idx, testCase := range testCases {
+		err := validateNodePublicKey(testCase.PublicKey)
+		if testCase.Valid && err != nil {
+			t.Errorf("test case #%d: unexpected error: %v", idx, err)
+		} else if !testCase.Valid && err == nil {
+			t.Errorf("test case #%d: expected error, but none received", idx)
+		}
+	}
+}
+
+func TestValidateFarmerAddresses(t *testing.T) {
+	a := types.UnlockHash{Type: types.UnlockTypePubKey}
+	a.Hash[0] = 1
+	b := types.UnlockHash{Type: types.UnlockTypePubKey}
+	b.Hash[0] = 2
+	ms := types.UnlockHash{Type: types.UnlockTypeMultiSig}
+
+	testCases := []struct {
+		Auth, Deauth []types.UnlockHash
+		Valid        bool
+	}{
+		{nil, nil, false},
+		{[]types.UnlockHash{a}, nil, true},
+		{nil, []types.UnlockHash{a}, true},
+		{[]types.UnlockHash{a}, []types.UnlockHash{b}, true},
+		{[]types.UnlockHash{a, b}, nil, true},
+		{[]types.UnlockHash{a, a}, nil, false},
+		{[]types.UnlockHash{a}, []types.UnlockHash{a}, false},
+		{[]types.UnlockHash{ms}, nil, true},
+		{[]types.UnlockHash{{Type: types.UnlockTypeAtomicSwap}}, nil, false},
+		{[]types.UnlockHash{{}}, nil, false},
+	}
+	for idx, testCase := range testCases {
+		err := validateFarmerAddresses(testCase.Auth, testCase.Deauth)
+		if testCase.Valid && err != nil {
+			t.Errorf("test case #%d: unexpected error: %v", idx, err)
+		} else if !testCase.Valid && err == nil {
+			t.Errorf("test case #%d: expected error, but none received", idx)
+		}
+	}
+}
+
+func TestValidateNodeSignature(t *testing.T) {
+	types.RegisterTransactionVersion(ctypes.TransactionVersionNodeLink, ctypes.NodeLinkTransactionController{})
+	defer types.RegisterTransactionVersion(ctypes.TransactionVersionNodeLink, nil)
+
+	sk, pk := crypto.GenerateKeyPair()
+	node := types.Ed25519PublicKey(pk)
+	nltx := ctypes.NodeLinkTransaction{
+		Node:       node,
+		FarmID:     1,
+		Manager:    types.UnlockHash{Type: types.UnlockTypePubKey},
+		CoinInputs: []types.CoinInput{{}},
+		MinerFees:  []types.Currency{types.NewCurrency64(1)},
+	}
+	tx := nltx.Transaction()
+	hash, err := tx.SignatureHash(ctypes.NodeLinkSignatureSpecifierNode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := crypto.SignHash(hash, sk)
+	ctx := types.TransactionValidationContext{}
+
+	err = validateNodeSignature(tx, node, sig[:], ctx, ctypes.NodeLinkSignatureSpecifierNode)
+	if err != nil {
+		t.Fatal("unexpected error for valid node signature:", err)
+	}
+	// a signature created for the manager is not valid for the node
+	err = validateNodeSignature(tx, node, sig[:], ctx, ctypes.NodeLinkSignatureSpecifierManager)
+	if err == nil {
+		t.Fatal("expected error for node signature using the wrong specifier, but none received")
+	}
+	// a signature of another node is not valid
+	_, opk := crypto.GenerateKeyPair()
+	err = validateNodeSignature(tx, types.Ed25519PublicKey(opk), sig[:], ctx, ctypes.NodeLinkSignatureSpecifierNode)
+	if err == nil {
+		t.Fatal("expected error for signature of another node, but none received")
+	}
+	// a missing signature is not valid
+	err = validateNodeSignature(tx, node, nil, ctx, ctypes.NodeLinkSignatureSpecifierNode)
+	if err == nil {
+		t.Fatal("expected error for missing node signature, but none received")
+	}
+}
@@ -0,0 +1,143 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	rtypes "github.com/threefoldfoundation/tfchain/extensions/recovery/types"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/pkg/api"
+	"github.com/threefoldtech/rivine/types"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+type (
+	// GetFrozenAddress contains a requested frozen address,
+	// as well as the current unspent coin balance of that address.
+	GetFrozenAddress struct {
+		Record  rtypes.FrozenAddress `json:"record"`
+		Balance types.Currency       `json:"balance"`
+	}
+
+	// GetBlacklistedAddress contains the blacklist status of a requested address
+	// at the current block height, as well as its blacklist history.
+	GetBlacklistedAddress struct {
+		rtypes.AddressBlacklistStatus
+	}
+)
+
+// RegisterConsensusHTTPHandlers registers the recovery handlers for all consensus HTTP endpoints.
+func RegisterConsensusHTTPHandlers(router api.Router, cs modules.ConsensusSet, registry rtypes.FrozenAddressReadRegistry, blacklistRegistry rtypes.BlacklistedAddressReadRegistry) {
+	if cs == nil {
+		panic("no ConsensusSet API given")
+	}
+	if registry == nil {
+		panic("no FrozenAddressReadRegistry API given")
+	}
+	if blacklistRegistry == nil {
+		panic("no BlacklistedAddressReadRegistry API given")
+	}
+	if router == nil {
+		panic("no httprouter Router given")
+	}
+
+	router.GET("/consensus/frozenaddress/:id", NewGetFrozenAddressHandler(registry))
+	router.GET("/consensus/blacklistedaddress/:address", NewGetBlacklistedAddressHandler(cs, blacklistRegistry))
+}
+
+// RegisterExplorerHTTPHandlers registers the recovery handlers for all explorer HTTP endpoints.
+func RegisterExplorerHTTPHandlers(router api.Router, cs modules.ConsensusSet, registry rtypes.FrozenAddressReadRegistry, blacklistRegistry rtypes.BlacklistedAddressReadRegistry) {
+	if cs == nil {
+		panic("no ConsensusSet API given")
+	}
+	if registry == nil {
+		panic("no FrozenAddressReadRegistry API given")
+	}
+	if blacklistRegistry == nil {
+		panic("no BlacklistedAddressReadRegistry API given")
+	}
+	if router == nil {
+		panic("no httprouter Router given")
+	}
+
+	router.GET("/explorer/frozenaddress/:id", NewGetFrozenAddressHandler(registry))
+	router.GET("/explorer/blacklistedaddress/:address", NewGetBlacklistedAddressHandler(cs, blacklistRegistry))
+}
+
+// NewGetFrozenAddressHandler creates a handler to handle the API calls to /transactiondb/frozenaddress/:id,
+// where the id is either the frozen address or the ID of the transaction that froze it.
+func NewGetFrozenAddressHandler(registry rtypes.FrozenAddressReadRegistry) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		var (
+			err    error
+			record rtypes.FrozenAddress
+		)
+		idStr := ps.ByName("id")
+		var address types.UnlockHash
+		err = address.LoadString(idStr)
+		if err == nil {
+			// interpret it as an address
+			record, err = registry.GetFrozenAddress(address)
+		} else {
+			// interpret it as a freeze transaction ID
+			var txID types.TransactionID
+			err = txID.LoadString(idStr)
+			if err != nil {
+				api.WriteError(w, api.Error{Message: fmt.Errorf("id has to be a valid address or transaction ID: %v", err).Error()},
+					http.StatusBadRequest)
+				return
+			}
+			record, err = registry.GetFrozenAddressForTransaction(txID)
+		}
+		if err != nil {
+			api.WriteError(w, api.Error{Message: err.Error()}, recoveryErrorAsHTTPStatusCode(err))
+			return
+		}
+		balance, err := registry.GetUnspentBalance(record.Address)
+		if err != nil {
+			api.WriteError(w, api.Error{Message: fmt.Errorf("failed to get unspent balance of frozen address: %v", err).Error()},
+				recoveryErrorAsHTTPStatusCode(err))
+			return
+		}
+		api.WriteJSON(w, GetFrozenAddress{
+			Record:  record,
+			Balance: balance,
+		})
+	}
+}
+
+// NewGetBlacklistedAddressHandler creates a handler to handle the API calls to /transactiondb/blacklistedaddress/:address,
+// returning the blacklist status of the address at the current block height, as well as its blacklist history.
+func NewGetBlacklistedAddressHandler(cs modules.ConsensusSet, registry rtypes.BlacklistedAddressReadRegistry) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		addressStr := ps.ByName("address")
+		var address types.UnlockHash
+		err := address.LoadString(addressStr)
+		if err != nil {
+			api.WriteError(w, api.Error{Message: fmt.Errorf("invalid address %q: %v", addressStr, err).Error()},
+				http.StatusBadRequest)
+			return
+		}
+		history, err := registry.GetAddressBlacklistHistory(address)
+		if err != nil {
+			api.WriteError(w, api.Error{Message: err.Error()}, recoveryErrorAsHTTPStatusCode(err))
+			return
+		}
+		api.WriteJSON(w, GetBlacklistedAddress{
+			AddressBlacklistStatus: rtypes.NewAddressBlacklistStatus(address, cs.Height(), history),
+		})
+	}
+}
+
+// recoveryErrorAsHTTPStatusCode converts a recovery error to an http status code.
+// if it is not an applicable recovery error, an internal server error code is returned
+func recoveryErrorAsHTTPStatusCode(err error) int {
+	switch err {
+	case rtypes.ErrAddressNotFrozen, rtypes.ErrFreezeTransactionNotFound, rtypes.ErrAddressNotBlacklisted:
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
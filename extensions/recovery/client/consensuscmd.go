@@ -0,0 +1,98 @@
+package client
+
+import (
+	"github.com/threefoldtech/rivine/pkg/cli"
+	"github.com/threefoldtech/rivine/pkg/client"
+	rivinecli "github.com/threefoldtech/rivine/pkg/client"
+	"github.com/threefoldtech/rivine/types"
+
+	"github.com/spf13/cobra"
+)
+
+func CreateConsensusSubCmds(ccli *rivinecli.CommandLineClient) error {
+	bc, err := client.NewLazyBaseClientFromCommandLineClient(ccli)
+	if err != nil {
+		return err
+	}
+
+	consensusSubCmds := &consensusSubCmds{
+		cli:     ccli,
+		rClient: NewPluginConsensusClient(bc),
+	}
+
+	// define commands
+	var (
+		getFrozenAddressCmd = &cobra.Command{
+			Use:   "frozenaddress (address|freezetxid)",
+			Short: "Get the frozen address linked to the given info",
+			Long: `Get the frozen address linked to the given address,
+or the ID of the address freeze transaction that froze it,
+as well as the unspent coin balance that can be recovered.
+`,
+			Run: rivinecli.Wrap(consensusSubCmds.getFrozenAddress),
+		}
+		getBlacklistedAddressCmd = &cobra.Command{
+			Use:   "blacklistedaddress <address>",
+			Short: "Get the blacklist status and history of the given address",
+			Long: `Get whether or not the given address is blacklisted at the current block height,
+as well as all (lifted and expired) blacklistings of that address.
+`,
+			Run: rivinecli.Wrap(consensusSubCmds.getBlacklistedAddress),
+		}
+	)
+
+	// add commands as consensus sub commands
+	ccli.ConsensusCmd.AddCommand(
+		getFrozenAddressCmd,
+		getBlacklistedAddressCmd,
+	)
+
+	// register flags
+	getFrozenAddressCmd.Flags().Var(
+		cli.NewEncodingTypeFlag(0, &consensusSubCmds.getFrozenAddressCfg.EncodingType, 0), "encoding",
+		cli.EncodingTypeFlagDescription(0))
+	getBlacklistedAddressCmd.Flags().Var(
+		cli.NewEncodingTypeFlag(0, &consensusSubCmds.getBlacklistedAddressCfg.EncodingType, 0), "encoding",
+		cli.EncodingTypeFlagDescription(0))
+
+	return nil
+}
+
+type consensusSubCmds struct {
+	cli                 *rivinecli.CommandLineClient
+	rClient             *PluginClient
+	getFrozenAddressCfg struct {
+		EncodingType cli.EncodingType
+	}
+	getBlacklistedAddressCfg struct {
+		EncodingType cli.EncodingType
+	}
+}
+
+func (consensusSubCmds *consensusSubCmds) getFrozenAddress(str string) {
+	result, err := consensusSubCmds.rClient.FrozenAddressForString(str)
+	if err != nil {
+		cli.DieWithError("error while fetching the frozen address", err)
+	}
+
+	err = encodeResult(result, consensusSubCmds.getFrozenAddressCfg.EncodingType)
+	if err != nil {
+		cli.DieWithError("failed to encode frozen address", err)
+	}
+}
+
+func (consensusSubCmds *consensusSubCmds) getBlacklistedAddress(str string) {
+	var address types.UnlockHash
+	err := address.LoadString(str)
+	if err != nil {
+		cli.DieWithError("invalid address", err)
+	}
+	result, err := consensusSubCmds.rClient.GetBlacklistedAddress(address)
+	if err != nil {
+		cli.DieWithError("error while fetching the blacklisted address", err)
+	}
+	err = encodeResult(result, consensusSubCmds.getBlacklistedAddressCfg.EncodingType)
+	if err != nil {
+		cli.DieWithError("failed to encode blacklisted address", err)
+	}
+}
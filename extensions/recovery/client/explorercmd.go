@@ -0,0 +1,98 @@
+package client
+
+import (
+	"github.com/threefoldtech/rivine/pkg/cli"
+	"github.com/threefoldtech/rivine/pkg/client"
+	rivinecli "github.com/threefoldtech/rivine/pkg/client"
+	"github.com/threefoldtech/rivine/types"
+
+	"github.com/spf13/cobra"
+)
+
+func CreateExplorerSubCmds(ccli *rivinecli.CommandLineClient) error {
+	bc, err := client.NewLazyBaseClientFromCommandLineClient(ccli)
+	if err != nil {
+		return err
+	}
+
+	explorerSubCmds := &explorerSubCmds{
+		cli:     ccli,
+		rClient: NewPluginExplorerClient(bc),
+	}
+
+	// define commands
+	var (
+		getFrozenAddressCmd = &cobra.Command{
+			Use:   "frozenaddress (address|freezetxid)",
+			Short: "Get the frozen address linked to the given info",
+			Long: `Get the frozen address linked to the given address,
+or the ID of the address freeze transaction that froze it,
+as well as the unspent coin balance that can be recovered.
+`,
+			Run: rivinecli.Wrap(explorerSubCmds.getFrozenAddress),
+		}
+		getBlacklistedAddressCmd = &cobra.Command{
+			Use:   "blacklistedaddress <address>",
+			Short: "Get the blacklist status and history of the given address",
+			Long: `Get whether or not the given address is blacklisted at the current block height,
+as well as all (lifted and expired) blacklistings of that address.
+`,
+			Run: rivinecli.Wrap(explorerSubCmds.getBlacklistedAddress),
+		}
+	)
+
+	// add commands as explorer sub commands
+	ccli.ExploreCmd.AddCommand(
+		getFrozenAddressCmd,
+		getBlacklistedAddressCmd,
+	)
+
+	// register flags
+	getFrozenAddressCmd.Flags().Var(
+		cli.NewEncodingTypeFlag(0, &explorerSubCmds.getFrozenAddressCfg.EncodingType, 0), "encoding",
+		cli.EncodingTypeFlagDescription(0))
+	getBlacklistedAddressCmd.Flags().Var(
+		cli.NewEncodingTypeFlag(0, &explorerSubCmds.getBlacklistedAddressCfg.EncodingType, 0), "encoding",
+		cli.EncodingTypeFlagDescription(0))
+
+	return nil
+}
+
+type explorerSubCmds struct {
+	cli                 *rivinecli.CommandLineClient
+	rClient             *PluginClient
+	getFrozenAddressCfg struct {
+		EncodingType cli.EncodingType
+	}
+	getBlacklistedAddressCfg struct {
+		EncodingType cli.EncodingType
+	}
+}
+
+func (explorerSubCmds *explorerSubCmds) getFrozenAddress(str string) {
+	result, err := explorerSubCmds.rClient.FrozenAddressForString(str)
+	if err != nil {
+		cli.DieWithError("error while fetching the frozen address", err)
+	}
+
+	err = encodeResult(result, explorerSubCmds.getFrozenAddressCfg.EncodingType)
+	if err != nil {
+		cli.DieWithError("failed to encode frozen address", err)
+	}
+}
+
+func (explorerSubCmds *explorerSubCmds) getBlacklistedAddress(str string) {
+	var address types.UnlockHash
+	err := address.LoadString(str)
+	if err != nil {
+		cli.DieWithError("invalid address", err)
+	}
+	result, err := explorerSubCmds.rClient.GetBlacklistedAddress(address)
+	if err != nil {
+		cli.DieWithError("error while fetching the blacklisted address", err)
+	}
+	err = encodeResult(result, explorerSubCmds.getBlacklistedAddressCfg.EncodingType)
+	if err != nil {
+		cli.DieWithError("failed to encode blacklisted address", err)
+	}
+}
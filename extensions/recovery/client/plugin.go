@@ -0,0 +1,120 @@
+package client
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	rapi "github.com/threefoldfoundation/tfchain/extensions/recovery/api"
+	"github.com/threefoldtech/rivine/pkg/cli"
+	"github.com/threefoldtech/rivine/pkg/client"
+	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// PluginClient is used to be able to get recovery information from
+// a daemon that has the recovery extension enabled and running.
+type PluginClient struct {
+	bc           client.BaseClient
+	rootEndpoint string
+}
+
+// NewPluginConsensusClient creates a new PluginClient,
+// that can be used for easy interaction with the API exposed via the Consensus endpoints
+func NewPluginConsensusClient(bc client.BaseClient) *PluginClient {
+	if bc == nil {
+		panic("no BaseClient given")
+	}
+	return &PluginClient{
+		bc:           bc,
+		rootEndpoint: "/consensus",
+	}
+}
+
+// NewPluginExplorerClient creates a new PluginClient,
+// that can be used for easy interaction with the API exposed via the Explorer endpoints
+func NewPluginExplorerClient(bc client.BaseClient) *PluginClient {
+	if bc == nil {
+		panic("no BaseClient given")
+	}
+	return &PluginClient{
+		bc:           bc,
+		rootEndpoint: "/explorer",
+	}
+}
+
+// GetFrozenAddress returns the state and unspent balance of the given frozen address.
+func (client *PluginClient) GetFrozenAddress(address types.UnlockHash) (*rapi.GetFrozenAddress, error) {
+	var result rapi.GetFrozenAddress
+	err := client.bc.HTTP().GetWithResponse(fmt.Sprintf("%s/frozenaddress/%s", client.rootEndpoint, address.String()), &result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get frozen address %s from daemon: %v", address.String(), err)
+	}
+	return &result, nil
+}
+
+// GetFrozenAddressForTransaction returns the state and unspent balance
+// of the address frozen by the given AddressFreezeTransaction.
+func (client *PluginClient) GetFrozenAddressForTransaction(id types.TransactionID) (*rapi.GetFrozenAddress, error) {
+	var result rapi.GetFrozenAddress
+	err := client.bc.HTTP().GetWithResponse(fmt.Sprintf("%s/frozenaddress/%s", client.rootEndpoint, id.String()), &result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get address frozen by tx %s from daemon: %v", id.String(), err)
+	}
+	return &result, nil
+}
+
+// FrozenAddressForString returns the state and unspent balance of a frozen address,
+// where the given string is either the frozen address or the ID of the transaction that froze it.
+func (client *PluginClient) FrozenAddressForString(str string) (*rapi.GetFrozenAddress, error) {
+	// try str as an address
+	var address types.UnlockHash
+	err := address.LoadString(str)
+	if err == nil {
+		return client.GetFrozenAddress(address)
+	}
+
+	// should be a transaction ID, last choice
+	var id types.TransactionID
+	err = id.LoadString(str)
+	if err != nil {
+		return nil, errors.New("argument should be a valid address or transaction ID")
+	}
+	return client.GetFrozenAddressForTransaction(id)
+}
+
+// GetBlacklistedAddress returns the blacklist status (at the current block height)
+// and blacklist history of the given address.
+func (client *PluginClient) GetBlacklistedAddress(address types.UnlockHash) (*rapi.GetBlacklistedAddress, error) {
+	var result rapi.GetBlacklistedAddress
+	err := client.bc.HTTP().GetWithResponse(fmt.Sprintf("%s/blacklistedaddress/%s", client.rootEndpoint, address.String()), &result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blacklisted address %s from daemon: %v", address.String(), err)
+	}
+	return &result, nil
+}
+
+// encodeResult encodes the given result to the STDOUT, depending on the given encoding type
+func encodeResult(result interface{}, encodingType cli.EncodingType) error {
+	var encode func(interface{}) error
+	switch encodingType {
+	case cli.EncodingTypeHuman:
+		e := json.NewEncoder(os.Stdout)
+		e.SetIndent("", "  ")
+		encode = e.Encode
+	case cli.EncodingTypeJSON:
+		encode = json.NewEncoder(os.Stdout).Encode
+	case cli.EncodingTypeHex:
+		encode = func(v interface{}) error {
+			b, err := siabin.Marshal(v)
+			if err != nil {
+				return err
+			}
+			fmt.Println(hex.EncodeToString(b))
+			return nil
+		}
+	}
+	return encode(result)
+}
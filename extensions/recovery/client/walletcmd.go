@@ -0,0 +1,296 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	rtypes "github.com/threefoldfoundation/tfchain/extensions/recovery/types"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/pkg/cli"
+	"github.com/threefoldtech/rivine/pkg/client"
+	"github.com/threefoldtech/rivine/types"
+
+	"github.com/spf13/cobra"
+)
+
+// CreateWalletCmds adds the wallet cli subcommands for the recovery plugin
+func CreateWalletCmds(ccli *client.CommandLineClient) error {
+	bc, err := client.NewLazyBaseClientFromCommandLineClient(ccli)
+	if err != nil {
+		return err
+	}
+	walletCmd := &walletCmd{
+		cli:     ccli,
+		rClient: NewPluginConsensusClient(bc),
+	}
+
+	// define commands
+	var (
+		createAddressFreezeTxCmd = &cobra.Command{
+			Use:   "addressfreezetransaction <address> <documenthash>",
+			Short: "Create a new address freeze transaction",
+			Long: `Create a new address freeze transaction for the given address,
+motivated by the (legal) document identified by the given (hex-encoded) hash.
+Once the transaction is created no outputs of the address can be spent any longer,
+allowing the unspent balance to be recovered to a new address using a coin recovery transaction.
+
+The returned (raw) AddressFreezeTransaction still has to be signed, prior to sending.
+	`,
+			Args: cobra.ExactArgs(2),
+			Run:  walletCmd.createAddressFreezeTxCmd,
+		}
+		createCoinRecoveryTxCmd = &cobra.Command{
+			Use:   "coinrecoverytransaction <frozenaddress>|<freezetxid> <dest>|<rawCondition>",
+			Short: "Create a new coin recovery transaction",
+			Long: `Create a new coin recovery transaction, recovering the unspent balance
+of the given frozen address to the given output condition (or address, which resolves to a singlesignature condition).
+
+The Minimum Miner Fee is paid from the recovered balance.
+
+The returned (raw) CoinRecoveryTransaction still has to be signed, prior to sending.
+	`,
+			Args: cobra.ExactArgs(2),
+			Run:  walletCmd.createCoinRecoveryTxCmd,
+		}
+		createAddressBlacklistTxCmd = &cobra.Command{
+			Use:   "addressblacklisttransaction <address> <documenthash>",
+			Short: "Create a new address blacklist transaction",
+			Long: `Create a new address blacklist transaction for the given address,
+as ordered by the (court) document identified by the given (hex-encoded) hash.
+Once the transaction is created no outputs of the address can be spent any longer,
+until the blacklisting expires or is lifted using an address blacklist lift transaction.
+
+The returned (raw) AddressBlacklistTransaction still has to be signed, prior to sending.
+	`,
+			Args: cobra.ExactArgs(2),
+			Run:  walletCmd.createAddressBlacklistTxCmd,
+		}
+		createAddressBlacklistLiftTxCmd = &cobra.Command{
+			Use:   "addressblacklistlifttransaction <address> <documenthash>",
+			Short: "Create a new address blacklist lift transaction",
+			Long: `Create a new address blacklist lift transaction for the given (blacklisted) address,
+as ordered by the (court) document identified by the given (hex-encoded) hash.
+Once the transaction is created the outputs of the address can be spent once again.
+
+The returned (raw) AddressBlacklistLiftTransaction still has to be signed, prior to sending.
+	`,
+			Args: cobra.ExactArgs(2),
+			Run:  walletCmd.createAddressBlacklistLiftTxCmd,
+		}
+	)
+
+	// add commands as wallet sub commands
+	ccli.WalletCmd.RootCmdCreate.AddCommand(
+		createAddressFreezeTxCmd,
+		createCoinRecoveryTxCmd,
+		createAddressBlacklistTxCmd,
+		createAddressBlacklistLiftTxCmd,
+	)
+
+	cli.ArbitraryDataFlagVar(createAddressFreezeTxCmd.Flags(), &walletCmd.addressFreezeTxCfg.Description,
+		"description", "optionally add a description to describe the reasons of the address freeze, added as arbitrary data")
+	cli.ArbitraryDataFlagVar(createCoinRecoveryTxCmd.Flags(), &walletCmd.coinRecoveryTxCfg.Description,
+		"description", "optionally add a description to describe the coin recovery, added as arbitrary data")
+	cli.ArbitraryDataFlagVar(createAddressBlacklistTxCmd.Flags(), &walletCmd.addressBlacklistTxCfg.Description,
+		"description", "optionally add a description to describe the reasons of the address blacklisting, added as arbitrary data")
+	createAddressBlacklistTxCmd.Flags().Uint64Var(&walletCmd.addressBlacklistTxCfg.ExpiryHeight,
+		"expiry", 0, "optionally define the block height since which the address is no longer blacklisted")
+	cli.ArbitraryDataFlagVar(createAddressBlacklistLiftTxCmd.Flags(), &walletCmd.addressBlacklistLiftTxCfg.Description,
+		"description", "optionally add a description to describe the reasons of the lifting, added as arbitrary data")
+
+	return nil
+}
+
+type walletCmd struct {
+	cli     *client.CommandLineClient
+	rClient *PluginClient
+
+	addressFreezeTxCfg struct {
+		Description []byte
+	}
+	coinRecoveryTxCfg struct {
+		Description []byte
+	}
+	addressBlacklistTxCfg struct {
+		Description  []byte
+		ExpiryHeight uint64
+	}
+	addressBlacklistLiftTxCfg struct {
+		Description []byte
+	}
+}
+
+func (walletCmd *walletCmd) createAddressFreezeTxCmd(cmd *cobra.Command, args []string) {
+	// create an address freeze tx with a random nonce and the minimum required miner fee
+	tx := rtypes.AddressFreezeTransaction{
+		Nonce:     types.RandomTransactionNonce(),
+		MinerFees: []types.Currency{walletCmd.cli.Config.MinimumTransactionFee},
+	}
+
+	if n := len(walletCmd.addressFreezeTxCfg.Description); n > 0 {
+		tx.ArbitraryData = make([]byte, n)
+		copy(tx.ArbitraryData[:], walletCmd.addressFreezeTxCfg.Description[:])
+	}
+
+	err := tx.Address.LoadString(args[0])
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.Die(fmt.Sprintf("invalid address %q: %v", args[0], err))
+	}
+	err = tx.DocumentHash.LoadString(args[1])
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.Die(fmt.Sprintf("invalid document hash %q: %v", args[1], err))
+	}
+	if tx.DocumentHash == (crypto.Hash{}) {
+		cmd.UsageFunc()(cmd)
+		cli.Die("a non-nil document hash is required")
+	}
+
+	// encode the transaction as a JSON-encoded string and print it to the STDOUT
+	err = json.NewEncoder(os.Stdout).Encode(tx.Transaction())
+	if err != nil {
+		cli.DieWithError("failed to encode address freeze transaction", err)
+	}
+}
+
+func (walletCmd *walletCmd) createCoinRecoveryTxCmd(cmd *cobra.Command, args []string) {
+	result, err := walletCmd.rClient.FrozenAddressForString(args[0])
+	if err != nil {
+		cli.DieWithError("error while fetching the frozen address", err)
+	}
+	if result.Record.IsRecovered() {
+		cli.Die(fmt.Sprintf("coins of frozen address %s are already recovered by tx %s",
+			result.Record.Address.String(), result.Record.RecoveryTransactionID.String()))
+	}
+	fee := walletCmd.cli.Config.MinimumTransactionFee
+	if result.Balance.Cmp(fee) <= 0 {
+		cli.Die(fmt.Sprintf("unspent balance %s of frozen address %s is too low to be recovered",
+			result.Balance.String(), result.Record.Address.String()))
+	}
+
+	condition, err := parseConditionString(args[1])
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.Die(err)
+	}
+
+	// create a coin recovery tx with a random nonce, recovering the unspent balance
+	// minus the minimum required miner fee
+	tx := rtypes.CoinRecoveryTransaction{
+		Nonce:               types.RandomTransactionNonce(),
+		FreezeTransactionID: result.Record.FreezeTransactionID,
+		CoinOutputs: []types.CoinOutput{
+			{
+				Value:     result.Balance.Sub(fee),
+				Condition: condition,
+			},
+		},
+		MinerFees: []types.Currency{fee},
+	}
+
+	if n := len(walletCmd.coinRecoveryTxCfg.Description); n > 0 {
+		tx.ArbitraryData = make([]byte, n)
+		copy(tx.ArbitraryData[:], walletCmd.coinRecoveryTxCfg.Description[:])
+	}
+
+	// encode the transaction as a JSON-encoded string and print it to the STDOUT
+	err = json.NewEncoder(os.Stdout).Encode(tx.Transaction())
+	if err != nil {
+		cli.DieWithError("failed to encode coin recovery transaction", err)
+	}
+}
+
+func (walletCmd *walletCmd) createAddressBlacklistTxCmd(cmd *cobra.Command, args []string) {
+	// create an address blacklist tx with a random nonce and the minimum required miner fee
+	tx := rtypes.AddressBlacklistTransaction{
+		Nonce:        types.RandomTransactionNonce(),
+		ExpiryHeight: types.BlockHeight(walletCmd.addressBlacklistTxCfg.ExpiryHeight),
+		MinerFees:    []types.Currency{walletCmd.cli.Config.MinimumTransactionFee},
+	}
+
+	if n := len(walletCmd.addressBlacklistTxCfg.Description); n > 0 {
+		tx.ArbitraryData = make([]byte, n)
+		copy(tx.ArbitraryData[:], walletCmd.addressBlacklistTxCfg.Description[:])
+	}
+
+	err := tx.Address.LoadString(args[0])
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.Die(fmt.Sprintf("invalid address %q: %v", args[0], err))
+	}
+	tx.DocumentHash, err = parseDocumentHash(args[1])
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.Die(err)
+	}
+
+	// encode the transaction as a JSON-encoded string and print it to the STDOUT
+	err = json.NewEncoder(os.Stdout).Encode(tx.Transaction())
+	if err != nil {
+		cli.DieWithError("failed to encode address blacklist transaction", err)
+	}
+}
+
+func (walletCmd *walletCmd) createAddressBlacklistLiftTxCmd(cmd *cobra.Command, args []string) {
+	// create an address blacklist lift tx with a random nonce and the minimum required miner fee
+	tx := rtypes.AddressBlacklistLiftTransaction{
+		Nonce:     types.RandomTransactionNonce(),
+		MinerFees: []types.Currency{walletCmd.cli.Config.MinimumTransactionFee},
+	}
+
+	if n := len(walletCmd.addressBlacklistLiftTxCfg.Description); n > 0 {
+		tx.ArbitraryData = make([]byte, n)
+		copy(tx.ArbitraryData[:], walletCmd.addressBlacklistLiftTxCfg.Description[:])
+	}
+
+	err := tx.Address.LoadString(args[0])
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.Die(fmt.Sprintf("invalid address %q: %v", args[0], err))
+	}
+	tx.DocumentHash, err = parseDocumentHash(args[1])
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.Die(err)
+	}
+
+	// encode the transaction as a JSON-encoded string and print it to the STDOUT
+	err = json.NewEncoder(os.Stdout).Encode(tx.Transaction())
+	if err != nil {
+		cli.DieWithError("failed to encode address blacklist lift transaction", err)
+	}
+}
+
+func parseDocumentHash(str string) (hash crypto.Hash, err error) {
+	err = hash.LoadString(str)
+	if err != nil {
+		return crypto.Hash{}, fmt.Errorf("invalid document hash %q: %v", str, err)
+	}
+	if hash == (crypto.Hash{}) {
+		return crypto.Hash{}, errors.New("a non-nil document hash is required")
+	}
+	return hash, nil
+}
+
+func parseConditionString(str string) (condition types.UnlockConditionProxy, err error) {
+	// try to parse it as an unlock hash
+	var uh types.UnlockHash
+	err = uh.LoadString(str)
+	if err == nil {
+		// parsing as an unlock hash was succesfull
+		condition = types.NewCondition(types.NewUnlockHashCondition(uh))
+		return
+	}
+
+	// try to parse it as a JSON-encoded unlock condition
+	err = condition.UnmarshalJSON([]byte(str))
+	if err != nil {
+		return types.UnlockConditionProxy{}, fmt.Errorf(
+			"condition has to be UnlockHash or JSON-encoded UnlockCondition, output %q is neither", str)
+	}
+	return
+}
@@ -0,0 +1,1113 @@
+package recovery
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/extensions/minting"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/persist"
+	"github.com/threefoldtech/rivine/pkg/encoding/rivbin"
+	"github.com/threefoldtech/rivine/types"
+
+	rtypes "github.com/threefoldfoundation/tfchain/extensions/recovery/types"
+
+	bolt "github.com/rivine/bbolt"
+)
+
+const (
+	pluginDBVersion = "1.0.0.0"
+	pluginDBHeader  = "recoveryPlugin"
+)
+
+var (
+	bucketMintConditions     = []byte("mintconditions")   // height => mint condition
+	bucketBalances           = []byte("balances")         // address => unspent coin balance
+	bucketFrozenAddresses    = []byte("frozenaddresses")  // address => FrozenAddress
+	bucketFreezeTransactions = []byte("freezetxs")        // txID => address
+	bucketBlacklistHistory   = []byte("blacklisthistory") // address => []BlacklistEvent
+
+	bucketSlice = [][]byte{
+		bucketMintConditions,
+		bucketBalances,
+		bucketFrozenAddresses,
+		bucketFreezeTransactions,
+		bucketBlacklistHistory,
+	}
+)
+
+type (
+	// Plugin is a struct defines the recovery plugin,
+	// used to freeze the addresses of which the owner lost the seed,
+	// and recover the unspent coins of those addresses to a new address.
+	// It is also used to blacklist addresses as ordered by a court,
+	// e.g. because they hold stolen funds.
+	//
+	// The plugin keeps track of the mint condition itself,
+	// as the mint condition has to be looked up within the same
+	// DB transaction as the one used to validate the recovery transactions.
+	// It also keeps track of the unspent coin balance of all addresses,
+	// such that the recovered value can be validated.
+	Plugin struct {
+		genesisMintCondition               types.UnlockConditionProxy
+		minterDefinitionTransactionVersion types.TransactionVersion
+
+		storage            modules.PluginViewStorage
+		unregisterCallback modules.PluginUnregisterCallback
+	}
+)
+
+var (
+	_ modules.ConsensusSetPlugin            = (*Plugin)(nil)
+	_ minting.MintConditionGetter           = (*Plugin)(nil)
+	_ rtypes.FrozenAddressReadRegistry      = (*Plugin)(nil)
+	_ rtypes.BlacklistedAddressReadRegistry = (*Plugin)(nil)
+)
+
+// NewPlugin creates a new recovery Plugin,
+// using the genesis mint condition and the version of the minter definition transactions
+// to keep track of the mint condition, which has to be fulfilled by all recovery transactions.
+func NewPlugin(genesisMintCondition types.UnlockConditionProxy, minterDefinitionTransactionVersion types.TransactionVersion) *Plugin {
+	p := &Plugin{
+		genesisMintCondition:               genesisMintCondition,
+		minterDefinitionTransactionVersion: minterDefinitionTransactionVersion,
+	}
+	types.RegisterTransactionVersion(rtypes.TransactionVersionAddressFreeze, rtypes.AddressFreezeTransactionController{
+		MintConditionGetter: p,
+	})
+	types.RegisterTransactionVersion(rtypes.TransactionVersionCoinRecovery, rtypes.CoinRecoveryTransactionController{
+		MintConditionGetter: p,
+	})
+	types.RegisterTransactionVersion(rtypes.TransactionVersionAddressBlacklist, rtypes.AddressBlacklistTransactionController{
+		MintConditionGetter: p,
+	})
+	types.RegisterTransactionVersion(rtypes.TransactionVersionAddressBlacklistLift, rtypes.AddressBlacklistLiftTransactionController{
+		MintConditionGetter: p,
+	})
+	return p
+}
+
+// InitPlugin initializes the Bucket for the first time
+func (p *Plugin) InitPlugin(metadata *persist.Metadata, bucket *bolt.Bucket, storage modules.PluginViewStorage, unregisterCallback modules.PluginUnregisterCallback) (persist.Metadata, error) {
+	p.storage = storage
+	p.unregisterCallback = unregisterCallback
+	if metadata == nil {
+		err := createBuckets(bucket)
+		if err != nil {
+			return persist.Metadata{}, err
+		}
+
+		mintcond, err := rivbin.Marshal(p.genesisMintCondition)
+		if err != nil {
+			return persist.Metadata{}, fmt.Errorf("failed to marshal genesis mint condition: %v", err)
+		}
+		err = bucket.Bucket(bucketMintConditions).Put(encodeBlockheight(0), mintcond)
+		if err != nil {
+			return persist.Metadata{}, fmt.Errorf("failed to store genesis mint condition: %v", err)
+		}
+
+		metadata = &persist.Metadata{
+			Version: pluginDBVersion,
+			Header:  pluginDBHeader,
+		}
+	} else if metadata.Version != pluginDBVersion {
+		return persist.Metadata{}, errors.New("There is only 1 version of this plugin, version mismatch")
+	} else {
+		// buckets added since the plugin was first released
+		// are created for existing databases as well
+		err := createBuckets(bucket)
+		if err != nil {
+			return persist.Metadata{}, err
+		}
+	}
+	return *metadata, nil
+}
+
+// createBuckets creates all buckets of the plugin which do not exist yet
+func createBuckets(bucket *bolt.Bucket) error {
+	for _, bucketName := range bucketSlice {
+		b := bucket.Bucket([]byte(bucketName))
+		if b == nil {
+			_, err := bucket.CreateBucket([]byte(bucketName))
+			if err != nil {
+				return fmt.Errorf("failed to create bucket %s: %v", string(bucketName), err)
+			}
+		}
+	}
+	return nil
+}
+
+// GetActiveMintCondition implements minting.MintConditionGetter.GetActiveMintCondition
+func (p *Plugin) GetActiveMintCondition() (mintCondition types.UnlockConditionProxy, err error) {
+	err = p.storage.View(func(bucket *bolt.Bucket) error {
+		mintConditionBucket := bucket.Bucket(bucketMintConditions)
+		if mintConditionBucket == nil {
+			return errors.New("corrupt recovery plugin DB: mint condition bucket does not exist")
+		}
+		mintCondition, err = getActiveMintCondition(mintConditionBucket)
+		return err
+	})
+	return
+}
+
+// GetMintConditionAt implements minting.MintConditionGetter.GetMintConditionAt
+func (p *Plugin) GetMintConditionAt(height types.BlockHeight) (mintCondition types.UnlockConditionProxy, err error) {
+	err = p.storage.View(func(bucket *bolt.Bucket) error {
+		mintConditionBucket := bucket.Bucket(bucketMintConditions)
+		if mintConditionBucket == nil {
+			return errors.New("corrupt recovery plugin DB: mint condition bucket does not exist")
+		}
+		mintCondition, err = getMintConditionAt(mintConditionBucket, height)
+		return err
+	})
+	return
+}
+
+// GetFrozenAddress implements FrozenAddressReadRegistry.GetFrozenAddress
+func (p *Plugin) GetFrozenAddress(address types.UnlockHash) (fa rtypes.FrozenAddress, err error) {
+	err = p.storage.View(func(bucket *bolt.Bucket) error {
+		fa, err = getFrozenAddress(bucket, address)
+		return err
+	})
+	return
+}
+
+// GetFrozenAddressForTransaction implements FrozenAddressReadRegistry.GetFrozenAddressForTransaction
+func (p *Plugin) GetFrozenAddressForTransaction(id types.TransactionID) (fa rtypes.FrozenAddress, err error) {
+	err = p.storage.View(func(bucket *bolt.Bucket) error {
+		address, err := getAddressForFreezeTransaction(bucket, id)
+		if err != nil {
+			return err
+		}
+		fa, err = getFrozenAddress(bucket, address)
+		return err
+	})
+	return
+}
+
+// GetUnspentBalance implements FrozenAddressReadRegistry.GetUnspentBalance
+func (p *Plugin) GetUnspentBalance(address types.UnlockHash) (balance types.Currency, err error) {
+	err = p.storage.View(func(bucket *bolt.Bucket) error {
+		balance, err = getUnspentBalance(bucket, address)
+		return err
+	})
+	return
+}
+
+// GetAddressBlacklistHistory implements BlacklistedAddressReadRegistry.GetAddressBlacklistHistory
+func (p *Plugin) GetAddressBlacklistHistory(address types.UnlockHash) (history []rtypes.BlacklistEvent, err error) {
+	err = p.storage.View(func(bucket *bolt.Bucket) error {
+		history, err = getBlacklistHistory(bucket, address)
+		if err != nil {
+			return err
+		}
+		if len(history) == 0 {
+			return rtypes.ErrAddressNotBlacklisted
+		}
+		return nil
+	})
+	return
+}
+
+// ApplyBlock applies a block's transactions and miner payouts to the recovery bucket.
+func (p *Plugin) ApplyBlock(block modules.ConsensusBlock, bucket *persist.LazyBoltBucket) error {
+	if bucket == nil {
+		return errors.New("recovery bucket does not exist")
+	}
+	var err error
+	for idx, txn := range block.Transactions {
+		cTxn := modules.ConsensusTransaction{
+			Transaction:            txn,
+			BlockHeight:            block.Height,
+			BlockTime:              block.Timestamp,
+			SequenceID:             uint16(idx),
+			SpentCoinOutputs:       block.SpentCoinOutputs,
+			SpentBlockStakeOutputs: block.SpentBlockStakeOutputs,
+		}
+		err = p.ApplyTransaction(cTxn, bucket)
+		if err != nil {
+			return err
+		}
+	}
+	return applyMinerPayouts(bucket, block.MinerPayouts)
+}
+
+// ApplyBlockHeader applies a block's miner payouts to the recovery bucket.
+func (p *Plugin) ApplyBlockHeader(header modules.ConsensusBlockHeader, bucket *persist.LazyBoltBucket) error {
+	if bucket == nil {
+		return errors.New("recovery bucket does not exist")
+	}
+	return applyMinerPayouts(bucket, header.MinerPayouts)
+}
+
+// ApplyTransaction applies a transaction to the recovery bucket,
+// updating the unspent coin balances and applying the recovery (and minter definition) transactions.
+func (p *Plugin) ApplyTransaction(txn modules.ConsensusTransaction, bucket *persist.LazyBoltBucket) error {
+	if bucket == nil {
+		return errors.New("recovery bucket does not exist")
+	}
+	err := applyCoinBalances(bucket, txn)
+	if err != nil {
+		return err
+	}
+	// check the version and handle the ones we care about
+	switch txn.Version {
+	case p.minterDefinitionTransactionVersion:
+		err = p.applyMinterDefinitionTx(txn, bucket)
+	case rtypes.TransactionVersionAddressFreeze:
+		err = p.applyAddressFreezeTx(txn, bucket)
+	case rtypes.TransactionVersionCoinRecovery:
+		err = p.applyCoinRecoveryTx(txn, bucket)
+	case rtypes.TransactionVersionAddressBlacklist:
+		err = p.applyAddressBlacklistTx(txn, bucket)
+	case rtypes.TransactionVersionAddressBlacklistLift:
+		err = p.applyAddressBlacklistLiftTx(txn, bucket)
+	}
+	return err
+}
+
+func (p *Plugin) applyMinterDefinitionTx(txn modules.ConsensusTransaction, bucket *persist.LazyBoltBucket) error {
+	mdtx, err := minting.MinterDefinitionTransactionFromTransaction(txn.Transaction, p.minterDefinitionTransactionVersion, true)
+	if err != nil {
+		return fmt.Errorf("unexpected error while unpacking the minter def. tx type: %v", err)
+	}
+	mintConditionBucket, err := bucket.Bucket(bucketMintConditions)
+	if err != nil {
+		return fmt.Errorf("corrupt recovery plugin DB: %v", err)
+	}
+	mintcond, err := rivbin.Marshal(mdtx.MintCondition)
+	if err != nil {
+		return fmt.Errorf("failed to marshal mint condition: %v", err)
+	}
+	err = mintConditionBucket.Put(encodeBlockheight(txn.BlockHeight), mintcond)
+	if err != nil {
+		return fmt.Errorf(
+			"failed to put mint condition for block height %d: %v",
+			txn.BlockHeight, err)
+	}
+	return nil
+}
+
+func (p *Plugin) applyAddressFreezeTx(txn modules.ConsensusTransaction, bucket *persist.LazyBoltBucket) error {
+	aftx, err := rtypes.AddressFreezeTransactionFromTransaction(txn.Transaction)
+	if err != nil {
+		return fmt.Errorf("unexpected error while unpacking the address freeze tx type: %v", err)
+	}
+	txID := txn.ID()
+	err = putFrozenAddress(bucket, rtypes.FrozenAddress{
+		Address:             aftx.Address,
+		FreezeTransactionID: txID,
+		FreezeHeight:        txn.BlockHeight,
+		DocumentHash:        aftx.DocumentHash,
+	})
+	if err != nil {
+		return err
+	}
+	freezeTxBucket, err := bucket.Bucket(bucketFreezeTransactions)
+	if err != nil {
+		return fmt.Errorf("corrupt recovery plugin DB: %v", err)
+	}
+	err = freezeTxBucket.Put(encodeTransactionID(txID), encodeAddress(aftx.Address))
+	if err != nil {
+		return fmt.Errorf("failed to link freeze tx %s to address %s: %v", txID.String(), aftx.Address.String(), err)
+	}
+	return nil
+}
+
+func (p *Plugin) applyCoinRecoveryTx(txn modules.ConsensusTransaction, bucket *persist.LazyBoltBucket) error {
+	crtx, err := rtypes.CoinRecoveryTransactionFromTransaction(txn.Transaction)
+	if err != nil {
+		return fmt.Errorf("unexpected error while unpacking the coin recovery tx type: %v", err)
+	}
+	rootBucket, err := bucket.AsBoltBucket()
+	if err != nil {
+		return fmt.Errorf("failed to cast passed bucket as a bolt bucket: %v", err)
+	}
+	address, err := getAddressForFreezeTransaction(rootBucket, crtx.FreezeTransactionID)
+	if err != nil {
+		return err
+	}
+	fa, err := getFrozenAddress(rootBucket, address)
+	if err != nil {
+		return err
+	}
+	txID := txn.ID()
+	fa.RecoveryTransactionID = &txID
+	return putFrozenAddress(bucket, fa)
+}
+
+func (p *Plugin) applyAddressBlacklistTx(txn modules.ConsensusTransaction, bucket *persist.LazyBoltBucket) error {
+	abtx, err := rtypes.AddressBlacklistTransactionFromTransaction(txn.Transaction)
+	if err != nil {
+		return fmt.Errorf("unexpected error while unpacking the address blacklist tx type: %v", err)
+	}
+	return pushBlacklistEvent(bucket, abtx.Address, rtypes.BlacklistEvent{
+		Action:        rtypes.BlacklistActionBlacklist,
+		TransactionID: txn.ID(),
+		BlockHeight:   txn.BlockHeight,
+		DocumentHash:  abtx.DocumentHash,
+		ExpiryHeight:  abtx.ExpiryHeight,
+	})
+}
+
+func (p *Plugin) applyAddressBlacklistLiftTx(txn modules.ConsensusTransaction, bucket *persist.LazyBoltBucket) error {
+	abltx, err := rtypes.AddressBlacklistLiftTransactionFromTransaction(txn.Transaction)
+	if err != nil {
+		return fmt.Errorf("unexpected error while unpacking the address blacklist lift tx type: %v", err)
+	}
+	return pushBlacklistEvent(bucket, abltx.Address, rtypes.BlacklistEvent{
+		Action:        rtypes.BlacklistActionLift,
+		TransactionID: txn.ID(),
+		BlockHeight:   txn.BlockHeight,
+		DocumentHash:  abltx.DocumentHash,
+	})
+}
+
+// RevertBlock reverts a block's transactions and miner payouts from the recovery bucket.
+func (p *Plugin) RevertBlock(block modules.ConsensusBlock, bucket *persist.LazyBoltBucket) error {
+	if bucket == nil {
+		return errors.New("recovery bucket does not exist")
+	}
+	// revert in the opposite order as the block was applied,
+	// such that the unspent balances never become negative
+	err := revertMinerPayouts(bucket, block.MinerPayouts)
+	if err != nil {
+		return err
+	}
+	for idx := len(block.Transactions) - 1; idx >= 0; idx-- {
+		cTxn := modules.ConsensusTransaction{
+			Transaction:            block.Transactions[idx],
+			BlockHeight:            block.Height,
+			BlockTime:              block.Timestamp,
+			SequenceID:             uint16(idx),
+			SpentCoinOutputs:       block.SpentCoinOutputs,
+			SpentBlockStakeOutputs: block.SpentBlockStakeOutputs,
+		}
+		err = p.RevertTransaction(cTxn, bucket)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RevertBlockHeader reverts a block's miner payouts from the recovery bucket.
+func (p *Plugin) RevertBlockHeader(header modules.ConsensusBlockHeader, bucket *persist.LazyBoltBucket) error {
+	if bucket == nil {
+		return errors.New("recovery bucket does not exist")
+	}
+	return revertMinerPayouts(bucket, header.MinerPayouts)
+}
+
+// RevertTransaction reverts a transaction from the recovery bucket.
+func (p *Plugin) RevertTransaction(txn modules.ConsensusTransaction, bucket *persist.LazyBoltBucket) error {
+	if bucket == nil {
+		return errors.New("recovery bucket does not exist")
+	}
+	var err error
+	switch txn.Version {
+	case p.minterDefinitionTransactionVersion:
+		err = p.revertMinterDefinitionTx(txn, bucket)
+	case rtypes.TransactionVersionAddressFreeze:
+		err = p.revertAddressFreezeTx(txn, bucket)
+	case rtypes.TransactionVersionCoinRecovery:
+		err = p.revertCoinRecoveryTx(txn, bucket)
+	case rtypes.TransactionVersionAddressBlacklist:
+		err = p.revertAddressBlacklistTx(txn, bucket)
+	case rtypes.TransactionVersionAddressBlacklistLift:
+		err = p.revertAddressBlacklistLiftTx(txn, bucket)
+	}
+	if err != nil {
+		return err
+	}
+	return revertCoinBalances(bucket, txn)
+}
+
+func (p *Plugin) revertMinterDefinitionTx(txn modules.ConsensusTransaction, bucket *persist.LazyBoltBucket) error {
+	mintConditionBucket, err := bucket.Bucket(bucketMintConditions)
+	if err != nil {
+		return fmt.Errorf("corrupt recovery plugin DB: %v", err)
+	}
+	err = mintConditionBucket.Delete(encodeBlockheight(txn.BlockHeight))
+	if err != nil {
+		return fmt.Errorf(
+			"failed to delete mint condition for block height %d: %v",
+			txn.BlockHeight, err)
+	}
+	return nil
+}
+
+func (p *Plugin) revertAddressFreezeTx(txn modules.ConsensusTransaction, bucket *persist.LazyBoltBucket) error {
+	aftx, err := rtypes.AddressFreezeTransactionFromTransaction(txn.Transaction)
+	if err != nil {
+		return fmt.Errorf("unexpected error while unpacking the address freeze tx type: %v", err)
+	}
+	frozenBucket, err := bucket.Bucket(bucketFrozenAddresses)
+	if err != nil {
+		return fmt.Errorf("corrupt recovery plugin DB: %v", err)
+	}
+	err = frozenBucket.Delete(encodeAddress(aftx.Address))
+	if err != nil {
+		return fmt.Errorf("failed to delete frozen address %s: %v", aftx.Address.String(), err)
+	}
+	freezeTxBucket, err := bucket.Bucket(bucketFreezeTransactions)
+	if err != nil {
+		return fmt.Errorf("corrupt recovery plugin DB: %v", err)
+	}
+	txID := txn.ID()
+	err = freezeTxBucket.Delete(encodeTransactionID(txID))
+	if err != nil {
+		return fmt.Errorf("failed to delete freeze tx %s: %v", txID.String(), err)
+	}
+	return nil
+}
+
+func (p *Plugin) revertCoinRecoveryTx(txn modules.ConsensusTransaction, bucket *persist.LazyBoltBucket) error {
+	crtx, err := rtypes.CoinRecoveryTransactionFromTransaction(txn.Transaction)
+	if err != nil {
+		return fmt.Errorf("unexpected error while unpacking the coin recovery tx type: %v", err)
+	}
+	rootBucket, err := bucket.AsBoltBucket()
+	if err != nil {
+		return fmt.Errorf("failed to cast passed bucket as a bolt bucket: %v", err)
+	}
+	address, err := getAddressForFreezeTransaction(rootBucket, crtx.FreezeTransactionID)
+	if err != nil {
+		return err
+	}
+	fa, err := getFrozenAddress(rootBucket, address)
+	if err != nil {
+		return err
+	}
+	fa.RecoveryTransactionID = nil
+	return putFrozenAddress(bucket, fa)
+}
+
+func (p *Plugin) revertAddressBlacklistTx(txn modules.ConsensusTransaction, bucket *persist.LazyBoltBucket) error {
+	abtx, err := rtypes.AddressBlacklistTransactionFromTransaction(txn.Transaction)
+	if err != nil {
+		return fmt.Errorf("unexpected error while unpacking the address blacklist tx type: %v", err)
+	}
+	return popBlacklistEvent(bucket, abtx.Address, txn.ID())
+}
+
+func (p *Plugin) revertAddressBlacklistLiftTx(txn modules.ConsensusTransaction, bucket *persist.LazyBoltBucket) error {
+	abltx, err := rtypes.AddressBlacklistLiftTransactionFromTransaction(txn.Transaction)
+	if err != nil {
+		return fmt.Errorf("unexpected error while unpacking the address blacklist lift tx type: %v", err)
+	}
+	return popBlacklistEvent(bucket, abltx.Address, txn.ID())
+}
+
+// TransactionValidators returns all tx validators linked to this plugin,
+// ensuring that no coin outputs of frozen or blacklisted addresses can be spent,
+// and that no coins can be sent to frozen addresses.
+func (p *Plugin) TransactionValidators() []modules.PluginTransactionValidationFunction {
+	return []modules.PluginTransactionValidationFunction{
+		p.validateNoFrozenInputs,
+		p.validateNoFrozenOutputs,
+	}
+}
+
+// TransactionValidatorVersionFunctionMapping returns all tx validators linked to this plugin
+func (p *Plugin) TransactionValidatorVersionFunctionMapping() map[types.TransactionVersion][]modules.PluginTransactionValidationFunction {
+	return map[types.TransactionVersion][]modules.PluginTransactionValidationFunction{
+		rtypes.TransactionVersionAddressFreeze: {
+			p.validateAddressFreezeTx,
+		},
+		rtypes.TransactionVersionCoinRecovery: {
+			p.validateCoinRecoveryTx,
+		},
+		rtypes.TransactionVersionAddressBlacklist: {
+			p.validateAddressBlacklistTx,
+		},
+		rtypes.TransactionVersionAddressBlacklistLift: {
+			p.validateAddressBlacklistLiftTx,
+		},
+	}
+}
+
+// validateNoFrozenInputs ensures that no coin outputs of frozen addresses,
+// or of addresses blacklisted at the context-defined block height, can be spent.
+// Block stake outputs of frozen and blacklisted addresses are not frozen,
+// as these are respent by the block creator for each block it creates.
+func (p *Plugin) validateNoFrozenInputs(txn modules.ConsensusTransaction, ctx types.TransactionValidationContext, bucket *persist.LazyBoltBucket) error {
+	if len(txn.CoinInputs) == 0 {
+		return nil // nothing to validate
+	}
+	frozenBucket, err := bucket.Bucket(bucketFrozenAddresses)
+	if err != nil {
+		return fmt.Errorf("corrupt recovery plugin DB: %v", err)
+	}
+	rootBucket, err := bucket.AsBoltBucket()
+	if err != nil {
+		return fmt.Errorf("failed to cast passed bucket as a bolt bucket: %v", err)
+	}
+	for _, ci := range txn.CoinInputs {
+		co, ok := txn.SpentCoinOutputs[ci.ParentID]
+		if !ok {
+			continue // validated by the standard validators
+		}
+		uh := co.Condition.UnlockHash()
+		if len(frozenBucket.Get(encodeAddress(uh))) != 0 {
+			return fmt.Errorf("coin output %s cannot be spent: address %s is frozen", ci.ParentID.String(), uh.String())
+		}
+		event, ok, err := getLastBlacklistEvent(rootBucket, uh)
+		if err != nil {
+			return err
+		}
+		if ok && event.IsActiveAt(ctx.BlockHeight) {
+			return fmt.Errorf("coin output %s cannot be spent: address %s is blacklisted", ci.ParentID.String(), uh.String())
+		}
+	}
+	return nil
+}
+
+// validateNoFrozenOutputs ensures that no coins can be sent to frozen addresses,
+// as the coins of a frozen address can only be recovered once,
+// and coins received after that recovery would remain locked forever.
+func (p *Plugin) validateNoFrozenOutputs(txn modules.ConsensusTransaction, ctx types.TransactionValidationContext, bucket *persist.LazyBoltBucket) error {
+	if len(txn.CoinOutputs) == 0 {
+		return nil // nothing to validate
+	}
+	frozenBucket, err := bucket.Bucket(bucketFrozenAddresses)
+	if err != nil {
+		return fmt.Errorf("corrupt recovery plugin DB: %v", err)
+	}
+	for idx, co := range txn.CoinOutputs {
+		uh := co.Condition.UnlockHash()
+		if len(frozenBucket.Get(encodeAddress(uh))) != 0 {
+			return fmt.Errorf("coin output #%d cannot be sent to frozen address %s", idx, uh.String())
+		}
+	}
+	return nil
+}
+
+func (p *Plugin) validateAddressFreezeTx(txn modules.ConsensusTransaction, ctx types.TransactionValidationContext, bucket *persist.LazyBoltBucket) error {
+	aftx, err := rtypes.AddressFreezeTransactionFromTransaction(txn.Transaction)
+	if err != nil {
+		return fmt.Errorf("failed to use tx as an address freeze tx: %v", err)
+	}
+	// ensure the Nonce is not Nil
+	if aftx.Nonce == (types.TransactionNonce{}) {
+		return errors.New("nil nonce is not allowed for an address freeze transaction")
+	}
+	err = validateFreezeAddress(aftx.Address)
+	if err != nil {
+		return err
+	}
+	// a document hash is required, as proof of the motivation to freeze the address
+	if aftx.DocumentHash == (crypto.Hash{}) {
+		return errors.New("nil document hash is not allowed for an address freeze transaction")
+	}
+
+	rootBucket, err := bucket.AsBoltBucket()
+	if err != nil {
+		return fmt.Errorf("failed to cast passed bucket as a bolt bucket: %v", err)
+	}
+	// an address can be frozen only once
+	_, err = getFrozenAddress(rootBucket, aftx.Address)
+	if err == nil {
+		return rtypes.ErrAddressAlreadyFrozen
+	}
+	if err != rtypes.ErrAddressNotFrozen {
+		return fmt.Errorf("unexpected error while validating the address is not frozen yet: %v", err)
+	}
+
+	// check if MintFulfillment fulfills the Globally defined MintCondition for the context-defined block height
+	err = p.fulfillMintCondition(rootBucket, aftx.MintFulfillment, txn, ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fulfill mint condition for address freeze transaction: %v", err)
+	}
+
+	// validate the miner fee
+	for _, fee := range aftx.MinerFees {
+		if fee.Cmp(ctx.MinimumMinerFee) == -1 {
+			return types.ErrTooSmallMinerFee
+		}
+	}
+	return nil
+}
+
+func (p *Plugin) validateCoinRecoveryTx(txn modules.ConsensusTransaction, ctx types.TransactionValidationContext, bucket *persist.LazyBoltBucket) error {
+	crtx, err := rtypes.CoinRecoveryTransactionFromTransaction(txn.Transaction)
+	if err != nil {
+		return fmt.Errorf("failed to use tx as a coin recovery tx: %v", err)
+	}
+	// ensure the Nonce is not Nil
+	if crtx.Nonce == (types.TransactionNonce{}) {
+		return errors.New("nil nonce is not allowed for a coin recovery transaction")
+	}
+
+	rootBucket, err := bucket.AsBoltBucket()
+	if err != nil {
+		return fmt.Errorf("failed to cast passed bucket as a bolt bucket: %v", err)
+	}
+	// the referenced freeze transaction has to exist,
+	// and the coins of the address it froze cannot be recovered yet
+	address, err := getAddressForFreezeTransaction(rootBucket, crtx.FreezeTransactionID)
+	if err != nil {
+		return err
+	}
+	fa, err := getFrozenAddress(rootBucket, address)
+	if err != nil {
+		return err
+	}
+	if fa.IsRecovered() {
+		return rtypes.ErrCoinsAlreadyRecovered
+	}
+
+	// the recovered value has to equal the unspent balance of the frozen address
+	balance, err := getUnspentBalance(rootBucket, address)
+	if err != nil {
+		return err
+	}
+	err = validateRecoveredValue(crtx, address, balance)
+	if err != nil {
+		return err
+	}
+
+	// check if MintFulfillment fulfills the Globally defined MintCondition for the context-defined block height
+	err = p.fulfillMintCondition(rootBucket, crtx.MintFulfillment, txn, ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fulfill mint condition for coin recovery transaction: %v", err)
+	}
+
+	// validate the miner fee
+	for _, fee := range crtx.MinerFees {
+		if fee.Cmp(ctx.MinimumMinerFee) == -1 {
+			return types.ErrTooSmallMinerFee
+		}
+	}
+	return nil
+}
+
+func (p *Plugin) validateAddressBlacklistTx(txn modules.ConsensusTransaction, ctx types.TransactionValidationContext, bucket *persist.LazyBoltBucket) error {
+	abtx, err := rtypes.AddressBlacklistTransactionFromTransaction(txn.Transaction)
+	if err != nil {
+		return fmt.Errorf("failed to use tx as an address blacklist tx: %v", err)
+	}
+	// ensure the Nonce is not Nil
+	if abtx.Nonce == (types.TransactionNonce{}) {
+		return errors.New("nil nonce is not allowed for an address blacklist transaction")
+	}
+	err = validateFreezeAddress(abtx.Address)
+	if err != nil {
+		return err
+	}
+	// a document hash is required, as proof of the (court) order to blacklist the address
+	if abtx.DocumentHash == (crypto.Hash{}) {
+		return errors.New("nil document hash is not allowed for an address blacklist transaction")
+	}
+	err = validateBlacklistExpiryHeight(abtx.ExpiryHeight, ctx.BlockHeight)
+	if err != nil {
+		return err
+	}
+
+	rootBucket, err := bucket.AsBoltBucket()
+	if err != nil {
+		return fmt.Errorf("failed to cast passed bucket as a bolt bucket: %v", err)
+	}
+	// an address can only be blacklisted again once its previous blacklisting expired or was lifted
+	event, ok, err := getLastBlacklistEvent(rootBucket, abtx.Address)
+	if err != nil {
+		return err
+	}
+	if ok && event.IsActiveAt(ctx.BlockHeight) {
+		return rtypes.ErrAddressAlreadyBlacklisted
+	}
+
+	// check if MintFulfillment fulfills the Globally defined MintCondition for the context-defined block height
+	err = p.fulfillMintCondition(rootBucket, abtx.MintFulfillment, txn, ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fulfill mint condition for address blacklist transaction: %v", err)
+	}
+
+	// validate the miner fee
+	for _, fee := range abtx.MinerFees {
+		if fee.Cmp(ctx.MinimumMinerFee) == -1 {
+			return types.ErrTooSmallMinerFee
+		}
+	}
+	return nil
+}
+
+func (p *Plugin) validateAddressBlacklistLiftTx(txn modules.ConsensusTransaction, ctx types.TransactionValidationContext, bucket *persist.LazyBoltBucket) error {
+	abltx, err := rtypes.AddressBlacklistLiftTransactionFromTransaction(txn.Transaction)
+	if err != nil {
+		return fmt.Errorf("failed to use tx as an address blacklist lift tx: %v", err)
+	}
+	// ensure the Nonce is not Nil
+	if abltx.Nonce == (types.TransactionNonce{}) {
+		return errors.New("nil nonce is not allowed for an address blacklist lift transaction")
+	}
+	// a document hash is required, as proof of the (court) order to lift the blacklisting
+	if abltx.DocumentHash == (crypto.Hash{}) {
+		return errors.New("nil document hash is not allowed for an address blacklist lift transaction")
+	}
+
+	rootBucket, err := bucket.AsBoltBucket()
+	if err != nil {
+		return fmt.Errorf("failed to cast passed bucket as a bolt bucket: %v", err)
+	}
+	// only an active blacklisting can be lifted
+	event, ok, err := getLastBlacklistEvent(rootBucket, abltx.Address)
+	if err != nil {
+		return err
+	}
+	if !ok || !event.IsActiveAt(ctx.BlockHeight) {
+		return rtypes.ErrAddressNotBlacklisted
+	}
+
+	// check if MintFulfillment fulfills the Globally defined MintCondition for the context-defined block height
+	err = p.fulfillMintCondition(rootBucket, abltx.MintFulfillment, txn, ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fulfill mint condition for address blacklist lift transaction: %v", err)
+	}
+
+	// validate the miner fee
+	for _, fee := range abltx.MinerFees {
+		if fee.Cmp(ctx.MinimumMinerFee) == -1 {
+			return types.ErrTooSmallMinerFee
+		}
+	}
+	return nil
+}
+
+// fulfillMintCondition checks if the given fulfillment fulfills the mint condition
+// active at the block height defined by the validation context
+func (p *Plugin) fulfillMintCondition(rootBucket *bolt.Bucket, fulfillment types.UnlockFulfillmentProxy, txn modules.ConsensusTransaction, ctx types.TransactionValidationContext) error {
+	mintConditionBucket := rootBucket.Bucket(bucketMintConditions)
+	if mintConditionBucket == nil {
+		return errors.New("corrupt recovery plugin DB: mint condition bucket does not exist")
+	}
+	var (
+		mintCondition types.UnlockConditionProxy
+		err           error
+	)
+	if ctx.Confirmed || ctx.BlockHeight > 0 {
+		mintCondition, err = getMintConditionAt(mintConditionBucket, ctx.BlockHeight)
+	} else {
+		mintCondition, err = getActiveMintCondition(mintConditionBucket)
+	}
+	if err != nil {
+		return err
+	}
+	return mintCondition.Fulfill(fulfillment, types.FulfillContext{
+		BlockHeight: ctx.BlockHeight,
+		BlockTime:   ctx.BlockTime,
+		Transaction: txn.Transaction,
+	})
+}
+
+// Close unregisters the plugin from the consensus
+func (p *Plugin) Close() error {
+	if p.storage == nil {
+		return nil
+	}
+	return p.storage.Close()
+}
+
+func getActiveMintCondition(mintConditionBucket *bolt.Bucket) (types.UnlockConditionProxy, error) {
+	k, b := mintConditionBucket.Cursor().Last()
+	if len(k) == 0 {
+		return types.UnlockConditionProxy{}, errors.New("corrupt recovery plugin DB: no mint condition could be found")
+	}
+	var mintCondition types.UnlockConditionProxy
+	err := rivbin.Unmarshal(b, &mintCondition)
+	if err != nil {
+		return types.UnlockConditionProxy{}, fmt.Errorf("corrupt recovery plugin DB: failed to decode found mint condition: %v", err)
+	}
+	return mintCondition, nil
+}
+
+func getMintConditionAt(mintConditionBucket *bolt.Bucket, height types.BlockHeight) (types.UnlockConditionProxy, error) {
+	cursor := mintConditionBucket.Cursor()
+	k, b := cursor.Seek(encodeBlockheight(height))
+	if len(k) == 0 {
+		// could be that we're past the last key, use the last key in that case
+		k, b = cursor.Last()
+	} else if decodeBlockheight(k) > height {
+		// the mint condition we need was defined earlier
+		k, b = cursor.Prev()
+	}
+	if len(k) == 0 {
+		return types.UnlockConditionProxy{}, fmt.Errorf("corrupt recovery plugin DB: no mint condition could be found for height %d", height)
+	}
+	var mintCondition types.UnlockConditionProxy
+	err := rivbin.Unmarshal(b, &mintCondition)
+	if err != nil {
+		return types.UnlockConditionProxy{}, fmt.Errorf("corrupt recovery plugin DB: failed to decode found mint condition: %v", err)
+	}
+	return mintCondition, nil
+}
+
+func getFrozenAddress(rootBucket *bolt.Bucket, address types.UnlockHash) (rtypes.FrozenAddress, error) {
+	frozenBucket := rootBucket.Bucket(bucketFrozenAddresses)
+	if frozenBucket == nil {
+		return rtypes.FrozenAddress{}, errors.New("corrupt recovery plugin DB: frozen address bucket does not exist")
+	}
+	b := frozenBucket.Get(encodeAddress(address))
+	if len(b) == 0 {
+		return rtypes.FrozenAddress{}, rtypes.ErrAddressNotFrozen
+	}
+	var fa rtypes.FrozenAddress
+	err := rivbin.Unmarshal(b, &fa)
+	if err != nil {
+		return rtypes.FrozenAddress{}, fmt.Errorf("corrupt recovery plugin DB: failed to decode frozen address %s: %v", address.String(), err)
+	}
+	return fa, nil
+}
+
+func putFrozenAddress(bucket *persist.LazyBoltBucket, fa rtypes.FrozenAddress) error {
+	frozenBucket, err := bucket.Bucket(bucketFrozenAddresses)
+	if err != nil {
+		return fmt.Errorf("corrupt recovery plugin DB: %v", err)
+	}
+	b, err := rivbin.Marshal(fa)
+	if err != nil {
+		return fmt.Errorf("failed to marshal frozen address %s: %v", fa.Address.String(), err)
+	}
+	err = frozenBucket.Put(encodeAddress(fa.Address), b)
+	if err != nil {
+		return fmt.Errorf("failed to store frozen address %s: %v", fa.Address.String(), err)
+	}
+	return nil
+}
+
+func getAddressForFreezeTransaction(rootBucket *bolt.Bucket, id types.TransactionID) (types.UnlockHash, error) {
+	freezeTxBucket := rootBucket.Bucket(bucketFreezeTransactions)
+	if freezeTxBucket == nil {
+		return types.UnlockHash{}, errors.New("corrupt recovery plugin DB: freeze tx bucket does not exist")
+	}
+	b := freezeTxBucket.Get(encodeTransactionID(id))
+	if len(b) == 0 {
+		return types.UnlockHash{}, rtypes.ErrFreezeTransactionNotFound
+	}
+	return decodeAddress(b), nil
+}
+
+func getBlacklistHistory(rootBucket *bolt.Bucket, address types.UnlockHash) ([]rtypes.BlacklistEvent, error) {
+	historyBucket := rootBucket.Bucket(bucketBlacklistHistory)
+	if historyBucket == nil {
+		return nil, errors.New("corrupt recovery plugin DB: blacklist history bucket does not exist")
+	}
+	b := historyBucket.Get(encodeAddress(address))
+	if len(b) == 0 {
+		return nil, nil
+	}
+	var history []rtypes.BlacklistEvent
+	err := rivbin.Unmarshal(b, &history)
+	if err != nil {
+		return nil, fmt.Errorf("corrupt recovery plugin DB: failed to decode blacklist history of %s: %v", address.String(), err)
+	}
+	return history, nil
+}
+
+// getLastBlacklistEvent returns the last blacklist event of the given address,
+// false is returned in case the address was never blacklisted
+func getLastBlacklistEvent(rootBucket *bolt.Bucket, address types.UnlockHash) (rtypes.BlacklistEvent, bool, error) {
+	history, err := getBlacklistHistory(rootBucket, address)
+	if err != nil || len(history) == 0 {
+		return rtypes.BlacklistEvent{}, false, err
+	}
+	return history[len(history)-1], true, nil
+}
+
+func putBlacklistHistory(bucket *persist.LazyBoltBucket, address types.UnlockHash, history []rtypes.BlacklistEvent) error {
+	historyBucket, err := bucket.Bucket(bucketBlacklistHistory)
+	if err != nil {
+		return fmt.Errorf("corrupt recovery plugin DB: %v", err)
+	}
+	key := encodeAddress(address)
+	if len(history) == 0 {
+		err = historyBucket.Delete(key)
+		if err != nil {
+			return fmt.Errorf("failed to delete blacklist history of %s: %v", address.String(), err)
+		}
+		return nil
+	}
+	b, err := rivbin.Marshal(history)
+	if err != nil {
+		return fmt.Errorf("failed to marshal blacklist history of %s: %v", address.String(), err)
+	}
+	err = historyBucket.Put(key, b)
+	if err != nil {
+		return fmt.Errorf("failed to store blacklist history of %s: %v", address.String(), err)
+	}
+	return nil
+}
+
+// pushBlacklistEvent appends the given event to the blacklist history of the given address
+func pushBlacklistEvent(bucket *persist.LazyBoltBucket, address types.UnlockHash, event rtypes.BlacklistEvent) error {
+	rootBucket, err := bucket.AsBoltBucket()
+	if err != nil {
+		return fmt.Errorf("failed to cast passed bucket as a bolt bucket: %v", err)
+	}
+	history, err := getBlacklistHistory(rootBucket, address)
+	if err != nil {
+		return err
+	}
+	return putBlacklistHistory(bucket, address, append(history, event))
+}
+
+// popBlacklistEvent removes the last event, created by the given transaction,
+// from the blacklist history of the given address
+func popBlacklistEvent(bucket *persist.LazyBoltBucket, address types.UnlockHash, txID types.TransactionID) error {
+	rootBucket, err := bucket.AsBoltBucket()
+	if err != nil {
+		return fmt.Errorf("failed to cast passed bucket as a bolt bucket: %v", err)
+	}
+	history, err := getBlacklistHistory(rootBucket, address)
+	if err != nil {
+		return err
+	}
+	n := len(history)
+	if n == 0 || history[n-1].TransactionID != txID {
+		return fmt.Errorf("corrupt recovery plugin DB: last blacklist event of %s was not created by tx %s", address.String(), txID.String())
+	}
+	return putBlacklistHistory(bucket, address, history[:n-1])
+}
+
+func getUnspentBalance(rootBucket *bolt.Bucket, address types.UnlockHash) (types.Currency, error) {
+	balanceBucket := rootBucket.Bucket(bucketBalances)
+	if balanceBucket == nil {
+		return types.Currency{}, errors.New("corrupt recovery plugin DB: balance bucket does not exist")
+	}
+	return getBalance(balanceBucket, address)
+}
+
+func getBalance(balanceBucket *bolt.Bucket, address types.UnlockHash) (types.Currency, error) {
+	b := balanceBucket.Get(encodeAddress(address))
+	if len(b) == 0 {
+		return types.Currency{}, nil
+	}
+	var balance types.Currency
+	err := rivbin.Unmarshal(b, &balance)
+	if err != nil {
+		return types.Currency{}, fmt.Errorf("corrupt recovery plugin DB: failed to decode balance of %s: %v", address.String(), err)
+	}
+	return balance, nil
+}
+
+// updateBalance adds the given value to (or subtracts it from) the unspent balance of the given address
+func updateBalance(balanceBucket *bolt.Bucket, address types.UnlockHash, value types.Currency, subtract bool) error {
+	balance, err := getBalance(balanceBucket, address)
+	if err != nil {
+		return err
+	}
+	if subtract {
+		if balance.Cmp(value) < 0 {
+			return fmt.Errorf("corrupt recovery plugin DB: balance of %s (%s) is lower than spent value %s",
+				address.String(), balance.String(), value.String())
+		}
+		balance = balance.Sub(value)
+	} else {
+		balance = balance.Add(value)
+	}
+	key := encodeAddress(address)
+	if balance.IsZero() {
+		return balanceBucket.Delete(key)
+	}
+	b, err := rivbin.Marshal(balance)
+	if err != nil {
+		return err
+	}
+	return balanceBucket.Put(key, b)
+}
+
+// apply/revert the coin inputs and outputs of a transaction to/from the unspent balances
+func applyCoinBalances(bucket *persist.LazyBoltBucket, txn modules.ConsensusTransaction) error {
+	return updateCoinBalances(bucket, txn, false)
+}
+
+func revertCoinBalances(bucket *persist.LazyBoltBucket, txn modules.ConsensusTransaction) error {
+	return updateCoinBalances(bucket, txn, true)
+}
+
+func updateCoinBalances(bucket *persist.LazyBoltBucket, txn modules.ConsensusTransaction, revert bool) error {
+	if len(txn.CoinInputs) == 0 && len(txn.CoinOutputs) == 0 {
+		return nil // nothing to do
+	}
+	balanceBucket, err := bucket.Bucket(bucketBalances)
+	if err != nil {
+		return fmt.Errorf("corrupt recovery plugin DB: %v", err)
+	}
+	for _, ci := range txn.CoinInputs {
+		co, ok := txn.SpentCoinOutputs[ci.ParentID]
+		if !ok {
+			return fmt.Errorf("unable to find parent ID %s as a spent coin output", ci.ParentID.String())
+		}
+		err = updateBalance(balanceBucket, co.Condition.UnlockHash(), co.Value, !revert)
+		if err != nil {
+			return err
+		}
+	}
+	for _, co := range txn.CoinOutputs {
+		err = updateBalance(balanceBucket, co.Condition.UnlockHash(), co.Value, revert)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// apply/revert the miner payouts of a block to/from the unspent balances
+func applyMinerPayouts(bucket *persist.LazyBoltBucket, payouts []types.MinerPayout) error {
+	return updateMinerPayoutBalances(bucket, payouts, false)
+}
+
+func revertMinerPayouts(bucket *persist.LazyBoltBucket, payouts []types.MinerPayout) error {
+	return updateMinerPayoutBalances(bucket, payouts, true)
+}
+
+func updateMinerPayoutBalances(bucket *persist.LazyBoltBucket, payouts []types.MinerPayout, revert bool) error {
+	if len(payouts) == 0 {
+		return nil // nothing to do
+	}
+	balanceBucket, err := bucket.Bucket(bucketBalances)
+	if err != nil {
+		return fmt.Errorf("corrupt recovery plugin DB: %v", err)
+	}
+	for _, mp := range payouts {
+		err = updateBalance(balanceBucket, mp.UnlockHash, mp.Value, revert)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeBlockheight encodes the given blockheight as a sortable key
+func encodeBlockheight(height types.BlockHeight) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key[:], uint64(height))
+	return key
+}
+
+// encodeAddress encodes the given address as a key
+func encodeAddress(address types.UnlockHash) []byte {
+	key := make([]byte, 1+crypto.HashSize)
+	key[0] = byte(address.Type)
+	copy(key[1:], address.Hash[:])
+	return key
+}
+
+// decodeAddress decodes the given key as an address
+func decodeAddress(key []byte) (address types.UnlockHash) {
+	address.Type = types.UnlockType(key[0])
+	copy(address.Hash[:], key[1:])
+	return
+}
+
+// encodeTransactionID encodes the given transaction ID as a key
+func encodeTransactionID(id types.TransactionID) []byte {
+	return id[:]
+}
+
+// decodeBlockheight decodes the given sortable key as a blockheight
+func decodeBlockheight(key []byte) types.BlockHeight {
+	return types.BlockHeight(binary.BigEndian.Uint64(key))
+}
@@ -0,0 +1,608 @@
+package recovery
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/persist"
+	"github.com/threefoldtech/rivine/types"
+
+	rtypes "github.com/threefoldfoundation/tfchain/extensions/recovery/types"
+	tftypes "github.com/threefoldfoundation/tfchain/pkg/types"
+
+	bolt "github.com/rivine/bbolt"
+)
+
+var testPluginBucket = []byte("recovery")
+
+// testPluginStorage provides a read-only view on the bucket of the plugin
+type testPluginStorage struct {
+	db *bolt.DB
+}
+
+func (s testPluginStorage) View(callback func(bucket *bolt.Bucket) error) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		return callback(tx.Bucket(testPluginBucket))
+	})
+}
+
+func (s testPluginStorage) Close() error { return nil }
+
+// pluginTester applies and reverts blocks to a recovery plugin using a temporary bolt DB,
+// keeping track of the unspent coin outputs such that they can be spent by later blocks
+type pluginTester struct {
+	t      *testing.T
+	plugin *Plugin
+	db     *bolt.DB
+
+	minterSK crypto.SecretKey
+	minterPK crypto.PublicKey
+
+	outputs map[types.CoinOutputID]types.CoinOutput
+	blocks  []modules.ConsensusBlock
+}
+
+func newPluginTester(t *testing.T) (*pluginTester, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "tfchain-recovery")
+	if err != nil {
+		t.Fatal("failed to create temp dir:", err)
+	}
+	db, err := bolt.Open(filepath.Join(dir, "consensus.db"), 0600, nil)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal("failed to open DB:", err)
+	}
+
+	pt := &pluginTester{
+		t:       t,
+		db:      db,
+		outputs: make(map[types.CoinOutputID]types.CoinOutput),
+	}
+	pt.minterSK, pt.minterPK = crypto.GenerateKeyPair()
+	pt.plugin = NewPlugin(types.NewCondition(types.NewUnlockHashCondition(pt.keyAddress(pt.minterPK))), tftypes.TransactionVersionMinterDefinition)
+	err = db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucket(testPluginBucket)
+		if err != nil {
+			return err
+		}
+		_, err = pt.plugin.InitPlugin(nil, bucket, testPluginStorage{db: db}, nil)
+		return err
+	})
+	if err != nil {
+		t.Fatal("failed to init plugin:", err)
+	}
+
+	return pt, func() {
+		for _, version := range []types.TransactionVersion{
+			rtypes.TransactionVersionAddressFreeze,
+			rtypes.TransactionVersionCoinRecovery,
+			rtypes.TransactionVersionAddressBlacklist,
+			rtypes.TransactionVersionAddressBlacklistLift,
+		} {
+			types.RegisterTransactionVersion(version, nil)
+		}
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func (pt *pluginTester) keyAddress(pk crypto.PublicKey) types.UnlockHash {
+	uh, err := types.NewPubKeyUnlockHash(types.Ed25519PublicKey(pk))
+	if err != nil {
+		pt.t.Fatal(err)
+	}
+	return uh
+}
+
+// newAddress returns a new (random) personal address
+func (pt *pluginTester) newAddress() types.UnlockHash {
+	_, pk := crypto.GenerateKeyPair()
+	return pt.keyAddress(pk)
+}
+
+// height returns the height of the next block
+func (pt *pluginTester) height() types.BlockHeight {
+	return types.BlockHeight(len(pt.blocks))
+}
+
+func (pt *pluginTester) validationContext() types.TransactionValidationContext {
+	return types.TransactionValidationContext{
+		ValidationContext: types.ValidationContext{
+			Confirmed:   true,
+			BlockHeight: pt.height(),
+		},
+		MinimumMinerFee: types.NewCurrency64(1),
+	}
+}
+
+// validate the given transaction, as if it is part of the next block,
+// using all (generic and version-specific) validators of the plugin
+func (pt *pluginTester) validate(txn types.Transaction) error {
+	ctx := pt.validationContext()
+	cTxn := modules.ConsensusTransaction{
+		Transaction:      txn,
+		BlockHeight:      ctx.BlockHeight,
+		SpentCoinOutputs: pt.spentCoinOutputs(txn),
+	}
+	validators := pt.plugin.TransactionValidators()
+	validators = append(validators, pt.plugin.TransactionValidatorVersionFunctionMapping()[txn.Version]...)
+	return pt.db.View(func(tx *bolt.Tx) error {
+		bucket := persist.NewLazyBoltBucket(func() (*bolt.Bucket, error) {
+			return tx.Bucket(testPluginBucket), nil
+		})
+		for _, validator := range validators {
+			if err := validator(cTxn, ctx, bucket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// validateError ensures the given transaction is invalid
+func (pt *pluginTester) validateError(description string, txn types.Transaction) {
+	pt.t.Helper()
+	if err := pt.validate(txn); err == nil {
+		pt.t.Errorf("expected %s to be invalid", description)
+	}
+}
+
+func (pt *pluginTester) spentCoinOutputs(txns ...types.Transaction) map[types.CoinOutputID]types.CoinOutput {
+	spent := make(map[types.CoinOutputID]types.CoinOutput)
+	for _, txn := range txns {
+		for _, ci := range txn.CoinInputs {
+			if co, ok := pt.outputs[ci.ParentID]; ok {
+				spent[ci.ParentID] = co
+			}
+		}
+	}
+	return spent
+}
+
+// applyBlock validates the given transactions, and applies them as the next block
+func (pt *pluginTester) applyBlock(payouts []types.MinerPayout, txns ...types.Transaction) {
+	pt.t.Helper()
+	for idx, txn := range txns {
+		if err := pt.validate(txn); err != nil {
+			pt.t.Fatalf("transaction #%d of block %d is invalid: %v", idx, pt.height(), err)
+		}
+	}
+	block := modules.ConsensusBlock{
+		Block: types.Block{
+			MinerPayouts: payouts,
+			Transactions: txns,
+		},
+		Height:           pt.height(),
+		SpentCoinOutputs: pt.spentCoinOutputs(txns...),
+	}
+	err := pt.db.Update(func(tx *bolt.Tx) error {
+		bucket := persist.NewLazyBoltBucket(func() (*bolt.Bucket, error) {
+			return tx.Bucket(testPluginBucket), nil
+		})
+		return pt.plugin.ApplyBlock(block, bucket)
+	})
+	if err != nil {
+		pt.t.Fatalf("failed to apply block %d: %v", block.Height, err)
+	}
+	for _, txn := range txns {
+		for _, ci := range txn.CoinInputs {
+			delete(pt.outputs, ci.ParentID)
+		}
+		for idx, co := range txn.CoinOutputs {
+			pt.outputs[txn.CoinOutputID(uint64(idx))] = co
+		}
+	}
+	pt.blocks = append(pt.blocks, block)
+}
+
+// revertBlock reverts the last applied block
+func (pt *pluginTester) revertBlock() {
+	pt.t.Helper()
+	block := pt.blocks[len(pt.blocks)-1]
+	err := pt.db.Update(func(tx *bolt.Tx) error {
+		bucket := persist.NewLazyBoltBucket(func() (*bolt.Bucket, error) {
+			return tx.Bucket(testPluginBucket), nil
+		})
+		return pt.plugin.RevertBlock(block, bucket)
+	})
+	if err != nil {
+		pt.t.Fatalf("failed to revert block %d: %v", block.Height, err)
+	}
+	for _, txn := range block.Transactions {
+		for idx := range txn.CoinOutputs {
+			delete(pt.outputs, txn.CoinOutputID(uint64(idx)))
+		}
+	}
+	for id, co := range block.SpentCoinOutputs {
+		pt.outputs[id] = co
+	}
+	pt.blocks = pt.blocks[:len(pt.blocks)-1]
+}
+
+// newPaymentTx creates a transaction sending the given value to the given address,
+// spending the given coin outputs
+func newPaymentTx(to types.UnlockHash, value uint64, inputs ...types.CoinOutputID) types.Transaction {
+	nonce := types.RandomTransactionNonce() // ensures unique transaction IDs
+	txn := types.Transaction{
+		Version: types.TransactionVersionOne,
+		CoinOutputs: []types.CoinOutput{{
+			Value:     types.NewCurrency64(value),
+			Condition: types.NewCondition(types.NewUnlockHashCondition(to)),
+		}},
+		ArbitraryData: nonce[:],
+	}
+	for _, id := range inputs {
+		txn.CoinInputs = append(txn.CoinInputs, types.CoinInput{
+			ParentID:    id,
+			Fulfillment: types.NewFulfillment(&types.SingleSignatureFulfillment{}),
+		})
+	}
+	return txn
+}
+
+// fund applies a block sending the given value to the given address,
+// returning the ID of the created coin output
+func (pt *pluginTester) fund(address types.UnlockHash, value uint64) types.CoinOutputID {
+	pt.t.Helper()
+	txn := newPaymentTx(address, value)
+	pt.applyBlock(nil, txn)
+	return txn.CoinOutputID(0)
+}
+
+// signMint signs the mint fulfillment of the given transaction using the given key
+func (pt *pluginTester) signMint(txn *types.Transaction, sk crypto.SecretKey) {
+	pt.t.Helper()
+	err := txn.SignExtension(func(fulfillment *types.UnlockFulfillmentProxy, condition types.UnlockConditionProxy, extraObjects ...interface{}) error {
+		return fulfillment.Sign(types.FulfillmentSignContext{
+			ExtraObjects: extraObjects,
+			Transaction:  *txn,
+			Key:          sk,
+		})
+	})
+	if err != nil {
+		pt.t.Fatal("failed to sign mint fulfillment:", err)
+	}
+}
+
+func (pt *pluginTester) mintFulfillment(pk crypto.PublicKey) types.UnlockFulfillmentProxy {
+	return types.NewFulfillment(types.NewSingleSignatureFulfillment(types.Ed25519PublicKey(pk)))
+}
+
+// newFreezeTx creates an address freeze transaction, signed by the minter,
+// after applying the given modifications
+func (pt *pluginTester) newFreezeTx(address types.UnlockHash, modify ...func(*rtypes.AddressFreezeTransaction)) types.Transaction {
+	pt.t.Helper()
+	aftx := rtypes.AddressFreezeTransaction{
+		Nonce:           types.RandomTransactionNonce(),
+		Address:         address,
+		DocumentHash:    crypto.HashBytes([]byte("lost seed declaration")),
+		MintFulfillment: pt.mintFulfillment(pt.minterPK),
+		MinerFees:       []types.Currency{types.NewCurrency64(1)},
+	}
+	for _, fn := range modify {
+		fn(&aftx)
+	}
+	txn := aftx.Transaction()
+	pt.signMint(&txn, pt.minterSK)
+	return txn
+}
+
+// newRecoveryTx creates a coin recovery transaction, signed by the minter,
+// recovering the given value (minus a miner fee of 1) to the given address
+func (pt *pluginTester) newRecoveryTx(freezeTxID types.TransactionID, to types.UnlockHash, value uint64) types.Transaction {
+	pt.t.Helper()
+	crtx := rtypes.CoinRecoveryTransaction{
+		Nonce:               types.RandomTransactionNonce(),
+		FreezeTransactionID: freezeTxID,
+		MintFulfillment:     pt.mintFulfillment(pt.minterPK),
+		CoinOutputs: []types.CoinOutput{{
+			Value:     types.NewCurrency64(value - 1),
+			Condition: types.NewCondition(types.NewUnlockHashCondition(to)),
+		}},
+		MinerFees: []types.Currency{types.NewCurrency64(1)},
+	}
+	txn := crtx.Transaction()
+	pt.signMint(&txn, pt.minterSK)
+	return txn
+}
+
+// newBlacklistTx creates an address blacklist transaction, signed by the minter,
+// expiring at the given height (never if 0), after applying the given modifications
+func (pt *pluginTester) newBlacklistTx(address types.UnlockHash, expiry types.BlockHeight, modify ...func(*rtypes.AddressBlacklistTransaction)) types.Transaction {
+	pt.t.Helper()
+	abtx := rtypes.AddressBlacklistTransaction{
+		Nonce:           types.RandomTransactionNonce(),
+		Address:         address,
+		DocumentHash:    crypto.HashBytes([]byte("court order")),
+		ExpiryHeight:    expiry,
+		MintFulfillment: pt.mintFulfillment(pt.minterPK),
+		MinerFees:       []types.Currency{types.NewCurrency64(1)},
+	}
+	for _, fn := range modify {
+		fn(&abtx)
+	}
+	txn := abtx.Transaction()
+	pt.signMint(&txn, pt.minterSK)
+	return txn
+}
+
+// newBlacklistLiftTx creates an address blacklist lift transaction, signed by the minter,
+// after applying the given modifications
+func (pt *pluginTester) newBlacklistLiftTx(address types.UnlockHash, modify ...func(*rtypes.AddressBlacklistLiftTransaction)) types.Transaction {
+	pt.t.Helper()
+	abltx := rtypes.AddressBlacklistLiftTransaction{
+		Nonce:           types.RandomTransactionNonce(),
+		Address:         address,
+		DocumentHash:    crypto.HashBytes([]byte("court order to lift")),
+		MintFulfillment: pt.mintFulfillment(pt.minterPK),
+		MinerFees:       []types.Currency{types.NewCurrency64(1)},
+	}
+	for _, fn := range modify {
+		fn(&abltx)
+	}
+	txn := abltx.Transaction()
+	pt.signMint(&txn, pt.minterSK)
+	return txn
+}
+
+// expectBlacklistHistory ensures the blacklist history of the given address
+// consists of the events created by the given transactions
+func (pt *pluginTester) expectBlacklistHistory(address types.UnlockHash, txns ...types.Transaction) []rtypes.BlacklistEvent {
+	pt.t.Helper()
+	history, err := pt.plugin.GetAddressBlacklistHistory(address)
+	if len(txns) == 0 {
+		if err != rtypes.ErrAddressNotBlacklisted {
+			pt.t.Errorf("expected address never to be blacklisted at height %d, not: %v", pt.height(), err)
+		}
+		return nil
+	}
+	if err != nil {
+		pt.t.Fatal("failed to get blacklist history:", err)
+	}
+	if len(history) != len(txns) {
+		pt.t.Fatalf("expected %d blacklist events at height %d, not %d", len(txns), pt.height(), len(history))
+	}
+	for idx, txn := range txns {
+		if history[idx].TransactionID != txn.ID() {
+			pt.t.Errorf("blacklist event #%d was not created by tx %s", idx, txn.ID().String())
+		}
+	}
+	return history
+}
+
+func (pt *pluginTester) expectBalance(address types.UnlockHash, expected uint64) {
+	pt.t.Helper()
+	balance, err := pt.plugin.GetUnspentBalance(address)
+	if err != nil {
+		pt.t.Fatal("failed to get unspent balance:", err)
+	}
+	if !balance.Equals64(expected) {
+		pt.t.Errorf("expected unspent balance %d for %s at height %d, not %s", expected, address.String(), pt.height(), balance.String())
+	}
+}
+
+func TestPluginBalances(t *testing.T) {
+	pt, cleanup := newPluginTester(t)
+	defer cleanup()
+
+	alice, bob, creator := pt.newAddress(), pt.newAddress(), pt.newAddress()
+	output1 := pt.fund(alice, 100)
+	pt.fund(alice, 50)
+	pt.expectBalance(alice, 150)
+
+	pt.applyBlock([]types.MinerPayout{{Value: types.NewCurrency64(10), UnlockHash: creator}}, newPaymentTx(bob, 100, output1))
+	pt.expectBalance(alice, 50)
+	pt.expectBalance(bob, 100)
+	pt.expectBalance(creator, 10)
+
+	pt.revertBlock()
+	pt.expectBalance(alice, 150)
+	pt.expectBalance(bob, 0)
+	pt.expectBalance(creator, 0)
+
+	pt.revertBlock()
+	pt.revertBlock()
+	pt.expectBalance(alice, 0)
+}
+
+func TestPluginFreezeAndRecovery(t *testing.T) {
+	pt, cleanup := newPluginTester(t)
+	defer cleanup()
+
+	alice, bob := pt.newAddress(), pt.newAddress()
+	output := pt.fund(alice, 100)
+	pt.fund(bob, 10)
+
+	// invalid freeze transactions
+	pt.validateError("freeze tx with nil nonce", pt.newFreezeTx(alice, func(aftx *rtypes.AddressFreezeTransaction) {
+		aftx.Nonce = types.TransactionNonce{}
+	}))
+	pt.validateError("freeze tx of atomic swap address", pt.newFreezeTx(types.UnlockHash{Type: types.UnlockTypeAtomicSwap}))
+	pt.validateError("freeze tx with nil document hash", pt.newFreezeTx(alice, func(aftx *rtypes.AddressFreezeTransaction) {
+		aftx.DocumentHash = crypto.Hash{}
+	}))
+	pt.validateError("freeze tx with too small miner fee", pt.newFreezeTx(alice, func(aftx *rtypes.AddressFreezeTransaction) {
+		aftx.MinerFees = []types.Currency{types.ZeroCurrency}
+	}))
+	otherSK, otherPK := crypto.GenerateKeyPair()
+	notMinted := (&rtypes.AddressFreezeTransaction{
+		Nonce:           types.RandomTransactionNonce(),
+		Address:         alice,
+		DocumentHash:    crypto.HashBytes([]byte("lost seed declaration")),
+		MintFulfillment: pt.mintFulfillment(otherPK),
+		MinerFees:       []types.Currency{types.NewCurrency64(1)},
+	}).Transaction()
+	pt.signMint(&notMinted, otherSK)
+	pt.validateError("freeze tx not signed by the minter", notMinted)
+
+	// freeze alice
+	freezeTx := pt.newFreezeTx(alice)
+	pt.applyBlock(nil, freezeTx)
+	fa, err := pt.plugin.GetFrozenAddress(alice)
+	if err != nil {
+		t.Fatal("expected address to be frozen:", err)
+	}
+	if fa.FreezeTransactionID != freezeTx.ID() || fa.FreezeHeight != 2 || fa.IsRecovered() {
+		t.Errorf("unexpected frozen address: %+v", fa)
+	}
+	if fa, err = pt.plugin.GetFrozenAddressForTransaction(freezeTx.ID()); err != nil || fa.Address != alice {
+		t.Errorf("unexpected frozen address for freeze tx: %+v (%v)", fa, err)
+	}
+	if _, err = pt.plugin.GetFrozenAddress(bob); err != rtypes.ErrAddressNotFrozen {
+		t.Errorf("expected bob not to be frozen, not: %v", err)
+	}
+	if err = pt.validate(pt.newFreezeTx(alice)); err != rtypes.ErrAddressAlreadyFrozen {
+		t.Errorf("expected a second freeze of the address to fail, not: %v", err)
+	}
+
+	// coins of a frozen address cannot be spent, and no coins can be sent to it
+	pt.validateError("spending coins of a frozen address", newPaymentTx(bob, 100, output))
+	pt.validateError("sending coins to a frozen address", newPaymentTx(alice, 10))
+
+	// recover the coins of alice to bob
+	pt.validateError("recovery of a different value", pt.newRecoveryTx(freezeTx.ID(), bob, 99))
+	pt.validateError("recovery to the frozen address", pt.newRecoveryTx(freezeTx.ID(), alice, 100))
+	if err = pt.validate(pt.newRecoveryTx(types.TransactionID{1}, bob, 100)); err != rtypes.ErrFreezeTransactionNotFound {
+		t.Errorf("expected recovery of an unknown freeze tx to fail, not: %v", err)
+	}
+	recoveryTx := pt.newRecoveryTx(freezeTx.ID(), bob, 100)
+	pt.applyBlock(nil, recoveryTx)
+	if fa, err = pt.plugin.GetFrozenAddress(alice); err != nil || !fa.IsRecovered() || *fa.RecoveryTransactionID != recoveryTx.ID() {
+		t.Errorf("expected coins of frozen address to be recovered: %+v (%v)", fa, err)
+	}
+	pt.expectBalance(bob, 109)
+	pt.expectBalance(alice, 100) // the frozen coins remain unspent forever
+	if err = pt.validate(pt.newRecoveryTx(freezeTx.ID(), bob, 100)); err != rtypes.ErrCoinsAlreadyRecovered {
+		t.Errorf("expected a second recovery to fail, not: %v", err)
+	}
+	pt.validateError("sending coins to a recovered address", newPaymentTx(alice, 10))
+
+	// revert the recovery and freeze
+	pt.revertBlock()
+	if fa, err = pt.plugin.GetFrozenAddress(alice); err != nil || fa.IsRecovered() {
+		t.Errorf("expected recovery to be reverted: %+v (%v)", fa, err)
+	}
+	pt.expectBalance(bob, 10)
+	pt.revertBlock()
+	if _, err = pt.plugin.GetFrozenAddress(alice); err != rtypes.ErrAddressNotFrozen {
+		t.Errorf("expected freeze to be reverted, not: %v", err)
+	}
+	if _, err = pt.plugin.GetFrozenAddressForTransaction(freezeTx.ID()); err != rtypes.ErrFreezeTransactionNotFound {
+		t.Errorf("expected freeze tx to be reverted, not: %v", err)
+	}
+	if err = pt.validate(newPaymentTx(bob, 100, output)); err != nil {
+		t.Error("expected coins of an unfrozen address to be spendable:", err)
+	}
+	if err = pt.validate(newPaymentTx(alice, 10)); err != nil {
+		t.Error("expected coins to be sendable to an unfrozen address:", err)
+	}
+}
+
+func TestPluginBlacklist(t *testing.T) {
+	pt, cleanup := newPluginTester(t)
+	defer cleanup()
+
+	alice, bob := pt.newAddress(), pt.newAddress()
+	output := pt.fund(alice, 100)
+	pt.fund(bob, 10)
+
+	// invalid blacklist transactions
+	pt.validateError("blacklist tx with nil nonce", pt.newBlacklistTx(alice, 0, func(abtx *rtypes.AddressBlacklistTransaction) {
+		abtx.Nonce = types.TransactionNonce{}
+	}))
+	pt.validateError("blacklist tx of atomic swap address", pt.newBlacklistTx(types.UnlockHash{Type: types.UnlockTypeAtomicSwap}, 0))
+	pt.validateError("blacklist tx with nil document hash", pt.newBlacklistTx(alice, 0, func(abtx *rtypes.AddressBlacklistTransaction) {
+		abtx.DocumentHash = crypto.Hash{}
+	}))
+	pt.validateError("blacklist tx expiring at the current height", pt.newBlacklistTx(alice, pt.height()))
+	pt.validateError("blacklist tx with too small miner fee", pt.newBlacklistTx(alice, 0, func(abtx *rtypes.AddressBlacklistTransaction) {
+		abtx.MinerFees = []types.Currency{types.ZeroCurrency}
+	}))
+	otherSK, otherPK := crypto.GenerateKeyPair()
+	notMinted := (&rtypes.AddressBlacklistTransaction{
+		Nonce:           types.RandomTransactionNonce(),
+		Address:         alice,
+		DocumentHash:    crypto.HashBytes([]byte("court order")),
+		MintFulfillment: pt.mintFulfillment(otherPK),
+		MinerFees:       []types.Currency{types.NewCurrency64(1)},
+	}).Transaction()
+	pt.signMint(&notMinted, otherSK)
+	pt.validateError("blacklist tx not signed by the minter", notMinted)
+	if err := pt.validate(pt.newBlacklistLiftTx(alice)); err != rtypes.ErrAddressNotBlacklisted {
+		t.Errorf("expected lift of a non-blacklisted address to fail, not: %v", err)
+	}
+
+	// blacklist alice until 3 blocks from now
+	blacklistHeight := pt.height()
+	blacklistTx := pt.newBlacklistTx(alice, blacklistHeight+3)
+	pt.applyBlock(nil, blacklistTx)
+	history := pt.expectBlacklistHistory(alice, blacklistTx)
+	if event := history[0]; event.Action != rtypes.BlacklistActionBlacklist || event.BlockHeight != blacklistHeight ||
+		event.ExpiryHeight != blacklistHeight+3 || event.DocumentHash != crypto.HashBytes([]byte("court order")) {
+		t.Errorf("unexpected blacklist event: %+v", event)
+	}
+	pt.expectBlacklistHistory(bob)
+	if err := pt.validate(pt.newBlacklistTx(alice, 0)); err != rtypes.ErrAddressAlreadyBlacklisted {
+		t.Errorf("expected a second blacklisting of the address to fail, not: %v", err)
+	}
+
+	// coins of a blacklisted address cannot be spent, while coins can still be sent to it
+	pt.validateError("spending coins of a blacklisted address", newPaymentTx(bob, 100, output))
+	if err := pt.validate(newPaymentTx(alice, 10)); err != nil {
+		t.Error("expected coins to be sendable to a blacklisted address:", err)
+	}
+	pt.fund(bob, 1)
+	pt.validateError("spending coins of a blacklisted address before expiry", newPaymentTx(bob, 100, output))
+
+	// the blacklisting expires at its expiry height
+	pt.fund(bob, 1)
+	if pt.height() != blacklistHeight+3 {
+		t.Fatalf("unexpected height: %d", pt.height())
+	}
+	if err := pt.validate(newPaymentTx(bob, 100, output)); err != nil {
+		t.Error("expected coins of an address with an expired blacklisting to be spendable:", err)
+	}
+	if err := pt.validate(pt.newBlacklistLiftTx(alice)); err != rtypes.ErrAddressNotBlacklisted {
+		t.Errorf("expected lift of an expired blacklisting to fail, not: %v", err)
+	}
+
+	// re-blacklist alice, without expiry, and lift it again
+	reblacklistTx := pt.newBlacklistTx(alice, 0)
+	pt.applyBlock(nil, reblacklistTx)
+	pt.expectBlacklistHistory(alice, blacklistTx, reblacklistTx)
+	pt.validateError("spending coins of a re-blacklisted address", newPaymentTx(bob, 100, output))
+	pt.validateError("lift tx with nil document hash", pt.newBlacklistLiftTx(alice, func(abltx *rtypes.AddressBlacklistLiftTransaction) {
+		abltx.DocumentHash = crypto.Hash{}
+	}))
+	liftTx := pt.newBlacklistLiftTx(alice)
+	pt.applyBlock(nil, liftTx)
+	history = pt.expectBlacklistHistory(alice, blacklistTx, reblacklistTx, liftTx)
+	if event := history[2]; event.Action != rtypes.BlacklistActionLift || event.ExpiryHeight != 0 {
+		t.Errorf("unexpected lift event: %+v", event)
+	}
+	if err := pt.validate(newPaymentTx(bob, 100, output)); err != nil {
+		t.Error("expected coins of an address with a lifted blacklisting to be spendable:", err)
+	}
+	if err := pt.validate(pt.newBlacklistTx(alice, 0)); err != nil {
+		t.Error("expected an address with a lifted blacklisting to be blacklistable again:", err)
+	}
+
+	// revert the lift and blacklistings
+	pt.revertBlock()
+	pt.expectBlacklistHistory(alice, blacklistTx, reblacklistTx)
+	pt.validateError("spending coins of an address with a reverted lift", newPaymentTx(bob, 100, output))
+	pt.revertBlock()
+	pt.expectBlacklistHistory(alice, blacklistTx)
+	pt.revertBlock()
+	pt.revertBlock()
+	pt.revertBlock()
+	pt.expectBlacklistHistory(alice)
+	if err := pt.validate(newPaymentTx(bob, 100, output)); err != nil {
+		t.Error("expected coins of an address with a reverted blacklisting to be spendable:", err)
+	}
+}
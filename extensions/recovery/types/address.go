@@ -0,0 +1,127 @@
+package types
+
+import (
+	"errors"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// Recovery errors
+var (
+	ErrAddressNotFrozen          = errors.New("address is not frozen")
+	ErrAddressAlreadyFrozen      = errors.New("address is already frozen")
+	ErrFreezeTransactionNotFound = errors.New("address freeze transaction not found")
+	ErrCoinsAlreadyRecovered     = errors.New("coins of frozen address are already recovered")
+	ErrAddressNotBlacklisted     = errors.New("address is not blacklisted")
+	ErrAddressAlreadyBlacklisted = errors.New("address is already blacklisted")
+)
+
+type (
+	// FrozenAddress is the state of an address,
+	// frozen using an AddressFreezeTransaction.
+	FrozenAddress struct {
+		// Address that is frozen
+		Address types.UnlockHash `json:"address"`
+		// FreezeTransactionID is the ID of the AddressFreezeTransaction that froze the address
+		FreezeTransactionID types.TransactionID `json:"freezetxid"`
+		// FreezeHeight is the height of the block which contains the AddressFreezeTransaction
+		FreezeHeight types.BlockHeight `json:"freezeheight"`
+		// DocumentHash is the hash of the document that motivates the freezing of the address,
+		// as defined by the AddressFreezeTransaction
+		DocumentHash crypto.Hash `json:"documenthash"`
+		// RecoveryTransactionID is the ID of the CoinRecoveryTransaction
+		// that recovered the coins of the frozen address, nil if not recovered yet
+		RecoveryTransactionID *types.TransactionID `json:"recoverytxid,omitempty"`
+	}
+
+	// FrozenAddressReadRegistry defines the public READ API
+	// expected from a registry of frozen addresses.
+	FrozenAddressReadRegistry interface {
+		// GetFrozenAddress returns the state of the given frozen address,
+		// returning ErrAddressNotFrozen if the address is not frozen.
+		GetFrozenAddress(address types.UnlockHash) (FrozenAddress, error)
+		// GetFrozenAddressForTransaction returns the state of the address
+		// frozen by the given AddressFreezeTransaction,
+		// returning ErrFreezeTransactionNotFound if no address was frozen by that transaction.
+		GetFrozenAddressForTransaction(id types.TransactionID) (FrozenAddress, error)
+		// GetUnspentBalance returns the sum of the values of all unspent coin outputs
+		// that can be unlocked by the given address.
+		GetUnspentBalance(address types.UnlockHash) (types.Currency, error)
+	}
+
+	// BlacklistAction defines the action of a BlacklistEvent.
+	BlacklistAction string
+
+	// BlacklistEvent is an event in the blacklist history of an address,
+	// created by either an AddressBlacklistTransaction or an AddressBlacklistLiftTransaction.
+	BlacklistEvent struct {
+		// Action defines whether the address was blacklisted or its blacklisting was lifted
+		Action BlacklistAction `json:"action"`
+		// TransactionID is the ID of the transaction that created this event
+		TransactionID types.TransactionID `json:"txid"`
+		// BlockHeight is the height of the block which contains that transaction
+		BlockHeight types.BlockHeight `json:"blockheight"`
+		// DocumentHash is the hash of the (court) document that motivates this event
+		DocumentHash crypto.Hash `json:"documenthash"`
+		// ExpiryHeight is the block height since which the blacklisting expires,
+		// 0 if it never expires or if the event lifts the blacklisting
+		ExpiryHeight types.BlockHeight `json:"expiryheight,omitempty"`
+	}
+
+	// AddressBlacklistStatus is the blacklist status of an address at a given block height,
+	// as well as its full blacklist history.
+	AddressBlacklistStatus struct {
+		// Address of which this is the status
+		Address types.UnlockHash `json:"address"`
+		// Height at which the status was evaluated
+		Height types.BlockHeight `json:"height"`
+		// Blacklisted is true if the coin outputs of the address cannot be spent at Height
+		Blacklisted bool `json:"blacklisted"`
+		// History lists all blacklist events of the address, from old to new
+		History []BlacklistEvent `json:"history"`
+	}
+
+	// BlacklistedAddressReadRegistry defines the public READ API
+	// expected from a registry of blacklisted addresses.
+	BlacklistedAddressReadRegistry interface {
+		// GetAddressBlacklistHistory returns all blacklist events of the given address,
+		// from old to new, returning ErrAddressNotBlacklisted if the address was never blacklisted.
+		GetAddressBlacklistHistory(address types.UnlockHash) ([]BlacklistEvent, error)
+	}
+)
+
+// All actions of a BlacklistEvent.
+const (
+	// BlacklistActionBlacklist is the action of an AddressBlacklistTransaction.
+	BlacklistActionBlacklist BlacklistAction = "blacklist"
+	// BlacklistActionLift is the action of an AddressBlacklistLiftTransaction.
+	BlacklistActionLift BlacklistAction = "lift"
+)
+
+// IsRecovered returns true if the coins of the frozen address are already recovered.
+func (fa *FrozenAddress) IsRecovered() bool {
+	return fa.RecoveryTransactionID != nil
+}
+
+// IsActiveAt returns true if this event blacklists an address at the given block height.
+func (be *BlacklistEvent) IsActiveAt(height types.BlockHeight) bool {
+	if be.Action != BlacklistActionBlacklist {
+		return false
+	}
+	return be.ExpiryHeight == 0 || height < be.ExpiryHeight
+}
+
+// NewAddressBlacklistStatus creates the blacklist status of an address at the given block height,
+// using the (ordered) blacklist history of that address.
+func NewAddressBlacklistStatus(address types.UnlockHash, height types.BlockHeight, history []BlacklistEvent) AddressBlacklistStatus {
+	status := AddressBlacklistStatus{
+		Address: address,
+		Height:  height,
+		History: history,
+	}
+	if n := len(history); n > 0 {
+		status.Blacklisted = history[n-1].IsActiveAt(height)
+	}
+	return status
+}
@@ -0,0 +1,1172 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/extensions/minting"
+	"github.com/threefoldtech/rivine/pkg/encoding/rivbin"
+	"github.com/threefoldtech/rivine/types"
+)
+
+const (
+	// TransactionVersionAddressFreeze defines the Transaction version
+	// for an AddressFreeze Transaction, used by the foundation to freeze
+	// an address of which the owner lost the seed.
+	TransactionVersionAddressFreeze types.TransactionVersion = iota + 160
+	// TransactionVersionCoinRecovery defines the Transaction version
+	// for a CoinRecovery Transaction, used by the foundation to mint
+	// the unspent balance of a frozen address to a new address.
+	TransactionVersionCoinRecovery
+	// TransactionVersionAddressBlacklist defines the Transaction version
+	// for an AddressBlacklist Transaction, used by the foundation to blacklist
+	// an address as ordered by a court, e.g. one holding stolen funds.
+	TransactionVersionAddressBlacklist
+	// TransactionVersionAddressBlacklistLift defines the Transaction version
+	// for an AddressBlacklistLift Transaction, used by the foundation to lift
+	// the blacklisting of an address before it expires.
+	TransactionVersionAddressBlacklistLift
+)
+
+var (
+	SpecifierAddressFreezeTransaction        = types.Specifier{'a', 'd', 'd', 'r', ' ', 'f', 'r', 'e', 'e', 'z', 'e', ' ', 't', 'x'}
+	SpecifierCoinRecoveryTransaction         = types.Specifier{'c', 'o', 'i', 'n', ' ', 'r', 'e', 'c', 'o', 'v', 'e', 'r', ' ', 't', 'x'}
+	SpecifierAddressBlacklistTransaction     = types.Specifier{'a', 'd', 'd', 'r', ' ', 'b', 'l', 'a', 'c', 'k', 'l', 'i', 's', 't'}
+	SpecifierAddressBlacklistLiftTransaction = types.Specifier{'b', 'l', 'a', 'c', 'k', 'l', 'i', 's', 't', ' ', 'l', 'i', 'f', 't'}
+)
+
+type (
+	// AddressFreezeTransaction defines the Transaction (with version 0xa0)
+	// used to freeze an address, such that none of its unspent coin outputs can be spent any longer,
+	// and no coins can be sent to it anymore. It is to be created only by the Coin Minters,
+	// and is used for holders that lost their seed, such that their coins can be recovered
+	// to a new address using a CoinRecoveryTransaction.
+	AddressFreezeTransaction struct {
+		// Nonce used to ensure the uniqueness of an AddressFreezeTransaction's ID and signature.
+		Nonce types.TransactionNonce `json:"nonce"`
+		// Address to freeze, either a PubKey or MultiSig address.
+		Address types.UnlockHash `json:"address"`
+		// DocumentHash is the hash of the (legal) document
+		// that motivates the freezing of the address.
+		DocumentHash crypto.Hash `json:"documenthash"`
+		// MintFulfillment defines the fulfillment which is used in order to
+		// fulfill the globally defined MintCondition.
+		MintFulfillment types.UnlockFulfillmentProxy `json:"mintfulfillment"`
+		// MinerFees, a fee paid for this address freeze transaction.
+		MinerFees []types.Currency `json:"minerfees"`
+		// ArbitraryData can be used for any purpose.
+		ArbitraryData []byte `json:"arbitrarydata,omitempty"`
+	}
+	// AddressFreezeTransactionExtension defines the AddressFreezeTransaction Extension Data
+	AddressFreezeTransactionExtension struct {
+		Nonce           types.TransactionNonce
+		Address         types.UnlockHash
+		DocumentHash    crypto.Hash
+		MintFulfillment types.UnlockFulfillmentProxy
+	}
+)
+
+// AddressFreezeTransactionFromTransaction creates an AddressFreezeTransaction,
+// using a regular in-memory tfchain transaction.
+//
+// Past the (tx) Version validation it piggy-backs onto the
+// `AddressFreezeTransactionFromTransactionData` constructor.
+func AddressFreezeTransactionFromTransaction(tx types.Transaction) (AddressFreezeTransaction, error) {
+	if tx.Version != TransactionVersionAddressFreeze {
+		return AddressFreezeTransaction{}, fmt.Errorf(
+			"an address freeze transaction requires tx version %d",
+			TransactionVersionAddressFreeze)
+	}
+	return AddressFreezeTransactionFromTransactionData(types.TransactionData{
+		CoinInputs:        tx.CoinInputs,
+		CoinOutputs:       tx.CoinOutputs,
+		BlockStakeInputs:  tx.BlockStakeInputs,
+		BlockStakeOutputs: tx.BlockStakeOutputs,
+		MinerFees:         tx.MinerFees,
+		ArbitraryData:     tx.ArbitraryData,
+		Extension:         tx.Extension,
+	})
+}
+
+// AddressFreezeTransactionFromTransactionData creates an AddressFreezeTransaction,
+// using the TransactionData from a regular in-memory tfchain transaction.
+func AddressFreezeTransactionFromTransactionData(txData types.TransactionData) (AddressFreezeTransaction, error) {
+	// (tx) extension (data) is expected to be a pointer to a valid AddressFreezeTransactionExtension
+	extensionData, ok := txData.Extension.(*AddressFreezeTransactionExtension)
+	if !ok {
+		return AddressFreezeTransaction{}, errors.New("invalid extension data for an AddressFreezeTransaction")
+	}
+	// at least one miner fee is required
+	if len(txData.MinerFees) == 0 {
+		return AddressFreezeTransaction{}, errors.New("at least one miner fee is required for an AddressFreezeTransaction")
+	}
+	// no coin inputs/outputs or block stake inputs/outputs are allowed
+	if len(txData.CoinInputs) != 0 || len(txData.CoinOutputs) != 0 || len(txData.BlockStakeInputs) != 0 || len(txData.BlockStakeOutputs) != 0 {
+		return AddressFreezeTransaction{}, errors.New("no coin inputs/outputs and block stake inputs/outputs are allowed in an AddressFreezeTransaction")
+	}
+	return AddressFreezeTransaction{
+		Nonce:           extensionData.Nonce,
+		Address:         extensionData.Address,
+		DocumentHash:    extensionData.DocumentHash,
+		MintFulfillment: extensionData.MintFulfillment,
+		MinerFees:       txData.MinerFees,
+		ArbitraryData:   txData.ArbitraryData,
+	}, nil
+}
+
+// TransactionData returns this AddressFreezeTransaction
+// as regular tfchain transaction data.
+func (aftx *AddressFreezeTransaction) TransactionData() types.TransactionData {
+	return types.TransactionData{
+		MinerFees:     aftx.MinerFees,
+		ArbitraryData: aftx.ArbitraryData,
+		Extension: &AddressFreezeTransactionExtension{
+			Nonce:           aftx.Nonce,
+			Address:         aftx.Address,
+			DocumentHash:    aftx.DocumentHash,
+			MintFulfillment: aftx.MintFulfillment,
+		},
+	}
+}
+
+// Transaction returns this AddressFreezeTransaction
+// as regular tfchain transaction, using TransactionVersionAddressFreeze as the type.
+func (aftx *AddressFreezeTransaction) Transaction() types.Transaction {
+	return types.Transaction{
+		Version:       TransactionVersionAddressFreeze,
+		MinerFees:     aftx.MinerFees,
+		ArbitraryData: aftx.ArbitraryData,
+		Extension: &AddressFreezeTransactionExtension{
+			Nonce:           aftx.Nonce,
+			Address:         aftx.Address,
+			DocumentHash:    aftx.DocumentHash,
+			MintFulfillment: aftx.MintFulfillment,
+		},
+	}
+}
+
+// MarshalSia implements SiaMarshaler.MarshalSia,
+// alias of MarshalRivine for backwards-compatibility reasons.
+func (aftx AddressFreezeTransaction) MarshalSia(w io.Writer) error {
+	return aftx.MarshalRivine(w)
+}
+
+// UnmarshalSia implements SiaUnmarshaler.UnmarshalSia,
+// alias of UnmarshalRivine for backwards-compatibility reasons.
+func (aftx *AddressFreezeTransaction) UnmarshalSia(r io.Reader) error {
+	return aftx.UnmarshalRivine(r)
+}
+
+// MarshalRivine implements RivineMarshaler.MarshalRivine
+func (aftx AddressFreezeTransaction) MarshalRivine(w io.Writer) error {
+	return rivbin.NewEncoder(w).EncodeAll(
+		aftx.Nonce,
+		aftx.Address,
+		aftx.DocumentHash,
+		aftx.MintFulfillment,
+		aftx.MinerFees,
+		aftx.ArbitraryData,
+	)
+}
+
+// UnmarshalRivine implements RivineUnmarshaler.UnmarshalRivine
+func (aftx *AddressFreezeTransaction) UnmarshalRivine(r io.Reader) error {
+	return rivbin.NewDecoder(r).DecodeAll(
+		&aftx.Nonce,
+		&aftx.Address,
+		&aftx.DocumentHash,
+		&aftx.MintFulfillment,
+		&aftx.MinerFees,
+		&aftx.ArbitraryData,
+	)
+}
+
+type (
+	// CoinRecoveryTransaction defines the Transaction (with version 0xa1)
+	// used to recover the coins of a frozen address, by minting its unspent balance
+	// to a new address. It is to be created only by the Coin Minters.
+	//
+	// The sum of all coin outputs and miner fees has to equal the unspent balance
+	// of the frozen address, and the coins of a frozen address can be recovered only once.
+	CoinRecoveryTransaction struct {
+		// Nonce used to ensure the uniqueness of a CoinRecoveryTransaction's ID and signature.
+		Nonce types.TransactionNonce `json:"nonce"`
+		// FreezeTransactionID is the ID of the AddressFreezeTransaction
+		// that froze the address of which the coins are recovered.
+		FreezeTransactionID types.TransactionID `json:"freezetxid"`
+		// MintFulfillment defines the fulfillment which is used in order to
+		// fulfill the globally defined MintCondition.
+		MintFulfillment types.UnlockFulfillmentProxy `json:"mintfulfillment"`
+		// CoinOutputs defines the coin outputs,
+		// which contain the recovered coins.
+		CoinOutputs []types.CoinOutput `json:"coinoutputs"`
+		// MinerFees, a fee paid for this coin recovery transaction,
+		// paid from the recovered coins.
+		MinerFees []types.Currency `json:"minerfees"`
+		// ArbitraryData can be used for any purpose.
+		ArbitraryData []byte `json:"arbitrarydata,omitempty"`
+	}
+	// CoinRecoveryTransactionExtension defines the CoinRecoveryTransaction Extension Data
+	CoinRecoveryTransactionExtension struct {
+		Nonce               types.TransactionNonce
+		FreezeTransactionID types.TransactionID
+		MintFulfillment     types.UnlockFulfillmentProxy
+	}
+)
+
+// CoinRecoveryTransactionFromTransaction creates a CoinRecoveryTransaction,
+// using a regular in-memory tfchain transaction.
+//
+// Past the (tx) Version validation it piggy-backs onto the
+// `CoinRecoveryTransactionFromTransactionData` constructor.
+func CoinRecoveryTransactionFromTransaction(tx types.Transaction) (CoinRecoveryTransaction, error) {
+	if tx.Version != TransactionVersionCoinRecovery {
+		return CoinRecoveryTransaction{}, fmt.Errorf(
+			"a coin recovery transaction requires tx version %d",
+			TransactionVersionCoinRecovery)
+	}
+	return CoinRecoveryTransactionFromTransactionData(types.TransactionData{
+		CoinInputs:        tx.CoinInputs,
+		CoinOutputs:       tx.CoinOutputs,
+		BlockStakeInputs:  tx.BlockStakeInputs,
+		BlockStakeOutputs: tx.BlockStakeOutputs,
+		MinerFees:         tx.MinerFees,
+		ArbitraryData:     tx.ArbitraryData,
+		Extension:         tx.Extension,
+	})
+}
+
+// CoinRecoveryTransactionFromTransactionData creates a CoinRecoveryTransaction,
+// using the TransactionData from a regular in-memory tfchain transaction.
+func CoinRecoveryTransactionFromTransactionData(txData types.TransactionData) (CoinRecoveryTransaction, error) {
+	// (tx) extension (data) is expected to be a pointer to a valid CoinRecoveryTransactionExtension
+	extensionData, ok := txData.Extension.(*CoinRecoveryTransactionExtension)
+	if !ok {
+		return CoinRecoveryTransaction{}, errors.New("invalid extension data for a CoinRecoveryTransaction")
+	}
+	// at least one coin output as well as one miner fee is required
+	if len(txData.CoinOutputs) == 0 {
+		return CoinRecoveryTransaction{}, errors.New("at least one coin output is required for a CoinRecoveryTransaction")
+	}
+	if len(txData.MinerFees) == 0 {
+		return CoinRecoveryTransaction{}, errors.New("at least one miner fee is required for a CoinRecoveryTransaction")
+	}
+	// no coin inputs, block stake inputs or block stake outputs are allowed
+	if len(txData.CoinInputs) != 0 || len(txData.BlockStakeInputs) != 0 || len(txData.BlockStakeOutputs) != 0 {
+		return CoinRecoveryTransaction{}, errors.New("no coin inputs and block stake inputs/outputs are allowed in a CoinRecoveryTransaction")
+	}
+	return CoinRecoveryTransaction{
+		Nonce:               extensionData.Nonce,
+		FreezeTransactionID: extensionData.FreezeTransactionID,
+		MintFulfillment:     extensionData.MintFulfillment,
+		CoinOutputs:         txData.CoinOutputs,
+		MinerFees:           txData.MinerFees,
+		ArbitraryData:       txData.ArbitraryData,
+	}, nil
+}
+
+// TransactionData returns this CoinRecoveryTransaction
+// as regular tfchain transaction data.
+func (crtx *CoinRecoveryTransaction) TransactionData() types.TransactionData {
+	return types.TransactionData{
+		CoinOutputs:   crtx.CoinOutputs,
+		MinerFees:     crtx.MinerFees,
+		ArbitraryData: crtx.ArbitraryData,
+		Extension: &CoinRecoveryTransactionExtension{
+			Nonce:               crtx.Nonce,
+			FreezeTransactionID: crtx.FreezeTransactionID,
+			MintFulfillment:     crtx.MintFulfillment,
+		},
+	}
+}
+
+// Transaction returns this CoinRecoveryTransaction
+// as regular tfchain transaction, using TransactionVersionCoinRecovery as the type.
+func (crtx *CoinRecoveryTransaction) Transaction() types.Transaction {
+	return types.Transaction{
+		Version:       TransactionVersionCoinRecovery,
+		CoinOutputs:   crtx.CoinOutputs,
+		MinerFees:     crtx.MinerFees,
+		ArbitraryData: crtx.ArbitraryData,
+		Extension: &CoinRecoveryTransactionExtension{
+			Nonce:               crtx.Nonce,
+			FreezeTransactionID: crtx.FreezeTransactionID,
+			MintFulfillment:     crtx.MintFulfillment,
+		},
+	}
+}
+
+// RecoveredValue returns the total value recovered by this transaction,
+// the sum of all its coin outputs and miner fees.
+func (crtx *CoinRecoveryTransaction) RecoveredValue() (value types.Currency) {
+	for _, co := range crtx.CoinOutputs {
+		value = value.Add(co.Value)
+	}
+	for _, fee := range crtx.MinerFees {
+		value = value.Add(fee)
+	}
+	return
+}
+
+// MarshalSia implements SiaMarshaler.MarshalSia,
+// alias of MarshalRivine for backwards-compatibility reasons.
+func (crtx CoinRecoveryTransaction) MarshalSia(w io.Writer) error {
+	return crtx.MarshalRivine(w)
+}
+
+// UnmarshalSia implements SiaUnmarshaler.UnmarshalSia,
+// alias of UnmarshalRivine for backwards-compatibility reasons.
+func (crtx *CoinRecoveryTransaction) UnmarshalSia(r io.Reader) error {
+	return crtx.UnmarshalRivine(r)
+}
+
+// MarshalRivine implements RivineMarshaler.MarshalRivine
+func (crtx CoinRecoveryTransaction) MarshalRivine(w io.Writer) error {
+	return rivbin.NewEncoder(w).EncodeAll(
+		crtx.Nonce,
+		crtx.FreezeTransactionID,
+		crtx.MintFulfillment,
+		crtx.CoinOutputs,
+		crtx.MinerFees,
+		crtx.ArbitraryData,
+	)
+}
+
+// UnmarshalRivine implements RivineUnmarshaler.UnmarshalRivine
+func (crtx *CoinRecoveryTransaction) UnmarshalRivine(r io.Reader) error {
+	return rivbin.NewDecoder(r).DecodeAll(
+		&crtx.Nonce,
+		&crtx.FreezeTransactionID,
+		&crtx.MintFulfillment,
+		&crtx.CoinOutputs,
+		&crtx.MinerFees,
+		&crtx.ArbitraryData,
+	)
+}
+
+type (
+	// AddressBlacklistTransaction defines the Transaction (with version 0xa2)
+	// used to blacklist an address, as ordered by a court (e.g. because it holds stolen funds),
+	// such that none of its (current and future) unspent coin outputs can be spent,
+	// until the blacklisting expires or is lifted using an AddressBlacklistLiftTransaction.
+	// It is to be created only by the Coin Minters.
+	AddressBlacklistTransaction struct {
+		// Nonce used to ensure the uniqueness of an AddressBlacklistTransaction's ID and signature.
+		Nonce types.TransactionNonce `json:"nonce"`
+		// Address to blacklist, either a PubKey or MultiSig address.
+		Address types.UnlockHash `json:"address"`
+		// DocumentHash is the hash of the (court) document
+		// that motivates the blacklisting of the address.
+		DocumentHash crypto.Hash `json:"documenthash"`
+		// ExpiryHeight is the block height since which the address is no longer blacklisted,
+		// 0 if the blacklisting only ends when it is lifted.
+		ExpiryHeight types.BlockHeight `json:"expiryheight,omitempty"`
+		// MintFulfillment defines the fulfillment which is used in order to
+		// fulfill the globally defined MintCondition.
+		MintFulfillment types.UnlockFulfillmentProxy `json:"mintfulfillment"`
+		// MinerFees, a fee paid for this address blacklist transaction.
+		MinerFees []types.Currency `json:"minerfees"`
+		// ArbitraryData can be used for any purpose.
+		ArbitraryData []byte `json:"arbitrarydata,omitempty"`
+	}
+	// AddressBlacklistTransactionExtension defines the AddressBlacklistTransaction Extension Data
+	AddressBlacklistTransactionExtension struct {
+		Nonce           types.TransactionNonce
+		Address         types.UnlockHash
+		DocumentHash    crypto.Hash
+		ExpiryHeight    types.BlockHeight
+		MintFulfillment types.UnlockFulfillmentProxy
+	}
+)
+
+// AddressBlacklistTransactionFromTransaction creates an AddressBlacklistTransaction,
+// using a regular in-memory tfchain transaction.
+//
+// Past the (tx) Version validation it piggy-backs onto the
+// `AddressBlacklistTransactionFromTransactionData` constructor.
+func AddressBlacklistTransactionFromTransaction(tx types.Transaction) (AddressBlacklistTransaction, error) {
+	if tx.Version != TransactionVersionAddressBlacklist {
+		return AddressBlacklistTransaction{}, fmt.Errorf(
+			"an address blacklist transaction requires tx version %d",
+			TransactionVersionAddressBlacklist)
+	}
+	return AddressBlacklistTransactionFromTransactionData(types.TransactionData{
+		CoinInputs:        tx.CoinInputs,
+		CoinOutputs:       tx.CoinOutputs,
+		BlockStakeInputs:  tx.BlockStakeInputs,
+		BlockStakeOutputs: tx.BlockStakeOutputs,
+		MinerFees:         tx.MinerFees,
+		ArbitraryData:     tx.ArbitraryData,
+		Extension:         tx.Extension,
+	})
+}
+
+// AddressBlacklistTransactionFromTransactionData creates an AddressBlacklistTransaction,
+// using the TransactionData from a regular in-memory tfchain transaction.
+func AddressBlacklistTransactionFromTransactionData(txData types.TransactionData) (AddressBlacklistTransaction, error) {
+	// (tx) extension (data) is expected to be a pointer to a valid AddressBlacklistTransactionExtension
+	extensionData, ok := txData.Extension.(*AddressBlacklistTransactionExtension)
+	if !ok {
+		return AddressBlacklistTransaction{}, errors.New("invalid extension data for an AddressBlacklistTransaction")
+	}
+	// at least one miner fee is required
+	if len(txData.MinerFees) == 0 {
+		return AddressBlacklistTransaction{}, errors.New("at least one miner fee is required for an AddressBlacklistTransaction")
+	}
+	// no coin inputs/outputs or block stake inputs/outputs are allowed
+	if len(txData.CoinInputs) != 0 || len(txData.CoinOutputs) != 0 || len(txData.BlockStakeInputs) != 0 || len(txData.BlockStakeOutputs) != 0 {
+		return AddressBlacklistTransaction{}, errors.New("no coin inputs/outputs and block stake inputs/outputs are allowed in an AddressBlacklistTransaction")
+	}
+	return AddressBlacklistTransaction{
+		Nonce:           extensionData.Nonce,
+		Address:         extensionData.Address,
+		DocumentHash:    extensionData.DocumentHash,
+		ExpiryHeight:    extensionData.ExpiryHeight,
+		MintFulfillment: extensionData.MintFulfillment,
+		MinerFees:       txData.MinerFees,
+		ArbitraryData:   txData.ArbitraryData,
+	}, nil
+}
+
+// TransactionData returns this AddressBlacklistTransaction
+// as regular tfchain transaction data.
+func (abtx *AddressBlacklistTransaction) TransactionData() types.TransactionData {
+	return types.TransactionData{
+		MinerFees:     abtx.MinerFees,
+		ArbitraryData: abtx.ArbitraryData,
+		Extension: &AddressBlacklistTransactionExtension{
+			Nonce:           abtx.Nonce,
+			Address:         abtx.Address,
+			DocumentHash:    abtx.DocumentHash,
+			ExpiryHeight:    abtx.ExpiryHeight,
+			MintFulfillment: abtx.MintFulfillment,
+		},
+	}
+}
+
+// Transaction returns this AddressBlacklistTransaction
+// as regular tfchain transaction, using TransactionVersionAddressBlacklist as the type.
+func (abtx *AddressBlacklistTransaction) Transaction() types.Transaction {
+	return types.Transaction{
+		Version:       TransactionVersionAddressBlacklist,
+		MinerFees:     abtx.MinerFees,
+		ArbitraryData: abtx.ArbitraryData,
+		Extension: &AddressBlacklistTransactionExtension{
+			Nonce:           abtx.Nonce,
+			Address:         abtx.Address,
+			DocumentHash:    abtx.DocumentHash,
+			ExpiryHeight:    abtx.ExpiryHeight,
+			MintFulfillment: abtx.MintFulfillment,
+		},
+	}
+}
+
+// MarshalSia implements SiaMarshaler.MarshalSia,
+// alias of MarshalRivine for backwards-compatibility reasons.
+func (abtx AddressBlacklistTransaction) MarshalSia(w io.Writer) error {
+	return abtx.MarshalRivine(w)
+}
+
+// UnmarshalSia implements SiaUnmarshaler.UnmarshalSia,
+// alias of UnmarshalRivine for backwards-compatibility reasons.
+func (abtx *AddressBlacklistTransaction) UnmarshalSia(r io.Reader) error {
+	return abtx.UnmarshalRivine(r)
+}
+
+// MarshalRivine implements RivineMarshaler.MarshalRivine
+func (abtx AddressBlacklistTransaction) MarshalRivine(w io.Writer) error {
+	return rivbin.NewEncoder(w).EncodeAll(
+		abtx.Nonce,
+		abtx.Address,
+		abtx.DocumentHash,
+		abtx.ExpiryHeight,
+		abtx.MintFulfillment,
+		abtx.MinerFees,
+		abtx.ArbitraryData,
+	)
+}
+
+// UnmarshalRivine implements RivineUnmarshaler.UnmarshalRivine
+func (abtx *AddressBlacklistTransaction) UnmarshalRivine(r io.Reader) error {
+	return rivbin.NewDecoder(r).DecodeAll(
+		&abtx.Nonce,
+		&abtx.Address,
+		&abtx.DocumentHash,
+		&abtx.ExpiryHeight,
+		&abtx.MintFulfillment,
+		&abtx.MinerFees,
+		&abtx.ArbitraryData,
+	)
+}
+
+type (
+	// AddressBlacklistLiftTransaction defines the Transaction (with version 0xa3)
+	// used to lift the blacklisting of an address before it expires,
+	// such that its unspent coin outputs can be spent once again.
+	// It is to be created only by the Coin Minters.
+	AddressBlacklistLiftTransaction struct {
+		// Nonce used to ensure the uniqueness of an AddressBlacklistLiftTransaction's ID and signature.
+		Nonce types.TransactionNonce `json:"nonce"`
+		// Address of which to lift the blacklisting.
+		Address types.UnlockHash `json:"address"`
+		// DocumentHash is the hash of the (court) document
+		// that motivates the lifting of the blacklisting.
+		DocumentHash crypto.Hash `json:"documenthash"`
+		// MintFulfillment defines the fulfillment which is used in order to
+		// fulfill the globally defined MintCondition.
+		MintFulfillment types.UnlockFulfillmentProxy `json:"mintfulfillment"`
+		// MinerFees, a fee paid for this address blacklist lift transaction.
+		MinerFees []types.Currency `json:"minerfees"`
+		// ArbitraryData can be used for any purpose.
+		ArbitraryData []byte `json:"arbitrarydata,omitempty"`
+	}
+	// AddressBlacklistLiftTransactionExtension defines the AddressBlacklistLiftTransaction Extension Data
+	AddressBlacklistLiftTransactionExtension struct {
+		Nonce           types.TransactionNonce
+		Address         types.UnlockHash
+		DocumentHash    crypto.Hash
+		MintFulfillment types.UnlockFulfillmentProxy
+	}
+)
+
+// AddressBlacklistLiftTransactionFromTransaction creates an AddressBlacklistLiftTransaction,
+// using a regular in-memory tfchain transaction.
+//
+// Past the (tx) Version validation it piggy-backs onto the
+// `AddressBlacklistLiftTransactionFromTransactionData` constructor.
+func AddressBlacklistLiftTransactionFromTransaction(tx types.Transaction) (AddressBlacklistLiftTransaction, error) {
+	if tx.Version != TransactionVersionAddressBlacklistLift {
+		return AddressBlacklistLiftTransaction{}, fmt.Errorf(
+			"an address blacklist lift transaction requires tx version %d",
+			TransactionVersionAddressBlacklistLift)
+	}
+	return AddressBlacklistLiftTransactionFromTransactionData(types.TransactionData{
+		CoinInputs:        tx.CoinInputs,
+		CoinOutputs:       tx.CoinOutputs,
+		BlockStakeInputs:  tx.BlockStakeInputs,
+		BlockStakeOutputs: tx.BlockStakeOutputs,
+		MinerFees:         tx.MinerFees,
+		ArbitraryData:     tx.ArbitraryData,
+		Extension:         tx.Extension,
+	})
+}
+
+// AddressBlacklistLiftTransactionFromTransactionData creates an AddressBlacklistLiftTransaction,
+// using the TransactionData from a regular in-memory tfchain transaction.
+func AddressBlacklistLiftTransactionFromTransactionData(txData types.TransactionData) (AddressBlacklistLiftTransaction, error) {
+	// (tx) extension (data) is expected to be a pointer to a valid AddressBlacklistLiftTransactionExtension
+	extensionData, ok := txData.Extension.(*AddressBlacklistLiftTransactionExtension)
+	if !ok {
+		return AddressBlacklistLiftTransaction{}, errors.New("invalid extension data for an AddressBlacklistLiftTransaction")
+	}
+	// at least one miner fee is required
+	if len(txData.MinerFees) == 0 {
+		return AddressBlacklistLiftTransaction{}, errors.New("at least one miner fee is required for an AddressBlacklistLiftTransaction")
+	}
+	// no coin inputs/outputs or block stake inputs/outputs are allowed
+	if len(txData.CoinInputs) != 0 || len(txData.CoinOutputs) != 0 || len(txData.BlockStakeInputs) != 0 || len(txData.BlockStakeOutputs) != 0 {
+		return AddressBlacklistLiftTransaction{}, errors.New("no coin inputs/outputs and block stake inputs/outputs are allowed in an AddressBlacklistLiftTransaction")
+	}
+	return AddressBlacklistLiftTransaction{
+		Nonce:           extensionData.Nonce,
+		Address:         extensionData.Address,
+		DocumentHash:    extensionData.DocumentHash,
+		MintFulfillment: extensionData.MintFulfillment,
+		MinerFees:       txData.MinerFees,
+		ArbitraryData:   txData.ArbitraryData,
+	}, nil
+}
+
+// TransactionData returns this AddressBlacklistLiftTransaction
+// as regular tfchain transaction data.
+func (abltx *AddressBlacklistLiftTransaction) TransactionData() types.TransactionData {
+	return types.TransactionData{
+		MinerFees:     abltx.MinerFees,
+		ArbitraryData: abltx.ArbitraryData,
+		Extension: &AddressBlacklistLiftTransactionExtension{
+			Nonce:           abltx.Nonce,
+			Address:         abltx.Address,
+			DocumentHash:    abltx.DocumentHash,
+			MintFulfillment: abltx.MintFulfillment,
+		},
+	}
+}
+
+// Transaction returns this AddressBlacklistLiftTransaction
+// as regular tfchain transaction, using TransactionVersionAddressBlacklistLift as the type.
+func (abltx *AddressBlacklistLiftTransaction) Transaction() types.Transaction {
+	return types.Transaction{
+		Version:       TransactionVersionAddressBlacklistLift,
+		MinerFees:     abltx.MinerFees,
+		ArbitraryData: abltx.ArbitraryData,
+		Extension: &AddressBlacklistLiftTransactionExtension{
+			Nonce:           abltx.Nonce,
+			Address:         abltx.Address,
+			DocumentHash:    abltx.DocumentHash,
+			MintFulfillment: abltx.MintFulfillment,
+		},
+	}
+}
+
+// MarshalSia implements SiaMarshaler.MarshalSia,
+// alias of MarshalRivine for backwards-compatibility reasons.
+func (abltx AddressBlacklistLiftTransaction) MarshalSia(w io.Writer) error {
+	return abltx.MarshalRivine(w)
+}
+
+// UnmarshalSia implements SiaUnmarshaler.UnmarshalSia,
+// alias of UnmarshalRivine for backwards-compatibility reasons.
+func (abltx *AddressBlacklistLiftTransaction) UnmarshalSia(r io.Reader) error {
+	return abltx.UnmarshalRivine(r)
+}
+
+// MarshalRivine implements RivineMarshaler.MarshalRivine
+func (abltx AddressBlacklistLiftTransaction) MarshalRivine(w io.Writer) error {
+	return rivbin.NewEncoder(w).EncodeAll(
+		abltx.Nonce,
+		abltx.Address,
+		abltx.DocumentHash,
+		abltx.MintFulfillment,
+		abltx.MinerFees,
+		abltx.ArbitraryData,
+	)
+}
+
+// UnmarshalRivine implements RivineUnmarshaler.UnmarshalRivine
+func (abltx *AddressBlacklistLiftTransaction) UnmarshalRivine(r io.Reader) error {
+	return rivbin.NewDecoder(r).DecodeAll(
+		&abltx.Nonce,
+		&abltx.Address,
+		&abltx.DocumentHash,
+		&abltx.MintFulfillment,
+		&abltx.MinerFees,
+		&abltx.ArbitraryData,
+	)
+}
+
+type (
+	// AddressFreezeTransactionController defines a tfchain-specific transaction controller,
+	// for a transaction type reserved at type 0xa0. It allows the Coin Minters to freeze an address.
+	AddressFreezeTransactionController struct {
+		// MintConditionGetter is used to get the mint condition,
+		// which has to be fulfilled in order to freeze an address.
+		MintConditionGetter minting.MintConditionGetter
+	}
+
+	// CoinRecoveryTransactionController defines a tfchain-specific transaction controller,
+	// for a transaction type reserved at type 0xa1. It allows the Coin Minters to recover
+	// the coins of a frozen address.
+	CoinRecoveryTransactionController struct {
+		// MintConditionGetter is used to get the mint condition,
+		// which has to be fulfilled in order to recover coins.
+		MintConditionGetter minting.MintConditionGetter
+	}
+
+	// AddressBlacklistTransactionController defines a tfchain-specific transaction controller,
+	// for a transaction type reserved at type 0xa2. It allows the Coin Minters to blacklist an address.
+	AddressBlacklistTransactionController struct {
+		// MintConditionGetter is used to get the mint condition,
+		// which has to be fulfilled in order to blacklist an address.
+		MintConditionGetter minting.MintConditionGetter
+	}
+
+	// AddressBlacklistLiftTransactionController defines a tfchain-specific transaction controller,
+	// for a transaction type reserved at type 0xa3. It allows the Coin Minters to lift
+	// the blacklisting of an address.
+	AddressBlacklistLiftTransactionController struct {
+		// MintConditionGetter is used to get the mint condition,
+		// which has to be fulfilled in order to lift the blacklisting of an address.
+		MintConditionGetter minting.MintConditionGetter
+	}
+)
+
+var (
+	// ensure at compile time that AddressFreezeTransactionController
+	// implements the desired interfaces
+	_ types.TransactionController                = AddressFreezeTransactionController{}
+	_ types.TransactionExtensionSigner           = AddressFreezeTransactionController{}
+	_ types.TransactionSignatureHasher           = AddressFreezeTransactionController{}
+	_ types.TransactionIDEncoder                 = AddressFreezeTransactionController{}
+	_ types.TransactionCommonExtensionDataGetter = AddressFreezeTransactionController{}
+
+	// ensure at compile time that CoinRecoveryTransactionController
+	// implements the desired interfaces
+	_ types.TransactionController      = CoinRecoveryTransactionController{}
+	_ types.TransactionExtensionSigner = CoinRecoveryTransactionController{}
+	_ types.TransactionSignatureHasher = CoinRecoveryTransactionController{}
+	_ types.TransactionIDEncoder       = CoinRecoveryTransactionController{}
+
+	// ensure at compile time that AddressBlacklistTransactionController
+	// implements the desired interfaces
+	_ types.TransactionController                = AddressBlacklistTransactionController{}
+	_ types.TransactionExtensionSigner           = AddressBlacklistTransactionController{}
+	_ types.TransactionSignatureHasher           = AddressBlacklistTransactionController{}
+	_ types.TransactionIDEncoder                 = AddressBlacklistTransactionController{}
+	_ types.TransactionCommonExtensionDataGetter = AddressBlacklistTransactionController{}
+
+	// ensure at compile time that AddressBlacklistLiftTransactionController
+	// implements the desired interfaces
+	_ types.TransactionController                = AddressBlacklistLiftTransactionController{}
+	_ types.TransactionExtensionSigner           = AddressBlacklistLiftTransactionController{}
+	_ types.TransactionSignatureHasher           = AddressBlacklistLiftTransactionController{}
+	_ types.TransactionIDEncoder                 = AddressBlacklistLiftTransactionController{}
+	_ types.TransactionCommonExtensionDataGetter = AddressBlacklistLiftTransactionController{}
+)
+
+// AddressFreezeTransactionController
+
+// EncodeTransactionData implements TransactionController.EncodeTransactionData
+func (aftc AddressFreezeTransactionController) EncodeTransactionData(w io.Writer, txData types.TransactionData) error {
+	aftx, err := AddressFreezeTransactionFromTransactionData(txData)
+	if err != nil {
+		return fmt.Errorf("failed to convert txData to an AddressFreezeTx: %v", err)
+	}
+	return rivbin.NewEncoder(w).Encode(aftx)
+}
+
+// DecodeTransactionData implements TransactionController.DecodeTransactionData
+func (aftc AddressFreezeTransactionController) DecodeTransactionData(r io.Reader) (types.TransactionData, error) {
+	var aftx AddressFreezeTransaction
+	err := rivbin.NewDecoder(r).Decode(&aftx)
+	if err != nil {
+		return types.TransactionData{}, fmt.Errorf(
+			"failed to binary-decode tx as an AddressFreezeTx: %v", err)
+	}
+	// return address freeze tx as regular tfchain tx data
+	return aftx.TransactionData(), nil
+}
+
+// JSONEncodeTransactionData implements TransactionController.JSONEncodeTransactionData
+func (aftc AddressFreezeTransactionController) JSONEncodeTransactionData(txData types.TransactionData) ([]byte, error) {
+	aftx, err := AddressFreezeTransactionFromTransactionData(txData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert txData to an AddressFreezeTx: %v", err)
+	}
+	return json.Marshal(aftx)
+}
+
+// JSONDecodeTransactionData implements TransactionController.JSONDecodeTransactionData
+func (aftc AddressFreezeTransactionController) JSONDecodeTransactionData(data []byte) (types.TransactionData, error) {
+	var aftx AddressFreezeTransaction
+	err := json.Unmarshal(data, &aftx)
+	if err != nil {
+		return types.TransactionData{}, fmt.Errorf(
+			"failed to json-decode tx as an AddressFreezeTx: %v", err)
+	}
+	// return address freeze tx as regular tfchain tx data
+	return aftx.TransactionData(), nil
+}
+
+// SignExtension implements TransactionExtensionSigner.SignExtension
+func (aftc AddressFreezeTransactionController) SignExtension(extension interface{}, sign func(*types.UnlockFulfillmentProxy, types.UnlockConditionProxy, ...interface{}) error) (interface{}, error) {
+	// (tx) extension (data) is expected to be a pointer to a valid AddressFreezeTransactionExtension
+	afTxExtension, ok := extension.(*AddressFreezeTransactionExtension)
+	if !ok {
+		return nil, errors.New("invalid extension data for an AddressFreezeTransaction")
+	}
+	mintCondition, err := aftc.MintConditionGetter.GetActiveMintCondition()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the active mint condition: %v", err)
+	}
+	err = sign(&afTxExtension.MintFulfillment, mintCondition)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign mint fulfillment of address freeze tx: %v", err)
+	}
+	return afTxExtension, nil
+}
+
+// SignatureHash implements TransactionSignatureHasher.SignatureHash
+func (aftc AddressFreezeTransactionController) SignatureHash(t types.Transaction, extraObjects ...interface{}) (crypto.Hash, error) {
+	aftx, err := AddressFreezeTransactionFromTransaction(t)
+	if err != nil {
+		return crypto.Hash{}, fmt.Errorf("failed to use tx as an address freeze tx: %v", err)
+	}
+
+	h := crypto.NewHash()
+	enc := rivbin.NewEncoder(h)
+
+	enc.EncodeAll(
+		t.Version,
+		SpecifierAddressFreezeTransaction,
+		aftx.Nonce,
+	)
+
+	if len(extraObjects) > 0 {
+		enc.EncodeAll(extraObjects...)
+	}
+
+	enc.EncodeAll(
+		aftx.Address,
+		aftx.DocumentHash,
+		aftx.MinerFees,
+		aftx.ArbitraryData,
+	)
+
+	var hash crypto.Hash
+	h.Sum(hash[:0])
+	return hash, nil
+}
+
+// EncodeTransactionIDInput implements TransactionIDEncoder.EncodeTransactionIDInput
+func (aftc AddressFreezeTransactionController) EncodeTransactionIDInput(w io.Writer, txData types.TransactionData) error {
+	aftx, err := AddressFreezeTransactionFromTransactionData(txData)
+	if err != nil {
+		return fmt.Errorf("failed to convert txData to an AddressFreezeTx: %v", err)
+	}
+	return rivbin.NewEncoder(w).EncodeAll(SpecifierAddressFreezeTransaction, aftx)
+}
+
+// GetCommonExtensionData implements TransactionCommonExtensionDataGetter.GetCommonExtensionData,
+// such that the explorer links the transaction to the frozen address.
+func (aftc AddressFreezeTransactionController) GetCommonExtensionData(extension interface{}) (types.CommonTransactionExtensionData, error) {
+	afTxExtension, ok := extension.(*AddressFreezeTransactionExtension)
+	if !ok {
+		return types.CommonTransactionExtensionData{}, errors.New("invalid extension data for an AddressFreezeTransaction")
+	}
+	return types.CommonTransactionExtensionData{
+		UnlockConditions: []types.UnlockConditionProxy{
+			types.NewCondition(types.NewUnlockHashCondition(afTxExtension.Address)),
+		},
+	}, nil
+}
+
+// CoinRecoveryTransactionController
+
+// EncodeTransactionData implements TransactionController.EncodeTransactionData
+func (crtc CoinRecoveryTransactionController) EncodeTransactionData(w io.Writer, txData types.TransactionData) error {
+	crtx, err := CoinRecoveryTransactionFromTransactionData(txData)
+	if err != nil {
+		return fmt.Errorf("failed to convert txData to a CoinRecoveryTx: %v", err)
+	}
+	return rivbin.NewEncoder(w).Encode(crtx)
+}
+
+// DecodeTransactionData implements TransactionController.DecodeTransactionData
+func (crtc CoinRecoveryTransactionController) DecodeTransactionData(r io.Reader) (types.TransactionData, error) {
+	var crtx CoinRecoveryTransaction
+	err := rivbin.NewDecoder(r).Decode(&crtx)
+	if err != nil {
+		return types.TransactionData{}, fmt.Errorf(
+			"failed to binary-decode tx as a CoinRecoveryTx: %v", err)
+	}
+	// return coin recovery tx as regular tfchain tx data
+	return crtx.TransactionData(), nil
+}
+
+// JSONEncodeTransactionData implements TransactionController.JSONEncodeTransactionData
+func (crtc CoinRecoveryTransactionController) JSONEncodeTransactionData(txData types.TransactionData) ([]byte, error) {
+	crtx, err := CoinRecoveryTransactionFromTransactionData(txData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert txData to a CoinRecoveryTx: %v", err)
+	}
+	return json.Marshal(crtx)
+}
+
+// JSONDecodeTransactionData implements TransactionController.JSONDecodeTransactionData
+func (crtc CoinRecoveryTransactionController) JSONDecodeTransactionData(data []byte) (types.TransactionData, error) {
+	var crtx CoinRecoveryTransaction
+	err := json.Unmarshal(data, &crtx)
+	if err != nil {
+		return types.TransactionData{}, fmt.Errorf(
+			"failed to json-decode tx as a CoinRecoveryTx: %v", err)
+	}
+	// return coin recovery tx as regular tfchain tx data
+	return crtx.TransactionData(), nil
+}
+
+// SignExtension implements TransactionExtensionSigner.SignExtension
+func (crtc CoinRecoveryTransactionController) SignExtension(extension interface{}, sign func(*types.UnlockFulfillmentProxy, types.UnlockConditionProxy, ...interface{}) error) (interface{}, error) {
+	// (tx) extension (data) is expected to be a pointer to a valid CoinRecoveryTransactionExtension
+	crTxExtension, ok := extension.(*CoinRecoveryTransactionExtension)
+	if !ok {
+		return nil, errors.New("invalid extension data for a CoinRecoveryTransaction")
+	}
+	mintCondition, err := crtc.MintConditionGetter.GetActiveMintCondition()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the active mint condition: %v", err)
+	}
+	err = sign(&crTxExtension.MintFulfillment, mintCondition)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign mint fulfillment of coin recovery tx: %v", err)
+	}
+	return crTxExtension, nil
+}
+
+// SignatureHash implements TransactionSignatureHasher.SignatureHash
+func (crtc CoinRecoveryTransactionController) SignatureHash(t types.Transaction, extraObjects ...interface{}) (crypto.Hash, error) {
+	crtx, err := CoinRecoveryTransactionFromTransaction(t)
+	if err != nil {
+		return crypto.Hash{}, fmt.Errorf("failed to use tx as a coin recovery tx: %v", err)
+	}
+
+	h := crypto.NewHash()
+	enc := rivbin.NewEncoder(h)
+
+	enc.EncodeAll(
+		t.Version,
+		SpecifierCoinRecoveryTransaction,
+		crtx.Nonce,
+	)
+
+	if len(extraObjects) > 0 {
+		enc.EncodeAll(extraObjects...)
+	}
+
+	enc.EncodeAll(
+		crtx.FreezeTransactionID,
+		crtx.CoinOutputs,
+		crtx.MinerFees,
+		crtx.ArbitraryData,
+	)
+
+	var hash crypto.Hash
+	h.Sum(hash[:0])
+	return hash, nil
+}
+
+// EncodeTransactionIDInput implements TransactionIDEncoder.EncodeTransactionIDInput
+func (crtc CoinRecoveryTransactionController) EncodeTransactionIDInput(w io.Writer, txData types.TransactionData) error {
+	crtx, err := CoinRecoveryTransactionFromTransactionData(txData)
+	if err != nil {
+		return fmt.Errorf("failed to convert txData to a CoinRecoveryTx: %v", err)
+	}
+	return rivbin.NewEncoder(w).EncodeAll(SpecifierCoinRecoveryTransaction, crtx)
+}
+
+// AddressBlacklistTransactionController
+
+// EncodeTransactionData implements TransactionController.EncodeTransactionData
+func (abtc AddressBlacklistTransactionController) EncodeTransactionData(w io.Writer, txData types.TransactionData) error {
+	abtx, err := AddressBlacklistTransactionFromTransactionData(txData)
+	if err != nil {
+		return fmt.Errorf("failed to convert txData to an AddressBlacklistTx: %v", err)
+	}
+	return rivbin.NewEncoder(w).Encode(abtx)
+}
+
+// DecodeTransactionData implements TransactionController.DecodeTransactionData
+func (abtc AddressBlacklistTransactionController) DecodeTransactionData(r io.Reader) (types.TransactionData, error) {
+	var abtx AddressBlacklistTransaction
+	err := rivbin.NewDecoder(r).Decode(&abtx)
+	if err != nil {
+		return types.TransactionData{}, fmt.Errorf(
+			"failed to binary-decode tx as an AddressBlacklistTx: %v", err)
+	}
+	// return address blacklist tx as regular tfchain tx data
+	return abtx.TransactionData(), nil
+}
+
+// JSONEncodeTransactionData implements TransactionController.JSONEncodeTransactionData
+func (abtc AddressBlacklistTransactionController) JSONEncodeTransactionData(txData types.TransactionData) ([]byte, error) {
+	abtx, err := AddressBlacklistTransactionFromTransactionData(txData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert txData to an AddressBlacklistTx: %v", err)
+	}
+	return json.Marshal(abtx)
+}
+
+// JSONDecodeTransactionData implements TransactionController.JSONDecodeTransactionData
+func (abtc AddressBlacklistTransactionController) JSONDecodeTransactionData(data []byte) (types.TransactionData, error) {
+	var abtx AddressBlacklistTransaction
+	err := json.Unmarshal(data, &abtx)
+	if err != nil {
+		return types.TransactionData{}, fmt.Errorf(
+			"failed to json-decode tx as an AddressBlacklistTx: %v", err)
+	}
+	// return address blacklist tx as regular tfchain tx data
+	return abtx.TransactionData(), nil
+}
+
+// SignExtension implements TransactionExtensionSigner.SignExtension
+func (abtc AddressBlacklistTransactionController) SignExtension(extension interface{}, sign func(*types.UnlockFulfillmentProxy, types.UnlockConditionProxy, ...interface{}) error) (interface{}, error) {
+	// (tx) extension (data) is expected to be a pointer to a valid AddressBlacklistTransactionExtension
+	abTxExtension, ok := extension.(*AddressBlacklistTransactionExtension)
+	if !ok {
+		return nil, errors.New("invalid extension data for an AddressBlacklistTransaction")
+	}
+	mintCondition, err := abtc.MintConditionGetter.GetActiveMintCondition()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the active mint condition: %v", err)
+	}
+	err = sign(&abTxExtension.MintFulfillment, mintCondition)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign mint fulfillment of address blacklist tx: %v", err)
+	}
+	return abTxExtension, nil
+}
+
+// SignatureHash implements TransactionSignatureHasher.SignatureHash
+func (abtc AddressBlacklistTransactionController) SignatureHash(t types.Transaction, extraObjects ...interface{}) (crypto.Hash, error) {
+	abtx, err := AddressBlacklistTransactionFromTransaction(t)
+	if err != nil {
+		return crypto.Hash{}, fmt.Errorf("failed to use tx as an address blacklist tx: %v", err)
+	}
+
+	h := crypto.NewHash()
+	enc := rivbin.NewEncoder(h)
+
+	enc.EncodeAll(
+		t.Version,
+		SpecifierAddressBlacklistTransaction,
+		abtx.Nonce,
+	)
+
+	if len(extraObjects) > 0 {
+		enc.EncodeAll(extraObjects...)
+	}
+
+	enc.EncodeAll(
+		abtx.Address,
+		abtx.DocumentHash,
+		abtx.ExpiryHeight,
+		abtx.MinerFees,
+		abtx.ArbitraryData,
+	)
+
+	var hash crypto.Hash
+	h.Sum(hash[:0])
+	return hash, nil
+}
+
+// EncodeTransactionIDInput implements TransactionIDEncoder.EncodeTransactionIDInput
+func (abtc AddressBlacklistTransactionController) EncodeTransactionIDInput(w io.Writer, txData types.TransactionData) error {
+	abtx, err := AddressBlacklistTransactionFromTransactionData(txData)
+	if err != nil {
+		return fmt.Errorf("failed to convert txData to an AddressBlacklistTx: %v", err)
+	}
+	return rivbin.NewEncoder(w).EncodeAll(SpecifierAddressBlacklistTransaction, abtx)
+}
+
+// GetCommonExtensionData implements TransactionCommonExtensionDataGetter.GetCommonExtensionData,
+// such that the explorer links the transaction to the blacklisted address.
+func (abtc AddressBlacklistTransactionController) GetCommonExtensionData(extension interface{}) (types.CommonTransactionExtensionData, error) {
+	abTxExtension, ok := extension.(*AddressBlacklistTransactionExtension)
+	if !ok {
+		return types.CommonTransactionExtensionData{}, errors.New("invalid extension data for an AddressBlacklistTransaction")
+	}
+	return types.CommonTransactionExtensionData{
+		UnlockConditions: []types.UnlockConditionProxy{
+			types.NewCondition(types.NewUnlockHashCondition(abTxExtension.Address)),
+		},
+	}, nil
+}
+
+// AddressBlacklistLiftTransactionController
+
+// EncodeTransactionData implements TransactionController.EncodeTransactionData
+func (abltc AddressBlacklistLiftTransactionController) EncodeTransactionData(w io.Writer, txData types.TransactionData) error {
+	abltx, err := AddressBlacklistLiftTransactionFromTransactionData(txData)
+	if err != nil {
+		return fmt.Errorf("failed to convert txData to an AddressBlacklistLiftTx: %v", err)
+	}
+	return rivbin.NewEncoder(w).Encode(abltx)
+}
+
+// DecodeTransactionData implements TransactionController.DecodeTransactionData
+func (abltc AddressBlacklistLiftTransactionController) DecodeTransactionData(r io.Reader) (types.TransactionData, error) {
+	var abltx AddressBlacklistLiftTransaction
+	err := rivbin.NewDecoder(r).Decode(&abltx)
+	if err != nil {
+		return types.TransactionData{}, fmt.Errorf(
+			"failed to binary-decode tx as an AddressBlacklistLiftTx: %v", err)
+	}
+	// return address blacklist lift tx as regular tfchain tx data
+	return abltx.TransactionData(), nil
+}
+
+// JSONEncodeTransactionData implements TransactionController.JSONEncodeTransactionData
+func (abltc AddressBlacklistLiftTransactionController) JSONEncodeTransactionData(txData types.TransactionData) ([]byte, error) {
+	abltx, err := AddressBlacklistLiftTransactionFromTransactionData(txData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert txData to an AddressBlacklistLiftTx: %v", err)
+	}
+	return json.Marshal(abltx)
+}
+
+// JSONDecodeTransactionData implements TransactionController.JSONDecodeTransactionData
+func (abltc AddressBlacklistLiftTransactionController) JSONDecodeTransactionData(data []byte) (types.TransactionData, error) {
+	var abltx AddressBlacklistLiftTransaction
+	err := json.Unmarshal(data, &abltx)
+	if err != nil {
+		return types.TransactionData{}, fmt.Errorf(
+			"failed to json-decode tx as an AddressBlacklistLiftTx: %v", err)
+	}
+	// return address blacklist lift tx as regular tfchain tx data
+	return abltx.TransactionData(), nil
+}
+
+// SignExtension implements TransactionExtensionSigner.SignExtension
+func (abltc AddressBlacklistLiftTransactionController) SignExtension(extension interface{}, sign func(*types.UnlockFulfillmentProxy, types.UnlockConditionProxy, ...interface{}) error) (interface{}, error) {
+	// (tx) extension (data) is expected to be a pointer to a valid AddressBlacklistLiftTransactionExtension
+	ablTxExtension, ok := extension.(*AddressBlacklistLiftTransactionExtension)
+	if !ok {
+		return nil, errors.New("invalid extension data for an AddressBlacklistLiftTransaction")
+	}
+	mintCondition, err := abltc.MintConditionGetter.GetActiveMintCondition()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the active mint condition: %v", err)
+	}
+	err = sign(&ablTxExtension.MintFulfillment, mintCondition)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign mint fulfillment of address blacklist lift tx: %v", err)
+	}
+	return ablTxExtension, nil
+}
+
+// SignatureHash implements TransactionSignatureHasher.SignatureHash
+func (abltc AddressBlacklistLiftTransactionController) SignatureHash(t types.Transaction, extraObjects ...interface{}) (crypto.Hash, error) {
+	abltx, err := AddressBlacklistLiftTransactionFromTransaction(t)
+	if err != nil {
+		return crypto.Hash{}, fmt.Errorf("failed to use tx as an address blacklist lift tx: %v", err)
+	}
+
+	h := crypto.NewHash()
+	enc := rivbin.NewEncoder(h)
+
+	enc.EncodeAll(
+		t.Version,
+		SpecifierAddressBlacklistLiftTransaction,
+		abltx.Nonce,
+	)
+
+	if len(extraObjects) > 0 {
+		enc.EncodeAll(extraObjects...)
+	}
+
+	enc.EncodeAll(
+		abltx.Address,
+		abltx.DocumentHash,
+		abltx.MinerFees,
+		abltx.ArbitraryData,
+	)
+
+	var hash crypto.Hash
+	h.Sum(hash[:0])
+	return hash, nil
+}
+
+// EncodeTransactionIDInput implements TransactionIDEncoder.EncodeTransactionIDInput
+func (abltc AddressBlacklistLiftTransactionController) EncodeTransactionIDInput(w io.Writer, txData types.TransactionData) error {
+	abltx, err := AddressBlacklistLiftTransactionFromTransactionData(txData)
+	if err != nil {
+		return fmt.Errorf("failed to convert txData to an AddressBlacklistLiftTx: %v", err)
+	}
+	return rivbin.NewEncoder(w).EncodeAll(SpecifierAddressBlacklistLiftTransaction, abltx)
+}
+
+// GetCommonExtensionData implements TransactionCommonExtensionDataGetter.GetCommonExtensionData,
+// such that the explorer links the transaction to the address of which the blacklisting is lifted.
+func (abltc AddressBlacklistLiftTransactionController) GetCommonExtensionData(extension interface{}) (types.CommonTransactionExtensionData, error) {
+	ablTxExtension, ok := extension.(*AddressBlacklistLiftTransactionExtension)
+	if !ok {
+		return types.CommonTransactionExtensionData{}, errors.New("invalid extension data for an AddressBlacklistLiftTransaction")
+	}
+	return types.CommonTransactionExtensionData{
+		UnlockConditions: []types.UnlockConditionProxy{
+			types.NewCondition(types.NewUnlockHashCondition(ablTxExtension.Address)),
+		},
+	}, nil
+}
@@ -0,0 +1,306 @@
+package types
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/pkg/encoding/rivbin"
+	"github.com/threefoldtech/rivine/types"
+)
+
+func TestAddressFreezeTransactionEncodingAndID(t *testing.T) {
+	types.RegisterTransactionVersion(TransactionVersionAddressFreeze, AddressFreezeTransactionController{})
+	defer types.RegisterTransactionVersion(TransactionVersionAddressFreeze, nil)
+
+	aftx := AddressFreezeTransaction{
+		Nonce:           types.RandomTransactionNonce(),
+		Address:         testAddress(t, "01b49da2ff193f46ee0fc684d7a6121a8b8e324144dffc7327471a4da79f1730960edcb2ce737f"),
+		DocumentHash:    crypto.HashBytes([]byte("lost seed declaration")),
+		MintFulfillment: testMintFulfillment(),
+		MinerFees:       []types.Currency{types.NewCurrency64(100000000)},
+		ArbitraryData:   []byte("lost seed"),
+	}
+	testTransactionEncodingAndID(t, aftx.Transaction())
+
+	tx := aftx.Transaction()
+	oaftx, err := AddressFreezeTransactionFromTransaction(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if oaftx.Nonce != aftx.Nonce || oaftx.Address.Cmp(aftx.Address) != 0 || oaftx.DocumentHash != aftx.DocumentHash {
+		t.Fatal("unexpected address freeze transaction", oaftx, "!=", aftx)
+	}
+	if !bytes.Equal(oaftx.ArbitraryData, aftx.ArbitraryData) {
+		t.Fatal("unexpected arbitrary data", oaftx.ArbitraryData, "!=", aftx.ArbitraryData)
+	}
+}
+
+func TestCoinRecoveryTransactionEncodingAndID(t *testing.T) {
+	types.RegisterTransactionVersion(TransactionVersionCoinRecovery, CoinRecoveryTransactionController{})
+	defer types.RegisterTransactionVersion(TransactionVersionCoinRecovery, nil)
+
+	crtx := CoinRecoveryTransaction{
+		Nonce:               types.RandomTransactionNonce(),
+		FreezeTransactionID: types.TransactionID(crypto.HashBytes([]byte("freeze tx"))),
+		MintFulfillment:     testMintFulfillment(),
+		CoinOutputs: []types.CoinOutput{
+			{
+				Value:     types.NewCurrency64(4200000000),
+				Condition: types.NewCondition(types.NewUnlockHashCondition(testAddress(t, "017fda17489854109399aa8c1bfa6bdef40f93606744d95cc5055270d78b465e6acd263c96ab2b"))),
+			},
+		},
+		MinerFees: []types.Currency{types.NewCurrency64(100000000)},
+	}
+	testTransactionEncodingAndID(t, crtx.Transaction())
+
+	ocrtx, err := CoinRecoveryTransactionFromTransaction(crtx.Transaction())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ocrtx.Nonce != crtx.Nonce || ocrtx.FreezeTransactionID != crtx.FreezeTransactionID {
+		t.Fatal("unexpected coin recovery transaction", ocrtx, "!=", crtx)
+	}
+	if value := ocrtx.RecoveredValue(); !value.Equals64(4300000000) {
+		t.Fatal("unexpected recovered value:", value.String())
+	}
+}
+
+func TestCoinRecoveryTransactionFromTransactionData(t *testing.T) {
+	co := types.CoinOutput{
+		Value:     types.NewCurrency64(1),
+		Condition: types.NewCondition(types.NewUnlockHashCondition(testAddress(t, "017fda17489854109399aa8c1bfa6bdef40f93606744d95cc5055270d78b465e6acd263c96ab2b"))),
+	}
+	testCases := []struct {
+		TxData types.TransactionData
+		Valid  bool
+	}{
+		{types.TransactionData{}, false},
+		{types.TransactionData{Extension: &CoinRecoveryTransactionExtension{}}, false},
+		{types.TransactionData{
+			Extension: &CoinRecoveryTransactionExtension{},
+			MinerFees: []types.Currency{types.NewCurrency64(1)},
+		}, false},
+		{types.TransactionData{
+			Extension:   &CoinRecoveryTransactionExtension{},
+			CoinOutputs: []types.CoinOutput{co},
+		}, false},
+		{types.TransactionData{
+			Extension:   &CoinRecoveryTransactionExtension{},
+			CoinInputs:  []types.CoinInput{{}},
+			CoinOutputs: []types.CoinOutput{co},
+			MinerFees:   []types.Currency{types.NewCurrency64(1)},
+		}, false},
+		{types.TransactionData{
+			Extension:   &CoinRecoveryTransactionExtension{},
+			CoinOutputs: []types.CoinOutput{co},
+			MinerFees:   []types.Currency{types.NewCurrency64(1)},
+		}, true},
+	}
+	for idx, testCase := range testCases {
+		_, err := CoinRecoveryTransactionFromTransactionData(testCase.TxData)
+		if testCase.Valid && err != nil {
+			t.Errorf("test case #%d: unexpected error: %v", idx, err)
+		} else if !testCase.Valid && err == nil {
+			t.Errorf("test case #%d: expected error, but none received", idx)
+		}
+	}
+}
+
+func TestAddressFreezeTransactionUniqueSignatureHashes(t *testing.T) {
+	types.RegisterTransactionVersion(TransactionVersionAddressFreeze, AddressFreezeTransactionController{})
+	defer types.RegisterTransactionVersion(TransactionVersionAddressFreeze, nil)
+
+	aftx := AddressFreezeTransaction{
+		Nonce:           types.RandomTransactionNonce(),
+		Address:         testAddress(t, "01b49da2ff193f46ee0fc684d7a6121a8b8e324144dffc7327471a4da79f1730960edcb2ce737f"),
+		DocumentHash:    crypto.HashBytes([]byte("lost seed declaration")),
+		MintFulfillment: testMintFulfillment(),
+		MinerFees:       []types.Currency{types.NewCurrency64(100000000)},
+	}
+	hashes := map[crypto.Hash]struct{}{}
+	addHash := func(tx types.Transaction) {
+		hash, err := tx.SignatureHash()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := hashes[hash]; ok {
+			t.Fatal("duplicate signature hash:", hash.String())
+		}
+		hashes[hash] = struct{}{}
+	}
+	addHash(aftx.Transaction())
+	aftx.Nonce = types.RandomTransactionNonce()
+	addHash(aftx.Transaction())
+	aftx.DocumentHash = crypto.HashBytes([]byte("other declaration"))
+	addHash(aftx.Transaction())
+	aftx.Address = testAddress(t, "017fda17489854109399aa8c1bfa6bdef40f93606744d95cc5055270d78b465e6acd263c96ab2b")
+	addHash(aftx.Transaction())
+}
+
+func TestAddressBlacklistTransactionEncodingAndID(t *testing.T) {
+	types.RegisterTransactionVersion(TransactionVersionAddressBlacklist, AddressBlacklistTransactionController{})
+	defer types.RegisterTransactionVersion(TransactionVersionAddressBlacklist, nil)
+
+	abtx := AddressBlacklistTransaction{
+		Nonce:           types.RandomTransactionNonce(),
+		Address:         testAddress(t, "01b49da2ff193f46ee0fc684d7a6121a8b8e324144dffc7327471a4da79f1730960edcb2ce737f"),
+		DocumentHash:    crypto.HashBytes([]byte("court order")),
+		ExpiryHeight:    42000,
+		MintFulfillment: testMintFulfillment(),
+		MinerFees:       []types.Currency{types.NewCurrency64(100000000)},
+		ArbitraryData:   []byte("stolen funds"),
+	}
+	testTransactionEncodingAndID(t, abtx.Transaction())
+
+	oabtx, err := AddressBlacklistTransactionFromTransaction(abtx.Transaction())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if oabtx.Nonce != abtx.Nonce || oabtx.Address.Cmp(abtx.Address) != 0 ||
+		oabtx.DocumentHash != abtx.DocumentHash || oabtx.ExpiryHeight != abtx.ExpiryHeight {
+		t.Fatal("unexpected address blacklist transaction", oabtx, "!=", abtx)
+	}
+}
+
+func TestAddressBlacklistLiftTransactionEncodingAndID(t *testing.T) {
+	types.RegisterTransactionVersion(TransactionVersionAddressBlacklistLift, AddressBlacklistLiftTransactionController{})
+	defer types.RegisterTransactionVersion(TransactionVersionAddressBlacklistLift, nil)
+
+	abltx := AddressBlacklistLiftTransaction{
+		Nonce:           types.RandomTransactionNonce(),
+		Address:         testAddress(t, "01b49da2ff193f46ee0fc684d7a6121a8b8e324144dffc7327471a4da79f1730960edcb2ce737f"),
+		DocumentHash:    crypto.HashBytes([]byte("court order lifted")),
+		MintFulfillment: testMintFulfillment(),
+		MinerFees:       []types.Currency{types.NewCurrency64(100000000)},
+	}
+	testTransactionEncodingAndID(t, abltx.Transaction())
+
+	oabltx, err := AddressBlacklistLiftTransactionFromTransaction(abltx.Transaction())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if oabltx.Nonce != abltx.Nonce || oabltx.Address.Cmp(abltx.Address) != 0 || oabltx.DocumentHash != abltx.DocumentHash {
+		t.Fatal("unexpected address blacklist lift transaction", oabltx, "!=", abltx)
+	}
+}
+
+func TestAddressBlacklistTransactionUniqueSignatureHashes(t *testing.T) {
+	types.RegisterTransactionVersion(TransactionVersionAddressBlacklist, AddressBlacklistTransactionController{})
+	defer types.RegisterTransactionVersion(TransactionVersionAddressBlacklist, nil)
+
+	abtx := AddressBlacklistTransaction{
+		Nonce:           types.RandomTransactionNonce(),
+		Address:         testAddress(t, "01b49da2ff193f46ee0fc684d7a6121a8b8e324144dffc7327471a4da79f1730960edcb2ce737f"),
+		DocumentHash:    crypto.HashBytes([]byte("court order")),
+		MintFulfillment: testMintFulfillment(),
+		MinerFees:       []types.Currency{types.NewCurrency64(100000000)},
+	}
+	hashes := map[crypto.Hash]struct{}{}
+	addHash := func(tx types.Transaction) {
+		hash, err := tx.SignatureHash()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := hashes[hash]; ok {
+			t.Fatal("duplicate signature hash:", hash.String())
+		}
+		hashes[hash] = struct{}{}
+	}
+	addHash(abtx.Transaction())
+	abtx.ExpiryHeight = 42000
+	addHash(abtx.Transaction())
+	abtx.DocumentHash = crypto.HashBytes([]byte("other court order"))
+	addHash(abtx.Transaction())
+	abtx.Address = testAddress(t, "017fda17489854109399aa8c1bfa6bdef40f93606744d95cc5055270d78b465e6acd263c96ab2b")
+	addHash(abtx.Transaction())
+}
+
+func TestNewAddressBlacklistStatus(t *testing.T) {
+	address := testAddress(t, "01b49da2ff193f46ee0fc684d7a6121a8b8e324144dffc7327471a4da79f1730960edcb2ce737f")
+	blacklist := func(height, expiry types.BlockHeight) BlacklistEvent {
+		return BlacklistEvent{Action: BlacklistActionBlacklist, BlockHeight: height, ExpiryHeight: expiry}
+	}
+	lift := func(height types.BlockHeight) BlacklistEvent {
+		return BlacklistEvent{Action: BlacklistActionLift, BlockHeight: height}
+	}
+	testCases := []struct {
+		History     []BlacklistEvent
+		Height      types.BlockHeight
+		Blacklisted bool
+	}{
+		{nil, 10, false},
+		{[]BlacklistEvent{blacklist(5, 0)}, 10, true},
+		{[]BlacklistEvent{blacklist(5, 20)}, 10, true},
+		{[]BlacklistEvent{blacklist(5, 20)}, 20, false},
+		{[]BlacklistEvent{blacklist(5, 0), lift(8)}, 10, false},
+		{[]BlacklistEvent{blacklist(5, 0), lift(8), blacklist(9, 0)}, 10, true},
+		{[]BlacklistEvent{blacklist(5, 7), blacklist(9, 11)}, 12, false},
+	}
+	for idx, testCase := range testCases {
+		status := NewAddressBlacklistStatus(address, testCase.Height, testCase.History)
+		if status.Blacklisted != testCase.Blacklisted {
+			t.Errorf("test case #%d: unexpected blacklist status: %v", idx, status.Blacklisted)
+		}
+		if len(status.History) != len(testCase.History) {
+			t.Errorf("test case #%d: unexpected history length: %d", idx, len(status.History))
+		}
+	}
+}
+
+func testTransactionEncodingAndID(t *testing.T, tx types.Transaction) {
+	id := tx.ID()
+
+	b, err := json.Marshal(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var jsonTx types.Transaction
+	err = json.Unmarshal(b, &jsonTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if oID := jsonTx.ID(); id != oID {
+		t.Fatal("JSON:", id, "!=", oID)
+	}
+
+	b, err = rivbin.Marshal(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var binTx types.Transaction
+	err = rivbin.Unmarshal(b, &binTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if oID := binTx.ID(); id != oID {
+		t.Fatal("binary:", id, "!=", oID)
+	}
+	ob, err := rivbin.Marshal(binTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, ob) {
+		t.Fatal(hex.EncodeToString(b), "!=", hex.EncodeToString(ob))
+	}
+}
+
+func testAddress(t *testing.T, str string) (uh types.UnlockHash) {
+	err := uh.LoadString(str)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return
+}
+
+func testMintFulfillment() types.UnlockFulfillmentProxy {
+	return types.NewFulfillment(&types.SingleSignatureFulfillment{
+		PublicKey: types.PublicKey{
+			Algorithm: types.SignatureAlgoEd25519,
+			Key:       make([]byte, crypto.PublicKeySize),
+		},
+		Signature: make([]byte, crypto.SignatureSize),
+	})
+}
@@ -0,0 +1,53 @@
+package recovery
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/threefoldtech/rivine/types"
+
+	rtypes "github.com/threefoldfoundation/tfchain/extensions/recovery/types"
+)
+
+// validateFreezeAddress validates that the given address is an address which can be frozen,
+// only personal (public key) and multisig wallet addresses can be frozen.
+func validateFreezeAddress(address types.UnlockHash) error {
+	switch address.Type {
+	case types.UnlockTypePubKey, types.UnlockTypeMultiSig:
+		return nil
+	case types.UnlockTypeNil:
+		return errors.New("nil address cannot be frozen")
+	default:
+		return fmt.Errorf("address %s of unlock type %d cannot be frozen", address.String(), address.Type)
+	}
+}
+
+// validateBlacklistExpiryHeight validates that the given expiry height of a blacklisting
+// is either undefined (0) or still to come at the given block height.
+func validateBlacklistExpiryHeight(expiryHeight, height types.BlockHeight) error {
+	if expiryHeight != 0 && expiryHeight <= height {
+		return fmt.Errorf("blacklist expiry height %d has to be higher than the current block height %d", expiryHeight, height)
+	}
+	return nil
+}
+
+// validateRecoveredValue validates that the value recovered by the given coin recovery transaction
+// equals the given unspent balance of the given frozen address,
+// and that none of the recovered coins are sent back to the frozen address.
+func validateRecoveredValue(crtx rtypes.CoinRecoveryTransaction, address types.UnlockHash, balance types.Currency) error {
+	if balance.IsZero() {
+		return fmt.Errorf("frozen address %s has no unspent coins to recover", address.String())
+	}
+	for _, co := range crtx.CoinOutputs {
+		if co.Condition.UnlockHash() == address {
+			return fmt.Errorf("coins cannot be recovered to the frozen address %s", address.String())
+		}
+	}
+	recovered := crtx.RecoveredValue()
+	if !recovered.Equals(balance) {
+		return fmt.Errorf(
+			"recovered value %s (coin outputs and miner fees) does not equal the unspent balance %s of frozen address %s",
+			recovered.String(), balance.String(), address.String())
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package recovery
+
+import (
+	"testing"
+
+	"github.com/threefoldtech/rivine/types"
+
+	rtypes "github.com/threefoldfoundation/tfchain/extensions/recovery/types"
+)
+
+func TestValidateFreezeAddress(t *testing.T) {
+	testCases := []struct {
+		Type  types.UnlockType
+		Valid bool
+	}{
+		{types.UnlockTypeNil, false},
+		{types.UnlockTypePubKey, true},
+		{types.UnlockTypeAtomicSwap, false},
+		{types.UnlockTypeMultiSig, true},
+	}
+	for idx, testCase := range testCases {
+		err := validateFreezeAddress(types.UnlockHash{Type: testCase.Type})
+		if testCase.Valid && err != nil {
+			t.Errorf("test case #%d: unexpected error: %v", idx, err)
+		} else if !testCase.Valid && err == nil {
+			t.Errorf("test case #%d: expected error, but none received", idx)
+		}
+	}
+}
+
+func TestValidateBlacklistExpiryHeight(t *testing.T) {
+	testCases := []struct {
+		ExpiryHeight types.BlockHeight
+		Height       types.BlockHeight
+		Valid        bool
+	}{
+		{0, 0, true},
+		{0, 100, true},
+		{101, 100, true},
+		{100, 100, false},
+		{99, 100, false},
+	}
+	for idx, testCase := range testCases {
+		err := validateBlacklistExpiryHeight(testCase.ExpiryHeight, testCase.Height)
+		if testCase.Valid && err != nil {
+			t.Errorf("test case #%d: unexpected error: %v", idx, err)
+		} else if !testCase.Valid && err == nil {
+			t.Errorf("test case #%d: expected error, but none received", idx)
+		}
+	}
+}
+
+func TestValidateRecoveredValue(t *testing.T) {
+	frozen := types.UnlockHash{Type: types.UnlockTypePubKey}
+	frozen.Hash[0] = 1
+	dest := types.UnlockHash{Type: types.UnlockTypePubKey}
+	dest.Hash[0] = 2
+
+	newCrtx := func(to types.UnlockHash, value, fee uint64) rtypes.CoinRecoveryTransaction {
+		return rtypes.CoinRecoveryTransaction{
+			CoinOutputs: []types.CoinOutput{
+				{
+					Value:     types.NewCurrency64(value),
+					Condition: types.NewCondition(types.NewUnlockHashCondition(to)),
+				},
+			},
+			MinerFees: []types.Currency{types.NewCurrency64(fee)},
+		}
+	}
+	testCases := []struct {
+		Transaction rtypes.CoinRecoveryTransaction
+		Balance     uint64
+		Valid       bool
+	}{
+		{newCrtx(dest, 90, 10), 100, true},
+		{newCrtx(dest, 100, 10), 100, false},
+		{newCrtx(dest, 80, 10), 100, false},
+		{newCrtx(frozen, 90, 10), 100, false},
+		{newCrtx(dest, 0, 0), 0, false},
+	}
+	for idx, testCase := range testCases {
+		err := validateRecoveredValue(testCase.Transaction, frozen, types.NewCurrency64(testCase.Balance))
+		if testCase.Valid && err != nil {
+			t.Errorf("test case #%d: unexpected error: %v", idx, err)
+		} else if !testCase.Valid && err == nil {
+			t.Errorf("test case #%d: expected error, but none received", idx)
+		}
+	}
+}
@@ -4,6 +4,10 @@ import (
 	"github.com/threefoldfoundation/tfchain/pkg/config"
 	tftypes "github.com/threefoldfoundation/tfchain/pkg/types"
 
+	capacitycli "github.com/threefoldfoundation/tfchain/extensions/capacity/client"
+	ctypes "github.com/threefoldfoundation/tfchain/extensions/capacity/types"
+	rtypes "github.com/threefoldfoundation/tfchain/extensions/recovery/types"
+	tfconsensus "github.com/threefoldfoundation/tfchain/extensions/tfchain/consensus"
 	tbcli "github.com/threefoldfoundation/tfchain/extensions/threebot/client"
 	tbtypes "github.com/threefoldfoundation/tfchain/extensions/threebot/types"
 	erc20cli "github.com/threefoldtech/rivine-extension-erc20/client"
@@ -17,18 +21,26 @@ import (
 )
 
 func RegisterStandardTransactions(bc client.BaseClient) error {
-	return registerTransactions(bc, false, config.GetStandardDaemonNetworkConfig())
+	return registerTransactions(bc, tfconsensus.GetStandardForkSchedule(), config.GetStandardDaemonNetworkConfig())
 }
 
 func RegisterTestnetTransactions(bc client.BaseClient) error {
-	return registerTransactions(bc, true, config.GetTestnetDaemonNetworkConfig())
+	return registerTransactions(bc, tfconsensus.GetTestnetForkSchedule(), config.GetTestnetDaemonNetworkConfig())
 }
 
 func RegisterDevnetTransactions(bc client.BaseClient) error {
-	return registerTransactions(bc, true, config.GetDevnetDaemonNetworkConfig())
+	return registerTransactions(bc, tfconsensus.GetDevnetForkSchedule(), config.GetDevnetDaemonNetworkConfig())
 }
 
-func registerTransactions(bc client.BaseClient, extraPlugins bool, daemonCfg config.DaemonNetworkConfig) error {
+// RegisterCustomTransactions registers the transactions of a custom network,
+// using the activation heights and daemon network config of its network definition.
+func RegisterCustomTransactions(bc client.BaseClient, heights config.NetworkActivationHeights, daemonCfg config.DaemonNetworkConfig) error {
+	return registerTransactions(bc, tfconsensus.GetCustomForkSchedule(heights), daemonCfg)
+}
+
+// registerTransactions registers the transactions of all plugins scheduled
+// in the given fork schedule, even if they aren't active yet.
+func registerTransactions(bc client.BaseClient, schedule tfconsensus.ForkSchedule, daemonCfg config.DaemonNetworkConfig) error {
 	// create minting plugin client...
 	mintingCLI := mintingcli.NewPluginConsensusClient(bc)
 	// ...and register minting types
@@ -71,16 +83,32 @@ func registerTransactions(bc client.BaseClient, extraPlugins bool, daemonCfg con
 		TransactionVersion: tftypes.TransactionVersionAuthAddressUpdate,
 	})
 
-	if !extraPlugins {
-		return nil // 3Bot and ERC20 transactions are not enabled on all networks
-	}
-
 	cfg, err := bc.Config()
 	if err != nil {
 		return err
 	}
 
-	// register 3Bot Transactions
+	// 3Bot, ERC20, recovery and capacity transactions are not enabled on all networks
+	if schedule.IsScheduled(tfconsensus.FeatureThreeBot) {
+		registerThreeBotTransactions(bc, cfg, daemonCfg)
+	}
+	if schedule.IsScheduled(tfconsensus.FeatureERC20) {
+		registerERC20Transactions(bc, cfg, daemonCfg)
+	}
+	if schedule.IsScheduled(tfconsensus.FeatureRecovery) {
+		registerRecoveryTransactions(mintingCLI)
+	}
+	if schedule.IsScheduled(tfconsensus.FeatureCapacity) {
+		registerCapacityTransactions(bc, mintingCLI)
+	}
+	// the vesting condition is only known on the networks on which it is scheduled
+	if schedule.IsScheduled(tfconsensus.FeatureVesting) {
+		tftypes.RegisterVestingConditionType()
+	}
+	return nil
+}
+
+func registerThreeBotTransactions(bc client.BaseClient, cfg *client.Config, daemonCfg config.DaemonNetworkConfig) {
 	tbClient := tbcli.NewPluginConsensusClient(bc)
 	types.RegisterTransactionVersion(tbtypes.TransactionVersionBotRegistration, tbtypes.BotRegistrationTransactionController{
 		Registry:            tbClient,
@@ -98,7 +126,9 @@ func registerTransactions(bc client.BaseClient, extraPlugins bool, daemonCfg con
 		OneCoin:             cfg.CurrencyUnits.OneCoin,
 	})
 
-	// register ERC20 Transactions
+}
+
+func registerERC20Transactions(bc client.BaseClient, cfg *client.Config, daemonCfg config.DaemonNetworkConfig) {
 	erc20Client := erc20cli.NewPluginConsensusClient(bc)
 	types.RegisterTransactionVersion(tftypes.TransactionVersionERC20Conversion, erc20types.ERC20ConvertTransactionController{
 		TransactionVersion: tftypes.TransactionVersionERC20Conversion,
@@ -115,5 +145,35 @@ func registerTransactions(bc client.BaseClient, extraPlugins bool, daemonCfg con
 		OneCoin:            cfg.CurrencyUnits.OneCoin,
 	})
 
-	return nil
+}
+
+func registerRecoveryTransactions(mintingCLI *mintingcli.PluginClient) {
+	types.RegisterTransactionVersion(rtypes.TransactionVersionAddressFreeze, rtypes.AddressFreezeTransactionController{
+		MintConditionGetter: mintingCLI,
+	})
+	types.RegisterTransactionVersion(rtypes.TransactionVersionCoinRecovery, rtypes.CoinRecoveryTransactionController{
+		MintConditionGetter: mintingCLI,
+	})
+	types.RegisterTransactionVersion(rtypes.TransactionVersionAddressBlacklist, rtypes.AddressBlacklistTransactionController{
+		MintConditionGetter: mintingCLI,
+	})
+	types.RegisterTransactionVersion(rtypes.TransactionVersionAddressBlacklistLift, rtypes.AddressBlacklistLiftTransactionController{
+		MintConditionGetter: mintingCLI,
+	})
+
+}
+
+func registerCapacityTransactions(bc client.BaseClient, mintingCLI *mintingcli.PluginClient) {
+	types.RegisterTransactionVersion(ctypes.TransactionVersionFarmerAuthorization, ctypes.FarmerAuthorizationTransactionController{
+		MintConditionGetter: mintingCLI,
+	})
+	types.RegisterTransactionVersion(ctypes.TransactionVersionCapacityRegistration, ctypes.CapacityRegistrationTransactionController{})
+	types.RegisterTransactionVersion(ctypes.TransactionVersionFarmRegistration, ctypes.FarmRegistrationTransactionController{})
+	types.RegisterTransactionVersion(ctypes.TransactionVersionFarmUpdate, ctypes.FarmUpdateTransactionController{
+		Registry: capacitycli.NewPluginConsensusClient(bc),
+	})
+	types.RegisterTransactionVersion(ctypes.TransactionVersionNodeLink, ctypes.NodeLinkTransactionController{})
+	types.RegisterTransactionVersion(ctypes.TransactionVersionFarmingReward, ctypes.FarmingRewardTransactionController{
+		MintConditionGetter: mintingCLI,
+	})
 }
@@ -0,0 +1,45 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/threefoldfoundation/tfchain/pkg/api"
+	"github.com/threefoldtech/rivine/pkg/client"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// VestingClient is used to be able to get vesting information from
+// a daemon that has the vesting feature scheduled.
+type VestingClient struct {
+	bc client.BaseClient
+}
+
+// NewVestingClient creates a new VestingClient,
+// that can be used for easy interaction with the vesting API.
+func NewVestingClient(bc client.BaseClient) *VestingClient {
+	if bc == nil {
+		panic("no BaseClient given")
+	}
+	return &VestingClient{bc: bc}
+}
+
+// GetVestingOutput returns the vested and locked amounts of the given unspent vesting coin output.
+func (client *VestingClient) GetVestingOutput(id types.CoinOutputID) (*api.ConsensusVestingGET, error) {
+	var result api.ConsensusVestingGET
+	err := client.bc.HTTP().GetWithResponse("/consensus/vesting/"+id.String(), &result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vesting output %s from daemon: %v", id.String(), err)
+	}
+	return &result, nil
+}
+
+// GetAddressVesting returns all unspent vesting coin outputs of the given address,
+// as well as the total vested and locked amounts.
+func (client *VestingClient) GetAddressVesting(address types.UnlockHash) (*api.ExplorerVestingGET, error) {
+	var result api.ExplorerVestingGET
+	err := client.bc.HTTP().GetWithResponse("/explorer/vesting/"+address.String(), &result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vesting outputs of address %s from explorer: %v", address.String(), err)
+	}
+	return &result, nil
+}
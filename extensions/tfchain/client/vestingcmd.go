@@ -0,0 +1,291 @@
+package client
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	tftypes "github.com/threefoldfoundation/tfchain/pkg/types"
+	"github.com/threefoldtech/rivine/pkg/api"
+	"github.com/threefoldtech/rivine/pkg/cli"
+	"github.com/threefoldtech/rivine/pkg/client"
+	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
+	"github.com/threefoldtech/rivine/types"
+
+	"github.com/spf13/cobra"
+)
+
+// CreateVestingCmds adds the consensus, explore and wallet cli subcommands
+// used to create, inspect and claim vesting outputs.
+func CreateVestingCmds(ccli *client.CommandLineClient) error {
+	bc, err := client.NewLazyBaseClientFromCommandLineClient(ccli)
+	if err != nil {
+		return err
+	}
+	vestingCmd := &vestingCmd{
+		cli:     ccli,
+		vClient: NewVestingClient(bc),
+	}
+
+	// define commands
+	var (
+		getVestingOutputCmd = &cobra.Command{
+			Use:   "vesting <outputid>",
+			Short: "Get the vested and locked amounts of an unspent vesting output",
+			Args:  cobra.ExactArgs(1),
+			Run:   vestingCmd.getVestingOutputCmd,
+		}
+		getAddressVestingCmd = &cobra.Command{
+			Use:   "vesting <address>",
+			Short: "Get all unspent vesting outputs of an address",
+			Long: `Get all unspent vesting outputs of an address,
+as well as the total amount that is vested (spendable) and the total amount that is still locked.
+`,
+			Args: cobra.ExactArgs(1),
+			Run:  vestingCmd.getAddressVestingCmd,
+		}
+		sendVestingCmd = &cobra.Command{
+			Use:   "vesting <owner> <amount> <start> <end>",
+			Short: "Send coins locked by a vesting schedule",
+			Long: `Send coins to the given owner (address), locked by a vesting schedule,
+releasing the coins gradually between start and end. Start and end are block heights
+if lower than 500000000, otherwise they are Unix Epoch timestamps (in seconds).
+
+By default the coins are released linearly, use the --steps flag to release them
+in a fixed amount of equal steps instead.
+`,
+			Args: cobra.ExactArgs(4),
+			Run:  vestingCmd.sendVestingCmd,
+		}
+		createVestingClaimTxCmd = &cobra.Command{
+			Use:   "vestingclaimtransaction <outputid> <dest>|<rawCondition>",
+			Short: "Create a new transaction claiming the vested coins of a vesting output",
+			Long: `Create a new transaction claiming the vested coins of the given vesting output,
+sending them to the given output condition (or address, which resolves to a singlesignature condition).
+The coins which are still locked are re-locked in a new output using the same vesting condition.
+
+The Minimum Miner Fee is paid from the claimed coins.
+
+The returned (raw) transaction still has to be signed, prior to sending.
+`,
+			Args: cobra.ExactArgs(2),
+			Run:  vestingCmd.createVestingClaimTxCmd,
+		}
+	)
+
+	// add commands as sub commands
+	ccli.ConsensusCmd.AddCommand(getVestingOutputCmd)
+	ccli.ExploreCmd.AddCommand(getAddressVestingCmd)
+	ccli.WalletCmd.RootCmdSend.AddCommand(sendVestingCmd)
+	ccli.WalletCmd.RootCmdCreate.AddCommand(createVestingClaimTxCmd)
+
+	// register flags
+	getVestingOutputCmd.Flags().Var(
+		cli.NewEncodingTypeFlag(0, &vestingCmd.getVestingOutputCfg.EncodingType, 0), "encoding",
+		cli.EncodingTypeFlagDescription(0))
+	getAddressVestingCmd.Flags().Var(
+		cli.NewEncodingTypeFlag(0, &vestingCmd.getAddressVestingCfg.EncodingType, 0), "encoding",
+		cli.EncodingTypeFlagDescription(0))
+	sendVestingCmd.Flags().Uint64Var(&vestingCmd.sendVestingCfg.Steps,
+		"steps", 0, "optionally release the coins in the given amount of equal steps, rather than linearly")
+	cli.ArbitraryDataFlagVar(sendVestingCmd.Flags(), &vestingCmd.sendVestingCfg.Data,
+		"data", "optional arbitrary data (or description) to attach to the transaction")
+
+	return nil
+}
+
+type vestingCmd struct {
+	cli     *client.CommandLineClient
+	vClient *VestingClient
+
+	getVestingOutputCfg struct {
+		EncodingType cli.EncodingType
+	}
+	getAddressVestingCfg struct {
+		EncodingType cli.EncodingType
+	}
+	sendVestingCfg struct {
+		Steps uint64
+		Data  []byte
+	}
+}
+
+func (vestingCmd *vestingCmd) getVestingOutputCmd(cmd *cobra.Command, args []string) {
+	var id types.CoinOutputID
+	err := id.LoadString(args[0])
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.DieWithError("invalid coin output id", err)
+	}
+	result, err := vestingCmd.vClient.GetVestingOutput(id)
+	if err != nil {
+		cli.DieWithError("error while fetching the vesting output", err)
+	}
+	err = encodeResult(result, vestingCmd.getVestingOutputCfg.EncodingType)
+	if err != nil {
+		cli.DieWithError("failed to encode vesting output", err)
+	}
+}
+
+func (vestingCmd *vestingCmd) getAddressVestingCmd(cmd *cobra.Command, args []string) {
+	var address types.UnlockHash
+	err := address.LoadString(args[0])
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.DieWithError("invalid address", err)
+	}
+	result, err := vestingCmd.vClient.GetAddressVesting(address)
+	if err != nil {
+		cli.DieWithError("error while fetching the vesting outputs", err)
+	}
+	err = encodeResult(result, vestingCmd.getAddressVestingCfg.EncodingType)
+	if err != nil {
+		cli.DieWithError("failed to encode vesting outputs", err)
+	}
+}
+
+func (vestingCmd *vestingCmd) sendVestingCmd(cmd *cobra.Command, args []string) {
+	var owner types.UnlockHash
+	err := owner.LoadString(args[0])
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.DieWithError("invalid owner address", err)
+	}
+	currencyConvertor := vestingCmd.cli.CreateCurrencyConvertor()
+	amount, err := currencyConvertor.ParseCoinString(args[1])
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.DieWithError("invalid amount", err)
+	}
+	start, err := strconv.ParseUint(args[2], 10, 64)
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.DieWithError("invalid vesting start", err)
+	}
+	end, err := strconv.ParseUint(args[3], 10, 64)
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.DieWithError("invalid vesting end", err)
+	}
+
+	condition := tftypes.NewVestingCondition(start, end, vestingCmd.sendVestingCfg.Steps, amount, types.NewUnlockHashCondition(owner))
+	if err = condition.IsStandardCondition(types.ValidationContext{}); err != nil {
+		cli.DieWithError("invalid vesting condition", err)
+	}
+
+	body := api.WalletCoinsPOST{
+		CoinOutputs: []types.CoinOutput{
+			{
+				Value:     amount,
+				Condition: types.NewCondition(condition),
+			},
+		},
+		Data: vestingCmd.sendVestingCfg.Data,
+	}
+	b, err := json.Marshal(&body)
+	if err != nil {
+		cli.DieWithError("failed to JSON Marshal the input body", err)
+	}
+	var resp api.WalletCoinsPOSTResp
+	err = vestingCmd.cli.PostWithResponse("/wallet/coins", string(b), &resp)
+	if err != nil {
+		cli.DieWithError("could not send vesting coins", err)
+	}
+	fmt.Println("Succesfully sent vesting coins as transaction " + resp.TransactionID.String())
+	fmt.Printf("Sent %s to %s, vesting from %d until %d\n",
+		currencyConvertor.ToCoinStringWithUnit(amount), owner.String(), start, end)
+}
+
+func (vestingCmd *vestingCmd) createVestingClaimTxCmd(cmd *cobra.Command, args []string) {
+	var id types.CoinOutputID
+	err := id.LoadString(args[0])
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.DieWithError("invalid coin output id", err)
+	}
+	condition, err := parseConditionString(args[1])
+	if err != nil {
+		cmd.UsageFunc()(cmd)
+		cli.Die(err)
+	}
+	result, err := vestingCmd.vClient.GetVestingOutput(id)
+	if err != nil {
+		cli.DieWithError("error while fetching the vesting output", err)
+	}
+	fee := vestingCmd.cli.Config.MinimumTransactionFee
+	if result.Output.Vested.Cmp(fee) <= 0 {
+		cli.Die(fmt.Sprintf("vested amount %s of output %s is too low to be claimed",
+			result.Output.Vested.String(), id.String()))
+	}
+
+	// claim the vested coins minus the minimum required miner fee,
+	// and re-lock the remainder using the same vesting condition
+	tx := types.Transaction{
+		Version: types.TransactionVersionOne,
+		CoinInputs: []types.CoinInput{
+			{ParentID: id},
+		},
+		CoinOutputs: []types.CoinOutput{
+			{
+				Value:     result.Output.Vested.Sub(fee),
+				Condition: condition,
+			},
+		},
+		MinerFees: []types.Currency{fee},
+	}
+	if !result.Output.Locked.IsZero() {
+		tx.CoinOutputs = append(tx.CoinOutputs, types.CoinOutput{
+			Value:     result.Output.Locked,
+			Condition: result.Output.Condition,
+		})
+	}
+
+	// encode the transaction as a JSON-encoded string and print it to the STDOUT
+	err = json.NewEncoder(os.Stdout).Encode(tx)
+	if err != nil {
+		cli.DieWithError("failed to encode vesting claim transaction", err)
+	}
+}
+
+func parseConditionString(str string) (condition types.UnlockConditionProxy, err error) {
+	// try to parse it as an unlock hash
+	var uh types.UnlockHash
+	err = uh.LoadString(str)
+	if err == nil {
+		// parsing as an unlock hash was succesfull
+		condition = types.NewCondition(types.NewUnlockHashCondition(uh))
+		return
+	}
+
+	// try to parse it as a JSON-encoded unlock condition
+	err = condition.UnmarshalJSON([]byte(str))
+	if err != nil {
+		return types.UnlockConditionProxy{}, fmt.Errorf(
+			"condition has to be UnlockHash or JSON-encoded UnlockCondition, output %q is neither", str)
+	}
+	return
+}
+
+func encodeResult(result interface{}, encodingType cli.EncodingType) error {
+	var encode func(interface{}) error
+	switch encodingType {
+	case cli.EncodingTypeHuman:
+		e := json.NewEncoder(os.Stdout)
+		e.SetIndent("", "  ")
+		encode = e.Encode
+	case cli.EncodingTypeJSON:
+		encode = json.NewEncoder(os.Stdout).Encode
+	case cli.EncodingTypeHex:
+		encode = func(v interface{}) error {
+			b, err := siabin.Marshal(v)
+			if err != nil {
+				return err
+			}
+			fmt.Println(hex.EncodeToString(b))
+			return nil
+		}
+	}
+	return encode(result)
+}
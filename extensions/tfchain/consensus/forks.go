@@ -0,0 +1,266 @@
+package consensus
+
+import (
+	"fmt"
+
+	capacitytypes "github.com/threefoldfoundation/tfchain/extensions/capacity/types"
+	recoverytypes "github.com/threefoldfoundation/tfchain/extensions/recovery/types"
+	tbtypes "github.com/threefoldfoundation/tfchain/extensions/threebot/types"
+	"github.com/threefoldfoundation/tfchain/pkg/config"
+	tftypes "github.com/threefoldfoundation/tfchain/pkg/types"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/modules/consensus"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// Feature is the name of a feature added during the lifetime of tfchain,
+// which is activated on a network since a specific block height.
+type Feature string
+
+// All features that can be scheduled in a ForkSchedule.
+const (
+	// FeatureMinerFeesRequired requires v0 and v1 transactions
+	// to pay at least the minimum miner fee.
+	FeatureMinerFeesRequired Feature = "minerfeesrequired"
+	// FeatureMinerFeesEnforced requires all transactions,
+	// regardless of their version, to pay at least the minimum miner fee.
+	FeatureMinerFeesEnforced Feature = "minerfeesenforced"
+	// FeatureMinerFeesPerSize requires all transactions to pay
+	// the minimum miner fee for every started kilobyte of their (encoded) size.
+	FeatureMinerFeesPerSize Feature = "minerfeespersize"
+	// FeatureLegacyTransactionsDisabled no longer allows (v0) legacy transactions.
+	FeatureLegacyTransactionsDisabled Feature = "legacytransactionsdisabled"
+	// FeatureThreeBotDoubleRegistrationsForbidden no longer allows
+	// a 3Bot name or public key to be registered more than once.
+	FeatureThreeBotDoubleRegistrationsForbidden Feature = "threebotdoubleregistrationsforbidden"
+
+	// FeatureThreeBot enables the 3Bot plugin and its transactions.
+	FeatureThreeBot Feature = "threebot"
+	// FeatureERC20 enables the ERC20 plugin and its transactions.
+	FeatureERC20 Feature = "erc20"
+	// FeatureRecovery enables the recovery plugin and its transactions.
+	FeatureRecovery Feature = "recovery"
+	// FeatureCapacity enables the capacity plugin and its transactions.
+	FeatureCapacity Feature = "capacity"
+
+	// FeatureVesting allows coin outputs to use the vesting condition,
+	// releasing their value gradually over a range of block heights or timestamps.
+	FeatureVesting Feature = "vesting"
+)
+
+// pluginFeatureTransactionVersions maps the plugin features
+// to the transaction versions they introduce.
+var pluginFeatureTransactionVersions = map[Feature][]types.TransactionVersion{
+	FeatureThreeBot: {
+		tbtypes.TransactionVersionBotRegistration,
+		tbtypes.TransactionVersionBotRecordUpdate,
+		tbtypes.TransactionVersionBotNameTransfer,
+	},
+	FeatureERC20: {
+		tftypes.TransactionVersionERC20Conversion,
+		tftypes.TransactionVersionERC20CoinCreation,
+		tftypes.TransactionVersionERC20AddressRegistration,
+	},
+	FeatureRecovery: {
+		recoverytypes.TransactionVersionAddressFreeze,
+		recoverytypes.TransactionVersionCoinRecovery,
+		recoverytypes.TransactionVersionAddressBlacklist,
+		recoverytypes.TransactionVersionAddressBlacklistLift,
+	},
+	FeatureCapacity: {
+		capacitytypes.TransactionVersionFarmerAuthorization,
+		capacitytypes.TransactionVersionCapacityRegistration,
+		capacitytypes.TransactionVersionFarmRegistration,
+		capacitytypes.TransactionVersionFarmUpdate,
+		capacitytypes.TransactionVersionNodeLink,
+		capacitytypes.TransactionVersionFarmingReward,
+	},
+}
+
+// ForkSchedule maps features to the block height since which they are active on a network.
+// A feature which isn't part of the schedule is never activated on that network.
+type ForkSchedule map[Feature]types.BlockHeight
+
+// GetStandardForkSchedule returns the fork schedule of the standard network.
+func GetStandardForkSchedule() ForkSchedule {
+	return ForkSchedule{
+		FeatureMinerFeesRequired:                    300000,
+		FeatureLegacyTransactionsDisabled:           385000,
+		FeatureThreeBotDoubleRegistrationsForbidden: 0,
+		// 3Bot and ERC20 transactions are only allowed since this height on the standard network
+		FeatureThreeBot: 500000,
+		FeatureERC20:    500000,
+	}
+}
+
+// GetTestnetForkSchedule returns the fork schedule of the testnet network.
+func GetTestnetForkSchedule() ForkSchedule {
+	return ForkSchedule{
+		FeatureMinerFeesRequired:                    300000,
+		FeatureLegacyTransactionsDisabled:           385000,
+		FeatureThreeBotDoubleRegistrationsForbidden: 350000,
+		FeatureThreeBot:                             0,
+		FeatureERC20:                                0,
+		// recovery and capacity transactions, as well as vesting conditions,
+		// are only allowed since this height on the testnet network
+		FeatureRecovery: 600000,
+		FeatureCapacity: 600000,
+		FeatureVesting:  600000,
+	}
+}
+
+// GetDevnetForkSchedule returns the fork schedule of the devnet network.
+func GetDevnetForkSchedule() ForkSchedule {
+	return ForkSchedule{
+		FeatureMinerFeesEnforced:                    0,
+		FeatureMinerFeesPerSize:                     0,
+		FeatureLegacyTransactionsDisabled:           0,
+		FeatureThreeBotDoubleRegistrationsForbidden: 0,
+		FeatureThreeBot:                             0,
+		FeatureERC20:                                0,
+		FeatureRecovery:                             0,
+		FeatureCapacity:                             0,
+		FeatureVesting:                              0,
+	}
+}
+
+// GetCustomForkSchedule returns the fork schedule of a custom network,
+// using the activation heights defined in its network definition.
+func GetCustomForkSchedule(heights config.NetworkActivationHeights) ForkSchedule {
+	schedule := ForkSchedule{
+		FeatureMinerFeesRequired:                    heights.MinerFeesRequired,
+		FeatureMinerFeesEnforced:                    0,
+		FeatureLegacyTransactionsDisabled:           heights.LegacyTransactionsDisabled,
+		FeatureThreeBotDoubleRegistrationsForbidden: 0,
+		FeatureThreeBot:                             0,
+		FeatureERC20:                                0,
+		FeatureRecovery:                             0,
+		FeatureCapacity:                             0,
+	}
+	if heights.MinerFeesPerSize != nil {
+		schedule[FeatureMinerFeesPerSize] = *heights.MinerFeesPerSize
+	}
+	if heights.Vesting != nil {
+		schedule[FeatureVesting] = *heights.Vesting
+	}
+	return schedule
+}
+
+// ActivationHeight returns the block height since which the given feature is active,
+// false is returned in case the feature is never activated.
+func (schedule ForkSchedule) ActivationHeight(feature Feature) (types.BlockHeight, bool) {
+	height, ok := schedule[feature]
+	return height, ok
+}
+
+// IsScheduled returns true if the given feature is activated at some (block) height.
+func (schedule ForkSchedule) IsScheduled(feature Feature) bool {
+	_, ok := schedule[feature]
+	return ok
+}
+
+// IsActive returns true if the given feature is active at the given block height.
+func (schedule ForkSchedule) IsActive(feature Feature, height types.BlockHeight) bool {
+	activationHeight, ok := schedule[feature]
+	return ok && height >= activationHeight
+}
+
+// TransactionValidators returns the validators which apply to all transactions,
+// regardless of their version, as defined by this fork schedule.
+func (schedule ForkSchedule) TransactionValidators() []modules.TransactionValidationFunction {
+	validators := []modules.TransactionValidationFunction{
+		consensus.ValidateTransactionFitsInABlock,
+		consensus.ValidateTransactionArbitraryData,
+		consensus.ValidateCoinInputsAreValid,
+		consensus.ValidateCoinOutputsAreValid,
+		consensus.ValidateBlockStakeInputsAreValid,
+		consensus.ValidateBlockStakeOutputsAreValid,
+	}
+	if height, ok := schedule.ActivationHeight(FeatureMinerFeesEnforced); ok {
+		validators = append(validators,
+			validateSinceBlockHeight(height, consensus.ValidateMinerFeeIsPresent),
+			validateSinceBlockHeight(height, consensus.ValidateMinerFeesAreValid))
+	}
+	if height, ok := schedule.ActivationHeight(FeatureMinerFeesPerSize); ok {
+		validator := &MinimumMinerFeePerSizeValidator{MinimumBlockHeight: height}
+		validators = append(validators, validator.Validate)
+	}
+	if height, ok := schedule.ActivationHeight(FeatureVesting); ok {
+		validator := &VestingConditionValidator{MinimumBlockHeight: height}
+		validators = append(validators, validator.Validate)
+	}
+	return append(validators,
+		consensus.ValidateDoubleCoinSpends,
+		consensus.ValidateDoubleBlockStakeSpends,
+		consensus.ValidateCoinInputsAreFulfilled,
+		consensus.ValidateBlockStakeInputsAreFulfilled,
+	)
+}
+
+// TransactionVersionMappedValidators returns the validators which apply
+// to transactions of a specific version, as defined by this fork schedule.
+func (schedule ForkSchedule) TransactionVersionMappedValidators() map[types.TransactionVersion][]modules.TransactionValidationFunction {
+	var v0Validators, v1Validators []modules.TransactionValidationFunction
+	if height, ok := schedule.ActivationHeight(FeatureMinerFeesRequired); ok {
+		validator := &MinimumMinerFeeValidator{MinimumBlockHeight: height}
+		v0Validators = append(v0Validators, validator.Validate)
+		v1Validators = append(v1Validators, validator.Validate)
+	}
+	if height, ok := schedule.ActivationHeight(FeatureLegacyTransactionsDisabled); ok {
+		legacyValidator := &DisableTransactionSinceValidator{MinimumBlockHeight: height}
+		v0Validators = append(v0Validators, legacyValidator.Validate)
+	}
+	mappedValidators := map[types.TransactionVersion][]modules.TransactionValidationFunction{
+		types.TransactionVersionZero: append(v0Validators,
+			consensus.ValidateCoinOutputsAreBalanced,
+			consensus.ValidateBlockStakeOutputsAreBalanced,
+		),
+		types.TransactionVersionOne: append(v1Validators,
+			consensus.ValidateCoinOutputsAreBalanced,
+			consensus.ValidateBlockStakeOutputsAreBalanced,
+		),
+	}
+	// transactions of plugins which are only activated at a later height
+	// are not allowed prior to that height
+	for feature, versions := range pluginFeatureTransactionVersions {
+		height, ok := schedule.ActivationHeight(feature)
+		if !ok || height == 0 {
+			continue
+		}
+		validator := &EnableTransactionSinceValidator{MinimumBlockHeight: height}
+		for _, version := range versions {
+			mappedValidators[version] = append(mappedValidators[version], validator.Validate)
+		}
+	}
+	return mappedValidators
+}
+
+// validateSinceBlockHeight wraps a validator function,
+// such that it only applies since the given block height.
+func validateSinceBlockHeight(height types.BlockHeight, fn modules.TransactionValidationFunction) modules.TransactionValidationFunction {
+	if height == 0 {
+		return fn
+	}
+	return func(tx modules.ConsensusTransaction, ctx types.TransactionValidationContext) error {
+		if ctx.BlockHeight < height {
+			// no need to check
+			return nil
+		}
+		return fn(tx, ctx)
+	}
+}
+
+// EnableTransactionSinceValidator is a validator which allows to
+// only allow a transaction since a specific block height
+type EnableTransactionSinceValidator struct {
+	MinimumBlockHeight types.BlockHeight
+}
+
+// Validate is a validator function that checks if the transaction is already allowed
+// in the current chain.
+func (validator *EnableTransactionSinceValidator) Validate(tx modules.ConsensusTransaction, ctx types.TransactionValidationContext) error {
+	if ctx.BlockHeight >= validator.MinimumBlockHeight {
+		return nil
+	}
+	return fmt.Errorf("transaction is not yet allowed at block height %d (allowed since %d)", ctx.BlockHeight, validator.MinimumBlockHeight)
+}
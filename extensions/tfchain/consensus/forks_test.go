@@ -0,0 +1,202 @@
+package consensus
+
+import (
+	"testing"
+
+	capacitytypes "github.com/threefoldfoundation/tfchain/extensions/capacity/types"
+	tbtypes "github.com/threefoldfoundation/tfchain/extensions/threebot/types"
+	"github.com/threefoldfoundation/tfchain/pkg/config"
+	tftypes "github.com/threefoldfoundation/tfchain/pkg/types"
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+func TestForkScheduleIsActive(t *testing.T) {
+	schedule := GetStandardForkSchedule()
+	testCases := []struct {
+		Feature  Feature
+		Height   types.BlockHeight
+		IsActive bool
+	}{
+		{FeatureMinerFeesRequired, 0, false},
+		{FeatureMinerFeesRequired, 299999, false},
+		{FeatureMinerFeesRequired, 300000, true},
+		{FeatureLegacyTransactionsDisabled, 384999, false},
+		{FeatureLegacyTransactionsDisabled, 385000, true},
+		{FeatureMinerFeesEnforced, 1000000, false},
+		{FeatureThreeBot, 499999, false},
+		{FeatureThreeBot, 500000, true},
+		{FeatureERC20, 499999, false},
+		{FeatureERC20, 500000, true},
+		{FeatureCapacity, 1000000, false},
+	}
+	for idx, testCase := range testCases {
+		if isActive := schedule.IsActive(testCase.Feature, testCase.Height); isActive != testCase.IsActive {
+			t.Errorf("#%d: expected %s to be active at %d: %v, but it is: %v",
+				idx, testCase.Feature, testCase.Height, testCase.IsActive, isActive)
+		}
+	}
+	for _, feature := range []Feature{FeatureRecovery, FeatureCapacity} {
+		if schedule.IsScheduled(feature) {
+			t.Errorf("plugin feature %s is not expected to be scheduled on the standard network", feature)
+		}
+	}
+	for _, feature := range []Feature{FeatureThreeBot, FeatureERC20, FeatureRecovery, FeatureCapacity} {
+		if !GetTestnetForkSchedule().IsScheduled(feature) {
+			t.Errorf("plugin feature %s is expected to be scheduled on the testnet network", feature)
+		}
+	}
+}
+
+func TestCustomForkSchedule(t *testing.T) {
+	schedule := GetCustomForkSchedule(config.NetworkActivationHeights{
+		MinerFeesRequired:          42,
+		LegacyTransactionsDisabled: 100,
+	})
+	if height, ok := schedule.ActivationHeight(FeatureMinerFeesRequired); !ok || height != 42 {
+		t.Errorf("unexpected miner fees required activation height: %d (%v)", height, ok)
+	}
+	if height, ok := schedule.ActivationHeight(FeatureLegacyTransactionsDisabled); !ok || height != 100 {
+		t.Errorf("unexpected legacy transactions disabled activation height: %d (%v)", height, ok)
+	}
+	if !schedule.IsActive(FeatureCapacity, 0) {
+		t.Error("capacity feature is expected to be active since genesis on a custom network")
+	}
+	for _, feature := range []Feature{FeatureMinerFeesPerSize, FeatureVesting} {
+		if schedule.IsScheduled(feature) {
+			t.Errorf("optional feature %s is not expected to be scheduled when its height isn't defined", feature)
+		}
+	}
+
+	vestingHeight := types.BlockHeight(1000)
+	schedule = GetCustomForkSchedule(config.NetworkActivationHeights{Vesting: &vestingHeight})
+	if height, ok := schedule.ActivationHeight(FeatureVesting); !ok || height != vestingHeight {
+		t.Errorf("unexpected vesting activation height: %d (%v)", height, ok)
+	}
+}
+
+func TestTestnetVestingActivation(t *testing.T) {
+	schedule := GetTestnetForkSchedule()
+	height, ok := schedule.ActivationHeight(FeatureVesting)
+	if !ok || height == 0 {
+		t.Fatal("vesting is expected to be activated at a later height on the testnet network")
+	}
+	owner := types.NewCondition(types.NewUnlockHashCondition(types.UnlockHash{
+		Type: types.UnlockTypePubKey,
+		Hash: crypto.Hash{1},
+	}))
+	tx := modules.ConsensusTransaction{
+		Transaction: types.Transaction{
+			Version: types.TransactionVersionOne,
+			CoinOutputs: []types.CoinOutput{{
+				Value:     types.NewCurrency64(1000),
+				Condition: types.NewCondition(tftypes.NewVestingCondition(uint64(height), uint64(height)+100, 0, types.NewCurrency64(1000), owner.Condition)),
+			}},
+		},
+	}
+	validators := schedule.TransactionValidators()
+	for _, testCase := range []struct {
+		Height  types.BlockHeight
+		IsValid bool
+	}{
+		{0, false},
+		{height - 1, false},
+		{height, true},
+	} {
+		err := validateUsingValidators(tx, testCase.Height, validators)
+		if testCase.IsValid && err != nil {
+			t.Errorf("height %d: unexpected error: %v", testCase.Height, err)
+		} else if !testCase.IsValid && err == nil {
+			t.Errorf("height %d: expected error, but none received", testCase.Height)
+		}
+	}
+}
+
+func TestForkSchedulePluginTransactionsActivation(t *testing.T) {
+	schedule := ForkSchedule{
+		FeatureThreeBot: 1000,
+		FeatureCapacity: 0,
+	}
+	mappedValidators := schedule.TransactionVersionMappedValidators()
+
+	if _, ok := mappedValidators[capacitytypes.TransactionVersionFarmRegistration]; ok {
+		t.Error("no validators expected for a plugin activated since genesis")
+	}
+	validators, ok := mappedValidators[tbtypes.TransactionVersionBotRegistration]
+	if !ok {
+		t.Fatal("validators expected for a plugin activated at a later height")
+	}
+	tx := modules.ConsensusTransaction{
+		Transaction: types.Transaction{Version: tbtypes.TransactionVersionBotRegistration},
+	}
+	for _, testCase := range []struct {
+		Height  types.BlockHeight
+		IsValid bool
+	}{
+		{0, false},
+		{999, false},
+		{1000, true},
+		{1001, true},
+	} {
+		err := validateUsingValidators(tx, testCase.Height, validators)
+		if testCase.IsValid && err != nil {
+			t.Errorf("height %d: unexpected error: %v", testCase.Height, err)
+		} else if !testCase.IsValid && err == nil {
+			t.Errorf("height %d: expected error, but none received", testCase.Height)
+		}
+	}
+}
+
+func TestStandardThreeBotAndERC20TransactionsActivation(t *testing.T) {
+	testPluginTransactionsActivation(t, "standard", GetStandardForkSchedule(), FeatureThreeBot, FeatureERC20)
+}
+
+func TestTestnetRecoveryAndCapacityTransactionsActivation(t *testing.T) {
+	testPluginTransactionsActivation(t, "testnet", GetTestnetForkSchedule(), FeatureRecovery, FeatureCapacity)
+}
+
+// testPluginTransactionsActivation ensures the transactions of the given plugin features
+// are rejected prior to the (non-genesis) activation height of those features
+func testPluginTransactionsActivation(t *testing.T, network string, schedule ForkSchedule, features ...Feature) {
+	mappedValidators := schedule.TransactionVersionMappedValidators()
+	for _, feature := range features {
+		height, ok := schedule.ActivationHeight(feature)
+		if !ok || height == 0 {
+			t.Fatalf("feature %s is expected to be activated at a later height on the %s network", feature, network)
+		}
+		for _, version := range pluginFeatureTransactionVersions[feature] {
+			tx := modules.ConsensusTransaction{
+				Transaction: types.Transaction{Version: version},
+			}
+			for _, testCase := range []struct {
+				Height  types.BlockHeight
+				IsValid bool
+			}{
+				{0, false},
+				{height - 1, false},
+				{height, true},
+				{height + 1, true},
+			} {
+				err := validateUsingValidators(tx, testCase.Height, mappedValidators[version])
+				if testCase.IsValid && err != nil {
+					t.Errorf("%s: tx version %d at height %d: unexpected error: %v", feature, version, testCase.Height, err)
+				} else if !testCase.IsValid && err == nil {
+					t.Errorf("%s: tx version %d at height %d: expected error, but none received", feature, version, testCase.Height)
+				}
+			}
+		}
+	}
+}
+
+func validateUsingValidators(tx modules.ConsensusTransaction, height types.BlockHeight, validators []modules.TransactionValidationFunction) error {
+	for _, validator := range validators {
+		err := validator(tx, types.TransactionValidationContext{
+			ValidationContext: types.ValidationContext{BlockHeight: height},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -3,112 +3,59 @@ package consensus
 import (
 	"fmt"
 
+	"github.com/threefoldfoundation/tfchain/pkg/config"
+	"github.com/threefoldfoundation/tfchain/pkg/feemarket"
+	tftypes "github.com/threefoldfoundation/tfchain/pkg/types"
 	"github.com/threefoldtech/rivine/modules"
-	"github.com/threefoldtech/rivine/modules/consensus"
 	"github.com/threefoldtech/rivine/types"
 )
 
+// GetStandardTransactionValidators returns the transaction validators of the standard network.
 func GetStandardTransactionValidators() []modules.TransactionValidationFunction {
-	return []modules.TransactionValidationFunction{
-		consensus.ValidateTransactionFitsInABlock,
-		consensus.ValidateTransactionArbitraryData,
-		consensus.ValidateCoinInputsAreValid,
-		consensus.ValidateCoinOutputsAreValid,
-		consensus.ValidateBlockStakeInputsAreValid,
-		consensus.ValidateBlockStakeOutputsAreValid,
-		consensus.ValidateDoubleCoinSpends,
-		consensus.ValidateDoubleBlockStakeSpends,
-		consensus.ValidateCoinInputsAreFulfilled,
-		consensus.ValidateBlockStakeInputsAreFulfilled,
-	}
+	return GetStandardForkSchedule().TransactionValidators()
 }
 
+// GetStandardTransactionVersionMappedValidators returns the transaction version mapped validators
+// of the standard network.
 func GetStandardTransactionVersionMappedValidators() map[types.TransactionVersion][]modules.TransactionValidationFunction {
-	const (
-		minimumBlockHeightSinceMinerFeesAreRequired   = 300000
-		blockHeightSinceLegacyTransactionsAreDisabled = 385000
-	)
-	validator := &MinimumMinerFeeValidator{MinimumBlockHeight: minimumBlockHeightSinceMinerFeesAreRequired}
-	legacyValidator := &DisableTransactionSinceValidator{MinimumBlockHeight: blockHeightSinceLegacyTransactionsAreDisabled}
-	return map[types.TransactionVersion][]modules.TransactionValidationFunction{
-		types.TransactionVersionZero: {
-			validator.Validate,
-			legacyValidator.Validate,
-			consensus.ValidateCoinOutputsAreBalanced,
-			consensus.ValidateBlockStakeOutputsAreBalanced,
-		},
-		types.TransactionVersionOne: {
-			validator.Validate,
-			consensus.ValidateCoinOutputsAreBalanced,
-			consensus.ValidateBlockStakeOutputsAreBalanced,
-		},
-	}
+	return GetStandardForkSchedule().TransactionVersionMappedValidators()
 }
 
+// GetTestnetTransactionValidators returns the transaction validators of the testnet network.
 func GetTestnetTransactionValidators() []modules.TransactionValidationFunction {
-	return []modules.TransactionValidationFunction{
-		consensus.ValidateTransactionFitsInABlock,
-		consensus.ValidateTransactionArbitraryData,
-		consensus.ValidateCoinInputsAreValid,
-		consensus.ValidateCoinOutputsAreValid,
-		consensus.ValidateBlockStakeInputsAreValid,
-		consensus.ValidateBlockStakeOutputsAreValid,
-		consensus.ValidateDoubleCoinSpends,
-		consensus.ValidateDoubleBlockStakeSpends,
-		consensus.ValidateCoinInputsAreFulfilled,
-		consensus.ValidateBlockStakeInputsAreFulfilled,
-	}
+	return GetTestnetForkSchedule().TransactionValidators()
 }
 
+// GetTestnetTransactionVersionMappedValidators returns the transaction version mapped validators
+// of the testnet network.
 func GetTestnetTransactionVersionMappedValidators() map[types.TransactionVersion][]modules.TransactionValidationFunction {
-	const (
-		minimumBlockHeightSinceMinerFeesAreRequired   = 300000
-		blockHeightSinceLegacyTransactionsAreDisabled = 385000
-	)
-	validator := &MinimumMinerFeeValidator{MinimumBlockHeight: minimumBlockHeightSinceMinerFeesAreRequired}
-	legacyValidator := &DisableTransactionSinceValidator{MinimumBlockHeight: blockHeightSinceLegacyTransactionsAreDisabled}
-	return map[types.TransactionVersion][]modules.TransactionValidationFunction{
-		types.TransactionVersionZero: {
-			validator.Validate,
-			legacyValidator.Validate,
-			consensus.ValidateCoinOutputsAreBalanced,
-			consensus.ValidateBlockStakeOutputsAreBalanced,
-		},
-		types.TransactionVersionOne: {
-			validator.Validate,
-			consensus.ValidateCoinOutputsAreBalanced,
-			consensus.ValidateBlockStakeOutputsAreBalanced,
-		},
-	}
+	return GetTestnetForkSchedule().TransactionVersionMappedValidators()
 }
 
+// GetDevnetTransactionValidators returns the transaction validators of the devnet network.
 func GetDevnetTransactionValidators() []modules.TransactionValidationFunction {
-	return []modules.TransactionValidationFunction{
-		consensus.ValidateTransactionFitsInABlock,
-		consensus.ValidateTransactionArbitraryData,
-		consensus.ValidateCoinInputsAreValid,
-		consensus.ValidateCoinOutputsAreValid,
-		consensus.ValidateBlockStakeInputsAreValid,
-		consensus.ValidateBlockStakeOutputsAreValid,
-		consensus.ValidateMinerFeeIsPresent,
-		consensus.ValidateMinerFeesAreValid,
-		consensus.ValidateDoubleCoinSpends,
-		consensus.ValidateDoubleBlockStakeSpends,
-		consensus.ValidateCoinInputsAreFulfilled,
-		consensus.ValidateBlockStakeInputsAreFulfilled,
-	}
+	return GetDevnetForkSchedule().TransactionValidators()
 }
 
+// GetDevnetTransactionVersionMappedValidators returns the transaction version mapped validators
+// of the devnet network.
 func GetDevnetTransactionVersionMappedValidators() map[types.TransactionVersion][]modules.TransactionValidationFunction {
-	return map[types.TransactionVersion][]modules.TransactionValidationFunction{
-		types.TransactionVersionZero: {
-			consensus.ValidateInvalidByDefault, // no longer required to exist on devnet
-		},
-		types.TransactionVersionOne: {
-			consensus.ValidateCoinOutputsAreBalanced,
-			consensus.ValidateBlockStakeOutputsAreBalanced,
-		},
-	}
+	return GetDevnetForkSchedule().TransactionVersionMappedValidators()
+}
+
+// GetCustomTransactionValidators returns the transaction validators of a custom network.
+func GetCustomTransactionValidators() []modules.TransactionValidationFunction {
+	return GetCustomForkSchedule(config.NetworkActivationHeights{}).TransactionValidators()
+}
+
+// GetCustomTransactionVersionMappedValidators returns the transaction version mapped validators
+// of a custom network, using the given heights since which miner fees are required
+// and since which legacy transactions are disabled.
+func GetCustomTransactionVersionMappedValidators(minimumBlockHeightSinceMinerFeesAreRequired, blockHeightSinceLegacyTransactionsAreDisabled types.BlockHeight) map[types.TransactionVersion][]modules.TransactionValidationFunction {
+	return GetCustomForkSchedule(config.NetworkActivationHeights{
+		MinerFeesRequired:          minimumBlockHeightSinceMinerFeesAreRequired,
+		LegacyTransactionsDisabled: blockHeightSinceLegacyTransactionsAreDisabled,
+	}).TransactionVersionMappedValidators()
 }
 
 // MinimumMinerFeeValidator is a validator which allows to check
@@ -138,6 +85,35 @@ func (validator *MinimumMinerFeeValidator) Validate(tx modules.ConsensusTransact
 	return nil
 }
 
+// MinimumMinerFeePerSizeValidator is a validator which allows to check,
+// only since a specific (block) height, that transactions pay the minimum miner fee
+// for every started feemarket.SizeUnit of bytes, rather than only once.
+type MinimumMinerFeePerSizeValidator struct {
+	MinimumBlockHeight types.BlockHeight
+}
+
+// Validate is a validator function that checks if the total miner fee is sufficient
+// for the size of the transaction. Until the minimum block height no such check is done.
+func (validator *MinimumMinerFeePerSizeValidator) Validate(tx modules.ConsensusTransaction, ctx types.TransactionValidationContext) error {
+	if ctx.BlockHeight < validator.MinimumBlockHeight {
+		// no need to check
+		return nil
+	}
+	if ctx.IsBlockCreatingTx {
+		return nil // validation does not apply to to block creation tx
+	}
+	size, err := feemarket.TransactionSize(tx.Transaction)
+	if err != nil {
+		return fmt.Errorf("failed to compute size of tx %s: %v", tx.ID().String(), err)
+	}
+	required := feemarket.RequiredMinerFee(size, ctx.MinimumMinerFee)
+	if feemarket.TotalMinerFee(tx.Transaction).Cmp(required) < 0 {
+		return fmt.Errorf("tx %s of %d bytes pays a miner fee lower than the required %s: %v",
+			tx.ID().String(), size, required.String(), types.ErrTooSmallMinerFee)
+	}
+	return nil
+}
+
 // DisableTransactionSinceValidator is a validator which allows to
 // no longer allow a transaction since a specific block height
 type DisableTransactionSinceValidator struct {
@@ -153,3 +129,79 @@ func (validator *DisableTransactionSinceValidator) Validate(tx modules.Consensus
 	}
 	return fmt.Errorf("transaction is no longer allowed since block height %d (disallowed since %d)", ctx.BlockHeight, validator.MinimumBlockHeight)
 }
+
+// VestingConditionValidator is a validator which allows vesting conditions
+// to be used as the condition of coin outputs since a specific block height,
+// and which ensures that the locked amount of spent vesting outputs is re-locked.
+type VestingConditionValidator struct {
+	MinimumBlockHeight types.BlockHeight
+}
+
+// Validate is a validator function that checks if the vesting conditions used by the transaction are valid.
+// Vesting conditions cannot be used for block stake outputs, and for coin outputs only since the minimum block height.
+// Spending a vesting output is only allowed if its locked amount is sent
+// to one or multiple outputs using the exact same vesting condition.
+func (validator *VestingConditionValidator) Validate(tx modules.ConsensusTransaction, ctx types.TransactionValidationContext) error {
+	for _, bso := range tx.BlockStakeOutputs {
+		if bso.Condition.ConditionType() == tftypes.ConditionTypeVesting {
+			return fmt.Errorf("tx %s uses a vesting condition for a block stake output", tx.ID().String())
+		}
+	}
+	if ctx.BlockHeight < validator.MinimumBlockHeight {
+		for _, co := range tx.CoinOutputs {
+			if co.Condition.ConditionType() == tftypes.ConditionTypeVesting {
+				return fmt.Errorf("vesting condition is not yet allowed at block height %d (allowed since %d)",
+					ctx.BlockHeight, validator.MinimumBlockHeight)
+			}
+		}
+		return nil
+	}
+
+	// collect the amount to be re-locked for each vesting condition spent by this transaction
+	type relock struct {
+		condition *tftypes.VestingCondition
+		amount    types.Currency
+	}
+	var relocks []relock
+	for _, ci := range tx.CoinInputs {
+		co, ok := tx.SpentCoinOutputs[ci.ParentID]
+		if !ok {
+			continue // validated by the ValidateCoinInputsAreFulfilled validator
+		}
+		vc, ok := co.Condition.Condition.(*tftypes.VestingCondition)
+		if !ok {
+			continue
+		}
+		locked := vc.LockedAmount(ctx.BlockHeight, ctx.BlockTime)
+		if co.Value.Cmp(locked) < 0 {
+			locked = co.Value
+		}
+		if locked.IsZero() {
+			continue
+		}
+		var found bool
+		for idx := range relocks {
+			if relocks[idx].condition.Equal(vc) {
+				relocks[idx].amount = relocks[idx].amount.Add(locked)
+				found = true
+				break
+			}
+		}
+		if !found {
+			relocks = append(relocks, relock{condition: vc, amount: locked})
+		}
+	}
+	for _, rl := range relocks {
+		var relocked types.Currency
+		for _, co := range tx.CoinOutputs {
+			if rl.condition.Equal(co.Condition.Condition) {
+				relocked = relocked.Add(co.Value)
+			}
+		}
+		if relocked.Cmp(rl.amount) < 0 {
+			return fmt.Errorf("tx %s re-locks only %s of the %s coins still locked by a spent vesting condition",
+				tx.ID().String(), relocked.String(), rl.amount.String())
+		}
+	}
+	return nil
+}
@@ -5,6 +5,8 @@ import (
 	"testing"
 
 	"github.com/threefoldfoundation/tfchain/pkg/config"
+	tftypes "github.com/threefoldfoundation/tfchain/pkg/types"
+	"github.com/threefoldtech/rivine/crypto"
 	"github.com/threefoldtech/rivine/modules"
 	"github.com/threefoldtech/rivine/types"
 )
@@ -368,3 +370,108 @@ func huh(str string) (uh types.UnlockHash) {
 	}
 	return
 }
+
+func TestMinimumMinerFeePerSizeValidator(t *testing.T) {
+	minimumFee := types.NewCurrency64(100)
+	validator := &MinimumMinerFeePerSizeValidator{MinimumBlockHeight: 1000}
+	newTx := func(fee uint64) modules.ConsensusTransaction {
+		return modules.ConsensusTransaction{
+			Transaction: types.Transaction{
+				Version:       types.TransactionVersionOne,
+				ArbitraryData: make([]byte, 2000),
+				MinerFees:     []types.Currency{types.NewCurrency64(fee)},
+			},
+		}
+	}
+	testCases := []struct {
+		Fee     uint64
+		Height  types.BlockHeight
+		IsValid bool
+	}{
+		{100, 999, true},
+		{100, 1000, false},
+		{200, 1000, false},
+		{300, 1000, true},
+		{1000, 2000, true},
+	}
+	for idx, testCase := range testCases {
+		err := validator.Validate(newTx(testCase.Fee), types.TransactionValidationContext{
+			ValidationContext: types.ValidationContext{BlockHeight: testCase.Height},
+			MinimumMinerFee:   minimumFee,
+		})
+		if testCase.IsValid && err != nil {
+			t.Errorf("#%d: unexpected error: %v", idx, err)
+		} else if !testCase.IsValid && err == nil {
+			t.Errorf("#%d: expected error, but none received", idx)
+		}
+	}
+}
+
+func TestVestingConditionValidator(t *testing.T) {
+	validator := &VestingConditionValidator{MinimumBlockHeight: 100}
+	owner := types.NewCondition(types.NewUnlockHashCondition(types.UnlockHash{
+		Type: types.UnlockTypePubKey,
+		Hash: crypto.Hash{1},
+	}))
+	vesting := tftypes.NewVestingCondition(100, 200, 0, types.NewCurrency64(1000), owner.Condition)
+	otherVesting := tftypes.NewVestingCondition(100, 300, 0, types.NewCurrency64(1000), owner.Condition)
+	parentID := types.CoinOutputID{1}
+	newTx := func(outputs ...types.CoinOutput) modules.ConsensusTransaction {
+		return modules.ConsensusTransaction{
+			Transaction: types.Transaction{
+				Version:     types.TransactionVersionOne,
+				CoinInputs:  []types.CoinInput{{ParentID: parentID}},
+				CoinOutputs: outputs,
+			},
+			SpentCoinOutputs: map[types.CoinOutputID]types.CoinOutput{
+				parentID: {Value: types.NewCurrency64(1000), Condition: types.NewCondition(vesting)},
+			},
+		}
+	}
+	testCases := []struct {
+		Outputs []types.CoinOutput
+		Height  types.BlockHeight
+		IsValid bool
+	}{
+		// vesting conditions are not yet allowed
+		{[]types.CoinOutput{{Value: types.NewCurrency64(1000), Condition: types.NewCondition(vesting)}}, 99, false},
+		// nothing vested yet, everything has to be re-locked
+		{[]types.CoinOutput{{Value: types.NewCurrency64(1000), Condition: types.NewCondition(vesting)}}, 100, true},
+		{[]types.CoinOutput{{Value: types.NewCurrency64(1000), Condition: owner}}, 100, false},
+		// half vested
+		{[]types.CoinOutput{
+			{Value: types.NewCurrency64(500), Condition: owner},
+			{Value: types.NewCurrency64(500), Condition: types.NewCondition(vesting)},
+		}, 150, true},
+		{[]types.CoinOutput{
+			{Value: types.NewCurrency64(600), Condition: owner},
+			{Value: types.NewCurrency64(400), Condition: types.NewCondition(vesting)},
+		}, 150, false},
+		{[]types.CoinOutput{
+			{Value: types.NewCurrency64(500), Condition: owner},
+			{Value: types.NewCurrency64(500), Condition: types.NewCondition(otherVesting)},
+		}, 150, false},
+		// fully vested
+		{[]types.CoinOutput{{Value: types.NewCurrency64(1000), Condition: owner}}, 200, true},
+	}
+	for idx, testCase := range testCases {
+		err := validator.Validate(newTx(testCase.Outputs...), types.TransactionValidationContext{
+			ValidationContext: types.ValidationContext{BlockHeight: testCase.Height},
+		})
+		if testCase.IsValid && err != nil {
+			t.Errorf("#%d: unexpected error: %v", idx, err)
+		} else if !testCase.IsValid && err == nil {
+			t.Errorf("#%d: expected error, but none received", idx)
+		}
+	}
+
+	// vesting conditions can never be used for block stake outputs
+	err := validator.Validate(modules.ConsensusTransaction{
+		Transaction: types.Transaction{
+			BlockStakeOutputs: []types.BlockStakeOutput{{Value: types.NewCurrency64(1), Condition: types.NewCondition(vesting)}},
+		},
+	}, types.TransactionValidationContext{ValidationContext: types.ValidationContext{BlockHeight: 1000}})
+	if err == nil {
+		t.Error("expected vesting block stake output to be invalid")
+	}
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	rapi "github.com/threefoldtech/rivine/pkg/api"
+
+	"github.com/threefoldfoundation/tfchain/pkg/erc20"
+)
+
+// ERC20LocalWithdrawalsGET is the response body of a GET request to /erc20/local/withdrawals.
+type ERC20LocalWithdrawalsGET struct {
+	Withdrawals []erc20.Withdrawal `json:"withdrawals"`
+}
+
+// RegisterLocalERC20HTTPHandlers registers the handlers used to manage
+// the observed withdrawals of a LocalERC20TransactionValidator.
+func RegisterLocalERC20HTTPHandlers(router rapi.Router, validator *erc20.LocalERC20TransactionValidator, requiredPassword string) {
+	if router == nil {
+		panic("no router given")
+	}
+	if validator == nil {
+		panic("no LocalERC20TransactionValidator given")
+	}
+	router.GET("/erc20/local/withdrawals", NewERC20LocalWithdrawalsHandler(validator))
+	router.POST("/erc20/local/withdrawals", rapi.RequirePasswordHandler(NewERC20LocalWithdrawalsPostHandler(validator), requiredPassword))
+}
+
+// NewERC20LocalWithdrawalsHandler creates a handler to handle GET requests to /erc20/local/withdrawals,
+// returning all withdrawals observed by the local ERC20 validator.
+func NewERC20LocalWithdrawalsHandler(validator *erc20.LocalERC20TransactionValidator) httprouter.Handle {
+	return func(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+		withdrawals, err := validator.Withdrawals()
+		if err != nil {
+			rapi.WriteError(w, rapi.Error{Message: err.Error()}, http.StatusInternalServerError)
+			return
+		}
+		rapi.WriteJSON(w, ERC20LocalWithdrawalsGET{Withdrawals: withdrawals})
+	}
+}
+
+// NewERC20LocalWithdrawalsPostHandler creates a handler to handle POST requests to /erc20/local/withdrawals,
+// adding the JSON-encoded withdrawal in the request body to the withdrawals observed by the local ERC20 validator.
+func NewERC20LocalWithdrawalsPostHandler(validator *erc20.LocalERC20TransactionValidator) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		var withdrawal erc20.Withdrawal
+		err := json.NewDecoder(req.Body).Decode(&withdrawal)
+		if err != nil {
+			rapi.WriteError(w, rapi.Error{Message: "failed to decode withdrawal: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		err = validator.AddWithdrawal(withdrawal)
+		if err != nil {
+			rapi.WriteError(w, rapi.Error{Message: err.Error()}, http.StatusBadRequest)
+			return
+		}
+		rapi.WriteSuccess(w)
+	}
+}
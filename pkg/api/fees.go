@@ -0,0 +1,54 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/threefoldfoundation/tfchain/pkg/feemarket"
+	rapi "github.com/threefoldtech/rivine/pkg/api"
+)
+
+// RegisterDaemonFeesHTTPHandlers registers the handler for the fee suggestion HTTP endpoint.
+func RegisterDaemonFeesHTTPHandlers(router rapi.Router, estimator *feemarket.FeeEstimator) {
+	if router == nil {
+		panic("no router given")
+	}
+	if estimator == nil {
+		panic("no FeeEstimator given")
+	}
+	router.GET("/daemon/fees", NewDaemonFeesHandler(estimator))
+}
+
+// NewDaemonFeesHandler creates a handler to handle GET requests to /daemon/fees,
+// suggesting a miner fee based on the fullness of recent blocks.
+// The optional size (in bytes) and blocks query parameters define the size of the transaction
+// a fee is suggested for, and the amount of recent blocks to look at (at most feemarket.MaxEstimatorBlocks).
+func NewDaemonFeesHandler(estimator *feemarket.FeeEstimator) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		var (
+			size   uint64
+			blocks = feemarket.DefaultEstimatorBlocks
+		)
+		if str := req.FormValue("size"); str != "" {
+			n, err := strconv.ParseUint(str, 10, 64)
+			if err != nil {
+				rapi.WriteError(w, rapi.Error{Message: "invalid size: " + err.Error()}, http.StatusBadRequest)
+				return
+			}
+			size = n
+		}
+		if str := req.FormValue("blocks"); str != "" {
+			n, err := strconv.Atoi(str)
+			if err != nil || n <= 0 || n > feemarket.MaxEstimatorBlocks {
+				rapi.WriteError(w, rapi.Error{
+					Message: fmt.Sprintf("invalid blocks: has to be a positive number, no greater than %d", feemarket.MaxEstimatorBlocks),
+				}, http.StatusBadRequest)
+				return
+			}
+			blocks = n
+		}
+		rapi.WriteJSON(w, estimator.SuggestFee(size, blocks))
+	}
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/threefoldfoundation/tfchain/pkg/config"
+	"github.com/threefoldfoundation/tfchain/pkg/feemarket"
+	rtypes "github.com/threefoldtech/rivine/types"
+)
+
+// testBlockConsensusSet returns an empty block for every height up to its current height
+type testBlockConsensusSet struct {
+	testConsensusSet
+}
+
+func (cs *testBlockConsensusSet) BlockAtHeight(height rtypes.BlockHeight) (rtypes.Block, bool) {
+	return rtypes.Block{}, height <= cs.height
+}
+
+func TestDaemonFeesHandler(t *testing.T) {
+	cs := &testBlockConsensusSet{testConsensusSet{height: 500}}
+	handler := NewDaemonFeesHandler(feemarket.NewFeeEstimator(cs, config.GetDevnetGenesis()))
+
+	testCases := []struct {
+		Query  string
+		Code   int
+		Blocks int
+	}{
+		{"", http.StatusOK, feemarket.DefaultEstimatorBlocks},
+		{"?blocks=1", http.StatusOK, 1},
+		{"?blocks=100&size=2500", http.StatusOK, feemarket.MaxEstimatorBlocks},
+		{"?blocks=101", http.StatusBadRequest, 0},
+		{"?blocks=1000000000", http.StatusBadRequest, 0},
+		{"?blocks=0", http.StatusBadRequest, 0},
+		{"?blocks=-1", http.StatusBadRequest, 0},
+		{"?blocks=ten", http.StatusBadRequest, 0},
+		{"?size=-1", http.StatusBadRequest, 0},
+	}
+	for _, testCase := range testCases {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodGet, "/daemon/fees"+testCase.Query, nil), nil)
+		if rec.Code != testCase.Code {
+			t.Errorf("%q: expected status code %d, not %d", testCase.Query, testCase.Code, rec.Code)
+			continue
+		}
+		if testCase.Code != http.StatusOK {
+			continue
+		}
+		var suggestion feemarket.FeeSuggestion
+		if err := json.NewDecoder(rec.Body).Decode(&suggestion); err != nil {
+			t.Fatal("failed to decode fee suggestion:", err)
+		}
+		if suggestion.Blocks != testCase.Blocks {
+			t.Errorf("%q: expected %d blocks to be looked at, not %d", testCase.Query, testCase.Blocks, suggestion.Blocks)
+		}
+	}
+}
@@ -0,0 +1,356 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/threefoldtech/rivine/modules"
+	rapi "github.com/threefoldtech/rivine/pkg/api"
+	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
+	rtypes "github.com/threefoldtech/rivine/types"
+
+	erc20types "github.com/threefoldtech/rivine-extension-erc20/types"
+)
+
+// names of the modules reported by the HealthMonitor
+const (
+	HealthModuleDaemon    = "daemon"
+	HealthModuleGateway   = "gateway"
+	HealthModuleConsensus = "consensus"
+	HealthModuleWallet    = "wallet"
+	HealthModuleERC20     = "erc20"
+)
+
+// RPCSendHeight is the name of the gateway RPC used by tfchain nodes
+// to report the height of their consensus set to their peers.
+const RPCSendHeight = "SendHeight"
+
+// minPeerHeightsRefreshInterval is the minimum amount of time between two requests
+// for the heights of the peers, used as well in case no block frequency is configured.
+const minPeerHeightsRefreshInterval = 10 * time.Second
+
+// DaemonHealthGET is the response body of the /daemon/health and /daemon/ready endpoints.
+type DaemonHealthGET struct {
+	Ready   bool                    `json:"ready"`
+	Modules map[string]ModuleHealth `json:"modules"`
+}
+
+// ModuleHealth contains the status of a single (loaded) daemon module.
+type ModuleHealth struct {
+	Ready  bool   `json:"ready"`
+	Status string `json:"status"`
+}
+
+// HealthMonitorOptions can be used to configure the thresholds of a HealthMonitor.
+type HealthMonitorOptions struct {
+	// BlockFrequency is the (average) expected amount of seconds between two blocks.
+	BlockFrequency rtypes.BlockHeight
+	// MaxBlockDelta defines how many blocks the consensus set can lag behind the highest
+	// height reported by its peers (or the ERC20 light client behind the highest known block),
+	// while still being considered synced.
+	MaxBlockDelta rtypes.BlockHeight
+	// MinimumPeers defines the minimum amount of peers the gateway has to be connected to.
+	MinimumPeers int
+}
+
+// HealthMonitor keeps track of the daemon modules as they are loaded,
+// such that their status can be reported while the daemon is still starting up.
+type HealthMonitor struct {
+	opts HealthMonitorOptions
+
+	gateway     modules.Gateway
+	cs          modules.ConsensusSet
+	wallet      modules.Wallet
+	erc20       erc20types.ERC20InfoAPI
+	erc20Synced bool
+	loaded      bool
+
+	mu sync.RWMutex
+
+	// heights reported by the peers of the gateway, refreshed in the background
+	// once every block frequency, such that peers aren't contacted for any request
+	peerHeights []rtypes.BlockHeight
+	peersMu     sync.Mutex
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewHealthMonitor creates a new HealthMonitor, with no modules registered yet.
+func NewHealthMonitor(opts HealthMonitorOptions) *HealthMonitor {
+	return &HealthMonitor{
+		opts: opts,
+		stop: make(chan struct{}),
+	}
+}
+
+// SetGateway registers the gateway module to the monitor,
+// and starts requesting the heights of its peers in the background.
+func (hm *HealthMonitor) SetGateway(g modules.Gateway) {
+	hm.mu.Lock()
+	hm.gateway = g
+	hm.registerHeightRPC()
+	hm.mu.Unlock()
+	go hm.threadedRefreshPeerHeights(g)
+}
+
+// SetConsensusSet registers the consensus module to the monitor.
+func (hm *HealthMonitor) SetConsensusSet(cs modules.ConsensusSet) {
+	hm.mu.Lock()
+	hm.cs = cs
+	hm.registerHeightRPC()
+	hm.mu.Unlock()
+}
+
+// registerHeightRPC registers the RPC reporting the height of the consensus set to peers,
+// as soon as both the gateway and consensus set are registered
+func (hm *HealthMonitor) registerHeightRPC() {
+	if hm.gateway == nil || hm.cs == nil {
+		return
+	}
+	cs := hm.cs
+	hm.gateway.RegisterRPC(RPCSendHeight, func(conn modules.PeerConn) error {
+		return siabin.WriteObject(conn, cs.Height())
+	})
+}
+
+// SetWallet registers the wallet module to the monitor.
+func (hm *HealthMonitor) SetWallet(w modules.Wallet) {
+	hm.mu.Lock()
+	hm.wallet = w
+	hm.mu.Unlock()
+}
+
+// SetERC20 registers the ERC20 light client to the monitor.
+// It will be reported as syncing until MarkERC20Synced is called.
+func (hm *HealthMonitor) SetERC20(info erc20types.ERC20InfoAPI) {
+	hm.mu.Lock()
+	hm.erc20 = info
+	hm.mu.Unlock()
+}
+
+// MarkERC20Synced marks the initial sync of the ERC20 light client as finished.
+func (hm *HealthMonitor) MarkERC20Synced() {
+	hm.mu.Lock()
+	hm.erc20Synced = true
+	hm.mu.Unlock()
+}
+
+// MarkLoaded marks the daemon as fully loaded.
+func (hm *HealthMonitor) MarkLoaded() {
+	hm.mu.Lock()
+	hm.loaded = true
+	hm.mu.Unlock()
+}
+
+// Close stops requesting the heights of the peers of the gateway.
+func (hm *HealthMonitor) Close() {
+	hm.stopOnce.Do(func() {
+		close(hm.stop)
+	})
+}
+
+// Status returns the status of all registered modules,
+// the daemon is only ready if all those modules are ready.
+// It only uses the peer heights known at the time of the call,
+// such that it never has to wait for the network.
+func (hm *HealthMonitor) Status() DaemonHealthGET {
+	hm.mu.RLock()
+	defer hm.mu.RUnlock()
+
+	status := DaemonHealthGET{
+		Modules: make(map[string]ModuleHealth),
+	}
+	if hm.loaded {
+		status.Modules[HealthModuleDaemon] = ModuleHealth{Ready: true, Status: "loaded"}
+	} else {
+		status.Modules[HealthModuleDaemon] = ModuleHealth{Status: "loading"}
+	}
+	if hm.gateway != nil {
+		status.Modules[HealthModuleGateway] = hm.gatewayHealth()
+	}
+	if hm.cs != nil {
+		status.Modules[HealthModuleConsensus] = hm.consensusHealth()
+	}
+	if hm.wallet != nil {
+		status.Modules[HealthModuleWallet] = hm.walletHealth()
+	}
+	if hm.erc20 != nil {
+		status.Modules[HealthModuleERC20] = hm.erc20Health()
+	}
+
+	status.Ready = true
+	for _, module := range status.Modules {
+		if !module.Ready {
+			status.Ready = false
+			break
+		}
+	}
+	return status
+}
+
+func (hm *HealthMonitor) gatewayHealth() ModuleHealth {
+	peers := len(hm.gateway.Peers())
+	return ModuleHealth{
+		Ready:  peers >= hm.opts.MinimumPeers,
+		Status: fmt.Sprintf("connected to %d peer(s)", peers),
+	}
+}
+
+func (hm *HealthMonitor) consensusHealth() ModuleHealth {
+	height := hm.cs.Height()
+	if !hm.cs.Synced() {
+		return ModuleHealth{Status: fmt.Sprintf("syncing at height %d", height)}
+	}
+	peerHeight, ok := hm.highestPeerHeight()
+	if !ok {
+		// none of the peers reported its height, rely on the consensus set only
+		return ModuleHealth{Ready: true, Status: fmt.Sprintf("synced at height %d", height)}
+	}
+	// consider the consensus set out of sync in case it lags behind
+	// the highest height reported by its peers by more than the max allowed delta
+	if height+hm.opts.MaxBlockDelta < peerHeight {
+		return ModuleHealth{Status: fmt.Sprintf("lagging behind at height %d/%d", height, peerHeight)}
+	}
+	return ModuleHealth{Ready: true, Status: fmt.Sprintf("synced at height %d", height)}
+}
+
+// highestPeerHeight returns the highest height last reported by the peers of the gateway,
+// false is returned if no gateway is registered or none of the peers reported its height
+func (hm *HealthMonitor) highestPeerHeight() (rtypes.BlockHeight, bool) {
+	if hm.gateway == nil {
+		return 0, false
+	}
+	hm.peersMu.Lock()
+	defer hm.peersMu.Unlock()
+	if len(hm.peerHeights) == 0 {
+		return 0, false
+	}
+	highest := hm.peerHeights[0]
+	for _, height := range hm.peerHeights[1:] {
+		if height > highest {
+			highest = height
+		}
+	}
+	return highest, true
+}
+
+// threadedRefreshPeerHeights requests the heights of the peers of the given gateway
+// once every block frequency, until the monitor is closed
+func (hm *HealthMonitor) threadedRefreshPeerHeights(g modules.Gateway) {
+	interval := time.Duration(hm.opts.BlockFrequency) * time.Second
+	if interval < minPeerHeightsRefreshInterval {
+		interval = minPeerHeightsRefreshInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		hm.refreshPeerHeights(g)
+		select {
+		case <-hm.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// refreshPeerHeights requests the heights of the peers of the given gateway,
+// only locking the monitor once all peers responded, in order to store the new heights
+func (hm *HealthMonitor) refreshPeerHeights(g modules.Gateway) {
+	heights := requestPeerHeights(g)
+	hm.peersMu.Lock()
+	hm.peerHeights = heights
+	hm.peersMu.Unlock()
+}
+
+// requestPeerHeights requests the height of the consensus set of all peers of the gateway,
+// ignoring the peers which fail to report it, such as nodes which don't support the RPC
+func requestPeerHeights(g modules.Gateway) []rtypes.BlockHeight {
+	peers := g.Peers()
+	results := make(chan rtypes.BlockHeight, len(peers))
+	var wg sync.WaitGroup
+	for _, peer := range peers {
+		wg.Add(1)
+		go func(addr modules.NetAddress) {
+			defer wg.Done()
+			var height rtypes.BlockHeight
+			err := g.RPC(addr, RPCSendHeight, func(conn modules.PeerConn) error {
+				return siabin.ReadObject(conn, &height, 16)
+			})
+			if err == nil {
+				results <- height
+			}
+		}(peer.NetAddress)
+	}
+	wg.Wait()
+	close(results)
+	var heights []rtypes.BlockHeight
+	for height := range results {
+		heights = append(heights, height)
+	}
+	return heights
+}
+
+func (hm *HealthMonitor) walletHealth() ModuleHealth {
+	if !hm.wallet.Encrypted() {
+		return ModuleHealth{Status: "not initialized"}
+	}
+	if !hm.wallet.Unlocked() {
+		return ModuleHealth{Status: "locked"}
+	}
+	return ModuleHealth{Ready: true, Status: "unlocked"}
+}
+
+func (hm *HealthMonitor) erc20Health() ModuleHealth {
+	status, err := hm.erc20.GetStatus()
+	if err != nil {
+		return ModuleHealth{Status: "failed to get sync status: " + err.Error()}
+	}
+	if !hm.erc20Synced {
+		return ModuleHealth{Status: fmt.Sprintf("syncing at block %d/%d", status.CurrentBlock, status.HighestBlock)}
+	}
+	if status.CurrentBlock+uint64(hm.opts.MaxBlockDelta) < status.HighestBlock {
+		return ModuleHealth{Status: fmt.Sprintf("lagging behind at block %d/%d", status.CurrentBlock, status.HighestBlock)}
+	}
+	return ModuleHealth{Ready: true, Status: fmt.Sprintf("synced at block %d", status.CurrentBlock)}
+}
+
+// RegisterDaemonHealthHTTPHandlers registers the handlers for the health and readiness HTTP endpoints.
+func RegisterDaemonHealthHTTPHandlers(router rapi.Router, hm *HealthMonitor) {
+	if router == nil {
+		panic("no router given")
+	}
+	if hm == nil {
+		panic("no HealthMonitor given")
+	}
+	router.GET("/daemon/health", NewDaemonHealthHandler(hm))
+	router.GET("/daemon/ready", NewDaemonReadyHandler(hm))
+}
+
+// NewDaemonHealthHandler creates a handler to handle GET requests to /daemon/health,
+// reporting the status of all loaded modules, as long as the daemon is alive.
+func NewDaemonHealthHandler(hm *HealthMonitor) httprouter.Handle {
+	return func(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+		rapi.WriteJSON(w, hm.Status())
+	}
+}
+
+// NewDaemonReadyHandler creates a handler to handle GET requests to /daemon/ready,
+// reporting the status of all loaded modules, with a 503 status code for as long
+// as not all of those modules are ready.
+func NewDaemonReadyHandler(hm *HealthMonitor) httprouter.Handle {
+	return func(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+		status := hm.Status()
+		if !status.Ready {
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(status) // ignore error, same as rivine's WriteError
+			return
+		}
+		rapi.WriteJSON(w, status)
+	}
+}
@@ -0,0 +1,330 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
+	rtypes "github.com/threefoldtech/rivine/types"
+
+	erc20types "github.com/threefoldtech/rivine-extension-erc20/types"
+)
+
+// testPeerConn implements modules.PeerConn on top of an in-memory connection
+type testPeerConn struct {
+	net.Conn
+	addr modules.NetAddress
+}
+
+func (conn testPeerConn) RPCAddr() modules.NetAddress { return conn.addr }
+
+// testGateway is connected to peers reporting the given heights,
+// peers without a height don't support the height RPC
+type testGateway struct {
+	modules.Gateway
+	peers   map[modules.NetAddress]*rtypes.BlockHeight
+	handler modules.RPCFunc
+}
+
+func (g *testGateway) Peers() []modules.Peer {
+	peers := make([]modules.Peer, 0, len(g.peers))
+	for addr := range g.peers {
+		peers = append(peers, modules.Peer{NetAddress: addr})
+	}
+	return peers
+}
+
+func (g *testGateway) RegisterRPC(name string, fn modules.RPCFunc) {
+	if name == RPCSendHeight {
+		g.handler = fn
+	}
+}
+
+func (g *testGateway) RPC(addr modules.NetAddress, name string, fn modules.RPCFunc) error {
+	height, ok := g.peers[addr]
+	if !ok || name != RPCSendHeight {
+		return errors.New("unknown peer or RPC")
+	}
+	if height == nil {
+		return errors.New("RPC not supported by peer")
+	}
+	local, remote := net.Pipe()
+	defer local.Close()
+	go func() {
+		defer remote.Close()
+		siabin.WriteObject(remote, *height)
+	}()
+	return fn(testPeerConn{Conn: local, addr: addr})
+}
+
+type testConsensusSet struct {
+	modules.ConsensusSet
+	height rtypes.BlockHeight
+	synced bool
+}
+
+func (cs *testConsensusSet) Height() rtypes.BlockHeight { return cs.height }
+func (cs *testConsensusSet) Synced() bool               { return cs.synced }
+
+type testWallet struct {
+	modules.Wallet
+	encrypted, unlocked bool
+}
+
+func (w *testWallet) Encrypted() bool { return w.encrypted }
+func (w *testWallet) Unlocked() bool  { return w.unlocked }
+
+type testERC20Info struct {
+	status *erc20types.ERC20SyncStatus
+	err    error
+}
+
+func (info *testERC20Info) GetStatus() (*erc20types.ERC20SyncStatus, error) {
+	return info.status, info.err
+}
+
+func (info *testERC20Info) GetBalanceInfo() (*erc20types.ERC20BalanceInfo, error) {
+	return nil, errors.New("not implemented")
+}
+
+func heightPtr(height rtypes.BlockHeight) *rtypes.BlockHeight { return &height }
+
+// getHealth requests the status from the given handler, returning the status code and decoded body
+func getHealth(t *testing.T, handler httprouter.Handle) (int, DaemonHealthGET) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/", nil), nil)
+	var body DaemonHealthGET
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatal("failed to decode health status:", err)
+	}
+	return rec.Code, body
+}
+
+func TestHealthHandlers(t *testing.T) {
+	testCases := []struct {
+		Name    string
+		Gateway *testGateway
+		CS      *testConsensusSet
+		Wallet  *testWallet
+		ERC20   *testERC20Info
+		Synced  bool // ERC20 initial sync finished
+		Loaded  bool
+		Module  string
+		Ready   bool
+		Status  string
+	}{
+		{
+			Name:   "loading",
+			Module: HealthModuleDaemon, Status: "loading",
+		},
+		{
+			Name: "loaded", Loaded: true,
+			Module: HealthModuleDaemon, Ready: true, Status: "loaded",
+		},
+		{
+			Name: "no peers", Loaded: true,
+			Gateway: &testGateway{},
+			Module:  HealthModuleGateway, Status: "connected to 0 peer(s)",
+		},
+		{
+			Name: "peers", Loaded: true,
+			Gateway: &testGateway{peers: map[modules.NetAddress]*rtypes.BlockHeight{"a:1": nil}},
+			Module:  HealthModuleGateway, Ready: true, Status: "connected to 1 peer(s)",
+		},
+		{
+			Name: "consensus syncing", Loaded: true,
+			CS:     &testConsensusSet{height: 5},
+			Module: HealthModuleConsensus, Status: "syncing at height 5",
+		},
+		{
+			Name: "consensus synced without gateway", Loaded: true,
+			CS:     &testConsensusSet{height: 5, synced: true},
+			Module: HealthModuleConsensus, Ready: true, Status: "synced at height 5",
+		},
+		{
+			Name: "consensus synced without peer heights", Loaded: true,
+			Gateway: &testGateway{peers: map[modules.NetAddress]*rtypes.BlockHeight{"a:1": nil}},
+			CS:      &testConsensusSet{height: 5, synced: true},
+			Module:  HealthModuleConsensus, Ready: true, Status: "synced at height 5",
+		},
+		{
+			Name: "consensus synced within delta", Loaded: true,
+			Gateway: &testGateway{peers: map[modules.NetAddress]*rtypes.BlockHeight{
+				"a:1": heightPtr(15), "b:1": heightPtr(3), "c:1": nil,
+			}},
+			CS:     &testConsensusSet{height: 5, synced: true},
+			Module: HealthModuleConsensus, Ready: true, Status: "synced at height 5",
+		},
+		{
+			Name: "consensus lagging behind", Loaded: true,
+			Gateway: &testGateway{peers: map[modules.NetAddress]*rtypes.BlockHeight{
+				"a:1": heightPtr(16), "b:1": heightPtr(3), "c:1": nil,
+			}},
+			CS:     &testConsensusSet{height: 5, synced: true},
+			Module: HealthModuleConsensus, Status: "lagging behind at height 5/16",
+		},
+		{
+			Name: "erc20 status failure", Loaded: true,
+			ERC20:  &testERC20Info{err: errors.New("offline")},
+			Module: HealthModuleERC20, Status: "failed to get sync status: offline",
+		},
+		{
+			Name: "erc20 syncing", Loaded: true,
+			ERC20:  &testERC20Info{status: &erc20types.ERC20SyncStatus{CurrentBlock: 100, HighestBlock: 100}},
+			Module: HealthModuleERC20, Status: "syncing at block 100/100",
+		},
+		{
+			Name: "erc20 lagging behind", Loaded: true, Synced: true,
+			ERC20:  &testERC20Info{status: &erc20types.ERC20SyncStatus{CurrentBlock: 100, HighestBlock: 111}},
+			Module: HealthModuleERC20, Status: "lagging behind at block 100/111",
+		},
+		{
+			Name: "erc20 synced", Loaded: true, Synced: true,
+			ERC20:  &testERC20Info{status: &erc20types.ERC20SyncStatus{CurrentBlock: 100, HighestBlock: 110}},
+			Module: HealthModuleERC20, Ready: true, Status: "synced at block 100",
+		},
+		{
+			Name: "wallet not initialized", Loaded: true,
+			Wallet: &testWallet{},
+			Module: HealthModuleWallet, Status: "not initialized",
+		},
+		{
+			Name: "wallet locked", Loaded: true,
+			Wallet: &testWallet{encrypted: true},
+			Module: HealthModuleWallet, Status: "locked",
+		},
+		{
+			Name: "wallet unlocked", Loaded: true,
+			Wallet: &testWallet{encrypted: true, unlocked: true},
+			Module: HealthModuleWallet, Ready: true, Status: "unlocked",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			hm := NewHealthMonitor(HealthMonitorOptions{MaxBlockDelta: 10, MinimumPeers: 1})
+			defer hm.Close()
+			if testCase.Gateway != nil {
+				hm.SetGateway(testCase.Gateway)
+				// don't rely on the timing of the background refresh
+				hm.refreshPeerHeights(testCase.Gateway)
+			}
+			if testCase.CS != nil {
+				hm.SetConsensusSet(testCase.CS)
+			}
+			if testCase.Wallet != nil {
+				hm.SetWallet(testCase.Wallet)
+			}
+			if testCase.ERC20 != nil {
+				hm.SetERC20(testCase.ERC20)
+				if testCase.Synced {
+					hm.MarkERC20Synced()
+				}
+			}
+			if testCase.Loaded {
+				hm.MarkLoaded()
+			}
+
+			code, health := getHealth(t, NewDaemonHealthHandler(hm))
+			if code != http.StatusOK {
+				t.Errorf("expected status code %d from the health endpoint, not %d", http.StatusOK, code)
+			}
+			module, ok := health.Modules[testCase.Module]
+			if !ok {
+				t.Fatalf("module %s is not reported: %v", testCase.Module, health.Modules)
+			}
+			if module.Ready != testCase.Ready || module.Status != testCase.Status {
+				t.Errorf("expected module %s to be %v (%q), not %v (%q)",
+					testCase.Module, testCase.Ready, testCase.Status, module.Ready, module.Status)
+			}
+			if health.Ready != testCase.Ready {
+				t.Errorf("expected the daemon to be ready: %v, not %v", testCase.Ready, health.Ready)
+			}
+
+			expectedCode := http.StatusServiceUnavailable
+			if testCase.Ready {
+				expectedCode = http.StatusOK
+			}
+			code, ready := getHealth(t, NewDaemonReadyHandler(hm))
+			if code != expectedCode {
+				t.Errorf("expected status code %d from the ready endpoint, not %d", expectedCode, code)
+			}
+			if ready.Ready != testCase.Ready {
+				t.Errorf("expected the ready endpoint to report %v, not %v", testCase.Ready, ready.Ready)
+			}
+		})
+	}
+}
+
+// blockingGateway is connected to peers which never respond to the height RPC,
+// until the release channel is closed
+type blockingGateway struct {
+	testGateway
+	release chan struct{}
+}
+
+func (g *blockingGateway) RPC(addr modules.NetAddress, name string, fn modules.RPCFunc) error {
+	<-g.release
+	return errors.New("peer did not respond")
+}
+
+func TestHealthStatusDoesNotWaitForPeers(t *testing.T) {
+	g := &blockingGateway{
+		testGateway: testGateway{peers: map[modules.NetAddress]*rtypes.BlockHeight{"a:1": heightPtr(16)}},
+		release:     make(chan struct{}),
+	}
+	defer close(g.release)
+	hm := NewHealthMonitor(HealthMonitorOptions{MaxBlockDelta: 10})
+	defer hm.Close()
+	hm.SetGateway(g)
+	hm.SetConsensusSet(&testConsensusSet{height: 5, synced: true})
+
+	done := make(chan DaemonHealthGET)
+	go func() {
+		done <- hm.Status()
+	}()
+	select {
+	case status := <-done:
+		module := status.Modules[HealthModuleConsensus]
+		if !module.Ready || module.Status != "synced at height 5" {
+			t.Errorf("expected the consensus set to be synced at height 5, not %v (%q)", module.Ready, module.Status)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("status is waiting for the peers of the gateway")
+	}
+}
+
+func TestHealthMonitorReportsHeight(t *testing.T) {
+	g := &testGateway{}
+	hm := NewHealthMonitor(HealthMonitorOptions{})
+	defer hm.Close()
+	hm.SetGateway(g)
+	if g.handler != nil {
+		t.Fatal("expected the height RPC to be registered only once the consensus set is registered")
+	}
+	hm.SetConsensusSet(&testConsensusSet{height: 42})
+	if g.handler == nil {
+		t.Fatal("expected the height RPC to be registered")
+	}
+
+	local, remote := net.Pipe()
+	defer local.Close()
+	go func() {
+		defer remote.Close()
+		g.handler(testPeerConn{Conn: remote})
+	}()
+	var height rtypes.BlockHeight
+	if err := siabin.ReadObject(local, &height, 16); err != nil {
+		t.Fatal("failed to read reported height:", err)
+	}
+	if height != 42 {
+		t.Errorf("expected reported height 42, not %d", height)
+	}
+}
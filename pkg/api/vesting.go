@@ -0,0 +1,163 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/threefoldtech/rivine/modules"
+	rapi "github.com/threefoldtech/rivine/pkg/api"
+	rtypes "github.com/threefoldtech/rivine/types"
+
+	tftypes "github.com/threefoldfoundation/tfchain/pkg/types"
+)
+
+type (
+	// VestingOutput is an unspent coin output using a vesting condition,
+	// with its value split into the part that is vested and the part that is still locked.
+	VestingOutput struct {
+		ID        rtypes.CoinOutputID         `json:"id"`
+		Value     rtypes.Currency             `json:"value"`
+		Condition rtypes.UnlockConditionProxy `json:"condition"`
+		Vested    rtypes.Currency             `json:"vested"`
+		Locked    rtypes.Currency             `json:"locked"`
+	}
+
+	// ConsensusVestingGET contains the vesting info of a single unspent coin output,
+	// as evaluated at the current block height and time.
+	ConsensusVestingGET struct {
+		Output    VestingOutput      `json:"output"`
+		Height    rtypes.BlockHeight `json:"height"`
+		Timestamp rtypes.Timestamp   `json:"timestamp"`
+	}
+
+	// ExplorerVestingGET contains all unspent vesting outputs of an address,
+	// as evaluated at the current block height and time.
+	ExplorerVestingGET struct {
+		Address   rtypes.UnlockHash  `json:"address"`
+		Outputs   []VestingOutput    `json:"outputs"`
+		Vested    rtypes.Currency    `json:"vested"`
+		Locked    rtypes.Currency    `json:"locked"`
+		Height    rtypes.BlockHeight `json:"height"`
+		Timestamp rtypes.Timestamp   `json:"timestamp"`
+	}
+)
+
+// NewVestingOutput creates the vesting info of a coin output at the given block height and time,
+// false is returned in case the coin output doesn't use a vesting condition.
+func NewVestingOutput(id rtypes.CoinOutputID, co rtypes.CoinOutput, height rtypes.BlockHeight, time rtypes.Timestamp) (VestingOutput, bool) {
+	vc, ok := co.Condition.Condition.(*tftypes.VestingCondition)
+	if !ok {
+		return VestingOutput{}, false
+	}
+	// an output can hold less than the total vested amount, after a partial spend
+	locked := vc.LockedAmount(height, time)
+	if co.Value.Cmp(locked) < 0 {
+		locked = co.Value
+	}
+	return VestingOutput{
+		ID:        id,
+		Value:     co.Value,
+		Condition: co.Condition,
+		Vested:    co.Value.Sub(locked),
+		Locked:    locked,
+	}, true
+}
+
+// RegisterConsensusVestingHTTPHandlers registers the handlers for the vesting Consensus HTTP endpoints.
+func RegisterConsensusVestingHTTPHandlers(router rapi.Router, cs modules.ConsensusSet) {
+	if router == nil {
+		panic("no router given")
+	}
+	if cs == nil {
+		panic("no ConsensusSet API given")
+	}
+	router.GET("/consensus/vesting/:id", NewConsensusVestingHandler(cs))
+}
+
+// RegisterExplorerVestingHTTPHandlers registers the handlers for the vesting Explorer HTTP endpoints.
+func RegisterExplorerVestingHTTPHandlers(router rapi.Router, cs modules.ConsensusSet, explorer modules.Explorer) {
+	if router == nil {
+		panic("no router given")
+	}
+	if cs == nil {
+		panic("no ConsensusSet API given")
+	}
+	if explorer == nil {
+		panic("no Explorer API given")
+	}
+	router.GET("/explorer/vesting/:address", NewExplorerVestingHandler(cs, explorer))
+}
+
+// NewConsensusVestingHandler creates a handler to handle GET requests to /consensus/vesting/:id.
+func NewConsensusVestingHandler(cs modules.ConsensusSet) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		var id rtypes.CoinOutputID
+		err := id.LoadString(ps.ByName("id"))
+		if err != nil {
+			rapi.WriteError(w, rapi.Error{Message: "invalid coin output id: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		co, err := cs.GetCoinOutput(id)
+		if err != nil {
+			rapi.WriteError(w, rapi.Error{Message: err.Error()}, http.StatusNoContent)
+			return
+		}
+		height, timestamp := cs.Height(), cs.CurrentBlock().Timestamp
+		output, ok := NewVestingOutput(id, co, height, timestamp)
+		if !ok {
+			rapi.WriteError(w, rapi.Error{Message: "coin output does not use a vesting condition"}, http.StatusBadRequest)
+			return
+		}
+		rapi.WriteJSON(w, ConsensusVestingGET{
+			Output:    output,
+			Height:    height,
+			Timestamp: timestamp,
+		})
+	}
+}
+
+// NewExplorerVestingHandler creates a handler to handle GET requests to /explorer/vesting/:address.
+func NewExplorerVestingHandler(cs modules.ConsensusSet, explorer modules.Explorer) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		var address rtypes.UnlockHash
+		err := address.LoadString(ps.ByName("address"))
+		if err != nil {
+			rapi.WriteError(w, rapi.Error{Message: "invalid address: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		resp := ExplorerVestingGET{
+			Address:   address,
+			Outputs:   []VestingOutput{},
+			Height:    cs.Height(),
+			Timestamp: cs.CurrentBlock().Timestamp,
+		}
+		for _, txid := range explorer.UnlockHash(address) {
+			block, _, ok := explorer.Transaction(txid)
+			if !ok {
+				continue
+			}
+			for _, txn := range block.Transactions {
+				if txn.ID() != txid {
+					continue
+				}
+				for idx, co := range txn.CoinOutputs {
+					if co.Condition.UnlockHash().Cmp(address) != 0 {
+						continue
+					}
+					id := txn.CoinOutputID(uint64(idx))
+					if _, err := cs.GetCoinOutput(id); err != nil {
+						continue // output is already spent
+					}
+					output, ok := NewVestingOutput(id, co, resp.Height, resp.Timestamp)
+					if !ok {
+						continue
+					}
+					resp.Outputs = append(resp.Outputs, output)
+					resp.Vested = resp.Vested.Add(output.Vested)
+					resp.Locked = resp.Locked.Add(output.Locked)
+				}
+			}
+		}
+		rapi.WriteJSON(w, resp)
+	}
+}
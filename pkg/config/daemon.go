@@ -13,16 +13,17 @@ type DaemonNetworkConfig struct {
 
 // GetStandardDaemonNetworkConfig returns the standard network config for the daemon
 func GetStandardDaemonNetworkConfig() DaemonNetworkConfig {
+	mintCondition := types.NewMultiSignatureCondition(types.UnlockHashSlice{
+		unlockHashFromHex("01434535fd01243c02c277cd58d71423163767a575a8ae44e15807bf545e4a8456a5c4afabad51"),
+		unlockHashFromHex("01334cf68f312026ff9df84fc023558db8624bedd717adcc9edc6900488cf6df54ac8e3d1c89a8"),
+		unlockHashFromHex("0149a5496fea27315b7db6251e5dfda23bc9d4bf677c5a5c2d70f1382c44357197d8453d9dfa32"),
+	}, 2)
 	return DaemonNetworkConfig{
-		GenesisMintingCondition: types.NewCondition(types.NewMultiSignatureCondition(types.UnlockHashSlice{
-			unlockHashFromHex("01434535fd01243c02c277cd58d71423163767a575a8ae44e15807bf545e4a8456a5c4afabad51"),
-			unlockHashFromHex("01334cf68f312026ff9df84fc023558db8624bedd717adcc9edc6900488cf6df54ac8e3d1c89a8"),
-			unlockHashFromHex("0149a5496fea27315b7db6251e5dfda23bc9d4bf677c5a5c2d70f1382c44357197d8453d9dfa32"),
-		}, 2)),
-		// TODO: define final address
-		FoundationPoolAddress: unlockHashFromHex("017267221ef1947bb18506e390f1f9446b995acfb6d08d8e39508bb974d9830b8cb8fdca788e34"),
-		// TODO: define final address
-		ERC20FeePoolAddress: unlockHashFromHex("017267221ef1947bb18506e390f1f9446b995acfb6d08d8e39508bb974d9830b8cb8fdca788e34"),
+		GenesisMintingCondition: types.NewCondition(mintCondition),
+		// 3Bot and ERC20 fees are paid to the foundation multisig wallet,
+		// the same wallet which is used to mint coins
+		FoundationPoolAddress: mintCondition.UnlockHash(),
+		ERC20FeePoolAddress:   mintCondition.UnlockHash(),
 	}
 }
 
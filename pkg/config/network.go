@@ -0,0 +1,207 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+type (
+	// NetworkDefinition defines a custom (private) tfchain network,
+	// allowing a network other than the standard, test and dev networks to be used,
+	// without having to patch the code. It is loaded from a (genesis) JSON file.
+	NetworkDefinition struct {
+		// Name of the network, cannot be the name of one of the official networks.
+		Name string `json:"name"`
+
+		// Constants of the network, values which aren't defined
+		// default to the constants used for the devnet.
+		Constants NetworkConstantsDefinition `json:"constants"`
+		// Genesis defines the outputs created in the genesis block.
+		Genesis NetworkGenesisDefinition `json:"genesis"`
+
+		// AuthCondition is the genesis condition used to authorize addresses.
+		AuthCondition types.UnlockConditionProxy `json:"authcondition"`
+		// MintingCondition is the genesis condition used to mint coins.
+		MintingCondition types.UnlockConditionProxy `json:"mintingcondition"`
+		// FoundationPoolAddress is the address receiving the 3Bot fees.
+		FoundationPoolAddress types.UnlockHash `json:"foundationpooladdress"`
+		// ERC20FeePoolAddress is the address receiving the ERC20 fees.
+		ERC20FeePoolAddress types.UnlockHash `json:"erc20feepooladdress"`
+
+		// BootstrapPeers are the default bootstrap peers of the network.
+		BootstrapPeers []modules.NetAddress `json:"bootstrappeers,omitempty"`
+		// ActivationHeights define the block heights since which
+		// the validators of features added during the lifetime of tfchain are active.
+		ActivationHeights NetworkActivationHeights `json:"activationheights"`
+
+		// ERC20NetworkName is the ethereum network linked to this network,
+		// defaults to rinkeby when not defined.
+		ERC20NetworkName string `json:"erc20networkname,omitempty"`
+		// Explorers are the public explorers of the network, used by light clients.
+		Explorers []string `json:"explorers,omitempty"`
+	}
+
+	// NetworkConstantsDefinition defines the chain constants of a custom network.
+	NetworkConstantsDefinition struct {
+		BlockFrequency          types.BlockHeight          `json:"blockfrequency"`
+		MaturityDelay           types.BlockHeight          `json:"maturitydelay"`
+		MedianTimestampWindow   uint64                     `json:"mediantimestampwindow"`
+		TargetWindow            types.BlockHeight          `json:"targetwindow"`
+		MaxAdjustmentUp         *big.Rat                   `json:"maxadjustmentup"`
+		MaxAdjustmentDown       *big.Rat                   `json:"maxadjustmentdown"`
+		FutureThreshold         types.Timestamp            `json:"futurethreshold"`
+		ExtremeFutureThreshold  types.Timestamp            `json:"extremefuturethreshold"`
+		StakeModifierDelay      types.BlockHeight          `json:"stakemodifierdelay"`
+		BlockStakeAging         uint64                     `json:"blockstakeaging"`
+		BlockCreatorFee         types.Currency             `json:"blockcreatorfee"`
+		MinimumTransactionFee   types.Currency             `json:"minimumtransactionfee"`
+		TransactionFeeCondition types.UnlockConditionProxy `json:"transactionfeecondition"`
+		GenesisTimestamp        types.Timestamp            `json:"genesistimestamp"`
+	}
+
+	// NetworkGenesisDefinition defines the outputs created in the genesis block of a custom network.
+	NetworkGenesisDefinition struct {
+		CoinOutputs       []types.CoinOutput       `json:"coinoutputs"`
+		BlockStakeOutputs []types.BlockStakeOutput `json:"blockstakeoutputs"`
+	}
+
+	// NetworkActivationHeights defines the block heights since which the validators
+	// of features added during the lifetime of tfchain are active for a custom network.
+	NetworkActivationHeights struct {
+		MinerFeesRequired          types.BlockHeight `json:"minerfeesrequired"`
+		LegacyTransactionsDisabled types.BlockHeight `json:"legacytransactionsdisabled"`
+		// MinerFeesPerSize is optional, miner fees are not size-aware when it isn't defined.
+		MinerFeesPerSize *types.BlockHeight `json:"minerfeespersize,omitempty"`
+		// Vesting is optional, the vesting condition is not available when it isn't defined.
+		Vesting *types.BlockHeight `json:"vesting,omitempty"`
+	}
+)
+
+// LoadNetworkDefinition loads and validates a custom network definition from a (genesis) JSON file.
+func LoadNetworkDefinition(path string) (*NetworkDefinition, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open genesis file %s: %v", path, err)
+	}
+	defer file.Close()
+
+	def := DefaultNetworkDefinition()
+	decoder := json.NewDecoder(file)
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(def)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode genesis file %s: %v", path, err)
+	}
+	err = def.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid genesis file %s: %v", path, err)
+	}
+	return def, nil
+}
+
+// DefaultNetworkDefinition returns a network definition,
+// with all its (optional) constants set to the values used for the devnet.
+func DefaultNetworkDefinition() *NetworkDefinition {
+	cts := GetDevnetGenesis()
+	return &NetworkDefinition{
+		Constants: NetworkConstantsDefinition{
+			BlockFrequency:          cts.BlockFrequency,
+			MaturityDelay:           cts.MaturityDelay,
+			MedianTimestampWindow:   cts.MedianTimestampWindow,
+			TargetWindow:            cts.TargetWindow,
+			MaxAdjustmentUp:         cts.MaxAdjustmentUp,
+			MaxAdjustmentDown:       cts.MaxAdjustmentDown,
+			FutureThreshold:         cts.FutureThreshold,
+			ExtremeFutureThreshold:  cts.ExtremeFutureThreshold,
+			StakeModifierDelay:      cts.StakeModifierDelay,
+			BlockStakeAging:         cts.BlockStakeAging,
+			BlockCreatorFee:         cts.BlockCreatorFee,
+			MinimumTransactionFee:   cts.MinimumTransactionFee,
+			TransactionFeeCondition: cts.TransactionFeeCondition,
+			GenesisTimestamp:        cts.GenesisTimestamp,
+		},
+	}
+}
+
+// Validate the network definition, ensuring all required properties are defined.
+func (def *NetworkDefinition) Validate() error {
+	switch def.Name {
+	case "":
+		return errors.New("network name is required")
+	case NetworkNameStandard, NetworkNameTest, NetworkNameDev:
+		return fmt.Errorf("network name %q is reserved for an official network", def.Name)
+	}
+	if len(def.Genesis.CoinOutputs) == 0 {
+		return errors.New("at least one genesis coin output is required")
+	}
+	if len(def.Genesis.BlockStakeOutputs) == 0 {
+		return errors.New("at least one genesis block stake output is required")
+	}
+	if def.AuthCondition.ConditionType() == types.ConditionTypeNil {
+		return errors.New("a (non-nil) auth condition is required")
+	}
+	if def.MintingCondition.ConditionType() == types.ConditionTypeNil {
+		return errors.New("a (non-nil) minting condition is required")
+	}
+	if def.FoundationPoolAddress.Type == types.UnlockTypeNil {
+		return errors.New("a (non-nil) foundation pool address is required")
+	}
+	if def.ERC20FeePoolAddress.Type == types.UnlockTypeNil {
+		return errors.New("a (non-nil) ERC20 fee pool address is required")
+	}
+	for idx, addr := range def.BootstrapPeers {
+		if err := addr.IsStdValid(); err != nil {
+			return fmt.Errorf("invalid bootstrap peer #%d %s: %v", idx, addr, err)
+		}
+	}
+	constants := def.ChainConstants()
+	return constants.Validate()
+}
+
+// ChainConstants returns the chain constants of the custom network.
+func (def *NetworkDefinition) ChainConstants() types.ChainConstants {
+	cfg := GetDevnetGenesis()
+
+	cfg.BlockFrequency = def.Constants.BlockFrequency
+	cfg.MaturityDelay = def.Constants.MaturityDelay
+	cfg.MedianTimestampWindow = def.Constants.MedianTimestampWindow
+	cfg.TargetWindow = def.Constants.TargetWindow
+	cfg.MaxAdjustmentUp = def.Constants.MaxAdjustmentUp
+	cfg.MaxAdjustmentDown = def.Constants.MaxAdjustmentDown
+	cfg.FutureThreshold = def.Constants.FutureThreshold
+	cfg.ExtremeFutureThreshold = def.Constants.ExtremeFutureThreshold
+	cfg.StakeModifierDelay = def.Constants.StakeModifierDelay
+	cfg.BlockStakeAging = def.Constants.BlockStakeAging
+	cfg.BlockCreatorFee = def.Constants.BlockCreatorFee
+	cfg.MinimumTransactionFee = def.Constants.MinimumTransactionFee
+	cfg.TransactionFeeCondition = def.Constants.TransactionFeeCondition
+	cfg.GenesisTimestamp = def.Constants.GenesisTimestamp
+
+	cfg.GenesisCoinDistribution = def.Genesis.CoinOutputs
+	cfg.GenesisBlockStakeAllocation = def.Genesis.BlockStakeOutputs
+
+	return cfg
+}
+
+// DaemonNetworkConfig returns the daemon network config of the custom network.
+func (def *NetworkDefinition) DaemonNetworkConfig() DaemonNetworkConfig {
+	return DaemonNetworkConfig{
+		GenesisMintingCondition: def.MintingCondition,
+		FoundationPoolAddress:   def.FoundationPoolAddress,
+		ERC20FeePoolAddress:     def.ERC20FeePoolAddress,
+	}
+}
+
+// ERC20Network returns the name of the ethereum network linked to the custom network.
+func (def *NetworkDefinition) ERC20Network() string {
+	if def.ERC20NetworkName == "" {
+		return "rinkeby"
+	}
+	return def.ERC20NetworkName
+}
@@ -0,0 +1,194 @@
+package config
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/threefoldtech/rivine/types"
+)
+
+const testUnlockHash = "015a080a9259b9d4aaa550e2156f49b1a79a64c7ea463d810d4493e8242e6791584fbdac553e6f"
+
+// testNetworkDefinition returns a valid network definition as a JSON string,
+// with the given properties (JSON key-value pairs) appended or replaced
+func testNetworkDefinition(overwrites map[string]string) string {
+	condition := `{"type": 1, "data": {"unlockhash": "` + testUnlockHash + `"}}`
+	properties := []struct{ Key, Value string }{
+		{"name", `"mynet"`},
+		{"constants", `{"blockfrequency": 5, "maxadjustmentup": "6/5", "minimumtransactionfee": "100000000"}`},
+		{"genesis", `{
+			"coinoutputs": [{"value": "100000000000000000", "condition": ` + condition + `}],
+			"blockstakeoutputs": [{"value": "3000", "condition": ` + condition + `}]
+		}`},
+		{"authcondition", condition},
+		{"mintingcondition", condition},
+		{"foundationpooladdress", `"` + testUnlockHash + `"`},
+		{"erc20feepooladdress", `"` + testUnlockHash + `"`},
+		{"bootstrappeers", `["localhost:23112"]`},
+		{"activationheights", `{"minerfeesrequired": 10, "legacytransactionsdisabled": 20, "minerfeespersize": 30, "vesting": 40}`},
+		{"explorers", `["http://localhost:23110"]`},
+	}
+	var pairs []string
+	for _, property := range properties {
+		value := property.Value
+		if overwrite, ok := overwrites[property.Key]; ok {
+			value = overwrite
+			delete(overwrites, property.Key)
+			if value == "" {
+				continue // property is removed
+			}
+		}
+		pairs = append(pairs, `"`+property.Key+`": `+value)
+	}
+	for key, value := range overwrites {
+		pairs = append(pairs, `"`+key+`": `+value)
+	}
+	return "{" + strings.Join(pairs, ",\n") + "}"
+}
+
+func loadTestNetworkDefinition(t *testing.T, content string) (*NetworkDefinition, error) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "tfchain-network")
+	if err != nil {
+		t.Fatal("failed to create temp dir:", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "genesis.json")
+	if err = ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal("failed to write genesis file:", err)
+	}
+	return LoadNetworkDefinition(path)
+}
+
+func TestLoadNetworkDefinitionInvalid(t *testing.T) {
+	testCases := []struct {
+		Name       string
+		Overwrites map[string]string
+		Error      string
+	}{
+		{"unknown property", map[string]string{"foo": `"bar"`}, `unknown field "foo"`},
+		{"unknown constant", map[string]string{"constants": `{"blockfrequence": 5}`}, `unknown field "blockfrequence"`},
+		{"unknown activation height", map[string]string{"activationheights": `{"minerfees": 5}`}, `unknown field "minerfees"`},
+		{"invalid JSON", map[string]string{"name": `mynet`}, "failed to decode"},
+		{"invalid address", map[string]string{"foundationpooladdress": `"01abc"`}, "failed to decode"},
+		{"missing name", map[string]string{"name": ""}, "network name is required"},
+		{"standard name", map[string]string{"name": `"standard"`}, "reserved"},
+		{"testnet name", map[string]string{"name": `"testnet"`}, "reserved"},
+		{"devnet name", map[string]string{"name": `"devnet"`}, "reserved"},
+		{"no coin outputs", map[string]string{"genesis": `{"blockstakeoutputs": [{"value": "1", "condition": {}}]}`}, "genesis coin output"},
+		{"no block stake outputs", map[string]string{"genesis": `{"coinoutputs": [{"value": "1", "condition": {}}]}`}, "genesis block stake output"},
+		{"missing auth condition", map[string]string{"authcondition": ""}, "auth condition"},
+		{"missing minting condition", map[string]string{"mintingcondition": ""}, "minting condition"},
+		{"missing foundation pool address", map[string]string{"foundationpooladdress": ""}, "foundation pool address"},
+		{"missing ERC20 fee pool address", map[string]string{"erc20feepooladdress": ""}, "ERC20 fee pool address"},
+		{"invalid bootstrap peer", map[string]string{"bootstrappeers": `["localhost"]`}, "invalid bootstrap peer #0"},
+		{"invalid genesis timestamp", map[string]string{"constants": `{"genesistimestamp": 1000}`}, "Invalid genesis timestamp"},
+	}
+	for _, testCase := range testCases {
+		_, err := loadTestNetworkDefinition(t, testNetworkDefinition(testCase.Overwrites))
+		if err == nil {
+			t.Errorf("%s: expected network definition to be invalid", testCase.Name)
+			continue
+		}
+		if !strings.Contains(err.Error(), testCase.Error) {
+			t.Errorf("%s: expected error to contain %q: %v", testCase.Name, testCase.Error, err)
+		}
+	}
+
+	if _, err := LoadNetworkDefinition(filepath.Join(os.TempDir(), "tfchain-missing-genesis.json")); err == nil {
+		t.Error("expected an error for a missing genesis file")
+	}
+}
+
+func TestNetworkDefinitionChainConstants(t *testing.T) {
+	def, err := loadTestNetworkDefinition(t, testNetworkDefinition(map[string]string{}))
+	if err != nil {
+		t.Fatal("failed to load valid network definition:", err)
+	}
+	if def.Name != "mynet" {
+		t.Errorf("unexpected network name: %s", def.Name)
+	}
+	if len(def.BootstrapPeers) != 1 || def.BootstrapPeers[0] != "localhost:23112" {
+		t.Errorf("unexpected bootstrap peers: %v", def.BootstrapPeers)
+	}
+	heights := def.ActivationHeights
+	if heights.MinerFeesRequired != 10 || heights.LegacyTransactionsDisabled != 20 ||
+		heights.MinerFeesPerSize == nil || *heights.MinerFeesPerSize != 30 || heights.Vesting == nil || *heights.Vesting != 40 {
+		t.Errorf("unexpected activation heights: %+v", heights)
+	}
+	if network := def.ERC20Network(); network != "rinkeby" {
+		t.Errorf("expected the rinkeby ERC20 network by default, not %s", network)
+	}
+
+	var uh types.UnlockHash
+	if err = uh.LoadString(testUnlockHash); err != nil {
+		t.Fatal(err)
+	}
+	devnet := GetDevnetGenesis()
+	cts := def.ChainConstants()
+	// defined constants
+	if cts.BlockFrequency != 5 {
+		t.Errorf("unexpected block frequency: %d", cts.BlockFrequency)
+	}
+	if cts.MaxAdjustmentUp.Cmp(big.NewRat(6, 5)) != 0 {
+		t.Errorf("unexpected max adjustment up: %s", cts.MaxAdjustmentUp)
+	}
+	if cts.MinimumTransactionFee.Cmp64(100000000) != 0 {
+		t.Errorf("unexpected minimum transaction fee: %s", cts.MinimumTransactionFee.String())
+	}
+	// constants defaulting to the devnet
+	if cts.MaturityDelay != devnet.MaturityDelay || cts.TargetWindow != devnet.TargetWindow ||
+		cts.MaxAdjustmentDown.Cmp(devnet.MaxAdjustmentDown) != 0 || cts.BlockCreatorFee.Cmp(devnet.BlockCreatorFee) != 0 ||
+		cts.GenesisTimestamp != devnet.GenesisTimestamp || cts.CurrencyUnits.OneCoin.Cmp(devnet.CurrencyUnits.OneCoin) != 0 {
+		t.Error("expected undefined constants to default to the devnet constants")
+	}
+	// genesis outputs
+	if len(cts.GenesisCoinDistribution) != 1 || cts.GenesisCoinDistribution[0].Value.Cmp64(100000000000000000) != 0 ||
+		cts.GenesisCoinDistribution[0].Condition.UnlockHash() != uh {
+		t.Errorf("unexpected genesis coin distribution: %v", cts.GenesisCoinDistribution)
+	}
+	if len(cts.GenesisBlockStakeAllocation) != 1 || cts.GenesisBlockStakeAllocation[0].Value.Cmp64(3000) != 0 ||
+		cts.GenesisBlockStakeAllocation[0].Condition.UnlockHash() != uh {
+		t.Errorf("unexpected genesis block stake allocation: %v", cts.GenesisBlockStakeAllocation)
+	}
+	if err = cts.Validate(); err != nil {
+		t.Error("expected valid chain constants:", err)
+	}
+
+	daemonCfg := def.DaemonNetworkConfig()
+	if daemonCfg.GenesisMintingCondition.UnlockHash() != uh {
+		t.Errorf("unexpected minting condition: %v", daemonCfg.GenesisMintingCondition)
+	}
+	if daemonCfg.FoundationPoolAddress != uh || daemonCfg.ERC20FeePoolAddress != uh {
+		t.Errorf("unexpected pool addresses: %s, %s", daemonCfg.FoundationPoolAddress, daemonCfg.ERC20FeePoolAddress)
+	}
+}
+
+func TestNetworkDefinitionOptionalProperties(t *testing.T) {
+	def, err := loadTestNetworkDefinition(t, testNetworkDefinition(map[string]string{
+		"constants":         "",
+		"bootstrappeers":    "",
+		"activationheights": "",
+		"explorers":         "",
+		"erc20networkname":  `"ropsten"`,
+	}))
+	if err != nil {
+		t.Fatal("failed to load minimal network definition:", err)
+	}
+	if def.ActivationHeights.MinerFeesRequired != 0 || def.ActivationHeights.MinerFeesPerSize != nil || def.ActivationHeights.Vesting != nil {
+		t.Errorf("unexpected activation heights: %+v", def.ActivationHeights)
+	}
+	if network := def.ERC20Network(); network != "ropsten" {
+		t.Errorf("unexpected ERC20 network: %s", network)
+	}
+	devnet := GetDevnetGenesis()
+	cts := def.ChainConstants()
+	if cts.BlockFrequency != devnet.BlockFrequency || cts.MinimumTransactionFee.Cmp(devnet.MinimumTransactionFee) != 0 ||
+		cts.MaxAdjustmentUp.Cmp(devnet.MaxAdjustmentUp) != 0 {
+		t.Error("expected all constants to default to the devnet constants")
+	}
+}
@@ -0,0 +1,232 @@
+package erc20
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	erc20types "github.com/threefoldtech/rivine-extension-erc20/types"
+	"github.com/threefoldtech/rivine/types"
+)
+
+type (
+	// Withdrawal defines an ERC20 withdrawal (of TFT from the ERC20 bridge contract)
+	// as observed by a LocalERC20TransactionValidator.
+	Withdrawal struct {
+		// BlockID is optional, and is only checked if defined by both the
+		// withdrawal and the ERC20 Coin Creation Transaction.
+		BlockID erc20types.ERC20Hash    `json:"blockid"`
+		TxID    erc20types.ERC20Hash    `json:"txid"`
+		Address erc20types.ERC20Address `json:"address"`
+		Amount  types.Currency          `json:"amount"`
+	}
+
+	// LocalERC20TransactionValidator is an ERC20TransactionValidator
+	// which validates ERC20 withdrawals against a local list of observed withdrawals,
+	// rather than against the Ethereum network, allowing ERC20 Coin Creation Transactions
+	// to be used without network access, e.g. on a local devnet or in tests.
+	//
+	// The list can be defined as a JSON file, which is reloaded when it changes,
+	// and/or be extended using AddWithdrawal (e.g. through the HTTP API),
+	// in which case the file is updated as well.
+	LocalERC20TransactionValidator struct {
+		path    string
+		modTime time.Time
+
+		withdrawals map[erc20types.ERC20Hash]Withdrawal
+
+		mu sync.Mutex
+	}
+)
+
+var (
+	_ erc20types.ERC20TransactionValidator = (*LocalERC20TransactionValidator)(nil)
+)
+
+// NewLocalERC20TransactionValidator creates a new LocalERC20TransactionValidator,
+// using the JSON file at the given path as its list of observed withdrawals.
+// The file doesn't have to exist yet, and no file is used at all if the path is empty.
+func NewLocalERC20TransactionValidator(path string) (*LocalERC20TransactionValidator, error) {
+	lv := &LocalERC20TransactionValidator{
+		path:        path,
+		withdrawals: make(map[erc20types.ERC20Hash]Withdrawal),
+	}
+	err := lv.reload()
+	if err != nil {
+		return nil, err
+	}
+	return lv, nil
+}
+
+// ValidateWithdrawTx implements ERC20TransactionValidator.ValidateWithdrawTx,
+// validating the withdrawal against the local list of observed withdrawals.
+func (lv *LocalERC20TransactionValidator) ValidateWithdrawTx(blockID, txID erc20types.ERC20Hash, expectedAddress erc20types.ERC20Address, expectedAmount types.Currency) error {
+	lv.mu.Lock()
+	defer lv.mu.Unlock()
+
+	err := lv.reload()
+	if err != nil {
+		return err
+	}
+	w, ok := lv.withdrawals[txID]
+	if !ok {
+		return fmt.Errorf("Withdraw tx validation failed: no matching withdraw event found - invalid tx ID %s", txID.String())
+	}
+	if (blockID != erc20types.ERC20Hash{}) && (w.BlockID != erc20types.ERC20Hash{}) && blockID != w.BlockID {
+		return fmt.Errorf("Withdraw tx validation failed: invalid block ID. Want block ID %s, got block ID %s", w.BlockID.String(), blockID.String())
+	}
+	if w.Address != expectedAddress {
+		return fmt.Errorf("Withdraw tx validation failed: invalid receiving address. Want address %s, got address %s", w.Address.String(), expectedAddress.String())
+	}
+	if !w.Amount.Equals(expectedAmount) {
+		return fmt.Errorf("Withdraw tx validation failed: invalid amount. Want %s, got %s", w.Amount.String(), expectedAmount.String())
+	}
+	return nil
+}
+
+// GetStatus implements ERC20InfoAPI.GetStatus,
+// reporting the amount of observed withdrawals as the amount of (synced) blocks.
+func (lv *LocalERC20TransactionValidator) GetStatus() (*erc20types.ERC20SyncStatus, error) {
+	lv.mu.Lock()
+	defer lv.mu.Unlock()
+	n := uint64(len(lv.withdrawals))
+	return &erc20types.ERC20SyncStatus{
+		CurrentBlock: n,
+		HighestBlock: n,
+	}, nil
+}
+
+// GetBalanceInfo implements ERC20InfoAPI.GetBalanceInfo,
+// returning an empty balance, as there is no bridge contract.
+func (lv *LocalERC20TransactionValidator) GetBalanceInfo() (*erc20types.ERC20BalanceInfo, error) {
+	return &erc20types.ERC20BalanceInfo{}, nil
+}
+
+// Wait implements ERC20TransactionValidator.Wait,
+// returning immediately as there is no network to sync with.
+func (lv *LocalERC20TransactionValidator) Wait(_ context.Context) error {
+	return nil
+}
+
+// Withdrawals returns all observed withdrawals.
+func (lv *LocalERC20TransactionValidator) Withdrawals() ([]Withdrawal, error) {
+	lv.mu.Lock()
+	defer lv.mu.Unlock()
+
+	err := lv.reload()
+	if err != nil {
+		return nil, err
+	}
+	withdrawals := make([]Withdrawal, 0, len(lv.withdrawals))
+	for _, w := range lv.withdrawals {
+		withdrawals = append(withdrawals, w)
+	}
+	return withdrawals, nil
+}
+
+// AddWithdrawal adds a withdrawal to the list of observed withdrawals,
+// storing it in the file of the validator if it uses one.
+func (lv *LocalERC20TransactionValidator) AddWithdrawal(w Withdrawal) error {
+	if (w.TxID == erc20types.ERC20Hash{}) {
+		return errors.New("withdrawal requires a transaction ID")
+	}
+	if w.Amount.IsZero() {
+		return errors.New("withdrawal requires a non-zero amount")
+	}
+
+	lv.mu.Lock()
+	defer lv.mu.Unlock()
+
+	err := lv.reload()
+	if err != nil {
+		return err
+	}
+	if _, ok := lv.withdrawals[w.TxID]; ok {
+		return fmt.Errorf("withdrawal with tx ID %s already exists", w.TxID.String())
+	}
+	lv.withdrawals[w.TxID] = w
+	return lv.save()
+}
+
+// reload the withdrawals from the file, if it changed since it was last loaded.
+// The mutex has to be locked by the caller.
+func (lv *LocalERC20TransactionValidator) reload() error {
+	if lv.path == "" {
+		return nil
+	}
+	stat, err := os.Stat(lv.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if stat.ModTime().Equal(lv.modTime) {
+		return nil // nothing changed
+	}
+
+	file, err := os.Open(lv.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	var withdrawals []Withdrawal
+	err = json.NewDecoder(file).Decode(&withdrawals)
+	if err != nil {
+		return fmt.Errorf("failed to decode ERC20 withdrawals file %s: %v", lv.path, err)
+	}
+	lv.withdrawals = make(map[erc20types.ERC20Hash]Withdrawal, len(withdrawals))
+	for _, w := range withdrawals {
+		lv.withdrawals[w.TxID] = w
+	}
+	lv.modTime = stat.ModTime()
+	return nil
+}
+
+// save the withdrawals to the file, if one is used.
+// The mutex has to be locked by the caller.
+func (lv *LocalERC20TransactionValidator) save() error {
+	if lv.path == "" {
+		return nil
+	}
+	withdrawals := make([]Withdrawal, 0, len(lv.withdrawals))
+	for _, w := range lv.withdrawals {
+		withdrawals = append(withdrawals, w)
+	}
+	// write to a temporary file in the same directory first,
+	// such that other readers never see a partial file
+	file, err := ioutil.TempFile(filepath.Dir(lv.path), filepath.Base(lv.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := file.Name()
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	err = encoder.Encode(withdrawals)
+	if err == nil {
+		// the file is shared with other nodes, TempFile creates it as private
+		err = file.Chmod(0644)
+	}
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, lv.path)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	stat, err := os.Stat(lv.path)
+	if err != nil {
+		return err
+	}
+	lv.modTime = stat.ModTime()
+	return nil
+}
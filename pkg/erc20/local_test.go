@@ -0,0 +1,188 @@
+package erc20
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	erc20types "github.com/threefoldtech/rivine-extension-erc20/types"
+	"github.com/threefoldtech/rivine/types"
+)
+
+func newTestWithdrawal(id byte, amount uint64) Withdrawal {
+	w := Withdrawal{Amount: types.NewCurrency64(amount)}
+	w.TxID[0] = id
+	w.Address[0] = id
+	return w
+}
+
+func newTestWithdrawalsDir(t *testing.T) (string, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "tfchain-erc20")
+	if err != nil {
+		t.Fatal("failed to create temp dir:", err)
+	}
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+// writeWithdrawals writes the withdrawals file, with the given modification time
+func writeWithdrawals(t *testing.T, path string, modTime time.Time, withdrawals ...Withdrawal) {
+	t.Helper()
+	b, err := json.Marshal(withdrawals)
+	if err != nil {
+		t.Fatal("failed to encode withdrawals:", err)
+	}
+	if err = ioutil.WriteFile(path, b, 0644); err != nil {
+		t.Fatal("failed to write withdrawals:", err)
+	}
+	if err = os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal("failed to set modification time:", err)
+	}
+}
+
+func TestLocalValidatorReload(t *testing.T) {
+	dir, cleanup := newTestWithdrawalsDir(t)
+	defer cleanup()
+	path := filepath.Join(dir, "withdrawals.json")
+
+	// the file doesn't have to exist yet
+	lv, err := NewLocalERC20TransactionValidator(path)
+	if err != nil {
+		t.Fatal("failed to create validator:", err)
+	}
+	w1, w2 := newTestWithdrawal(1, 100), newTestWithdrawal(2, 200)
+	if err = lv.ValidateWithdrawTx(erc20types.ERC20Hash{}, w1.TxID, w1.Address, w1.Amount); err == nil {
+		t.Error("expected unknown withdrawal to be invalid")
+	}
+
+	modTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	writeWithdrawals(t, path, modTime, w1)
+	if err = lv.ValidateWithdrawTx(erc20types.ERC20Hash{}, w1.TxID, w1.Address, w1.Amount); err != nil {
+		t.Error("expected withdrawal of created file to be valid:", err)
+	}
+
+	// the file isn't reloaded as long as its modification time is unchanged
+	writeWithdrawals(t, path, modTime, w1, w2)
+	if err = lv.ValidateWithdrawTx(erc20types.ERC20Hash{}, w2.TxID, w2.Address, w2.Amount); err == nil {
+		t.Error("expected file not to be reloaded while its modification time is unchanged")
+	}
+	writeWithdrawals(t, path, modTime.Add(time.Second), w2)
+	if err = lv.ValidateWithdrawTx(erc20types.ERC20Hash{}, w2.TxID, w2.Address, w2.Amount); err != nil {
+		t.Error("expected withdrawal of changed file to be valid:", err)
+	}
+	if err = lv.ValidateWithdrawTx(erc20types.ERC20Hash{}, w1.TxID, w1.Address, w1.Amount); err == nil {
+		t.Error("expected withdrawal removed from the file to be invalid")
+	}
+
+	// an invalid file is reported
+	if err = ioutil.WriteFile(path, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chtimes(path, modTime.Add(2*time.Second), modTime.Add(2*time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = lv.Withdrawals(); err == nil {
+		t.Error("expected an error for an invalid withdrawals file")
+	}
+}
+
+func TestLocalValidatorAddWithdrawal(t *testing.T) {
+	dir, cleanup := newTestWithdrawalsDir(t)
+	defer cleanup()
+	path := filepath.Join(dir, "withdrawals.json")
+
+	lv, err := NewLocalERC20TransactionValidator(path)
+	if err != nil {
+		t.Fatal("failed to create validator:", err)
+	}
+	if err = lv.AddWithdrawal(Withdrawal{Amount: types.NewCurrency64(1)}); err == nil {
+		t.Error("expected withdrawal without tx ID to be refused")
+	}
+	if err = lv.AddWithdrawal(newTestWithdrawal(1, 0)); err == nil {
+		t.Error("expected withdrawal without amount to be refused")
+	}
+
+	w := newTestWithdrawal(1, 100)
+	w.BlockID[0] = 42
+	if err = lv.AddWithdrawal(w); err != nil {
+		t.Fatal("failed to add withdrawal:", err)
+	}
+	if err = lv.AddWithdrawal(w); err == nil {
+		t.Error("expected duplicate withdrawal to be refused")
+	}
+
+	testCases := []struct {
+		Name    string
+		BlockID erc20types.ERC20Hash
+		TxID    erc20types.ERC20Hash
+		Address erc20types.ERC20Address
+		Amount  types.Currency
+		Valid   bool
+	}{
+		{"valid", w.BlockID, w.TxID, w.Address, w.Amount, true},
+		{"no block ID", erc20types.ERC20Hash{}, w.TxID, w.Address, w.Amount, true},
+		{"invalid block ID", erc20types.ERC20Hash{1}, w.TxID, w.Address, w.Amount, false},
+		{"invalid tx ID", w.BlockID, erc20types.ERC20Hash{2}, w.Address, w.Amount, false},
+		{"invalid address", w.BlockID, w.TxID, erc20types.ERC20Address{2}, w.Amount, false},
+		{"invalid amount", w.BlockID, w.TxID, w.Address, types.NewCurrency64(101), false},
+	}
+	for _, testCase := range testCases {
+		err := lv.ValidateWithdrawTx(testCase.BlockID, testCase.TxID, testCase.Address, testCase.Amount)
+		if testCase.Valid && err != nil {
+			t.Errorf("%s: expected withdrawal to be valid: %v", testCase.Name, err)
+		} else if !testCase.Valid && err == nil {
+			t.Errorf("%s: expected withdrawal to be invalid", testCase.Name)
+		}
+	}
+
+	// the withdrawal is stored in the file, without leaving temporary files behind
+	other, err := NewLocalERC20TransactionValidator(path)
+	if err != nil {
+		t.Fatal("failed to create validator using the same file:", err)
+	}
+	withdrawals, err := other.Withdrawals()
+	if err != nil {
+		t.Fatal("failed to list withdrawals:", err)
+	}
+	if len(withdrawals) != 1 || withdrawals[0].TxID != w.TxID || withdrawals[0].BlockID != w.BlockID ||
+		withdrawals[0].Address != w.Address || !withdrawals[0].Amount.Equals(w.Amount) {
+		t.Errorf("unexpected stored withdrawals: %v", withdrawals)
+	}
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 1 {
+		t.Errorf("expected only the withdrawals file to exist, found %d files", len(infos))
+	}
+	if status, _ := lv.GetStatus(); status.CurrentBlock != 1 || status.HighestBlock != 1 {
+		t.Errorf("unexpected status: %+v", status)
+	}
+
+	// withdrawals added by another validator are seen once the file changes
+	w2 := newTestWithdrawal(2, 200)
+	time.Sleep(10 * time.Millisecond) // ensure a different modification time
+	if err = other.AddWithdrawal(w2); err != nil {
+		t.Fatal("failed to add withdrawal:", err)
+	}
+	if err = lv.ValidateWithdrawTx(erc20types.ERC20Hash{}, w2.TxID, w2.Address, w2.Amount); err != nil {
+		t.Error("expected withdrawal added by another validator to be valid:", err)
+	}
+}
+
+func TestLocalValidatorWithoutFile(t *testing.T) {
+	lv, err := NewLocalERC20TransactionValidator("")
+	if err != nil {
+		t.Fatal("failed to create validator:", err)
+	}
+	w := newTestWithdrawal(1, 100)
+	if err = lv.AddWithdrawal(w); err != nil {
+		t.Fatal("failed to add withdrawal:", err)
+	}
+	if err = lv.ValidateWithdrawTx(erc20types.ERC20Hash{}, w.TxID, w.Address, w.Amount); err != nil {
+		t.Error("expected added withdrawal to be valid:", err)
+	}
+}
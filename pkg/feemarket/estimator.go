@@ -0,0 +1,132 @@
+package feemarket
+
+import (
+	"sort"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// DefaultEstimatorBlocks is the default amount of recent blocks
+// the FeeEstimator looks at to suggest a miner fee.
+const DefaultEstimatorBlocks = 10
+
+// MaxEstimatorBlocks is the maximum amount of recent blocks
+// the FeeEstimator looks at to suggest a miner fee.
+const MaxEstimatorBlocks = 100
+
+// Block fullness thresholds used by the FeeEstimator.
+const (
+	// busyBlockFullness is the average block fullness since which
+	// the median fee rate of recent transactions is suggested.
+	busyBlockFullness = 0.5
+	// fullBlockFullness is the average block fullness since which
+	// the upper quartile fee rate of recent transactions is suggested.
+	fullBlockFullness = 0.9
+)
+
+// FeeSuggestion is the miner fee suggested by a FeeEstimator,
+// based on the fullness of recent blocks.
+type FeeSuggestion struct {
+	// Height of the last block that was looked at
+	Height types.BlockHeight `json:"height"`
+	// Blocks is the amount of recent blocks that were looked at
+	Blocks int `json:"blocks"`
+	// Fullness is the average fraction of the block size limit used by those blocks
+	Fullness float64 `json:"fullness"`
+	// SizeUnit is the amount of bytes both the minimum and suggested fees are defined for
+	SizeUnit uint64 `json:"sizeunit"`
+	// MinimumFee is the minimum miner fee per SizeUnit
+	MinimumFee types.Currency `json:"minimumfee"`
+	// SuggestedFee is the suggested miner fee per SizeUnit
+	SuggestedFee types.Currency `json:"suggestedfee"`
+	// TransactionSize is the size (in bytes) of the transaction to suggest a fee for
+	TransactionSize uint64 `json:"transactionsize"`
+	// TransactionFee is the suggested (total) miner fee for a transaction of TransactionSize bytes
+	TransactionFee types.Currency `json:"transactionfee"`
+}
+
+// FeeEstimator suggests miner fees based on the fullness of recent blocks,
+// such that transactions get included in a block timely, even when blocks fill up.
+type FeeEstimator struct {
+	cs        modules.ConsensusSet
+	constants types.ChainConstants
+}
+
+// NewFeeEstimator creates a new FeeEstimator, using the blocks of the given consensus set.
+func NewFeeEstimator(cs modules.ConsensusSet, constants types.ChainConstants) *FeeEstimator {
+	if cs == nil {
+		panic("no ConsensusSet given")
+	}
+	return &FeeEstimator{
+		cs:        cs,
+		constants: constants,
+	}
+}
+
+// SuggestFee suggests a miner fee for a transaction of the given size (in bytes),
+// based on the given amount of recent blocks (at most MaxEstimatorBlocks). The minimum miner fee is suggested
+// for as long as the blocks are less than half full, afterwards the fee rates
+// paid by the transactions in those blocks are used.
+func (fe *FeeEstimator) SuggestFee(size uint64, blocks int) FeeSuggestion {
+	if blocks <= 0 {
+		blocks = DefaultEstimatorBlocks
+	} else if blocks > MaxEstimatorBlocks {
+		blocks = MaxEstimatorBlocks
+	}
+	height := fe.cs.Height()
+	suggestion := FeeSuggestion{
+		Height:          height,
+		SizeUnit:        SizeUnit,
+		MinimumFee:      fe.constants.MinimumTransactionFee,
+		SuggestedFee:    fe.constants.MinimumTransactionFee,
+		TransactionSize: size,
+	}
+
+	var (
+		fullness float64
+		rates    []FeeRate
+	)
+	for i := 0; i < blocks && types.BlockHeight(i) <= height; i++ {
+		block, ok := fe.cs.BlockAtHeight(height - types.BlockHeight(i))
+		if !ok {
+			break
+		}
+		b, err := siabin.Marshal(block)
+		if err != nil {
+			continue
+		}
+		fullness += float64(len(b)) / float64(fe.constants.BlockSizeLimit)
+		suggestion.Blocks++
+		for _, tx := range block.Transactions {
+			if len(tx.MinerFees) == 0 {
+				continue // block creation transactions don't pay a fee
+			}
+			rate, err := TransactionFeeRate(tx)
+			if err != nil {
+				continue
+			}
+			rates = append(rates, rate)
+		}
+	}
+	if suggestion.Blocks > 0 {
+		suggestion.Fullness = fullness / float64(suggestion.Blocks)
+	}
+
+	if suggestion.Fullness >= busyBlockFullness && len(rates) > 0 {
+		sort.SliceStable(rates, func(i, j int) bool {
+			return rates[i].Cmp(rates[j]) < 0
+		})
+		idx := len(rates) / 2
+		if suggestion.Fullness >= fullBlockFullness {
+			idx = (len(rates) * 3) / 4
+		}
+		if fee := rates[idx].PerSizeUnit(); fee.Cmp(suggestion.SuggestedFee) > 0 {
+			suggestion.SuggestedFee = fee
+		}
+	}
+
+	suggestion.TransactionFee = RequiredMinerFee(size, suggestion.SuggestedFee)
+	return suggestion
+}
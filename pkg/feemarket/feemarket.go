@@ -0,0 +1,152 @@
+package feemarket
+
+import (
+	"sort"
+
+	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// SizeUnit is the amount of (encoded) transaction bytes
+// for which the minimum miner fee has to be paid,
+// with every started unit paying the full minimum miner fee.
+const SizeUnit = 1000
+
+// TransactionSize returns the size of the transaction in bytes,
+// using the same (siabin) encoding which is used to limit the block size.
+func TransactionSize(tx types.Transaction) (uint64, error) {
+	b, err := siabin.Marshal(tx)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(len(b)), nil
+}
+
+// RequiredMinerFee returns the minimum (total) miner fee a transaction
+// of the given size has to pay, paying the minimum miner fee per started SizeUnit.
+func RequiredMinerFee(size uint64, minimumMinerFee types.Currency) types.Currency {
+	units := (size + SizeUnit - 1) / SizeUnit
+	if units == 0 {
+		units = 1
+	}
+	return minimumMinerFee.Mul64(units)
+}
+
+// TotalMinerFee returns the sum of all miner fees paid by the given transaction.
+func TotalMinerFee(tx types.Transaction) types.Currency {
+	var total types.Currency
+	for _, fee := range tx.MinerFees {
+		total = total.Add(fee)
+	}
+	return total
+}
+
+// FeeRate is the (total) miner fee paid by a transaction
+// in relation to its (encoded) size.
+type FeeRate struct {
+	Fee  types.Currency
+	Size uint64
+}
+
+// TransactionFeeRate returns the fee rate of the given transaction.
+func TransactionFeeRate(tx types.Transaction) (FeeRate, error) {
+	size, err := TransactionSize(tx)
+	if err != nil {
+		return FeeRate{}, err
+	}
+	return FeeRate{Fee: TotalMinerFee(tx), Size: size}, nil
+}
+
+// Cmp compares two fee rates, returning -1 if rate is lower than other,
+// 0 if both are equal and 1 if rate is higher than other.
+func (rate FeeRate) Cmp(other FeeRate) int {
+	// a/b < c/d <=> a*d < c*b, avoiding (rounded) divisions
+	return rate.Fee.Mul64(other.Size).Cmp(other.Fee.Mul64(rate.Size))
+}
+
+// PerSizeUnit returns the fee paid per SizeUnit of bytes.
+func (rate FeeRate) PerSizeUnit() types.Currency {
+	if rate.Size == 0 {
+		return rate.Fee
+	}
+	return rate.Fee.Mul64(SizeUnit).Div64(rate.Size)
+}
+
+// OrderByFeeRate orders the given transactions from the highest to the lowest fee rate,
+// while ensuring that a transaction which spends the outputs of another transaction
+// in the list is still ordered after that transaction. Transactions with an equal
+// fee rate remain in their original order.
+func OrderByFeeRate(txs []types.Transaction) []types.Transaction {
+	if len(txs) < 2 {
+		return txs
+	}
+
+	// collect the fee rate of each transaction, as well as which outputs they create
+	rates := make([]FeeRate, len(txs))
+	coinOutputs := make(map[types.CoinOutputID]int)
+	blockStakeOutputs := make(map[types.BlockStakeOutputID]int)
+	for idx, tx := range txs {
+		rate, err := TransactionFeeRate(tx)
+		if err != nil {
+			return txs // keep the original order, should never happen
+		}
+		rates[idx] = rate
+		for i := range tx.CoinOutputs {
+			coinOutputs[tx.CoinOutputID(uint64(i))] = idx
+		}
+		for i := range tx.BlockStakeOutputs {
+			blockStakeOutputs[tx.BlockStakeOutputID(uint64(i))] = idx
+		}
+	}
+
+	// link each transaction to the transactions it depends on
+	parents := make([]int, len(txs))
+	children := make([][]int, len(txs))
+	for idx, tx := range txs {
+		dependencies := make(map[int]struct{})
+		for _, ci := range tx.CoinInputs {
+			if parent, ok := coinOutputs[ci.ParentID]; ok && parent != idx {
+				dependencies[parent] = struct{}{}
+			}
+		}
+		for _, bsi := range tx.BlockStakeInputs {
+			if parent, ok := blockStakeOutputs[bsi.ParentID]; ok && parent != idx {
+				dependencies[parent] = struct{}{}
+			}
+		}
+		parents[idx] = len(dependencies)
+		for parent := range dependencies {
+			children[parent] = append(children[parent], idx)
+		}
+	}
+
+	// select the ready transaction with the highest fee rate, until all are ordered
+	var ready []int
+	for idx := range txs {
+		if parents[idx] == 0 {
+			ready = append(ready, idx)
+		}
+	}
+	ordered := make([]types.Transaction, 0, len(txs))
+	for len(ready) > 0 {
+		sort.SliceStable(ready, func(i, j int) bool {
+			if c := rates[ready[i]].Cmp(rates[ready[j]]); c != 0 {
+				return c > 0
+			}
+			return ready[i] < ready[j]
+		})
+		idx := ready[0]
+		ready = ready[1:]
+		ordered = append(ordered, txs[idx])
+		for _, child := range children[idx] {
+			parents[child]--
+			if parents[child] == 0 {
+				ready = append(ready, child)
+			}
+		}
+	}
+	if len(ordered) != len(txs) {
+		return txs // circular dependencies, should never happen
+	}
+	return ordered
+}
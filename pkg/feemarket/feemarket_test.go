@@ -0,0 +1,78 @@
+package feemarket
+
+import (
+	"testing"
+
+	"github.com/threefoldtech/rivine/types"
+)
+
+func TestRequiredMinerFee(t *testing.T) {
+	minimumFee := types.NewCurrency64(100)
+	testCases := []struct {
+		Size     uint64
+		Expected uint64
+	}{
+		{0, 100},
+		{1, 100},
+		{SizeUnit, 100},
+		{SizeUnit + 1, 200},
+		{3 * SizeUnit, 300},
+	}
+	for idx, testCase := range testCases {
+		fee := RequiredMinerFee(testCase.Size, minimumFee)
+		if fee.Cmp64(testCase.Expected) != 0 {
+			t.Errorf("#%d: expected fee %d for size %d, not %s", idx, testCase.Expected, testCase.Size, fee.String())
+		}
+	}
+}
+
+func TestFeeRateCmp(t *testing.T) {
+	low := FeeRate{Fee: types.NewCurrency64(100), Size: 200}
+	high := FeeRate{Fee: types.NewCurrency64(100), Size: 100}
+	if low.Cmp(high) != -1 || high.Cmp(low) != 1 {
+		t.Error("expected a lower fee rate for a larger transaction paying the same fee")
+	}
+	if c := low.Cmp(FeeRate{Fee: types.NewCurrency64(50), Size: 100}); c != 0 {
+		t.Errorf("expected equal fee rates, not %d", c)
+	}
+	if fee := high.PerSizeUnit(); fee.Cmp64(1000) != 0 {
+		t.Errorf("unexpected fee per size unit: %s", fee.String())
+	}
+}
+
+func TestOrderByFeeRate(t *testing.T) {
+	newTx := func(fee uint64, parents ...types.CoinOutputID) types.Transaction {
+		tx := types.Transaction{
+			Version: types.TransactionVersionOne,
+			CoinOutputs: []types.CoinOutput{{
+				Value:     types.NewCurrency64(1000),
+				Condition: types.NewCondition(types.NewUnlockHashCondition(types.UnlockHash{Type: types.UnlockTypePubKey})),
+			}},
+			MinerFees: []types.Currency{types.NewCurrency64(fee)},
+		}
+		for _, parentID := range parents {
+			tx.CoinInputs = append(tx.CoinInputs, types.CoinInput{
+				ParentID:    parentID,
+				Fulfillment: types.NewFulfillment(&types.SingleSignatureFulfillment{}),
+			})
+		}
+		return tx
+	}
+
+	low := newTx(1)
+	// child pays the highest fee, but has to remain ordered after its (low fee) parent
+	child := newTx(10, low.CoinOutputID(0))
+	medium := newTx(5)
+	high := newTx(8)
+
+	ordered := OrderByFeeRate([]types.Transaction{low, child, medium, high})
+	expected := []types.Transaction{high, medium, low, child}
+	if len(ordered) != len(expected) {
+		t.Fatalf("expected %d transactions, not %d", len(expected), len(ordered))
+	}
+	for idx := range expected {
+		if ordered[idx].ID() != expected[idx].ID() {
+			t.Errorf("#%d: expected tx %s, not %s", idx, expected[idx].ID().String(), ordered[idx].ID().String())
+		}
+	}
+}
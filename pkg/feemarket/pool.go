@@ -0,0 +1,70 @@
+package feemarket
+
+import (
+	"sync"
+
+	"github.com/threefoldtech/rivine/modules"
+	"github.com/threefoldtech/rivine/types"
+)
+
+// TransactionPool wraps a transaction pool, such that its unconfirmed transactions
+// are listed (and passed to its subscribers, e.g. the block creator) ordered by fee rate,
+// prioritizing the transactions which pay the highest fee per byte when blocks fill up.
+type TransactionPool struct {
+	modules.TransactionPool
+
+	subscribers map[modules.TransactionPoolSubscriber]*orderedSubscriber
+	mu          sync.Mutex
+}
+
+// NewTransactionPool wraps the given transaction pool
+// into a transaction pool ordered by fee rate.
+func NewTransactionPool(tpool modules.TransactionPool) *TransactionPool {
+	if tpool == nil {
+		panic("no TransactionPool given")
+	}
+	return &TransactionPool{
+		TransactionPool: tpool,
+		subscribers:     make(map[modules.TransactionPoolSubscriber]*orderedSubscriber),
+	}
+}
+
+// TransactionList returns all unconfirmed transactions, ordered by fee rate.
+func (tpool *TransactionPool) TransactionList() []types.Transaction {
+	return OrderByFeeRate(tpool.TransactionPool.TransactionList())
+}
+
+// TransactionPoolSubscribe subscribes to the wrapped transaction pool,
+// passing the unconfirmed transactions ordered by fee rate to the subscriber.
+func (tpool *TransactionPool) TransactionPoolSubscribe(subscriber modules.TransactionPoolSubscriber) {
+	tpool.mu.Lock()
+	ordered, ok := tpool.subscribers[subscriber]
+	if !ok {
+		ordered = &orderedSubscriber{subscriber: subscriber}
+		tpool.subscribers[subscriber] = ordered
+	}
+	tpool.mu.Unlock()
+	tpool.TransactionPool.TransactionPoolSubscribe(ordered)
+}
+
+// Unsubscribe removes a subscriber from the wrapped transaction pool.
+func (tpool *TransactionPool) Unsubscribe(subscriber modules.TransactionPoolSubscriber) {
+	tpool.mu.Lock()
+	ordered, ok := tpool.subscribers[subscriber]
+	delete(tpool.subscribers, subscriber)
+	tpool.mu.Unlock()
+	if ok {
+		tpool.TransactionPool.Unsubscribe(ordered)
+	}
+}
+
+// orderedSubscriber passes the unconfirmed transactions ordered by fee rate
+// to the subscriber it wraps.
+type orderedSubscriber struct {
+	subscriber modules.TransactionPoolSubscriber
+}
+
+// ReceiveUpdatedUnconfirmedTransactions implements modules.TransactionPoolSubscriber.ReceiveUpdatedUnconfirmedTransactions
+func (os *orderedSubscriber) ReceiveUpdatedUnconfirmedTransactions(txs []types.Transaction, cc modules.ConsensusChange) error {
+	return os.subscriber.ReceiveUpdatedUnconfirmedTransactions(OrderByFeeRate(txs), cc)
+}
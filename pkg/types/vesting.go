@@ -0,0 +1,231 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/types"
+)
+
+const (
+	// ConditionTypeVesting defines the condition type of a VestingCondition,
+	// locking the value of a coin output according to a vesting schedule.
+	//
+	// See the `VestingCondition` type for more information.
+	ConditionTypeVesting types.ConditionType = 128
+)
+
+// RegisterVestingConditionType registers the VestingCondition,
+// such that it can be used as the condition of coin outputs.
+//
+// Registering this condition type is a consensus-breaking change,
+// and should therefore only be done on networks that schedule it.
+func RegisterVestingConditionType() {
+	types.RegisterUnlockConditionType(ConditionTypeVesting, func() types.MarshalableUnlockCondition {
+		return &VestingCondition{}
+	})
+}
+
+// VestingCondition locks the value of a coin output,
+// releasing it gradually over a range of block heights or timestamps,
+// after which it can be spent by the owner as defined by the internal condition.
+//
+// Prior to the end of the vesting schedule the output can only be spent,
+// if the still locked part of the value is sent to an output
+// using the exact same VestingCondition, re-locking the remainder.
+// This last rule is validated by the tfchain consensus,
+// as it requires knowledge about the value of the spent output.
+type VestingCondition struct {
+	// Start and End define the range over which the vested amount is released.
+	// If the value is less than types.LockTimeMinTimestampValue they are considered block heights,
+	// otherwise they are considered Unix Epoch timestamps (in seconds).
+	// Both values have to be of the same kind.
+	Start uint64
+	End   uint64
+	// Steps defines the amount of (equal) steps in which the vested amount is released,
+	// zero means it is released linearly over the entire range.
+	Steps uint64
+	// Amount is the total amount of coins vested by this condition.
+	Amount types.Currency
+	// Condition defines the owner of the vested coins.
+	Condition types.MarshalableUnlockCondition
+}
+
+var (
+	_ types.MarshalableUnlockCondition       = (*VestingCondition)(nil)
+	_ types.MarshalableUnlockConditionGetter = (*VestingCondition)(nil)
+)
+
+// NewVestingCondition creates a new VestingCondition.
+func NewVestingCondition(start, end, steps uint64, amount types.Currency, condition types.MarshalableUnlockCondition) *VestingCondition {
+	return &VestingCondition{
+		Start:     start,
+		End:       end,
+		Steps:     steps,
+		Amount:    amount,
+		Condition: condition,
+	}
+}
+
+// VestedAmount returns the part of the vested amount
+// which is released at the given block height and time.
+func (vc *VestingCondition) VestedAmount(height types.BlockHeight, time types.Timestamp) types.Currency {
+	now := uint64(height)
+	if vc.Start >= types.LockTimeMinTimestampValue {
+		now = uint64(time)
+	}
+	if now >= vc.End {
+		return vc.Amount
+	}
+	if now <= vc.Start {
+		return types.ZeroCurrency
+	}
+	elapsed, duration := now-vc.Start, vc.End-vc.Start
+	if vc.Steps == 0 {
+		return vc.Amount.Mul64(elapsed).Div64(duration)
+	}
+	// only full steps are released
+	steps := types.NewCurrency64(elapsed).Mul64(vc.Steps).Div64(duration)
+	return vc.Amount.Mul(steps).Div64(vc.Steps)
+}
+
+// LockedAmount returns the part of the vested amount
+// which is still locked at the given block height and time.
+func (vc *VestingCondition) LockedAmount(height types.BlockHeight, time types.Timestamp) types.Currency {
+	return vc.Amount.Sub(vc.VestedAmount(height, time))
+}
+
+// Fulfill implements UnlockCondition.Fulfill
+//
+// The fulfillment is delegated to the internal condition,
+// the re-locking of the locked amount is validated by the consensus.
+func (vc *VestingCondition) Fulfill(fulfillment types.UnlockFulfillment, ctx types.FulfillContext) error {
+	switch tf := fulfillment.(type) {
+	case *types.SingleSignatureFulfillment:
+		return vc.Condition.Fulfill(tf, ctx)
+	default:
+		return types.ErrUnexpectedUnlockFulfillment
+	}
+}
+
+// ConditionType implements UnlockCondition.ConditionType
+func (vc *VestingCondition) ConditionType() types.ConditionType { return ConditionTypeVesting }
+
+// IsStandardCondition implements UnlockCondition.IsStandardCondition
+func (vc *VestingCondition) IsStandardCondition(ctx types.ValidationContext) error {
+	if vc.Start >= vc.End {
+		return errors.New("vesting start has to be before its end")
+	}
+	if (vc.Start < types.LockTimeMinTimestampValue) != (vc.End < types.LockTimeMinTimestampValue) {
+		return errors.New("vesting start and end have to be both block heights or both timestamps")
+	}
+	if vc.Steps > vc.End-vc.Start {
+		return errors.New("vesting steps cannot exceed the vesting duration")
+	}
+	if vc.Amount.IsZero() {
+		return errors.New("vested amount has to be defined")
+	}
+	if vc.Condition == nil || vc.Condition.ConditionType() != types.ConditionTypeUnlockHash {
+		return errors.New("unexpected internal unlock condition used as part of vesting condition")
+	}
+	uh := vc.Condition.UnlockHash()
+	if uh.Hash == (crypto.Hash{}) {
+		return errors.New("nil crypto hash cannot be used as unlock hash")
+	}
+	if uh.Type != types.UnlockTypePubKey {
+		return errors.New("non-standard unlock hash type")
+	}
+	return nil
+}
+
+// UnlockHash implements UnlockCondition.UnlockHash
+func (vc *VestingCondition) UnlockHash() types.UnlockHash {
+	return vc.Condition.UnlockHash()
+}
+
+// GetMarshalableUnlockCondition implements MarshalableUnlockConditionGetter.GetMarshalableUnlockCondition
+func (vc *VestingCondition) GetMarshalableUnlockCondition() types.MarshalableUnlockCondition {
+	return vc.Condition
+}
+
+// Equal implements UnlockCondition.Equal
+func (vc *VestingCondition) Equal(c types.UnlockCondition) bool {
+	if p, ok := c.(types.UnlockConditionProxy); ok {
+		c = p.Condition
+	}
+	ovc, ok := c.(*VestingCondition)
+	if !ok {
+		return false
+	}
+	return vc.Start == ovc.Start && vc.End == ovc.End && vc.Steps == ovc.Steps &&
+		vc.Amount.Equals(ovc.Amount) && vc.Condition.Equal(ovc.Condition)
+}
+
+// Fulfillable implements UnlockCondition.Fulfillable
+//
+// A vesting condition is only considered fulfillable once the full amount is vested,
+// partially vested outputs can only be spent by re-locking the locked amount.
+func (vc *VestingCondition) Fulfillable(ctx types.FulfillableContext) bool {
+	return vc.LockedAmount(ctx.BlockHeight, ctx.BlockTime).IsZero()
+}
+
+// Marshal implements MarshalableUnlockCondition.Marshal
+func (vc *VestingCondition) Marshal(f types.MarshalFunc) ([]byte, error) {
+	return f(vc.Start, vc.End, vc.Steps, vc.Amount, types.UnlockConditionProxy{Condition: vc.Condition})
+}
+
+// Unmarshal implements MarshalableUnlockCondition.Unmarshal
+func (vc *VestingCondition) Unmarshal(b []byte, f types.UnmarshalFunc) error {
+	var proxy types.UnlockConditionProxy
+	err := f(b, &vc.Start, &vc.End, &vc.Steps, &vc.Amount, &proxy)
+	if err != nil {
+		return err
+	}
+	vc.Condition = proxy.Condition
+	if vc.Condition == nil {
+		vc.Condition = &types.NilCondition{}
+	}
+	return nil
+}
+
+type jsonVestingCondition struct {
+	Start     uint64                     `json:"start"`
+	End       uint64                     `json:"end"`
+	Steps     uint64                     `json:"steps,omitempty"`
+	Amount    types.Currency             `json:"amount"`
+	Condition types.UnlockConditionProxy `json:"condition"`
+}
+
+// MarshalJSON implements json.Marshaler.MarshalJSON
+//
+// This function is required, as to ensure
+// the internal condition is serialized including its type.
+func (vc *VestingCondition) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonVestingCondition{
+		Start:     vc.Start,
+		End:       vc.End,
+		Steps:     vc.Steps,
+		Amount:    vc.Amount,
+		Condition: types.UnlockConditionProxy{Condition: vc.Condition},
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.UnmarshalJSON
+//
+// This function is required, as to be able to unmarshal
+// the internal condition based on the encoded condition type.
+func (vc *VestingCondition) UnmarshalJSON(b []byte) error {
+	var jvc jsonVestingCondition
+	err := json.Unmarshal(b, &jvc)
+	if err != nil {
+		return err
+	}
+	vc.Start, vc.End, vc.Steps, vc.Amount = jvc.Start, jvc.End, jvc.Steps, jvc.Amount
+	if jvc.Condition.Condition == nil {
+		vc.Condition = &types.NilCondition{}
+	} else {
+		vc.Condition = jvc.Condition.Condition
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/threefoldtech/rivine/crypto"
+	"github.com/threefoldtech/rivine/pkg/encoding/rivbin"
+	"github.com/threefoldtech/rivine/pkg/encoding/siabin"
+	"github.com/threefoldtech/rivine/types"
+)
+
+func init() {
+	RegisterVestingConditionType()
+}
+
+func newTestVestingCondition(start, end, steps uint64) *VestingCondition {
+	return NewVestingCondition(start, end, steps, types.NewCurrency64(1000), types.NewUnlockHashCondition(types.UnlockHash{
+		Type: types.UnlockTypePubKey,
+		Hash: crypto.Hash{1, 2, 3},
+	}))
+}
+
+func TestVestingConditionVestedAmount(t *testing.T) {
+	testCases := []struct {
+		Condition *VestingCondition
+		Height    types.BlockHeight
+		Time      types.Timestamp
+		Vested    uint64
+	}{
+		// linear, block height based
+		{newTestVestingCondition(100, 200, 0), 0, 0, 0},
+		{newTestVestingCondition(100, 200, 0), 100, 0, 0},
+		{newTestVestingCondition(100, 200, 0), 101, 0, 10},
+		{newTestVestingCondition(100, 200, 0), 150, 0, 500},
+		{newTestVestingCondition(100, 200, 0), 199, 0, 990},
+		{newTestVestingCondition(100, 200, 0), 200, 0, 1000},
+		{newTestVestingCondition(100, 200, 0), 300, 0, 1000},
+		// stepped, block height based
+		{newTestVestingCondition(100, 200, 4), 124, 0, 0},
+		{newTestVestingCondition(100, 200, 4), 125, 0, 250},
+		{newTestVestingCondition(100, 200, 4), 174, 0, 500},
+		{newTestVestingCondition(100, 200, 4), 175, 0, 750},
+		{newTestVestingCondition(100, 200, 4), 200, 0, 1000},
+		// linear, timestamp based
+		{newTestVestingCondition(1500000000, 1500001000, 0), 1000, 1499999999, 0},
+		{newTestVestingCondition(1500000000, 1500001000, 0), 0, 1500000250, 250},
+		{newTestVestingCondition(1500000000, 1500001000, 0), 0, 1500001000, 1000},
+	}
+	for idx, testCase := range testCases {
+		vested := testCase.Condition.VestedAmount(testCase.Height, testCase.Time)
+		if !vested.Equals64(testCase.Vested) {
+			t.Errorf("#%d: expected vested amount %d, but got %s", idx, testCase.Vested, vested.String())
+		}
+		locked := testCase.Condition.LockedAmount(testCase.Height, testCase.Time)
+		if !locked.Add(vested).Equals(testCase.Condition.Amount) {
+			t.Errorf("#%d: locked amount %s and vested amount %s do not add up", idx, locked.String(), vested.String())
+		}
+		fulfillable := testCase.Condition.Fulfillable(types.FulfillableContext{
+			BlockHeight: testCase.Height,
+			BlockTime:   testCase.Time,
+		})
+		if fulfillable != locked.IsZero() {
+			t.Errorf("#%d: expected fulfillable to be %v, but it is %v", idx, locked.IsZero(), fulfillable)
+		}
+	}
+}
+
+func TestVestingConditionEncoding(t *testing.T) {
+	condition := types.NewCondition(newTestVestingCondition(100, 200, 4))
+
+	b, err := json.Marshal(condition)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var jsonCondition types.UnlockConditionProxy
+	err = json.Unmarshal(b, &jsonCondition)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !condition.Equal(jsonCondition) {
+		t.Errorf("JSON round trip failed: %s", string(b))
+	}
+
+	b, err = siabin.Marshal(condition)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var siaCondition types.UnlockConditionProxy
+	err = siabin.Unmarshal(b, &siaCondition)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !condition.Equal(siaCondition) {
+		t.Error("sia binary round trip failed")
+	}
+
+	b, err = rivbin.Marshal(condition)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rivineCondition types.UnlockConditionProxy
+	err = rivbin.Unmarshal(b, &rivineCondition)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !condition.Equal(rivineCondition) {
+		t.Error("rivine binary round trip failed")
+	}
+}
+
+func TestVestingConditionIsStandardCondition(t *testing.T) {
+	testCases := []struct {
+		Condition  *VestingCondition
+		IsStandard bool
+	}{
+		{newTestVestingCondition(100, 200, 0), true},
+		{newTestVestingCondition(100, 200, 100), true},
+		{newTestVestingCondition(1500000000, 1500001000, 10), true},
+		{newTestVestingCondition(200, 100, 0), false},
+		{newTestVestingCondition(100, 100, 0), false},
+		{newTestVestingCondition(100, 200, 101), false},
+		{newTestVestingCondition(100, 1500000000, 0), false},
+		{NewVestingCondition(100, 200, 0, types.ZeroCurrency, types.NewUnlockHashCondition(types.UnlockHash{
+			Type: types.UnlockTypePubKey,
+			Hash: crypto.Hash{1},
+		})), false},
+		{NewVestingCondition(100, 200, 0, types.NewCurrency64(1), &types.NilCondition{}), false},
+	}
+	for idx, testCase := range testCases {
+		err := testCase.Condition.IsStandardCondition(types.ValidationContext{})
+		if testCase.IsStandard && err != nil {
+			t.Errorf("#%d: unexpected error: %v", idx, err)
+		} else if !testCase.IsStandard && err == nil {
+			t.Errorf("#%d: expected error, but none received", idx)
+		}
+	}
+}